@@ -16,6 +16,8 @@ import (
 	"github.com/urandom/handler/method"
 	"github.com/urandom/readeef"
 	"github.com/urandom/readeef/api/fever"
+	"github.com/urandom/readeef/api/greader"
+	"github.com/urandom/readeef/api/nextcloud"
 	"github.com/urandom/readeef/api/token"
 	"github.com/urandom/readeef/api/ttrss"
 	"github.com/urandom/readeef/config"
@@ -24,6 +26,7 @@ import (
 	"github.com/urandom/readeef/content/repo"
 	"github.com/urandom/readeef/content/repo/eventable"
 	"github.com/urandom/readeef/content/search"
+	"github.com/urandom/readeef/feed"
 	"github.com/urandom/readeef/log"
 )
 
@@ -33,9 +36,11 @@ func Mux(
 	ctx context.Context,
 	service eventable.Service,
 	feedManager *readeef.FeedManager,
+	hub *readeef.Hub,
 	searchProvider search.Provider,
 	extractor extract.Generator,
 	fs http.FileSystem,
+	client *http.Client,
 	processors []processor.Article,
 	config config.Config,
 	log log.Log,
@@ -64,18 +69,36 @@ func Mux(
 		routes = append(routes, hubbubRoutes(service, log, gzip, access))
 	}
 
-	emulatorRoutes := emulatorRoutes(ctx, service, searchProvider, feedManager, processors, config, log, gzip, access)
+	// A nil *readeef.Hub must not end up in a non-nil interface
+	var websub websubHub
+	if hub != nil {
+		websub = hub
+		routes = append(routes, hubRoutes(hub, config, log, gzip, access))
+	}
+
+	icons := feed.NewIconCache(service.FeedImageRepo(), config.FeedManager.Converted.IconRefreshInterval, client, log)
+
+	routes = append(routes, publishedRoutes(service, []byte(config.Auth.Secret), websub, config, log, gzip, access))
+	routes = append(routes, syndicationRoutes(service, searchProvider, processors, websub, config, log, gzip, access))
+
+	emulatorRoutes := emulatorRoutes(ctx, service, searchProvider, feedManager, icons, processors, config, log, gzip, access)
 	routes = append(routes, emulatorRoutes...)
 
 	routes = append(routes, mainRoutes(
 		userMiddleware(service.UserRepo(), storage, []byte(config.Auth.Secret), log),
 		featureRoutes(features, gzip, access),
-		feedsRoutes(service, feedManager, log, gzip, access),
+		feedsRoutes(service, feedManager, icons, config, log, gzip, access),
 		tagRoutes(service.TagRepo(), log, gzip, access),
+		ruleRoutes(service.RuleRepo(), log, gzip, access),
+		labelRoutes(service.LabelRepo(), log, gzip, access),
+		savedSearchRoutes(service, searchProvider, log, gzip, access),
+		publicationRoutes(service.PublicationRepo(), []byte(config.Auth.Secret), websub, log, gzip, access),
+		syncRoutes(service.ArticleRepo(), log, gzip, access),
 		articlesRoutes(service, extractor, searchProvider, processors, config, log, gzip, access),
 		opmlRoutes(service, feedManager, log, gzip, access),
 		eventsRoutes(ctx, service, storage, feedManager, log),
 		userRoutes(service, []byte(config.Auth.Secret), log, gzip, access),
+		adminRoutes(service, log, gzip, access),
 	))
 
 	r := chi.NewRouter()
@@ -142,11 +165,84 @@ func hubbubRoutes(service repo.Service, log log.Log, gzip, access mw) routes {
 	}}
 }
 
+func hubRoutes(hub websubHub, config config.Config, log log.Log, gzip, access mw) routes {
+	return routes{path: "/hub", route: func(r chi.Router) {
+		r.Use(timeout(5*time.Second), gzip, access)
+		r.Post("/", hubRegistration(hub, config.Hub.BaseURL, config.Hub.Converted.LeaseDuration, log))
+	}}
+}
+
+func publishedRoutes(service repo.Service, secret []byte, hub websubHub, config config.Config, log log.Log, gzip, access mw) routes {
+	return routes{path: "/published", route: func(r chi.Router) {
+		r.Use(timeout(5*time.Second), gzip, access)
+		if hub != nil {
+			r.Use(hubLinks(config.Hub.BaseURL))
+		}
+		r.Get("/{login}/{token:[0-9a-f]+}/{format:atom|rss}",
+			publishedFeed(service.UserRepo(), service.PublicationRepo(), secret, log))
+	}}
+}
+
+func syndicationRoutes(
+	service repo.Service,
+	searchProvider search.Provider,
+	processors []processor.Article,
+	hub websubHub,
+	config config.Config,
+	log log.Log,
+	gzip, access mw,
+) routes {
+	feedRepo := service.FeedRepo()
+	tagRepo := service.TagRepo()
+	labelRepo := service.LabelRepo()
+	limit := config.API.Limits.ArticlesPerQuery
+
+	return routes{path: "/syndication", route: func(r chi.Router) {
+		r.Use(timeout(30*time.Second), gzip, access)
+		if hub != nil {
+			r.Use(hubLinks(config.Hub.BaseURL))
+		}
+
+		r.Route("/{login}/{token:[0-9a-f]+}/{format:atom|rss|json}", func(r chi.Router) {
+			r.Use(syndicationContext(service.UserRepo(), []byte(config.Auth.Secret), log))
+
+			r.Get("/", syndicatedArticles(service, nil, userRepoType, noRepoType, processors, limit, log))
+			r.Get("/favorite", syndicatedArticles(service, nil, favoriteRepoType, noRepoType, processors, limit, log))
+
+			r.Route("/popular", func(r chi.Router) {
+				r.Get("/", syndicatedArticles(service, nil, popularRepoType, userRepoType, processors, limit, log))
+				r.With(feedContext(feedRepo, log)).Get("/feed/{feedID:[0-9]+}",
+					syndicatedArticles(service, nil, popularRepoType, feedRepoType, processors, limit, log))
+				r.With(tagContext(tagRepo, log)).Get("/tag/{tagID:[0-9]+}",
+					syndicatedArticles(service, nil, popularRepoType, tagRepoType, processors, limit, log))
+			})
+
+			r.With(feedContext(feedRepo, log)).Get("/feed/{feedID:[0-9]+}",
+				syndicatedArticles(service, nil, feedRepoType, noRepoType, processors, limit, log))
+			r.With(tagContext(tagRepo, log)).Get("/tag/{tagID:[0-9]+}",
+				syndicatedArticles(service, nil, tagRepoType, noRepoType, processors, limit, log))
+			r.With(labelContext(labelRepo, log)).Get("/label/{labelID:[0-9]+}",
+				syndicatedArticles(service, nil, labelRepoType, noRepoType, processors, limit, log))
+
+			if searchProvider != nil {
+				r.Route("/search", func(r chi.Router) {
+					r.Get("/", syndicatedArticles(service, searchProvider, userRepoType, noRepoType, processors, limit, log))
+					r.With(feedContext(feedRepo, log)).Get("/feed/{feedID:[0-9]+}",
+						syndicatedArticles(service, searchProvider, feedRepoType, noRepoType, processors, limit, log))
+					r.With(tagContext(tagRepo, log)).Get("/tag/{tagID:[0-9]+}",
+						syndicatedArticles(service, searchProvider, tagRepoType, noRepoType, processors, limit, log))
+				})
+			}
+		})
+	}}
+}
+
 func emulatorRoutes(
 	ctx context.Context,
 	service repo.Service,
 	searchProvider search.Provider,
 	feedManager *readeef.FeedManager,
+	icons feed.IconCache,
 	processors []processor.Article,
 	config config.Config,
 	log log.Log,
@@ -168,6 +264,7 @@ func emulatorRoutes(
 						[]byte(config.Auth.Secret), config.FeedManager.Converted.UpdateInterval,
 						log,
 					))
+					r.Get("/"+ttrss.ICONS_URL+"/{feedID:[0-9]+}.ico", feedIconByID(service.FeedRepo(), icons, log))
 				},
 			})
 		case "fever":
@@ -175,8 +272,24 @@ func emulatorRoutes(
 				path: "/fever/",
 				route: func(r chi.Router) {
 					r.Use(timeout(10*time.Second), gzip, access)
-					r.Post("/", fever.Handler(service, processors, log))
-					r.Get("/", fever.Handler(service, processors, log))
+					r.Post("/", fever.Handler(service, icons, processors, log))
+					r.Get("/", fever.Handler(service, icons, processors, log))
+				},
+			})
+		case "greader":
+			rr = append(rr, routes{
+				path: "/greader/",
+				route: func(r chi.Router) {
+					r.Use(timeout(10*time.Second), gzip, access)
+					r.Mount("/", greader.Handler(service, processors, []byte(config.Auth.Secret), log))
+				},
+			})
+		case "nextcloud-news":
+			rr = append(rr, routes{
+				path: "/nextcloud-news/",
+				route: func(r chi.Router) {
+					r.Use(timeout(10*time.Second), gzip, access)
+					r.Mount("/", nextcloud.Handler(service, feedManager, processors, log))
 				},
 			})
 		}
@@ -194,6 +307,11 @@ func mainRoutes(middleware []middleware, subroutes ...routes) routes {
 		}
 
 		for _, sub := range subroutes {
+			// Optional routes are left out by returning an empty route
+			if sub.route == nil {
+				continue
+			}
+
 			r.Route(sub.path, sub.route)
 		}
 	}}
@@ -233,7 +351,7 @@ func featureRoutes(features features, gzip, access mw) routes {
 	}}
 }
 
-func feedsRoutes(service repo.Service, feedManager *readeef.FeedManager, log log.Log, gzip, access mw) routes {
+func feedsRoutes(service eventable.Service, feedManager *readeef.FeedManager, icons iconCache, config config.Config, log log.Log, gzip, access mw) routes {
 	return routes{path: "/feed", route: func(r chi.Router) {
 		feedRepo := service.FeedRepo()
 		r.Use(gzip, access)
@@ -242,15 +360,26 @@ func feedsRoutes(service repo.Service, feedManager *readeef.FeedManager, log log
 
 		r.With(timeout(30*time.Second)).Get("/discover", discoverFeeds(feedRepo, feedManager, log))
 
+		r.With(timeout(5*time.Second)).Post("/refresh", refreshFeeds(feedRepo, feedManager, service, log))
+
 		r.Route("/{feedID:[0-9]+}", func(r chi.Router) {
 			r.Use(feedContext(service.FeedRepo(), log))
-			r.Use(timeout(5 * time.Second))
 
-			r.Delete("/", deleteFeed(feedRepo, feedManager, log))
+			r.With(timeout(5*time.Second)).Delete("/", deleteFeed(feedRepo, feedManager, log))
 
-			r.Get("/tags", getFeedTags(service.TagRepo(), log))
-			r.Put("/tags", setFeedTags(feedRepo, log))
+			r.With(timeout(30*time.Second)).Post("/refresh", refreshFeed(feedManager, service, log))
 
+			r.With(timeout(5*time.Second)).Get("/settings", getFeedSettings(service.FeedSettingsRepo(), log))
+			r.With(timeout(5*time.Second)).Put("/settings", updateFeedSettings(feedRepo, service.FeedSettingsRepo(), log))
+
+			r.With(timeout(5*time.Second)).Get("/tags", getFeedTags(service.TagRepo(), log))
+			r.With(timeout(5*time.Second)).Put("/tags", setFeedTags(feedRepo, log))
+
+			r.With(timeout(30*time.Second)).Get("/icon", getFeedIcon(icons, log))
+
+			r.With(timeout(5*time.Second)).Get("/subscription", getFeedSubscription(service.SubscriptionRepo(), config.Hubbub.Converted.SilenceTimeout, log))
+
+			r.With(timeout(5*time.Second)).Get("/health", getFeedHealth(service.FeedHealthRepo(), log))
 		})
 	}}
 }
@@ -269,6 +398,90 @@ func tagRoutes(repo repo.Tag, log log.Log, gzip, access mw) routes {
 	}}
 }
 
+func ruleRoutes(repo repo.Rule, log log.Log, gzip, access mw) routes {
+	return routes{path: "/rule", route: func(r chi.Router) {
+		r.Use(timeout(5*time.Second), gzip, access)
+		r.Get("/", listRules(repo, log))
+		r.Post("/", updateRule(repo, log))
+
+		r.Route("/{ruleID:[0-9]+}", func(r chi.Router) {
+			r.Use(ruleContext(repo, log))
+
+			r.Get("/", getRule)
+			r.Put("/", updateRule(repo, log))
+			r.Delete("/", deleteRule(repo, log))
+		})
+	}}
+}
+
+func syncRoutes(repo repo.Article, log log.Log, gzip, access mw) routes {
+	return routes{path: "/sync", route: func(r chi.Router) {
+		r.Use(timeout(5*time.Second), gzip, access)
+		r.Get("/", getSync(repo, log))
+	}}
+}
+
+func labelRoutes(repo repo.Label, log log.Log, gzip, access mw) routes {
+	return routes{path: "/label", route: func(r chi.Router) {
+		r.Use(timeout(5*time.Second), gzip, access)
+		r.Get("/", listLabels(repo, log))
+		r.Post("/", updateLabel(repo, log))
+
+		r.Route("/{labelID:[0-9]+}", func(r chi.Router) {
+			r.Use(labelContext(repo, log))
+
+			r.Get("/", getLabel)
+			r.Put("/", updateLabel(repo, log))
+			r.Delete("/", deleteLabel(repo, log))
+			r.Post("/articles", labelArticles(repo, log))
+			r.Delete("/articles", labelArticles(repo, log))
+		})
+	}}
+}
+
+func publicationRoutes(repo repo.Publication, secret []byte, hub websubHub, log log.Log, gzip, access mw) routes {
+	return routes{path: "/publication", route: func(r chi.Router) {
+		r.Use(timeout(5*time.Second), gzip, access)
+		if hub != nil {
+			r.Use(hubCheck(hub))
+		}
+		r.Get("/", listPublications(repo, secret, log))
+		r.Post("/", updatePublication(repo, log))
+		r.Post("/articles", publishArticles(repo, log))
+		r.Delete("/articles", publishArticles(repo, log))
+
+		r.Route("/{publicationID:[0-9]+}", func(r chi.Router) {
+			r.Use(publicationContext(repo, log))
+
+			r.Get("/", getPublication)
+			r.Put("/", updatePublication(repo, log))
+			r.Delete("/", deletePublication(repo, log))
+		})
+	}}
+}
+
+func savedSearchRoutes(service repo.Service, searchProvider search.Provider, log log.Log, gzip, access mw) routes {
+	if searchProvider == nil {
+		return routes{}
+	}
+
+	repo := service.SavedSearchRepo()
+
+	return routes{path: "/saved-search", route: func(r chi.Router) {
+		r.Use(timeout(30*time.Second), gzip, access)
+		r.Get("/", listSavedSearches(service, searchProvider, log))
+		r.Post("/", updateSavedSearch(service, log))
+
+		r.Route("/{savedSearchID:[0-9]+}", func(r chi.Router) {
+			r.Use(savedSearchContext(repo, log))
+
+			r.Get("/", getSavedSearch)
+			r.Put("/", updateSavedSearch(service, log))
+			r.Delete("/", deleteSavedSearch(repo, log))
+		})
+	}}
+}
+
 func articlesRoutes(
 	service repo.Service,
 	extractor extract.Generator,
@@ -281,6 +494,7 @@ func articlesRoutes(
 	articleRepo := service.ArticleRepo()
 	feedRepo := service.FeedRepo()
 	tagRepo := service.TagRepo()
+	labelRepo := service.LabelRepo()
 
 	return routes{path: "/article", route: func(r chi.Router) {
 		r.Use(timeout(30*time.Second), gzip, access)
@@ -295,12 +509,17 @@ func articlesRoutes(
 				r.With(tagContext(tagRepo, log)).Get("/tag/{tagID:[0-9]+}",
 					articleSearch(service, searchProvider, tagRepoType, processors, config.API.Limits.ArticlesPerQuery, log))
 			})
+
+			r.With(savedSearchContext(service.SavedSearchRepo(), log)).Get("/saved-search/{savedSearchID:[0-9]+}",
+				savedSearchArticles(service, searchProvider, processors, config.API.Limits.ArticlesPerQuery, log))
 		}
 
 		r.Get("/ids", getIDs(service, userRepoType, noRepoType, config.API.Limits.ArticlesPerQuery, log))
 
 		r.Post("/read", articlesStateChange(service, userRepoType, read, log))
 		r.Delete("/read", articlesStateChange(service, userRepoType, read, log))
+		r.Post("/hidden", articlesStateChange(service, userRepoType, hidden, log))
+		r.Delete("/hidden", articlesStateChange(service, userRepoType, hidden, log))
 
 		r.Route("/{articleID:[0-9]+}", func(r chi.Router) {
 			r.Use(articleContext(articleRepo, processors, log))
@@ -313,6 +532,8 @@ func articlesRoutes(
 			r.Delete("/read", articleStateChange(articleRepo, read, log))
 			r.Post("/favorite", articleStateChange(articleRepo, favorite, log))
 			r.Delete("/favorite", articleStateChange(articleRepo, favorite, log))
+			r.Post("/hidden", articleStateChange(articleRepo, hidden, log))
+			r.Delete("/hidden", articleStateChange(articleRepo, hidden, log))
 		})
 
 		r.Route("/favorite", func(r chi.Router) {
@@ -366,6 +587,16 @@ func articlesRoutes(
 			r.Delete("/read", articlesStateChange(service, tagRepoType, read, log))
 		})
 
+		r.Route("/label/{labelID:[0-9]+}", func(r chi.Router) {
+			r.Use(labelContext(labelRepo, log))
+
+			r.Get("/", getArticles(service, labelRepoType, noRepoType, processors, config.API.Limits.ArticlesPerQuery, log))
+			r.Get("/ids", getIDs(service, labelRepoType, noRepoType, config.API.Limits.ArticlesPerQuery, log))
+
+			r.Post("/read", articlesStateChange(service, labelRepoType, read, log))
+			r.Delete("/read", articlesStateChange(service, labelRepoType, read, log))
+		})
+
 	}}
 }
 
@@ -373,7 +604,7 @@ func opmlRoutes(service repo.Service, feedManager *readeef.FeedManager, log log.
 	return routes{path: "/opml", route: func(r chi.Router) {
 		r.Use(gzip, access)
 		r.With(timeout(10*time.Second)).Get("/", exportOPML(service, log))
-		r.With(timeout(2*time.Minute)).Post("/", importOPML(service.FeedRepo(), feedManager, log))
+		r.With(timeout(2*time.Minute)).Post("/", importOPML(service, feedManager, log))
 	}}
 }
 
@@ -396,6 +627,7 @@ func userRoutes(service repo.Service, secret []byte, log log.Log, gzip, access m
 
 		r.Get("/current", getUserData)
 		r.Post("/token", createUserToken(secret, log))
+		r.Get("/syndication-token", getSyndicationToken(secret))
 
 		r.Route("/settings", func(r chi.Router) {
 			r.Get("/", getSettingKeys)
@@ -418,6 +650,14 @@ func userRoutes(service repo.Service, secret []byte, log log.Log, gzip, access m
 	}}
 }
 
+func adminRoutes(service repo.Service, log log.Log, gzip, access mw) routes {
+	return routes{path: "/admin", route: func(r chi.Router) {
+		r.Use(timeout(10*time.Second), gzip, access, adminValidator)
+
+		r.Get("/feeds/failing", listFailingFeeds(service.FeedRepo(), service.FeedHealthRepo(), log))
+	}}
+}
+
 func fatal(w http.ResponseWriter, log log.Log, format string, err error) {
 	log.Printf(format, err)
 	http.Error(w, fmt.Sprintf(format, err.Error()), http.StatusInternalServerError)
@@ -36,6 +36,7 @@ const (
 	popularRepoType
 	tagRepoType
 	feedRepoType
+	labelRepoType
 )
 
 func getArticles(
@@ -60,82 +61,114 @@ func getArticles(
 			return
 		}
 
-		o = append(o, content.Filters(content.GetUserFilters(user)))
+		o = append(o, content.Filters(content.GetUserFilters(user)),
+			content.IncludeMedia, content.IncludeCategories, content.IncludeLabels)
 
-		switch repoType {
-		case favoriteRepoType:
-			o = append(o, content.FavoriteOnly)
-		case userRepoType:
-		case popularRepoType:
-			o = append(o, content.IncludeScores)
-			o = append(o, content.HighScoredFirst)
-			o = append(o, content.TimeRange(time.Now().AddDate(0, 0, -5), time.Now().Add(-15*time.Minute)))
+		view, stop := articleViewOptions(w, r, user, repoType, subType, tagRepo, log)
+		if stop {
+			return
+		}
 
-			switch subType {
-			case userRepoType:
-			case tagRepoType:
-				tag, stop := tagFromRequest(w, r)
-				if stop {
-					return
-				}
+		o = append(o, view...)
 
-				ids, err := tagRepo.FeedIDs(tag, user)
-				if err != nil {
-					fatal(w, log, "Error getting tag feed ids: %+v", err)
-					return
-				}
+		articles, err := repo.ForUser(user, o...)
 
-				o = append(o, content.FeedIDs(ids))
-			case feedRepoType:
-				feed, stop := feedFromRequest(w, r)
-				if stop {
-					return
-				}
+		if err != nil {
+			fatal(w, log, "Error getting articles: %+v", err)
+			return
+		}
 
-				o = append(o, content.FeedIDs([]content.FeedID{feed.ID}))
-			default:
-				http.Error(w, "Unknown article repository", http.StatusBadRequest)
-				return
-			}
+		articles = processor.Articles(processors).Process(articles)
+
+		if articles == nil {
+			articles = []content.Article{}
+		}
+		args{"articles": articles}.WriteJSON(w)
+	}
+}
+
+// articleViewOptions returns the query options that select the articles of
+// the given repository type, using the feed, tag or label from the request
+// context.
+func articleViewOptions(
+	w http.ResponseWriter,
+	r *http.Request,
+	user content.User,
+	repoType articleRepoType,
+	subType articleRepoType,
+	tagRepo repo.Tag,
+	log log.Log,
+) ([]content.QueryOpt, bool) {
+	o := []content.QueryOpt{}
+
+	switch repoType {
+	case favoriteRepoType:
+		o = append(o, content.FavoriteOnly)
+	case userRepoType:
+	case popularRepoType:
+		o = append(o, content.IncludeScores)
+		o = append(o, content.HighScoredFirst)
+		o = append(o, content.TimeRange(time.Now().AddDate(0, 0, -5), time.Now().Add(-15*time.Minute)))
+
+		switch subType {
+		case userRepoType:
 		case tagRepoType:
 			tag, stop := tagFromRequest(w, r)
 			if stop {
-				return
+				return o, true
 			}
 
 			ids, err := tagRepo.FeedIDs(tag, user)
 			if err != nil {
 				fatal(w, log, "Error getting tag feed ids: %+v", err)
-				return
+				return o, true
 			}
 
 			o = append(o, content.FeedIDs(ids))
 		case feedRepoType:
 			feed, stop := feedFromRequest(w, r)
 			if stop {
-				return
+				return o, true
 			}
 
 			o = append(o, content.FeedIDs([]content.FeedID{feed.ID}))
 		default:
 			http.Error(w, "Unknown article repository", http.StatusBadRequest)
-			return
+			return o, true
+		}
+	case tagRepoType:
+		tag, stop := tagFromRequest(w, r)
+		if stop {
+			return o, true
 		}
 
-		articles, err := repo.ForUser(user, o...)
-
+		ids, err := tagRepo.FeedIDs(tag, user)
 		if err != nil {
-			fatal(w, log, "Error getting articles: %+v", err)
-			return
+			fatal(w, log, "Error getting tag feed ids: %+v", err)
+			return o, true
 		}
 
-		articles = processor.Articles(processors).Process(articles)
+		o = append(o, content.FeedIDs(ids))
+	case feedRepoType:
+		feed, stop := feedFromRequest(w, r)
+		if stop {
+			return o, true
+		}
 
-		if articles == nil {
-			articles = []content.Article{}
+		o = append(o, content.FeedIDs([]content.FeedID{feed.ID}))
+	case labelRepoType:
+		label, stop := labelFromRequest(w, r)
+		if stop {
+			return o, true
 		}
-		args{"articles": articles}.WriteJSON(w)
+
+		o = append(o, content.LabelIDs([]content.LabelID{label.ID}))
+	default:
+		http.Error(w, "Unknown article repository", http.StatusBadRequest)
+		return o, true
 	}
+
+	return o, false
 }
 
 type searcher interface {
@@ -169,7 +202,7 @@ func articleSearch(
 			return
 		}
 
-		o = append(o, content.Filters(content.GetUserFilters(user)))
+		o = append(o, content.Filters(content.GetUserFilters(user)), content.IncludeCategories)
 
 		switch repoType {
 		case userRepoType:
@@ -292,6 +325,13 @@ func getIDs(
 			}
 
 			o = append(o, content.FeedIDs([]content.FeedID{feed.ID}))
+		case labelRepoType:
+			label, stop := labelFromRequest(w, r)
+			if stop {
+				return
+			}
+
+			o = append(o, content.LabelIDs([]content.LabelID{label.ID}))
 		default:
 			http.Error(w, "Unknown article repository", http.StatusBadRequest)
 			return
@@ -356,6 +396,7 @@ type articleState int
 const (
 	read articleState = iota
 	favorite
+	hidden
 )
 
 func articleStateChange(
@@ -378,20 +419,26 @@ func articleStateChange(
 
 		var previousState bool
 
-		if state == read {
+		switch state {
+		case read:
 			previousState = article.Read
-		} else {
+		case favorite:
 			previousState = article.Favorite
+		case hidden:
+			previousState = article.Hidden
 		}
 
 		if previousState != value {
 			var err error
 			ids := []content.ArticleID{article.ID}
 
-			if state == read {
+			switch state {
+			case read:
 				err = repo.Read(value, user, content.IDs(ids))
-			} else {
+			case favorite:
 				err = repo.Favor(value, user, content.IDs(ids))
+			case hidden:
+				err = repo.Hide(value, user, content.IDs(ids))
 			}
 
 			if err != nil {
@@ -453,16 +500,26 @@ func articlesStateChange(
 			}
 
 			o = append(o, content.FeedIDs([]content.FeedID{feed.ID}))
+		case labelRepoType:
+			label, stop := labelFromRequest(w, r)
+			if stop {
+				return
+			}
+
+			o = append(o, content.LabelIDs([]content.LabelID{label.ID}))
 		default:
 			http.Error(w, "Unknown type", http.StatusBadRequest)
 			return
 		}
 
 		var err error
-		if state == read {
+		switch state {
+		case read:
 			err = articleRepo.Read(value, user, o...)
-		} else {
+		case favorite:
 			err = articleRepo.Favor(value, user, o...)
+		case hidden:
+			err = articleRepo.Hide(value, user, o...)
 		}
 
 		if err != nil {
@@ -573,6 +630,14 @@ func articleQueryOptions(w http.ResponseWriter, r *http.Request, articlesLimit i
 		o = append(o, content.IDs(ids))
 	}
 
+	if authors, ok := query["author"]; ok {
+		o = append(o, content.Authors(authors))
+	}
+
+	if categories, ok := query["category"]; ok {
+		o = append(o, content.Categories(categories))
+	}
+
 	if _, ok := query["unreadOnly"]; ok {
 		o = append(o, content.UnreadOnly)
 	}
@@ -581,6 +646,10 @@ func articleQueryOptions(w http.ResponseWriter, r *http.Request, articlesLimit i
 		o = append(o, content.ReadOnly)
 	}
 
+	if _, ok := query["hiddenOnly"]; ok {
+		o = append(o, content.HiddenOnly)
+	}
+
 	if _, ok := query["unreadFirst"]; ok {
 		o = append(o, content.UnreadFirst)
 	}
@@ -610,7 +679,8 @@ func articleContext(repo repo.Article, processors []processor.Article, log log.L
 				return
 			}
 
-			articles, err := repo.ForUser(user, content.IDs([]content.ArticleID{content.ArticleID(id)}))
+			articles, err := repo.ForUser(user, content.IDs([]content.ArticleID{content.ArticleID(id)}),
+				content.IncludeMedia, content.IncludeCategories, content.IncludeHidden, content.IncludeLabels)
 			if err != nil {
 				fatal(w, log, "Error getting article: %+v", err)
 				return
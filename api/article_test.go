@@ -69,20 +69,22 @@ func Test_getArticles(t *testing.T) {
 		{name: "invalid after time opt", url: "/?afterTime=no", badQuery: true, code: 400},
 		{name: "invalid ids", url: "/?id=4&id=no", badQuery: true, code: 400},
 		{name: "invalid repo type", url: "/?id=4&id=1&limit=10&beforeID=3", code: 400},
-		{name: "articles err", url: "/", repoType: favoriteRepoType, articlesErr: errors.New("err"), code: 500, opts: content.QueryOptions{Limit: 50, FavoriteOnly: true, SortField: content.SortByDate, SortOrder: content.DescendingOrder}},
-		{name: "popular user", url: "/", repoType: popularRepoType, subType: userRepoType, articles: []content.Article{{ID: 1}}, code: 200, opts: content.QueryOptions{Limit: 50, IncludeScores: true, HighScoredFirst: true, BeforeDate: time.Now(), AfterDate: time.Now().AddDate(0, 0, -5), SortField: content.SortByDate, SortOrder: content.DescendingOrder}},
-		{name: "popular tag", url: "/?limit=25&offset=10", repoType: popularRepoType, subType: tagRepoType, articles: []content.Article{{ID: 1}}, code: 200, opts: content.QueryOptions{Limit: 25, Offset: 0, IncludeScores: true, HighScoredFirst: true, BeforeDate: time.Now(), AfterDate: time.Now().AddDate(0, 0, -5), FeedIDs: []content.FeedID{1, 2, 3, 4}, SortField: content.SortByDate, SortOrder: content.DescendingOrder}},
+		{name: "articles err", url: "/", repoType: favoriteRepoType, articlesErr: errors.New("err"), code: 500, opts: content.QueryOptions{Limit: 50, FavoriteOnly: true, SortField: content.SortByDate, SortOrder: content.DescendingOrder, IncludeMedia: true, IncludeCategories: true, IncludeLabels: true}},
+		{name: "popular user", url: "/", repoType: popularRepoType, subType: userRepoType, articles: []content.Article{{ID: 1}}, code: 200, opts: content.QueryOptions{Limit: 50, IncludeScores: true, HighScoredFirst: true, BeforeDate: time.Now(), AfterDate: time.Now().AddDate(0, 0, -5), SortField: content.SortByDate, SortOrder: content.DescendingOrder, IncludeMedia: true, IncludeCategories: true, IncludeLabels: true}},
+		{name: "popular tag", url: "/?limit=25&offset=10", repoType: popularRepoType, subType: tagRepoType, articles: []content.Article{{ID: 1}}, code: 200, opts: content.QueryOptions{Limit: 25, Offset: 0, IncludeScores: true, HighScoredFirst: true, BeforeDate: time.Now(), AfterDate: time.Now().AddDate(0, 0, -5), FeedIDs: []content.FeedID{1, 2, 3, 4}, SortField: content.SortByDate, SortOrder: content.DescendingOrder, IncludeMedia: true, IncludeCategories: true, IncludeLabels: true}},
 		{name: "popular no tag", url: "/?limit=25&offset=10", repoType: popularRepoType, subType: tagRepoType, noTag: true, code: 400},
 		{name: "popular tag err", url: "/?limit=25&offset=10", repoType: popularRepoType, subType: tagRepoType, articles: nil, code: 500, feedIDsErr: errors.New("err")},
-		{name: "popular feed", url: "/?limit=25", repoType: popularRepoType, subType: feedRepoType, articles: []content.Article{{ID: 1}}, code: 200, opts: content.QueryOptions{Limit: 25, IncludeScores: true, HighScoredFirst: true, BeforeDate: time.Now(), AfterDate: time.Now().AddDate(0, 0, -5), FeedIDs: []content.FeedID{1}, SortField: content.SortByDate, SortOrder: content.DescendingOrder}},
+		{name: "popular feed", url: "/?limit=25", repoType: popularRepoType, subType: feedRepoType, articles: []content.Article{{ID: 1}}, code: 200, opts: content.QueryOptions{Limit: 25, IncludeScores: true, HighScoredFirst: true, BeforeDate: time.Now(), AfterDate: time.Now().AddDate(0, 0, -5), FeedIDs: []content.FeedID{1}, SortField: content.SortByDate, SortOrder: content.DescendingOrder, IncludeMedia: true, IncludeCategories: true, IncludeLabels: true}},
 		{name: "popular no feed", url: "/?limit=25&offset=10", repoType: popularRepoType, subType: feedRepoType, noFeed: true, code: 400},
 		{name: "popular unknown", url: "/?limit=25&offset=10", repoType: popularRepoType, subType: 0, code: 400},
-		{name: "tag", url: "/?limit=25&unreadOnly&olderFirst", repoType: tagRepoType, code: 200, opts: content.QueryOptions{Limit: 25, UnreadOnly: true, FeedIDs: []content.FeedID{1, 2, 3, 4}, SortField: content.SortByDate, SortOrder: content.AscendingOrder}, articles: []content.Article{{ID: 1}, {ID: 2, Link: "http://example.com"}}},
+		{name: "tag", url: "/?limit=25&unreadOnly&olderFirst", repoType: tagRepoType, code: 200, opts: content.QueryOptions{Limit: 25, UnreadOnly: true, FeedIDs: []content.FeedID{1, 2, 3, 4}, SortField: content.SortByDate, SortOrder: content.AscendingOrder, IncludeMedia: true, IncludeCategories: true, IncludeLabels: true}, articles: []content.Article{{ID: 1}, {ID: 2, Link: "http://example.com"}}},
 		{name: "tag err", url: "/?limit=25&unreadOnly&olderFirst", repoType: tagRepoType, code: 500, feedIDsErr: errors.New("err")},
 		{name: "no tag", url: "/?limit=25&unreadOnly&olderFirst", repoType: tagRepoType, code: 400, noTag: true},
-		{name: "feed", url: "/?limit=25&unreadFirst", repoType: feedRepoType, code: 200, opts: content.QueryOptions{Limit: 25, UnreadFirst: true, FeedIDs: []content.FeedID{1}, SortField: content.SortByDate, SortOrder: content.DescendingOrder}, articles: []content.Article{{ID: 1}, {ID: 2, Link: "http://example.com"}}},
+		{name: "feed", url: "/?limit=25&unreadFirst", repoType: feedRepoType, code: 200, opts: content.QueryOptions{Limit: 25, UnreadFirst: true, FeedIDs: []content.FeedID{1}, SortField: content.SortByDate, SortOrder: content.DescendingOrder, IncludeMedia: true, IncludeCategories: true, IncludeLabels: true}, articles: []content.Article{{ID: 1}, {ID: 2, Link: "http://example.com"}}},
 		{name: "no feed", url: "/?limit=25&unreadFirst", repoType: feedRepoType, code: 400, noFeed: true},
-		{name: "user", url: "/?limit=25&beforeTime=100000&afterTime=500", repoType: userRepoType, code: 200, opts: content.QueryOptions{Limit: 25, AfterDate: time.Unix(500, 0), BeforeDate: time.Unix(100000, 0), SortField: content.SortByDate, SortOrder: content.DescendingOrder}, articles: []content.Article{{ID: 1}, {ID: 2, Link: "http://example.com"}}},
+		{name: "user", url: "/?limit=25&beforeTime=100000&afterTime=500", repoType: userRepoType, code: 200, opts: content.QueryOptions{Limit: 25, AfterDate: time.Unix(500, 0), BeforeDate: time.Unix(100000, 0), SortField: content.SortByDate, SortOrder: content.DescendingOrder, IncludeMedia: true, IncludeCategories: true, IncludeLabels: true}, articles: []content.Article{{ID: 1}, {ID: 2, Link: "http://example.com"}}},
+		{name: "author and category", url: "/?author=John+Doe&category=go", repoType: userRepoType, code: 200, opts: content.QueryOptions{Limit: 50, Authors: []string{"John Doe"}, Categories: []string{"go"}, SortField: content.SortByDate, SortOrder: content.DescendingOrder, IncludeMedia: true, IncludeCategories: true, IncludeLabels: true}, articles: []content.Article{{ID: 1, Author: "John Doe", Categories: []string{"Go"}}}},
+		{name: "label", url: "/?unreadOnly", repoType: labelRepoType, code: 200, opts: content.QueryOptions{Limit: 50, UnreadOnly: true, LabelIDs: []content.LabelID{1}, SortField: content.SortByDate, SortOrder: content.DescendingOrder, IncludeMedia: true, IncludeCategories: true, IncludeLabels: true}, articles: []content.Article{{ID: 1, Labels: []content.LabelID{1}}}},
 	}
 	type data struct {
 		Articles []content.Article `json:"articles"`
@@ -141,6 +143,10 @@ func Test_getArticles(t *testing.T) {
 					}
 				}
 
+				if tt.repoType == labelRepoType {
+					r = r.WithContext(context.WithValue(r.Context(), labelKey, content.Label{ID: 1, UserLogin: user.Login}))
+				}
+
 				if tt.repoType == 0 || tt.repoType == popularRepoType && tt.subType == 0 {
 					break
 				}
@@ -216,13 +222,14 @@ func Test_articleSearch(t *testing.T) {
 		{name: "invalid after time opt", url: "/?query=test&afterTime=no", badQuery: true, code: 400},
 		{name: "invalid ids", url: "/?query=test&id=4&id=no", badQuery: true, code: 400},
 		{name: "invalid repo type", url: "/?query=test&id=4&id=1&limit=10&beforeID=3", code: 400},
-		{name: "articles err", url: "/?query=test", repoType: userRepoType, articlesErr: errors.New("err"), code: 500, opts: content.QueryOptions{Limit: 50, SortField: content.SortByDate, SortOrder: content.DescendingOrder}},
-		{name: "tag", url: "/?query=test&limit=25&unreadOnly&olderFirst", repoType: tagRepoType, code: 200, opts: content.QueryOptions{Limit: 25, UnreadOnly: true, FeedIDs: []content.FeedID{1, 2, 3, 4}, SortField: content.SortByDate, SortOrder: content.AscendingOrder}, articles: []content.Article{{ID: 1}, {ID: 2, Link: "http://example.com"}}},
+		{name: "articles err", url: "/?query=test", repoType: userRepoType, articlesErr: errors.New("err"), code: 500, opts: content.QueryOptions{Limit: 50, SortField: content.SortByDate, SortOrder: content.DescendingOrder, IncludeCategories: true}},
+		{name: "tag", url: "/?query=test&limit=25&unreadOnly&olderFirst", repoType: tagRepoType, code: 200, opts: content.QueryOptions{Limit: 25, UnreadOnly: true, FeedIDs: []content.FeedID{1, 2, 3, 4}, SortField: content.SortByDate, SortOrder: content.AscendingOrder, IncludeCategories: true}, articles: []content.Article{{ID: 1}, {ID: 2, Link: "http://example.com"}}},
 		{name: "tag err", url: "/?query=test&limit=25&unreadOnly&olderFirst", repoType: tagRepoType, code: 500, feedIDsErr: errors.New("err")},
 		{name: "no tag", url: "/?query=test&limit=25&unreadOnly&olderFirst", repoType: tagRepoType, code: 400, noTag: true},
-		{name: "feed", url: "/?query=test&limit=25&unreadFirst", repoType: feedRepoType, code: 200, opts: content.QueryOptions{Limit: 25, UnreadFirst: true, FeedIDs: []content.FeedID{1}, SortField: content.SortByDate, SortOrder: content.DescendingOrder}, articles: []content.Article{{ID: 1}, {ID: 2, Link: "http://example.com"}}},
+		{name: "feed", url: "/?query=test&limit=25&unreadFirst", repoType: feedRepoType, code: 200, opts: content.QueryOptions{Limit: 25, UnreadFirst: true, FeedIDs: []content.FeedID{1}, SortField: content.SortByDate, SortOrder: content.DescendingOrder, IncludeCategories: true}, articles: []content.Article{{ID: 1}, {ID: 2, Link: "http://example.com"}}},
 		{name: "no feed", url: "/?query=test&limit=25&unreadFirst", repoType: feedRepoType, code: 400, noFeed: true},
-		{name: "user", url: "/?query=test&limit=25&beforeTime=100000&afterTime=500", repoType: userRepoType, code: 200, opts: content.QueryOptions{Limit: 25, AfterDate: time.Unix(500, 0), BeforeDate: time.Unix(100000, 0), SortField: content.SortByDate, SortOrder: content.DescendingOrder}, articles: []content.Article{{ID: 1}, {ID: 2, Link: "http://example.com"}}},
+		{name: "user", url: "/?query=test&limit=25&beforeTime=100000&afterTime=500", repoType: userRepoType, code: 200, opts: content.QueryOptions{Limit: 25, AfterDate: time.Unix(500, 0), BeforeDate: time.Unix(100000, 0), SortField: content.SortByDate, SortOrder: content.DescendingOrder, IncludeCategories: true}, articles: []content.Article{{ID: 1}, {ID: 2, Link: "http://example.com"}}},
+		{name: "author", url: "/?query=test&author=John+Doe", repoType: userRepoType, code: 200, opts: content.QueryOptions{Limit: 50, Authors: []string{"John Doe"}, SortField: content.SortByDate, SortOrder: content.DescendingOrder, IncludeCategories: true}, articles: []content.Article{{ID: 1, Author: "John Doe"}}},
 	}
 	type data struct {
 		Articles []content.Article `json:"articles"`
@@ -589,12 +596,17 @@ func Test_articleStateChange(t *testing.T) {
 		{name: "change read false", state: read, current: true, code: 200},
 		{name: "change favorite true", state: favorite, value: true, code: 200},
 		{name: "change favorite false", state: favorite, current: true, code: 200},
+		{name: "no change hidden true", state: hidden, current: true, value: true, code: 200},
+		{name: "hidden err", state: hidden, value: true, stateErr: errors.New("err"), code: 500},
+		{name: "change hidden true", state: hidden, value: true, code: 200},
+		{name: "change hidden false", state: hidden, current: true, code: 200},
 	}
 
 	type data struct {
 		Success  bool `json:"success"`
 		Read     bool `json:"read"`
 		Favorite bool `json:"favorite"`
+		Hidden   bool `json:"hidden"`
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -627,10 +639,13 @@ func Test_articleStateChange(t *testing.T) {
 
 				article = content.Article{ID: 4, Link: "http://example.com"}
 				if tt.current {
-					if tt.state == read {
+					switch tt.state {
+					case read:
 						article.Read = tt.current
-					} else {
+					case favorite:
 						article.Favorite = tt.current
+					case hidden:
+						article.Hidden = tt.current
 					}
 				}
 				r = r.WithContext(context.WithValue(r.Context(), articleKey, article))
@@ -639,10 +654,13 @@ func Test_articleStateChange(t *testing.T) {
 					break
 				}
 
-				if tt.state == read {
+				switch tt.state {
+				case read:
 					articleRepo.EXPECT().Read(tt.value, userMatcher{user}, gomock.Any()).Return(tt.stateErr)
-				} else {
+				case favorite:
 					articleRepo.EXPECT().Favor(tt.value, userMatcher{user}, gomock.Any()).Return(tt.stateErr)
+				case hidden:
+					articleRepo.EXPECT().Hide(tt.value, userMatcher{user}, gomock.Any()).Return(tt.stateErr)
 				}
 
 				if tt.stateErr != nil {
@@ -664,10 +682,13 @@ func Test_articleStateChange(t *testing.T) {
 			var want data
 			if tt.code == 200 {
 				want = data{Success: true}
-				if tt.state == read {
+				switch tt.state {
+				case read:
 					want.Read = tt.value
-				} else {
+				case favorite:
 					want.Favorite = tt.value
+				case hidden:
+					want.Hidden = tt.value
 				}
 			}
 
@@ -877,7 +898,7 @@ func Test_articleContext(t *testing.T) {
 					o := content.QueryOptions{}
 					o.Apply(opts)
 
-					want := content.QueryOptions{IDs: []content.ArticleID{tt.articleID}}
+					want := content.QueryOptions{IDs: []content.ArticleID{tt.articleID}, IncludeMedia: true, IncludeCategories: true, IncludeHidden: true, IncludeLabels: true}
 
 					if !reflect.DeepEqual(o, want) {
 						t.Errorf("getArticles() options = %#v, want %#v", o, want)
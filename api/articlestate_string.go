@@ -4,9 +4,9 @@ package api
 
 import "fmt"
 
-const _articleState_name = "readfavorite"
+const _articleState_name = "readfavoritehidden"
 
-var _articleState_index = [...]uint8{0, 4, 12}
+var _articleState_index = [...]uint8{0, 4, 12, 18}
 
 func (i articleState) String() string {
 	if i < 0 || i >= articleState(len(_articleState_index)-1) {
@@ -11,6 +11,7 @@ import (
 
 	"github.com/go-chi/chi"
 	"github.com/pkg/errors"
+	"github.com/urandom/readeef"
 	"github.com/urandom/readeef/content"
 	"github.com/urandom/readeef/content/repo"
 	"github.com/urandom/readeef/log"
@@ -87,8 +88,10 @@ func (e addFeedError) Error() string {
 
 type feedManager interface {
 	AddFeedByLink(link string) (content.Feed, error)
+	AddFeedWithSettings(link string, settings content.FeedSettings) (content.Feed, error)
 	RemoveFeed(feed content.Feed)
 	DiscoverFeeds(link string) ([]content.Feed, error)
+	RefreshFeed(feed content.Feed) (<-chan readeef.RefreshResult, error)
 }
 
 func addFeed(repo repo.Feed, feedManager feedManager) http.HandlerFunc {
@@ -100,6 +103,18 @@ func addFeed(repo repo.Feed, feedManager feedManager) http.HandlerFunc {
 
 		links := r.Form["link"]
 
+		// Private feeds may only be discovered with their credentials
+		var settings *content.FeedSettings
+		if hasFeedSettings(r.Form) {
+			s, err := feedSettingsFromForm(r.Form, content.FeedSettings{})
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+
+			settings = &s
+		}
+
 		var wait sync.WaitGroup
 		wait.Add(len(links))
 
@@ -112,7 +127,7 @@ func addFeed(repo repo.Feed, feedManager feedManager) http.HandlerFunc {
 		for i, link := range links {
 			go func(i int, link string) {
 				defer wait.Done()
-				feed, err := addFeedByURL(link, user, repo, feedManager)
+				feed, err := addFeedByURL(link, user, repo, feedManager, settings)
 				if err == nil {
 					feedResp[i].link = link
 					feedResp[i].feed = feed
@@ -147,6 +162,7 @@ func addFeedByURL(
 	user content.User,
 	repo repo.Feed,
 	feedManager feedManager,
+	settings *content.FeedSettings,
 ) (content.Feed, error) {
 	u, err := url.Parse(link)
 	if err != nil {
@@ -157,7 +173,17 @@ func addFeedByURL(
 		return content.Feed{}, addFeedError{Link: link, Message: "Link is not absolute"}
 	}
 
-	if f, err := feedManager.AddFeedByLink(link); err == nil {
+	var f content.Feed
+	if settings == nil {
+		f, err = feedManager.AddFeedByLink(link)
+	} else {
+		s := *settings
+		s.Owner = user.Login
+
+		f, err = feedManager.AddFeedWithSettings(link, s)
+	}
+
+	if err == nil {
 		err = repo.AttachTo(f, user)
 		if err != nil {
 			return content.Feed{}, addFeedError{Link: link, Title: f.Title, Message: fmt.Sprintf("adding feed to user %s: %s", user, err.Error())}
@@ -0,0 +1,115 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/urandom/readeef/content"
+	"github.com/urandom/readeef/content/repo"
+	"github.com/urandom/readeef/log"
+)
+
+const defaultFeedFetchLimit = 20
+
+type failingFeed struct {
+	ID     content.FeedID     `json:"id"`
+	Title  string             `json:"title"`
+	Link   string             `json:"link"`
+	Health content.FeedHealth `json:"health"`
+}
+
+// getFeedHealth responds with the health of the feed, and its latest fetches.
+func getFeedHealth(repo repo.FeedHealth, log log.Log) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		feed, stop := feedFromRequest(w, r)
+		if stop {
+			return
+		}
+
+		limit := defaultFeedFetchLimit
+		if v := r.Form.Get("limit"); v != "" {
+			var err error
+			if limit, err = strconv.Atoi(v); err != nil || limit <= 0 {
+				http.Error(w, "Invalid limit", http.StatusBadRequest)
+				return
+			}
+
+			if limit > content.FeedFetchHistorySize {
+				limit = content.FeedFetchHistorySize
+			}
+		}
+
+		health, err := repo.Get(feed)
+		if err != nil {
+			if content.IsNoContent(err) {
+				args{"health": nil, "history": []content.FeedFetch{}}.WriteJSON(w)
+			} else {
+				fatal(w, log, "Error getting feed health: %+v", err)
+			}
+			return
+		}
+
+		history, err := repo.History(feed, limit)
+		if err != nil {
+			fatal(w, log, "Error getting feed fetch history: %+v", err)
+			return
+		}
+
+		if history == nil {
+			history = []content.FeedFetch{}
+		}
+
+		args{"health": health, "history": history}.WriteJSON(w)
+	}
+}
+
+// listFailingFeeds responds with the feeds of the instance whose last fetch
+// has failed, starting with the ones that have been failing the longest. When
+// 'failingFor' is given, only the feeds failing for at least that long are
+// listed.
+func listFailingFeeds(feedRepo repo.Feed, healthRepo repo.FeedHealth, log log.Log) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var failingFor time.Duration
+		if v := r.Form.Get("failingFor"); v != "" {
+			var err error
+			if failingFor, err = time.ParseDuration(v); err != nil || failingFor < 0 {
+				http.Error(w, "Invalid failingFor duration", http.StatusBadRequest)
+				return
+			}
+		}
+
+		health, err := healthRepo.Failing()
+		if err != nil {
+			fatal(w, log, "Error getting failing feeds: %+v", err)
+			return
+		}
+
+		feeds, err := feedRepo.All()
+		if err != nil {
+			fatal(w, log, "Error getting feeds: %+v", err)
+			return
+		}
+
+		feedMap := make(map[content.FeedID]content.Feed, len(feeds))
+		for _, f := range feeds {
+			feedMap[f.ID] = f
+		}
+
+		failing := []failingFeed{}
+		for _, h := range health {
+			if failingFor > 0 && time.Since(h.FailingSince) < failingFor {
+				continue
+			}
+
+			feed, ok := feedMap[h.FeedID]
+			if !ok {
+				continue
+			}
+
+			failing = append(failing, failingFeed{ID: feed.ID, Title: feed.Title, Link: feed.Link, Health: h})
+		}
+
+		args{"feeds": failing}.WriteJSON(w)
+	}
+}
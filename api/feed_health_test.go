@@ -0,0 +1,175 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/pkg/errors"
+	"github.com/urandom/readeef/content"
+	"github.com/urandom/readeef/content/repo/mock_repo"
+)
+
+func Test_getFeedHealth(t *testing.T) {
+	at := time.Date(2018, 1, 2, 3, 4, 5, 0, time.UTC)
+	health := content.FeedHealth{
+		FeedID: 1, LastFetch: at, LastSuccess: at.Add(-time.Hour), FailingSince: at, Failures: 1,
+		LastStatusCode: 404, LastErrorClass: content.FetchErrorHTTP, LastError: "HTTP Status: 404",
+	}
+	history := []content.FeedFetch{
+		{FeedID: 1, Time: at, StatusCode: 404, Duration: 200 * time.Millisecond, ErrorClass: content.FetchErrorHTTP, Error: "HTTP Status: 404"},
+	}
+
+	tests := []struct {
+		name       string
+		hasFeed    bool
+		limit      string
+		wantLimit  int
+		health     content.FeedHealth
+		healthErr  error
+		history    []content.FeedFetch
+		historyErr error
+		want       string
+		code       int
+	}{
+		{name: "no feed", code: http.StatusBadRequest},
+		{name: "invalid limit", hasFeed: true, limit: "none", code: http.StatusBadRequest},
+		{name: "not fetched", hasFeed: true, healthErr: content.ErrNoContent, code: http.StatusOK,
+			want: `{"health":null,"history":[]}`},
+		{name: "health", hasFeed: true, wantLimit: defaultFeedFetchLimit, health: health, history: history, code: http.StatusOK,
+			want: `{"health":{"feedID":1,"lastFetch":"2018-01-02T03:04:05Z","lastSuccess":"2018-01-02T02:04:05Z","failingSince":"2018-01-02T03:04:05Z","failures":1,"lastStatusCode":404,"lastErrorClass":"http","lastError":"HTTP Status: 404"},` +
+				`"history":[{"time":"2018-01-02T03:04:05Z","statusCode":404,"bytes":0,"newArticles":0,"errorClass":"http","error":"HTTP Status: 404","duration":200}]}`},
+		{name: "capped limit", hasFeed: true, limit: "1000", wantLimit: content.FeedFetchHistorySize, health: content.FeedHealth{FeedID: 1}, code: http.StatusOK,
+			want: `{"health":{"feedID":1,"lastFetch":"0001-01-01T00:00:00Z","lastSuccess":"0001-01-01T00:00:00Z","failingSince":"0001-01-01T00:00:00Z","failures":0,"lastStatusCode":0},"history":[]}`},
+		{name: "health err", hasFeed: true, healthErr: errors.New("err"), code: http.StatusInternalServerError},
+		{name: "history err", hasFeed: true, wantLimit: defaultFeedFetchLimit, health: health, historyErr: errors.New("err"), code: http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			healthRepo := mock_repo.NewMockFeedHealth(ctrl)
+
+			r := httptest.NewRequest("GET", "/?limit="+tt.limit, nil)
+			r.ParseForm()
+			w := httptest.NewRecorder()
+
+			if tt.hasFeed {
+				feed := content.Feed{ID: 1}
+				r = r.WithContext(context.WithValue(r.Context(), feedKey, feed))
+
+				if tt.health.FeedID != 0 || tt.healthErr != nil {
+					healthRepo.EXPECT().Get(feed).Return(tt.health, tt.healthErr)
+				}
+
+				if tt.wantLimit > 0 {
+					healthRepo.EXPECT().History(feed, tt.wantLimit).Return(tt.history, tt.historyErr)
+				}
+			}
+
+			getFeedHealth(healthRepo, logger).ServeHTTP(w, r)
+
+			if w.Code != tt.code {
+				t.Errorf("getFeedHealth() code = %v, want %v", w.Code, tt.code)
+				return
+			}
+
+			if tt.code == http.StatusOK {
+				if got := strings.TrimSpace(w.Body.String()); got != tt.want {
+					t.Errorf("getFeedHealth() body = %s, want %s", got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func Test_listFailingFeeds(t *testing.T) {
+	now := time.Now()
+	feeds := []content.Feed{
+		{ID: 1, Title: "Feed 1", Link: "http://example.com/1"},
+		{ID: 2, Title: "Feed 2", Link: "http://example.com/2"},
+	}
+	health := []content.FeedHealth{
+		{FeedID: 2, FailingSince: now.Add(-72 * time.Hour), Failures: 30},
+		{FeedID: 1, FailingSince: now.Add(-time.Hour), Failures: 1},
+		// The feed has been deleted since
+		{FeedID: 3, FailingSince: now.Add(-time.Hour), Failures: 1},
+	}
+
+	tests := []struct {
+		name       string
+		failingFor string
+		healthErr  error
+		feedsErr   error
+		want       []content.FeedID
+		code       int
+	}{
+		{name: "all", want: []content.FeedID{2, 1}, code: http.StatusOK},
+		{name: "failing for", failingFor: "24h", want: []content.FeedID{2}, code: http.StatusOK},
+		{name: "none failing for", failingFor: "100h", want: []content.FeedID{}, code: http.StatusOK},
+		{name: "invalid failing for", failingFor: "long", code: http.StatusBadRequest},
+		{name: "health err", healthErr: errors.New("err"), code: http.StatusInternalServerError},
+		{name: "feeds err", feedsErr: errors.New("err"), code: http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			feedRepo := mock_repo.NewMockFeed(ctrl)
+			healthRepo := mock_repo.NewMockFeedHealth(ctrl)
+
+			r := httptest.NewRequest("GET", "/?failingFor="+tt.failingFor, nil)
+			r.ParseForm()
+			w := httptest.NewRecorder()
+
+			if tt.code != http.StatusBadRequest {
+				healthRepo.EXPECT().Failing().Return(health, tt.healthErr)
+
+				if tt.healthErr == nil {
+					feedRepo.EXPECT().All().Return(feeds, tt.feedsErr)
+				}
+			}
+
+			listFailingFeeds(feedRepo, healthRepo, logger).ServeHTTP(w, r)
+
+			if w.Code != tt.code {
+				t.Errorf("listFailingFeeds() code = %v, want %v", w.Code, tt.code)
+				return
+			}
+
+			if tt.code != http.StatusOK {
+				return
+			}
+
+			var body struct {
+				Feeds []failingFeed `json:"feeds"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Fatalf("listFailingFeeds() decode error = %v", err)
+			}
+
+			got := []content.FeedID{}
+			for _, f := range body.Feeds {
+				got = append(got, f.ID)
+				if f.Title == "" || f.Health.FeedID != f.ID {
+					t.Errorf("listFailingFeeds() feed = %#v", f)
+				}
+			}
+
+			if len(got) != len(tt.want) {
+				t.Fatalf("listFailingFeeds() feeds = %v, want %v", got, tt.want)
+			}
+
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("listFailingFeeds() feeds = %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
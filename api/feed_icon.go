@@ -0,0 +1,69 @@
+package api
+
+import (
+	"bytes"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi"
+	"github.com/urandom/readeef/content"
+	"github.com/urandom/readeef/content/repo"
+	"github.com/urandom/readeef/log"
+)
+
+type iconCache interface {
+	Get(feed content.Feed) (content.FeedImage, error)
+}
+
+func getFeedIcon(icons iconCache, log log.Log) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		feed, stop := feedFromRequest(w, r)
+		if stop {
+			return
+		}
+
+		serveFeedIcon(w, r, feed, icons, log)
+	}
+}
+
+// feedIconByID serves feed icons without a user context, for clients that
+// fetch them as static resources, such as the TT-RSS ones.
+func feedIconByID(repo repo.Feed, icons iconCache, log log.Log) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := strconv.ParseInt(chi.URLParam(r, "feedID"), 10, 64)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		feed, err := repo.Get(content.FeedID(id), content.User{})
+		if err != nil {
+			if content.IsNoContent(err) {
+				http.Error(w, "Not found", http.StatusNotFound)
+			} else {
+				fatal(w, log, "Error getting feed: %+v", err)
+			}
+			return
+		}
+
+		serveFeedIcon(w, r, feed, icons, log)
+	}
+}
+
+func serveFeedIcon(w http.ResponseWriter, r *http.Request, feed content.Feed, icons iconCache, log log.Log) {
+	image, err := icons.Get(feed)
+	if err != nil {
+		fatal(w, log, "Error getting feed icon: %+v", err)
+		return
+	}
+
+	if len(image.Icon) == 0 {
+		http.Error(w, "Not found", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", image.IconType)
+	w.Header().Set("Cache-Control", "private, max-age=86400")
+
+	http.ServeContent(w, r, "", image.FetchDate, bytes.NewReader(image.Icon))
+}
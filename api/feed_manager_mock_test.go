@@ -8,6 +8,7 @@ import (
 	reflect "reflect"
 
 	gomock "github.com/golang/mock/gomock"
+	readeef "github.com/urandom/readeef"
 	content "github.com/urandom/readeef/content"
 )
 
@@ -57,6 +58,19 @@ func (mr *MockfeedManagerMockRecorder) RemoveFeed(feed interface{}) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveFeed", reflect.TypeOf((*MockfeedManager)(nil).RemoveFeed), feed)
 }
 
+// AddFeedWithSettings mocks base method
+func (m *MockfeedManager) AddFeedWithSettings(link string, settings content.FeedSettings) (content.Feed, error) {
+	ret := m.ctrl.Call(m, "AddFeedWithSettings", link, settings)
+	ret0, _ := ret[0].(content.Feed)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddFeedWithSettings indicates an expected call of AddFeedWithSettings
+func (mr *MockfeedManagerMockRecorder) AddFeedWithSettings(link, settings interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddFeedWithSettings", reflect.TypeOf((*MockfeedManager)(nil).AddFeedWithSettings), link, settings)
+}
+
 // DiscoverFeeds mocks base method
 func (m *MockfeedManager) DiscoverFeeds(link string) ([]content.Feed, error) {
 	ret := m.ctrl.Call(m, "DiscoverFeeds", link)
@@ -69,3 +83,16 @@ func (m *MockfeedManager) DiscoverFeeds(link string) ([]content.Feed, error) {
 func (mr *MockfeedManagerMockRecorder) DiscoverFeeds(link interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DiscoverFeeds", reflect.TypeOf((*MockfeedManager)(nil).DiscoverFeeds), link)
 }
+
+// RefreshFeed mocks base method
+func (m *MockfeedManager) RefreshFeed(feed content.Feed) (<-chan readeef.RefreshResult, error) {
+	ret := m.ctrl.Call(m, "RefreshFeed", feed)
+	ret0, _ := ret[0].(<-chan readeef.RefreshResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RefreshFeed indicates an expected call of RefreshFeed
+func (mr *MockfeedManagerMockRecorder) RefreshFeed(feed interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RefreshFeed", reflect.TypeOf((*MockfeedManager)(nil).RefreshFeed), feed)
+}
@@ -0,0 +1,126 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/urandom/readeef"
+	"github.com/urandom/readeef/content"
+	"github.com/urandom/readeef/content/repo"
+	"github.com/urandom/readeef/content/repo/eventable"
+	"github.com/urandom/readeef/log"
+)
+
+// The longest time a feed refresh request waits for the outcome, which is
+// sent as an event in any case.
+const refreshWait = 20 * time.Second
+
+// refreshTimeout is the longest time the outcome of a refresh is waited
+// for, after which the refresh is reported as failed.
+var refreshTimeout = 10 * time.Minute
+
+var errRefreshTimeout = errors.New("Timed out waiting for the feed refresh")
+
+type eventDispatcher interface {
+	Dispatch(name string, data interface{})
+}
+
+// refreshFeed updates the feed right away, and responds with the outcome of
+// the update, unless it takes too long.
+func refreshFeed(feedManager feedManager, dispatcher eventDispatcher, log log.Log) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user, stop := userFromRequest(w, r)
+		if stop {
+			return
+		}
+
+		feed, stop := feedFromRequest(w, r)
+		if stop {
+			return
+		}
+
+		results, err := feedManager.RefreshFeed(feed)
+		if err != nil {
+			if err == readeef.ErrNotScheduled {
+				http.Error(w, err.Error(), http.StatusConflict)
+			} else {
+				fatal(w, log, "Error refreshing feed: %+v", err)
+			}
+			return
+		}
+
+		select {
+		case data := <-dispatchRefresh(user, feed, results, dispatcher):
+			args{"refresh": data}.WriteJSON(w)
+		case <-time.After(refreshWait):
+			args{"refresh": nil, "pending": true}.WriteJSON(w)
+		case <-r.Context().Done():
+		}
+	}
+}
+
+// refreshFeeds updates all the feeds of the user right away. The outcome of
+// each update is sent as an event.
+func refreshFeeds(repo repo.Feed, feedManager feedManager, dispatcher eventDispatcher, log log.Log) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user, stop := userFromRequest(w, r)
+		if stop {
+			return
+		}
+
+		feeds, err := repo.ForUser(user)
+		if err != nil {
+			fatal(w, log, "Error getting user feeds: %+v", err)
+			return
+		}
+
+		ids := make([]content.FeedID, 0, len(feeds))
+		for _, feed := range feeds {
+			results, err := feedManager.RefreshFeed(feed)
+			if err != nil {
+				log.Printf("Error refreshing feed %s: %+v", feed, err)
+				continue
+			}
+
+			dispatchRefresh(user, feed, results, dispatcher)
+			ids = append(ids, feed.ID)
+		}
+
+		args{"feedIDs": ids}.WriteJSON(w)
+	}
+}
+
+// dispatchRefresh sends the outcome of a refresh as an event to the user
+// once it arrives, and then to the returned channel. A refresh whose outcome
+// doesn't arrive in time is reported as failed.
+func dispatchRefresh(
+	user content.User,
+	feed content.Feed,
+	results <-chan readeef.RefreshResult,
+	dispatcher eventDispatcher,
+) <-chan eventable.FeedRefreshData {
+	ret := make(chan eventable.FeedRefreshData, 1)
+
+	go func() {
+		var result readeef.RefreshResult
+
+		select {
+		case result = <-results:
+		case <-time.After(refreshTimeout):
+			result = readeef.RefreshResult{Feed: feed, Err: errRefreshTimeout}
+		}
+
+		data := eventable.FeedRefreshData{
+			User: user.Login, FeedID: result.Feed.ID, NewArticles: result.NewArticles,
+		}
+		if result.Err != nil {
+			data.Error = result.Err.Error()
+		}
+
+		dispatcher.Dispatch(eventable.FeedRefreshEvent, data)
+		ret <- data
+	}()
+
+	return ret
+}
@@ -0,0 +1,171 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/pkg/errors"
+	"github.com/urandom/readeef"
+	"github.com/urandom/readeef/content"
+	"github.com/urandom/readeef/content/repo/eventable"
+	"github.com/urandom/readeef/content/repo/mock_repo"
+)
+
+type testDispatcher struct {
+	mu     sync.Mutex
+	events []eventable.Event
+	done   chan struct{}
+}
+
+func (d *testDispatcher) Dispatch(name string, data interface{}) {
+	d.mu.Lock()
+	d.events = append(d.events, eventable.Event{Name: name, Data: data})
+	d.mu.Unlock()
+
+	if d.done != nil {
+		d.done <- struct{}{}
+	}
+}
+
+func refreshResults(result readeef.RefreshResult) <-chan readeef.RefreshResult {
+	ret := make(chan readeef.RefreshResult, 1)
+	ret <- result
+
+	return ret
+}
+
+func Test_refreshFeed(t *testing.T) {
+	user := content.User{Login: "user1"}
+	feed := content.Feed{ID: 1}
+
+	tests := []struct {
+		name       string
+		result     readeef.RefreshResult
+		refreshErr error
+		want       string
+		code       int
+	}{
+		{name: "new articles", result: readeef.RefreshResult{Feed: feed, NewArticles: 3}, code: http.StatusOK,
+			want: `{"refresh":{"user":"user1","feedID":1,"newArticles":3}}`},
+		{name: "update error", result: readeef.RefreshResult{Feed: feed, Err: errors.New("HTTP Status: 404")}, code: http.StatusOK,
+			want: `{"refresh":{"user":"user1","feedID":1,"newArticles":0,"error":"HTTP Status: 404"}}`},
+		{name: "not scheduled", refreshErr: readeef.ErrNotScheduled, code: http.StatusConflict},
+		{name: "refresh err", refreshErr: errors.New("err"), code: http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			feedManager := NewMockfeedManager(ctrl)
+			dispatcher := &testDispatcher{}
+
+			r := httptest.NewRequest("POST", "/", nil)
+			r = r.WithContext(context.WithValue(r.Context(), userKey, user))
+			r = r.WithContext(context.WithValue(r.Context(), feedKey, feed))
+			w := httptest.NewRecorder()
+
+			if tt.refreshErr == nil {
+				feedManager.EXPECT().RefreshFeed(feed).Return(refreshResults(tt.result), nil)
+			} else {
+				feedManager.EXPECT().RefreshFeed(feed).Return(nil, tt.refreshErr)
+			}
+
+			refreshFeed(feedManager, dispatcher, logger).ServeHTTP(w, r)
+
+			if w.Code != tt.code {
+				t.Errorf("refreshFeed() code = %v, want %v", w.Code, tt.code)
+				return
+			}
+
+			if tt.code != http.StatusOK {
+				return
+			}
+
+			if got := strings.TrimSpace(w.Body.String()); got != tt.want {
+				t.Errorf("refreshFeed() body = %s, want %s", got, tt.want)
+			}
+
+			if len(dispatcher.events) != 1 || dispatcher.events[0].Name != eventable.FeedRefreshEvent {
+				t.Errorf("refreshFeed() events = %#v", dispatcher.events)
+			}
+		})
+	}
+}
+
+func Test_refreshFeeds(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	feedRepo := mock_repo.NewMockFeed(ctrl)
+	feedManager := NewMockfeedManager(ctrl)
+	dispatcher := &testDispatcher{done: make(chan struct{})}
+
+	user := content.User{Login: "user1"}
+	feeds := []content.Feed{{ID: 1}, {ID: 2}, {ID: 3}}
+
+	feedRepo.EXPECT().ForUser(user).Return(feeds, nil)
+	feedManager.EXPECT().RefreshFeed(feeds[0]).Return(refreshResults(readeef.RefreshResult{Feed: feeds[0], NewArticles: 1}), nil)
+	feedManager.EXPECT().RefreshFeed(feeds[1]).Return(nil, readeef.ErrNotScheduled)
+	feedManager.EXPECT().RefreshFeed(feeds[2]).Return(refreshResults(readeef.RefreshResult{Feed: feeds[2]}), nil)
+
+	r := httptest.NewRequest("POST", "/", nil)
+	r = r.WithContext(context.WithValue(r.Context(), userKey, user))
+	w := httptest.NewRecorder()
+
+	refreshFeeds(feedRepo, feedManager, dispatcher, logger).ServeHTTP(w, r)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("refreshFeeds() code = %v, want %v", w.Code, http.StatusOK)
+	}
+
+	if got, want := strings.TrimSpace(w.Body.String()), `{"feedIDs":[1,3]}`; got != want {
+		t.Errorf("refreshFeeds() body = %s, want %s", got, want)
+	}
+
+	// The outcomes arrive as events
+	<-dispatcher.done
+	<-dispatcher.done
+
+	dispatcher.mu.Lock()
+	defer dispatcher.mu.Unlock()
+
+	got := map[content.FeedID]int{}
+	for _, e := range dispatcher.events {
+		data := e.Data.(eventable.FeedRefreshData)
+		if data.User != user.Login {
+			t.Errorf("refreshFeeds() event user = %s", data.User)
+		}
+		got[data.FeedID] = data.NewArticles
+	}
+
+	if len(got) != 2 || got[1] != 1 || got[3] != 0 {
+		t.Errorf("refreshFeeds() events = %#v", dispatcher.events)
+	}
+}
+
+func Test_dispatchRefresh_timeout(t *testing.T) {
+	defer func(timeout time.Duration) { refreshTimeout = timeout }(refreshTimeout)
+	refreshTimeout = 10 * time.Millisecond
+
+	dispatcher := &testDispatcher{}
+	user := content.User{Login: "user1"}
+	feed := content.Feed{ID: 1}
+
+	select {
+	case data := <-dispatchRefresh(user, feed, make(chan readeef.RefreshResult), dispatcher):
+		if data.FeedID != feed.ID || data.Error != errRefreshTimeout.Error() {
+			t.Errorf("dispatchRefresh() = %#v", data)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("dispatchRefresh() did not time out")
+	}
+
+	if len(dispatcher.events) != 1 || dispatcher.events[0].Name != eventable.FeedRefreshEvent {
+		t.Errorf("dispatchRefresh() events = %#v", dispatcher.events)
+	}
+}
@@ -0,0 +1,179 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/urandom/readeef/content"
+	"github.com/urandom/readeef/content/repo"
+	"github.com/urandom/readeef/log"
+)
+
+var feedSettingsParams = []string{
+	"updateInterval", "paused", "userAgent", "header",
+	"username", "password", "cookie", "extractContent",
+}
+
+// getFeedSettings returns the settings of the feed. The users that don't own
+// them don't see which credentials and headers are used.
+func getFeedSettings(repo repo.FeedSettings, log log.Log) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user, stop := userFromRequest(w, r)
+		if stop {
+			return
+		}
+
+		feed, stop := feedFromRequest(w, r)
+		if stop {
+			return
+		}
+
+		settings, err := repo.Get(feed)
+		if err != nil && !content.IsNoContent(err) {
+			fatal(w, log, "Error getting feed settings: %+v", err)
+			return
+		}
+
+		if settings.Owner != user.Login {
+			settings.Username, settings.Headers = "", nil
+		}
+
+		args{"settings": settings, "editable": settings.EditableBy(user.Login)}.WriteJSON(w)
+	}
+}
+
+// updateFeedSettings changes the settings given in the request, keeping the
+// rest of them. Only the owner of the settings may change them, and
+// credentials may not be set on a feed shared with other users.
+func updateFeedSettings(feedRepo repo.Feed, repo repo.FeedSettings, log log.Log) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user, stop := userFromRequest(w, r)
+		if stop {
+			return
+		}
+
+		feed, stop := feedFromRequest(w, r)
+		if stop {
+			return
+		}
+
+		settings, err := repo.Get(feed)
+		if err != nil && !content.IsNoContent(err) {
+			fatal(w, log, "Error getting feed settings: %+v", err)
+			return
+		}
+
+		if !settings.EditableBy(user.Login) {
+			http.Error(w, "Feed settings are owned by another user", http.StatusForbidden)
+			return
+		}
+		settings.FeedID, settings.Owner = feed.ID, user.Login
+
+		if settings, err = feedSettingsFromForm(r.Form, settings); err == nil {
+			err = settings.Validate()
+		}
+
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		if settings.Private() {
+			users, err := feedRepo.Users(feed)
+			if err != nil {
+				fatal(w, log, "Error getting feed users: %+v", err)
+				return
+			}
+
+			for _, u := range users {
+				if u.Login != user.Login {
+					http.Error(w, "Feed credentials cannot be set on a feed shared with other users", http.StatusConflict)
+					return
+				}
+			}
+		}
+
+		if err := repo.Update(settings); err != nil {
+			fatal(w, log, "Error updating feed settings: %+v", err)
+			return
+		}
+
+		args{"settings": settings, "editable": true}.WriteJSON(w)
+	}
+}
+
+// hasFeedSettings reports whether any of the feed settings are present in
+// the form.
+func hasFeedSettings(form url.Values) bool {
+	for _, p := range feedSettingsParams {
+		if _, ok := form[p]; ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// feedSettingsFromForm overrides the settings with the ones present in the
+// form. An empty 'updateInterval' restores the adaptive interval, while
+// each 'header' is given as 'Name: value', replacing the existing headers.
+func feedSettingsFromForm(form url.Values, settings content.FeedSettings) (content.FeedSettings, error) {
+	if _, ok := form["updateInterval"]; ok {
+		settings.UpdateInterval = 0
+
+		if v := form.Get("updateInterval"); v != "" {
+			d, err := time.ParseDuration(v)
+			if err != nil {
+				return settings, fmt.Errorf("Invalid updateInterval: %s", v)
+			}
+
+			settings.UpdateInterval = d
+		}
+	}
+
+	if _, ok := form["header"]; ok {
+		settings.Headers = nil
+
+		for _, h := range form["header"] {
+			if h == "" {
+				continue
+			}
+
+			parts := strings.SplitN(h, ":", 2)
+			if len(parts) != 2 {
+				return settings, fmt.Errorf("Invalid header: %s", h)
+			}
+
+			if settings.Headers == nil {
+				settings.Headers = content.FeedHeaders{}
+			}
+
+			settings.Headers[strings.TrimSpace(parts[0])] = strings.TrimSpace(parts[1])
+		}
+	}
+
+	for name, value := range map[string]*string{
+		"userAgent": &settings.UserAgent,
+		"username":  &settings.Username,
+		"password":  &settings.Password,
+		"cookie":    &settings.Cookie,
+	} {
+		if _, ok := form[name]; ok {
+			*value = form.Get(name)
+		}
+	}
+
+	for name, value := range map[string]*bool{
+		"paused":         &settings.Paused,
+		"extractContent": &settings.ExtractContent,
+	} {
+		if _, ok := form[name]; ok {
+			*value = form.Get(name) == "true"
+		}
+	}
+
+	return settings, nil
+}
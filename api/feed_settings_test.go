@@ -0,0 +1,143 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/pkg/errors"
+	"github.com/urandom/readeef/content"
+	"github.com/urandom/readeef/content/repo/mock_repo"
+)
+
+func Test_getFeedSettings(t *testing.T) {
+	owned := content.FeedSettings{FeedID: 1, Owner: "user1", UpdateInterval: time.Hour, Username: "user", Password: "pass", Headers: content.FeedHeaders{"X-Token": "abcd"}}
+
+	tests := []struct {
+		name        string
+		noUser      bool
+		hasFeed     bool
+		settings    content.FeedSettings
+		settingsErr error
+		want        string
+		code        int
+	}{
+		{name: "no user", noUser: true, code: http.StatusBadRequest},
+		{name: "no feed", code: http.StatusBadRequest},
+		{name: "defaults", hasFeed: true, settingsErr: content.ErrNoContent, code: http.StatusOK,
+			want: `{"editable":true,"settings":{"paused":false,"userAgent":"","username":"","extractContent":false,"updateInterval":"","headerNames":[],"hasPassword":false,"hasCookie":false}}`},
+		{name: "owner", hasFeed: true, settings: owned, code: http.StatusOK,
+			want: `{"editable":true,"settings":{"paused":false,"userAgent":"","username":"user","extractContent":false,"updateInterval":"1h0m0s","headerNames":["X-Token"],"hasPassword":true,"hasCookie":false}}`},
+		{name: "other user", hasFeed: true, settings: content.FeedSettings{FeedID: 1, Owner: "user2", Username: "user", Password: "pass", Headers: content.FeedHeaders{"X-Token": "abcd"}}, code: http.StatusOK,
+			want: `{"editable":false,"settings":{"paused":false,"userAgent":"","username":"","extractContent":false,"updateInterval":"","headerNames":[],"hasPassword":true,"hasCookie":false}}`},
+		{name: "settings err", hasFeed: true, settingsErr: errors.New("err"), code: http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			settingsRepo := mock_repo.NewMockFeedSettings(ctrl)
+
+			r := httptest.NewRequest("GET", "/", nil)
+			w := httptest.NewRecorder()
+
+			if !tt.noUser {
+				r = r.WithContext(context.WithValue(r.Context(), userKey, content.User{Login: "user1"}))
+			}
+
+			if tt.hasFeed {
+				feed := content.Feed{ID: 1}
+				r = r.WithContext(context.WithValue(r.Context(), feedKey, feed))
+				settingsRepo.EXPECT().Get(feed).Return(tt.settings, tt.settingsErr)
+			}
+
+			getFeedSettings(settingsRepo, logger).ServeHTTP(w, r)
+
+			if w.Code != tt.code {
+				t.Errorf("getFeedSettings() code = %v, want %v", w.Code, tt.code)
+				return
+			}
+
+			if tt.code == http.StatusOK {
+				if got := strings.TrimSpace(w.Body.String()); got != tt.want {
+					t.Errorf("getFeedSettings() body = %s, want %s", got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func Test_updateFeedSettings(t *testing.T) {
+	existing := content.FeedSettings{FeedID: 1, Owner: "user1", UserAgent: "agent", Username: "user", Password: "pass", Headers: content.FeedHeaders{"X-Old": "1"}}
+	alone := []content.User{{Login: "user1"}}
+	shared := []content.User{{Login: "user1"}, {Login: "user2"}}
+
+	tests := []struct {
+		name      string
+		form      url.Values
+		settings  content.FeedSettings
+		users     []content.User
+		want      content.FeedSettings
+		updateErr error
+		code      int
+	}{
+		{name: "new", form: url.Values{"updateInterval": {"2h"}, "paused": {"true"}, "header": {"X-Token: abcd", "Accept: application/atom+xml"}}, users: alone,
+			want: content.FeedSettings{FeedID: 1, Owner: "user1", UpdateInterval: 2 * time.Hour, Paused: true, Headers: content.FeedHeaders{"X-Token": "abcd", "Accept": "application/atom+xml"}}, code: http.StatusOK},
+		{name: "partial", form: url.Values{"cookie": {"session=abcd"}, "extractContent": {"true"}}, settings: existing, users: alone,
+			want: content.FeedSettings{FeedID: 1, Owner: "user1", UserAgent: "agent", Username: "user", Password: "pass", Headers: content.FeedHeaders{"X-Old": "1"}, Cookie: "session=abcd", ExtractContent: true}, code: http.StatusOK},
+		{name: "clear", form: url.Values{"updateInterval": {""}, "header": {""}, "username": {""}, "password": {""}}, settings: existing,
+			want: content.FeedSettings{FeedID: 1, Owner: "user1", UserAgent: "agent"}, code: http.StatusOK},
+		{name: "shared public", form: url.Values{"paused": {"true"}}, settings: content.FeedSettings{FeedID: 1, UserAgent: "agent"},
+			want: content.FeedSettings{FeedID: 1, Owner: "user1", UserAgent: "agent", Paused: true}, code: http.StatusOK},
+		{name: "shared private", form: url.Values{"cookie": {"session=abcd"}}, users: shared, code: http.StatusConflict},
+		{name: "other owner", form: url.Values{"paused": {"true"}}, settings: content.FeedSettings{FeedID: 1, Owner: "user2"}, code: http.StatusForbidden},
+		{name: "invalid interval", form: url.Values{"updateInterval": {"often"}}, code: http.StatusBadRequest},
+		{name: "short interval", form: url.Values{"updateInterval": {"1s"}}, code: http.StatusBadRequest},
+		{name: "invalid header", form: url.Values{"header": {"X-Token"}}, code: http.StatusBadRequest},
+		{name: "update err", form: url.Values{"paused": {"true"}}, want: content.FeedSettings{FeedID: 1, Owner: "user1", Paused: true}, updateErr: errors.New("err"), code: http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			feedRepo := mock_repo.NewMockFeed(ctrl)
+			settingsRepo := mock_repo.NewMockFeedSettings(ctrl)
+
+			r := httptest.NewRequest("PUT", "/", strings.NewReader(tt.form.Encode()))
+			r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			r.ParseForm()
+			w := httptest.NewRecorder()
+
+			feed := content.Feed{ID: 1}
+			r = r.WithContext(context.WithValue(r.Context(), userKey, content.User{Login: "user1"}))
+			r = r.WithContext(context.WithValue(r.Context(), feedKey, feed))
+
+			var settingsErr error
+			if tt.settings.FeedID == 0 {
+				settingsErr = content.ErrNoContent
+			}
+			settingsRepo.EXPECT().Get(feed).Return(tt.settings, settingsErr)
+
+			if tt.users != nil {
+				feedRepo.EXPECT().Users(feed).Return(tt.users, nil)
+			}
+
+			switch tt.code {
+			case http.StatusBadRequest, http.StatusForbidden, http.StatusConflict:
+			default:
+				settingsRepo.EXPECT().Update(tt.want).Return(tt.updateErr)
+			}
+
+			updateFeedSettings(feedRepo, settingsRepo, logger).ServeHTTP(w, r)
+
+			if w.Code != tt.code {
+				t.Errorf("updateFeedSettings() code = %v, want %v, body = %s", w.Code, tt.code, w.Body)
+			}
+		})
+	}
+}
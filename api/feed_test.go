@@ -1,6 +1,7 @@
 package api
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"net/http"
@@ -10,6 +11,7 @@ import (
 	"strconv"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
 	"github.com/pkg/errors"
@@ -164,6 +166,7 @@ func Test_addFeed(t *testing.T) {
 		addedFeed    []content.Feed
 		addedFeedErr []error
 		attachErr    []error
+		settings     *content.FeedSettings
 	}{
 		{name: "no user", noUser: true},
 		{name: "no links"},
@@ -181,6 +184,14 @@ func Test_addFeed(t *testing.T) {
 			addedFeedErr: []error{nil, errors.New("err")},
 			attachErr:    []error{nil},
 		},
+		{
+			name:         "private feed",
+			form:         url.Values{"link": []string{"http://example.com"}, "username": []string{"user"}, "password": []string{"pass"}},
+			addedFeed:    []content.Feed{{ID: 1, Link: "http://example.com"}},
+			addedFeedErr: []error{nil},
+			attachErr:    []error{nil},
+			settings:     &content.FeedSettings{Owner: "test", Username: "user", Password: "pass"},
+		},
 	}
 
 	type addErr struct {
@@ -221,7 +232,11 @@ func Test_addFeed(t *testing.T) {
 				want.Errors = []addErr{}
 				want.Feeds = map[string]content.Feed{}
 				for i, link := range tt.form["link"] {
-					feedManager.EXPECT().AddFeedByLink(link).Return(tt.addedFeed[i], tt.addedFeedErr[i])
+					if tt.settings == nil {
+						feedManager.EXPECT().AddFeedByLink(link).Return(tt.addedFeed[i], tt.addedFeedErr[i])
+					} else {
+						feedManager.EXPECT().AddFeedWithSettings(link, *tt.settings).Return(tt.addedFeed[i], tt.addedFeedErr[i])
+					}
 
 					if tt.addedFeedErr[i] != nil {
 						want.Errors = append(want.Errors, addErr{Link: link, Error: "adding feed to the database: " + tt.addedFeedErr[i].Error()})
@@ -431,3 +446,55 @@ func Test_discoverFeeds(t *testing.T) {
 		})
 	}
 }
+
+func Test_getFeedIcon(t *testing.T) {
+	tests := []struct {
+		name    string
+		noFeed  bool
+		image   content.FeedImage
+		iconErr error
+		code    int
+	}{
+		{name: "no feed", noFeed: true, code: http.StatusBadRequest},
+		{name: "icon err", iconErr: errors.New("err"), code: http.StatusInternalServerError},
+		{name: "no icon", image: content.FeedImage{FeedID: 1, FetchDate: time.Now()}, code: http.StatusNotFound},
+		{name: "success", image: content.FeedImage{FeedID: 1, Icon: []byte("icon"), IconType: "image/png", FetchDate: time.Now()}, code: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			icons := NewMockiconCache(ctrl)
+
+			r := httptest.NewRequest("GET", "/", nil)
+			w := httptest.NewRecorder()
+
+			if !tt.noFeed {
+				feed := content.Feed{ID: 1, Link: "http://example.com"}
+				r = r.WithContext(context.WithValue(r.Context(), feedKey, feed))
+
+				icons.EXPECT().Get(feed).Return(tt.image, tt.iconErr)
+			}
+
+			getFeedIcon(icons, logger).ServeHTTP(w, r)
+
+			if w.Code != tt.code {
+				t.Errorf("getFeedIcon() code = %v, want %v", w.Code, tt.code)
+				return
+			}
+
+			if tt.code != http.StatusOK {
+				return
+			}
+
+			if ct := w.Header().Get("Content-Type"); ct != tt.image.IconType {
+				t.Errorf("getFeedIcon() content type = %q, want %q", ct, tt.image.IconType)
+			}
+
+			if !bytes.Equal(w.Body.Bytes(), tt.image.Icon) {
+				t.Errorf("getFeedIcon() body = %q, want %q", w.Body.Bytes(), tt.image.Icon)
+			}
+		})
+	}
+}
@@ -3,7 +3,6 @@ package fever
 import (
 	"encoding/base64"
 	"net/http"
-	"sync"
 
 	"github.com/pkg/errors"
 	"github.com/urandom/readeef/content"
@@ -17,16 +16,12 @@ type favicon struct {
 	Data string         `json:"data"`
 }
 
-var (
-	faviconCache   = map[content.FeedID]string{}
-	faviconCacheMu sync.RWMutex
-)
-
 func favicons(
 	r *http.Request,
 	resp resp,
 	user content.User,
 	service repo.Service,
+	icons rffeed.IconCache,
 	log log.Log,
 ) error {
 	log.Infoln("Fetching fever feeds favicons")
@@ -39,27 +34,17 @@ func favicons(
 	}
 
 	for _, f := range feeds {
-		faviconCacheMu.RLock()
-		data := faviconCache[f.ID]
-		faviconCacheMu.RUnlock()
-
-		if data != "" {
-			favicons = append(favicons, favicon{f.ID, data})
+		image, err := icons.Get(f)
+		if err != nil {
+			log.Printf("Error getting icon for feed %s: %+v", f, err)
 			continue
 		}
 
-		log.Debugf("Getting favicon for %q", f.SiteLink)
-		b, ct, err := rffeed.Favicon(f.SiteLink)
-		if err != nil {
-			log.Printf("Error getting favicon for %q: %v", f.SiteLink, err)
+		if len(image.Icon) == 0 {
 			continue
 		}
 
-		data = ct + ";base64," + base64.StdEncoding.EncodeToString(b)
-		faviconCacheMu.Lock()
-		faviconCache[f.ID] = data
-		faviconCacheMu.Unlock()
-
+		data := image.IconType + ";base64," + base64.StdEncoding.EncodeToString(image.Icon)
 		favicons = append(favicons, favicon{f.ID, data})
 	}
 
@@ -68,6 +53,8 @@ func favicons(
 	return nil
 }
 
-func init() {
-	actions["favicons"] = favicons
+func registerFaviconActions(icons rffeed.IconCache) {
+	actions["favicons"] = func(r *http.Request, resp resp, user content.User, service repo.Service, log log.Log) error {
+		return favicons(r, resp, user, service, icons, log)
+	}
 }
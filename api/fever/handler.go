@@ -8,6 +8,7 @@ import (
 	"github.com/urandom/readeef/content"
 	"github.com/urandom/readeef/content/processor"
 	"github.com/urandom/readeef/content/repo"
+	rffeed "github.com/urandom/readeef/feed"
 	"github.com/urandom/readeef/log"
 )
 
@@ -25,12 +26,14 @@ var (
 
 func Handler(
 	service repo.Service,
+	icons rffeed.IconCache,
 	processors []processor.Article,
 	log log.Log,
 ) http.HandlerFunc {
 
 	processors = filterProcessors(processors)
 
+	registerFaviconActions(icons)
 	registerItemActions(processors)
 	registerLinkActions(processors)
 
@@ -3,6 +3,8 @@ package fever
 import (
 	"net/http"
 	"strconv"
+	"sync"
+	"time"
 
 	"github.com/pkg/errors"
 	"github.com/urandom/readeef/content"
@@ -11,6 +13,34 @@ import (
 	"github.com/urandom/readeef/pool"
 )
 
+// idCache keeps the unread and saved item ids of each user, along with the
+// article change log cursor they were fetched at. The ids are reused as
+// long as the user has no newer changes. Since some changes, like
+// unsubscribing from a feed, are not logged, the ids also expire.
+type idCache struct {
+	sync.Mutex
+	entries map[idCacheKey]idCacheEntry
+}
+
+type idCacheKey struct {
+	login content.Login
+	name  string
+}
+
+type idCacheEntry struct {
+	ids     string
+	cursor  content.ChangeID
+	fetched time.Time
+}
+
+const (
+	idCacheExpiry = 15 * time.Minute
+)
+
+var (
+	itemIDCache = idCache{entries: map[idCacheKey]idCacheEntry{}}
+)
+
 func unreadItemIDs(
 	r *http.Request,
 	resp resp,
@@ -20,24 +50,12 @@ func unreadItemIDs(
 ) error {
 	log.Infoln("Fetching unread fever item ids")
 
-	ids, err := service.ArticleRepo().IDs(user,
-		content.UnreadOnly, content.Filters(content.GetUserFilters(user)))
+	list, err := itemIDCache.get("unread_item_ids", user, service.ArticleRepo(), content.UnreadOnly)
 	if err != nil {
 		return errors.WithMessage(err, "getting unread ids")
 	}
 
-	buf := pool.Buffer.Get()
-	defer pool.Buffer.Put(buf)
-
-	for i := range ids {
-		if i != 0 {
-			buf.WriteString(",")
-		}
-
-		buf.WriteString(strconv.FormatInt(int64(ids[i]), 10))
-	}
-
-	resp["unread_item_ids"] = buf.String()
+	resp["unread_item_ids"] = list
 
 	return nil
 }
@@ -51,26 +69,83 @@ func savedItemIDs(
 ) error {
 	log.Infoln("Fetching saved fever item ids")
 
-	ids, err := service.ArticleRepo().IDs(user,
-		content.FavoriteOnly, content.Filters(content.GetUserFilters(user)))
+	list, err := itemIDCache.get("saved_item_ids", user, service.ArticleRepo(), content.FavoriteOnly)
 	if err != nil {
-		return errors.WithMessage(err, "getting unread ids")
+		return errors.WithMessage(err, "getting saved ids")
+	}
+
+	resp["saved_item_ids"] = list
+
+	return nil
+}
+
+// get returns the comma-separated ids of the user's articles in the given
+// state, fetching them again if the user's articles have changed since
+// they were cached.
+func (c *idCache) get(
+	name string,
+	user content.User,
+	repo repo.Article,
+	state content.QueryOpt,
+) (string, error) {
+	first, last, err := repo.ChangeRange()
+	if err != nil {
+		return "", errors.WithMessage(err, "getting article change range")
+	}
+
+	key := idCacheKey{user.Login, name}
+
+	c.Lock()
+	entry, ok := c.entries[key]
+	c.Unlock()
+
+	// Changes older than the first one in the log have been removed, so
+	// the entry can't be validated if its cursor precedes them.
+	if ok && first > 0 && entry.cursor >= first-1 && time.Since(entry.fetched) < idCacheExpiry {
+		changes, err := repo.Changes(user, entry.cursor, 1)
+		if err != nil {
+			return "", errors.WithMessage(err, "getting article changes")
+		}
+
+		if len(changes) == 0 {
+			if last > entry.cursor {
+				entry.cursor = last
+				c.set(key, entry)
+			}
+
+			return entry.ids, nil
+		}
+	}
+
+	list, err := repo.IDs(user, state, content.Filters(content.GetUserFilters(user)))
+	if err != nil {
+		return "", errors.WithMessage(err, "getting article ids")
 	}
 
 	buf := pool.Buffer.Get()
 	defer pool.Buffer.Put(buf)
 
-	for i := range ids {
+	for i := range list {
 		if i != 0 {
 			buf.WriteString(",")
 		}
 
-		buf.WriteString(strconv.FormatInt(int64(ids[i]), 10))
+		buf.WriteString(strconv.FormatInt(int64(list[i]), 10))
 	}
 
-	resp["saved_item_ids"] = buf.String()
+	// The cursor precedes the fetch, so that concurrent changes will
+	// invalidate the entry.
+	entry = idCacheEntry{ids: buf.String(), cursor: last, fetched: time.Now()}
+	c.set(key, entry)
 
-	return nil
+	return entry.ids, nil
+}
+
+func (c *idCache) set(key idCacheKey, entry idCacheEntry) {
+	c.Lock()
+	defer c.Unlock()
+
+	c.entries[key] = entry
 }
 
 func init() {
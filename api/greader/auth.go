@@ -0,0 +1,124 @@
+package greader
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/urandom/readeef/content"
+	"github.com/urandom/readeef/content/repo"
+	"github.com/urandom/readeef/log"
+)
+
+type loginContent struct {
+	SID  string `json:"SID"`
+	LSID string `json:"LSID"`
+	Auth string `json:"Auth"`
+}
+
+type userInfoContent struct {
+	UserID        string `json:"userId"`
+	UserName      string `json:"userName"`
+	UserProfileID string `json:"userProfileId"`
+	UserEmail     string `json:"userEmail"`
+}
+
+// clientLogin authenticates the user using the Email and Passwd values, and
+// returns the token that the client sends back in the Authorization header.
+func clientLogin(repo repo.User, secret []byte, log log.Log) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		user, err := repo.Get(content.Login(r.Form.Get("Email")))
+		if err == nil && user.Active {
+			var ok bool
+			if ok, err = user.Authenticate(r.Form.Get("Passwd"), secret); err == nil && !ok {
+				err = errors.Errorf("invalid password for Google Reader user '%s'", user.Login)
+			}
+		} else if err == nil {
+			err = errors.Errorf("Google Reader user '%s' is inactive", user.Login)
+		}
+
+		if err != nil {
+			log.Printf("Error logging in Google Reader user: %+v", err)
+			http.Error(w, "Error=BadAuthentication", http.StatusUnauthorized)
+			return
+		}
+
+		auth := authToken(user, secret)
+
+		if r.Form.Get("output") == "json" {
+			writeJSON(w, loginContent{SID: auth, LSID: "null", Auth: auth})
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		fmt.Fprintf(w, "SID=%s\nLSID=null\nAuth=%s\n", auth, auth)
+	}
+}
+
+// token returns the edit token, which clients send with modifying requests.
+// Since the requests are already authenticated, it isn't checked.
+func token(secret []byte) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		auth := authToken(userFromRequest(r), secret)
+		auth = auth[strings.LastIndex(auth, "/")+1:]
+
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.Write([]byte(auth))
+	}
+}
+
+func userInfo(w http.ResponseWriter, r *http.Request) {
+	user := userFromRequest(r)
+
+	writeJSON(w, userInfoContent{
+		UserID:        string(user.Login),
+		UserName:      string(user.Login),
+		UserProfileID: string(user.Login),
+		UserEmail:     user.Email,
+	})
+}
+
+// authToken returns a token, in the form of login/signature, that stays valid
+// until the user's password changes.
+func authToken(user content.User, secret []byte) string {
+	return string(user.Login) + "/" + tokenSignature(user, secret)
+}
+
+func tokenSignature(user content.User, secret []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(user.Login))
+	mac.Write(user.Hash)
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func userFromToken(repo repo.User, token string, secret []byte) (content.User, error) {
+	i := strings.LastIndex(token, "/")
+	if i == -1 {
+		return content.User{}, errors.New("malformed token")
+	}
+
+	user, err := repo.Get(content.Login(token[:i]))
+	if err != nil {
+		return content.User{}, errors.WithMessage(err, "getting token user")
+	}
+
+	if !user.Active {
+		return content.User{}, errors.Errorf("user %s is inactive", user.Login)
+	}
+
+	if !hmac.Equal([]byte(token[i+1:]), []byte(tokenSignature(user, secret))) {
+		return content.User{}, errors.New("invalid token signature")
+	}
+
+	return user, nil
+}
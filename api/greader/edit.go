@@ -0,0 +1,127 @@
+package greader
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/urandom/readeef/content"
+	"github.com/urandom/readeef/content/repo"
+	"github.com/urandom/readeef/log"
+)
+
+// editTag adds the 'a' and removes the 'r' tags of the 'i' items. State
+// streams change the read and favorite states, while labels are assigned to
+// or unassigned from the articles, with missing labels being created.
+func editTag(service repo.Service, log log.Log) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user := userFromRequest(r)
+
+		ids, err := itemIDs(r.Form["i"])
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		if len(ids) == 0 {
+			http.Error(w, "No items", http.StatusBadRequest)
+			return
+		}
+
+		for _, t := range r.Form["a"] {
+			if err := applyTag(normalizeStreamID(t), true, ids, user, service); err != nil {
+				fatal(w, log, "Error adding item tag: %+v", err)
+				return
+			}
+		}
+
+		for _, t := range r.Form["r"] {
+			if err := applyTag(normalizeStreamID(t), false, ids, user, service); err != nil {
+				fatal(w, log, "Error removing item tag: %+v", err)
+				return
+			}
+		}
+
+		writeOK(w)
+	}
+}
+
+func applyTag(
+	tag string,
+	add bool,
+	ids []content.ArticleID,
+	user content.User,
+	service repo.Service,
+) error {
+	articleRepo := service.ArticleRepo()
+	opts := content.IDs(ids)
+
+	switch {
+	case tag == STREAM_READ:
+		return articleRepo.Read(add, user, opts)
+	case tag == STREAM_KEPT_UNREAD:
+		if add {
+			return articleRepo.Read(false, user, opts)
+		}
+		return nil
+	case tag == STREAM_STARRED:
+		return articleRepo.Favor(add, user, opts)
+	case strings.HasPrefix(tag, STREAM_LABEL_PREFIX):
+		labelRepo := service.LabelRepo()
+
+		name := strings.TrimPrefix(tag, STREAM_LABEL_PREFIX)
+		label, err := labelByName(name, user, labelRepo)
+		if err != nil {
+			if errors.Cause(err) != errUnknownStream {
+				return err
+			}
+
+			if !add {
+				return nil
+			}
+
+			label = content.Label{UserLogin: user.Login, Name: name}
+			if err := labelRepo.Update(&label); err != nil {
+				return errors.WithMessage(err, "creating label")
+			}
+		}
+
+		if add {
+			return labelRepo.Assign(label, ids)
+		}
+
+		return labelRepo.Unassign(label, ids)
+	}
+
+	// Other tags, such as the broadcast ones, are not supported
+	return nil
+}
+
+// markAllAsRead marks the articles of the 's' stream as read. If the 'ts'
+// timestamp is given in microseconds, only older articles are marked.
+func markAllAsRead(service repo.Service, log log.Log) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user := userFromRequest(r)
+
+		opts, err := streamOpts(r.Form.Get("s"), user, service)
+		if err != nil {
+			fatal(w, log, "Error parsing stream: %+v", err)
+			return
+		}
+
+		opts = append(opts, content.Filters(content.GetUserFilters(user)))
+
+		if ts, err := strconv.ParseInt(r.Form.Get("ts"), 10, 64); err == nil && ts > 0 {
+			opts = append(opts, content.TimeRange(time.Time{}, time.Unix(0, ts*int64(time.Microsecond))))
+		}
+
+		if err := service.ArticleRepo().Read(true, user, opts...); err != nil {
+			fatal(w, log, "Error marking articles as read: %+v", err)
+			return
+		}
+
+		writeOK(w)
+	}
+}
@@ -0,0 +1,292 @@
+package greader
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi"
+	"github.com/pkg/errors"
+	"github.com/urandom/readeef/content"
+	"github.com/urandom/readeef/content/processor"
+	"github.com/urandom/readeef/content/repo"
+	"github.com/urandom/readeef/log"
+)
+
+type contextKey string
+
+const (
+	// Stream ids, with the user id already replaced by '-'
+	STREAM_READING_LIST = "user/-/state/com.google/reading-list"
+	STREAM_READ         = "user/-/state/com.google/read"
+	STREAM_STARRED      = "user/-/state/com.google/starred"
+	STREAM_KEPT_UNREAD  = "user/-/state/com.google/kept-unread"
+
+	STREAM_LABEL_PREFIX = "user/-/label/"
+	STREAM_FEED_PREFIX  = "feed/"
+
+	ITEM_ID_PREFIX = "tag:google.com,2005:reader/item/"
+
+	DEFAULT_COUNT      = 20
+	MAX_ITEMS_COUNT    = 1000
+	MAX_ITEM_IDS_COUNT = 10000
+)
+
+var (
+	userKey = contextKey("user")
+
+	errUnknownStream = errors.New("unknown stream")
+)
+
+// Handler returns the http handler of the Google Reader API emulator. The
+// ClientLogin endpoint is served under /accounts, and the rest of the API
+// under /reader/api/0.
+func Handler(
+	service repo.Service,
+	processors []processor.Article,
+	secret []byte,
+	log log.Log,
+) http.Handler {
+	processors = filterProcessors(processors)
+
+	r := chi.NewRouter()
+
+	r.HandleFunc("/accounts/ClientLogin", clientLogin(service.UserRepo(), secret, log))
+
+	r.Route("/reader/api/0", func(r chi.Router) {
+		r.Use(authenticate(service.UserRepo(), secret, log))
+
+		r.Get("/token", token(secret))
+		r.Get("/user-info", userInfo)
+
+		r.Get("/subscription/list", subscriptionList(service, log))
+		r.Get("/tag/list", tagList(service, log))
+
+		r.Get("/stream/contents", streamContents(service, processors, log))
+		r.Get("/stream/contents/*", streamFromPath(streamContents(service, processors, log)))
+		r.Get("/stream/items/ids", streamItemIDs(service, log))
+		r.Get("/stream/items/contents", streamItemContents(service, processors, log))
+		r.Post("/stream/items/contents", streamItemContents(service, processors, log))
+
+		r.Post("/edit-tag", editTag(service, log))
+		r.Post("/mark-all-as-read", markAllAsRead(service, log))
+	})
+
+	return r
+}
+
+func authenticate(repo repo.User, secret []byte, log log.Log) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if err := r.ParseForm(); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+
+			auth := r.Header.Get("Authorization")
+			if !strings.HasPrefix(auth, "GoogleLogin auth=") {
+				http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+				return
+			}
+
+			user, err := userFromToken(repo, strings.TrimPrefix(auth, "GoogleLogin auth="), secret)
+			if err != nil {
+				log.Debugf("Error authenticating Google Reader user: %+v", err)
+				http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+				return
+			}
+
+			ctx := context.WithValue(r.Context(), userKey, user)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+func userFromRequest(r *http.Request) content.User {
+	user, _ := r.Context().Value(userKey).(content.User)
+	return user
+}
+
+// normalizeStreamID replaces the user id of user streams with '-', since
+// clients use both forms.
+func normalizeStreamID(id string) string {
+	if strings.HasPrefix(id, "user/") {
+		if parts := strings.SplitN(id, "/", 3); len(parts) == 3 {
+			return "user/-/" + parts[2]
+		}
+	}
+
+	return id
+}
+
+// streamOpts returns the article query options that select the articles of
+// the given stream.
+func streamOpts(id string, user content.User, service repo.Service) ([]content.QueryOpt, error) {
+	id = normalizeStreamID(id)
+
+	switch {
+	case id == "" || id == STREAM_READING_LIST:
+		return nil, nil
+	case id == STREAM_READ:
+		return []content.QueryOpt{content.ReadOnly}, nil
+	case id == STREAM_STARRED:
+		return []content.QueryOpt{content.FavoriteOnly}, nil
+	case id == STREAM_KEPT_UNREAD:
+		return []content.QueryOpt{content.UnreadOnly}, nil
+	case strings.HasPrefix(id, STREAM_FEED_PREFIX):
+		feedID, err := feedIDFromStream(id, service.FeedRepo())
+		if err != nil {
+			return nil, err
+		}
+
+		return []content.QueryOpt{content.FeedIDs([]content.FeedID{feedID})}, nil
+	case strings.HasPrefix(id, STREAM_LABEL_PREFIX):
+		name := strings.TrimPrefix(id, STREAM_LABEL_PREFIX)
+
+		tagRepo := service.TagRepo()
+		tags, err := tagRepo.ForUser(user)
+		if err != nil {
+			return nil, errors.WithMessage(err, "getting user tags")
+		}
+
+		for _, t := range tags {
+			if string(t.Value) == name {
+				ids, err := tagRepo.FeedIDs(t, user)
+				if err != nil {
+					return nil, errors.WithMessage(err, "getting tag feed ids")
+				}
+
+				return []content.QueryOpt{content.FeedIDs(ids)}, nil
+			}
+		}
+
+		label, err := labelByName(name, user, service.LabelRepo())
+		if err != nil {
+			return nil, err
+		}
+
+		return []content.QueryOpt{content.LabelIDs([]content.LabelID{label.ID})}, nil
+	}
+
+	return nil, errors.Wrapf(errUnknownStream, "stream %s", id)
+}
+
+func feedIDFromStream(id string, repo repo.Feed) (content.FeedID, error) {
+	val := strings.TrimPrefix(id, STREAM_FEED_PREFIX)
+	if feedID, err := strconv.ParseInt(val, 10, 64); err == nil {
+		return content.FeedID(feedID), nil
+	}
+
+	feed, err := repo.FindByLink(val)
+	if err != nil {
+		if content.IsNoContent(err) {
+			err = errors.Wrapf(errUnknownStream, "stream %s", id)
+		}
+		return 0, errors.WithMessage(err, "getting feed by link")
+	}
+
+	return feed.ID, nil
+}
+
+func labelByName(name string, user content.User, repo repo.Label) (content.Label, error) {
+	labels, err := repo.ForUser(user)
+	if err != nil {
+		return content.Label{}, errors.WithMessage(err, "getting user labels")
+	}
+
+	for _, l := range labels {
+		if l.Name == name {
+			return l, nil
+		}
+	}
+
+	return content.Label{}, errors.Wrapf(errUnknownStream, "label %s", name)
+}
+
+func feedStreamID(id content.FeedID) string {
+	return STREAM_FEED_PREFIX + strconv.FormatInt(int64(id), 10)
+}
+
+func labelStreamID(name string) string {
+	return STREAM_LABEL_PREFIX + name
+}
+
+func longItemID(id content.ArticleID) string {
+	return fmt.Sprintf("%s%016x", ITEM_ID_PREFIX, uint64(id))
+}
+
+// parseItemID parses both the long, hex-encoded, and short, decimal, forms of
+// an item id.
+func parseItemID(id string) (content.ArticleID, error) {
+	if strings.HasPrefix(id, ITEM_ID_PREFIX) {
+		val, err := strconv.ParseUint(strings.TrimPrefix(id, ITEM_ID_PREFIX), 16, 64)
+		if err != nil {
+			return 0, errors.Wrapf(err, "parsing item id %s", id)
+		}
+
+		return content.ArticleID(val), nil
+	}
+
+	val, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		return 0, errors.Wrapf(err, "parsing item id %s", id)
+	}
+
+	return content.ArticleID(val), nil
+}
+
+func itemIDs(values []string) ([]content.ArticleID, error) {
+	ids := make([]content.ArticleID, 0, len(values))
+
+	for _, v := range values {
+		id, err := parseItemID(v)
+		if err != nil {
+			return nil, err
+		}
+
+		ids = append(ids, id)
+	}
+
+	return ids, nil
+}
+
+func writeJSON(w http.ResponseWriter, data interface{}) {
+	if b, err := json.Marshal(data); err == nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(b)
+	} else {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func writeOK(w http.ResponseWriter) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Write([]byte("OK"))
+}
+
+func fatal(w http.ResponseWriter, log log.Log, format string, err error) {
+	if errors.Cause(err) == errUnknownStream {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	log.Printf(format, err)
+	http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+}
+
+func filterProcessors(input []processor.Article) []processor.Article {
+	processors := make([]processor.Article, 0, len(input))
+
+	for i := range input {
+		if _, ok := input[i].(processor.ProxyHTTP); ok {
+			continue
+		}
+
+		processors = append(processors, input[i])
+	}
+
+	return processors
+}
@@ -0,0 +1,546 @@
+package greader
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/urandom/readeef/config"
+	"github.com/urandom/readeef/content"
+	"github.com/urandom/readeef/content/repo/mock_repo"
+	"github.com/urandom/readeef/log"
+)
+
+var (
+	secret   = []byte("secret")
+	logger   log.Log
+	testUser = content.User{Login: "test", Active: true}
+)
+
+func init() {
+	cfg := config.Log{}
+	cfg.Converted.Writer = os.Stderr
+	cfg.Converted.Prefix = "[testing] "
+
+	logger = log.WithStd(cfg)
+
+	testUser.Password("pass", secret)
+}
+
+func TestHandler_auth(t *testing.T) {
+	inactive := content.User{Login: "inactive"}
+	inactive.Password("pass", secret)
+
+	tests := []struct {
+		name string
+		form url.Values
+		auth string
+		code int
+	}{
+		{name: "login", form: url.Values{"Email": {"test"}, "Passwd": {"pass"}}, code: http.StatusOK},
+		{name: "login wrong password", form: url.Values{"Email": {"test"}, "Passwd": {"wrong"}}, code: http.StatusUnauthorized},
+		{name: "login unknown user", form: url.Values{"Email": {"unknown"}, "Passwd": {"pass"}}, code: http.StatusUnauthorized},
+		{name: "login inactive user", form: url.Values{"Email": {"inactive"}, "Passwd": {"pass"}}, code: http.StatusUnauthorized},
+		{name: "no token", auth: "", code: http.StatusUnauthorized},
+		{name: "malformed token", auth: "GoogleLogin auth=test", code: http.StatusUnauthorized},
+		{name: "forged token", auth: "GoogleLogin auth=test/" + strings.Repeat("0", 64), code: http.StatusUnauthorized},
+		{name: "inactive user token", auth: "GoogleLogin auth=" + authToken(inactive, secret), code: http.StatusUnauthorized},
+		{name: "token", auth: "GoogleLogin auth=" + authToken(testUser, secret), code: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			service := mock_repo.NewMockService(ctrl)
+			userRepo := mock_repo.NewMockUser(ctrl)
+			service.EXPECT().UserRepo().Return(userRepo).AnyTimes()
+
+			userRepo.EXPECT().Get(gomock.Any()).DoAndReturn(func(login content.Login) (content.User, error) {
+				switch login {
+				case testUser.Login:
+					return testUser, nil
+				case inactive.Login:
+					return inactive, nil
+				}
+				return content.User{}, content.ErrNoContent
+			}).AnyTimes()
+
+			handler := Handler(service, nil, secret, logger)
+
+			var r *http.Request
+			if tt.form != nil {
+				r = httptest.NewRequest("POST", "/accounts/ClientLogin", strings.NewReader(tt.form.Encode()))
+				r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			} else {
+				r = httptest.NewRequest("GET", "/reader/api/0/user-info", nil)
+				if tt.auth != "" {
+					r.Header.Set("Authorization", tt.auth)
+				}
+			}
+
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, r)
+
+			if rec.Code != tt.code {
+				t.Fatalf("code = %d, want %d: %s", rec.Code, tt.code, rec.Body)
+			}
+
+			if tt.code != http.StatusOK {
+				return
+			}
+
+			if tt.form != nil {
+				want := "Auth=" + authToken(testUser, secret)
+				if !strings.Contains(rec.Body.String(), want) {
+					t.Errorf("login response = %s, want it to contain %s", rec.Body, want)
+				}
+			} else {
+				var info userInfoContent
+				if err := json.Unmarshal(rec.Body.Bytes(), &info); err != nil {
+					t.Fatal(err)
+				}
+
+				if info.UserID != "test" {
+					t.Errorf("user info = %#v", info)
+				}
+			}
+		})
+	}
+}
+
+func TestHandler_streams(t *testing.T) {
+	tests := []struct {
+		name         string
+		path         string
+		code         int
+		ids          []content.ArticleID
+		categories   map[content.ArticleID][]string
+		continuation string
+	}{
+		{name: "reading list", path: "/stream/contents", code: http.StatusOK, ids: []content.ArticleID{3, 2, 1},
+			categories: map[content.ArticleID][]string{
+				1: {STREAM_READING_LIST, STREAM_READ, STREAM_STARRED, "user/-/label/dev"},
+				2: {STREAM_READING_LIST, "user/-/label/dev", "user/-/label/later"},
+				3: {STREAM_READING_LIST},
+			}},
+		{name: "reading list oldest first", path: "/stream/contents?r=o", code: http.StatusOK, ids: []content.ArticleID{1, 2, 3}},
+		{name: "reading list paged", path: "/stream/contents?n=2", code: http.StatusOK, ids: []content.ArticleID{3, 2}, continuation: "2"},
+		{name: "reading list continued", path: "/stream/contents?n=2&c=2", code: http.StatusOK, ids: []content.ArticleID{1}},
+		{name: "starred", path: "/stream/contents/user%2F-%2Fstate%2Fcom.google%2Fstarred", code: http.StatusOK, ids: []content.ArticleID{1}},
+		{name: "starred with user id", path: "/stream/contents?s=user/1234/state/com.google/starred", code: http.StatusOK, ids: []content.ArticleID{1}},
+		{name: "excluding read", path: "/stream/contents?xt=user/-/state/com.google/read", code: http.StatusOK, ids: []content.ArticleID{3, 2}},
+		{name: "feed", path: "/stream/contents/feed%2F2", code: http.StatusOK, ids: []content.ArticleID{3}},
+		{name: "feed by link", path: "/stream/contents?s=feed/https://blog.golang.org/feed.atom", code: http.StatusOK, ids: []content.ArticleID{2, 1}},
+		{name: "folder", path: "/stream/contents?s=user/-/label/dev", code: http.StatusOK, ids: []content.ArticleID{2, 1}},
+		{name: "label", path: "/stream/contents?s=user/-/label/later", code: http.StatusOK, ids: []content.ArticleID{2}},
+		{name: "unknown label", path: "/stream/contents?s=user/-/label/unknown", code: http.StatusBadRequest},
+		{name: "unknown feed", path: "/stream/contents?s=feed/https://example.com/unknown", code: http.StatusBadRequest},
+		{name: "unknown stream", path: "/stream/contents?s=unknown", code: http.StatusBadRequest},
+		{name: "item ids", path: "/stream/items/ids?s=user/-/state/com.google/reading-list", code: http.StatusOK, ids: []content.ArticleID{3, 2, 1}},
+		{name: "item ids unread", path: "/stream/items/ids?s=user/-/state/com.google/reading-list&xt=user/-/state/com.google/read&n=1", code: http.StatusOK, ids: []content.ArticleID{3}, continuation: "1"},
+		{name: "item contents", path: "/stream/items/contents?i=1&i=tag:google.com,2005:reader/item/0000000000000003", code: http.StatusOK, ids: []content.ArticleID{3, 1}},
+		{name: "item contents invalid id", path: "/stream/items/contents?i=tag:google.com,2005:reader/item/zz", code: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			w := newTestWorld(ctrl)
+			rec := w.call(t, "GET", "/reader/api/0"+tt.path, nil)
+
+			if rec.Code != tt.code {
+				t.Fatalf("code = %d, want %d: %s", rec.Code, tt.code, rec.Body)
+			}
+
+			if tt.code != http.StatusOK {
+				return
+			}
+
+			var resp struct {
+				Items        []item    `json:"items"`
+				ItemRefs     []itemRef `json:"itemRefs"`
+				Continuation string    `json:"continuation"`
+			}
+			if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+				t.Fatal(err)
+			}
+
+			ids := []content.ArticleID{}
+			for _, i := range resp.Items {
+				id, err := parseItemID(i.ID)
+				if err != nil {
+					t.Fatal(err)
+				}
+				ids = append(ids, id)
+
+				if categories, ok := tt.categories[id]; ok && !reflect.DeepEqual(i.Categories, categories) {
+					t.Errorf("item %d categories = %v, want %v", id, i.Categories, categories)
+				}
+			}
+			for _, ref := range resp.ItemRefs {
+				id, err := parseItemID(ref.ID)
+				if err != nil {
+					t.Fatal(err)
+				}
+				ids = append(ids, id)
+			}
+
+			if !reflect.DeepEqual(ids, tt.ids) {
+				t.Errorf("ids = %v, want %v", ids, tt.ids)
+			}
+
+			if resp.Continuation != tt.continuation {
+				t.Errorf("continuation = %q, want %q", resp.Continuation, tt.continuation)
+			}
+		})
+	}
+}
+
+func TestHandler_edit(t *testing.T) {
+	tests := []struct {
+		name     string
+		path     string
+		form     url.Values
+		code     int
+		read     []content.ArticleID
+		unread   []content.ArticleID
+		favor    []content.ArticleID
+		unfavor  []content.ArticleID
+		assigned map[string][]content.ArticleID
+	}{
+		{name: "mark as read", path: "/edit-tag", form: url.Values{"i": {"3", "tag:google.com,2005:reader/item/0000000000000002"}, "a": {"user/-/state/com.google/read"}},
+			code: http.StatusOK, read: []content.ArticleID{2, 3}},
+		{name: "mark as unread", path: "/edit-tag", form: url.Values{"i": {"1"}, "r": {"user/1234/state/com.google/read"}},
+			code: http.StatusOK, unread: []content.ArticleID{1}},
+		{name: "keep unread", path: "/edit-tag", form: url.Values{"i": {"1"}, "a": {"user/-/state/com.google/kept-unread"}},
+			code: http.StatusOK, unread: []content.ArticleID{1}},
+		{name: "star", path: "/edit-tag", form: url.Values{"i": {"3"}, "a": {"user/-/state/com.google/starred"}},
+			code: http.StatusOK, favor: []content.ArticleID{3}},
+		{name: "unstar", path: "/edit-tag", form: url.Values{"i": {"1"}, "r": {"user/-/state/com.google/starred"}},
+			code: http.StatusOK, unfavor: []content.ArticleID{1}},
+		{name: "add label", path: "/edit-tag", form: url.Values{"i": {"1"}, "a": {"user/-/label/later"}},
+			code: http.StatusOK, assigned: map[string][]content.ArticleID{"later": {1, 2}}},
+		{name: "add new label", path: "/edit-tag", form: url.Values{"i": {"3"}, "a": {"user/-/label/new"}},
+			code: http.StatusOK, assigned: map[string][]content.ArticleID{"new": {3}}},
+		{name: "remove label", path: "/edit-tag", form: url.Values{"i": {"2"}, "r": {"user/-/label/later"}},
+			code: http.StatusOK, assigned: map[string][]content.ArticleID{"later": {}}},
+		{name: "no items", path: "/edit-tag", form: url.Values{"a": {"user/-/state/com.google/read"}}, code: http.StatusBadRequest},
+		{name: "invalid item", path: "/edit-tag", form: url.Values{"i": {"one"}, "a": {"user/-/state/com.google/read"}}, code: http.StatusBadRequest},
+		{name: "mark all as read", path: "/mark-all-as-read", form: url.Values{"s": {"user/-/state/com.google/reading-list"}},
+			code: http.StatusOK, read: []content.ArticleID{2, 3}},
+		{name: "mark feed as read", path: "/mark-all-as-read", form: url.Values{"s": {"feed/1"}},
+			code: http.StatusOK, read: []content.ArticleID{2}},
+		{name: "mark older as read", path: "/mark-all-as-read", form: url.Values{"s": {"user/-/state/com.google/reading-list"}, "ts": {"1504137600000000"}},
+			code: http.StatusOK, read: []content.ArticleID{2}},
+		{name: "mark unknown stream as read", path: "/mark-all-as-read", form: url.Values{"s": {"unknown"}}, code: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			w := newTestWorld(ctrl)
+			rec := w.call(t, "POST", "/reader/api/0"+tt.path, tt.form)
+
+			if rec.Code != tt.code {
+				t.Fatalf("code = %d, want %d: %s", rec.Code, tt.code, rec.Body)
+			}
+
+			if tt.code != http.StatusOK {
+				return
+			}
+
+			if rec.Body.String() != "OK" {
+				t.Errorf("body = %s, want OK", rec.Body)
+			}
+
+			checkIDs(t, "read", w.changed(func(a content.Article) bool { return a.Read }, true), tt.read)
+			checkIDs(t, "unread", w.changed(func(a content.Article) bool { return a.Read }, false), tt.unread)
+			checkIDs(t, "favor", w.changed(func(a content.Article) bool { return a.Favorite }, true), tt.favor)
+			checkIDs(t, "unfavor", w.changed(func(a content.Article) bool { return a.Favorite }, false), tt.unfavor)
+
+			for name, want := range tt.assigned {
+				var got []content.ArticleID
+				for _, a := range w.articles {
+					for _, id := range a.Labels {
+						if w.labelName(id) == name {
+							got = append(got, a.ID)
+						}
+					}
+				}
+
+				checkIDs(t, "label "+name, got, want)
+			}
+		})
+	}
+}
+
+func checkIDs(t *testing.T, name string, got, want []content.ArticleID) {
+	sort.Slice(got, func(i, j int) bool { return got[i] < got[j] })
+
+	if len(got) != len(want) || len(want) > 0 && !reflect.DeepEqual(got, want) {
+		t.Errorf("%s = %v, want %v", name, got, want)
+	}
+}
+
+// testWorld is an in-memory implementation of the repositories, filtering
+// its articles with the query options.
+type testWorld struct {
+	service *mock_repo.MockService
+
+	feeds    []content.Feed
+	tags     map[content.FeedID][]content.Tag
+	labels   []content.Label
+	original []content.Article
+	articles []content.Article
+}
+
+func newTestWorld(ctrl *gomock.Controller) *testWorld {
+	dev := content.Tag{ID: 1, Value: "dev"}
+
+	w := &testWorld{
+		service: mock_repo.NewMockService(ctrl),
+		feeds: []content.Feed{
+			{ID: 1, Title: "Go Blog", Link: "https://blog.golang.org/feed.atom", SiteLink: "https://blog.golang.org"},
+			{ID: 2, Title: "News", Link: "https://news.example.com/rss"},
+		},
+		tags:   map[content.FeedID][]content.Tag{1: {dev}},
+		labels: []content.Label{{ID: 1, UserLogin: "test", Name: "later"}},
+		original: []content.Article{
+			{ID: 1, FeedID: 1, Title: "Toward Go 2", Link: "https://blog.golang.org/toward-go2",
+				Date: time.Date(2017, 7, 13, 0, 0, 0, 0, time.UTC), Read: true, Favorite: true},
+			{ID: 2, FeedID: 1, Title: "Go 1.9 is released", Link: "https://blog.golang.org/go1.9",
+				Date: time.Date(2017, 8, 24, 0, 0, 0, 0, time.UTC), Labels: []content.LabelID{1}},
+			{ID: 3, FeedID: 2, Title: "Breaking news", Link: "https://news.example.com/breaking",
+				Date: time.Date(2017, 9, 1, 0, 0, 0, 0, time.UTC)},
+		},
+	}
+
+	w.articles = append([]content.Article{}, w.original...)
+
+	userRepo := mock_repo.NewMockUser(ctrl)
+	feedRepo := mock_repo.NewMockFeed(ctrl)
+	tagRepo := mock_repo.NewMockTag(ctrl)
+	labelRepo := mock_repo.NewMockLabel(ctrl)
+	articleRepo := mock_repo.NewMockArticle(ctrl)
+
+	w.service.EXPECT().UserRepo().Return(userRepo).AnyTimes()
+	w.service.EXPECT().FeedRepo().Return(feedRepo).AnyTimes()
+	w.service.EXPECT().TagRepo().Return(tagRepo).AnyTimes()
+	w.service.EXPECT().LabelRepo().Return(labelRepo).AnyTimes()
+	w.service.EXPECT().ArticleRepo().Return(articleRepo).AnyTimes()
+
+	userRepo.EXPECT().Get(testUser.Login).Return(testUser, nil).AnyTimes()
+
+	feedRepo.EXPECT().ForUser(gomock.Any()).Return(w.feeds, nil).AnyTimes()
+	feedRepo.EXPECT().FindByLink(gomock.Any()).DoAndReturn(func(link string) (content.Feed, error) {
+		for _, f := range w.feeds {
+			if f.Link == link {
+				return f, nil
+			}
+		}
+		return content.Feed{}, content.ErrNoContent
+	}).AnyTimes()
+
+	tagRepo.EXPECT().ForUser(gomock.Any()).Return([]content.Tag{dev}, nil).AnyTimes()
+	tagRepo.EXPECT().FeedIDs(gomock.Any(), gomock.Any()).DoAndReturn(func(tag content.Tag, _ content.User) ([]content.FeedID, error) {
+		var ids []content.FeedID
+		for _, f := range w.feeds {
+			for _, t := range w.tags[f.ID] {
+				if t.ID == tag.ID {
+					ids = append(ids, f.ID)
+				}
+			}
+		}
+		return ids, nil
+	}).AnyTimes()
+
+	labelRepo.EXPECT().ForUser(gomock.Any()).DoAndReturn(func(content.User) ([]content.Label, error) {
+		return w.labels, nil
+	}).AnyTimes()
+	labelRepo.EXPECT().Update(gomock.Any()).DoAndReturn(func(l *content.Label) error {
+		l.ID = content.LabelID(len(w.labels) + 1)
+		w.labels = append(w.labels, *l)
+		return nil
+	}).AnyTimes()
+	labelRepo.EXPECT().Assign(gomock.Any(), gomock.Any()).DoAndReturn(func(l content.Label, ids []content.ArticleID) error {
+		w.update(ids, func(a *content.Article) {
+			a.Labels = append(a.Labels, l.ID)
+		})
+		return nil
+	}).AnyTimes()
+	labelRepo.EXPECT().Unassign(gomock.Any(), gomock.Any()).DoAndReturn(func(l content.Label, ids []content.ArticleID) error {
+		w.update(ids, func(a *content.Article) {
+			var labels []content.LabelID
+			for _, id := range a.Labels {
+				if id != l.ID {
+					labels = append(labels, id)
+				}
+			}
+			a.Labels = labels
+		})
+		return nil
+	}).AnyTimes()
+
+	articleRepo.EXPECT().ForUser(gomock.Any(), gomock.Any()).DoAndReturn(func(_ content.User, opts ...content.QueryOpt) ([]content.Article, error) {
+		return w.query(opts), nil
+	}).AnyTimes()
+	articleRepo.EXPECT().IDs(gomock.Any(), gomock.Any()).DoAndReturn(func(_ content.User, opts ...content.QueryOpt) ([]content.ArticleID, error) {
+		var ids []content.ArticleID
+		for _, a := range w.query(opts) {
+			ids = append(ids, a.ID)
+		}
+		return ids, nil
+	}).AnyTimes()
+	articleRepo.EXPECT().Read(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(state bool, _ content.User, opts ...content.QueryOpt) error {
+		w.update(w.ids(opts), func(a *content.Article) { a.Read = state })
+		return nil
+	}).AnyTimes()
+	articleRepo.EXPECT().Favor(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(state bool, _ content.User, opts ...content.QueryOpt) error {
+		w.update(w.ids(opts), func(a *content.Article) { a.Favorite = state })
+		return nil
+	}).AnyTimes()
+
+	return w
+}
+
+// call sends an authenticated request to the handler.
+func (w *testWorld) call(t *testing.T, method, target string, form url.Values) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(method, target, strings.NewReader(form.Encode()))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	r.Header.Set("Authorization", "GoogleLogin auth="+authToken(testUser, secret))
+
+	rec := httptest.NewRecorder()
+	Handler(w.service, nil, secret, logger).ServeHTTP(rec, r)
+
+	return rec
+}
+
+func (w *testWorld) ids(opts []content.QueryOpt) []content.ArticleID {
+	var ids []content.ArticleID
+	for _, a := range w.query(opts) {
+		ids = append(ids, a.ID)
+	}
+
+	return ids
+}
+
+func (w *testWorld) update(ids []content.ArticleID, f func(a *content.Article)) {
+	for i := range w.articles {
+		for _, id := range ids {
+			if w.articles[i].ID == id {
+				f(&w.articles[i])
+			}
+		}
+	}
+}
+
+// changed returns the ids of the articles whose state, as returned by get,
+// has been changed to the given value.
+func (w *testWorld) changed(get func(a content.Article) bool, state bool) []content.ArticleID {
+	var ids []content.ArticleID
+	for i, a := range w.articles {
+		if get(a) == state && get(w.original[i]) != state {
+			ids = append(ids, a.ID)
+		}
+	}
+
+	return ids
+}
+
+func (w *testWorld) labelName(id content.LabelID) string {
+	for _, l := range w.labels {
+		if l.ID == id {
+			return l.Name
+		}
+	}
+
+	return ""
+}
+
+// query returns the articles matching the options used by the emulator.
+func (w *testWorld) query(opts []content.QueryOpt) []content.Article {
+	o := content.QueryOptions{}
+	o.Apply(opts)
+
+	in := func(id int64, ids []int64) bool {
+		for _, i := range ids {
+			if i == id {
+				return true
+			}
+		}
+		return false
+	}
+
+	var ids, feedIDs, labelIDs []int64
+	for _, id := range o.IDs {
+		ids = append(ids, int64(id))
+	}
+	for _, id := range o.FeedIDs {
+		feedIDs = append(feedIDs, int64(id))
+	}
+	for _, id := range o.LabelIDs {
+		labelIDs = append(labelIDs, int64(id))
+	}
+
+	articles := []content.Article{}
+	for _, a := range w.articles {
+		switch {
+		case len(ids) > 0 && !in(int64(a.ID), ids),
+			len(feedIDs) > 0 && !in(int64(a.FeedID), feedIDs),
+			o.UnreadOnly && a.Read,
+			o.ReadOnly && !a.Read,
+			o.FavoriteOnly && !a.Favorite,
+			!o.AfterDate.IsZero() && !a.Date.After(o.AfterDate),
+			!o.BeforeDate.IsZero() && !a.Date.Before(o.BeforeDate):
+			continue
+		}
+
+		if len(labelIDs) > 0 {
+			var labelled bool
+			for _, id := range a.Labels {
+				labelled = labelled || in(int64(id), labelIDs)
+			}
+
+			if !labelled {
+				continue
+			}
+		}
+
+		articles = append(articles, a)
+	}
+
+	sort.SliceStable(articles, func(i, j int) bool {
+		if o.SortOrder == content.AscendingOrder {
+			return articles[i].Date.Before(articles[j].Date)
+		}
+
+		return articles[i].Date.After(articles[j].Date)
+	})
+
+	if o.Offset > 0 {
+		if o.Offset >= len(articles) {
+			return []content.Article{}
+		}
+		articles = articles[o.Offset:]
+	}
+
+	if o.Limit > 0 && o.Limit < len(articles) {
+		articles = articles[:o.Limit]
+	}
+
+	return articles
+}
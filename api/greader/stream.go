@@ -0,0 +1,360 @@
+package greader
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi"
+	"github.com/pkg/errors"
+	"github.com/urandom/readeef/content"
+	"github.com/urandom/readeef/content/processor"
+	"github.com/urandom/readeef/content/repo"
+	"github.com/urandom/readeef/log"
+)
+
+type link struct {
+	Href string `json:"href"`
+	Type string `json:"type,omitempty"`
+}
+
+type summary struct {
+	Direction string `json:"direction"`
+	Content   string `json:"content"`
+}
+
+type origin struct {
+	StreamID string `json:"streamId"`
+	Title    string `json:"title"`
+	HTMLURL  string `json:"htmlUrl"`
+}
+
+type item struct {
+	ID            string   `json:"id"`
+	CrawlTimeMsec string   `json:"crawlTimeMsec"`
+	TimestampUsec string   `json:"timestampUsec"`
+	Published     int64    `json:"published"`
+	Updated       int64    `json:"updated"`
+	Title         string   `json:"title"`
+	Canonical     []link   `json:"canonical"`
+	Alternate     []link   `json:"alternate"`
+	Summary       summary  `json:"summary"`
+	Author        string   `json:"author"`
+	Categories    []string `json:"categories"`
+	Origin        origin   `json:"origin"`
+}
+
+type streamContent struct {
+	Direction    string `json:"direction"`
+	ID           string `json:"id"`
+	Title        string `json:"title,omitempty"`
+	Updated      int64  `json:"updated"`
+	Items        []item `json:"items"`
+	Continuation string `json:"continuation,omitempty"`
+}
+
+type itemRef struct {
+	ID string `json:"id"`
+}
+
+type itemRefsContent struct {
+	ItemRefs     []itemRef `json:"itemRefs"`
+	Continuation string    `json:"continuation,omitempty"`
+}
+
+type streamQuery struct {
+	opts   []content.QueryOpt
+	count  int
+	offset int
+}
+
+func (q streamQuery) continuation(found int) string {
+	if found < q.count {
+		return ""
+	}
+
+	return strconv.Itoa(q.offset + q.count)
+}
+
+func streamContents(
+	service repo.Service,
+	processors []processor.Article,
+	log log.Log,
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user := userFromRequest(r)
+
+		stream := r.Form.Get("s")
+		if stream == "" {
+			stream = STREAM_READING_LIST
+		}
+
+		query, err := parseStreamQuery(r, stream, MAX_ITEMS_COUNT, user, service)
+		if err != nil {
+			fatal(w, log, "Error parsing stream query: %+v", err)
+			return
+		}
+
+		articles, err := service.ArticleRepo().ForUser(user, append(query.opts, content.IncludeLabels)...)
+		if err != nil {
+			fatal(w, log, "Error getting user articles: %+v", err)
+			return
+		}
+
+		items, err := articleItems(articles, user, service, processors)
+		if err != nil {
+			fatal(w, log, "Error converting user articles: %+v", err)
+			return
+		}
+
+		writeJSON(w, streamContent{
+			Direction:    "ltr",
+			ID:           stream,
+			Updated:      time.Now().Unix(),
+			Items:        items,
+			Continuation: query.continuation(len(articles)),
+		})
+	}
+}
+
+// streamFromPath sets the 's' stream parameter from the remainder of the
+// path, for clients that request the stream contents by their path. The
+// wildcard is only read on the routes that end with it, since the parameter
+// of the mounting route is also visible to the handlers.
+func streamFromPath(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if param := chi.URLParam(r, "*"); param != "" {
+			if unescaped, err := url.PathUnescape(param); err == nil {
+				param = unescaped
+			}
+
+			r.Form.Set("s", param)
+		}
+
+		next(w, r)
+	}
+}
+
+func streamItemIDs(service repo.Service, log log.Log) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user := userFromRequest(r)
+
+		query, err := parseStreamQuery(r, r.Form.Get("s"), MAX_ITEM_IDS_COUNT, user, service)
+		if err != nil {
+			fatal(w, log, "Error parsing stream query: %+v", err)
+			return
+		}
+
+		ids, err := service.ArticleRepo().IDs(user, query.opts...)
+		if err != nil {
+			fatal(w, log, "Error getting user article ids: %+v", err)
+			return
+		}
+
+		refs := make([]itemRef, len(ids))
+		for i := range ids {
+			refs[i] = itemRef{ID: strconv.FormatInt(int64(ids[i]), 10)}
+		}
+
+		writeJSON(w, itemRefsContent{ItemRefs: refs, Continuation: query.continuation(len(ids))})
+	}
+}
+
+func streamItemContents(
+	service repo.Service,
+	processors []processor.Article,
+	log log.Log,
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user := userFromRequest(r)
+
+		ids, err := itemIDs(r.Form["i"])
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		items := []item{}
+		if len(ids) > 0 {
+			articles, err := service.ArticleRepo().ForUser(user,
+				content.IDs(ids),
+				content.IncludeLabels,
+				content.Sorting(content.SortByDate, content.DescendingOrder),
+			)
+			if err != nil {
+				fatal(w, log, "Error getting user articles: %+v", err)
+				return
+			}
+
+			if items, err = articleItems(articles, user, service, processors); err != nil {
+				fatal(w, log, "Error converting user articles: %+v", err)
+				return
+			}
+		}
+
+		writeJSON(w, streamContent{
+			Direction: "ltr",
+			ID:        STREAM_READING_LIST,
+			Updated:   time.Now().Unix(),
+			Items:     items,
+		})
+	}
+}
+
+// parseStreamQuery converts the common stream parameters into article query
+// options. The number of items is given by 'n', the continuation by 'c', and
+// 'r=o' requests the oldest items first. The 'ot' and 'nt' parameters limit
+// the time range in seconds, while the 'xt' and 'it' parameters exclude or
+// include state streams.
+func parseStreamQuery(
+	r *http.Request,
+	stream string,
+	max int,
+	user content.User,
+	service repo.Service,
+) (streamQuery, error) {
+	opts, err := streamOpts(stream, user, service)
+	if err != nil {
+		return streamQuery{}, err
+	}
+
+	query := streamQuery{count: DEFAULT_COUNT, opts: opts}
+
+	if n, err := strconv.Atoi(r.Form.Get("n")); err == nil && n > 0 {
+		query.count = n
+	}
+
+	if query.count > max {
+		query.count = max
+	}
+
+	if c, err := strconv.Atoi(r.Form.Get("c")); err == nil && c > 0 {
+		query.offset = c
+	}
+
+	query.opts = append(query.opts,
+		content.Paging(query.count, query.offset),
+		content.Filters(content.GetUserFilters(user)),
+	)
+
+	if r.Form.Get("r") == "o" {
+		query.opts = append(query.opts, content.Sorting(content.SortByDate, content.AscendingOrder))
+	} else {
+		query.opts = append(query.opts, content.Sorting(content.SortByDate, content.DescendingOrder))
+	}
+
+	var oldest, newest time.Time
+	if ot, err := strconv.ParseInt(r.Form.Get("ot"), 10, 64); err == nil && ot > 0 {
+		oldest = time.Unix(ot, 0)
+	}
+
+	if nt, err := strconv.ParseInt(r.Form.Get("nt"), 10, 64); err == nil && nt > 0 {
+		newest = time.Unix(nt, 0)
+	}
+
+	if !oldest.IsZero() || !newest.IsZero() {
+		query.opts = append(query.opts, content.TimeRange(oldest, newest))
+	}
+
+	switch normalizeStreamID(r.Form.Get("xt")) {
+	case STREAM_READ:
+		query.opts = append(query.opts, content.UnreadOnly)
+	case STREAM_KEPT_UNREAD:
+		query.opts = append(query.opts, content.ReadOnly)
+	}
+
+	switch normalizeStreamID(r.Form.Get("it")) {
+	case STREAM_READ:
+		query.opts = append(query.opts, content.ReadOnly)
+	case STREAM_STARRED:
+		query.opts = append(query.opts, content.FavoriteOnly)
+	case STREAM_KEPT_UNREAD:
+		query.opts = append(query.opts, content.UnreadOnly)
+	}
+
+	return query, nil
+}
+
+func articleItems(
+	articles []content.Article,
+	user content.User,
+	service repo.Service,
+	processors []processor.Article,
+) ([]item, error) {
+	items := make([]item, 0, len(articles))
+	if len(articles) == 0 {
+		return items, nil
+	}
+
+	feeds, err := service.FeedRepo().ForUser(user)
+	if err != nil {
+		return nil, errors.WithMessage(err, "getting user feeds")
+	}
+
+	feedMap := make(map[content.FeedID]content.Feed, len(feeds))
+	for _, f := range feeds {
+		feedMap[f.ID] = f
+	}
+
+	feedTags, err := feedTagMap(user, service.TagRepo())
+	if err != nil {
+		return nil, err
+	}
+
+	labels, err := service.LabelRepo().ForUser(user)
+	if err != nil {
+		return nil, errors.WithMessage(err, "getting user labels")
+	}
+
+	labelNames := make(map[content.LabelID]string, len(labels))
+	for _, l := range labels {
+		labelNames[l.ID] = l.Name
+	}
+
+	articles = processor.Articles(processors).Process(articles)
+
+	for _, a := range articles {
+		categories := []string{STREAM_READING_LIST}
+		if a.Read {
+			categories = append(categories, STREAM_READ)
+		}
+
+		if a.Favorite {
+			categories = append(categories, STREAM_STARRED)
+		}
+
+		for _, t := range feedTags[a.FeedID] {
+			categories = append(categories, labelStreamID(string(t)))
+		}
+
+		for _, id := range a.Labels {
+			if name, ok := labelNames[id]; ok {
+				categories = append(categories, labelStreamID(name))
+			}
+		}
+
+		feed := feedMap[a.FeedID]
+
+		items = append(items, item{
+			ID:            longItemID(a.ID),
+			CrawlTimeMsec: strconv.FormatInt(a.Date.UnixNano()/int64(time.Millisecond), 10),
+			TimestampUsec: strconv.FormatInt(a.Date.UnixNano()/int64(time.Microsecond), 10),
+			Published:     a.Date.Unix(),
+			Updated:       a.Date.Unix(),
+			Title:         a.Title,
+			Canonical:     []link{{Href: a.Link}},
+			Alternate:     []link{{Href: a.Link, Type: "text/html"}},
+			Summary:       summary{Direction: "ltr", Content: a.Description},
+			Author:        a.Author,
+			Categories:    categories,
+			Origin: origin{
+				StreamID: feedStreamID(a.FeedID),
+				Title:    feed.Title,
+				HTMLURL:  feed.SiteLink,
+			},
+		})
+	}
+
+	return items, nil
+}
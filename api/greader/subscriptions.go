@@ -0,0 +1,119 @@
+package greader
+
+import (
+	"net/http"
+	"sort"
+
+	"github.com/pkg/errors"
+	"github.com/urandom/readeef/content"
+	"github.com/urandom/readeef/content/repo"
+	"github.com/urandom/readeef/log"
+)
+
+type category struct {
+	ID    string `json:"id"`
+	Label string `json:"label"`
+}
+
+type subscription struct {
+	ID         string     `json:"id"`
+	Title      string     `json:"title"`
+	Categories []category `json:"categories"`
+	URL        string     `json:"url"`
+	HTMLURL    string     `json:"htmlUrl"`
+}
+
+type tag struct {
+	ID   string `json:"id"`
+	Type string `json:"type,omitempty"`
+}
+
+func subscriptionList(service repo.Service, log log.Log) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user := userFromRequest(r)
+
+		feeds, err := service.FeedRepo().ForUser(user)
+		if err != nil {
+			fatal(w, log, "Error getting user feeds: %+v", err)
+			return
+		}
+
+		feedTags, err := feedTagMap(user, service.TagRepo())
+		if err != nil {
+			fatal(w, log, "Error getting feed tags: %+v", err)
+			return
+		}
+
+		subscriptions := make([]subscription, len(feeds))
+		for i, f := range feeds {
+			categories := []category{}
+			for _, t := range feedTags[f.ID] {
+				categories = append(categories, category{ID: labelStreamID(string(t)), Label: string(t)})
+			}
+
+			subscriptions[i] = subscription{
+				ID: feedStreamID(f.ID), Title: f.Title, Categories: categories,
+				URL: f.Link, HTMLURL: f.SiteLink,
+			}
+		}
+
+		writeJSON(w, map[string]interface{}{"subscriptions": subscriptions})
+	}
+}
+
+// tagList returns the state streams, the feed tags as folders, and the
+// article labels as tags.
+func tagList(service repo.Service, log log.Log) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user := userFromRequest(r)
+
+		tags, err := service.TagRepo().ForUser(user)
+		if err != nil {
+			fatal(w, log, "Error getting user tags: %+v", err)
+			return
+		}
+
+		labels, err := service.LabelRepo().ForUser(user)
+		if err != nil {
+			fatal(w, log, "Error getting user labels: %+v", err)
+			return
+		}
+
+		list := []tag{{ID: STREAM_STARRED}}
+		for _, t := range tags {
+			list = append(list, tag{ID: labelStreamID(string(t.Value)), Type: "folder"})
+		}
+
+		for _, l := range labels {
+			list = append(list, tag{ID: labelStreamID(l.Name), Type: "tag"})
+		}
+
+		writeJSON(w, map[string]interface{}{"tags": list})
+	}
+}
+
+// feedTagMap returns the sorted tag values of each of the user's feeds.
+func feedTagMap(user content.User, repo repo.Tag) (map[content.FeedID][]content.TagValue, error) {
+	tags, err := repo.ForUser(user)
+	if err != nil {
+		return nil, errors.WithMessage(err, "getting user tags")
+	}
+
+	feedTags := map[content.FeedID][]content.TagValue{}
+	for _, t := range tags {
+		ids, err := repo.FeedIDs(t, user)
+		if err != nil {
+			return nil, errors.WithMessage(err, "getting tag feed ids")
+		}
+
+		for _, id := range ids {
+			feedTags[id] = append(feedTags[id], t.Value)
+		}
+	}
+
+	for _, values := range feedTags {
+		sort.Slice(values, func(i, j int) bool { return values[i] < values[j] })
+	}
+
+	return feedTags, nil
+}
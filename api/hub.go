@@ -0,0 +1,145 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/urandom/readeef"
+	"github.com/urandom/readeef/content"
+	"github.com/urandom/readeef/log"
+)
+
+type websubHub interface {
+	Subscribe(s content.HubSubscription, lease time.Duration) error
+	Unsubscribe(s content.HubSubscription) error
+	Check()
+}
+
+// hubRegistration accepts the requests to the built-in WebSub hub. Only the
+// published and syndicated feeds under the base URL of the API may be
+// subscribed to, and the requested lease is capped by the configured one. A
+// publish request schedules a check of all topics.
+func hubRegistration(hub websubHub, baseURL string, maxLease time.Duration, log log.Log) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			http.Error(w, "Error parsing form data", http.StatusBadRequest)
+			return
+		}
+
+		mode := r.Form.Get("hub.mode")
+		if mode == "publish" {
+			hub.Check()
+			w.WriteHeader(http.StatusAccepted)
+			return
+		}
+
+		if mode != "subscribe" && mode != "unsubscribe" {
+			http.Error(w, "Unsupported hub.mode", http.StatusBadRequest)
+			return
+		}
+
+		s := content.HubSubscription{
+			Topic:    r.Form.Get("hub.topic"),
+			Callback: r.Form.Get("hub.callback"),
+			Secret:   r.Form.Get("hub.secret"),
+		}
+
+		if !isHubTopic(s.Topic, baseURL) {
+			http.Error(w, "Unsupported hub.topic", http.StatusBadRequest)
+			return
+		}
+
+		lease := maxLease
+		if v := r.Form.Get("hub.lease_seconds"); v != "" {
+			seconds, err := strconv.ParseInt(v, 10, 64)
+			if err != nil || seconds <= 0 {
+				http.Error(w, "Invalid hub.lease_seconds", http.StatusBadRequest)
+				return
+			}
+
+			if d := time.Duration(seconds) * time.Second; d < lease {
+				lease = d
+			}
+		}
+
+		log.Infof("Receiving hub %s request for %s", mode, s)
+
+		var err error
+		if mode == "subscribe" {
+			err = hub.Subscribe(s, lease)
+		} else {
+			err = hub.Unsubscribe(s)
+		}
+
+		if err != nil {
+			if content.IsValidationError(err) {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+			} else {
+				fatal(w, log, "Error registering hub request: %+v", err)
+			}
+			return
+		}
+
+		w.WriteHeader(http.StatusAccepted)
+	}
+}
+
+// isHubTopic reports whether the topic is a published or syndicated feed
+// under the base URL of the API. Since the hub fetches the topics, the host
+// of the request is never trusted.
+func isHubTopic(topic, baseURL string) bool {
+	if topic == "" || baseURL == "" {
+		return false
+	}
+
+	if !strings.HasPrefix(topic, baseURL+"/v2/published/") && !strings.HasPrefix(topic, baseURL+"/v2/syndication/") {
+		return false
+	}
+
+	u, err := url.Parse(topic)
+	if err != nil {
+		return false
+	}
+
+	// Relative segments could lead outside of the feeds
+	return path.Clean(u.Path) == strings.TrimSuffix(u.Path, "/")
+}
+
+// hubLinks advertises the hub and the canonical topic URL of the served
+// feeds, as found under the base URL of the API.
+func hubLinks(baseURL string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if i := strings.Index(r.URL.Path, "/v2/"); i != -1 {
+				self := baseURL + r.URL.Path[i:]
+				if r.URL.RawQuery != "" {
+					self += "?" + r.URL.RawQuery
+				}
+
+				w.Header().Add("Link", fmt.Sprintf(`<%s>; rel="hub"`, readeef.HubURL(self)))
+				w.Header().Add("Link", fmt.Sprintf(`<%s>; rel="self"`, self))
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// hubCheck schedules a check of the hub topics after the requests that may
+// have changed them.
+func hubCheck(hub websubHub) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			next.ServeHTTP(w, r)
+
+			if r.Method != "GET" {
+				hub.Check()
+			}
+		})
+	}
+}
@@ -0,0 +1,114 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/pkg/errors"
+	"github.com/urandom/readeef/content"
+)
+
+func Test_hubRegistration(t *testing.T) {
+	topic := "http://example.com/api/v2/published/test/abcd/atom"
+	callback := "http://sub.example.com/callback"
+
+	tests := []struct {
+		name        string
+		form        string
+		subscribe   *content.HubSubscription
+		unsubscribe *content.HubSubscription
+		lease       time.Duration
+		check       bool
+		host        string
+		err         error
+		code        int
+	}{
+		{name: "no mode", form: "hub.topic=" + topic, code: http.StatusBadRequest},
+		{name: "foreign topic", form: "hub.mode=subscribe&hub.callback=" + callback + "&hub.topic=http://other.com/api/v2/published/test/abcd/atom", code: http.StatusBadRequest},
+		{name: "forged host", form: "hub.mode=subscribe&hub.callback=" + callback + "&hub.topic=http://internal.local/api/v2/published/test/abcd/atom", host: "internal.local", code: http.StatusBadRequest},
+		{name: "relative topic", form: "hub.mode=subscribe&hub.callback=" + callback + "&hub.topic=http://example.com/api/v2/published/../../admin", code: http.StatusBadRequest},
+		{name: "not a feed", form: "hub.mode=subscribe&hub.callback=" + callback + "&hub.topic=http://example.com/api/v2/feed", code: http.StatusBadRequest},
+		{name: "invalid lease", form: "hub.mode=subscribe&hub.callback=" + callback + "&hub.topic=" + topic + "&hub.lease_seconds=-1", code: http.StatusBadRequest},
+		{name: "invalid callback", form: "hub.mode=subscribe&hub.callback=callback&hub.topic=" + topic,
+			subscribe: &content.HubSubscription{Topic: topic, Callback: "callback"}, lease: time.Hour,
+			err: content.NewValidationError(errors.New("invalid")), code: http.StatusBadRequest},
+		{name: "subscribe", form: "hub.mode=subscribe&hub.callback=" + callback + "&hub.topic=" + topic + "&hub.secret=s3cr3t&hub.lease_seconds=60",
+			subscribe: &content.HubSubscription{Topic: topic, Callback: callback, Secret: "s3cr3t"}, lease: time.Minute, code: http.StatusAccepted},
+		{name: "capped lease", form: "hub.mode=subscribe&hub.callback=" + callback + "&hub.topic=" + topic + "&hub.lease_seconds=86400",
+			subscribe: &content.HubSubscription{Topic: topic, Callback: callback}, lease: time.Hour, code: http.StatusAccepted},
+		{name: "syndication", form: "hub.mode=subscribe&hub.callback=" + callback + "&hub.topic=http://example.com/api/v2/syndication/test/abcd/json/tag/1",
+			subscribe: &content.HubSubscription{Topic: "http://example.com/api/v2/syndication/test/abcd/json/tag/1", Callback: callback}, lease: time.Hour, code: http.StatusAccepted},
+		{name: "unsubscribe", form: "hub.mode=unsubscribe&hub.callback=" + callback + "&hub.topic=" + topic,
+			unsubscribe: &content.HubSubscription{Topic: topic, Callback: callback}, code: http.StatusAccepted},
+		{name: "hub err", form: "hub.mode=unsubscribe&hub.callback=" + callback + "&hub.topic=" + topic,
+			unsubscribe: &content.HubSubscription{Topic: topic, Callback: callback}, err: errors.New("err"), code: http.StatusInternalServerError},
+		{name: "publish", form: "hub.mode=publish&hub.url=" + topic, check: true, code: http.StatusAccepted},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			hub := NewMockwebsubHub(ctrl)
+
+			r := httptest.NewRequest("POST", "http://example.com/api/v2/hub", strings.NewReader(tt.form))
+			r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			if tt.host != "" {
+				r.Host = tt.host
+			}
+			w := httptest.NewRecorder()
+
+			if tt.subscribe != nil {
+				hub.EXPECT().Subscribe(*tt.subscribe, tt.lease).Return(tt.err)
+			}
+
+			if tt.unsubscribe != nil {
+				hub.EXPECT().Unsubscribe(*tt.unsubscribe).Return(tt.err)
+			}
+
+			if tt.check {
+				hub.EXPECT().Check()
+			}
+
+			hubRegistration(hub, "http://example.com/api", time.Hour, logger).ServeHTTP(w, r)
+
+			if w.Code != tt.code {
+				t.Errorf("hubRegistration() code = %v, want %v", w.Code, tt.code)
+			}
+		})
+	}
+}
+
+func Test_hubLinks(t *testing.T) {
+	r := httptest.NewRequest("GET", "http://internal.local/api/v2/syndication/test/abcd/rss/favorite?limit=10", nil)
+	w := httptest.NewRecorder()
+
+	hubLinks("http://example.com/api")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})).ServeHTTP(w, r)
+
+	want := []string{
+		`<http://example.com/api/v2/hub>; rel="hub"`,
+		`<http://example.com/api/v2/syndication/test/abcd/rss/favorite?limit=10>; rel="self"`,
+	}
+
+	if got := w.Header()["Link"]; strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("hubLinks() = %v, want %v", got, want)
+	}
+}
+
+func Test_hubCheck(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	hub := NewMockwebsubHub(ctrl)
+	hub.EXPECT().Check()
+
+	handler := hubCheck(hub)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("POST", "/", nil))
+}
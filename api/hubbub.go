@@ -1,10 +1,17 @@
 package api
 
 import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
 	"fmt"
+	"hash"
 	"net/http"
 	"path"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/urandom/readeef/content"
@@ -22,6 +29,11 @@ func hubbubRegistration(
 	subRepo := service.SubscriptionRepo()
 
 	return func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			http.Error(w, "Error parsing form data", http.StatusBadRequest)
+			return
+		}
+
 		feedID, err := strconv.ParseInt(path.Base(r.URL.Path), 10, 64)
 
 		if err != nil {
@@ -43,6 +55,16 @@ func hubbubRegistration(
 
 		log.Infoln("Receiving hubbub event " + r.Form.Get("hub.mode") + " for " + f.String())
 
+		// The verifications are only for the feed's own topic
+		switch r.Form.Get("hub.mode") {
+		case "subscribe", "unsubscribe", "denied":
+			if topic := r.Form.Get("hub.topic"); topic != f.Link {
+				log.Printf("Rejecting hubbub %s of topic '%s' for %s", r.Form.Get("hub.mode"), topic, f)
+				http.Error(w, "Unknown hub.topic", http.StatusNotFound)
+				return
+			}
+		}
+
 		switch r.Form.Get("hub.mode") {
 		case "subscribe":
 			if lease, err := strconv.Atoi(r.Form.Get("hub.lease_seconds")); err == nil {
@@ -59,6 +81,14 @@ func hubbubRegistration(
 		case "denied":
 			w.Write([]byte{})
 			log.Printf("Unable to subscribe to '%s': %s\n", r.Form.Get("hub.topic"), r.Form.Get("hub.reason"))
+
+			// The feed falls back to polling until it is subscribed again
+			s.SubscriptionFailure = true
+
+			f.SubscribeError = fmt.Sprintf("%s: Subscription denied: %s", time.Now().Format(time.UnixDate), r.Form.Get("hub.reason"))
+			if _, err = feedRepo.Update(&f); err != nil {
+				log.Printf("Error updating feed %s: %+v", f, err)
+			}
 		default:
 			w.Write([]byte{})
 
@@ -70,7 +100,14 @@ func hubbubRegistration(
 				return
 			}
 
-			if pf, err := parser.ParseFeed(buf.Bytes(), parser.ParseRss2, parser.ParseAtom, parser.ParseRss1); err == nil {
+			// Content that isn't signed with the subscription secret is
+			// acknowledged, but ignored.
+			if s.Secret != "" && !validHubSignature(r.Header.Get("X-Hub-Signature"), s.Secret, buf.Bytes()) {
+				log.Printf("Ignoring content with an invalid signature for subscription %s", s)
+				return
+			}
+
+			if pf, err := parser.ParseFeed(buf.Bytes(), parser.ParseJSONFeed, parser.ParseRss2, parser.ParseAtom, parser.ParseRss1); err == nil {
 				f.Refresh(pf)
 
 				if _, err = feedRepo.Update(&f); err != nil {
@@ -82,7 +119,7 @@ func hubbubRegistration(
 				return
 			}
 
-			return
+			s.LastPush = time.Now()
 		}
 
 		if err = subRepo.Update(s); err != nil {
@@ -91,3 +128,75 @@ func hubbubRegistration(
 		}
 	}
 }
+
+type feedSubscription struct {
+	Hub         string                    `json:"hub"`
+	State       content.SubscriptionState `json:"state"`
+	LeaseExpiry time.Time                 `json:"leaseExpiry"`
+	LastPush    time.Time                 `json:"lastPush"`
+	Signed      bool                      `json:"signed"`
+	Error       string                    `json:"error,omitempty"`
+}
+
+// getFeedSubscription shows the state of the hub subscription of the feed.
+// Feeds without a subscription get a null one.
+func getFeedSubscription(repo repo.Subscription, silence time.Duration, log log.Log) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		feed, stop := feedFromRequest(w, r)
+		if stop {
+			return
+		}
+
+		s, err := repo.Get(feed)
+		if err != nil {
+			if content.IsNoContent(err) {
+				args{"subscription": nil}.WriteJSON(w)
+			} else {
+				fatal(w, log, "Error getting feed subscription: %+v", err)
+			}
+			return
+		}
+
+		resp := feedSubscription{
+			Hub:      s.Link,
+			State:    s.State(silence),
+			LastPush: s.LastPush,
+			Signed:   s.Secret != "",
+			Error:    feed.SubscribeError,
+		}
+
+		if s.LeaseDuration > 0 {
+			resp.LeaseExpiry = s.LeaseExpiry()
+		}
+
+		args{"subscription": resp}.WriteJSON(w)
+	}
+}
+
+// validHubSignature checks the X-Hub-Signature header of pushed content,
+// which holds the HMAC of the body, keyed with the subscription secret.
+func validHubSignature(header, secret string, body []byte) bool {
+	parts := strings.SplitN(header, "=", 2)
+	if len(parts) != 2 {
+		return false
+	}
+
+	var h func() hash.Hash
+	switch parts[0] {
+	case "sha1":
+		h = sha1.New
+	case "sha256":
+		h = sha256.New
+	case "sha384":
+		h = sha512.New384
+	case "sha512":
+		h = sha512.New
+	default:
+		return false
+	}
+
+	mac := hmac.New(h, []byte(secret))
+	mac.Write(body)
+
+	return hmac.Equal([]byte(strings.ToLower(parts[1])), []byte(hex.EncodeToString(mac.Sum(nil))))
+}
@@ -1,8 +1,16 @@
 package api
 
 import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"reflect"
 	"strings"
 	"testing"
@@ -30,19 +38,31 @@ func Test_hubbubRegistration(t *testing.T) {
 		hasFeedXML    bool
 		hasFeedXMLErr bool
 		updateFeedErr error
+		denied        bool
+		signature     string
+		ignored       bool
+		otherTopic    bool
 		response      []byte
 	}{
 		{name: "invalid url", url: "/whatever", hasURLErr: true},
 		{name: "get feed err", url: "/feed/12", feedID: 12, feedErr: errors.New("get feed err")},
-		{name: "get sub err", url: "/feed/12", feedID: 12, feed: content.Feed{ID: 12}, subErr: errors.New("get sub err")},
-		{name: "subscription", url: "/feed/12", form: "hub.mode=subscribe&hub.challenge=test", feedID: 12, feed: content.Feed{ID: 12}, sub: content.Subscription{FeedID: 12, SubscriptionFailure: true}, updateSub: content.Subscription{FeedID: 12, VerificationTime: time.Now()}, response: []byte("test")},
-		{name: "subscription with lease", url: "/feed/12", form: "hub.mode=subscribe&hub.lease_seconds=300&hub.challenge=test", feedID: 12, feed: content.Feed{ID: 12}, sub: content.Subscription{FeedID: 12, SubscriptionFailure: true}, updateSub: content.Subscription{FeedID: 12, VerificationTime: time.Now(), LeaseDuration: int64(300 * time.Second)}, response: []byte("test")},
-		{name: "unsubscribe", url: "/feed/12", form: "hub.mode=unsubscribe&hub.challenge=test", feedID: 12, feed: content.Feed{ID: 12}, sub: content.Subscription{FeedID: 12}, updateSub: content.Subscription{FeedID: 12, SubscriptionFailure: true}, response: []byte("test")},
-		{name: "denied", url: "/feed/12", form: "hub.mode=denied", feedID: 12, feed: content.Feed{ID: 12}, sub: content.Subscription{FeedID: 12}, updateSub: content.Subscription{FeedID: 12}},
-		{name: "update error", url: "/feed/12", form: "hub.mode=denied", feedID: 12, feed: content.Feed{ID: 12}, sub: content.Subscription{FeedID: 12}, updateSub: content.Subscription{FeedID: 12}, updateSubErr: errors.New("err")},
-		{name: "feed update", url: "/feed/12", form: singleAtomXML, feedID: 12, feed: content.Feed{ID: 12}, sub: content.Subscription{FeedID: 12}, hasFeedXML: true},
-		{name: "unknown feed format", url: "/feed/12", form: "not-a-feed-xml-format", feedID: 12, feed: content.Feed{ID: 12}, sub: content.Subscription{FeedID: 12}, hasFeedXML: true, hasFeedXMLErr: true},
-		{name: "feed update err", url: "/feed/12", form: singleAtomXML, feedID: 12, feed: content.Feed{ID: 12}, sub: content.Subscription{FeedID: 12}, hasFeedXML: true, updateFeedErr: errors.New("update feed err")},
+		{name: "get sub err", url: "/feed/12", feedID: 12, feed: content.Feed{ID: 12, Link: testHubTopic}, subErr: errors.New("get sub err")},
+		{name: "subscription", url: "/feed/12", form: "hub.mode=subscribe&hub.challenge=test&hub.topic=" + url.QueryEscape(testHubTopic), feedID: 12, feed: content.Feed{ID: 12, Link: testHubTopic}, sub: content.Subscription{FeedID: 12, SubscriptionFailure: true}, updateSub: content.Subscription{FeedID: 12, VerificationTime: time.Now()}, response: []byte("test")},
+		{name: "subscription with lease", url: "/feed/12", form: "hub.mode=subscribe&hub.lease_seconds=300&hub.challenge=test&hub.topic=" + url.QueryEscape(testHubTopic), feedID: 12, feed: content.Feed{ID: 12, Link: testHubTopic}, sub: content.Subscription{FeedID: 12, SubscriptionFailure: true}, updateSub: content.Subscription{FeedID: 12, VerificationTime: time.Now(), LeaseDuration: int64(300 * time.Second)}, response: []byte("test")},
+		{name: "unsubscribe", url: "/feed/12", form: "hub.mode=unsubscribe&hub.challenge=test&hub.topic=" + url.QueryEscape(testHubTopic), feedID: 12, feed: content.Feed{ID: 12, Link: testHubTopic}, sub: content.Subscription{FeedID: 12}, updateSub: content.Subscription{FeedID: 12, SubscriptionFailure: true}, response: []byte("test")},
+		{name: "denied", url: "/feed/12", form: "hub.mode=denied&hub.topic=" + url.QueryEscape(testHubTopic), feedID: 12, feed: content.Feed{ID: 12, Link: testHubTopic}, sub: content.Subscription{FeedID: 12}, updateSub: content.Subscription{FeedID: 12, SubscriptionFailure: true}, denied: true},
+		{name: "update error", url: "/feed/12", form: "hub.mode=denied&hub.topic=" + url.QueryEscape(testHubTopic), feedID: 12, feed: content.Feed{ID: 12, Link: testHubTopic}, sub: content.Subscription{FeedID: 12}, updateSub: content.Subscription{FeedID: 12, SubscriptionFailure: true}, updateSubErr: errors.New("err"), denied: true},
+		{name: "subscription of another topic", url: "/feed/12", form: "hub.mode=subscribe&hub.challenge=test&hub.topic=http://example.com/other", feedID: 12, feed: content.Feed{ID: 12, Link: testHubTopic}, sub: content.Subscription{FeedID: 12, SubscriptionFailure: true}, otherTopic: true},
+		{name: "unsubscribe without topic", url: "/feed/12", form: "hub.mode=unsubscribe&hub.challenge=test", feedID: 12, feed: content.Feed{ID: 12, Link: testHubTopic}, sub: content.Subscription{FeedID: 12}, otherTopic: true},
+		{name: "denied of another topic", url: "/feed/12", form: "hub.mode=denied&hub.topic=http://example.com/other", feedID: 12, feed: content.Feed{ID: 12, Link: testHubTopic}, sub: content.Subscription{FeedID: 12}, otherTopic: true},
+		{name: "feed update", url: "/feed/12", form: singleAtomXML, feedID: 12, feed: content.Feed{ID: 12, Link: testHubTopic}, sub: content.Subscription{FeedID: 12}, hasFeedXML: true, updateSub: content.Subscription{FeedID: 12, LastPush: time.Now()}},
+		{name: "sha1 signature", url: "/feed/12", form: singleAtomXML, feedID: 12, feed: content.Feed{ID: 12, Link: testHubTopic}, sub: content.Subscription{FeedID: 12, Secret: "s3cr3t"}, hasFeedXML: true, signature: "sha1=" + testHubSignature(sha1.New, "s3cr3t", singleAtomXML), updateSub: content.Subscription{FeedID: 12, LastPush: time.Now()}},
+		{name: "sha256 signature", url: "/feed/12", form: singleAtomXML, feedID: 12, feed: content.Feed{ID: 12, Link: testHubTopic}, sub: content.Subscription{FeedID: 12, Secret: "s3cr3t"}, hasFeedXML: true, signature: "sha256=" + testHubSignature(sha256.New, "s3cr3t", singleAtomXML), updateSub: content.Subscription{FeedID: 12, LastPush: time.Now()}},
+		{name: "invalid signature", url: "/feed/12", form: singleAtomXML, feedID: 12, feed: content.Feed{ID: 12, Link: testHubTopic}, sub: content.Subscription{FeedID: 12, Secret: "s3cr3t"}, hasFeedXML: true, signature: "sha256=" + testHubSignature(sha256.New, "other", singleAtomXML), ignored: true},
+		{name: "unsupported signature", url: "/feed/12", form: singleAtomXML, feedID: 12, feed: content.Feed{ID: 12, Link: testHubTopic}, sub: content.Subscription{FeedID: 12, Secret: "s3cr3t"}, hasFeedXML: true, signature: "md5=abcd", ignored: true},
+		{name: "missing signature", url: "/feed/12", form: singleAtomXML, feedID: 12, feed: content.Feed{ID: 12, Link: testHubTopic}, sub: content.Subscription{FeedID: 12, Secret: "s3cr3t"}, hasFeedXML: true, ignored: true},
+		{name: "unknown feed format", url: "/feed/12", form: "not-a-feed-xml-format", feedID: 12, feed: content.Feed{ID: 12, Link: testHubTopic}, sub: content.Subscription{FeedID: 12}, hasFeedXML: true, hasFeedXMLErr: true},
+		{name: "feed update err", url: "/feed/12", form: singleAtomXML, feedID: 12, feed: content.Feed{ID: 12, Link: testHubTopic}, sub: content.Subscription{FeedID: 12}, hasFeedXML: true, updateFeedErr: errors.New("update feed err")},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -59,6 +79,9 @@ func Test_hubbubRegistration(t *testing.T) {
 			if !tt.hasFeedXML {
 				r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
 			}
+			if tt.signature != "" {
+				r.Header.Set("X-Hub-Signature", tt.signature)
+			}
 			r.ParseForm()
 			w := httptest.NewRecorder()
 
@@ -84,12 +107,24 @@ func Test_hubbubRegistration(t *testing.T) {
 					break
 				}
 
+				if tt.otherTopic {
+					code = http.StatusNotFound
+					break
+				}
+
 				if tt.hasFeedXML {
-					if !tt.hasFeedXMLErr {
+					if !tt.hasFeedXMLErr && !tt.ignored {
 						feedRepo.EXPECT().Update(gomock.Any()).Return(nil, tt.updateFeedErr)
+						if tt.updateFeedErr == nil {
+							subRepo.EXPECT().Update(subscriptionMatcher{tt.updateSub}).Return(nil)
+						}
 					}
 					break
 				} else {
+					if tt.denied {
+						feedRepo.EXPECT().Update(gomock.Any()).Return(nil, nil)
+					}
+
 					subRepo.EXPECT().Update(subscriptionMatcher{tt.updateSub}).Return(tt.updateSubErr)
 				}
 
@@ -112,6 +147,60 @@ func Test_hubbubRegistration(t *testing.T) {
 	}
 }
 
+const testHubTopic = "http://example.com/feed.xml"
+
+func Test_getFeedSubscription(t *testing.T) {
+	now := time.Now().Truncate(time.Second)
+
+	tests := []struct {
+		name    string
+		hasFeed bool
+		feed    content.Feed
+		sub     content.Subscription
+		subErr  error
+		want    string
+		code    int
+	}{
+		{name: "no feed", code: http.StatusBadRequest},
+		{name: "not subscribed", hasFeed: true, feed: content.Feed{ID: 1}, subErr: content.ErrNoContent, want: `{"subscription":null}`, code: http.StatusOK},
+		{name: "sub err", hasFeed: true, feed: content.Feed{ID: 1}, subErr: errors.New("sub err"), code: http.StatusInternalServerError},
+		{name: "active", hasFeed: true, feed: content.Feed{ID: 1},
+			sub:  content.Subscription{FeedID: 1, Link: "http://hub.example.com", Secret: "s3cr3t", VerificationTime: now, LastPush: now, LeaseDuration: int64(time.Hour)},
+			want: fmt.Sprintf(`{"subscription":{"hub":"http://hub.example.com","state":"active","leaseExpiry":%q,"lastPush":%q,"signed":true}}`, now.Add(time.Hour).Format(time.RFC3339Nano), now.Format(time.RFC3339Nano)), code: http.StatusOK},
+		{name: "denied", hasFeed: true, feed: content.Feed{ID: 1, SubscribeError: "denied"},
+			sub:  content.Subscription{FeedID: 1, Link: "http://hub.example.com", SubscriptionFailure: true},
+			want: `{"subscription":{"hub":"http://hub.example.com","state":"unverified","leaseExpiry":"0001-01-01T00:00:00Z","lastPush":"0001-01-01T00:00:00Z","signed":false,"error":"denied"}}`, code: http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			subRepo := mock_repo.NewMockSubscription(ctrl)
+
+			r := httptest.NewRequest("GET", "/", nil)
+			w := httptest.NewRecorder()
+
+			if tt.hasFeed {
+				r = r.WithContext(context.WithValue(r.Context(), feedKey, tt.feed))
+				subRepo.EXPECT().Get(tt.feed).Return(tt.sub, tt.subErr)
+			}
+
+			getFeedSubscription(subRepo, 24*time.Hour, logger).ServeHTTP(w, r)
+
+			if w.Code != tt.code {
+				t.Errorf("getFeedSubscription() code = %v, want %v", w.Code, tt.code)
+				return
+			}
+
+			if tt.code == http.StatusOK {
+				if got := strings.TrimSpace(w.Body.String()); got != tt.want {
+					t.Errorf("getFeedSubscription() body = %s, want %s", got, tt.want)
+				}
+			}
+		})
+	}
+}
+
 type subscriptionMatcher struct{ subscription content.Subscription }
 
 func (u subscriptionMatcher) Matches(x interface{}) bool {
@@ -120,7 +209,8 @@ func (u subscriptionMatcher) Matches(x interface{}) bool {
 			u.subscription.Link == subscription.Link &&
 			u.subscription.LeaseDuration == subscription.LeaseDuration &&
 			u.subscription.SubscriptionFailure == subscription.SubscriptionFailure &&
-			u.subscription.VerificationTime.Sub(subscription.VerificationTime) < time.Second
+			u.subscription.VerificationTime.Sub(subscription.VerificationTime) < time.Second &&
+			u.subscription.LastPush.Sub(subscription.LastPush) < time.Second
 	}
 	return false
 }
@@ -129,6 +219,13 @@ func (u subscriptionMatcher) String() string {
 	return "Matches by certain subscription fields"
 }
 
+func testHubSignature(h func() hash.Hash, secret, body string) string {
+	mac := hmac.New(h, []byte(secret))
+	mac.Write([]byte(body))
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
 const (
 	singleAtomXML = `
 <feed xmlns="http://www.w3.org/2005/Atom" updated="2003-12-13T18:30:02Z">
@@ -0,0 +1,180 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi"
+	"github.com/urandom/readeef/content"
+	"github.com/urandom/readeef/content/repo"
+	"github.com/urandom/readeef/log"
+)
+
+var labelKey = contextKey("label")
+
+func listLabels(repo repo.Label, log log.Log) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user, stop := userFromRequest(w, r)
+		if stop {
+			return
+		}
+
+		labels, err := repo.ForUser(user)
+		if err != nil {
+			fatal(w, log, "Error getting labels: %+v", err)
+			return
+		}
+
+		args{"labels": labels}.WriteJSON(w)
+	}
+}
+
+func getLabel(w http.ResponseWriter, r *http.Request) {
+	label, stop := labelFromRequest(w, r)
+	if stop {
+		return
+	}
+
+	args{"label": label}.WriteJSON(w)
+}
+
+// updateLabel creates a new label, or renames the one in the request
+// context, using the name form value.
+func updateLabel(repo repo.Label, log log.Log) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user, stop := userFromRequest(w, r)
+		if stop {
+			return
+		}
+
+		label, ok := r.Context().Value(labelKey).(content.Label)
+		if !ok {
+			label = content.Label{UserLogin: user.Login}
+		}
+
+		label.Name = r.Form.Get("name")
+
+		if err := label.Validate(); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		labels, err := repo.ForUser(user)
+		if err != nil {
+			fatal(w, log, "Error getting labels: %+v", err)
+			return
+		}
+
+		for _, l := range labels {
+			if l.Name == label.Name && l.ID != label.ID {
+				http.Error(w, "Label already exists", http.StatusConflict)
+				return
+			}
+		}
+
+		if err := repo.Update(&label); err != nil {
+			fatal(w, log, "Error updating label: %+v", err)
+			return
+		}
+
+		args{"label": label}.WriteJSON(w)
+	}
+}
+
+func deleteLabel(repo repo.Label, log log.Log) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		label, stop := labelFromRequest(w, r)
+		if stop {
+			return
+		}
+
+		if err := repo.Delete(label); err != nil {
+			fatal(w, log, "Error deleting label: %+v", err)
+			return
+		}
+
+		args{"success": true}.WriteJSON(w)
+	}
+}
+
+// labelArticles assigns the label to the articles given by the id form
+// values for POST requests, and removes it from them otherwise.
+func labelArticles(repo repo.Label, log log.Log) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		label, stop := labelFromRequest(w, r)
+		if stop {
+			return
+		}
+
+		if len(r.Form["id"]) == 0 {
+			http.Error(w, "No article ids", http.StatusBadRequest)
+			return
+		}
+
+		ids := make([]content.ArticleID, len(r.Form["id"]))
+		for i, v := range r.Form["id"] {
+			id, err := strconv.ParseInt(v, 10, 64)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+
+			ids[i] = content.ArticleID(id)
+		}
+
+		var err error
+		if r.Method == http.MethodPost {
+			err = repo.Assign(label, ids)
+		} else {
+			err = repo.Unassign(label, ids)
+		}
+
+		if err != nil {
+			fatal(w, log, "Error setting label articles: %+v", err)
+			return
+		}
+
+		args{"success": true}.WriteJSON(w)
+	}
+}
+
+func labelContext(repo repo.Label, log log.Log) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			user, stop := userFromRequest(w, r)
+			if stop {
+				return
+			}
+
+			id, err := strconv.ParseInt(chi.URLParam(r, "labelID"), 10, 64)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+
+			label, err := repo.Get(content.LabelID(id), user)
+			if err != nil {
+				if content.IsNoContent(err) {
+					http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+				} else {
+					fatal(w, log, "Error getting label: %+v", err)
+				}
+				return
+			}
+
+			ctx := context.WithValue(r.Context(), labelKey, label)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+func labelFromRequest(w http.ResponseWriter, r *http.Request) (label content.Label, stop bool) {
+	var ok bool
+	if label, ok = r.Context().Value(labelKey).(content.Label); ok {
+		return label, false
+	}
+
+	http.Error(w, "Bad Request", http.StatusBadRequest)
+	return content.Label{}, true
+}
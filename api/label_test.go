@@ -0,0 +1,312 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/pkg/errors"
+	"github.com/urandom/readeef/content"
+	"github.com/urandom/readeef/content/repo/mock_repo"
+)
+
+func Test_listLabels(t *testing.T) {
+	tests := []struct {
+		name    string
+		hasUser bool
+		listErr error
+	}{
+		{"no user", false, nil},
+		{"success list", true, nil},
+		{"list error", true, errors.New("list err")},
+	}
+
+	type data struct {
+		Labels []content.Label `json:"labels"`
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			labelRepo := mock_repo.NewMockLabel(ctrl)
+
+			r := httptest.NewRequest("GET", "/", nil)
+			w := httptest.NewRecorder()
+
+			code := http.StatusBadRequest
+			want := data{}
+			if tt.hasUser {
+				u := content.User{Login: "test"}
+				r = r.WithContext(context.WithValue(r.Context(), userKey, u))
+
+				var labels []content.Label
+				if tt.listErr == nil {
+					code = http.StatusOK
+					labels = []content.Label{{ID: 1, Name: "Later"}, {ID: 2, Name: "Work"}}
+					want.Labels = labels
+				} else {
+					code = http.StatusInternalServerError
+				}
+
+				labelRepo.EXPECT().ForUser(userMatcher{u}).Return(labels, tt.listErr)
+			}
+
+			listLabels(labelRepo, logger).ServeHTTP(w, r)
+
+			if w.Code != code {
+				t.Errorf("listLabels() code = %v, want %v", w.Code, code)
+				return
+			}
+
+			var got data
+			if err := json.Unmarshal(w.Body.Bytes(), &got); (err != nil) && (w.Code == http.StatusOK) {
+				t.Errorf("listLabels() body = '%s', error = %v", w.Body, err)
+				return
+			}
+
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("listLabels() got = %v, want = %v", got, want)
+			}
+		})
+	}
+}
+
+func Test_updateLabel(t *testing.T) {
+	existing := []content.Label{{ID: 1, UserLogin: "test", Name: "Later"}}
+
+	tests := []struct {
+		name      string
+		hasUser   bool
+		existing  *content.Label
+		form      string
+		want      content.Label
+		updateErr error
+		code      int
+	}{
+		{name: "no user", code: http.StatusBadRequest},
+		{name: "no name", hasUser: true, form: "name=+", code: http.StatusBadRequest},
+		{name: "duplicate", hasUser: true, form: "name=Later", code: http.StatusConflict},
+		{name: "create", hasUser: true, form: "name=Work", want: content.Label{ID: 2, UserLogin: "test", Name: "Work"}, code: http.StatusOK},
+		{name: "rename", hasUser: true, existing: &existing[0], form: "name=Later", want: content.Label{ID: 1, UserLogin: "test", Name: "Later"}, code: http.StatusOK},
+		{name: "update err", hasUser: true, form: "name=Work", updateErr: errors.New("err"), want: content.Label{UserLogin: "test", Name: "Work"}, code: http.StatusInternalServerError},
+	}
+
+	type data struct {
+		Label content.Label `json:"label"`
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			labelRepo := mock_repo.NewMockLabel(ctrl)
+
+			r := httptest.NewRequest("POST", "/", strings.NewReader(tt.form))
+			r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			r.ParseForm()
+			w := httptest.NewRecorder()
+
+			if tt.hasUser {
+				user := content.User{Login: "test"}
+				r = r.WithContext(context.WithValue(r.Context(), userKey, user))
+
+				if strings.TrimSpace(r.Form.Get("name")) != "" {
+					labelRepo.EXPECT().ForUser(userMatcher{user}).Return(existing, nil)
+				}
+			}
+
+			if tt.existing != nil {
+				r = r.WithContext(context.WithValue(r.Context(), labelKey, *tt.existing))
+			}
+
+			if tt.want.UserLogin != "" {
+				labelRepo.EXPECT().Update(gomock.Any()).DoAndReturn(func(label *content.Label) error {
+					want := tt.want
+					if tt.existing == nil {
+						want.ID = 0
+					}
+
+					if !reflect.DeepEqual(*label, want) {
+						t.Errorf("updateLabel() label = %v, want %v", *label, want)
+					}
+
+					label.ID = tt.want.ID
+
+					return tt.updateErr
+				})
+			}
+
+			updateLabel(labelRepo, logger).ServeHTTP(w, r)
+
+			if w.Code != tt.code {
+				t.Errorf("updateLabel() code = %v, want %v", w.Code, tt.code)
+				return
+			}
+
+			if tt.code != http.StatusOK {
+				return
+			}
+
+			var got data
+			if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+				t.Errorf("updateLabel() body = '%s', error = %v", w.Body, err)
+				return
+			}
+
+			want := tt.want
+			want.UserLogin = ""
+			if !reflect.DeepEqual(got.Label, want) {
+				t.Errorf("updateLabel() got = %v, want = %v", got.Label, want)
+			}
+		})
+	}
+}
+
+func Test_deleteLabel(t *testing.T) {
+	tests := []struct {
+		name      string
+		hasLabel  bool
+		deleteErr error
+		code      int
+	}{
+		{"no label", false, nil, http.StatusBadRequest},
+		{"delete err", true, errors.New("err"), http.StatusInternalServerError},
+		{"success", true, nil, http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			labelRepo := mock_repo.NewMockLabel(ctrl)
+
+			r := httptest.NewRequest("DELETE", "/", nil)
+			w := httptest.NewRecorder()
+
+			if tt.hasLabel {
+				label := content.Label{ID: 1, UserLogin: "test"}
+				r = r.WithContext(context.WithValue(r.Context(), labelKey, label))
+
+				labelRepo.EXPECT().Delete(label).Return(tt.deleteErr)
+			}
+
+			deleteLabel(labelRepo, logger).ServeHTTP(w, r)
+
+			if w.Code != tt.code {
+				t.Errorf("deleteLabel() code = %v, want %v", w.Code, tt.code)
+			}
+		})
+	}
+}
+
+func Test_labelArticles(t *testing.T) {
+	tests := []struct {
+		name     string
+		method   string
+		hasLabel bool
+		form     string
+		ids      []content.ArticleID
+		err      error
+		code     int
+	}{
+		{name: "no label", method: "POST", form: "id=1", code: http.StatusBadRequest},
+		{name: "no ids", method: "POST", hasLabel: true, code: http.StatusBadRequest},
+		{name: "invalid id", method: "POST", hasLabel: true, form: "id=1&id=foo", code: http.StatusBadRequest},
+		{name: "assign", method: "POST", hasLabel: true, form: "id=1&id=2", ids: []content.ArticleID{1, 2}, code: http.StatusOK},
+		{name: "assign err", method: "POST", hasLabel: true, form: "id=1", ids: []content.ArticleID{1}, err: errors.New("err"), code: http.StatusInternalServerError},
+		{name: "unassign", method: "DELETE", hasLabel: true, form: "id=3", ids: []content.ArticleID{3}, code: http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			labelRepo := mock_repo.NewMockLabel(ctrl)
+
+			r := httptest.NewRequest(tt.method, "/?"+tt.form, nil)
+			r.ParseForm()
+			w := httptest.NewRecorder()
+
+			if tt.hasLabel {
+				label := content.Label{ID: 1, UserLogin: "test"}
+				r = r.WithContext(context.WithValue(r.Context(), labelKey, label))
+
+				if tt.ids != nil {
+					if tt.method == "POST" {
+						labelRepo.EXPECT().Assign(label, tt.ids).Return(tt.err)
+					} else {
+						labelRepo.EXPECT().Unassign(label, tt.ids).Return(tt.err)
+					}
+				}
+			}
+
+			labelArticles(labelRepo, logger).ServeHTTP(w, r)
+
+			if w.Code != tt.code {
+				t.Errorf("labelArticles() code = %v, want %v", w.Code, tt.code)
+			}
+		})
+	}
+}
+
+func Test_labelContext(t *testing.T) {
+	tests := []struct {
+		name    string
+		hasUser bool
+		hasID   bool
+		getErr  error
+	}{
+		{"no user", false, false, nil},
+		{"no id", true, false, nil},
+		{"get err", true, true, errors.New("get err")},
+		{"label value", true, true, nil},
+		{"unknown label", true, true, content.ErrNoContent},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			labelRepo := mock_repo.NewMockLabel(ctrl)
+
+			r := httptest.NewRequest("GET", "/", nil)
+			w := httptest.NewRecorder()
+
+			code := http.StatusBadRequest
+			if tt.hasUser {
+				user := content.User{Login: "test"}
+				r = r.WithContext(context.WithValue(r.Context(), userKey, user))
+
+				if tt.hasID {
+					r = addChiParam(r, "labelID", "1")
+					label := content.Label{}
+					if tt.getErr == nil {
+						code = http.StatusNoContent
+						label.ID = 1
+					} else if content.IsNoContent(tt.getErr) {
+						code = http.StatusNotFound
+					} else {
+						code = http.StatusInternalServerError
+					}
+
+					labelRepo.EXPECT().Get(content.LabelID(1), userMatcher{user}).Return(label, tt.getErr)
+				} else {
+					r = addChiParam(r, "labelID", "foo")
+				}
+			}
+
+			labelContext(labelRepo, logger)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if label, ok := r.Context().Value(labelKey).(content.Label); !ok || label.ID != 1 {
+					t.Errorf("labelContext() label ctx value = %v", r.Context().Value(labelKey))
+					return
+				}
+				w.WriteHeader(http.StatusNoContent)
+			})).ServeHTTP(w, r)
+
+			if w.Code != code {
+				t.Errorf("labelContext() code = %v, want %v", w.Code, code)
+			}
+		})
+	}
+}
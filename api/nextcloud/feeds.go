@@ -0,0 +1,369 @@
+package nextcloud
+
+import (
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+
+	"github.com/go-chi/chi"
+	"github.com/pkg/errors"
+	"github.com/urandom/readeef/content"
+	"github.com/urandom/readeef/content/repo"
+	"github.com/urandom/readeef/log"
+)
+
+type folder struct {
+	ID   content.TagID    `json:"id"`
+	Name content.TagValue `json:"name"`
+}
+
+type feed struct {
+	ID               content.FeedID `json:"id"`
+	URL              string         `json:"url"`
+	Title            string         `json:"title"`
+	FaviconLink      *string        `json:"faviconLink"`
+	Added            int64          `json:"added"`
+	FolderID         *content.TagID `json:"folderId"`
+	UnreadCount      int64          `json:"unreadCount"`
+	Ordering         int            `json:"ordering"`
+	Link             string         `json:"link"`
+	Pinned           bool           `json:"pinned"`
+	UpdateErrorCount int            `json:"updateErrorCount"`
+	LastUpdateError  string         `json:"lastUpdateError"`
+}
+
+func getFolders(service repo.Service, log log.Log) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		tags, err := service.TagRepo().ForUser(userFromRequest(r))
+		if err != nil {
+			fatal(w, log, "Error getting user tags: %+v", err)
+			return
+		}
+
+		folders := make([]folder, len(tags))
+		for i, t := range tags {
+			folders[i] = folder{ID: t.ID, Name: t.Value}
+		}
+
+		writeJSON(w, map[string]interface{}{"folders": folders})
+	}
+}
+
+func markFolderRead(service repo.Service, log log.Log) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user := userFromRequest(r)
+
+		req, err := readRequest(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		id, _ := strconv.ParseInt(chi.URLParam(r, "folderID"), 10, 64)
+
+		ids, err := folderFeedIDs(content.TagID(id), user, service.TagRepo())
+		if err != nil {
+			if content.IsNoContent(err) {
+				http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+			} else {
+				fatal(w, log, "Error getting folder feeds: %+v", err)
+			}
+			return
+		}
+
+		if err := service.ArticleRepo().Read(true, user,
+			content.FeedIDs(ids),
+			content.IDRange(0, content.ArticleID(req.NewestItemID+1)),
+		); err != nil {
+			fatal(w, log, "Error marking folder as read: %+v", err)
+			return
+		}
+
+		writeEmpty(w)
+	}
+}
+
+func getFeeds(service repo.Service, log log.Log) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user := userFromRequest(r)
+
+		feeds, err := service.FeedRepo().ForUser(user)
+		if err != nil {
+			fatal(w, log, "Error getting user feeds: %+v", err)
+			return
+		}
+
+		data := make([]feed, 0, len(feeds))
+		for _, f := range feeds {
+			converted, err := convertFeed(f, user, service)
+			if err != nil {
+				fatal(w, log, "Error converting feed: %+v", err)
+				return
+			}
+
+			data = append(data, converted)
+		}
+
+		articleRepo := service.ArticleRepo()
+		starred, err := articleRepo.Count(user, content.FavoriteOnly)
+		if err != nil {
+			fatal(w, log, "Error getting starred count: %+v", err)
+			return
+		}
+
+		newest, err := newestItemID(user, articleRepo)
+		if err != nil {
+			fatal(w, log, "Error getting newest item id: %+v", err)
+			return
+		}
+
+		resp := map[string]interface{}{"feeds": data, "starredCount": starred}
+		if newest > 0 {
+			resp["newestItemId"] = newest
+		}
+
+		writeJSON(w, resp)
+	}
+}
+
+func createFeed(service repo.Service, feedManager feedManager, log log.Log) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user := userFromRequest(r)
+
+		req, err := readRequest(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		if u, err := url.Parse(req.URL); err != nil || !u.IsAbs() {
+			http.Error(w, "Invalid feed url", http.StatusUnprocessableEntity)
+			return
+		}
+
+		feedRepo := service.FeedRepo()
+		if f, err := feedRepo.FindByLink(req.URL); err == nil {
+			if _, err := feedRepo.Get(f.ID, user); err == nil {
+				http.Error(w, "Feed already exists", http.StatusConflict)
+				return
+			}
+		}
+
+		var tags []*content.Tag
+		if req.FolderID != nil && *req.FolderID != 0 {
+			tag, err := service.TagRepo().Get(*req.FolderID, user)
+			if err != nil {
+				if content.IsNoContent(err) {
+					http.Error(w, "Unknown folder", http.StatusUnprocessableEntity)
+				} else {
+					fatal(w, log, "Error getting folder: %+v", err)
+				}
+				return
+			}
+
+			tags = append(tags, &tag)
+		}
+
+		f, err := feedManager.AddFeedByLink(req.URL)
+		if err != nil {
+			log.Printf("Error adding Nextcloud News feed %s: %+v", req.URL, err)
+			http.Error(w, "Error adding feed: "+err.Error(), http.StatusUnprocessableEntity)
+			return
+		}
+
+		if err := feedRepo.AttachTo(f, user); err != nil {
+			if errors.Cause(err) == content.ErrPrivateFeed {
+				http.Error(w, "Error adding feed: "+content.ErrPrivateFeed.Error(), http.StatusUnprocessableEntity)
+			} else {
+				fatal(w, log, "Error adding feed to user: %+v", err)
+			}
+			return
+		}
+
+		if len(tags) > 0 {
+			if err := feedRepo.SetUserTags(f, user, tags); err != nil {
+				fatal(w, log, "Error setting feed folder: %+v", err)
+				return
+			}
+		}
+
+		converted, err := convertFeed(f, user, service)
+		if err != nil {
+			fatal(w, log, "Error converting feed: %+v", err)
+			return
+		}
+
+		resp := map[string]interface{}{"feeds": []feed{converted}}
+		if newest, err := newestItemID(user, service.ArticleRepo()); err == nil && newest > 0 {
+			resp["newestItemId"] = newest
+		}
+
+		writeJSON(w, resp)
+	}
+}
+
+func deleteFeed(service repo.Service, feedManager feedManager, log log.Log) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user := userFromRequest(r)
+
+		f, stop := feedFromRequest(w, r, service.FeedRepo(), log)
+		if stop {
+			return
+		}
+
+		if err := service.FeedRepo().DetachFrom(f, user); err != nil {
+			fatal(w, log, "Error deleting feed: %+v", err)
+			return
+		}
+
+		feedManager.RemoveFeed(f)
+
+		writeEmpty(w)
+	}
+}
+
+// moveFeed replaces the tags of the feed with the given folder, or removes
+// them when moving to the root folder.
+func moveFeed(service repo.Service, log log.Log) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user := userFromRequest(r)
+
+		req, err := readRequest(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		f, stop := feedFromRequest(w, r, service.FeedRepo(), log)
+		if stop {
+			return
+		}
+
+		tags := []*content.Tag{}
+		if req.FolderID != nil && *req.FolderID != 0 {
+			tag, err := service.TagRepo().Get(*req.FolderID, user)
+			if err != nil {
+				if content.IsNoContent(err) {
+					http.Error(w, "Unknown folder", http.StatusUnprocessableEntity)
+				} else {
+					fatal(w, log, "Error getting folder: %+v", err)
+				}
+				return
+			}
+
+			tags = append(tags, &tag)
+		}
+
+		if err := service.FeedRepo().SetUserTags(f, user, tags); err != nil {
+			fatal(w, log, "Error setting feed folder: %+v", err)
+			return
+		}
+
+		writeEmpty(w)
+	}
+}
+
+func markFeedRead(service repo.Service, log log.Log) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user := userFromRequest(r)
+
+		req, err := readRequest(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		f, stop := feedFromRequest(w, r, service.FeedRepo(), log)
+		if stop {
+			return
+		}
+
+		if err := service.ArticleRepo().Read(true, user,
+			content.FeedIDs([]content.FeedID{f.ID}),
+			content.IDRange(0, content.ArticleID(req.NewestItemID+1)),
+		); err != nil {
+			fatal(w, log, "Error marking feed as read: %+v", err)
+			return
+		}
+
+		writeEmpty(w)
+	}
+}
+
+func feedFromRequest(w http.ResponseWriter, r *http.Request, repo repo.Feed, log log.Log) (content.Feed, bool) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "feedID"), 10, 64)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return content.Feed{}, true
+	}
+
+	f, err := repo.Get(content.FeedID(id), userFromRequest(r))
+	if err != nil {
+		if content.IsNoContent(err) {
+			http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+		} else {
+			fatal(w, log, "Error getting feed: %+v", err)
+		}
+		return content.Feed{}, true
+	}
+
+	return f, false
+}
+
+// convertFeed converts the readeef feed. Since Nextcloud News feeds belong
+// to a single folder, the first of the feed's tags is used.
+func convertFeed(f content.Feed, user content.User, service repo.Service) (feed, error) {
+	tags, err := service.TagRepo().ForFeed(f, user)
+	if err != nil {
+		return feed{}, errors.WithMessage(err, "getting feed tags")
+	}
+
+	unread, err := service.ArticleRepo().Count(user, content.FeedIDs([]content.FeedID{f.ID}), content.UnreadOnly)
+	if err != nil {
+		return feed{}, errors.WithMessage(err, "getting feed unread count")
+	}
+
+	converted := feed{
+		ID: f.ID, URL: f.Link, Title: f.Title, Link: f.SiteLink,
+		UnreadCount: unread, LastUpdateError: f.UpdateError,
+	}
+
+	if f.UpdateError != "" {
+		converted.UpdateErrorCount = 1
+	}
+
+	if len(tags) > 0 {
+		sort.Slice(tags, func(i, j int) bool { return tags[i].Value < tags[j].Value })
+		converted.FolderID = &tags[0].ID
+	}
+
+	return converted, nil
+}
+
+func folderFeedIDs(id content.TagID, user content.User, repo repo.Tag) ([]content.FeedID, error) {
+	tag, err := repo.Get(id, user)
+	if err != nil {
+		return nil, errors.WithMessage(err, "getting user tag")
+	}
+
+	ids, err := repo.FeedIDs(tag, user)
+	if err != nil {
+		return nil, errors.WithMessage(err, "getting tag feed ids")
+	}
+
+	return ids, nil
+}
+
+func newestItemID(user content.User, repo repo.Article) (content.ArticleID, error) {
+	ids, err := repo.IDs(user, content.Sorting(content.SortByID, content.DescendingOrder), content.Paging(1, 0))
+	if err != nil {
+		return 0, errors.WithMessage(err, "getting newest article id")
+	}
+
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	return ids[0], nil
+}
@@ -0,0 +1,243 @@
+package nextcloud
+
+import (
+	"context"
+	"crypto/md5"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi"
+	"github.com/pkg/errors"
+	"github.com/urandom/readeef/content"
+	"github.com/urandom/readeef/content/processor"
+	"github.com/urandom/readeef/content/repo"
+	"github.com/urandom/readeef/log"
+)
+
+type contextKey string
+
+type feedManager interface {
+	AddFeedByLink(link string) (content.Feed, error)
+	RemoveFeed(content.Feed)
+}
+
+// request holds the parameters of a call, which are sent either as a json
+// body, or as query and form values.
+type request struct {
+	URL          string          `json:"url"`
+	FolderID     *content.TagID  `json:"folderId"`
+	NewestItemID int64           `json:"newestItemId"`
+	Items        json.RawMessage `json:"items"`
+}
+
+const (
+	// API_PATH is the path of the emulated API, relative to the emulator
+	// root.
+	API_PATH = "/index.php/apps/news/api/v1-2"
+
+	API_VERSION = "12.0.4"
+
+	ITEM_TYPE_FEED    = 0
+	ITEM_TYPE_FOLDER  = 1
+	ITEM_TYPE_STARRED = 2
+	ITEM_TYPE_ALL     = 3
+
+	DEFAULT_BATCH_SIZE = 20
+)
+
+var (
+	userKey = contextKey("user")
+)
+
+// Handler returns the http handler of the Nextcloud News API emulator.
+func Handler(
+	service repo.Service,
+	feedManager feedManager,
+	processors []processor.Article,
+	log log.Log,
+) http.Handler {
+	processors = filterProcessors(processors)
+
+	r := chi.NewRouter()
+
+	r.Route(API_PATH, func(r chi.Router) {
+		r.Use(authenticate(service.UserRepo(), log))
+
+		r.Get("/version", version)
+		r.Get("/status", status)
+		r.Get("/user", userInfo)
+
+		r.Get("/folders", getFolders(service, log))
+		r.Put("/folders/{folderID:[0-9]+}/read", markFolderRead(service, log))
+
+		r.Get("/feeds", getFeeds(service, log))
+		r.Post("/feeds", createFeed(service, feedManager, log))
+		r.Delete("/feeds/{feedID:[0-9]+}", deleteFeed(service, feedManager, log))
+		r.Put("/feeds/{feedID:[0-9]+}/move", moveFeed(service, log))
+		r.Put("/feeds/{feedID:[0-9]+}/read", markFeedRead(service, log))
+
+		r.Get("/items", getItems(service, processors, log))
+		r.Get("/items/updated", getUpdatedItems(service, processors, log))
+		r.Put("/items/read", markAllRead(service, log))
+		r.Put("/items/read/multiple", markItems(service, true, log))
+		r.Put("/items/unread/multiple", markItems(service, false, log))
+		r.Put("/items/star/multiple", starItems(service, true, log))
+		r.Put("/items/unstar/multiple", starItems(service, false, log))
+		r.Put("/items/{itemID:[0-9]+}/read", markItem(service, true, log))
+		r.Put("/items/{itemID:[0-9]+}/unread", markItem(service, false, log))
+		r.Put("/items/{feedID:[0-9]+}/{guidHash}/star", starItem(service, true, log))
+		r.Put("/items/{feedID:[0-9]+}/{guidHash}/unstar", starItem(service, false, log))
+	})
+
+	return r
+}
+
+// authenticate checks the basic auth credentials against the user's md5 api
+// key, avoiding the cost of the password hash for every request.
+func authenticate(repo repo.User, log log.Log) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			login, password, ok := r.BasicAuth()
+			if !ok {
+				unauthorized(w)
+				return
+			}
+
+			user, err := repo.Get(content.Login(login))
+			if err != nil {
+				if !content.IsNoContent(err) {
+					log.Printf("Error getting Nextcloud News user: %+v", err)
+				}
+				unauthorized(w)
+				return
+			}
+
+			hash := md5.Sum([]byte(fmt.Sprintf("%s:%s", user.Login, password)))
+			if !user.Active || subtle.ConstantTimeCompare(hash[:], user.MD5API) != 1 {
+				unauthorized(w)
+				return
+			}
+
+			if err := r.ParseForm(); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+
+			ctx := context.WithValue(r.Context(), userKey, user)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+func unauthorized(w http.ResponseWriter) {
+	w.Header().Set("WWW-Authenticate", `Basic realm="readeef"`)
+	http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+}
+
+func userFromRequest(r *http.Request) content.User {
+	user, _ := r.Context().Value(userKey).(content.User)
+	return user
+}
+
+func version(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, map[string]interface{}{"version": API_VERSION})
+}
+
+func status(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, map[string]interface{}{
+		"version": API_VERSION,
+		"warnings": map[string]bool{
+			"improperlyConfiguredCron": false,
+			"incorrectDbCharset":       false,
+		},
+	})
+}
+
+func userInfo(w http.ResponseWriter, r *http.Request) {
+	user := userFromRequest(r)
+
+	name := strings.TrimSpace(user.FirstName + " " + user.LastName)
+	if name == "" {
+		name = string(user.Login)
+	}
+
+	writeJSON(w, map[string]interface{}{
+		"userId":             user.Login,
+		"displayName":        name,
+		"lastLoginTimestamp": 0,
+		"avatar":             nil,
+	})
+}
+
+// readRequest reads the call parameters from the json body, falling back to
+// the query and form values.
+func readRequest(r *http.Request) (request, error) {
+	req := request{}
+
+	if strings.Contains(r.Header.Get("Content-Type"), "json") {
+		b, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			return req, errors.Wrap(err, "reading request body")
+		}
+
+		if len(b) > 0 {
+			if err := json.Unmarshal(b, &req); err != nil {
+				return req, errors.Wrap(err, "unmarshaling request body")
+			}
+		}
+	}
+
+	if req.URL == "" {
+		req.URL = r.Form.Get("url")
+	}
+
+	if req.FolderID == nil {
+		if id, err := strconv.ParseInt(r.Form.Get("folderId"), 10, 64); err == nil {
+			tagID := content.TagID(id)
+			req.FolderID = &tagID
+		}
+	}
+
+	if req.NewestItemID == 0 {
+		req.NewestItemID, _ = strconv.ParseInt(r.Form.Get("newestItemId"), 10, 64)
+	}
+
+	return req, nil
+}
+
+func writeJSON(w http.ResponseWriter, data interface{}) {
+	if b, err := json.Marshal(data); err == nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(b)
+	} else {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func writeEmpty(w http.ResponseWriter) {
+	writeJSON(w, map[string]interface{}{})
+}
+
+func fatal(w http.ResponseWriter, log log.Log, format string, err error) {
+	log.Printf(format, err)
+	http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+}
+
+func filterProcessors(input []processor.Article) []processor.Article {
+	processors := make([]processor.Article, 0, len(input))
+
+	for i := range input {
+		if _, ok := input[i].(processor.ProxyHTTP); ok {
+			continue
+		}
+
+		processors = append(processors, input[i])
+	}
+
+	return processors
+}
@@ -0,0 +1,401 @@
+package nextcloud
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/urandom/readeef/config"
+	"github.com/urandom/readeef/content"
+	"github.com/urandom/readeef/content/repo/mock_repo"
+	"github.com/urandom/readeef/log"
+)
+
+var (
+	secret   = []byte("secret")
+	logger   log.Log
+	testUser = content.User{Login: "test", Active: true}
+)
+
+func init() {
+	cfg := config.Log{}
+	cfg.Converted.Writer = os.Stderr
+	cfg.Converted.Prefix = "[testing] "
+
+	logger = log.WithStd(cfg)
+
+	testUser.Password("pass", secret)
+}
+
+func TestHandler_auth(t *testing.T) {
+	inactive := content.User{Login: "inactive"}
+	inactive.Password("pass", secret)
+
+	tests := []struct {
+		name     string
+		login    string
+		password string
+		code     int
+	}{
+		{name: "no credentials", code: http.StatusUnauthorized},
+		{name: "wrong password", login: "test", password: "wrong", code: http.StatusUnauthorized},
+		{name: "unknown user", login: "unknown", password: "pass", code: http.StatusUnauthorized},
+		{name: "inactive user", login: "inactive", password: "pass", code: http.StatusUnauthorized},
+		{name: "valid", login: "test", password: "pass", code: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			service := mock_repo.NewMockService(ctrl)
+			userRepo := mock_repo.NewMockUser(ctrl)
+			service.EXPECT().UserRepo().Return(userRepo).AnyTimes()
+
+			userRepo.EXPECT().Get(gomock.Any()).DoAndReturn(func(login content.Login) (content.User, error) {
+				switch login {
+				case testUser.Login:
+					return testUser, nil
+				case inactive.Login:
+					return inactive, nil
+				}
+				return content.User{}, content.ErrNoContent
+			}).AnyTimes()
+
+			r := httptest.NewRequest("GET", API_PATH+"/version", nil)
+			if tt.login != "" {
+				r.SetBasicAuth(tt.login, tt.password)
+			}
+
+			rec := httptest.NewRecorder()
+			Handler(service, nil, nil, logger).ServeHTTP(rec, r)
+
+			if rec.Code != tt.code {
+				t.Fatalf("code = %d, want %d: %s", rec.Code, tt.code, rec.Body)
+			}
+
+			if tt.code == http.StatusUnauthorized && rec.Header().Get("WWW-Authenticate") == "" {
+				t.Errorf("missing WWW-Authenticate header")
+			}
+
+			if tt.code == http.StatusOK && !strings.Contains(rec.Body.String(), API_VERSION) {
+				t.Errorf("version response = %s", rec.Body)
+			}
+		})
+	}
+}
+
+func TestHandler_items(t *testing.T) {
+	tests := []struct {
+		name  string
+		path  string
+		code  int
+		ids   []content.ArticleID
+		state map[content.ArticleID][2]bool
+	}{
+		{name: "all", path: "/items?type=3&id=0&batchSize=-1", code: http.StatusOK, ids: []content.ArticleID{4, 3, 2, 1},
+			state: map[content.ArticleID][2]bool{1: {false, true}, 2: {true, false}, 4: {false, false}}},
+		{name: "batch", path: "/items?type=3&batchSize=2", code: http.StatusOK, ids: []content.ArticleID{4, 3}},
+		{name: "next batch", path: "/items?type=3&batchSize=2&offset=3", code: http.StatusOK, ids: []content.ArticleID{2, 1}},
+		{name: "oldest first", path: "/items?type=3&batchSize=-1&offset=2&oldestFirst=true", code: http.StatusOK, ids: []content.ArticleID{3, 4}},
+		{name: "unread", path: "/items?type=3&batchSize=-1&getRead=false", code: http.StatusOK, ids: []content.ArticleID{3, 2}},
+		{name: "starred", path: "/items?type=2&batchSize=-1", code: http.StatusOK, ids: []content.ArticleID{1}},
+		{name: "feed", path: "/items?type=0&id=2&batchSize=-1", code: http.StatusOK, ids: []content.ArticleID{4, 3}},
+		{name: "folder", path: "/items?type=1&id=1&batchSize=-1", code: http.StatusOK, ids: []content.ArticleID{2, 1}},
+		{name: "root folder", path: "/items?type=1&id=0&batchSize=-1", code: http.StatusOK, ids: []content.ArticleID{4, 3}},
+		{name: "unknown folder", path: "/items?type=1&id=5&batchSize=-1", code: http.StatusOK, ids: []content.ArticleID{}},
+		{name: "updated", path: "/items/updated?type=3&lastModified=1504224000", code: http.StatusOK, ids: []content.ArticleID{3, 1}},
+		{name: "updated in milliseconds", path: "/items/updated?type=3&lastModified=1504310400000", code: http.StatusOK, ids: []content.ArticleID{1}},
+		{name: "updated feed", path: "/items/updated?type=0&id=2&lastModified=1504224000", code: http.StatusOK, ids: []content.ArticleID{3}},
+		{name: "updated without timestamp", path: "/items/updated?type=3", code: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			w := newTestWorld(ctrl)
+			rec := w.call(t, "GET", tt.path, "", nil)
+
+			if rec.Code != tt.code {
+				t.Fatalf("code = %d, want %d: %s", rec.Code, tt.code, rec.Body)
+			}
+
+			if tt.code != http.StatusOK {
+				return
+			}
+
+			var resp struct {
+				Items []item `json:"items"`
+			}
+			if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+				t.Fatal(err)
+			}
+
+			ids := []content.ArticleID{}
+			for _, i := range resp.Items {
+				ids = append(ids, i.ID)
+
+				if state, ok := tt.state[i.ID]; ok && (i.Unread != state[0] || i.Starred != state[1]) {
+					t.Errorf("item %d unread, starred = %v, %v, want %v", i.ID, i.Unread, i.Starred, state)
+				}
+			}
+
+			if !reflect.DeepEqual(ids, tt.ids) {
+				t.Errorf("ids = %v, want %v", ids, tt.ids)
+			}
+		})
+	}
+}
+
+func TestHandler_edit(t *testing.T) {
+	tests := []struct {
+		name        string
+		path        string
+		contentType string
+		body        string
+		code        int
+		read        []content.ArticleID
+		unread      []content.ArticleID
+		favor       []content.ArticleID
+		unfavor     []content.ArticleID
+	}{
+		{name: "mark as read", path: "/items/2/read", code: http.StatusOK, read: []content.ArticleID{2}},
+		{name: "mark as unread", path: "/items/4/unread", code: http.StatusOK, unread: []content.ArticleID{4}},
+		{name: "mark multiple as read", path: "/items/read/multiple", contentType: "application/json", body: `{"items": [2, 3]}`,
+			code: http.StatusOK, read: []content.ArticleID{2, 3}},
+		{name: "mark multiple as read with a form", path: "/items/read/multiple", contentType: "application/x-www-form-urlencoded", body: "items[]=2&items[]=3",
+			code: http.StatusOK, read: []content.ArticleID{2, 3}},
+		{name: "mark multiple as unread", path: "/items/unread/multiple", contentType: "application/json", body: `{"items": [1, 4]}`,
+			code: http.StatusOK, unread: []content.ArticleID{1, 4}},
+		{name: "mark multiple with invalid ids", path: "/items/read/multiple", contentType: "application/json", body: `{"items": ["one"]}`,
+			code: http.StatusBadRequest},
+		{name: "mark all as read", path: "/items/read", contentType: "application/json", body: `{"newestItemId": 2}`,
+			code: http.StatusOK, read: []content.ArticleID{2}},
+		{name: "star", path: "/items/1/2/star", code: http.StatusOK, favor: []content.ArticleID{2}},
+		{name: "star in another feed", path: "/items/2/2/star", code: http.StatusOK},
+		{name: "unstar", path: "/items/1/1/unstar", code: http.StatusOK, unfavor: []content.ArticleID{1}},
+		{name: "star invalid hash", path: "/items/1/abc/star", code: http.StatusNotFound},
+		{name: "star multiple", path: "/items/star/multiple", contentType: "application/json", body: `{"items": [{"feedId": 1, "guidHash": "2"}, {"feedId": 2, "guidHash": "3"}]}`,
+			code: http.StatusOK, favor: []content.ArticleID{2, 3}},
+		{name: "star multiple by id", path: "/items/star/multiple", contentType: "application/json", body: `{"items": [4]}`,
+			code: http.StatusOK, favor: []content.ArticleID{4}},
+		{name: "unstar multiple", path: "/items/unstar/multiple", contentType: "application/json", body: `{"items": [{"feedId": 1, "guidHash": "1"}]}`,
+			code: http.StatusOK, unfavor: []content.ArticleID{1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			w := newTestWorld(ctrl)
+			rec := w.call(t, "PUT", tt.path, tt.contentType, strings.NewReader(tt.body))
+
+			if rec.Code != tt.code {
+				t.Fatalf("code = %d, want %d: %s", rec.Code, tt.code, rec.Body)
+			}
+
+			if tt.code != http.StatusOK {
+				return
+			}
+
+			checkIDs(t, "read", w.changed(func(a content.Article) bool { return a.Read }, true), tt.read)
+			checkIDs(t, "unread", w.changed(func(a content.Article) bool { return a.Read }, false), tt.unread)
+			checkIDs(t, "favor", w.changed(func(a content.Article) bool { return a.Favorite }, true), tt.favor)
+			checkIDs(t, "unfavor", w.changed(func(a content.Article) bool { return a.Favorite }, false), tt.unfavor)
+		})
+	}
+}
+
+func checkIDs(t *testing.T, name string, got, want []content.ArticleID) {
+	sort.Slice(got, func(i, j int) bool { return got[i] < got[j] })
+
+	if len(got) != len(want) || len(want) > 0 && !reflect.DeepEqual(got, want) {
+		t.Errorf("%s = %v, want %v", name, got, want)
+	}
+}
+
+// testWorld is an in-memory implementation of the repositories, filtering
+// its articles with the query options.
+type testWorld struct {
+	service *mock_repo.MockService
+
+	feeds     []content.Feed
+	tags      map[content.FeedID][]content.Tag
+	original  []content.Article
+	articles  []content.Article
+	changedAt map[content.ArticleID]time.Time
+}
+
+func newTestWorld(ctrl *gomock.Controller) *testWorld {
+	dev := content.Tag{ID: 1, Value: "dev"}
+
+	w := &testWorld{
+		service: mock_repo.NewMockService(ctrl),
+		feeds: []content.Feed{
+			{ID: 1, Title: "Go Blog", Link: "https://blog.golang.org/feed.atom"},
+			{ID: 2, Title: "News", Link: "https://news.example.com/rss"},
+		},
+		tags: map[content.FeedID][]content.Tag{1: {dev}},
+		original: []content.Article{
+			{ID: 1, FeedID: 1, Title: "Toward Go 2", Link: "https://blog.golang.org/toward-go2",
+				Date: time.Date(2017, 7, 13, 0, 0, 0, 0, time.UTC), Read: true, Favorite: true},
+			{ID: 2, FeedID: 1, Title: "Go 1.9 is released", Link: "https://blog.golang.org/go1.9",
+				Date: time.Date(2017, 8, 24, 0, 0, 0, 0, time.UTC)},
+			{ID: 3, FeedID: 2, Title: "Breaking news", Link: "https://news.example.com/breaking",
+				Date: time.Date(2017, 9, 1, 0, 0, 0, 0, time.UTC)},
+			{ID: 4, FeedID: 2, Title: "Old news", Link: "https://news.example.com/old",
+				Date: time.Date(2017, 9, 2, 0, 0, 0, 0, time.UTC), Read: true},
+		},
+		changedAt: map[content.ArticleID]time.Time{
+			1: time.Date(2017, 9, 3, 0, 0, 0, 0, time.UTC),
+			3: time.Date(2017, 9, 2, 0, 0, 0, 0, time.UTC),
+		},
+	}
+
+	w.articles = append([]content.Article{}, w.original...)
+
+	userRepo := mock_repo.NewMockUser(ctrl)
+	tagRepo := mock_repo.NewMockTag(ctrl)
+	articleRepo := mock_repo.NewMockArticle(ctrl)
+
+	w.service.EXPECT().UserRepo().Return(userRepo).AnyTimes()
+	w.service.EXPECT().TagRepo().Return(tagRepo).AnyTimes()
+	w.service.EXPECT().ArticleRepo().Return(articleRepo).AnyTimes()
+
+	userRepo.EXPECT().Get(testUser.Login).Return(testUser, nil).AnyTimes()
+
+	tagRepo.EXPECT().Get(gomock.Any(), gomock.Any()).DoAndReturn(func(id content.TagID, _ content.User) (content.Tag, error) {
+		if id == dev.ID {
+			return dev, nil
+		}
+		return content.Tag{}, content.ErrNoContent
+	}).AnyTimes()
+	tagRepo.EXPECT().FeedIDs(gomock.Any(), gomock.Any()).DoAndReturn(func(tag content.Tag, _ content.User) ([]content.FeedID, error) {
+		var ids []content.FeedID
+		for _, f := range w.feeds {
+			for _, t := range w.tags[f.ID] {
+				if t.ID == tag.ID {
+					ids = append(ids, f.ID)
+				}
+			}
+		}
+		return ids, nil
+	}).AnyTimes()
+
+	articleRepo.EXPECT().ForUser(gomock.Any(), gomock.Any()).DoAndReturn(func(_ content.User, opts ...content.QueryOpt) ([]content.Article, error) {
+		return w.query(opts), nil
+	}).AnyTimes()
+	articleRepo.EXPECT().Read(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(state bool, _ content.User, opts ...content.QueryOpt) error {
+		w.update(opts, func(a *content.Article) { a.Read = state })
+		return nil
+	}).AnyTimes()
+	articleRepo.EXPECT().Favor(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(state bool, _ content.User, opts ...content.QueryOpt) error {
+		w.update(opts, func(a *content.Article) { a.Favorite = state })
+		return nil
+	}).AnyTimes()
+
+	return w
+}
+
+// call sends an authenticated request to the handler.
+func (w *testWorld) call(t *testing.T, method, path, contentType string, body io.Reader) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(method, API_PATH+path, body)
+	r.SetBasicAuth("test", "pass")
+	if contentType != "" {
+		r.Header.Set("Content-Type", contentType)
+	}
+
+	rec := httptest.NewRecorder()
+	Handler(w.service, nil, nil, logger).ServeHTTP(rec, r)
+
+	return rec
+}
+
+func (w *testWorld) update(opts []content.QueryOpt, f func(a *content.Article)) {
+	for _, a := range w.query(opts) {
+		for i := range w.articles {
+			if w.articles[i].ID == a.ID {
+				f(&w.articles[i])
+			}
+		}
+	}
+}
+
+// changed returns the ids of the articles whose state, as returned by get,
+// has been changed to the given value.
+func (w *testWorld) changed(get func(a content.Article) bool, state bool) []content.ArticleID {
+	var ids []content.ArticleID
+	for i, a := range w.articles {
+		if get(a) == state && get(w.original[i]) != state {
+			ids = append(ids, a.ID)
+		}
+	}
+
+	return ids
+}
+
+// query returns the articles matching the options used by the emulator.
+func (w *testWorld) query(opts []content.QueryOpt) []content.Article {
+	o := content.QueryOptions{}
+	o.Apply(opts)
+
+	in := func(id int64, ids []int64) bool {
+		for _, i := range ids {
+			if i == id {
+				return true
+			}
+		}
+		return false
+	}
+
+	var ids, feedIDs []int64
+	for _, id := range o.IDs {
+		ids = append(ids, int64(id))
+	}
+	for _, id := range o.FeedIDs {
+		feedIDs = append(feedIDs, int64(id))
+	}
+
+	articles := []content.Article{}
+	for _, a := range w.articles {
+		switch {
+		case len(ids) > 0 && !in(int64(a.ID), ids),
+			len(feedIDs) > 0 && !in(int64(a.FeedID), feedIDs),
+			o.UnreadOnly && a.Read,
+			o.FavoriteOnly && !a.Favorite,
+			o.UntaggedOnly && len(w.tags[a.FeedID]) > 0,
+			o.AfterID > 0 && a.ID <= o.AfterID,
+			o.BeforeID > 0 && a.ID >= o.BeforeID,
+			!o.ChangedSince.IsZero() && !w.changedAt[a.ID].After(o.ChangedSince):
+			continue
+		}
+
+		articles = append(articles, a)
+	}
+
+	sort.SliceStable(articles, func(i, j int) bool {
+		if o.SortOrder == content.AscendingOrder {
+			return articles[i].ID < articles[j].ID
+		}
+
+		return articles[i].ID > articles[j].ID
+	})
+
+	if o.Limit > 0 && o.Limit < len(articles) {
+		articles = articles[:o.Limit]
+	}
+
+	return articles
+}
@@ -0,0 +1,419 @@
+package nextcloud
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi"
+	"github.com/pkg/errors"
+	"github.com/urandom/readeef/content"
+	"github.com/urandom/readeef/content/processor"
+	"github.com/urandom/readeef/content/repo"
+	"github.com/urandom/readeef/log"
+)
+
+type item struct {
+	ID               content.ArticleID `json:"id"`
+	GUID             string            `json:"guid"`
+	GUIDHash         string            `json:"guidHash"`
+	URL              string            `json:"url"`
+	Title            string            `json:"title"`
+	Author           string            `json:"author"`
+	PubDate          int64             `json:"pubDate"`
+	Body             string            `json:"body"`
+	EnclosureMime    *string           `json:"enclosureMime"`
+	EnclosureLink    *string           `json:"enclosureLink"`
+	MediaThumbnail   *string           `json:"mediaThumbnail"`
+	MediaDescription *string           `json:"mediaDescription"`
+	FeedID           content.FeedID    `json:"feedId"`
+	Unread           bool              `json:"unread"`
+	Starred          bool              `json:"starred"`
+	RTL              bool              `json:"rtl"`
+	LastModified     int64             `json:"lastModified"`
+	Fingerprint      string            `json:"fingerprint"`
+	ContentHash      string            `json:"contentHash"`
+}
+
+// itemRef identifies an item by its feed and guid hash, as used by the star
+// calls.
+type itemRef struct {
+	FeedID   content.FeedID `json:"feedId"`
+	GUIDHash string         `json:"guidHash"`
+}
+
+// getItems returns a batch of items of the given 'type' and 'id'. The
+// 'offset' is the id of the last item of the previous batch, and a
+// 'batchSize' of -1 returns all items.
+func getItems(
+	service repo.Service,
+	processors []processor.Article,
+	log log.Log,
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user := userFromRequest(r)
+
+		opts, err := typeOpts(r, user, service)
+		if err != nil {
+			if content.IsNoContent(err) {
+				writeJSON(w, map[string]interface{}{"items": []item{}})
+			} else {
+				fatal(w, log, "Error parsing item type: %+v", err)
+			}
+			return
+		}
+
+		batchSize := DEFAULT_BATCH_SIZE
+		if size, err := strconv.Atoi(r.Form.Get("batchSize")); err == nil && size != 0 {
+			batchSize = size
+		}
+
+		if batchSize > 0 {
+			opts = append(opts, content.Paging(batchSize, 0))
+		}
+
+		offset, _ := strconv.ParseInt(r.Form.Get("offset"), 10, 64)
+		oldestFirst := r.Form.Get("oldestFirst") == "true"
+
+		if oldestFirst {
+			opts = append(opts, content.Sorting(content.SortByID, content.AscendingOrder))
+			if offset > 0 {
+				opts = append(opts, content.IDRange(content.ArticleID(offset), 0))
+			}
+		} else {
+			opts = append(opts, content.Sorting(content.SortByID, content.DescendingOrder))
+			if offset > 0 {
+				opts = append(opts, content.IDRange(0, content.ArticleID(offset)))
+			}
+		}
+
+		if r.Form.Get("getRead") == "false" {
+			opts = append(opts, content.UnreadOnly)
+		}
+
+		writeItems(w, opts, time.Time{}, user, service, processors, log)
+	}
+}
+
+// getUpdatedItems returns the items of the given 'type' and 'id', whose state
+// has changed after the 'lastModified' timestamp, according to the user's
+// article change log. Since the log doesn't keep the time of the latest
+// change along with the article, the returned items are marked as modified
+// at the time of the request.
+func getUpdatedItems(
+	service repo.Service,
+	processors []processor.Article,
+	log log.Log,
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user := userFromRequest(r)
+
+		opts, err := typeOpts(r, user, service)
+		if err != nil {
+			if content.IsNoContent(err) {
+				writeJSON(w, map[string]interface{}{"items": []item{}})
+			} else {
+				fatal(w, log, "Error parsing item type: %+v", err)
+			}
+			return
+		}
+
+		lastModified, err := strconv.ParseInt(r.Form.Get("lastModified"), 10, 64)
+		if err != nil {
+			http.Error(w, "Invalid lastModified", http.StatusBadRequest)
+			return
+		}
+
+		// The change log timestamps have a precision of a second.
+		modified := time.Now().Add(-time.Second)
+
+		opts = append(opts,
+			content.ChangedSince(parseTimestamp(lastModified)),
+			content.Sorting(content.SortByID, content.DescendingOrder),
+		)
+
+		writeItems(w, opts, modified, user, service, processors, log)
+	}
+}
+
+func markAllRead(service repo.Service, log log.Log) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user := userFromRequest(r)
+
+		req, err := readRequest(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		if err := service.ArticleRepo().Read(true, user,
+			content.IDRange(0, content.ArticleID(req.NewestItemID+1)),
+		); err != nil {
+			fatal(w, log, "Error marking all items as read: %+v", err)
+			return
+		}
+
+		writeEmpty(w)
+	}
+}
+
+func markItems(service repo.Service, read bool, log log.Log) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user := userFromRequest(r)
+
+		ids, err := requestItemIDs(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		if len(ids) > 0 {
+			if err := service.ArticleRepo().Read(read, user, content.IDs(ids)); err != nil {
+				fatal(w, log, "Error changing items read state: %+v", err)
+				return
+			}
+		}
+
+		writeEmpty(w)
+	}
+}
+
+func starItems(service repo.Service, star bool, log log.Log) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user := userFromRequest(r)
+
+		ids, err := requestItemIDs(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		if len(ids) > 0 {
+			if err := service.ArticleRepo().Favor(star, user, content.IDs(ids)); err != nil {
+				fatal(w, log, "Error changing items starred state: %+v", err)
+				return
+			}
+		}
+
+		writeEmpty(w)
+	}
+}
+
+func markItem(service repo.Service, read bool, log log.Log) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user := userFromRequest(r)
+
+		id, err := strconv.ParseInt(chi.URLParam(r, "itemID"), 10, 64)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		if err := service.ArticleRepo().Read(read, user, content.IDs([]content.ArticleID{content.ArticleID(id)})); err != nil {
+			fatal(w, log, "Error changing item read state: %+v", err)
+			return
+		}
+
+		writeEmpty(w)
+	}
+}
+
+// starItem changes the starred state of the item. Since the guid hash of an
+// item is its id, the feed id is only used to limit the query.
+func starItem(service repo.Service, star bool, log log.Log) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user := userFromRequest(r)
+
+		feedID, _ := strconv.ParseInt(chi.URLParam(r, "feedID"), 10, 64)
+		id, err := strconv.ParseInt(chi.URLParam(r, "guidHash"), 10, 64)
+		if err != nil {
+			http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+			return
+		}
+
+		if err := service.ArticleRepo().Favor(star, user,
+			content.IDs([]content.ArticleID{content.ArticleID(id)}),
+			content.FeedIDs([]content.FeedID{content.FeedID(feedID)}),
+		); err != nil {
+			fatal(w, log, "Error changing item starred state: %+v", err)
+			return
+		}
+
+		writeEmpty(w)
+	}
+}
+
+// typeOpts converts the 'type' and 'id' parameters into article query
+// options. The root folder, with an id of 0, contains the untagged feeds.
+func typeOpts(r *http.Request, user content.User, service repo.Service) ([]content.QueryOpt, error) {
+	opts := []content.QueryOpt{content.Filters(content.GetUserFilters(user))}
+
+	id, _ := strconv.ParseInt(r.Form.Get("id"), 10, 64)
+
+	typ := ITEM_TYPE_ALL
+	if t, err := strconv.Atoi(r.Form.Get("type")); err == nil {
+		typ = t
+	}
+
+	switch typ {
+	case ITEM_TYPE_FEED:
+		opts = append(opts, content.FeedIDs([]content.FeedID{content.FeedID(id)}))
+	case ITEM_TYPE_FOLDER:
+		if id == 0 {
+			opts = append(opts, content.UntaggedOnly)
+			break
+		}
+
+		ids, err := folderFeedIDs(content.TagID(id), user, service.TagRepo())
+		if err != nil {
+			return nil, err
+		}
+
+		if len(ids) == 0 {
+			return nil, content.ErrNoContent
+		}
+
+		opts = append(opts, content.FeedIDs(ids))
+	case ITEM_TYPE_STARRED:
+		opts = append(opts, content.FavoriteOnly)
+	}
+
+	return opts, nil
+}
+
+func writeItems(
+	w http.ResponseWriter,
+	opts []content.QueryOpt,
+	modified time.Time,
+	user content.User,
+	service repo.Service,
+	processors []processor.Article,
+	log log.Log,
+) {
+	articles, err := service.ArticleRepo().ForUser(user, append(opts, content.IncludeMedia)...)
+	if err != nil {
+		fatal(w, log, "Error getting user articles: %+v", err)
+		return
+	}
+
+	articles = processor.Articles(processors).Process(articles)
+
+	items := make([]item, len(articles))
+	for i, a := range articles {
+		items[i] = articleItem(a)
+
+		if !modified.IsZero() {
+			items[i].LastModified = modified.Unix()
+		}
+	}
+
+	writeJSON(w, map[string]interface{}{"items": items})
+}
+
+func articleItem(a content.Article) item {
+	guid := a.Link
+	if a.Guid.Valid && a.Guid.String != "" {
+		guid = a.Guid.String
+	}
+
+	hash := md5.Sum([]byte(a.Title + a.Link + a.Description))
+	fingerprint := hex.EncodeToString(hash[:])
+
+	i := item{
+		ID:           a.ID,
+		GUID:         guid,
+		GUIDHash:     strconv.FormatInt(int64(a.ID), 10),
+		URL:          a.Link,
+		Title:        a.Title,
+		Author:       a.Author,
+		PubDate:      a.Date.Unix(),
+		Body:         a.Description,
+		FeedID:       a.FeedID,
+		Unread:       !a.Read,
+		Starred:      a.Favorite,
+		LastModified: a.Date.Unix(),
+		Fingerprint:  fingerprint,
+		ContentHash:  fingerprint,
+	}
+
+	for _, m := range a.Media {
+		if m.Link == "" || m.Medium == "image" {
+			continue
+		}
+
+		mime, link := m.Type, m.Link
+		i.EnclosureMime, i.EnclosureLink = &mime, &link
+
+		if m.Title != "" {
+			title := m.Title
+			i.MediaDescription = &title
+		}
+		break
+	}
+
+	if thumbnail := a.ThumbnailLink; thumbnail != "" {
+		i.MediaThumbnail = &thumbnail
+	}
+
+	return i
+}
+
+// requestItemIDs reads the 'items' of the request, which are either a list of
+// item ids, or a list of feed id and guid hash pairs.
+func requestItemIDs(r *http.Request) ([]content.ArticleID, error) {
+	req, err := readRequest(r)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := []content.ArticleID{}
+
+	if len(req.Items) == 0 {
+		for _, v := range append(r.Form["items"], r.Form["items[]"]...) {
+			id, err := strconv.ParseInt(v, 10, 64)
+			if err != nil {
+				return nil, errors.Wrapf(err, "parsing item id %s", v)
+			}
+
+			ids = append(ids, content.ArticleID(id))
+		}
+
+		return ids, nil
+	}
+
+	if err := json.Unmarshal(req.Items, &ids); err == nil {
+		return ids, nil
+	}
+
+	var refs []itemRef
+	if err := json.Unmarshal(req.Items, &refs); err != nil {
+		return nil, errors.Wrap(err, "unmarshaling items")
+	}
+
+	for _, ref := range refs {
+		id, err := strconv.ParseInt(ref.GUIDHash, 10, 64)
+		if err != nil {
+			return nil, errors.Wrapf(err, "parsing guid hash %s", ref.GUIDHash)
+		}
+
+		ids = append(ids, content.ArticleID(id))
+	}
+
+	return ids, nil
+}
+
+// parseTimestamp converts a timestamp, which some clients send in
+// milliseconds or microseconds instead of seconds.
+func parseTimestamp(ts int64) time.Time {
+	switch {
+	case ts > 1e14:
+		return time.Unix(0, ts*int64(time.Microsecond))
+	case ts > 1e11:
+		return time.Unix(0, ts*int64(time.Millisecond))
+	}
+
+	return time.Unix(ts, 0)
+}
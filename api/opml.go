@@ -1,18 +1,70 @@
 package api
 
 import (
+	"encoding/json"
 	"encoding/xml"
 	"net/http"
+	"reflect"
+	"sort"
 	"strings"
+	"time"
 
+	"github.com/pkg/errors"
 	"github.com/urandom/readeef/content"
 	"github.com/urandom/readeef/content/repo"
 	"github.com/urandom/readeef/log"
 	"github.com/urandom/readeef/parser"
 )
 
+type opmlImportStatus string
+
+const (
+	opmlImportAdded      opmlImportStatus = "added"
+	opmlImportExists     opmlImportStatus = "exists"
+	opmlImportDiscovered opmlImportStatus = "discovered"
+	opmlImportFailed     opmlImportStatus = "failed"
+)
+
+// opmlImportResult describes the outcome of importing a single OPML feed.
+type opmlImportResult struct {
+	Link   string           `json:"link"`
+	Title  string           `json:"title"`
+	Status opmlImportStatus `json:"status"`
+	Error  string           `json:"error,omitempty"`
+
+	SettingsError string `json:"settingsError,omitempty"`
+}
+
+// opmlSettings holds the readeef specific user data, stored in the
+// readeef:settings attribute of an exported OPML document.
+type opmlSettings struct {
+	Filters []opmlFilter `json:"filters,omitempty"`
+}
+
+// opmlFeedSettings holds the content.FeedSettings stored in the
+// readeef:settings attribute of a feed outline. The headers and credentials
+// of private feeds are never exported.
+type opmlFeedSettings struct {
+	UpdateInterval string `json:"updateInterval,omitempty"`
+	Paused         bool   `json:"paused,omitempty"`
+	UserAgent      string `json:"userAgent,omitempty"`
+	ExtractContent bool   `json:"extractContent,omitempty"`
+}
+
+// opmlFilter is a content.Filter that refers to tags and feeds by value and
+// link, rather than by their database ids.
+type opmlFilter struct {
+	Tag          content.TagValue `json:"tag,omitempty"`
+	Feeds        []string         `json:"feeds,omitempty"`
+	InverseFeeds bool             `json:"inverseFeeds,omitempty"`
+	URLTerm      string           `json:"urlTerm,omitempty"`
+	TitleTerm    string           `json:"titleTerm,omitempty"`
+	InverseURL   bool             `json:"inverseURL,omitempty"`
+	InverseTitle bool             `json:"inverseTitle,omitempty"`
+}
+
 func importOPML(
-	repo repo.Feed,
+	service repo.Service,
 	feedManager feedManager,
 	log log.Log,
 ) http.HandlerFunc {
@@ -31,6 +83,8 @@ func importOPML(
 			return
 		}
 
+		repo := service.FeedRepo()
+		settingsRepo := service.FeedSettingsRepo()
 		feeds, err := repo.ForUser(user)
 		if err != nil {
 			fatal(w, log, "Error getting user feeds: %+v", err)
@@ -44,40 +98,278 @@ func importOPML(
 		feeds = make([]content.Feed, 0, 10)
 
 		var skipped []string
+		results := make([]opmlImportResult, 0, len(opml.Feeds))
 		for _, opmlFeed := range opml.Feeds {
-			if _, err := repo.FindByLink(opmlFeed.URL); err == nil {
+			result := opmlImportResult{Link: opmlFeed.URL, Title: opmlFeed.Title}
+
+			var candidates []content.Feed
+			if f, err := repo.FindByLink(opmlFeed.URL); err == nil {
+				if _, ok := feedSet[f.ID]; ok {
+					result.Status = opmlImportExists
+
+					if opmlFeed.Settings != "" && !dryRun {
+						if err := importOPMLFeedSettings(opmlFeed.Settings, f, user, settingsRepo); err != nil {
+							log.Printf("Error importing settings of feed %s: %+v", f, err)
+							result.SettingsError = err.Error()
+						}
+					}
+				} else {
+					candidates = []content.Feed{f}
+				}
+			} else if !content.IsNoContent(err) {
+				result.Status = opmlImportFailed
+				result.Error = "finding feed: " + err.Error()
+			} else if candidates, err = feedManager.DiscoverFeeds(opmlFeed.URL); err != nil {
+				result.Status = opmlImportFailed
+				result.Error = "discovering feeds: " + err.Error()
+			} else if len(candidates) == 0 {
+				result.Status = opmlImportFailed
+				result.Error = "discovering feeds: no feeds found"
+			}
+
+			for _, f := range candidates {
+				if _, ok := feedSet[f.ID]; ok {
+					continue
+				}
+
+				if len(opmlFeed.Tags) > 0 {
+					f.Link += "#" + strings.Join(opmlFeed.Tags, ",")
+				}
+
+				if dryRun {
+					feeds = append(feeds, f)
+					result.Status = opmlImportDiscovered
+					continue
+				}
+
+				feed, err := addFeedByURL(f.Link, user, repo, feedManager, nil)
+				if err != nil {
+					log.Printf("Error importing feed %s: %+v", f.Link, err)
+					result.Status = opmlImportFailed
+					result.Error = err.Error()
+					break
+				}
+
+				feeds = append(feeds, feed)
+				feedSet[feed.ID] = struct{}{}
+				result.Status = opmlImportAdded
+
+				if opmlFeed.Settings != "" {
+					if err := importOPMLFeedSettings(opmlFeed.Settings, feed, user, settingsRepo); err != nil {
+						log.Printf("Error importing settings of feed %s: %+v", feed, err)
+						result.SettingsError = err.Error()
+					}
+				}
+			}
+
+			if result.Status == "" {
+				result.Status = opmlImportExists
+			}
+
+			if result.Status == opmlImportExists || result.Status == opmlImportFailed {
 				skipped = append(skipped, opmlFeed.URL)
-				continue
 			}
 
-			discovered, err := feedManager.DiscoverFeeds(opmlFeed.URL)
+			results = append(results, result)
+		}
+
+		data := args{"feeds": feeds, "skipped": skipped, "results": results}
+
+		if opml.Settings != "" && !dryRun {
+			count, err := importOPMLSettings(opml.Settings, user, service)
 			if err != nil {
-				skipped = append(skipped, opmlFeed.URL)
+				log.Printf("Error importing OPML settings: %+v", err)
+				data["settingsError"] = err.Error()
+			} else {
+				data["filters"] = count
+			}
+		}
+
+		data.WriteJSON(w)
+	}
+}
+
+// importOPMLFeedSettings applies the settings from the readeef:settings
+// attribute of a feed outline, unless they are owned by another user.
+func importOPMLFeedSettings(raw string, feed content.Feed, user content.User, repo repo.FeedSettings) error {
+	var fs opmlFeedSettings
+	if err := json.Unmarshal([]byte(raw), &fs); err != nil {
+		return errors.Wrap(err, "unmarshaling feed settings")
+	}
+
+	settings, err := repo.Get(feed)
+	if err != nil && !content.IsNoContent(err) {
+		return errors.WithMessage(err, "getting feed settings")
+	}
+
+	if !settings.EditableBy(user.Login) {
+		return errors.New("feed settings are owned by another user")
+	}
+
+	settings.FeedID, settings.Owner = feed.ID, user.Login
+	settings.UpdateInterval = 0
+	if fs.UpdateInterval != "" {
+		if settings.UpdateInterval, err = time.ParseDuration(fs.UpdateInterval); err != nil {
+			return errors.Wrap(err, "parsing feed update interval")
+		}
+	}
+
+	settings.Paused = fs.Paused
+	settings.UserAgent = fs.UserAgent
+	settings.ExtractContent = fs.ExtractContent
+
+	if err := repo.Update(settings); err != nil {
+		return errors.WithMessage(err, "updating feed settings")
+	}
+
+	return nil
+}
+
+// importOPMLSettings adds the filters from the readeef:settings attribute to
+// the user's profile, skipping the ones that are already present. It returns
+// the number of added filters.
+func importOPMLSettings(raw string, user content.User, service repo.Service) (int, error) {
+	var settings opmlSettings
+	if err := json.Unmarshal([]byte(raw), &settings); err != nil {
+		return 0, errors.Wrap(err, "unmarshaling settings")
+	}
+
+	if len(settings.Filters) == 0 {
+		return 0, nil
+	}
+
+	feeds, err := service.FeedRepo().ForUser(user)
+	if err != nil {
+		return 0, errors.WithMessage(err, "getting user feeds")
+	}
+
+	feedIDs := make(map[string]content.FeedID, len(feeds))
+	for _, f := range feeds {
+		feedIDs[f.Link] = f.ID
+	}
+
+	tags, err := service.TagRepo().ForUser(user)
+	if err != nil {
+		return 0, errors.WithMessage(err, "getting user tags")
+	}
+
+	tagIDs := make(map[content.TagValue]content.TagID, len(tags))
+	for _, t := range tags {
+		tagIDs[t.Value] = t.ID
+	}
+
+	filters := content.GetUserFilters(user)
+	count := 0
+
+	for _, of := range settings.Filters {
+		filter := content.Filter{
+			InverseFeeds: of.InverseFeeds,
+			URLTerm:      of.URLTerm,
+			TitleTerm:    of.TitleTerm,
+			InverseURL:   of.InverseURL,
+			InverseTitle: of.InverseTitle,
+		}
+
+		if of.Tag != "" {
+			id, ok := tagIDs[of.Tag]
+			if !ok {
 				continue
 			}
+			filter.TagID = id
+		}
 
-			for _, f := range discovered {
-				if _, ok := feedSet[f.ID]; !ok {
-					if len(opmlFeed.Tags) > 0 {
-						f.Link += "#" + strings.Join(opmlFeed.Tags, ",")
-					}
+		for _, link := range of.Feeds {
+			if id, ok := feedIDs[link]; ok {
+				filter.FeedIDs = append(filter.FeedIDs, id)
+			}
+		}
 
-					if dryRun {
-						feeds = append(feeds, f)
-					} else {
-						if feed, err := addFeedByURL(f.Link, user, repo, feedManager); err == nil {
-							feeds = append(feeds, feed)
-						} else {
-							fatal(w, log, "Error adding feed: %+v", err)
-							return
-						}
-					}
-				}
+		if (len(of.Feeds) > 0 && len(filter.FeedIDs) == 0) || !filter.Valid() {
+			continue
+		}
+
+		exists := false
+		for i := range filters {
+			if reflect.DeepEqual(filters[i], filter) {
+				exists = true
+				break
 			}
 		}
 
-		args{"feeds": feeds, "skipped": skipped}.WriteJSON(w)
+		if !exists {
+			filters = append(filters, filter)
+			count++
+		}
+	}
+
+	if count == 0 {
+		return 0, nil
+	}
+
+	if user.ProfileData == nil {
+		user.ProfileData = content.ProfileData{}
 	}
+	user.ProfileData["filters"] = filters
+
+	if err := service.UserRepo().Update(user); err != nil {
+		return 0, errors.WithMessage(err, "updating user filters")
+	}
+
+	return count, nil
+}
+
+// opmlNode is a category outline of an exported OPML document.
+type opmlNode struct {
+	text     string
+	children map[string]*opmlNode
+	feeds    []content.Feed
+}
+
+func (n *opmlNode) child(text string) *opmlNode {
+	if n.children == nil {
+		n.children = map[string]*opmlNode{}
+	}
+
+	c, ok := n.children[text]
+	if !ok {
+		c = &opmlNode{text: text}
+		n.children[text] = c
+	}
+
+	return c
+}
+
+// outlines returns the category and feed outlines of the node, where the feed
+// outlines hold their exported settings.
+func (n *opmlNode) outlines(settings map[content.FeedID]string) []parser.OpmlOutline {
+	names := make([]string, 0, len(n.children))
+	for name := range n.children {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	outlines := make([]parser.OpmlOutline, 0, len(names)+len(n.feeds))
+	for _, name := range names {
+		c := n.children[name]
+		outlines = append(outlines, parser.OpmlOutline{
+			Text:    c.text,
+			Title:   c.text,
+			Outline: c.outlines(settings),
+		})
+	}
+
+	for _, f := range n.feeds {
+		outlines = append(outlines, parser.OpmlOutline{
+			Text:     f.Title,
+			Title:    f.Title,
+			XmlUrl:   f.Link,
+			HtmlUrl:  f.SiteLink,
+			Type:     "rss",
+			Settings: settings[f.ID],
+		})
+	}
+
+	return outlines
 }
 
 func exportOPML(
@@ -91,7 +383,7 @@ func exportOPML(
 		}
 
 		o := parser.OpmlXml{
-			Version: "1.1",
+			Version: "2.0",
 			Head:    parser.OpmlHead{Title: "Feed subscriptions of " + user.String() + " from readeef"},
 		}
 
@@ -101,8 +393,13 @@ func exportOPML(
 			return
 		}
 
-		body := parser.OpmlBody{}
+		root := &opmlNode{}
+		feedLinks := make(map[content.FeedID]string, len(feeds))
+		feedSettings := map[content.FeedID]string{}
+		tagValues := map[content.TagID]content.TagValue{}
+
 		tagRepo := service.TagRepo()
+		settingsRepo := service.FeedSettingsRepo()
 		for _, f := range feeds {
 			tags, err := tagRepo.ForFeed(f, user)
 			if err != nil {
@@ -110,21 +407,48 @@ func exportOPML(
 				return
 			}
 
-			category := make([]string, len(tags))
-			for i, t := range tags {
-				category[i] = string(t.Value)
+			settings, err := settingsRepo.Get(f)
+			if err == nil {
+				if feedSettings[f.ID], err = exportOPMLFeedSettings(settings); err != nil {
+					fatal(w, log, "Error marshaling opml feed settings: %+v", err)
+					return
+				}
+			} else if !content.IsNoContent(err) {
+				fatal(w, log, "Error getting feed settings: %+v", err)
+				return
+			}
+
+			feedLinks[f.ID] = f.Link
+
+			if len(tags) == 0 {
+				root.feeds = append(root.feeds, f)
+				continue
+			}
+
+			for _, t := range tags {
+				tagValues[t.ID] = t.Value
+
+				node := root
+				for _, part := range strings.Split(string(t.Value), "/") {
+					if part = strings.TrimSpace(part); part != "" {
+						node = node.child(part)
+					}
+				}
+
+				node.feeds = append(node.feeds, f)
 			}
-			body.Outline = append(body.Outline, parser.OpmlOutline{
-				Text:     f.Title,
-				Title:    f.Title,
-				XmlUrl:   f.Link,
-				HtmlUrl:  f.SiteLink,
-				Category: strings.Join(category, ","),
-				Type:     "rss",
-			})
 		}
 
-		o.Body = body
+		o.Body = parser.OpmlBody{Outline: root.outlines(feedSettings)}
+
+		if settings := exportOPMLSettings(user, feedLinks, tagValues); len(settings.Filters) > 0 {
+			if b, err := json.Marshal(settings); err == nil {
+				o.Settings = string(b)
+			} else {
+				fatal(w, log, "Error marshaling opml settings: %+v", err)
+				return
+			}
+		}
 
 		if b, err := xml.MarshalIndent(o, "", "    "); err == nil {
 			args{"opml": xml.Header + string(b)}.WriteJSON(w)
@@ -133,3 +457,60 @@ func exportOPML(
 		}
 	}
 }
+
+// exportOPMLFeedSettings returns the readeef:settings attribute value of a
+// feed outline, which is empty when the feed has the default settings.
+func exportOPMLFeedSettings(settings content.FeedSettings) (string, error) {
+	fs := opmlFeedSettings{
+		Paused:         settings.Paused,
+		UserAgent:      settings.UserAgent,
+		ExtractContent: settings.ExtractContent,
+	}
+
+	if settings.UpdateInterval > 0 {
+		fs.UpdateInterval = settings.UpdateInterval.String()
+	}
+
+	if fs == (opmlFeedSettings{}) {
+		return "", nil
+	}
+
+	b, err := json.Marshal(fs)
+	return string(b), err
+}
+
+func exportOPMLSettings(
+	user content.User,
+	feedLinks map[content.FeedID]string,
+	tagValues map[content.TagID]content.TagValue,
+) opmlSettings {
+	settings := opmlSettings{}
+
+	for _, f := range content.GetUserFilters(user) {
+		of := opmlFilter{
+			InverseFeeds: f.InverseFeeds,
+			URLTerm:      f.URLTerm,
+			TitleTerm:    f.TitleTerm,
+			InverseURL:   f.InverseURL,
+			InverseTitle: f.InverseTitle,
+		}
+
+		if f.TagID != 0 {
+			value, ok := tagValues[f.TagID]
+			if !ok {
+				continue
+			}
+			of.Tag = value
+		}
+
+		for _, id := range f.FeedIDs {
+			if link, ok := feedLinks[id]; ok {
+				of.Feeds = append(of.Feeds, link)
+			}
+		}
+
+		settings.Filters = append(settings.Filters, of)
+	}
+
+	return settings
+}
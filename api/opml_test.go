@@ -7,8 +7,10 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"reflect"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
 	"github.com/pkg/errors"
@@ -34,11 +36,21 @@ func Test_importOPML(t *testing.T) {
 		addedErrs       [][]error
 		attachErrs      [][]error
 		setUserTagsErrs [][]error
+		statuses        []opmlImportStatus
 	}{
-		{"no user", false, nil, 0, false, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil},
-		{"parse error", true, url.Values{"opml": []string{"not-xml"}}, 0, true, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil},
-		{"feeds err", true, url.Values{"opml": []string{singleOmplXML}}, 1, false, nil, errors.New("feeds err"), nil, nil, nil, nil, nil, nil, nil, nil},
-		{"exists", true, url.Values{"opml": []string{singleOmplXML}}, 1, false, nil, nil, []error{nil}, nil, nil, nil, nil, nil, nil, nil},
+		{"no user", false, nil, 0, false, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil},
+		{"parse error", true, url.Values{"opml": []string{"not-xml"}}, 0, true, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil},
+		{"feeds err", true, url.Values{"opml": []string{singleOmplXML}}, 1, false, nil, errors.New("feeds err"), nil, nil, nil, nil, nil, nil, nil, nil, nil},
+		{"exists", true, url.Values{"opml": []string{singleOmplXML}}, 1, false, []content.Feed{{}}, nil, []error{nil}, nil, nil, nil, nil, nil, nil, nil, []opmlImportStatus{opmlImportExists}},
+		{
+			name:     "find err",
+			hasUser:  true,
+			form:     url.Values{"opml": []string{singleOmplXML}},
+			numInput: 1,
+			feeds:    []content.Feed{{ID: 1}},
+			findErrs: []error{errors.New("find err")},
+			statuses: []opmlImportStatus{opmlImportFailed},
+		},
 		{
 			name:            "discovered errs",
 			hasUser:         true,
@@ -55,6 +67,7 @@ func Test_importOPML(t *testing.T) {
 			addedErrs:       nil,
 			attachErrs:      nil,
 			setUserTagsErrs: nil,
+			statuses:        []opmlImportStatus{opmlImportFailed},
 		},
 		{
 			name:            "bad link",
@@ -72,6 +85,7 @@ func Test_importOPML(t *testing.T) {
 			addedErrs:       [][]error{{errors.New("bad link err")}},
 			attachErrs:      nil,
 			setUserTagsErrs: nil,
+			statuses:        []opmlImportStatus{opmlImportFailed},
 		},
 		{
 			name:            "relative link",
@@ -89,6 +103,7 @@ func Test_importOPML(t *testing.T) {
 			addedErrs:       [][]error{{errors.New("relative link err")}},
 			attachErrs:      nil,
 			setUserTagsErrs: nil,
+			statuses:        []opmlImportStatus{opmlImportFailed},
 		},
 		{
 			name:            "manager add err",
@@ -106,6 +121,7 @@ func Test_importOPML(t *testing.T) {
 			addedErrs:       [][]error{{errors.New("add err")}},
 			attachErrs:      nil,
 			setUserTagsErrs: nil,
+			statuses:        []opmlImportStatus{opmlImportFailed},
 		},
 		{
 			name:            "error attaching",
@@ -119,10 +135,11 @@ func Test_importOPML(t *testing.T) {
 			discovered:      [][]content.Feed{{{Link: "https://example.com"}, {Link: "https://example2.com"}}},
 			discoveredErrs:  []error{nil},
 			addedCalls:      [][]bool{{true, true}},
-			added:           [][]content.Feed{{{Link: "https://example.com"}, {Link: "https://example2.com"}}},
+			added:           [][]content.Feed{{{ID: 2, Link: "https://example.com"}, {ID: 3, Link: "https://example2.com"}}},
 			addedErrs:       [][]error{{nil, nil}},
 			attachErrs:      [][]error{{errors.New("attach err"), nil}},
 			setUserTagsErrs: nil,
+			statuses:        []opmlImportStatus{opmlImportFailed},
 		},
 		{
 			name:            "successful addition",
@@ -136,10 +153,11 @@ func Test_importOPML(t *testing.T) {
 			discovered:      [][]content.Feed{{{Link: "https://example.com"}, {Link: "https://example2.com"}}},
 			discoveredErrs:  []error{nil},
 			addedCalls:      [][]bool{{true, true}},
-			added:           [][]content.Feed{{{Link: "https://example.com"}, {Link: "https://example2.com"}}},
+			added:           [][]content.Feed{{{ID: 2, Link: "https://example.com"}, {ID: 3, Link: "https://example2.com"}}},
 			addedErrs:       [][]error{{nil, nil}},
 			attachErrs:      [][]error{{nil, nil}},
 			setUserTagsErrs: nil,
+			statuses:        []opmlImportStatus{opmlImportAdded},
 		},
 		{
 			name:            "feeds with tags",
@@ -153,10 +171,11 @@ func Test_importOPML(t *testing.T) {
 			discovered:      [][]content.Feed{{{Link: "https://example.com"}, {Link: "https://example2.com"}}, {{Link: "https://example3.com"}}},
 			discoveredErrs:  []error{nil, nil},
 			addedCalls:      [][]bool{{true, true}, {true}},
-			added:           [][]content.Feed{{{Link: "https://example.com"}, {Link: "https://example2.com"}}, {{Link: "https://example3.com"}}},
+			added:           [][]content.Feed{{{ID: 2, Link: "https://example.com"}, {ID: 3, Link: "https://example2.com"}}, {{ID: 4, Link: "https://example3.com"}}},
 			addedErrs:       [][]error{{nil, nil}, {nil}},
 			attachErrs:      [][]error{{nil, nil}, {nil}},
 			setUserTagsErrs: [][]error{{nil, nil}, {nil}},
+			statuses:        []opmlImportStatus{opmlImportAdded, opmlImportAdded},
 		},
 		{
 			name:            "set tag err",
@@ -170,10 +189,11 @@ func Test_importOPML(t *testing.T) {
 			discovered:      [][]content.Feed{{{Link: "https://example.com"}, {Link: "https://example2.com"}}, {{Link: "https://example3.com"}}},
 			discoveredErrs:  []error{nil, nil},
 			addedCalls:      [][]bool{{true, true}, {true}},
-			added:           [][]content.Feed{{{Link: "https://example.com"}, {Link: "https://example2.com"}}, {{Link: "https://example3.com"}}},
+			added:           [][]content.Feed{{{ID: 2, Link: "https://example.com"}, {ID: 3, Link: "https://example2.com"}}, {{ID: 4, Link: "https://example3.com"}}},
 			addedErrs:       [][]error{{nil, nil}, {nil}},
 			attachErrs:      [][]error{{nil, nil}, {nil}},
 			setUserTagsErrs: [][]error{{nil, nil}, {errors.New("set tag err")}},
+			statuses:        []opmlImportStatus{opmlImportAdded, opmlImportFailed},
 		},
 		{
 			name:            "dry run",
@@ -191,15 +211,20 @@ func Test_importOPML(t *testing.T) {
 			addedErrs:       [][]error{{nil}},
 			attachErrs:      [][]error{{}},
 			setUserTagsErrs: nil,
+			statuses:        []opmlImportStatus{opmlImportDiscovered},
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			ctrl := gomock.NewController(t)
 			defer ctrl.Finish()
+			service := mock_repo.NewMockService(ctrl)
 			feedRepo := mock_repo.NewMockFeed(ctrl)
 			feedManager := NewMockfeedManager(ctrl)
 
+			service.EXPECT().FeedRepo().Return(feedRepo).AnyTimes()
+			service.EXPECT().FeedSettingsRepo().Return(mock_repo.NewMockFeedSettings(ctrl)).AnyTimes()
+
 			r := httptest.NewRequest("POST", "/", strings.NewReader(tt.form.Encode()))
 			r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
 			r.ParseForm()
@@ -249,10 +274,6 @@ func Test_importOPML(t *testing.T) {
 									if link == "https://example3.com" {
 										link = "https://example3.com#cat1"
 										feedRepo.EXPECT().SetUserTags(tt.added[i][j], userMatcher{user}, []*content.Tag{&content.Tag{Value: "cat1"}}).Return(tt.setUserTagsErrs[i][j])
-
-										if tt.setUserTagsErrs[i][j] != nil {
-											code = http.StatusInternalServerError
-										}
 									}
 									feedManager.EXPECT().AddFeedByLink(link).Return(tt.added[i][j], tt.addedErrs[i][j])
 								}
@@ -262,12 +283,10 @@ func Test_importOPML(t *testing.T) {
 										feedRepo.EXPECT().AttachTo(tt.added[i][j], userMatcher{user}).Return(tt.attachErrs[i][j])
 
 										if tt.attachErrs[i][j] != nil {
-											code = http.StatusInternalServerError
 											break
 										}
 									}
 								} else {
-									code = http.StatusInternalServerError
 									break
 								}
 							}
@@ -276,16 +295,218 @@ func Test_importOPML(t *testing.T) {
 				}
 			}
 
-			importOPML(feedRepo, feedManager, logger).ServeHTTP(w, r)
+			importOPML(service, feedManager, logger).ServeHTTP(w, r)
 
 			if w.Code != code {
 				t.Errorf("importOPML() code = %v, want %v", w.Code, code)
 				return
 			}
+
+			if code == http.StatusOK {
+				checkOPMLImportStatuses(t, w.Body.Bytes(), tt.statuses)
+			}
+		})
+	}
+}
+
+func checkOPMLImportStatuses(t *testing.T, body []byte, statuses []opmlImportStatus) {
+	var got struct {
+		Results []opmlImportResult `json:"results"`
+	}
+	if err := json.Unmarshal(body, &got); err != nil {
+		t.Errorf("importOPML() response parse error = %v", err)
+		return
+	}
+
+	if len(got.Results) != len(statuses) {
+		t.Errorf("importOPML() results = %v, want statuses %v", got.Results, statuses)
+		return
+	}
+
+	for i := range got.Results {
+		if got.Results[i].Status != statuses[i] {
+			t.Errorf("importOPML() result %d = %v, want status %v", i, got.Results[i], statuses[i])
+		}
+
+		if (got.Results[i].Status == opmlImportFailed) != (got.Results[i].Error != "") {
+			t.Errorf("importOPML() result %d = %v, unexpected error reason", i, got.Results[i])
+		}
+	}
+}
+
+func Test_importOPML_feedSettings(t *testing.T) {
+	user := content.User{Login: "test"}
+	imported := content.FeedSettings{Owner: "test", UpdateInterval: 2 * time.Hour, Paused: true}
+
+	withID := func(s content.FeedSettings, id content.FeedID) content.FeedSettings {
+		s.FeedID = id
+		return s
+	}
+
+	tests := []struct {
+		name        string
+		dryRun      bool
+		expect      func(feedRepo *mock_repo.MockFeed, settingsRepo *mock_repo.MockFeedSettings, feedManager *MockfeedManager)
+		status      opmlImportStatus
+		settingsErr bool
+	}{
+		{
+			name: "added",
+			expect: func(feedRepo *mock_repo.MockFeed, settingsRepo *mock_repo.MockFeedSettings, feedManager *MockfeedManager) {
+				f := content.Feed{ID: 2, Link: "https://example.com"}
+				feedRepo.EXPECT().FindByLink("http://www.item1.com/rss").Return(content.Feed{}, content.ErrNoContent)
+				feedManager.EXPECT().DiscoverFeeds("http://www.item1.com/rss").Return([]content.Feed{{Link: f.Link}}, nil)
+				feedManager.EXPECT().AddFeedByLink(f.Link).Return(f, nil)
+				feedRepo.EXPECT().AttachTo(f, userMatcher{user}).Return(nil)
+				settingsRepo.EXPECT().Get(f).Return(content.FeedSettings{}, content.ErrNoContent)
+				settingsRepo.EXPECT().Update(withID(imported, 2)).Return(nil)
+			},
+			status: opmlImportAdded,
+		},
+		{
+			name: "exists",
+			expect: func(feedRepo *mock_repo.MockFeed, settingsRepo *mock_repo.MockFeedSettings, feedManager *MockfeedManager) {
+				f := content.Feed{ID: 1, Link: "http://www.item1.com/rss"}
+				feedRepo.EXPECT().FindByLink(f.Link).Return(f, nil)
+				settingsRepo.EXPECT().Get(f).Return(content.FeedSettings{FeedID: 1, Owner: "test", UserAgent: "agent"}, nil)
+				settingsRepo.EXPECT().Update(withID(imported, 1)).Return(nil)
+			},
+			status: opmlImportExists,
+		},
+		{
+			name: "exists without subscription",
+			expect: func(feedRepo *mock_repo.MockFeed, settingsRepo *mock_repo.MockFeedSettings, feedManager *MockfeedManager) {
+				f := content.Feed{ID: 2, Link: "http://www.item1.com/rss"}
+				feedRepo.EXPECT().FindByLink(f.Link).Return(f, nil)
+				feedManager.EXPECT().AddFeedByLink(f.Link).Return(f, nil)
+				feedRepo.EXPECT().AttachTo(f, userMatcher{user}).Return(nil)
+				settingsRepo.EXPECT().Get(f).Return(content.FeedSettings{}, content.ErrNoContent)
+				settingsRepo.EXPECT().Update(withID(imported, 2)).Return(nil)
+			},
+			status: opmlImportAdded,
+		},
+		{
+			name: "owned by another user",
+			expect: func(feedRepo *mock_repo.MockFeed, settingsRepo *mock_repo.MockFeedSettings, feedManager *MockfeedManager) {
+				f := content.Feed{ID: 1, Link: "http://www.item1.com/rss"}
+				feedRepo.EXPECT().FindByLink(f.Link).Return(f, nil)
+				settingsRepo.EXPECT().Get(f).Return(content.FeedSettings{FeedID: 1, Owner: "other"}, nil)
+			},
+			status: opmlImportExists, settingsErr: true,
+		},
+		{
+			name: "update err",
+			expect: func(feedRepo *mock_repo.MockFeed, settingsRepo *mock_repo.MockFeedSettings, feedManager *MockfeedManager) {
+				f := content.Feed{ID: 1, Link: "http://www.item1.com/rss"}
+				feedRepo.EXPECT().FindByLink(f.Link).Return(f, nil)
+				settingsRepo.EXPECT().Get(f).Return(content.FeedSettings{}, content.ErrNoContent)
+				settingsRepo.EXPECT().Update(withID(imported, 1)).Return(errors.New("update err"))
+			},
+			status: opmlImportExists, settingsErr: true,
+		},
+		{
+			name:   "dry run",
+			dryRun: true,
+			expect: func(feedRepo *mock_repo.MockFeed, settingsRepo *mock_repo.MockFeedSettings, feedManager *MockfeedManager) {
+				feedRepo.EXPECT().FindByLink("http://www.item1.com/rss").Return(content.Feed{}, content.ErrNoContent)
+				feedManager.EXPECT().DiscoverFeeds("http://www.item1.com/rss").Return([]content.Feed{{Link: "https://example.com"}}, nil)
+			},
+			status: opmlImportDiscovered,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			service := mock_repo.NewMockService(ctrl)
+			feedRepo := mock_repo.NewMockFeed(ctrl)
+			settingsRepo := mock_repo.NewMockFeedSettings(ctrl)
+			feedManager := NewMockfeedManager(ctrl)
+
+			service.EXPECT().FeedRepo().Return(feedRepo).AnyTimes()
+			service.EXPECT().FeedSettingsRepo().Return(settingsRepo).AnyTimes()
+			feedRepo.EXPECT().ForUser(userMatcher{user}).Return([]content.Feed{{ID: 1}}, nil)
+			tt.expect(feedRepo, settingsRepo, feedManager)
+
+			form := url.Values{"opml": []string{settingsOmplXML}}
+			if tt.dryRun {
+				form.Set("dryRun", "")
+			}
+
+			r := httptest.NewRequest("POST", "/", strings.NewReader(form.Encode()))
+			r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			r.ParseForm()
+			r = r.WithContext(context.WithValue(r.Context(), userKey, user))
+			w := httptest.NewRecorder()
+
+			importOPML(service, feedManager, logger).ServeHTTP(w, r)
+
+			if w.Code != http.StatusOK {
+				t.Errorf("importOPML() code = %v, want %v", w.Code, http.StatusOK)
+				return
+			}
+
+			var got struct {
+				Results []opmlImportResult `json:"results"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+				t.Errorf("importOPML() response parse error = %v", err)
+				return
+			}
+
+			if len(got.Results) != 1 || got.Results[0].Status != tt.status || (got.Results[0].SettingsError != "") != tt.settingsErr {
+				t.Errorf("importOPML() results = %v, want status %v, settings error %v", got.Results, tt.status, tt.settingsErr)
+			}
 		})
 	}
 }
 
+func Test_importOPMLSettings(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	service := mock_repo.NewMockService(ctrl)
+	feedRepo := mock_repo.NewMockFeed(ctrl)
+	tagRepo := mock_repo.NewMockTag(ctrl)
+	userRepo := mock_repo.NewMockUser(ctrl)
+
+	user := content.User{Login: "test", ProfileData: content.ProfileData{
+		"filters": []content.Filter{{TitleTerm: "go"}},
+	}}
+
+	service.EXPECT().FeedRepo().Return(feedRepo)
+	service.EXPECT().TagRepo().Return(tagRepo)
+	service.EXPECT().UserRepo().Return(userRepo)
+	feedRepo.EXPECT().ForUser(userMatcher{user}).Return([]content.Feed{{ID: 2, Link: "http://example.com"}}, nil)
+	tagRepo.EXPECT().ForUser(userMatcher{user}).Return([]content.Tag{{ID: 3, Value: "tech/go"}}, nil)
+
+	want := []content.Filter{
+		{TitleTerm: "go"},
+		{TagID: 3, FeedIDs: []content.FeedID{2}, URLTerm: "blog", InverseURL: true},
+	}
+	userRepo.EXPECT().Update(gomock.Any()).Do(func(u content.User) {
+		if got := content.GetUserFilters(u); !reflect.DeepEqual(got, want) {
+			t.Errorf("importOPMLSettings() filters = %v, want %v", got, want)
+		}
+	}).Return(nil)
+
+	settings := `{"filters":[
+		{"titleTerm":"go"},
+		{"tag":"tech/go","feeds":["http://example.com"],"urlTerm":"blog","inverseURL":true},
+		{"tag":"missing","titleTerm":"rust"},
+		{"feeds":["http://missing.com"],"titleTerm":"rust"}
+	]}`
+
+	count, err := importOPMLSettings(settings, user, service)
+	if err != nil {
+		t.Errorf("importOPMLSettings() error = %v", err)
+		return
+	}
+
+	if count != 1 {
+		t.Errorf("importOPMLSettings() count = %v, want 1", count)
+	}
+}
+
 const (
 	singleOmplXML = `
 <?xml version="1.0" encoding="UTF-8"?>
@@ -313,6 +534,17 @@ const (
         <outline type="rss" text="text2" title="Item 2 title" xmlUrl="http://www.item2.com/rss" htmlUrl="http://www.item2.com" category="cat1"></outline>
     </body>
 </opml>
+`
+	settingsOmplXML = `
+<?xml version="1.0" encoding="UTF-8"?>
+<opml version="2.0" xmlns:readeef="https://github.com/urandom/readeef">
+    <head>
+        <title>OPML title</title>
+    </head>
+    <body>
+        <outline type="rss" text="Item 1 text" xmlUrl="http://www.item1.com/rss" readeef:settings="{&#34;updateInterval&#34;:&#34;2h0m0s&#34;,&#34;paused&#34;:true}"></outline>
+    </body>
+</opml>
 `
 )
 
@@ -324,6 +556,9 @@ func Test_exportOPML(t *testing.T) {
 		userFeedsErr error
 		tags         [][]content.Tag
 		tagsErr      []error
+		settings     []content.FeedSettings
+		filters      []content.Filter
+		want         parser.Opml
 	}{
 		{name: "no user", hasUser: false},
 		{name: "user feeds err", hasUser: true, userFeedsErr: errors.New("user feeds err")},
@@ -334,6 +569,38 @@ func Test_exportOPML(t *testing.T) {
 			tags:      [][]content.Tag{{}, {}},
 			tagsErr:   []error{nil, nil},
 		},
+		{
+			name:      "nested tags",
+			hasUser:   true,
+			filters:   []content.Filter{{TagID: 2, FeedIDs: []content.FeedID{1}, TitleTerm: "go"}},
+			userFeeds: []content.Feed{{ID: 1, Link: "http://example.com", SiteLink: "http://example.com/site"}, {ID: 2, Link: "http://example2.com"}},
+			tags:      [][]content.Tag{{{ID: 1, Value: "tech"}, {ID: 2, Value: "tech/go"}}, {}},
+			tagsErr:   []error{nil, nil},
+			want: parser.Opml{
+				Settings: `{"filters":[{"tag":"tech/go","feeds":["http://example.com"],"titleTerm":"go"}]}`,
+				Feeds: []parser.OpmlFeed{
+					{URL: "http://example.com", SiteURL: "http://example.com/site", Tags: []string{"tech/go", "tech"}},
+					{URL: "http://example2.com", Tags: []string{}},
+				},
+			},
+		},
+		{
+			name:      "feed settings",
+			hasUser:   true,
+			userFeeds: []content.Feed{{ID: 1, Link: "http://example.com"}, {ID: 2, Link: "http://example2.com"}},
+			tags:      [][]content.Tag{{}, {}},
+			tagsErr:   []error{nil, nil},
+			settings: []content.FeedSettings{{
+				FeedID: 1, Owner: "test", UpdateInterval: 2 * time.Hour, UserAgent: "agent", ExtractContent: true,
+				Username: "user", Password: "pass", Cookie: "session=abcd", Headers: content.FeedHeaders{"X-Token": "abcd"},
+			}, {}},
+			want: parser.Opml{
+				Feeds: []parser.OpmlFeed{
+					{URL: "http://example.com", Tags: []string{}, Settings: `{"updateInterval":"2h0m0s","userAgent":"agent","extractContent":true}`},
+					{URL: "http://example2.com", Tags: []string{}},
+				},
+			},
+		},
 		{
 			name:      "tag error",
 			hasUser:   true,
@@ -353,13 +620,14 @@ func Test_exportOPML(t *testing.T) {
 			service := mock_repo.NewMockService(ctrl)
 			feedRepo := mock_repo.NewMockFeed(ctrl)
 			tagRepo := mock_repo.NewMockTag(ctrl)
+			settingsRepo := mock_repo.NewMockFeedSettings(ctrl)
 
 			r := httptest.NewRequest("GET", "/", nil)
 			w := httptest.NewRecorder()
 
 			code := http.StatusOK
 			if tt.hasUser {
-				user := content.User{Login: "test"}
+				user := content.User{Login: "test", ProfileData: content.ProfileData{"filters": tt.filters}}
 				r = r.WithContext(context.WithValue(r.Context(), userKey, user))
 
 				service.EXPECT().FeedRepo().Return(feedRepo)
@@ -367,12 +635,19 @@ func Test_exportOPML(t *testing.T) {
 
 				if tt.userFeedsErr == nil {
 					service.EXPECT().TagRepo().Return(tagRepo)
+					service.EXPECT().FeedSettingsRepo().Return(settingsRepo)
 					for i, f := range tt.userFeeds {
 						tagRepo.EXPECT().ForFeed(f, userMatcher{user}).Return(tt.tags[i], tt.tagsErr[i])
 						if tt.tagsErr[i] != nil {
 							code = http.StatusInternalServerError
 							break
 						}
+
+						if i < len(tt.settings) && tt.settings[i].FeedID != 0 {
+							settingsRepo.EXPECT().Get(f).Return(tt.settings[i], nil)
+						} else {
+							settingsRepo.EXPECT().Get(f).Return(content.FeedSettings{}, content.ErrNoContent)
+						}
 					}
 				} else {
 					code = http.StatusInternalServerError
@@ -402,6 +677,13 @@ func Test_exportOPML(t *testing.T) {
 					return
 				}
 
+				if tt.want.Feeds != nil {
+					if !reflect.DeepEqual(opml, tt.want) {
+						t.Errorf("exportOPML() opml = %#v, want = %#v", opml, tt.want)
+					}
+					return
+				}
+
 				if len(opml.Feeds) != len(tt.userFeeds) {
 					t.Errorf("exportOPML() opml.Feeds = %v, want = %v", opml.Feeds, tt.userFeeds)
 					return
@@ -418,3 +700,85 @@ func Test_exportOPML(t *testing.T) {
 		})
 	}
 }
+
+// Test_OPML_roundTrip imports an exported document as another user, with none
+// of the feeds known, checking that the feed settings and filters carry over.
+func Test_OPML_roundTrip(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	service := mock_repo.NewMockService(ctrl)
+	feedRepo := mock_repo.NewMockFeed(ctrl)
+	tagRepo := mock_repo.NewMockTag(ctrl)
+	settingsRepo := mock_repo.NewMockFeedSettings(ctrl)
+	userRepo := mock_repo.NewMockUser(ctrl)
+	feedManager := NewMockfeedManager(ctrl)
+
+	service.EXPECT().FeedRepo().Return(feedRepo).AnyTimes()
+	service.EXPECT().TagRepo().Return(tagRepo).AnyTimes()
+	service.EXPECT().FeedSettingsRepo().Return(settingsRepo).AnyTimes()
+	service.EXPECT().UserRepo().Return(userRepo).AnyTimes()
+
+	exporter := content.User{Login: "exporter", ProfileData: content.ProfileData{
+		"filters": []content.Filter{{FeedIDs: []content.FeedID{1}, TitleTerm: "go"}},
+	}}
+	exported := content.Feed{ID: 1, Title: "Go", Link: "http://example.com/go", SiteLink: "http://example.com"}
+	tag := content.Tag{ID: 1, Value: "tech/go"}
+
+	feedRepo.EXPECT().ForUser(userMatcher{exporter}).Return([]content.Feed{exported}, nil)
+	tagRepo.EXPECT().ForFeed(exported, userMatcher{exporter}).Return([]content.Tag{tag}, nil)
+	settingsRepo.EXPECT().Get(exported).Return(content.FeedSettings{
+		FeedID: 1, Owner: "exporter", UpdateInterval: 45 * time.Minute, Paused: true, UserAgent: "agent", ExtractContent: true, Cookie: "session=abcd",
+	}, nil)
+
+	r := httptest.NewRequest("GET", "/", nil)
+	r = r.WithContext(context.WithValue(r.Context(), userKey, exporter))
+	w := httptest.NewRecorder()
+
+	exportOPML(service, logger).ServeHTTP(w, r)
+
+	var export struct {
+		Opml string `json:"opml"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &export); err != nil {
+		t.Fatalf("exportOPML() response parse error = %v, body = %s", err, w.Body)
+	}
+
+	importer := content.User{Login: "importer"}
+	imported := content.Feed{ID: 7, Title: "Go", Link: "http://example.com/go"}
+
+	gomock.InOrder(
+		feedRepo.EXPECT().ForUser(userMatcher{importer}).Return(nil, nil),
+		feedRepo.EXPECT().FindByLink(exported.Link).Return(content.Feed{}, content.ErrNoContent),
+	)
+	feedManager.EXPECT().DiscoverFeeds(exported.Link).Return([]content.Feed{{Link: exported.Link}}, nil)
+	feedManager.EXPECT().AddFeedByLink(exported.Link+"#tech/go").Return(imported, nil)
+	feedRepo.EXPECT().AttachTo(imported, userMatcher{importer}).Return(nil)
+	feedRepo.EXPECT().SetUserTags(imported, userMatcher{importer}, []*content.Tag{{Value: "tech/go"}}).Return(nil)
+	settingsRepo.EXPECT().Get(imported).Return(content.FeedSettings{}, content.ErrNoContent)
+	settingsRepo.EXPECT().Update(content.FeedSettings{
+		FeedID: 7, Owner: "importer", UpdateInterval: 45 * time.Minute, Paused: true, UserAgent: "agent", ExtractContent: true,
+	}).Return(nil)
+
+	feedRepo.EXPECT().ForUser(userMatcher{importer}).Return([]content.Feed{imported}, nil)
+	tagRepo.EXPECT().ForUser(userMatcher{importer}).Return([]content.Tag{{ID: 3, Value: "tech/go"}}, nil)
+	userRepo.EXPECT().Update(gomock.Any()).Do(func(u content.User) {
+		want := []content.Filter{{FeedIDs: []content.FeedID{7}, TitleTerm: "go"}}
+		if got := content.GetUserFilters(u); !reflect.DeepEqual(got, want) {
+			t.Errorf("importOPML() filters = %v, want %v", got, want)
+		}
+	}).Return(nil)
+
+	r = httptest.NewRequest("POST", "/", strings.NewReader(url.Values{"opml": {export.Opml}}.Encode()))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	r.ParseForm()
+	r = r.WithContext(context.WithValue(r.Context(), userKey, importer))
+	w = httptest.NewRecorder()
+
+	importOPML(service, feedManager, logger).ServeHTTP(w, r)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("importOPML() code = %v, body = %s", w.Code, w.Body)
+	}
+
+	checkOPMLImportStatuses(t, w.Body.Bytes(), []opmlImportStatus{opmlImportAdded})
+}
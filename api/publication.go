@@ -0,0 +1,205 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi"
+	"github.com/urandom/readeef/content"
+	"github.com/urandom/readeef/content/repo"
+	"github.com/urandom/readeef/log"
+)
+
+var publicationKey = contextKey("publication")
+
+const (
+	defaultPublicationsLimit = 50
+	maxPublicationsLimit     = 200
+)
+
+// listPublications returns a page of the user's publications, newest first,
+// along with the token that grants access to their public feed.
+func listPublications(repo repo.Publication, secret []byte, log log.Log) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user, stop := userFromRequest(w, r)
+		if stop {
+			return
+		}
+
+		var err error
+		limit, offset := defaultPublicationsLimit, 0
+		if r.Form.Get("limit") != "" {
+			if limit, err = strconv.Atoi(r.Form.Get("limit")); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+		}
+
+		if r.Form.Get("offset") != "" {
+			if offset, err = strconv.Atoi(r.Form.Get("offset")); err != nil || offset < 0 {
+				http.Error(w, "Invalid offset", http.StatusBadRequest)
+				return
+			}
+		}
+
+		if limit <= 0 || limit > maxPublicationsLimit {
+			limit = maxPublicationsLimit
+		}
+
+		publications, err := repo.ForUser(user, limit, offset)
+		if err != nil {
+			fatal(w, log, "Error getting publications: %+v", err)
+			return
+		}
+
+		args{"publications": publications, "token": publishedFeedToken(user, secret)}.WriteJSON(w)
+	}
+}
+
+func getPublication(w http.ResponseWriter, r *http.Request) {
+	publication, stop := publicationFromRequest(w, r)
+	if stop {
+		return
+	}
+
+	args{"publication": publication}.WriteJSON(w)
+}
+
+// updatePublication shares a new link, or changes the publication in the
+// request context, using the title, link, description and note form
+// values.
+func updatePublication(repo repo.Publication, log log.Log) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user, stop := userFromRequest(w, r)
+		if stop {
+			return
+		}
+
+		publication, ok := r.Context().Value(publicationKey).(content.Publication)
+		if !ok {
+			publication = content.Publication{UserLogin: user.Login}
+		}
+
+		// Only the given fields of an existing publication are changed
+		for name, field := range map[string]*string{
+			"title":       &publication.Title,
+			"link":        &publication.Link,
+			"description": &publication.Description,
+			"note":        &publication.Note,
+		} {
+			if _, ok := r.Form[name]; ok || publication.ID == 0 {
+				*field = r.Form.Get(name)
+			}
+		}
+
+		if err := publication.Validate(); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		if err := repo.Update(&publication); err != nil {
+			fatal(w, log, "Error updating publication: %+v", err)
+			return
+		}
+
+		args{"publication": publication}.WriteJSON(w)
+	}
+}
+
+func deletePublication(repo repo.Publication, log log.Log) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		publication, stop := publicationFromRequest(w, r)
+		if stop {
+			return
+		}
+
+		if err := repo.Delete(publication); err != nil {
+			fatal(w, log, "Error deleting publication: %+v", err)
+			return
+		}
+
+		args{"success": true}.WriteJSON(w)
+	}
+}
+
+// publishArticles publishes the articles given by the id form values for
+// POST requests, and unpublishes them otherwise.
+func publishArticles(repo repo.Publication, log log.Log) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user, stop := userFromRequest(w, r)
+		if stop {
+			return
+		}
+
+		if len(r.Form["id"]) == 0 {
+			http.Error(w, "No article ids", http.StatusBadRequest)
+			return
+		}
+
+		ids := make([]content.ArticleID, len(r.Form["id"]))
+		for i, v := range r.Form["id"] {
+			id, err := strconv.ParseInt(v, 10, 64)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+
+			ids[i] = content.ArticleID(id)
+		}
+
+		var err error
+		if r.Method == http.MethodPost {
+			err = repo.Publish(user, ids)
+		} else {
+			err = repo.Unpublish(user, ids)
+		}
+
+		if err != nil {
+			fatal(w, log, "Error setting published articles: %+v", err)
+			return
+		}
+
+		args{"success": true}.WriteJSON(w)
+	}
+}
+
+func publicationContext(repo repo.Publication, log log.Log) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			user, stop := userFromRequest(w, r)
+			if stop {
+				return
+			}
+
+			id, err := strconv.ParseInt(chi.URLParam(r, "publicationID"), 10, 64)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+
+			publication, err := repo.Get(content.PublicationID(id), user)
+			if err != nil {
+				if content.IsNoContent(err) {
+					http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+				} else {
+					fatal(w, log, "Error getting publication: %+v", err)
+				}
+				return
+			}
+
+			ctx := context.WithValue(r.Context(), publicationKey, publication)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+func publicationFromRequest(w http.ResponseWriter, r *http.Request) (publication content.Publication, stop bool) {
+	var ok bool
+	if publication, ok = r.Context().Value(publicationKey).(content.Publication); ok {
+		return publication, false
+	}
+
+	http.Error(w, "Bad Request", http.StatusBadRequest)
+	return content.Publication{}, true
+}
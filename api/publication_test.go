@@ -0,0 +1,295 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/pkg/errors"
+	"github.com/urandom/readeef/content"
+	"github.com/urandom/readeef/content/repo/mock_repo"
+)
+
+func Test_listPublications(t *testing.T) {
+	secret := []byte("secret")
+	tests := []struct {
+		name    string
+		hasUser bool
+		query   string
+		limit   int
+		offset  int
+		listErr error
+		code    int
+	}{
+		{name: "no user", code: http.StatusBadRequest},
+		{name: "default paging", hasUser: true, limit: 50, code: http.StatusOK},
+		{name: "paging", hasUser: true, query: "limit=10&offset=20", limit: 10, offset: 20, code: http.StatusOK},
+		{name: "limit cap", hasUser: true, query: "limit=1000", limit: 200, code: http.StatusOK},
+		{name: "invalid offset", hasUser: true, query: "offset=-1", code: http.StatusBadRequest},
+		{name: "list error", hasUser: true, limit: 50, listErr: errors.New("list err"), code: http.StatusInternalServerError},
+	}
+
+	type data struct {
+		Publications []content.Publication `json:"publications"`
+		Token        string                `json:"token"`
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			publicationRepo := mock_repo.NewMockPublication(ctrl)
+
+			r := httptest.NewRequest("GET", "/?"+tt.query, nil)
+			r.ParseForm()
+			w := httptest.NewRecorder()
+
+			u := content.User{Login: "test", Hash: []byte("hash")}
+			publications := []content.Publication{{ID: 1, Title: "Shared", Link: "https://example.com"}}
+			if tt.hasUser {
+				r = r.WithContext(context.WithValue(r.Context(), userKey, u))
+
+				if tt.limit > 0 {
+					publicationRepo.EXPECT().ForUser(userMatcher{u}, tt.limit, tt.offset).Return(publications, tt.listErr)
+				}
+			}
+
+			listPublications(publicationRepo, secret, logger).ServeHTTP(w, r)
+
+			if w.Code != tt.code {
+				t.Errorf("listPublications() code = %v, want %v", w.Code, tt.code)
+				return
+			}
+
+			if tt.code != http.StatusOK {
+				return
+			}
+
+			var got data
+			if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+				t.Errorf("listPublications() body = '%s', error = %v", w.Body, err)
+				return
+			}
+
+			want := data{Publications: publications, Token: publishedFeedToken(u, secret)}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("listPublications() got = %v, want = %v", got, want)
+			}
+		})
+	}
+}
+
+func Test_updatePublication(t *testing.T) {
+	existing := content.Publication{ID: 1, UserLogin: "test", ArticleID: 10, Title: "Go", Link: "https://golang.org", Description: "<p>Go</p>"}
+
+	tests := []struct {
+		name      string
+		hasUser   bool
+		existing  *content.Publication
+		form      string
+		want      content.Publication
+		updateErr error
+		code      int
+	}{
+		{name: "no user", code: http.StatusBadRequest},
+		{name: "no link", hasUser: true, form: "title=Go", code: http.StatusBadRequest},
+		{name: "relative link", hasUser: true, form: "title=Go&link=/go", code: http.StatusBadRequest},
+		{name: "share", hasUser: true, form: "title=Go&link=https://golang.org&note=Nice", want: content.Publication{ID: 2, UserLogin: "test", Title: "Go", Link: "https://golang.org", Note: "Nice"}, code: http.StatusOK},
+		{name: "note", hasUser: true, existing: &existing, form: "note=Must+read", want: content.Publication{ID: 1, UserLogin: "test", ArticleID: 10, Title: "Go", Link: "https://golang.org", Description: "<p>Go</p>", Note: "Must read"}, code: http.StatusOK},
+		{name: "update err", hasUser: true, form: "title=Go&link=https://golang.org", updateErr: errors.New("err"), want: content.Publication{UserLogin: "test", Title: "Go", Link: "https://golang.org"}, code: http.StatusInternalServerError},
+	}
+
+	type data struct {
+		Publication content.Publication `json:"publication"`
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			publicationRepo := mock_repo.NewMockPublication(ctrl)
+
+			r := httptest.NewRequest("POST", "/", strings.NewReader(tt.form))
+			r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			r.ParseForm()
+			w := httptest.NewRecorder()
+
+			if tt.hasUser {
+				r = r.WithContext(context.WithValue(r.Context(), userKey, content.User{Login: "test"}))
+			}
+
+			if tt.existing != nil {
+				r = r.WithContext(context.WithValue(r.Context(), publicationKey, *tt.existing))
+			}
+
+			if tt.want.UserLogin != "" {
+				publicationRepo.EXPECT().Update(gomock.Any()).DoAndReturn(func(p *content.Publication) error {
+					want := tt.want
+					if tt.existing == nil {
+						want.ID = 0
+					}
+
+					if !reflect.DeepEqual(*p, want) {
+						t.Errorf("updatePublication() publication = %v, want %v", *p, want)
+					}
+
+					p.ID = tt.want.ID
+
+					return tt.updateErr
+				})
+			}
+
+			updatePublication(publicationRepo, logger).ServeHTTP(w, r)
+
+			if w.Code != tt.code {
+				t.Errorf("updatePublication() code = %v, want %v", w.Code, tt.code)
+				return
+			}
+
+			if tt.code != http.StatusOK {
+				return
+			}
+
+			var got data
+			if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+				t.Errorf("updatePublication() body = '%s', error = %v", w.Body, err)
+				return
+			}
+
+			want := tt.want
+			want.UserLogin = ""
+			if !reflect.DeepEqual(got.Publication, want) {
+				t.Errorf("updatePublication() got = %v, want = %v", got.Publication, want)
+			}
+		})
+	}
+}
+
+func Test_publishArticles(t *testing.T) {
+	tests := []struct {
+		name    string
+		method  string
+		hasUser bool
+		form    string
+		ids     []content.ArticleID
+		err     error
+		code    int
+	}{
+		{name: "no user", method: "POST", form: "id=1", code: http.StatusBadRequest},
+		{name: "no ids", method: "POST", hasUser: true, code: http.StatusBadRequest},
+		{name: "invalid id", method: "POST", hasUser: true, form: "id=1&id=foo", code: http.StatusBadRequest},
+		{name: "publish", method: "POST", hasUser: true, form: "id=1&id=2", ids: []content.ArticleID{1, 2}, code: http.StatusOK},
+		{name: "publish err", method: "POST", hasUser: true, form: "id=1", ids: []content.ArticleID{1}, err: errors.New("err"), code: http.StatusInternalServerError},
+		{name: "unpublish", method: "DELETE", hasUser: true, form: "id=3", ids: []content.ArticleID{3}, code: http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			publicationRepo := mock_repo.NewMockPublication(ctrl)
+
+			r := httptest.NewRequest(tt.method, "/?"+tt.form, nil)
+			r.ParseForm()
+			w := httptest.NewRecorder()
+
+			if tt.hasUser {
+				user := content.User{Login: "test"}
+				r = r.WithContext(context.WithValue(r.Context(), userKey, user))
+
+				if tt.ids != nil {
+					if tt.method == "POST" {
+						publicationRepo.EXPECT().Publish(userMatcher{user}, tt.ids).Return(tt.err)
+					} else {
+						publicationRepo.EXPECT().Unpublish(userMatcher{user}, tt.ids).Return(tt.err)
+					}
+				}
+			}
+
+			publishArticles(publicationRepo, logger).ServeHTTP(w, r)
+
+			if w.Code != tt.code {
+				t.Errorf("publishArticles() code = %v, want %v", w.Code, tt.code)
+			}
+		})
+	}
+}
+
+func Test_publishedFeed(t *testing.T) {
+	secret := []byte("secret")
+	user := content.User{Login: "test", FirstName: "Jo", Active: true, Hash: []byte("hash")}
+	token := publishedFeedToken(user, secret)
+
+	publications := []content.Publication{
+		{ID: 2, Title: "Shared", Link: "https://example.com/shared", Note: "Worth <a> read"},
+		{ID: 1, ArticleID: 10, Title: "Go", Link: "https://golang.org", Description: "<p>Go</p>"},
+	}
+
+	tests := []struct {
+		name     string
+		login    string
+		token    string
+		format   string
+		inactive bool
+		code     int
+		contains []string
+	}{
+		{name: "unknown user", login: "other", token: token, format: "atom", code: http.StatusNotFound},
+		{name: "invalid token", login: "test", token: "abcd", format: "atom", code: http.StatusNotFound},
+		{name: "changed password", login: "test", token: publishedFeedToken(content.User{Login: "test", Hash: []byte("new")}, secret), format: "atom", code: http.StatusNotFound},
+		{name: "inactive", login: "test", token: token, format: "atom", inactive: true, code: http.StatusNotFound},
+		{name: "atom", login: "test", token: token, format: "atom", code: http.StatusOK, contains: []string{
+			`<feed xmlns="http://www.w3.org/2005/Atom">`,
+			`<title>Published by Jo</title>`,
+			`<id>urn:readeef:published:test:2</id>`,
+			`<link href="https://example.com/shared"></link>`,
+			`<content type="html">&lt;p&gt;Worth &amp;lt;a&amp;gt; read&lt;/p&gt;</content>`,
+			`<content type="html">&lt;p&gt;Go&lt;/p&gt;</content>`,
+		}},
+		{name: "rss", login: "test", token: token, format: "rss", code: http.StatusOK, contains: []string{
+			`<rss version="2.0">`,
+			`<link>http://example.com/published/test/` + token + `/rss</link>`,
+			`<guid isPermaLink="false">urn:readeef:published:test:1</guid>`,
+			`<link>https://golang.org</link>`,
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			userRepo := mock_repo.NewMockUser(ctrl)
+			publicationRepo := mock_repo.NewMockPublication(ctrl)
+
+			r := httptest.NewRequest("GET", "/published/"+tt.login+"/"+tt.token+"/"+tt.format, nil)
+			r = addChiParam(r, "login", tt.login, "token", tt.token, "format", tt.format)
+			w := httptest.NewRecorder()
+
+			if tt.login == "test" {
+				u := user
+				u.Active = !tt.inactive
+				userRepo.EXPECT().Get(content.Login("test")).Return(u, nil)
+			} else {
+				userRepo.EXPECT().Get(content.Login(tt.login)).Return(content.User{}, content.ErrNoContent)
+			}
+
+			if tt.code == http.StatusOK {
+				publicationRepo.EXPECT().ForUser(userMatcher{user}, publishedFeedLimit, 0).Return(publications, nil)
+			}
+
+			publishedFeed(userRepo, publicationRepo, secret, logger).ServeHTTP(w, r)
+
+			if w.Code != tt.code {
+				t.Errorf("publishedFeed() code = %v, want %v", w.Code, tt.code)
+				return
+			}
+
+			for _, s := range tt.contains {
+				if !strings.Contains(w.Body.String(), s) {
+					t.Errorf("publishedFeed() body = %s, missing %s", w.Body, s)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,217 @@
+package api
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"html"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi"
+	"github.com/urandom/readeef/content"
+	"github.com/urandom/readeef/content/repo"
+	"github.com/urandom/readeef/log"
+)
+
+// The number of most recent publications included in a published feed.
+const publishedFeedLimit = 50
+
+type atomFeed struct {
+	XMLName xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	Title   string      `xml:"title"`
+	ID      string      `xml:"id"`
+	Updated string      `xml:"updated"`
+	Link    atomLink    `xml:"link"`
+	Author  atomAuthor  `xml:"author"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type atomLink struct {
+	Rel  string `xml:"rel,attr,omitempty"`
+	Href string `xml:"href,attr"`
+}
+
+type atomAuthor struct {
+	Name string `xml:"name"`
+}
+
+type atomEntry struct {
+	Title      string         `xml:"title"`
+	ID         string         `xml:"id"`
+	Link       atomLink       `xml:"link"`
+	Published  string         `xml:"published"`
+	Updated    string         `xml:"updated"`
+	Author     *atomAuthor    `xml:"author,omitempty"`
+	Categories []atomCategory `xml:"category"`
+	Content    atomText       `xml:"content"`
+}
+
+type atomCategory struct {
+	Term string `xml:"term,attr"`
+}
+
+type atomText struct {
+	Type string `xml:"type,attr"`
+	Body string `xml:",chardata"`
+}
+
+type rssFeed struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	Description   string    `xml:"description"`
+	LastBuildDate string    `xml:"lastBuildDate,omitempty"`
+	Items         []rssItem `xml:"item"`
+}
+
+type rssItem struct {
+	Title       string   `xml:"title"`
+	Link        string   `xml:"link"`
+	Description string   `xml:"description"`
+	GUID        rssGUID  `xml:"guid"`
+	PubDate     string   `xml:"pubDate"`
+	Categories  []string `xml:"category"`
+}
+
+type rssGUID struct {
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+	Value       string `xml:",chardata"`
+}
+
+// publishedFeed serves the user's most recent publications as an Atom or
+// RSS feed. The feed doesn't require authentication, and is instead
+// protected by a token that changes along with the user's password.
+func publishedFeed(userRepo repo.User, repo repo.Publication, secret []byte, log log.Log) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user, err := userRepo.Get(content.Login(chi.URLParam(r, "login")))
+		if err != nil && !content.IsNoContent(err) {
+			fatal(w, log, "Error getting user: %+v", err)
+			return
+		}
+
+		token := chi.URLParam(r, "token")
+		if err != nil || !user.Active || !hmac.Equal([]byte(token), []byte(publishedFeedToken(user, secret))) {
+			http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+			return
+		}
+
+		publications, err := repo.ForUser(user, publishedFeedLimit, 0)
+		if err != nil {
+			fatal(w, log, "Error getting publications: %+v", err)
+			return
+		}
+
+		if chi.URLParam(r, "format") == "rss" {
+			writeFeed(w, publishedRSS(user, publications, requestURL(r)), log)
+		} else {
+			writeFeed(w, publishedAtom(user, publications, requestURL(r)), log)
+		}
+	}
+}
+
+func publishedAtom(user content.User, publications []content.Publication, self string) atomFeed {
+	feed := atomFeed{
+		Title:   publishedFeedTitle(user),
+		ID:      fmt.Sprintf("urn:readeef:published:%s", user.Login),
+		Updated: emptyFeedUpdated.Format(time.RFC3339),
+		Link:    atomLink{Rel: "self", Href: self},
+		Author:  atomAuthor{Name: publishedFeedAuthor(user)},
+	}
+
+	if len(publications) > 0 {
+		feed.Updated = publications[0].Date.UTC().Format(time.RFC3339)
+	}
+
+	for _, p := range publications {
+		date := p.Date.UTC().Format(time.RFC3339)
+		feed.Entries = append(feed.Entries, atomEntry{
+			Title:     p.Title,
+			ID:        publicationGUID(user, p),
+			Link:      atomLink{Href: p.Link},
+			Published: date,
+			Updated:   date,
+			Content:   atomText{Type: "html", Body: publicationContent(p)},
+		})
+	}
+
+	return feed
+}
+
+func publishedRSS(user content.User, publications []content.Publication, self string) rssFeed {
+	feed := rssFeed{Version: "2.0", Channel: rssChannel{
+		Title:       publishedFeedTitle(user),
+		Link:        self,
+		Description: fmt.Sprintf("Articles and links shared by %s", publishedFeedAuthor(user)),
+	}}
+
+	if len(publications) > 0 {
+		feed.Channel.LastBuildDate = publications[0].Date.UTC().Format(time.RFC1123Z)
+	}
+
+	for _, p := range publications {
+		feed.Channel.Items = append(feed.Channel.Items, rssItem{
+			Title:       p.Title,
+			Link:        p.Link,
+			Description: publicationContent(p),
+			GUID:        rssGUID{Value: publicationGUID(user, p)},
+			PubDate:     p.Date.UTC().Format(time.RFC1123Z),
+		})
+	}
+
+	return feed
+}
+
+func publishedFeedTitle(user content.User) string {
+	return fmt.Sprintf("Published by %s", publishedFeedAuthor(user))
+}
+
+func publishedFeedAuthor(user content.User) string {
+	if name := strings.TrimSpace(user.FirstName + " " + user.LastName); name != "" {
+		return name
+	}
+
+	return string(user.Login)
+}
+
+func publicationGUID(user content.User, p content.Publication) string {
+	return fmt.Sprintf("urn:readeef:published:%s:%d", user.Login, p.ID)
+}
+
+// publicationContent places the publication note above its description.
+func publicationContent(p content.Publication) string {
+	if p.Note == "" {
+		return p.Description
+	}
+
+	return "<p>" + html.EscapeString(p.Note) + "</p>" + p.Description
+}
+
+// publishedFeedToken signs the user's login and password hash. The
+// signature is salted with a purpose string, since the emulators may sign
+// the same data for their own tokens.
+func publishedFeedToken(user content.User, secret []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte("published-feed:"))
+	mac.Write([]byte(user.Login))
+	mac.Write(user.Hash)
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func requestURL(r *http.Request) string {
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+
+	return scheme + "://" + r.Host + r.URL.RequestURI()
+}
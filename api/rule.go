@@ -0,0 +1,134 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi"
+	"github.com/urandom/readeef/content"
+	"github.com/urandom/readeef/content/repo"
+	"github.com/urandom/readeef/log"
+)
+
+var ruleKey = contextKey("rule")
+
+func listRules(repo repo.Rule, log log.Log) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user, stop := userFromRequest(w, r)
+		if stop {
+			return
+		}
+
+		rules, err := repo.ForUser(user)
+		if err != nil {
+			fatal(w, log, "Error getting rules: %+v", err)
+			return
+		}
+
+		args{"rules": rules}.WriteJSON(w)
+	}
+}
+
+func getRule(w http.ResponseWriter, r *http.Request) {
+	rule, stop := ruleFromRequest(w, r)
+	if stop {
+		return
+	}
+
+	args{"rule": rule}.WriteJSON(w)
+}
+
+// updateRule creates a new rule, or updates the one in the request context,
+// using the name, field, match, value and action form values.
+func updateRule(repo repo.Rule, log log.Log) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user, stop := userFromRequest(w, r)
+		if stop {
+			return
+		}
+
+		rule, ok := r.Context().Value(ruleKey).(content.Rule)
+		if !ok {
+			rule = content.Rule{UserLogin: user.Login}
+		}
+
+		rule.Name = r.Form.Get("name")
+		rule.Field = content.RuleField(r.Form.Get("field"))
+		rule.Match = content.RuleMatch(r.Form.Get("match"))
+		rule.Value = r.Form.Get("value")
+
+		rule.Actions = make(content.RuleActions, len(r.Form["action"]))
+		for i, a := range r.Form["action"] {
+			rule.Actions[i] = content.RuleAction(a)
+		}
+
+		if err := rule.Validate(); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		if err := repo.Update(&rule); err != nil {
+			fatal(w, log, "Error updating rule: %+v", err)
+			return
+		}
+
+		args{"rule": rule}.WriteJSON(w)
+	}
+}
+
+func deleteRule(repo repo.Rule, log log.Log) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		rule, stop := ruleFromRequest(w, r)
+		if stop {
+			return
+		}
+
+		if err := repo.Delete(rule); err != nil {
+			fatal(w, log, "Error deleting rule: %+v", err)
+			return
+		}
+
+		args{"success": true}.WriteJSON(w)
+	}
+}
+
+func ruleContext(repo repo.Rule, log log.Log) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			user, stop := userFromRequest(w, r)
+			if stop {
+				return
+			}
+
+			id, err := strconv.ParseInt(chi.URLParam(r, "ruleID"), 10, 64)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+
+			rule, err := repo.Get(content.RuleID(id), user)
+			if err != nil {
+				if content.IsNoContent(err) {
+					http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+				} else {
+					fatal(w, log, "Error getting rule: %+v", err)
+				}
+				return
+			}
+
+			ctx := context.WithValue(r.Context(), ruleKey, rule)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+func ruleFromRequest(w http.ResponseWriter, r *http.Request) (rule content.Rule, stop bool) {
+	var ok bool
+	if rule, ok = r.Context().Value(ruleKey).(content.Rule); ok {
+		return rule, false
+	}
+
+	http.Error(w, "Bad Request", http.StatusBadRequest)
+	return content.Rule{}, true
+}
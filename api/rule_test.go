@@ -0,0 +1,267 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/pkg/errors"
+	"github.com/urandom/readeef/content"
+	"github.com/urandom/readeef/content/repo/mock_repo"
+)
+
+func Test_listRules(t *testing.T) {
+	tests := []struct {
+		name    string
+		hasUser bool
+		listErr error
+	}{
+		{"no user", false, nil},
+		{"success list", true, nil},
+		{"list error", true, errors.New("list err")},
+	}
+
+	type data struct {
+		Rules []content.Rule `json:"rules"`
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			ruleRepo := mock_repo.NewMockRule(ctrl)
+
+			r := httptest.NewRequest("GET", "/", nil)
+			w := httptest.NewRecorder()
+
+			code := http.StatusBadRequest
+			want := data{}
+			if tt.hasUser {
+				u := content.User{Login: "test"}
+				r = r.WithContext(context.WithValue(r.Context(), userKey, u))
+
+				var rules []content.Rule
+				if tt.listErr == nil {
+					code = http.StatusOK
+					rules = []content.Rule{{ID: 1, Field: content.RuleFieldTitle, Match: content.RuleMatchSubstring, Value: "go", Actions: content.RuleActions{content.RuleActionRead}}}
+					want.Rules = rules
+				} else {
+					code = http.StatusInternalServerError
+				}
+
+				ruleRepo.EXPECT().ForUser(userMatcher{u}).Return(rules, tt.listErr)
+			}
+
+			listRules(ruleRepo, logger).ServeHTTP(w, r)
+
+			if w.Code != code {
+				t.Errorf("listRules() code = %v, want %v", w.Code, code)
+				return
+			}
+
+			var got data
+			if err := json.Unmarshal(w.Body.Bytes(), &got); (err != nil) && (w.Code == http.StatusOK) {
+				t.Errorf("listRules() body = '%s', error = %v", w.Body, err)
+				return
+			}
+
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("listRules() got = %v, want = %v", got, want)
+			}
+		})
+	}
+}
+
+func Test_updateRule(t *testing.T) {
+	tests := []struct {
+		name      string
+		hasUser   bool
+		existing  *content.Rule
+		form      string
+		want      content.Rule
+		updateErr error
+		code      int
+	}{
+		{name: "no user", code: http.StatusBadRequest},
+		{name: "invalid", hasUser: true, form: "field=title&match=exact&value=go&action=read", code: http.StatusBadRequest},
+		{
+			name: "create", hasUser: true, form: "name=Go&field=title&match=substring&value=go&action=read&action=favorite",
+			want: content.Rule{ID: 2, UserLogin: "test", Name: "Go", Field: content.RuleFieldTitle, Match: content.RuleMatchSubstring, Value: "go", Actions: content.RuleActions{content.RuleActionRead, content.RuleActionFavorite}},
+			code: http.StatusOK,
+		},
+		{
+			name: "update", hasUser: true, existing: &content.Rule{ID: 1, UserLogin: "test"}, form: "field=author&match=regex&value=^John&action=hide",
+			want: content.Rule{ID: 1, UserLogin: "test", Field: content.RuleFieldAuthor, Match: content.RuleMatchRegex, Value: "^John", Actions: content.RuleActions{content.RuleActionHide}},
+			code: http.StatusOK,
+		},
+		{
+			name: "update err", hasUser: true, form: "field=title&match=substring&value=go&action=read", updateErr: errors.New("err"),
+			want: content.Rule{UserLogin: "test", Field: content.RuleFieldTitle, Match: content.RuleMatchSubstring, Value: "go", Actions: content.RuleActions{content.RuleActionRead}},
+			code: http.StatusInternalServerError,
+		},
+	}
+
+	type data struct {
+		Rule content.Rule `json:"rule"`
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			ruleRepo := mock_repo.NewMockRule(ctrl)
+
+			r := httptest.NewRequest("POST", "/", strings.NewReader(tt.form))
+			r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			r.ParseForm()
+			w := httptest.NewRecorder()
+
+			if tt.hasUser {
+				r = r.WithContext(context.WithValue(r.Context(), userKey, content.User{Login: "test"}))
+			}
+
+			if tt.existing != nil {
+				r = r.WithContext(context.WithValue(r.Context(), ruleKey, *tt.existing))
+			}
+
+			if tt.want.UserLogin != "" {
+				ruleRepo.EXPECT().Update(gomock.Any()).DoAndReturn(func(rule *content.Rule) error {
+					want := tt.want
+					if tt.existing == nil {
+						want.ID = 0
+					}
+
+					if !reflect.DeepEqual(*rule, want) {
+						t.Errorf("updateRule() rule = %v, want %v", *rule, want)
+					}
+
+					rule.ID = tt.want.ID
+
+					return tt.updateErr
+				})
+			}
+
+			updateRule(ruleRepo, logger).ServeHTTP(w, r)
+
+			if w.Code != tt.code {
+				t.Errorf("updateRule() code = %v, want %v", w.Code, tt.code)
+				return
+			}
+
+			if tt.code != http.StatusOK {
+				return
+			}
+
+			var got data
+			if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+				t.Errorf("updateRule() body = '%s', error = %v", w.Body, err)
+				return
+			}
+
+			want := tt.want
+			want.UserLogin = ""
+			if !reflect.DeepEqual(got.Rule, want) {
+				t.Errorf("updateRule() got = %v, want = %v", got.Rule, want)
+			}
+		})
+	}
+}
+
+func Test_deleteRule(t *testing.T) {
+	tests := []struct {
+		name      string
+		hasRule   bool
+		deleteErr error
+		code      int
+	}{
+		{"no rule", false, nil, http.StatusBadRequest},
+		{"delete err", true, errors.New("err"), http.StatusInternalServerError},
+		{"success", true, nil, http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			ruleRepo := mock_repo.NewMockRule(ctrl)
+
+			r := httptest.NewRequest("DELETE", "/", nil)
+			w := httptest.NewRecorder()
+
+			if tt.hasRule {
+				rule := content.Rule{ID: 1, UserLogin: "test"}
+				r = r.WithContext(context.WithValue(r.Context(), ruleKey, rule))
+
+				ruleRepo.EXPECT().Delete(rule).Return(tt.deleteErr)
+			}
+
+			deleteRule(ruleRepo, logger).ServeHTTP(w, r)
+
+			if w.Code != tt.code {
+				t.Errorf("deleteRule() code = %v, want %v", w.Code, tt.code)
+			}
+		})
+	}
+}
+
+func Test_ruleContext(t *testing.T) {
+	tests := []struct {
+		name    string
+		hasUser bool
+		hasID   bool
+		getErr  error
+	}{
+		{"no user", false, false, nil},
+		{"no id", true, false, nil},
+		{"get err", true, true, errors.New("get err")},
+		{"rule value", true, true, nil},
+		{"unknown rule", true, true, content.ErrNoContent},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			ruleRepo := mock_repo.NewMockRule(ctrl)
+
+			r := httptest.NewRequest("GET", "/", nil)
+			w := httptest.NewRecorder()
+
+			code := http.StatusBadRequest
+			if tt.hasUser {
+				user := content.User{Login: "test"}
+				r = r.WithContext(context.WithValue(r.Context(), userKey, user))
+
+				if tt.hasID {
+					r = addChiParam(r, "ruleID", "1")
+					rule := content.Rule{}
+					if tt.getErr == nil {
+						code = http.StatusNoContent
+						rule.ID = 1
+					} else if content.IsNoContent(tt.getErr) {
+						code = http.StatusNotFound
+					} else {
+						code = http.StatusInternalServerError
+					}
+
+					ruleRepo.EXPECT().Get(content.RuleID(1), userMatcher{user}).Return(rule, tt.getErr)
+				} else {
+					r = addChiParam(r, "ruleID", "foo")
+				}
+			}
+
+			ruleContext(ruleRepo, logger)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if rule, ok := r.Context().Value(ruleKey).(content.Rule); !ok || rule.ID != 1 {
+					t.Errorf("ruleContext() rule ctx value = %v", r.Context().Value(ruleKey))
+					return
+				}
+				w.WriteHeader(http.StatusNoContent)
+			})).ServeHTTP(w, r)
+
+			if w.Code != code {
+				t.Errorf("ruleContext() code = %v, want %v", w.Code, code)
+			}
+		})
+	}
+}
@@ -0,0 +1,244 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi"
+	"github.com/urandom/readeef/content"
+	"github.com/urandom/readeef/content/processor"
+	"github.com/urandom/readeef/content/repo"
+	"github.com/urandom/readeef/content/search"
+	"github.com/urandom/readeef/log"
+)
+
+var savedSearchKey = contextKey("savedSearch")
+
+// listSavedSearches returns the user's saved searches, along with the number
+// of unread articles each one finds.
+func listSavedSearches(service repo.Service, searchProvider searcher, log log.Log) http.HandlerFunc {
+	repo := service.SavedSearchRepo()
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		user, stop := userFromRequest(w, r)
+		if stop {
+			return
+		}
+
+		searches, err := repo.ForUser(user)
+		if err != nil {
+			fatal(w, log, "Error getting saved searches: %+v", err)
+			return
+		}
+
+		unread := map[content.SavedSearchID]int64{}
+		for _, s := range searches {
+			if unread[s.ID], err = search.CountSavedSearch(searchProvider, service, s, user, content.UnreadOnly); err != nil {
+				fatal(w, log, "Error getting saved search unread count: %+v", err)
+				return
+			}
+		}
+
+		args{"savedSearches": searches, "unread": unread}.WriteJSON(w)
+	}
+}
+
+func getSavedSearch(w http.ResponseWriter, r *http.Request) {
+	savedSearch, stop := savedSearchFromRequest(w, r)
+	if stop {
+		return
+	}
+
+	args{"savedSearch": savedSearch}.WriteJSON(w)
+}
+
+// updateSavedSearch creates a new saved search, or changes the one in the
+// request context, using the name, query, feedID and tagID form values.
+func updateSavedSearch(service repo.Service, log log.Log) http.HandlerFunc {
+	repo := service.SavedSearchRepo()
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		user, stop := userFromRequest(w, r)
+		if stop {
+			return
+		}
+
+		savedSearch, ok := r.Context().Value(savedSearchKey).(content.SavedSearch)
+		if !ok {
+			savedSearch = content.SavedSearch{UserLogin: user.Login}
+		}
+
+		// Only the given fields of an existing saved search are changed
+		for name, field := range map[string]*string{
+			"name":  &savedSearch.Name,
+			"query": &savedSearch.Query,
+		} {
+			if _, ok := r.Form[name]; ok || savedSearch.ID == 0 {
+				*field = r.Form.Get(name)
+			}
+		}
+
+		for name, field := range map[string]*int64{
+			"feedID": (*int64)(&savedSearch.FeedID),
+			"tagID":  (*int64)(&savedSearch.TagID),
+		} {
+			if v := r.Form.Get(name); v != "" {
+				id, err := strconv.ParseInt(v, 10, 64)
+				if err != nil {
+					http.Error(w, err.Error(), http.StatusBadRequest)
+					return
+				}
+
+				*field = id
+			} else if _, ok := r.Form[name]; ok {
+				*field = 0
+			}
+		}
+
+		if err := savedSearch.Validate(); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		if savedSearch.FeedID != 0 {
+			if _, err := service.FeedRepo().Get(savedSearch.FeedID, user); err != nil {
+				if content.IsNoContent(err) {
+					http.Error(w, "Unknown feed", http.StatusBadRequest)
+				} else {
+					fatal(w, log, "Error getting feed: %+v", err)
+				}
+				return
+			}
+		}
+
+		if savedSearch.TagID != 0 {
+			if _, err := service.TagRepo().Get(savedSearch.TagID, user); err != nil {
+				if content.IsNoContent(err) {
+					http.Error(w, "Unknown tag", http.StatusBadRequest)
+				} else {
+					fatal(w, log, "Error getting tag: %+v", err)
+				}
+				return
+			}
+		}
+
+		searches, err := repo.ForUser(user)
+		if err != nil {
+			fatal(w, log, "Error getting saved searches: %+v", err)
+			return
+		}
+
+		for _, s := range searches {
+			if s.Name == savedSearch.Name && s.ID != savedSearch.ID {
+				http.Error(w, "Saved search already exists", http.StatusConflict)
+				return
+			}
+		}
+
+		if err := repo.Update(&savedSearch); err != nil {
+			fatal(w, log, "Error updating saved search: %+v", err)
+			return
+		}
+
+		args{"savedSearch": savedSearch}.WriteJSON(w)
+	}
+}
+
+func deleteSavedSearch(repo repo.SavedSearch, log log.Log) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		savedSearch, stop := savedSearchFromRequest(w, r)
+		if stop {
+			return
+		}
+
+		if err := repo.Delete(savedSearch); err != nil {
+			fatal(w, log, "Error deleting saved search: %+v", err)
+			return
+		}
+
+		args{"success": true}.WriteJSON(w)
+	}
+}
+
+// savedSearchArticles returns the articles found by the saved search in the
+// request context.
+func savedSearchArticles(
+	service repo.Service,
+	searchProvider searcher,
+	processors []processor.Article,
+	articlesLimit int,
+	log log.Log,
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user, stop := userFromRequest(w, r)
+		if stop {
+			return
+		}
+
+		savedSearch, stop := savedSearchFromRequest(w, r)
+		if stop {
+			return
+		}
+
+		o, stop := articleQueryOptions(w, r, articlesLimit)
+		if stop {
+			return
+		}
+
+		o = append(o, content.Filters(content.GetUserFilters(user)), content.IncludeCategories)
+
+		articles, err := search.SavedSearch(searchProvider, service, savedSearch, user, o...)
+		if err != nil {
+			fatal(w, log, "Error searching for articles: %+v", err)
+			return
+		}
+
+		articles = processor.Articles(processors).Process(articles)
+
+		if articles == nil {
+			articles = []content.Article{}
+		}
+		args{"articles": articles}.WriteJSON(w)
+	}
+}
+
+func savedSearchContext(repo repo.SavedSearch, log log.Log) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			user, stop := userFromRequest(w, r)
+			if stop {
+				return
+			}
+
+			id, err := strconv.ParseInt(chi.URLParam(r, "savedSearchID"), 10, 64)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+
+			savedSearch, err := repo.Get(content.SavedSearchID(id), user)
+			if err != nil {
+				if content.IsNoContent(err) {
+					http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+				} else {
+					fatal(w, log, "Error getting saved search: %+v", err)
+				}
+				return
+			}
+
+			ctx := context.WithValue(r.Context(), savedSearchKey, savedSearch)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+func savedSearchFromRequest(w http.ResponseWriter, r *http.Request) (savedSearch content.SavedSearch, stop bool) {
+	var ok bool
+	if savedSearch, ok = r.Context().Value(savedSearchKey).(content.SavedSearch); ok {
+		return savedSearch, false
+	}
+
+	http.Error(w, "Bad Request", http.StatusBadRequest)
+	return content.SavedSearch{}, true
+}
@@ -0,0 +1,268 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/pkg/errors"
+	"github.com/urandom/readeef/content"
+	"github.com/urandom/readeef/content/repo/mock_repo"
+)
+
+func Test_listSavedSearches(t *testing.T) {
+	searches := []content.SavedSearch{
+		{ID: 1, Name: "Go", Query: "golang"},
+		{ID: 2, Name: "Rust", Query: "rust", FeedID: 3},
+	}
+
+	tests := []struct {
+		name      string
+		hasUser   bool
+		listErr   error
+		searchErr error
+		code      int
+	}{
+		{name: "no user", code: http.StatusBadRequest},
+		{name: "list err", hasUser: true, listErr: errors.New("err"), code: http.StatusInternalServerError},
+		{name: "search err", hasUser: true, searchErr: errors.New("err"), code: http.StatusInternalServerError},
+		{name: "list", hasUser: true, code: http.StatusOK},
+	}
+
+	type data struct {
+		SavedSearches []content.SavedSearch           `json:"savedSearches"`
+		Unread        map[content.SavedSearchID]int64 `json:"unread"`
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			service := mock_repo.NewMockService(ctrl)
+			savedSearchRepo := mock_repo.NewMockSavedSearch(ctrl)
+			feedRepo := mock_repo.NewMockFeed(ctrl)
+			searchProvider := NewMocksearcher(ctrl)
+
+			r := httptest.NewRequest("GET", "/", nil)
+			w := httptest.NewRecorder()
+
+			service.EXPECT().SavedSearchRepo().Return(savedSearchRepo)
+
+			if tt.hasUser {
+				user := content.User{Login: "test"}
+				r = r.WithContext(context.WithValue(r.Context(), userKey, user))
+
+				savedSearchRepo.EXPECT().ForUser(userMatcher{user}).Return(searches, tt.listErr)
+
+				if tt.listErr == nil {
+					searchProvider.EXPECT().Search("golang", userMatcher{user}, gomock.Any()).DoAndReturn(func(_ string, _ content.User, opts ...content.QueryOpt) ([]content.Article, error) {
+						o := content.QueryOptions{}
+						o.Apply(opts)
+
+						if !o.UnreadOnly || len(o.FeedIDs) > 0 {
+							t.Errorf("listSavedSearches() options = %#v", o)
+						}
+
+						return []content.Article{{ID: 1}, {ID: 2}}, tt.searchErr
+					})
+				}
+
+				if tt.code == http.StatusOK {
+					service.EXPECT().FeedRepo().Return(feedRepo)
+					feedRepo.EXPECT().Get(content.FeedID(3), userMatcher{user}).Return(content.Feed{ID: 3}, nil)
+					searchProvider.EXPECT().Search("rust", userMatcher{user}, gomock.Any()).Return([]content.Article{{ID: 3}}, nil)
+				}
+			}
+
+			listSavedSearches(service, searchProvider, logger).ServeHTTP(w, r)
+
+			if w.Code != tt.code {
+				t.Errorf("listSavedSearches() code = %v, want %v", w.Code, tt.code)
+				return
+			}
+
+			if tt.code != http.StatusOK {
+				return
+			}
+
+			var got data
+			if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+				t.Errorf("listSavedSearches() body = '%s', error = %v", w.Body, err)
+				return
+			}
+
+			want := data{SavedSearches: searches, Unread: map[content.SavedSearchID]int64{1: 2, 2: 1}}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("listSavedSearches() got = %v, want = %v", got, want)
+			}
+		})
+	}
+}
+
+func Test_updateSavedSearch(t *testing.T) {
+	existing := content.SavedSearch{ID: 1, UserLogin: "test", Name: "Go", Query: "golang", TagID: 2}
+
+	tests := []struct {
+		name      string
+		hasUser   bool
+		existing  *content.SavedSearch
+		form      string
+		feedErr   error
+		searches  []content.SavedSearch
+		want      content.SavedSearch
+		updateErr error
+		code      int
+	}{
+		{name: "no user", code: http.StatusBadRequest},
+		{name: "no query", hasUser: true, form: "name=Go", code: http.StatusBadRequest},
+		{name: "invalid feed id", hasUser: true, form: "name=Go&query=golang&feedID=go", code: http.StatusBadRequest},
+		{name: "feed and tag", hasUser: true, form: "name=Go&query=golang&feedID=1&tagID=2", code: http.StatusBadRequest},
+		{name: "unknown feed", hasUser: true, form: "name=Go&query=golang&feedID=1", feedErr: content.ErrNoContent, code: http.StatusBadRequest},
+		{name: "duplicate", hasUser: true, form: "name=Go&query=go", searches: []content.SavedSearch{existing}, code: http.StatusConflict},
+		{name: "create", hasUser: true, form: "name=Go&query=golang&feedID=1", want: content.SavedSearch{ID: 2, UserLogin: "test", Name: "Go", Query: "golang", FeedID: 1}, code: http.StatusOK},
+		{name: "rename", hasUser: true, existing: &existing, form: "name=Golang", searches: []content.SavedSearch{existing}, want: content.SavedSearch{ID: 1, UserLogin: "test", Name: "Golang", Query: "golang", TagID: 2}, code: http.StatusOK},
+		{name: "unscope", hasUser: true, existing: &existing, form: "tagID=", searches: []content.SavedSearch{existing}, want: content.SavedSearch{ID: 1, UserLogin: "test", Name: "Go", Query: "golang"}, code: http.StatusOK},
+		{name: "update err", hasUser: true, form: "name=Go&query=golang", updateErr: errors.New("err"), want: content.SavedSearch{UserLogin: "test", Name: "Go", Query: "golang"}, code: http.StatusInternalServerError},
+	}
+
+	type data struct {
+		SavedSearch content.SavedSearch `json:"savedSearch"`
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			service := mock_repo.NewMockService(ctrl)
+			savedSearchRepo := mock_repo.NewMockSavedSearch(ctrl)
+			feedRepo := mock_repo.NewMockFeed(ctrl)
+			tagRepo := mock_repo.NewMockTag(ctrl)
+
+			r := httptest.NewRequest("POST", "/", strings.NewReader(tt.form))
+			r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			r.ParseForm()
+			w := httptest.NewRecorder()
+
+			service.EXPECT().SavedSearchRepo().Return(savedSearchRepo)
+			service.EXPECT().FeedRepo().Return(feedRepo).AnyTimes()
+			service.EXPECT().TagRepo().Return(tagRepo).AnyTimes()
+
+			user := content.User{Login: "test"}
+			if tt.hasUser {
+				r = r.WithContext(context.WithValue(r.Context(), userKey, user))
+			}
+
+			if tt.existing != nil {
+				r = r.WithContext(context.WithValue(r.Context(), savedSearchKey, *tt.existing))
+			}
+
+			feedRepo.EXPECT().Get(content.FeedID(1), userMatcher{user}).Return(content.Feed{ID: 1}, tt.feedErr).AnyTimes()
+			tagRepo.EXPECT().Get(content.TagID(2), userMatcher{user}).Return(content.Tag{ID: 2}, nil).AnyTimes()
+			savedSearchRepo.EXPECT().ForUser(userMatcher{user}).Return(tt.searches, nil).AnyTimes()
+
+			if tt.want.UserLogin != "" {
+				savedSearchRepo.EXPECT().Update(gomock.Any()).DoAndReturn(func(s *content.SavedSearch) error {
+					want := tt.want
+					if tt.existing == nil {
+						want.ID = 0
+					}
+
+					if !reflect.DeepEqual(*s, want) {
+						t.Errorf("updateSavedSearch() saved search = %v, want %v", *s, want)
+					}
+
+					s.ID = tt.want.ID
+
+					return tt.updateErr
+				})
+			}
+
+			updateSavedSearch(service, logger).ServeHTTP(w, r)
+
+			if w.Code != tt.code {
+				t.Errorf("updateSavedSearch() code = %v, want %v", w.Code, tt.code)
+				return
+			}
+
+			if tt.code != http.StatusOK {
+				return
+			}
+
+			var got data
+			if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+				t.Errorf("updateSavedSearch() body = '%s', error = %v", w.Body, err)
+				return
+			}
+
+			want := tt.want
+			want.UserLogin = ""
+			if !reflect.DeepEqual(got.SavedSearch, want) {
+				t.Errorf("updateSavedSearch() got = %v, want = %v", got.SavedSearch, want)
+			}
+		})
+	}
+}
+
+func Test_savedSearchArticles(t *testing.T) {
+	tests := []struct {
+		name      string
+		url       string
+		search    content.SavedSearch
+		tagFeeds  []content.FeedID
+		opts      content.QueryOptions
+		searchErr error
+		code      int
+	}{
+		{name: "invalid limit", url: "/?limit=no", search: content.SavedSearch{Query: "go"}, code: http.StatusBadRequest},
+		{name: "unscoped", url: "/?unreadOnly", search: content.SavedSearch{Query: "go"}, opts: content.QueryOptions{Limit: 50, UnreadOnly: true, SortField: content.SortByDate, SortOrder: content.DescendingOrder, IncludeCategories: true}, code: http.StatusOK},
+		{name: "tag scope", url: "/", search: content.SavedSearch{Query: "go", TagID: 2}, tagFeeds: []content.FeedID{1, 3}, opts: content.QueryOptions{Limit: 50, FeedIDs: []content.FeedID{1, 3}, SortField: content.SortByDate, SortOrder: content.DescendingOrder, IncludeCategories: true}, code: http.StatusOK},
+		{name: "empty tag", url: "/", search: content.SavedSearch{Query: "go", TagID: 2}, tagFeeds: []content.FeedID{}, code: http.StatusOK},
+		{name: "search err", url: "/", search: content.SavedSearch{Query: "go"}, searchErr: errors.New("err"), opts: content.QueryOptions{Limit: 50, SortField: content.SortByDate, SortOrder: content.DescendingOrder, IncludeCategories: true}, code: http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			service := mock_repo.NewMockService(ctrl)
+			tagRepo := mock_repo.NewMockTag(ctrl)
+			searchProvider := NewMocksearcher(ctrl)
+
+			r := httptest.NewRequest("GET", tt.url, nil)
+			r.ParseForm()
+			w := httptest.NewRecorder()
+
+			user := content.User{Login: "test"}
+			r = r.WithContext(context.WithValue(r.Context(), userKey, user))
+			r = r.WithContext(context.WithValue(r.Context(), savedSearchKey, tt.search))
+
+			if tt.tagFeeds != nil {
+				tag := content.Tag{ID: 2}
+				service.EXPECT().TagRepo().Return(tagRepo).Times(2)
+				tagRepo.EXPECT().Get(content.TagID(2), userMatcher{user}).Return(tag, nil)
+				tagRepo.EXPECT().FeedIDs(tag, userMatcher{user}).Return(tt.tagFeeds, nil)
+			}
+
+			if tt.opts.Limit > 0 {
+				searchProvider.EXPECT().Search(tt.search.Query, userMatcher{user}, gomock.Any()).DoAndReturn(func(_ string, _ content.User, opts ...content.QueryOpt) ([]content.Article, error) {
+					o := content.QueryOptions{}
+					o.Apply(opts)
+
+					if !reflect.DeepEqual(o, tt.opts) {
+						t.Errorf("savedSearchArticles() options = %#v, want %#v", o, tt.opts)
+					}
+
+					return []content.Article{{ID: 1}}, tt.searchErr
+				})
+			}
+
+			savedSearchArticles(service, searchProvider, nil, 50, logger).ServeHTTP(w, r)
+
+			if w.Code != tt.code {
+				t.Errorf("savedSearchArticles() code = %v, want %v", w.Code, tt.code)
+			}
+		})
+	}
+}
@@ -0,0 +1,149 @@
+package api
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+
+	"github.com/urandom/readeef/content"
+	"github.com/urandom/readeef/content/repo"
+	"github.com/urandom/readeef/log"
+)
+
+const maxSyncChanges = 1000
+
+type syncDelta struct {
+	Cursor content.ChangeID `json:"cursor"`
+	// Reset is set when the client has to fetch the full article state,
+	// since the changes after its cursor are no longer known.
+	Reset bool `json:"reset"`
+	// More is set when there are more changes after the cursor.
+	More bool `json:"more"`
+
+	Inserted   []content.ArticleID `json:"inserted"`
+	Read       []content.ArticleID `json:"read"`
+	Unread     []content.ArticleID `json:"unread"`
+	Favorite   []content.ArticleID `json:"favorite"`
+	Unfavorite []content.ArticleID `json:"unfavorite"`
+}
+
+// getSync returns the user's article changes after the 'since' cursor. The
+// changes are collapsed, so that each article appears only in the list of
+// its latest read and favorite change, while inserted articles are expected
+// to be fetched along with their state.
+func getSync(repo repo.Article, log log.Log) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user, stop := userFromRequest(w, r)
+		if stop {
+			return
+		}
+
+		var since int64
+		if v := r.Form.Get("since"); v != "" {
+			var err error
+			if since, err = strconv.ParseInt(v, 10, 64); err != nil || since < 0 {
+				http.Error(w, "Invalid since cursor", http.StatusBadRequest)
+				return
+			}
+		}
+
+		limit := maxSyncChanges
+		if v, err := strconv.Atoi(r.Form.Get("limit")); err == nil && v > 0 && v < limit {
+			limit = v
+		}
+
+		first, last, err := repo.ChangeRange()
+		if err != nil {
+			fatal(w, log, "Error getting article change range: %+v", err)
+			return
+		}
+
+		delta := newSyncDelta()
+
+		// Changes older than the first one in the log have been removed, so
+		// a client that hasn't synced since can't rely on the log. A cursor
+		// past the last change is also unknown, as after a database reset.
+		cursor := content.ChangeID(since)
+		if cursor == 0 || first == 0 || cursor < first-1 || cursor > last {
+			delta.Cursor = last
+			delta.Reset = true
+
+			args{"sync": delta}.WriteJSON(w)
+			return
+		}
+
+		changes, err := repo.Changes(user, cursor, limit)
+		if err != nil {
+			fatal(w, log, "Error getting article changes: %+v", err)
+			return
+		}
+
+		delta.Cursor = cursor
+		delta.More = len(changes) == limit
+		delta.collapse(changes)
+
+		if !delta.More && last > delta.Cursor {
+			// Advance the cursor past the other users' changes, to keep it
+			// within the log.
+			delta.Cursor = last
+		}
+
+		args{"sync": delta}.WriteJSON(w)
+	}
+}
+
+func newSyncDelta() syncDelta {
+	return syncDelta{
+		Inserted:   []content.ArticleID{},
+		Read:       []content.ArticleID{},
+		Unread:     []content.ArticleID{},
+		Favorite:   []content.ArticleID{},
+		Unfavorite: []content.ArticleID{},
+	}
+}
+
+func (d *syncDelta) collapse(changes []content.ArticleChange) {
+	inserted := map[content.ArticleID]bool{}
+	read := map[content.ArticleID]content.ChangeType{}
+	favorite := map[content.ArticleID]content.ChangeType{}
+
+	for _, c := range changes {
+		d.Cursor = c.ID
+
+		switch c.Type {
+		case content.ChangeInsert:
+			inserted[c.ArticleID] = true
+		case content.ChangeRead, content.ChangeUnread:
+			read[c.ArticleID] = c.Type
+		case content.ChangeFavorite, content.ChangeUnfavorite:
+			favorite[c.ArticleID] = c.Type
+		}
+	}
+
+	for id := range inserted {
+		d.Inserted = append(d.Inserted, id)
+	}
+
+	for _, m := range []map[content.ArticleID]content.ChangeType{read, favorite} {
+		for id, t := range m {
+			if inserted[id] {
+				continue
+			}
+
+			switch t {
+			case content.ChangeRead:
+				d.Read = append(d.Read, id)
+			case content.ChangeUnread:
+				d.Unread = append(d.Unread, id)
+			case content.ChangeFavorite:
+				d.Favorite = append(d.Favorite, id)
+			case content.ChangeUnfavorite:
+				d.Unfavorite = append(d.Unfavorite, id)
+			}
+		}
+	}
+
+	for _, ids := range [][]content.ArticleID{d.Inserted, d.Read, d.Unread, d.Favorite, d.Unfavorite} {
+		sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	}
+}
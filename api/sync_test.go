@@ -0,0 +1,113 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/pkg/errors"
+	"github.com/urandom/readeef/content"
+	"github.com/urandom/readeef/content/repo/mock_repo"
+)
+
+func Test_getSync(t *testing.T) {
+	changes := []content.ArticleChange{
+		{ID: 11, ArticleID: 1, Type: content.ChangeRead},
+		{ID: 12, ArticleID: 2, Type: content.ChangeFavorite},
+		{ID: 13, ArticleID: 1, Type: content.ChangeUnread},
+		{ID: 14, ArticleID: 3, Type: content.ChangeInsert},
+		{ID: 15, ArticleID: 3, Type: content.ChangeRead},
+		{ID: 16, ArticleID: 2, Type: content.ChangeUnfavorite},
+		{ID: 17, ArticleID: 4, Type: content.ChangeRead},
+	}
+
+	tests := []struct {
+		name        string
+		hasUser     bool
+		query       string
+		first, last content.ChangeID
+		rangeErr    error
+		changes     []content.ArticleChange
+		limit       int
+		changesErr  error
+		code        int
+		want        syncDelta
+	}{
+		{name: "no user", code: http.StatusBadRequest},
+		{name: "invalid cursor", hasUser: true, query: "since=abc", code: http.StatusBadRequest},
+		{name: "range error", hasUser: true, query: "since=10", rangeErr: errors.New("err"), code: http.StatusInternalServerError},
+		{name: "initial", hasUser: true, first: 5, last: 20, code: http.StatusOK, want: syncDelta{Cursor: 20, Reset: true}},
+		{name: "empty log", hasUser: true, query: "since=10", code: http.StatusOK, want: syncDelta{Reset: true}},
+		{name: "removed changes", hasUser: true, query: "since=3", first: 5, last: 20, code: http.StatusOK, want: syncDelta{Cursor: 20, Reset: true}},
+		{name: "unknown cursor", hasUser: true, query: "since=30", first: 5, last: 20, code: http.StatusOK, want: syncDelta{Cursor: 20, Reset: true}},
+		{name: "changes error", hasUser: true, query: "since=10", first: 5, last: 20, limit: maxSyncChanges, changesErr: errors.New("err"), code: http.StatusInternalServerError},
+		{name: "no changes", hasUser: true, query: "since=10", first: 5, last: 20, limit: maxSyncChanges, code: http.StatusOK, want: syncDelta{Cursor: 20}},
+		{name: "changes", hasUser: true, query: "since=10", first: 4, last: 20, limit: maxSyncChanges, changes: changes, code: http.StatusOK, want: syncDelta{
+			Cursor: 20, Inserted: []content.ArticleID{3}, Unread: []content.ArticleID{1},
+			Read: []content.ArticleID{4}, Unfavorite: []content.ArticleID{2},
+		}},
+		{name: "limited changes", hasUser: true, query: "since=10&limit=2", first: 4, last: 20, limit: 2, changes: changes[:2], code: http.StatusOK, want: syncDelta{
+			Cursor: 12, More: true, Read: []content.ArticleID{1}, Favorite: []content.ArticleID{2},
+		}},
+	}
+
+	type data struct {
+		Sync syncDelta `json:"sync"`
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			articleRepo := mock_repo.NewMockArticle(ctrl)
+
+			r := httptest.NewRequest("GET", "/?"+tt.query, nil)
+			r.ParseForm()
+			w := httptest.NewRecorder()
+
+			if tt.hasUser {
+				u := content.User{Login: "test"}
+				r = r.WithContext(context.WithValue(r.Context(), userKey, u))
+
+				if tt.code != http.StatusBadRequest {
+					articleRepo.EXPECT().ChangeRange().Return(tt.first, tt.last, tt.rangeErr)
+				}
+
+				if tt.limit > 0 {
+					articleRepo.EXPECT().Changes(userMatcher{u}, gomock.Any(), tt.limit).Return(tt.changes, tt.changesErr)
+				}
+			}
+
+			getSync(articleRepo, logger).ServeHTTP(w, r)
+
+			if w.Code != tt.code {
+				t.Errorf("getSync() code = %v, want %v", w.Code, tt.code)
+				return
+			}
+
+			if w.Code != http.StatusOK {
+				return
+			}
+
+			var got data
+			if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+				t.Errorf("getSync() body = '%s', error = %v", w.Body, err)
+				return
+			}
+
+			want := tt.want
+			for _, ids := range []*[]content.ArticleID{&want.Inserted, &want.Read, &want.Unread, &want.Favorite, &want.Unfavorite} {
+				if *ids == nil {
+					*ids = []content.ArticleID{}
+				}
+			}
+
+			if !reflect.DeepEqual(got.Sync, want) {
+				t.Errorf("getSync() got = %+v, want = %+v", got.Sync, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,337 @@
+package api
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi"
+	"github.com/urandom/readeef/content"
+	"github.com/urandom/readeef/content/processor"
+	"github.com/urandom/readeef/content/repo"
+	"github.com/urandom/readeef/log"
+)
+
+const jsonFeedVersion = "https://jsonfeed.org/version/1.1"
+
+type jsonFeed struct {
+	Version string         `json:"version"`
+	Title   string         `json:"title"`
+	FeedURL string         `json:"feed_url"`
+	Items   []jsonFeedItem `json:"items"`
+}
+
+type jsonFeedItem struct {
+	ID            string           `json:"id"`
+	URL           string           `json:"url,omitempty"`
+	Title         string           `json:"title,omitempty"`
+	ContentHTML   string           `json:"content_html"`
+	Image         string           `json:"image,omitempty"`
+	DatePublished string           `json:"date_published,omitempty"`
+	Authors       []jsonFeedAuthor `json:"authors,omitempty"`
+	Tags          []string         `json:"tags,omitempty"`
+}
+
+type jsonFeedAuthor struct {
+	Name string `json:"name"`
+}
+
+// syndicatedArticles serves the articles of the given repository type as an
+// Atom, RSS or JSON feed. When a search provider is given, the articles are
+// instead searched for, using the query form value.
+func syndicatedArticles(
+	service repo.Service,
+	searchProvider searcher,
+	repoType articleRepoType,
+	subType articleRepoType,
+	processors []processor.Article,
+	articlesLimit int,
+	log log.Log,
+) http.HandlerFunc {
+	repo := service.ArticleRepo()
+	tagRepo := service.TagRepo()
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		user, stop := userFromRequest(w, r)
+		if stop {
+			return
+		}
+
+		query := r.Form.Get("query")
+		if searchProvider != nil && query == "" {
+			http.Error(w, "No query provided", http.StatusBadRequest)
+			return
+		}
+
+		o, stop := articleQueryOptions(w, r, articlesLimit)
+		if stop {
+			return
+		}
+
+		o = append(o, content.Filters(content.GetUserFilters(user)), content.IncludeCategories)
+
+		view, stop := articleViewOptions(w, r, user, repoType, subType, tagRepo, log)
+		if stop {
+			return
+		}
+
+		o = append(o, view...)
+
+		var articles []content.Article
+		var err error
+		if searchProvider == nil {
+			articles, err = repo.ForUser(user, o...)
+		} else {
+			articles, err = searchProvider.Search(query, user, o...)
+		}
+
+		if err != nil {
+			fatal(w, log, "Error getting articles: %+v", err)
+			return
+		}
+
+		articles = processor.Articles(processors).Process(articles)
+
+		title := syndicationTitle(r, repoType, query)
+		self := requestURL(r)
+
+		var feed interface{}
+		switch chi.URLParam(r, "format") {
+		case "rss":
+			feed = articlesRSS(title, articles, self)
+		case "json":
+			feed = articlesJSON(title, articles, self)
+		default:
+			feed = articlesAtom(title, user, articles, self)
+		}
+
+		writeFeed(w, feed, log)
+	}
+}
+
+// getSyndicationToken returns the token that grants access to the
+// syndicated article feeds of the user.
+func getSyndicationToken(secret []byte) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user, stop := userFromRequest(w, r)
+		if stop {
+			return
+		}
+
+		args{"token": syndicationToken(user, secret)}.WriteJSON(w)
+	}
+}
+
+// syndicationContext places the user, given by the login in the URL, in the
+// request context, if the token in the URL belongs to them. Since the
+// syndicated feeds are outside of the authenticated routes, it also parses
+// the form.
+func syndicationContext(repo repo.User, secret []byte, log log.Log) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			user, err := repo.Get(content.Login(chi.URLParam(r, "login")))
+			if err != nil && !content.IsNoContent(err) {
+				fatal(w, log, "Error getting user: %+v", err)
+				return
+			}
+
+			token := chi.URLParam(r, "token")
+			if err != nil || !user.Active || !hmac.Equal([]byte(token), []byte(syndicationToken(user, secret))) {
+				http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+				return
+			}
+
+			if err := r.ParseForm(); err != nil {
+				http.Error(w, "Error parsing form data", http.StatusBadRequest)
+				return
+			}
+
+			ctx := context.WithValue(r.Context(), userKey, user)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+func articlesAtom(title string, user content.User, articles []content.Article, self string) atomFeed {
+	feed := atomFeed{
+		Title:   title,
+		ID:      self,
+		Updated: articlesUpdated(articles).Format(time.RFC3339),
+		Link:    atomLink{Rel: "self", Href: self},
+		Author:  atomAuthor{Name: publishedFeedAuthor(user)},
+	}
+
+	for _, a := range articles {
+		date := a.Date.UTC().Format(time.RFC3339)
+		entry := atomEntry{
+			Title:     a.Title,
+			ID:        articleGUID(a),
+			Link:      atomLink{Href: a.Link},
+			Published: date,
+			Updated:   date,
+			Content:   atomText{Type: "html", Body: a.Description},
+		}
+
+		if a.Author != "" {
+			entry.Author = &atomAuthor{Name: a.Author}
+		}
+
+		for _, c := range a.Categories {
+			entry.Categories = append(entry.Categories, atomCategory{Term: c})
+		}
+
+		feed.Entries = append(feed.Entries, entry)
+	}
+
+	return feed
+}
+
+func articlesRSS(title string, articles []content.Article, self string) rssFeed {
+	feed := rssFeed{Version: "2.0", Channel: rssChannel{
+		Title:       title,
+		Link:        self,
+		Description: title,
+	}}
+
+	if len(articles) > 0 {
+		feed.Channel.LastBuildDate = articlesUpdated(articles).Format(time.RFC1123Z)
+	}
+
+	for _, a := range articles {
+		feed.Channel.Items = append(feed.Channel.Items, rssItem{
+			Title:       a.Title,
+			Link:        a.Link,
+			Description: a.Description,
+			GUID:        rssGUID{Value: articleGUID(a)},
+			PubDate:     a.Date.UTC().Format(time.RFC1123Z),
+			Categories:  a.Categories,
+		})
+	}
+
+	return feed
+}
+
+func articlesJSON(title string, articles []content.Article, self string) jsonFeed {
+	feed := jsonFeed{
+		Version: jsonFeedVersion,
+		Title:   title,
+		FeedURL: self,
+		Items:   []jsonFeedItem{},
+	}
+
+	for _, a := range articles {
+		item := jsonFeedItem{
+			ID:            articleGUID(a),
+			URL:           a.Link,
+			Title:         a.Title,
+			ContentHTML:   a.Description,
+			Image:         a.ThumbnailLink,
+			DatePublished: a.Date.UTC().Format(time.RFC3339),
+			Tags:          a.Categories,
+		}
+
+		if a.Author != "" {
+			item.Authors = []jsonFeedAuthor{{Name: a.Author}}
+		}
+
+		feed.Items = append(feed.Items, item)
+	}
+
+	return feed
+}
+
+// syndicationTitle describes the articles of the given repository type,
+// using the feed, tag or label from the request context.
+func syndicationTitle(r *http.Request, repoType articleRepoType, query string) string {
+	var title string
+	switch {
+	case query != "":
+		title = fmt.Sprintf("Search results for '%s'", query)
+	case repoType == favoriteRepoType:
+		title = "Favorite articles"
+	case repoType == popularRepoType:
+		title = "Popular articles"
+	default:
+		title = "Articles"
+	}
+
+	if feed, ok := r.Context().Value(feedKey).(content.Feed); ok {
+		title += " from " + feed.Title
+	} else if tag, ok := r.Context().Value(tagKey).(content.Tag); ok {
+		title += " tagged " + string(tag.Value)
+	} else if label, ok := r.Context().Value(labelKey).(content.Label); ok {
+		title += " labelled " + label.Name
+	}
+
+	return title
+}
+
+// emptyFeedUpdated is the update date of feeds without entries. It is fixed,
+// so that the content of a feed only changes along with its entries, which
+// the hub relies on to detect changes.
+var emptyFeedUpdated = time.Unix(0, 0).UTC()
+
+// articlesUpdated returns the date of the newest article, since the articles
+// aren't necessarily sorted by date.
+func articlesUpdated(articles []content.Article) time.Time {
+	var updated time.Time
+	for _, a := range articles {
+		if a.Date.After(updated) {
+			updated = a.Date
+		}
+	}
+
+	if updated.IsZero() {
+		updated = emptyFeedUpdated
+	}
+
+	return updated.UTC()
+}
+
+func articleGUID(a content.Article) string {
+	return fmt.Sprintf("urn:readeef:article:%d", a.ID)
+}
+
+// writeFeed encodes the Atom, RSS or JSON feed, along with its content type.
+func writeFeed(w http.ResponseWriter, feed interface{}, log log.Log) {
+	var b []byte
+	var err error
+	switch feed.(type) {
+	case jsonFeed:
+		w.Header().Set("Content-Type", "application/feed+json; charset=utf-8")
+		b, err = json.MarshalIndent(feed, "", "    ")
+	case rssFeed:
+		w.Header().Set("Content-Type", "application/rss+xml; charset=utf-8")
+		b, err = xml.MarshalIndent(feed, "", "    ")
+	default:
+		w.Header().Set("Content-Type", "application/atom+xml; charset=utf-8")
+		b, err = xml.MarshalIndent(feed, "", "    ")
+	}
+
+	if err != nil {
+		fatal(w, log, "Error encoding feed: %+v", err)
+		return
+	}
+
+	if _, ok := feed.(jsonFeed); !ok {
+		w.Write([]byte(xml.Header))
+	}
+	w.Write(b)
+}
+
+// syndicationToken signs the user's login and password hash, salted with a
+// purpose string different from the one of the published feed token.
+func syndicationToken(user content.User, secret []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte("syndication-feed:"))
+	mac.Write([]byte(user.Login))
+	mac.Write(user.Hash)
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
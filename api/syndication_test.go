@@ -0,0 +1,200 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/pkg/errors"
+	"github.com/urandom/readeef/content"
+	"github.com/urandom/readeef/content/repo/mock_repo"
+)
+
+func Test_syndicatedArticles(t *testing.T) {
+	date := time.Date(2017, 10, 1, 12, 0, 0, 0, time.UTC)
+	articles := []content.Article{
+		{ID: 2, Title: "Go 1.10", Link: "https://golang.org/1.10", Description: "<p>New</p>", Date: date, Author: "Gopher", Categories: []string{"go"}, ThumbnailLink: "https://golang.org/gopher.png"},
+		{ID: 1, Title: "Go 1.9", Link: "https://golang.org/1.9", Date: date.AddDate(0, -1, 0)},
+	}
+
+	tests := []struct {
+		name        string
+		noUser      bool
+		url         string
+		format      string
+		search      bool
+		repoType    articleRepoType
+		opts        content.QueryOptions
+		articlesErr error
+		code        int
+		contains    []string
+	}{
+		{name: "no user", url: "/", noUser: true, code: http.StatusBadRequest},
+		{name: "no query", url: "/", search: true, repoType: userRepoType, code: http.StatusBadRequest},
+		{name: "invalid limit opt", url: "/?limit=no", repoType: userRepoType, code: http.StatusBadRequest},
+		{name: "articles err", url: "/", repoType: userRepoType, articlesErr: errors.New("err"), code: http.StatusInternalServerError, opts: content.QueryOptions{Limit: 50, SortField: content.SortByDate, SortOrder: content.DescendingOrder, IncludeCategories: true}},
+		{name: "favorite atom", url: "/", format: "atom", repoType: favoriteRepoType, code: http.StatusOK, opts: content.QueryOptions{Limit: 50, FavoriteOnly: true, SortField: content.SortByDate, SortOrder: content.DescendingOrder, IncludeCategories: true}, contains: []string{
+			`<feed xmlns="http://www.w3.org/2005/Atom">`,
+			`<title>Favorite articles</title>`,
+			`<updated>2017-10-01T12:00:00Z</updated>`,
+			`<id>urn:readeef:article:2</id>`,
+			`<author>`,
+			`<name>Gopher</name>`,
+			`<category term="go"></category>`,
+			`<content type="html">&lt;p&gt;New&lt;/p&gt;</content>`,
+		}},
+		{name: "tag rss", url: "/?unreadOnly", format: "rss", repoType: tagRepoType, code: http.StatusOK, opts: content.QueryOptions{Limit: 50, UnreadOnly: true, FeedIDs: []content.FeedID{1, 2}, SortField: content.SortByDate, SortOrder: content.DescendingOrder, IncludeCategories: true}, contains: []string{
+			`<rss version="2.0">`,
+			`<title>Articles tagged golang</title>`,
+			`<guid isPermaLink="false">urn:readeef:article:1</guid>`,
+			`<category>go</category>`,
+		}},
+		{name: "search json", url: "/?query=release&limit=10", format: "json", search: true, repoType: userRepoType, code: http.StatusOK, opts: content.QueryOptions{Limit: 10, SortField: content.SortByDate, SortOrder: content.DescendingOrder, IncludeCategories: true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			service := mock_repo.NewMockService(ctrl)
+			articleRepo := mock_repo.NewMockArticle(ctrl)
+			tagRepo := mock_repo.NewMockTag(ctrl)
+			searchProvider := NewMocksearcher(ctrl)
+
+			r := httptest.NewRequest("GET", tt.url, nil)
+			r.ParseForm()
+			r = addChiParam(r, "format", tt.format)
+			w := httptest.NewRecorder()
+
+			service.EXPECT().ArticleRepo().Return(articleRepo)
+			service.EXPECT().TagRepo().Return(tagRepo)
+
+			user := content.User{Login: "test"}
+			if !tt.noUser {
+				r = r.WithContext(context.WithValue(r.Context(), userKey, user))
+			}
+
+			if tt.repoType == tagRepoType {
+				tag := content.Tag{ID: 1, Value: "golang"}
+				r = r.WithContext(context.WithValue(r.Context(), tagKey, tag))
+				tagRepo.EXPECT().FeedIDs(tag, userMatcher{user}).Return([]content.FeedID{1, 2}, nil)
+			}
+
+			checkOpts := func(opts []content.QueryOpt) {
+				o := content.QueryOptions{}
+				o.Apply(opts)
+
+				if !reflect.DeepEqual(o, tt.opts) {
+					t.Errorf("syndicatedArticles() options = %#v, want %#v", o, tt.opts)
+				}
+			}
+
+			var s searcher
+			if tt.search {
+				s = searchProvider
+				if tt.code != http.StatusBadRequest {
+					searchProvider.EXPECT().Search(r.Form.Get("query"), userMatcher{user}, gomock.Any()).DoAndReturn(func(query string, user content.User, opts ...content.QueryOpt) ([]content.Article, error) {
+						checkOpts(opts)
+						return articles, tt.articlesErr
+					})
+				}
+			} else if tt.code != http.StatusBadRequest {
+				articleRepo.EXPECT().ForUser(userMatcher{user}, gomock.Any()).DoAndReturn(func(user content.User, opts ...content.QueryOpt) ([]content.Article, error) {
+					checkOpts(opts)
+					return articles, tt.articlesErr
+				})
+			}
+
+			syndicatedArticles(service, s, tt.repoType, noRepoType, nil, 50, logger).ServeHTTP(w, r)
+
+			if w.Code != tt.code {
+				t.Errorf("syndicatedArticles() code = %v, want %v", w.Code, tt.code)
+				return
+			}
+
+			for _, s := range tt.contains {
+				if !strings.Contains(w.Body.String(), s) {
+					t.Errorf("syndicatedArticles() body = %s, missing %s", w.Body, s)
+				}
+			}
+
+			if tt.format != "json" {
+				return
+			}
+
+			var got jsonFeed
+			if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+				t.Errorf("syndicatedArticles() body = '%s', error = %v", w.Body, err)
+				return
+			}
+
+			want := jsonFeed{Version: jsonFeedVersion, Title: "Search results for 'release'", FeedURL: "http://example.com/?query=release&limit=10", Items: []jsonFeedItem{
+				{ID: "urn:readeef:article:2", URL: "https://golang.org/1.10", Title: "Go 1.10", ContentHTML: "<p>New</p>", Image: "https://golang.org/gopher.png", DatePublished: "2017-10-01T12:00:00Z", Authors: []jsonFeedAuthor{{Name: "Gopher"}}, Tags: []string{"go"}},
+				{ID: "urn:readeef:article:1", URL: "https://golang.org/1.9", Title: "Go 1.9", DatePublished: "2017-09-01T12:00:00Z"},
+			}}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("syndicatedArticles() got = %#v, want = %#v", got, want)
+			}
+		})
+	}
+}
+
+func Test_syndicationContext(t *testing.T) {
+	secret := []byte("secret")
+	user := content.User{Login: "test", Active: true, Hash: []byte("hash")}
+	token := syndicationToken(user, secret)
+
+	tests := []struct {
+		name     string
+		login    string
+		token    string
+		inactive bool
+		code     int
+	}{
+		{name: "unknown user", login: "other", token: token, code: http.StatusNotFound},
+		{name: "invalid token", login: "test", token: "abcd", code: http.StatusNotFound},
+		{name: "published feed token", login: "test", token: publishedFeedToken(user, secret), code: http.StatusNotFound},
+		{name: "inactive", login: "test", token: token, inactive: true, code: http.StatusNotFound},
+		{name: "valid", login: "test", token: token, code: http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			userRepo := mock_repo.NewMockUser(ctrl)
+
+			r := httptest.NewRequest("GET", "/?unreadOnly", nil)
+			r = addChiParam(r, "login", tt.login, "token", tt.token)
+			w := httptest.NewRecorder()
+
+			if tt.login == "test" {
+				u := user
+				u.Active = !tt.inactive
+				userRepo.EXPECT().Get(content.Login("test")).Return(u, nil)
+			} else {
+				userRepo.EXPECT().Get(content.Login(tt.login)).Return(content.User{}, content.ErrNoContent)
+			}
+
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if u, stop := userFromRequest(w, r); stop || u.Login != user.Login {
+					t.Errorf("syndicationContext() user = %v, want %v", u, user)
+				}
+
+				if _, ok := r.Form["unreadOnly"]; !ok {
+					t.Errorf("syndicationContext() form = %v", r.Form)
+				}
+			})
+
+			syndicationContext(userRepo, secret, logger)(next).ServeHTTP(w, r)
+
+			if w.Code != tt.code {
+				t.Errorf("syndicationContext() code = %v, want %v", w.Code, tt.code)
+			}
+		})
+	}
+}
@@ -18,6 +18,7 @@ type headline struct {
 	Id        content.ArticleID `json:"id"`
 	Unread    bool              `json:"unread"`
 	Marked    bool              `json:"marked"`
+	Published bool              `json:"published"`
 	Updated   int64             `json:"updated"`
 	IsUpdated bool              `json:"is_updated"`
 	Title     string            `json:"title"`
@@ -27,9 +28,11 @@ type headline struct {
 	Excerpt   string            `json:"excerpt,omitempty"`
 	Content   string            `json:"content,omitempty"`
 	FeedTitle string            `json:"feed_title"`
+	Note      string            `json:"note,omitempty"`
 
-	Tags   []string `json:"tags,omitempty"`
-	Labels []string `json:"labels,omitempty"`
+	Tags        []string        `json:"tags,omitempty"`
+	Labels      [][]interface{} `json:"labels,omitempty"`
+	Attachments []attachment    `json:"attachments,omitempty"`
 }
 
 type headlinesHeader struct {
@@ -46,13 +49,28 @@ type article struct {
 	Link      string `json:"link"`
 	Unread    bool   `json:"unread"`
 	Marked    bool   `json:"marked"`
+	Published bool   `json:"published"`
 	Author    string `json:"author"`
 	Updated   int64  `json:"updated"`
 	Content   string `json:"content,omitempty"`
 	FeedId    string `json:"feed_id"`
 	FeedTitle string `json:"feed_title"`
+	Note      string `json:"note,omitempty"`
 
-	Labels []string `json:"labels,omitempty"`
+	Tags        []string        `json:"tags,omitempty"`
+	Labels      [][]interface{} `json:"labels,omitempty"`
+	Attachments []attachment    `json:"attachments"`
+}
+
+type attachment struct {
+	Id          string `json:"id"`
+	ContentUrl  string `json:"content_url"`
+	ContentType string `json:"content_type"`
+	PostId      string `json:"post_id"`
+	Title       string `json:"title"`
+	Duration    string `json:"duration"`
+	Width       int    `json:"width"`
+	Height      int    `json:"height"`
 }
 
 func registerArticleActions(searchProvider search.Provider, processors []processor.Article) {
@@ -72,7 +90,7 @@ func getHeadlines(
 	searchProvider search.Provider,
 	processors []processor.Article,
 ) (interface{}, error) {
-	if req.FeedId == 0 {
+	if req.FeedId == 0 && !req.IsCat {
 		return nil, errors.WithStack(newErr("no feed id", "INCORRECT_USAGE"))
 	}
 
@@ -81,11 +99,15 @@ func getHeadlines(
 		limit = 200
 	}
 
-	var feedTitle string
 	var firstID content.ArticleID
 	opts := []content.QueryOpt{
 		content.Paging(limit, req.Skip), content.UnreadFirst,
 		content.Filters(content.GetUserFilters(user)),
+		content.IncludeCategories, content.IncludeLabels, content.IncludeNotes,
+	}
+
+	if req.IncludeAttachments {
+		opts = append(opts, content.IncludeMedia)
 	}
 
 	switch req.OrderBy {
@@ -95,124 +117,222 @@ func getHeadlines(
 		opts = append(opts, content.Sorting(content.SortByDate, content.DescendingOrder))
 	}
 
-	var feedGenerator func() ([]content.Feed, error)
-
 	if req.SinceId > 0 {
-		opts = append(opts, content.IDRange(0, req.SinceId))
+		opts = append(opts, content.IDRange(req.SinceId, 0))
 	}
 
+	// The feed ids, if any, that the articles are limited to
+	var feedIDs []content.FeedID
+	var savedSearch *content.SavedSearch
+	var none bool
+	var err error
+
 	if req.IsCat {
-		if req.FeedId == CAT_UNCATEGORIZED {
+		switch tagID := content.TagID(req.FeedId); {
+		case tagID == CAT_UNCATEGORIZED:
 			opts = append(opts, content.UntaggedOnly)
+		case tagID == CAT_LABELS:
+			ids, err := userLabelIDs(service, user)
+			if err != nil {
+				return nil, err
+			}
 
-			feedTitle = "Uncategorized"
-		} else if req.FeedId > 0 {
-			tag, err := service.TagRepo().Get(content.TagID(req.FeedId), user)
+			opts = append(opts, content.LabelIDs(ids))
+			none = len(ids) == 0
+		case tagID > 0:
+			tag, err := service.TagRepo().Get(tagID, user)
 			if err != nil {
 				return nil, errors.WithMessage(err, "getting tag for user")
 			}
 
-			feedGenerator = func() ([]content.Feed, error) {
-				return service.FeedRepo().ForTag(tag, user)
+			if feedIDs, err = categoryFeedIDs(tag, req.IncludeNested, user, service); err != nil {
+				return nil, err
 			}
 
-			feedTitle = string(tag.Value)
+			none = len(feedIDs) == 0
 		}
 	} else {
-		if req.FeedId == FAVORITE_ID {
+		switch {
+		case req.FeedId == FAVORITE_ID:
 			opts = append(opts, content.FavoriteOnly)
-			feedTitle = "Starred articles"
-		} else if req.FeedId == FRESH_ID {
+		case req.FeedId == FRESH_ID:
 			opts = append(opts, content.TimeRange(time.Now().Add(FRESH_DURATION), time.Time{}))
-			feedTitle = "Fresh articles"
-		} else if req.FeedId == ALL_ID {
-			feedTitle = "All articles"
-		} else if req.FeedId > 0 {
-			feed, err := service.FeedRepo().Get(req.FeedId, user)
+		case req.FeedId == ALL_ID:
+		case req.FeedId == RECENTLY_READ_ID:
+			opts = append(opts, content.ReadOnly, content.ChangedSince(time.Now().Add(FRESH_DURATION)))
+		case req.FeedId == PUBLISHED_ID:
+			opts = append(opts, content.PublishedOnly)
+		case isLabelFeed(req.FeedId):
+			label, err := service.LabelRepo().Get(feedLabelID(req.FeedId), user)
 			if err != nil {
-				return nil, errors.WithMessage(err, "getting user feed")
+				return nil, errors.WithMessage(err, "getting user label")
 			}
 
-			feedGenerator = func() ([]content.Feed, error) {
-				return []content.Feed{feed}, nil
+			opts = append(opts, content.LabelIDs([]content.LabelID{label.ID}))
+		case isSavedSearchFeed(req.FeedId):
+			s, err := feedSavedSearch(req.FeedId, user, service, searchProvider)
+			if err != nil {
+				return nil, err
 			}
 
-			feedTitle = feed.Title
-		}
-	}
+			savedSearch = &s
+		case req.FeedId > 0:
+			feed, err := service.FeedRepo().Get(req.FeedId, user)
+			if err != nil {
+				return nil, errors.WithMessage(err, "getting user feed")
+			}
 
-	if feedGenerator != nil {
-		feeds, err := feedGenerator()
-		if err != nil {
-			return nil, errors.WithMessage(err, "getting feeds")
+			feedIDs = []content.FeedID{feed.ID}
 		}
+	}
 
-		ids := make([]content.FeedID, len(feeds))
-		for i := range feeds {
-			ids[i] = feeds[i].ID
+	if req.Search != "" {
+		switch req.SearchMode {
+		case "all_feeds":
+			feedIDs = nil
+		case "this_cat":
+			if !req.IsCat && req.FeedId > 0 {
+				if feedIDs, err = feedCategoryFeedIDs(req.FeedId, user, service); err != nil {
+					return nil, err
+				}
+			}
 		}
+	}
 
-		opts = append(opts, content.FeedIDs(ids))
+	if len(feedIDs) > 0 {
+		opts = append(opts, content.FeedIDs(feedIDs))
 	}
 
-	var articleGenerator func() ([]content.Article, error)
-	if req.Search != "" {
-		if searchProvider != nil {
-			articleGenerator = func() ([]content.Article, error) {
-				return searchProvider.Search(req.Search, user, opts...)
+	switch req.ViewMode {
+	case "unread":
+		opts = append(opts, content.UnreadOnly)
+	case "marked":
+		opts = append(opts, content.FavoriteOnly)
+	case "published":
+		opts = append(opts, content.PublishedOnly)
+	case "adaptive":
+		// Only the unread articles are shown, unless there are none
+		if req.Search == "" && !none {
+			var count int64
+			if savedSearch != nil {
+				count, err = search.CountSavedSearch(searchProvider, service, *savedSearch, user, append(opts, content.UnreadOnly)...)
+			} else {
+				count, err = service.ArticleRepo().Count(user, append(opts, content.UnreadOnly)...)
+			}
+			if err != nil {
+				return nil, errors.WithMessage(err, "getting unread article count")
 			}
-		}
-	} else {
-		var skip bool
-
-		switch req.ViewMode {
-		case "all_articles":
-		case "adaptive":
-		case "unread":
-			opts = append(opts, content.UnreadOnly)
-		case "marked":
-			opts = append(opts, content.FavoriteOnly)
-		default:
-			skip = true
-		}
 
-		if !skip {
-			articleGenerator = func() ([]content.Article, error) {
-				return service.ArticleRepo().ForUser(user, opts...)
+			if count > 0 {
+				opts = append(opts, content.UnreadOnly)
 			}
 		}
 	}
 
-	if articleGenerator != nil {
-		articles, err := articleGenerator()
+	var articles []content.Article
+	if !none {
+		if req.Search != "" {
+			if searchProvider == nil {
+				return nil, errors.WithStack(newErr("no search provider", "INCORRECT_USAGE"))
+			}
+
+			articles, err = searchProvider.Search(req.Search, user, opts...)
+		} else if savedSearch != nil {
+			articles, err = search.SavedSearch(searchProvider, service, *savedSearch, user, opts...)
+		} else {
+			articles, err = service.ArticleRepo().ForUser(user, opts...)
+		}
+
 		if err != nil {
 			return nil, errors.WithMessage(err, "gettting articles")
 		}
+	}
 
-		if len(articles) > 0 {
-			articles = processor.Articles(processors).Process(articles)
+	labels, err := userLabels(service, user)
+	if err != nil {
+		return nil, err
+	}
 
-			firstID = articles[0].ID
-		}
+	feedTitles, err := userFeedTitles(service, user)
+	if err != nil {
+		return nil, err
+	}
 
-		headlines := headlinesFromArticles(articles, feedTitle, req.ShowContent, req.ShowExcerpt)
-		if req.IncludeHeader {
-			header := headlinesHeader{Id: req.FeedId, FirstId: firstID, IsCat: req.IsCat}
-			hContent := headlinesHeaderContent{}
+	if len(articles) > 0 {
+		articles = processor.Articles(processors).Process(articles)
+
+		firstID = articles[0].ID
+	}
 
-			hContent = append(hContent, header)
-			hContent = append(hContent, headlines)
+	headlines := headlinesFromArticles(articles, labels, feedTitles, req.ShowContent, req.ShowExcerpt, req.IncludeAttachments)
+	if req.IncludeHeader {
+		header := headlinesHeader{Id: req.FeedId, FirstId: firstID, IsCat: req.IsCat}
+		hContent := headlinesHeaderContent{}
+
+		hContent = append(hContent, header)
+		hContent = append(hContent, headlines)
+
+		return hContent, nil
+	}
+
+	return headlines, nil
+}
+
+// userFeedTitles returns the titles of the user's feeds, indexed by their id.
+func userFeedTitles(service repo.Service, user content.User) (map[content.FeedID]string, error) {
+	feeds, err := service.FeedRepo().ForUser(user)
+	if err != nil {
+		return nil, errors.WithMessage(err, "getting user feeds")
+	}
+
+	titles := make(map[content.FeedID]string, len(feeds))
+	for _, f := range feeds {
+		titles[f.ID] = f.Title
+	}
+
+	return titles, nil
+}
+
+// feedCategoryFeedIDs returns the ids of the feeds sharing a category with
+// the given one, or just its id if it is uncategorized.
+func feedCategoryFeedIDs(id content.FeedID, user content.User, service repo.Service) ([]content.FeedID, error) {
+	feed, err := service.FeedRepo().Get(id, user)
+	if err != nil {
+		return nil, errors.WithMessage(err, "getting user feed")
+	}
+
+	tags, err := service.TagRepo().ForFeed(feed, user)
+	if err != nil {
+		return nil, errors.WithMessage(err, "getting feed tags")
+	}
 
-			return hContent, nil
+	ids := []content.FeedID{feed.ID}
+	seen := map[content.FeedID]bool{feed.ID: true}
+	for _, t := range tags {
+		tagged, err := categoryFeedIDs(t, false, user, service)
+		if err != nil {
+			return nil, err
 		}
 
-		return headlines, nil
+		for _, id := range tagged {
+			if !seen[id] {
+				seen[id] = true
+				ids = append(ids, id)
+			}
+		}
 	}
 
-	return nil, nil
+	return ids, nil
 }
 
 func updateArticle(req request, user content.User, service repo.Service) (interface{}, error) {
+	switch req.Field {
+	case 1:
+		return updateArticlePublished(req, user, service)
+	case 3:
+		return updateArticleNote(req, user, service)
+	}
+
 	if req.Field != 0 && req.Field != 2 {
 		return nil, errors.Errorf("Unknown field %d", req.Field)
 	}
@@ -314,20 +434,88 @@ func updateArticle(req request, user content.User, service repo.Service) (interf
 	return genericContent{Status: "OK", Updated: int64(updateCount)}, nil
 }
 
+// updateArticlePublished publishes or unpublishes the articles, depending
+// on the mode.
+func updateArticlePublished(req request, user content.User, service repo.Service) (interface{}, error) {
+	articles, err := service.ArticleRepo().ForUser(user,
+		content.IDs(req.ArticleIds),
+		content.Filters(content.GetUserFilters(user)),
+	)
+	if err != nil {
+		return nil, errors.WithMessage(err, "getting user articles")
+	}
+
+	var publish, unpublish []content.ArticleID
+	for _, a := range articles {
+		switch {
+		case req.Mode == 0 && a.Published, req.Mode == 2 && a.Published:
+			unpublish = append(unpublish, a.ID)
+		case req.Mode == 1 && !a.Published, req.Mode == 2 && !a.Published:
+			publish = append(publish, a.ID)
+		}
+	}
+
+	if len(publish) > 0 {
+		if err := service.PublicationRepo().Publish(user, publish); err != nil {
+			return nil, errors.WithMessage(err, "publishing articles")
+		}
+	}
+
+	if len(unpublish) > 0 {
+		if err := service.PublicationRepo().Unpublish(user, unpublish); err != nil {
+			return nil, errors.WithMessage(err, "unpublishing articles")
+		}
+	}
+
+	return genericContent{Status: "OK", Updated: int64(len(publish) + len(unpublish))}, nil
+}
+
+// updateArticleNote sets the data as the note of the articles.
+func updateArticleNote(req request, user content.User, service repo.Service) (interface{}, error) {
+	ids, err := service.ArticleRepo().IDs(user,
+		content.IDs(req.ArticleIds),
+		content.Filters(content.GetUserFilters(user)),
+	)
+	if err != nil {
+		return nil, errors.WithMessage(err, "getting user article ids")
+	}
+
+	for _, id := range ids {
+		if err := service.ArticleRepo().SetNote(user, id, req.Data); err != nil {
+			return nil, errors.WithMessage(err, "setting article note")
+		}
+	}
+
+	return genericContent{Status: "OK", Updated: int64(len(ids))}, nil
+}
+
 func getArticle(
 	req request,
 	user content.User,
 	service repo.Service,
 	processors []processor.Article,
 ) (interface{}, error) {
+	if len(req.ArticleId) == 0 {
+		return nil, errors.WithStack(newErr("no article ids", "INCORRECT_USAGE"))
+	}
+
 	articles, err := service.ArticleRepo().ForUser(user,
-		content.IDs(req.ArticleIds),
+		content.IDs(req.ArticleId),
 		content.Filters(content.GetUserFilters(user)),
+		content.IncludeMedia,
+		content.IncludeCategories,
+		content.IncludeLabels,
+		content.IncludeNotes,
 	)
 	if err != nil {
 		return nil, errors.Wrap(err, "getting user articles")
 	}
 
+	labels, err := userLabels(service, user)
+	if err != nil {
+		return nil, err
+	}
+
 	feedTitles := map[content.FeedID]string{}
 
 	for _, a := range articles {
@@ -346,15 +534,21 @@ func getArticle(
 	for _, a := range articles {
 		title := feedTitles[a.FeedID]
 		h := article{
-			Id:        strconv.FormatInt(int64(a.ID), 10),
-			Unread:    !a.Read,
-			Marked:    a.Favorite,
-			Updated:   a.Date.Unix(),
-			Title:     a.Title,
-			Link:      a.Link,
-			FeedId:    strconv.FormatInt(int64(a.FeedID), 10),
-			FeedTitle: title,
-			Content:   a.Description,
+			Id:          strconv.FormatInt(int64(a.ID), 10),
+			Unread:      !a.Read,
+			Marked:      a.Favorite,
+			Published:   a.Published,
+			Author:      a.Author,
+			Updated:     a.Date.Unix(),
+			Title:       a.Title,
+			Link:        a.Link,
+			FeedId:      strconv.FormatInt(int64(a.FeedID), 10),
+			FeedTitle:   title,
+			Content:     a.Description,
+			Note:        a.Note,
+			Tags:        a.Categories,
+			Labels:      articleLabels(a, labels),
+			Attachments: attachmentsFromMedia(a.Media),
 		}
 
 		cContent = append(cContent, h)
@@ -363,20 +557,30 @@ func getArticle(
 	return cContent, nil
 }
 
-func headlinesFromArticles(articles []content.Article, feedTitle string, content, excerpt bool) headlinesContent {
+func headlinesFromArticles(
+	articles []content.Article,
+	labels map[content.LabelID]content.Label,
+	feedTitles map[content.FeedID]string,
+	content, excerpt, attachments bool,
+) headlinesContent {
 	c := headlinesContent{}
 	for _, a := range articles {
-		title := feedTitle
+		title := feedTitles[a.FeedID]
 		h := headline{
 			Id:        a.ID,
 			Unread:    !a.Read,
 			Marked:    a.Favorite,
+			Published: a.Published,
 			Updated:   a.Date.Unix(),
 			IsUpdated: !a.Read,
 			Title:     a.Title,
 			Link:      a.Link,
 			FeedId:    strconv.FormatInt(int64(a.FeedID), 10),
+			Author:    a.Author,
 			FeedTitle: title,
+			Note:      a.Note,
+			Tags:      a.Categories,
+			Labels:    articleLabels(a, labels),
 		}
 
 		if content {
@@ -392,8 +596,34 @@ func headlinesFromArticles(articles []content.Article, feedTitle string, content
 			h.Excerpt = excerpt
 		}
 
+		if attachments {
+			h.Attachments = attachmentsFromMedia(a.Media)
+		}
+
 		c = append(c, h)
 	}
 
 	return c
 }
+
+func attachmentsFromMedia(media []content.Media) []attachment {
+	attachments := make([]attachment, len(media))
+
+	for i, m := range media {
+		attachments[i] = attachment{
+			Id:          strconv.FormatInt(m.ID, 10),
+			ContentUrl:  m.Link,
+			ContentType: m.Type,
+			PostId:      strconv.FormatInt(int64(m.ArticleID), 10),
+			Title:       m.Title,
+			Width:       m.Width,
+			Height:      m.Height,
+		}
+
+		if m.Duration > 0 {
+			attachments[i].Duration = strconv.FormatInt(m.Duration, 10)
+		}
+	}
+
+	return attachments
+}
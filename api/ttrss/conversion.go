@@ -28,22 +28,36 @@ func convertRequest(in map[string]interface{}) request {
 			req.OrderBy = parseString(v)
 		case "search":
 			req.Search = parseString(v)
+		case "search_mode":
+			req.SearchMode = parseString(v)
 		case "data":
 			req.Data = parseString(v)
 		case "pref_name":
 			req.PrefName = parseString(v)
 		case "feed_url":
 			req.FeedUrl = parseString(v)
+		case "title":
+			req.Title = parseString(v)
+		case "url":
+			req.Url = parseString(v)
+		case "content":
+			req.Content = parseString(v)
 		case "unread_only":
 			req.UnreadOnly = parseBool(v)
 		case "include_empty":
 			req.IncludeEmpty = parseBool(v)
+		case "include_nested":
+			req.IncludeNested = parseBool(v)
+		case "assign":
+			req.Assign = parseBool(v)
 		case "is_cat":
 			req.IsCat = parseBool(v)
 		case "show_content":
 			req.ShowContent = parseBool(v)
 		case "show_excerpt":
 			req.ShowExcerpt = parseBool(v)
+		case "include_attachments":
+			req.IncludeAttachments = parseBool(v)
 		case "sanitize":
 			req.Sanitize = parseBool(v)
 		case "has_sandbox":
@@ -59,13 +73,19 @@ func convertRequest(in map[string]interface{}) request {
 		case "skip":
 			req.Skip = parseInt(v)
 		case "mode":
+			// catchupFeed uses named modes, while updateArticle uses numeric ones
 			req.Mode = parseInt(v)
+			req.CatchupMode = parseString(v)
 		case "field":
 			req.Field = parseInt(v)
 		case "cat_id":
 			req.CatId = content.TagID(parseInt64(v))
+		case "category_id":
+			req.CategoryId = content.TagID(parseInt64(v))
 		case "feed_id":
 			req.FeedId = content.FeedID(parseInt64(v))
+		case "label_id":
+			req.LabelId = content.FeedID(parseInt64(v))
 		case "since_id":
 			req.SinceId = content.ArticleID(parseInt64(v))
 		case "article_ids":
@@ -2,11 +2,13 @@ package ttrss
 
 import (
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/pkg/errors"
 	"github.com/urandom/readeef/content"
 	"github.com/urandom/readeef/content/repo"
+	"github.com/urandom/readeef/content/search"
 )
 
 type countersContent []counter
@@ -15,10 +17,25 @@ type counter struct {
 	Id         interface{} `json:"id"`
 	Counter    int64       `json:"counter"`
 	AuxCounter int64       `json:"auxcounter,omitempty"`
+	HasImg     int         `json:"has_img,omitempty"`
 	Kind       string      `json:"kind,omitempty"`
 }
 
-func getUnread(req request, user content.User, service repo.Service) (interface{}, error) {
+func registerCounterActions(searchProvider search.Provider) {
+	actions["getUnread"] = func(req request, user content.User, service repo.Service) (interface{}, error) {
+		return getUnread(req, user, service, searchProvider)
+	}
+	actions["getCounters"] = func(req request, user content.User, service repo.Service) (interface{}, error) {
+		return getCounters(req, user, service, searchProvider)
+	}
+}
+
+func getUnread(
+	req request,
+	user content.User,
+	service repo.Service,
+	searchProvider search.Provider,
+) (interface{}, error) {
 	opts := []content.QueryOpt{
 		content.Filters(content.GetUserFilters(user)),
 	}
@@ -46,8 +63,29 @@ func getUnread(req request, user content.User, service repo.Service) (interface{
 			opts = append(opts, content.FavoriteOnly)
 		case FRESH_ID:
 			opts = append(opts, content.TimeRange(time.Now().Add(FRESH_DURATION), time.Time{}))
+		case PUBLISHED_ID:
+			opts = append(opts, content.PublishedOnly)
 		default:
-			if req.FeedId > 0 {
+			if isLabelFeed(req.FeedId) {
+				label, err := service.LabelRepo().Get(feedLabelID(req.FeedId), user)
+				if err != nil {
+					return nil, errors.WithMessage(err, "getting user label")
+				}
+
+				opts = append(opts, content.LabelIDs([]content.LabelID{label.ID}))
+			} else if isSavedSearchFeed(req.FeedId) {
+				s, err := feedSavedSearch(req.FeedId, user, service, searchProvider)
+				if err != nil {
+					return nil, err
+				}
+
+				count, err := savedSearchUnread(s, user, service, searchProvider)
+				if err != nil {
+					return nil, err
+				}
+
+				return genericContent{Unread: strconv.FormatInt(count, 10)}, nil
+			} else if req.FeedId > 0 {
 				feed, err := service.FeedRepo().Get(req.FeedId, user)
 				if err != nil {
 					return nil, errors.WithMessage(err, "getting user feed")
@@ -83,16 +121,24 @@ func getUnread(req request, user content.User, service repo.Service) (interface{
 	return genericContent{Unread: strconv.FormatInt(count, 10)}, nil
 }
 
-func getCounters(req request, user content.User, service repo.Service) (interface{}, error) {
+// getCounters returns the global and virtual feed counters, followed by
+// the label (l), feed (f) and category (c) counters, as selected by the
+// output mode.
+func getCounters(
+	req request,
+	user content.User,
+	service repo.Service,
+	searchProvider search.Provider,
+) (interface{}, error) {
 	if req.OutputMode == "" {
 		req.OutputMode = "flc"
 	}
 	cContent := countersContent{}
 
 	articleRepo := service.ArticleRepo()
-	unreadCount, err := articleRepo.Count(user, content.UnreadOnly,
-		content.Filters(content.GetUserFilters(user)),
-	)
+	filters := content.Filters(content.GetUserFilters(user))
+
+	unreadCount, err := articleRepo.Count(user, content.UnreadOnly, filters)
 	if err != nil {
 		return nil, errors.WithMessage(err, "getting user unread count")
 	}
@@ -108,16 +154,12 @@ func getCounters(req request, user content.User, service repo.Service) (interfac
 
 	cContent = append(cContent, counter{Id: ARCHIVED_ID})
 
-	unreadFavCount, err := articleRepo.Count(user, content.UnreadOnly, content.FavoriteOnly,
-		content.Filters(content.GetUserFilters(user)),
-	)
+	unreadFavCount, err := articleRepo.Count(user, content.UnreadOnly, content.FavoriteOnly, filters)
 	if err != nil {
 		return nil, errors.WithMessage(err, "getting favorite unread count")
 	}
 
-	favCount, err := articleRepo.Count(user, content.FavoriteOnly,
-		content.Filters(content.GetUserFilters(user)),
-	)
+	favCount, err := articleRepo.Count(user, content.FavoriteOnly, filters)
 	if err != nil {
 		return nil, errors.WithMessage(err, "getting favorite count")
 	}
@@ -127,62 +169,142 @@ func getCounters(req request, user content.User, service repo.Service) (interfac
 			Counter:    unreadFavCount,
 			AuxCounter: favCount})
 
-	cContent = append(cContent, counter{Id: PUBLISHED_ID})
+	unreadPubCount, err := articleRepo.Count(user, content.UnreadOnly, content.PublishedOnly, filters)
+	if err != nil {
+		return nil, errors.WithMessage(err, "getting published unread count")
+	}
+
+	pubCount, err := articleRepo.Count(user, content.PublishedOnly, filters)
+	if err != nil {
+		return nil, errors.WithMessage(err, "getting published count")
+	}
+
+	cContent = append(cContent,
+		counter{Id: PUBLISHED_ID,
+			Counter:    unreadPubCount,
+			AuxCounter: pubCount})
 
 	freshTime := time.Now().Add(FRESH_DURATION)
 	freshCount, err := articleRepo.Count(user, content.UnreadOnly,
-		content.TimeRange(freshTime, time.Time{}),
-		content.Filters(content.GetUserFilters(user)),
+		content.TimeRange(freshTime, time.Time{}), filters,
 	)
 	if err != nil {
 		return nil, errors.WithMessage(err, "getting fresh unread count")
 	}
 	cContent = append(cContent,
-		counter{Id: FRESH_ID,
-			Counter:    freshCount,
-			AuxCounter: 0})
+		counter{Id: FRESH_ID, Counter: freshCount})
 
-	userCount, err := articleRepo.Count(user,
-		content.Filters(content.GetUserFilters(user)),
-	)
+	cContent = append(cContent,
+		counter{Id: ALL_ID, Counter: unreadCount})
+
+	searches, err := savedSearchCounters(user, service, searchProvider)
 	if err != nil {
-		return nil, errors.WithMessage(err, "getting user count")
+		return nil, err
 	}
-	cContent = append(cContent,
-		counter{Id: ALL_ID,
-			Counter:    userCount,
-			AuxCounter: 0})
-
-	for _, f := range feeds {
-		feedCount, err := articleRepo.Count(user,
-			content.FeedIDs([]content.FeedID{f.ID}),
-			content.Filters(content.GetUserFilters(user)),
-		)
+
+	cContent = append(cContent, searches...)
+
+	if strings.Contains(req.OutputMode, "l") {
+		labels, err := service.LabelRepo().ForUser(user)
 		if err != nil {
-			return nil, errors.WithMessage(err, "getting feed count")
+			return nil, errors.WithMessage(err, "getting user labels")
 		}
-		cContent = append(cContent,
-			counter{Id: int64(f.ID), Counter: feedCount},
-		)
 
+		for _, l := range labels {
+			ids := content.LabelIDs([]content.LabelID{l.ID})
+
+			unread, err := articleRepo.Count(user, content.UnreadOnly, ids, filters)
+			if err != nil {
+				return nil, errors.WithMessage(err, "getting label unread count")
+			}
+
+			total, err := articleRepo.Count(user, ids, filters)
+			if err != nil {
+				return nil, errors.WithMessage(err, "getting label count")
+			}
+
+			cContent = append(cContent,
+				counter{Id: int64(labelFeedID(l.ID)), Counter: unread, AuxCounter: total},
+			)
+		}
 	}
 
-	cContent = append(cContent, counter{Id: CAT_LABELS, Counter: 0, Kind: "cat"})
+	if strings.Contains(req.OutputMode, "f") {
+		for _, f := range feeds {
+			unread, err := articleRepo.Count(user, content.UnreadOnly,
+				content.FeedIDs([]content.FeedID{f.ID}), filters,
+			)
+			if err != nil {
+				return nil, errors.WithMessage(err, "getting feed unread count")
+			}
+
+			image, err := service.FeedImageRepo().Get(f)
+			if err != nil && !content.IsNoContent(err) {
+				return nil, errors.WithMessage(err, "getting feed image")
+			}
+
+			c := counter{Id: int64(f.ID), Counter: unread}
+			if len(image.Icon) > 0 {
+				c.HasImg = 1
+			}
 
-	tagRepo := service.TagRepo()
-	tags, err := tagRepo.ForUser(user)
+			cContent = append(cContent, c)
+		}
+	}
+
+	if strings.Contains(req.OutputMode, "c") {
+		categories, err := categoryCounters(user, service)
+		if err != nil {
+			return nil, err
+		}
+
+		cContent = append(cContent, categories...)
+	}
+
+	return cContent, nil
+}
+
+// categoryCounters returns the unread counters of the labels category, the
+// user's categories, including their nested categories, and the
+// uncategorized feeds.
+func categoryCounters(user content.User, service repo.Service) (countersContent, error) {
+	cContent := countersContent{}
+
+	articleRepo := service.ArticleRepo()
+	filters := content.Filters(content.GetUserFilters(user))
+
+	labelIDs, err := userLabelIDs(service, user)
+	if err != nil {
+		return nil, err
+	}
+
+	var labelsCount int64
+	if len(labelIDs) > 0 {
+		labelsCount, err = articleRepo.Count(user, content.UnreadOnly,
+			content.LabelIDs(labelIDs), filters,
+		)
+		if err != nil {
+			return nil, errors.WithMessage(err, "getting labels unread count")
+		}
+	}
+
+	cContent = append(cContent, counter{Id: CAT_LABELS, Counter: labelsCount, Kind: "cat"})
+
+	tags, err := service.TagRepo().ForUser(user)
 	if err != nil {
 		return nil, errors.WithMessage(err, "getting user tags")
 	}
+
+	sortTags(tags)
+
 	for _, tag := range tags {
-		ids, err := tagRepo.FeedIDs(tag, user)
+		ids, err := categoryFeedIDs(tag, true, user, service)
 		if err != nil {
-			return nil, errors.WithMessage(err, "getting tag feed ids")
+			return nil, err
 		}
 
 		tagCount, err := articleRepo.Count(user, content.UnreadOnly,
-			content.FeedIDs(ids),
-			content.Filters(content.GetUserFilters(user)),
+			content.FeedIDs(ids), filters,
 		)
 		if err != nil {
 			return nil, errors.WithMessage(err, "getting tag unread count")
@@ -197,8 +319,7 @@ func getCounters(req request, user content.User, service repo.Service) (interfac
 	}
 
 	unreadUntaggedCount, err := articleRepo.Count(user,
-		content.UnreadOnly, content.UntaggedOnly,
-		content.Filters(content.GetUserFilters(user)),
+		content.UnreadOnly, content.UntaggedOnly, filters,
 	)
 	if err != nil {
 		return nil, errors.WithMessage(err, "getting unread untagged count")
@@ -213,8 +334,3 @@ func getCounters(req request, user content.User, service repo.Service) (interfac
 
 	return cContent, nil
 }
-
-func init() {
-	actions["getUnread"] = getUnread
-	actions["getCounters"] = getCounters
-}
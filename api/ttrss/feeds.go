@@ -6,8 +6,10 @@ import (
 	"time"
 
 	"github.com/pkg/errors"
+	"github.com/urandom/readeef"
 	"github.com/urandom/readeef/content"
 	"github.com/urandom/readeef/content/repo"
+	"github.com/urandom/readeef/content/search"
 )
 
 type feedsContent []feed
@@ -20,6 +22,8 @@ type feed struct {
 	FeedUrl     string         `json:"feed_url,omitempty"`
 	LastUpdated int64          `json:"last_updated,omitempty"`
 	OrderId     int            `json:"order_id,omitempty"`
+	HasIcon     bool           `json:"has_icon"`
+	IsCat       bool           `json:"is_cat,omitempty"`
 }
 
 type category struct {
@@ -38,7 +42,27 @@ type feedTreeContent struct {
 	Categories category `json:"categories"`
 }
 
-func getFeeds(req request, user content.User, service repo.Service) (interface{}, error) {
+func registerFeedActions(searchProvider search.Provider, feedManager *readeef.FeedManager) {
+	actions["getFeeds"] = func(req request, user content.User, service repo.Service) (interface{}, error) {
+		return getFeeds(req, user, service, searchProvider)
+	}
+	actions["catchupFeed"] = func(req request, user content.User, service repo.Service) (interface{}, error) {
+		return catchupFeed(req, user, service, searchProvider)
+	}
+	actions["getFeedTree"] = func(req request, user content.User, service repo.Service) (interface{}, error) {
+		return getFeedTree(req, user, service, searchProvider)
+	}
+	actions["updateFeed"] = func(req request, user content.User, service repo.Service) (interface{}, error) {
+		return updateFeed(req, user, feedManager, service)
+	}
+}
+
+func getFeeds(
+	req request,
+	user content.User,
+	service repo.Service,
+	searchProvider search.Provider,
+) (interface{}, error) {
 	fContent := feedsContent{}
 
 	articleRepo := service.ArticleRepo()
@@ -60,6 +84,23 @@ func getFeeds(req request, user content.User, service repo.Service) (interface{}
 			})
 		}
 
+		unreadPub, err := articleRepo.Count(user,
+			content.UnreadOnly, content.PublishedOnly,
+			content.Filters(content.GetUserFilters(user)),
+		)
+		if err != nil {
+			return nil, errors.WithMessage(err, "getting unread published count")
+		}
+
+		if unreadPub > 0 || !req.UnreadOnly {
+			fContent = append(fContent, feed{
+				Id:     PUBLISHED_ID,
+				Title:  specialTitle(PUBLISHED_ID),
+				Unread: unreadPub,
+				CatId:  FAVORITE_ID,
+			})
+		}
+
 		freshTime := time.Now().Add(FRESH_DURATION)
 		unreadFresh, err := articleRepo.Count(user,
 			content.TimeRange(freshTime, time.Time{}), content.UnreadOnly,
@@ -93,6 +134,39 @@ func getFeeds(req request, user content.User, service repo.Service) (interface{}
 				CatId:  FAVORITE_ID,
 			})
 		}
+
+		searches, err := savedSearchFeeds(req, user, service, searchProvider)
+		if err != nil {
+			return nil, err
+		}
+
+		fContent = append(fContent, searches...)
+	}
+
+	if req.CatId == CAT_ALL || req.CatId == CAT_LABELS {
+		labels, err := service.LabelRepo().ForUser(user)
+		if err != nil {
+			return nil, errors.WithMessage(err, "getting user labels")
+		}
+
+		for _, l := range labels {
+			unread, err := articleRepo.Count(user, content.UnreadOnly,
+				content.LabelIDs([]content.LabelID{l.ID}),
+				content.Filters(content.GetUserFilters(user)),
+			)
+			if err != nil {
+				return nil, errors.WithMessage(err, "getting label unread count")
+			}
+
+			if unread > 0 || !req.UnreadOnly {
+				fContent = append(fContent, feed{
+					Id:     labelFeedID(l.ID),
+					Title:  l.Name,
+					Unread: unread,
+					CatId:  CAT_LABELS,
+				})
+			}
+		}
 	}
 
 	var feeds []content.Feed
@@ -127,6 +201,15 @@ func getFeeds(req request, user content.User, service repo.Service) (interface{}
 				return nil, errors.WithMessage(err, "getting user tag")
 			}
 
+			if req.IncludeNested {
+				nested, err := nestedCategoryFeeds(req, tag, user, service)
+				if err != nil {
+					return nil, err
+				}
+
+				fContent = append(fContent, nested...)
+			}
+
 			tagged, err := service.FeedRepo().ForTag(tag, user)
 			if err != nil {
 				return nil, errors.WithMessage(err, "getting tag feeds")
@@ -155,6 +238,11 @@ func getFeeds(req request, user content.User, service repo.Service) (interface{}
 			}
 
 			if unread > 0 || !req.UnreadOnly {
+				image, err := service.FeedImageRepo().Get(f)
+				if err != nil && !content.IsNoContent(err) {
+					return nil, errors.WithMessage(err, "getting feed image")
+				}
+
 				fContent = append(fContent, feed{
 					Id:          f.ID,
 					Title:       f.Title,
@@ -163,6 +251,7 @@ func getFeeds(req request, user content.User, service repo.Service) (interface{}
 					Unread:      unread,
 					LastUpdated: time.Now().Unix(),
 					OrderId:     0,
+					HasIcon:     len(image.Icon) > 0,
 				})
 			}
 		}
@@ -171,54 +260,165 @@ func getFeeds(req request, user content.User, service repo.Service) (interface{}
 	return fContent, nil
 }
 
-func updateFeed(req request, user content.User, service repo.Service) (interface{}, error) {
+// nestedCategoryFeeds returns the categories nested directly under the tag,
+// listed as feeds.
+func nestedCategoryFeeds(req request, tag content.Tag, user content.User, service repo.Service) (feedsContent, error) {
+	tags, err := service.TagRepo().ForUser(user)
+	if err != nil {
+		return nil, errors.WithMessage(err, "getting user tags")
+	}
+
+	sortTags(tags)
+
+	fContent := feedsContent{}
+	parents := tagParents(tags)
+	for _, t := range tags {
+		if parent, ok := parents[t.ID]; !ok || parent != tag.ID {
+			continue
+		}
+
+		ids, err := categoryFeedIDs(t, true, user, service)
+		if err != nil {
+			return nil, err
+		}
+
+		unread, err := service.ArticleRepo().Count(user,
+			content.UnreadOnly, content.FeedIDs(ids),
+			content.Filters(content.GetUserFilters(user)),
+		)
+		if err != nil {
+			return nil, errors.WithMessage(err, "getting nested category unread count")
+		}
+
+		if unread > 0 || !req.UnreadOnly {
+			fContent = append(fContent, feed{
+				Id:     content.FeedID(t.ID),
+				Title:  nestedTagName(t, tag),
+				Unread: unread,
+				CatId:  int(tag.ID),
+				IsCat:  true,
+			})
+		}
+	}
+
+	return fContent, nil
+}
+
+// updateFeed refreshes the feed right away. As the API doesn't report the
+// outcome, it doesn't wait for it.
+func updateFeed(
+	req request,
+	user content.User,
+	feedManager *readeef.FeedManager,
+	service repo.Service,
+) (interface{}, error) {
+	feed, err := service.FeedRepo().Get(req.FeedId, user)
+	if err != nil {
+		if content.IsNoContent(err) {
+			return nil, errors.WithStack(newErr("no feed", "FEED_NOT_FOUND"))
+		}
+		return nil, errors.WithMessage(err, "getting feed for user")
+	}
+
+	if _, err = feedManager.RefreshFeed(feed); err != nil {
+		return nil, errors.WithMessage(err, "refreshing feed")
+	}
+
 	return genericContent{Status: "OK"}, nil
 }
 
-func catchupFeed(req request, user content.User, service repo.Service) (interface{}, error) {
+func catchupFeed(
+	req request,
+	user content.User,
+	service repo.Service,
+	searchProvider search.Provider,
+) (interface{}, error) {
 	o := []content.QueryOpt{
-		content.TimeRange(time.Time{}, time.Now()),
 		content.Filters(content.GetUserFilters(user)),
 	}
 
-	var feedGenerator func() ([]content.Feed, error)
+	// The mode limits the catchup to articles older than the given period
+	var after time.Time
+	before := time.Now()
+	switch req.CatchupMode {
+	case "1day":
+		before = before.AddDate(0, 0, -1)
+	case "1week":
+		before = before.AddDate(0, 0, -7)
+	case "2week":
+		before = before.AddDate(0, 0, -14)
+	}
 
-	if req.IsCat {
-		tagID := content.TagID(req.FeedId)
+	var feedIDs []content.FeedID
+	var labelIDs []content.LabelID
+	var err error
 
-		if tagID == CAT_UNCATEGORIZED {
+	if req.IsCat {
+		switch tagID := content.TagID(req.FeedId); {
+		case tagID == CAT_UNCATEGORIZED:
 			o = append(o, content.UntaggedOnly)
-		} else {
+		case tagID == CAT_LABELS:
+			if labelIDs, err = userLabelIDs(service, user); err != nil {
+				return nil, err
+			}
+
+			if len(labelIDs) == 0 {
+				return genericContent{Status: "OK"}, nil
+			}
+		case tagID > 0:
 			tag, err := service.TagRepo().Get(tagID, user)
 			if err != nil {
 				return nil, errors.WithMessage(err, "getting tag for user")
 			}
 
-			feedGenerator = func() ([]content.Feed, error) {
-				return service.FeedRepo().ForTag(tag, user)
+			// Catching up a category includes its nested categories
+			if feedIDs, err = categoryFeedIDs(tag, true, user, service); err != nil {
+				return nil, err
 			}
+		default:
+			return genericContent{Status: "OK"}, nil
 		}
 	} else {
-		feedGenerator = func() ([]content.Feed, error) {
+		switch {
+		case req.FeedId == FAVORITE_ID:
+			o = append(o, content.FavoriteOnly)
+		case req.FeedId == PUBLISHED_ID:
+			o = append(o, content.PublishedOnly)
+		case req.FeedId == FRESH_ID:
+			after = time.Now().Add(FRESH_DURATION)
+		case req.FeedId == ALL_ID:
+		case isLabelFeed(req.FeedId):
+			label, err := service.LabelRepo().Get(feedLabelID(req.FeedId), user)
+			if err != nil {
+				return nil, errors.WithMessage(err, "getting user label")
+			}
+
+			labelIDs = []content.LabelID{label.ID}
+		case isSavedSearchFeed(req.FeedId):
+			return catchupSavedSearch(req, user, service, searchProvider, before)
+		case req.FeedId > 0:
 			feed, err := service.FeedRepo().Get(req.FeedId, user)
-			return []content.Feed{feed}, err
-		}
-	}
+			if err != nil {
+				return nil, errors.WithMessage(err, "getting user feed")
+			}
 
-	if feedGenerator != nil {
-		feeds, err := feedGenerator()
-		if err != nil {
-			return nil, errors.WithMessage(err, "getting feeds")
+			feedIDs = []content.FeedID{feed.ID}
+		default:
+			// Archived and recently read articles are never unread
+			return genericContent{Status: "OK"}, nil
 		}
+	}
 
-		ids := make([]content.FeedID, len(feeds))
-		for i := range feeds {
-			ids[i] = feeds[i].ID
-		}
+	if len(feedIDs) > 0 {
+		o = append(o, content.FeedIDs(feedIDs))
+	}
 
-		o = append(o, content.FeedIDs(ids))
+	if len(labelIDs) > 0 {
+		o = append(o, content.LabelIDs(labelIDs))
 	}
 
+	o = append(o, content.TimeRange(after, before))
+
 	if err := service.ArticleRepo().Read(true, user, o...); err != nil {
 		return nil, errors.WithMessage(err, "setting read state")
 	}
@@ -226,25 +426,53 @@ func catchupFeed(req request, user content.User, service repo.Service) (interfac
 	return genericContent{Status: "OK"}, nil
 }
 
-func getFeedTree(req request, user content.User, service repo.Service) (interface{}, error) {
+func getFeedTree(
+	req request,
+	user content.User,
+	service repo.Service,
+	searchProvider search.Provider,
+) (interface{}, error) {
 	items := []category{}
 
 	special, err := createSpecialCategory(service.ArticleRepo(), user)
 	if err != nil {
 		return nil, errors.WithMessage(err, "getting special categories")
 	}
+
+	searches, err := savedSearchCategoryFeeds(user, service, searchProvider)
+	if err != nil {
+		return nil, err
+	}
+
+	special.Items = append(special.Items, searches...)
 	items = append(items, special)
 
+	labels, err := createLabelsCategory(service, user)
+	if err != nil {
+		return nil, errors.WithMessage(err, "getting labels category")
+	}
+
+	if len(labels.Items) > 0 {
+		items = append(items, labels)
+	}
+
 	feeds, err := service.FeedRepo().ForUser(user)
 	if err != nil {
 		return nil, errors.WithMessage(err, "getting user feeds")
 	}
 
+	tags, err := service.TagRepo().ForUser(user)
+	if err != nil {
+		return nil, errors.WithMessage(err, "getting user tags")
+	}
+
+	sortTags(tags)
+
 	uncat := category{Id: "CAT:0", Items: []category{}, BareId: 0, Name: "Uncategorized", Type: "category"}
-	tagCategories := map[content.Tag]category{}
+	tagFeeds := map[content.TagID][]category{}
 
 	for _, f := range feeds {
-		tags, err := service.TagRepo().ForFeed(f, user)
+		feedTags, err := service.TagRepo().ForFeed(f, user)
 		if err != nil {
 			return nil, errors.WithMessage(err, "getting feed tags")
 		}
@@ -254,41 +482,55 @@ func getFeedTree(req request, user content.User, service repo.Service) (interfac
 			return nil, err
 		}
 
-		if len(tags) > 0 {
-			for _, t := range tags {
-				var c category
-				if cached, ok := tagCategories[t]; ok {
-					c = cached
-				} else {
-					c = category{
-						Id:     "CAT:" + strconv.FormatInt(int64(t.ID), 10),
-						BareId: content.FeedID(t.ID),
-						Name:   string(t.Value),
-						Type:   "category",
-						Items:  []category{},
-					}
-				}
-
-				c.Items = append(c.Items, item)
-				tagCategories[t] = c
+		if len(feedTags) > 0 {
+			for _, t := range feedTags {
+				tagFeeds[t.ID] = append(tagFeeds[t.ID], item)
 			}
 		} else {
 			uncat.Items = append(uncat.Items, item)
 		}
 	}
 
-	categories := []category{uncat}
-	for _, c := range tagCategories {
-		categories = append(categories, c)
+	// Tags containing a '/' are nested under the category of their closest
+	// ancestor, which lists its child categories before its feeds.
+	parents := tagParents(tags)
+	children := map[content.TagID][]content.Tag{}
+	var top []content.Tag
+	for _, t := range tags {
+		if parent, ok := parents[t.ID]; ok {
+			children[parent] = append(children[parent], t)
+		} else {
+			top = append(top, t)
+		}
 	}
 
-	for _, c := range categories {
-		if len(c.Items) == 1 {
-			c.Param = "(1 feed)"
-		} else {
-			c.Param = fmt.Sprintf("(%d feed)", len(c.Items))
+	var tagCategory func(t content.Tag, name string) category
+	tagCategory = func(t content.Tag, name string) category {
+		c := category{
+			Id:     "CAT:" + strconv.FormatInt(int64(t.ID), 10),
+			BareId: content.FeedID(t.ID),
+			Name:   name,
+			Type:   "category",
+			Items:  []category{},
+		}
+
+		for _, child := range children[t.ID] {
+			c.Items = append(c.Items, tagCategory(child, nestedTagName(child, t)))
 		}
-		items = append(items, c)
+
+		c.Items = append(c.Items, tagFeeds[t.ID]...)
+		c.Param = feedCountParam(len(c.Items))
+
+		return c
+	}
+
+	for _, t := range top {
+		items = append(items, tagCategory(t, string(t.Value)))
+	}
+
+	if len(uncat.Items) > 0 || req.IncludeEmpty {
+		uncat.Param = feedCountParam(len(uncat.Items))
+		items = append(items, uncat)
 	}
 
 	fl := category{Identifier: "id", Label: "name"}
@@ -297,6 +539,14 @@ func getFeedTree(req request, user content.User, service repo.Service) (interfac
 	return feedTreeContent{Categories: fl}, nil
 }
 
+func feedCountParam(count int) string {
+	if count == 1 {
+		return "(1 feed)"
+	}
+
+	return fmt.Sprintf("(%d feeds)", count)
+}
+
 func specialTitle(id content.FeedID) (t string) {
 	switch id {
 	case FAVORITE_ID:
@@ -343,6 +593,10 @@ func feedListCategoryFeed(
 			c.Unread, err = repo.Count(user, content.UnreadOnly, content.FavoriteOnly,
 				content.Filters(content.GetUserFilters(user)),
 			)
+		case PUBLISHED_ID:
+			c.Unread, err = repo.Count(user, content.UnreadOnly, content.PublishedOnly,
+				content.Filters(content.GetUserFilters(user)),
+			)
 		case FRESH_ID:
 			c.Unread, err = repo.Count(
 				user, content.UnreadOnly,
@@ -380,9 +634,28 @@ func createSpecialCategory(repo repo.Article, user content.User) (category, erro
 	return special, nil
 }
 
-func init() {
-	actions["getFeeds"] = getFeeds
-	actions["updateFeed"] = updateFeed
-	actions["catchupFeed"] = catchupFeed
-	actions["getFeedTree"] = getFeedTree
+func createLabelsCategory(service repo.Service, user content.User) (category, error) {
+	labels, err := service.LabelRepo().ForUser(user)
+	if err != nil {
+		return category{}, errors.WithMessage(err, "getting user labels")
+	}
+
+	c := category{Id: "CAT:-2", Items: []category{}, Name: "Labels", Type: "category", BareId: CAT_LABELS}
+
+	for _, l := range labels {
+		id := labelFeedID(l.ID)
+		item := category{BareId: id, Id: "FEED:" + strconv.FormatInt(int64(id), 10), Type: "feed", Name: l.Name}
+
+		item.Unread, err = service.ArticleRepo().Count(user, content.UnreadOnly,
+			content.LabelIDs([]content.LabelID{l.ID}),
+			content.Filters(content.GetUserFilters(user)),
+		)
+		if err != nil {
+			return category{}, errors.WithMessage(err, "getting label unread count")
+		}
+
+		c.Items = append(c.Items, item)
+	}
+
+	return c, nil
 }
@@ -40,7 +40,7 @@ func getConfig(req request, user content.User, service repo.Service) (interface{
 	}
 	feedCount := len(feeds)
 
-	return configContent{DaemonIsRunning: true, NumFeeds: feedCount}, nil
+	return configContent{IconsDir: ICONS_URL, IconsUrl: ICONS_URL, DaemonIsRunning: true, NumFeeds: feedCount}, nil
 }
 
 func unknown(req request, user content.User, service repo.Service) (interface{}, error) {
@@ -20,36 +20,46 @@ import (
 )
 
 type request struct {
-	Op            string              `json:"op"`
-	Sid           string              `json:"sid"`
-	Seq           int                 `json:"seq"`
-	User          string              `json:"user"`
-	Password      string              `json:"password"`
-	OutputMode    string              `json:"output_mode"`
-	UnreadOnly    bool                `json:"unread_only"`
-	IncludeEmpty  bool                `json:"include_empty"`
-	Limit         int                 `json:"limit"`
-	Offset        int                 `json:"offset"`
-	CatId         content.TagID       `json:"cat_id"`
-	FeedId        content.FeedID      `json:"feed_id"`
-	Skip          int                 `json:"skip"`
-	IsCat         bool                `json:"is_cat"`
-	ShowContent   bool                `json:"show_content"`
-	ShowExcerpt   bool                `json:"show_excerpt"`
-	ViewMode      string              `json:"view_mode"`
-	SinceId       content.ArticleID   `json:"since_id"`
-	Sanitize      bool                `json:"sanitize"`
-	HasSandbox    bool                `json:"has_sandbox"`
-	IncludeHeader bool                `json:"include_header"`
-	OrderBy       string              `json:"order_by"`
-	Search        string              `json:"search"`
-	ArticleIds    []content.ArticleID `json:"article_ids"`
-	Mode          int                 `json:"mode"`
-	Field         int                 `json:"field"`
-	Data          string              `json:"data"`
-	ArticleId     []content.ArticleID `json:"article_id"`
-	PrefName      string              `json:"pref_name"`
-	FeedUrl       string              `json:"feed_url"`
+	Op                 string              `json:"op"`
+	Sid                string              `json:"sid"`
+	Seq                int                 `json:"seq"`
+	User               string              `json:"user"`
+	Password           string              `json:"password"`
+	OutputMode         string              `json:"output_mode"`
+	UnreadOnly         bool                `json:"unread_only"`
+	IncludeEmpty       bool                `json:"include_empty"`
+	IncludeNested      bool                `json:"include_nested"`
+	Limit              int                 `json:"limit"`
+	Offset             int                 `json:"offset"`
+	CatId              content.TagID       `json:"cat_id"`
+	FeedId             content.FeedID      `json:"feed_id"`
+	Skip               int                 `json:"skip"`
+	IsCat              bool                `json:"is_cat"`
+	ShowContent        bool                `json:"show_content"`
+	ShowExcerpt        bool                `json:"show_excerpt"`
+	IncludeAttachments bool                `json:"include_attachments"`
+	ViewMode           string              `json:"view_mode"`
+	SinceId            content.ArticleID   `json:"since_id"`
+	Sanitize           bool                `json:"sanitize"`
+	HasSandbox         bool                `json:"has_sandbox"`
+	IncludeHeader      bool                `json:"include_header"`
+	OrderBy            string              `json:"order_by"`
+	Search             string              `json:"search"`
+	SearchMode         string              `json:"search_mode"`
+	ArticleIds         []content.ArticleID `json:"article_ids"`
+	Mode               int                 `json:"mode"`
+	CatchupMode        string              `json:"-"`
+	Field              int                 `json:"field"`
+	Data               string              `json:"data"`
+	ArticleId          []content.ArticleID `json:"article_id"`
+	PrefName           string              `json:"pref_name"`
+	FeedUrl            string              `json:"feed_url"`
+	Title              string              `json:"title"`
+	Url                string              `json:"url"`
+	Content            string              `json:"content"`
+	CategoryId         content.TagID       `json:"category_id"`
+	LabelId            content.FeedID      `json:"label_id"`
+	Assign             bool                `json:"assign"`
 }
 
 type response struct {
@@ -70,6 +80,9 @@ const (
 	API_VERSION    = "1.8.0"
 	API_LEVEL      = 12
 
+	// Feed icons are served relative to the emulator root
+	ICONS_URL = "feed-icons"
+
 	ARCHIVED_ID      = 0
 	FAVORITE_ID      = -1
 	PUBLISHED_ID     = -2
@@ -84,6 +97,13 @@ const (
 	CAT_LABELS             = -2
 	CAT_ALL_EXCEPT_VIRTUAL = -3 // i.e: labels
 	CAT_ALL                = -4
+
+	// Labels are exposed as virtual feeds, with ids below this one
+	LABEL_BASE_INDEX = -1024
+
+	// Saved searches are exposed as special feeds, with ids between this one
+	// and the label ones
+	SAVED_SEARCH_BASE_INDEX = -128
 )
 
 var (
@@ -106,6 +126,8 @@ func Handler(
 
 	registerAuthActions(sessionManager, secret)
 	registerArticleActions(searchProvider, processors)
+	registerFeedActions(searchProvider, feedManager)
+	registerCounterActions(searchProvider)
 	registerSettingActions(feedManager, update)
 
 	return func(w http.ResponseWriter, r *http.Request) {
@@ -243,7 +265,7 @@ func errorKind(err error) string {
 		Kind() string
 	}
 
-	if v, ok := err.(kinder); ok {
+	if v, ok := errors.Cause(err).(kinder); ok {
 		return v.Kind()
 	}
 
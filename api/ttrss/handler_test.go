@@ -0,0 +1,598 @@
+package ttrss
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/urandom/readeef/config"
+	"github.com/urandom/readeef/content"
+	"github.com/urandom/readeef/content/repo/mock_repo"
+	"github.com/urandom/readeef/content/search"
+	"github.com/urandom/readeef/log"
+)
+
+var (
+	secret   = []byte("secret")
+	logger   log.Log
+	testUser = content.User{Login: "test"}
+)
+
+// The snapshots in testdata hold a request, sent after logging in, and the
+// response of the emulator, given the subscriptions in newTestWorld. They
+// were written by hand after the documented TT-RSS API, not recorded from a
+// TT-RSS server, and only guard against regressions of the emulator. The
+// responses of a real server are checked by TestHandler_conformance.
+func TestHandler_snapshots(t *testing.T) {
+	tests := []struct {
+		name   string
+		search bool
+		check  func(t *testing.T, w *testWorld)
+	}{
+		{name: "getFeedTree"},
+		{name: "getCounters"},
+		{name: "getCounters_feeds"},
+		{name: "getCounters_categories"},
+		{name: "getHeadlines_feed"},
+		{name: "getHeadlines_since_id"},
+		{name: "getHeadlines_category"},
+		{name: "getHeadlines_category_nested"},
+		{name: "getHeadlines_uncategorized"},
+		{name: "getHeadlines_label"},
+		{name: "getHeadlines_adaptive"},
+		{name: "getHeadlines_published"},
+		{name: "getHeadlines_search"},
+		{name: "getHeadlines_saved_search", search: true},
+		{name: "getFeeds_saved_search", search: true},
+		{name: "getFeedTree_saved_search", search: true},
+		{name: "getCounters_saved_search", search: true},
+		{name: "catchupFeed_saved_search", search: true, check: func(t *testing.T, w *testWorld) {
+			checkRead(t, w, 101)
+		}},
+		{name: "getArticle"},
+		{name: "getLabels"},
+		{name: "setArticleLabel", check: func(t *testing.T, w *testWorld) {
+			if !reflect.DeepEqual(w.assigned, []content.ArticleID{103, 104}) {
+				t.Errorf("assigned = %v", w.assigned)
+			}
+		}},
+		{name: "updateArticle_note", check: func(t *testing.T, w *testWorld) {
+			want := map[content.ArticleID]string{101: "read later", 103: "read later"}
+			if !reflect.DeepEqual(w.notes, want) {
+				t.Errorf("notes = %v, want %v", w.notes, want)
+			}
+		}},
+		{name: "updateArticle_note_remove", check: func(t *testing.T, w *testWorld) {
+			if len(w.notes) != 0 {
+				t.Errorf("notes = %v, want none", w.notes)
+			}
+		}},
+		{name: "catchupFeed", check: func(t *testing.T, w *testWorld) {
+			checkRead(t, w, 104, 105)
+		}},
+		{name: "catchupFeed_1day", check: func(t *testing.T, w *testWorld) {
+			checkRead(t, w, 104)
+		}},
+		{name: "catchupFeed_category", check: func(t *testing.T, w *testWorld) {
+			checkRead(t, w, 101, 103)
+		}},
+		{name: "catchupFeed_published", check: func(t *testing.T, w *testWorld) {
+			checkRead(t, w, 103)
+		}},
+		{name: "updateArticle_published", check: func(t *testing.T, w *testWorld) {
+			want := map[content.ArticleID]bool{101: true}
+			if !reflect.DeepEqual(w.published, want) {
+				t.Errorf("published = %v, want %v", w.published, want)
+			}
+		}},
+		{name: "shareToPublished", check: func(t *testing.T, w *testWorld) {
+			want := []content.Publication{{
+				ID: 1, UserLogin: "test", Title: "Interesting read",
+				Link: "https://example.com/read", Description: "<p>Have a look</p>",
+			}}
+			if !reflect.DeepEqual(w.shared, want) {
+				t.Errorf("shared = %v, want %v", w.shared, want)
+			}
+		}},
+		{name: "shareToPublished_invalid", check: func(t *testing.T, w *testWorld) {
+			if len(w.shared) != 0 {
+				t.Errorf("shared = %v, want none", w.shared)
+			}
+		}},
+		{name: "subscribeToFeed_category", check: func(t *testing.T, w *testWorld) {
+			if tags := w.tags[4]; len(tags) != 1 || tags[0].ID != 2 {
+				t.Errorf("subscribed feed tags = %v", tags)
+			}
+		}},
+		{name: "subscribeToFeed_exists"},
+		{name: "subscribeToFeed_invalid"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			snapshot := readSnapshot(t, filepath.Join("testdata", tt.name+".json"))
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			got, w := serveSnapshot(t, ctrl, snapshot.Request, tt.search)
+
+			if !reflect.DeepEqual(got, snapshot.Response) {
+				gotB, _ := json.MarshalIndent(got, "", "\t")
+				wantB, _ := json.MarshalIndent(snapshot.Response, "", "\t")
+				t.Errorf("response = %s\nwant = %s", gotB, wantB)
+			}
+
+			if tt.check != nil {
+				tt.check(t, w)
+			}
+		})
+	}
+}
+
+type snapshot struct {
+	Request  map[string]interface{} `json:"request"`
+	Response interface{}            `json:"response"`
+}
+
+func readSnapshot(t *testing.T, path string) snapshot {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var s snapshot
+	if err := json.Unmarshal(b, &s); err != nil {
+		t.Fatalf("decoding %s: %v", path, err)
+	}
+
+	return s
+}
+
+// serveSnapshot logs in to the emulator, serving the subscriptions in
+// newTestWorld, and sends it the request of a snapshot.
+func serveSnapshot(t *testing.T, ctrl *gomock.Controller, request map[string]interface{}, withSearch bool) (map[string]interface{}, *testWorld) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	w := newTestWorld(ctrl)
+
+	var searchProvider search.Provider
+	if withSearch {
+		searchProvider = testSearcher{w: w}
+	}
+
+	handler := Handler(ctx, w.service, searchProvider, nil, nil, secret, 30*time.Minute, logger)
+
+	login := call(t, handler, map[string]interface{}{"op": "login", "user": "test", "password": "pass"})
+	sid, _ := login["content"].(map[string]interface{})["session_id"].(string)
+	if sid == "" {
+		t.Fatalf("login response = %v", login)
+	}
+
+	req := map[string]interface{}{"sid": sid}
+	for k, v := range request {
+		req[k] = v
+	}
+
+	return call(t, handler, req), w
+}
+
+func init() {
+	cfg := config.Log{}
+	cfg.Converted.Writer = os.Stderr
+	cfg.Converted.Prefix = "[testing] "
+
+	logger = log.WithStd(cfg)
+
+	testUser.Password("pass", secret)
+}
+
+func call(t *testing.T, handler http.HandlerFunc, body map[string]interface{}) map[string]interface{} {
+	b, err := json.Marshal(body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	w := httptest.NewRecorder()
+	handler(w, httptest.NewRequest("POST", "/", bytes.NewReader(b)))
+
+	resp := map[string]interface{}{}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decoding response '%s': %v", w.Body, err)
+	}
+
+	return resp
+}
+
+func checkRead(t *testing.T, w *testWorld, ids ...content.ArticleID) {
+	var read []content.ArticleID
+	for _, id := range w.marked {
+		read = append(read, id)
+	}
+
+	sort.Slice(read, func(i, j int) bool { return read[i] < read[j] })
+
+	if len(read) != len(ids) || len(ids) > 0 && !reflect.DeepEqual(read, ids) {
+		t.Errorf("marked as read = %v, want %v", read, ids)
+	}
+}
+
+// testWorld is an in-memory implementation of the repositories, filtering
+// its articles with the query options.
+type testWorld struct {
+	service *mock_repo.MockService
+
+	user     content.User
+	feeds    []content.Feed
+	tags     map[content.FeedID][]content.Tag
+	labels   []content.Label
+	searches []content.SavedSearch
+	articles []content.Article
+
+	marked   []content.ArticleID
+	assigned []content.ArticleID
+	notes    map[content.ArticleID]string
+
+	published map[content.ArticleID]bool
+	shared    []content.Publication
+}
+
+func newTestWorld(ctrl *gomock.Controller) *testWorld {
+	dev := content.Tag{ID: 1, Value: "dev"}
+	rust := content.Tag{ID: 2, Value: "dev/rust"}
+
+	w := &testWorld{
+		service: mock_repo.NewMockService(ctrl),
+		user:    testUser,
+		feeds: []content.Feed{
+			{ID: 1, Title: "Go Blog", Link: "https://blog.golang.org/feed.atom"},
+			{ID: 2, Title: "Rust Blog", Link: "https://blog.rust-lang.org/feed.xml"},
+			{ID: 3, Title: "News", Link: "https://news.example.com/rss"},
+		},
+		tags: map[content.FeedID][]content.Tag{
+			1: {dev},
+			2: {rust},
+		},
+		labels: []content.Label{{ID: 1, UserLogin: "test", Name: "later"}},
+		searches: []content.SavedSearch{
+			{ID: 1, UserLogin: "test", Name: "Go", Query: "go"},
+			{ID: 2, UserLogin: "test", Name: "Rust", Query: "rust", TagID: 2},
+		},
+		articles: []content.Article{
+			{ID: 101, FeedID: 1, Title: "Go 1.9 is released", Link: "https://blog.golang.org/go1.9",
+				Author: "Francesc", Description: "<p>Go 1.9 is out.</p>", Date: time.Date(2017, 8, 24, 0, 0, 0, 0, time.UTC),
+				Labels: []content.LabelID{1}},
+			{ID: 102, FeedID: 1, Title: "Toward Go 2", Link: "https://blog.golang.org/toward-go2",
+				Author: "Russ", Date: time.Date(2017, 7, 13, 0, 0, 0, 0, time.UTC), Read: true, Favorite: true},
+			{ID: 103, FeedID: 2, Title: "Rust 1.20", Link: "https://blog.rust-lang.org/1.20",
+				Date: time.Date(2017, 8, 31, 0, 0, 0, 0, time.UTC), Categories: []string{"release"}, Note: "compare",
+				Published: true},
+			{ID: 104, FeedID: 3, Title: "Old news", Link: "https://news.example.com/old",
+				Date: time.Date(2017, 6, 1, 0, 0, 0, 0, time.UTC)},
+			{ID: 105, FeedID: 3, Title: "Breaking news", Link: "https://news.example.com/breaking",
+				Date: time.Now().Add(-time.Hour)},
+		},
+		notes:     map[content.ArticleID]string{},
+		published: map[content.ArticleID]bool{},
+	}
+
+	for _, a := range w.articles {
+		if a.Note != "" {
+			w.notes[a.ID] = a.Note
+		}
+		if a.Published {
+			w.published[a.ID] = true
+		}
+	}
+
+	userRepo := mock_repo.NewMockUser(ctrl)
+	feedRepo := mock_repo.NewMockFeed(ctrl)
+	tagRepo := mock_repo.NewMockTag(ctrl)
+	labelRepo := mock_repo.NewMockLabel(ctrl)
+	articleRepo := mock_repo.NewMockArticle(ctrl)
+	feedImageRepo := mock_repo.NewMockFeedImage(ctrl)
+	publicationRepo := mock_repo.NewMockPublication(ctrl)
+	savedSearchRepo := mock_repo.NewMockSavedSearch(ctrl)
+
+	w.service.EXPECT().UserRepo().Return(userRepo).AnyTimes()
+	w.service.EXPECT().FeedRepo().Return(feedRepo).AnyTimes()
+	w.service.EXPECT().TagRepo().Return(tagRepo).AnyTimes()
+	w.service.EXPECT().LabelRepo().Return(labelRepo).AnyTimes()
+	w.service.EXPECT().ArticleRepo().Return(articleRepo).AnyTimes()
+	w.service.EXPECT().FeedImageRepo().Return(feedImageRepo).AnyTimes()
+	w.service.EXPECT().PublicationRepo().Return(publicationRepo).AnyTimes()
+	w.service.EXPECT().SavedSearchRepo().Return(savedSearchRepo).AnyTimes()
+
+	userRepo.EXPECT().Get(w.user.Login).Return(w.user, nil).AnyTimes()
+
+	feedRepo.EXPECT().ForUser(gomock.Any()).Return(w.feeds, nil).AnyTimes()
+	feedRepo.EXPECT().Get(gomock.Any(), gomock.Any()).DoAndReturn(func(id content.FeedID, _ content.User) (content.Feed, error) {
+		for _, f := range w.feeds {
+			if f.ID == id {
+				return f, nil
+			}
+		}
+		return content.Feed{}, content.ErrNoContent
+	}).AnyTimes()
+	feedRepo.EXPECT().FindByLink(gomock.Any()).DoAndReturn(func(link string) (content.Feed, error) {
+		for _, f := range w.feeds {
+			if f.Link == link {
+				return f, nil
+			}
+		}
+		if link == "https://lwn.net/headlines/rss" {
+			return content.Feed{ID: 4, Title: "LWN", Link: link}, nil
+		}
+		return content.Feed{}, content.ErrNoContent
+	}).AnyTimes()
+	feedRepo.EXPECT().Users(gomock.Any()).DoAndReturn(func(f content.Feed) ([]content.User, error) {
+		if f.ID == 4 {
+			return nil, nil
+		}
+		return []content.User{w.user}, nil
+	}).AnyTimes()
+	feedRepo.EXPECT().AttachTo(gomock.Any(), gomock.Any()).DoAndReturn(func(f content.Feed, _ content.User) error {
+		w.feeds = append(w.feeds, f)
+		return nil
+	}).AnyTimes()
+	feedRepo.EXPECT().SetUserTags(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(f content.Feed, _ content.User, tags []*content.Tag) error {
+		w.tags[f.ID] = nil
+		for _, t := range tags {
+			w.tags[f.ID] = append(w.tags[f.ID], *t)
+		}
+		return nil
+	}).AnyTimes()
+
+	tagRepo.EXPECT().Get(gomock.Any(), gomock.Any()).DoAndReturn(func(id content.TagID, _ content.User) (content.Tag, error) {
+		for _, t := range w.allTags() {
+			if t.ID == id {
+				return t, nil
+			}
+		}
+		return content.Tag{}, content.ErrNoContent
+	}).AnyTimes()
+	tagRepo.EXPECT().ForUser(gomock.Any()).DoAndReturn(func(content.User) ([]content.Tag, error) {
+		return w.allTags(), nil
+	}).AnyTimes()
+	tagRepo.EXPECT().ForFeed(gomock.Any(), gomock.Any()).DoAndReturn(func(f content.Feed, _ content.User) ([]content.Tag, error) {
+		return w.tags[f.ID], nil
+	}).AnyTimes()
+	tagRepo.EXPECT().FeedIDs(gomock.Any(), gomock.Any()).DoAndReturn(func(tag content.Tag, _ content.User) ([]content.FeedID, error) {
+		var ids []content.FeedID
+		for _, f := range w.feeds {
+			for _, t := range w.tags[f.ID] {
+				if t.ID == tag.ID {
+					ids = append(ids, f.ID)
+				}
+			}
+		}
+		return ids, nil
+	}).AnyTimes()
+
+	labelRepo.EXPECT().ForUser(gomock.Any()).Return(w.labels, nil).AnyTimes()
+	labelRepo.EXPECT().Get(gomock.Any(), gomock.Any()).DoAndReturn(func(id content.LabelID, _ content.User) (content.Label, error) {
+		for _, l := range w.labels {
+			if l.ID == id {
+				return l, nil
+			}
+		}
+		return content.Label{}, content.ErrNoContent
+	}).AnyTimes()
+	labelRepo.EXPECT().Assign(gomock.Any(), gomock.Any()).DoAndReturn(func(_ content.Label, ids []content.ArticleID) error {
+		w.assigned = append(w.assigned, ids...)
+		return nil
+	}).AnyTimes()
+
+	savedSearchRepo.EXPECT().ForUser(gomock.Any()).Return(w.searches, nil).AnyTimes()
+	savedSearchRepo.EXPECT().Get(gomock.Any(), gomock.Any()).DoAndReturn(func(id content.SavedSearchID, _ content.User) (content.SavedSearch, error) {
+		for _, s := range w.searches {
+			if s.ID == id {
+				return s, nil
+			}
+		}
+		return content.SavedSearch{}, content.ErrNoContent
+	}).AnyTimes()
+
+	articleRepo.EXPECT().ForUser(gomock.Any(), gomock.Any()).DoAndReturn(func(_ content.User, opts ...content.QueryOpt) ([]content.Article, error) {
+		return w.query(opts), nil
+	}).AnyTimes()
+	articleRepo.EXPECT().Count(gomock.Any(), gomock.Any()).DoAndReturn(func(_ content.User, opts ...content.QueryOpt) (int64, error) {
+		return int64(len(w.query(append(opts, content.Paging(0, 0))))), nil
+	}).AnyTimes()
+	articleRepo.EXPECT().IDs(gomock.Any(), gomock.Any()).DoAndReturn(func(_ content.User, opts ...content.QueryOpt) ([]content.ArticleID, error) {
+		var ids []content.ArticleID
+		for _, a := range w.query(opts) {
+			ids = append(ids, a.ID)
+		}
+		return ids, nil
+	}).AnyTimes()
+	articleRepo.EXPECT().Read(true, gomock.Any(), gomock.Any()).DoAndReturn(func(_ bool, _ content.User, opts ...content.QueryOpt) error {
+		for _, a := range w.query(append(opts, content.UnreadOnly)) {
+			w.marked = append(w.marked, a.ID)
+		}
+		return nil
+	}).AnyTimes()
+	articleRepo.EXPECT().SetNote(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(_ content.User, id content.ArticleID, note string) error {
+		if note == "" {
+			delete(w.notes, id)
+		} else {
+			w.notes[id] = note
+		}
+		return nil
+	}).AnyTimes()
+
+	publicationRepo.EXPECT().Publish(gomock.Any(), gomock.Any()).DoAndReturn(func(_ content.User, ids []content.ArticleID) error {
+		for _, id := range ids {
+			w.published[id] = true
+		}
+		return nil
+	}).AnyTimes()
+	publicationRepo.EXPECT().Unpublish(gomock.Any(), gomock.Any()).DoAndReturn(func(_ content.User, ids []content.ArticleID) error {
+		for _, id := range ids {
+			delete(w.published, id)
+		}
+		return nil
+	}).AnyTimes()
+	publicationRepo.EXPECT().Update(gomock.Any()).DoAndReturn(func(p *content.Publication) error {
+		p.ID = content.PublicationID(len(w.shared) + 1)
+		w.shared = append(w.shared, *p)
+		return nil
+	}).AnyTimes()
+
+	feedImageRepo.EXPECT().Get(gomock.Any()).DoAndReturn(func(f content.Feed) (content.FeedImage, error) {
+		if f.ID == 1 {
+			return content.FeedImage{FeedID: f.ID, Icon: []byte("icon")}, nil
+		}
+		return content.FeedImage{}, content.ErrNoContent
+	}).AnyTimes()
+
+	return w
+}
+
+func (w *testWorld) allTags() []content.Tag {
+	seen := map[content.TagID]bool{}
+	var tags []content.Tag
+	for _, f := range w.feeds {
+		for _, t := range w.tags[f.ID] {
+			if !seen[t.ID] {
+				seen[t.ID] = true
+				tags = append(tags, t)
+			}
+		}
+	}
+
+	return tags
+}
+
+// testSearcher matches the articles whose title contains the query, ignoring
+// case.
+type testSearcher struct {
+	search.Provider
+
+	w *testWorld
+}
+
+func (s testSearcher) Search(query string, _ content.User, opts ...content.QueryOpt) ([]content.Article, error) {
+	articles := []content.Article{}
+	for _, a := range s.w.query(opts) {
+		if strings.Contains(strings.ToLower(a.Title), strings.ToLower(query)) {
+			articles = append(articles, a)
+		}
+	}
+
+	return articles, nil
+}
+
+// query returns the articles matching the options supported by the
+// emulator.
+func (w *testWorld) query(opts []content.QueryOpt) []content.Article {
+	o := content.QueryOptions{}
+	o.Apply(opts)
+
+	if !o.ChangedSince.IsZero() {
+		// No changes are recorded
+		return nil
+	}
+
+	in := func(id int64, ids []int64) bool {
+		for _, i := range ids {
+			if i == id {
+				return true
+			}
+		}
+		return false
+	}
+
+	var ids, feedIDs, labelIDs []int64
+	for _, id := range o.IDs {
+		ids = append(ids, int64(id))
+	}
+	for _, id := range o.FeedIDs {
+		feedIDs = append(feedIDs, int64(id))
+	}
+	for _, id := range o.LabelIDs {
+		labelIDs = append(labelIDs, int64(id))
+	}
+
+	subscribed := map[content.FeedID]bool{}
+	for _, f := range w.feeds {
+		subscribed[f.ID] = true
+	}
+
+	articles := []content.Article{}
+	for _, a := range w.articles {
+		switch {
+		case !subscribed[a.FeedID],
+			len(ids) > 0 && !in(int64(a.ID), ids),
+			len(feedIDs) > 0 && !in(int64(a.FeedID), feedIDs),
+			o.UnreadOnly && a.Read,
+			o.ReadOnly && !a.Read,
+			o.FavoriteOnly && !a.Favorite,
+			o.PublishedOnly && !w.published[a.ID],
+			o.UntaggedOnly && len(w.tags[a.FeedID]) > 0,
+			o.AfterID > 0 && a.ID <= o.AfterID,
+			o.BeforeID > 0 && a.ID >= o.BeforeID,
+			!o.AfterDate.IsZero() && !a.Date.After(o.AfterDate),
+			!o.BeforeDate.IsZero() && !a.Date.Before(o.BeforeDate):
+			continue
+		}
+
+		if len(labelIDs) > 0 {
+			var labelled bool
+			for _, id := range a.Labels {
+				labelled = labelled || in(int64(id), labelIDs)
+			}
+
+			if !labelled {
+				continue
+			}
+		}
+
+		a.Published = w.published[a.ID]
+
+		if o.IncludeNotes {
+			a.Note = w.notes[a.ID]
+		} else {
+			a.Note = ""
+		}
+
+		articles = append(articles, a)
+	}
+
+	sort.SliceStable(articles, func(i, j int) bool {
+		if o.UnreadFirst && articles[i].Read != articles[j].Read {
+			return !articles[i].Read
+		}
+
+		if o.SortOrder == content.AscendingOrder {
+			return articles[i].Date.Before(articles[j].Date)
+		}
+
+		return articles[i].Date.After(articles[j].Date)
+	})
+
+	if o.Offset > 0 {
+		if o.Offset >= len(articles) {
+			return []content.Article{}
+		}
+		articles = articles[o.Offset:]
+	}
+
+	if o.Limit > 0 && o.Limit < len(articles) {
+		articles = articles[:o.Limit]
+	}
+
+	return articles
+}
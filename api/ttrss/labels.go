@@ -0,0 +1,138 @@
+package ttrss
+
+import (
+	"github.com/pkg/errors"
+	"github.com/urandom/readeef/content"
+	"github.com/urandom/readeef/content/repo"
+)
+
+type labelsContent []label
+
+type label struct {
+	Id      content.FeedID `json:"id"`
+	Caption string         `json:"caption"`
+	FgColor string         `json:"fg_color"`
+	BgColor string         `json:"bg_color"`
+	Checked bool           `json:"checked"`
+}
+
+func getLabels(req request, user content.User, service repo.Service) (interface{}, error) {
+	labels, err := service.LabelRepo().ForUser(user)
+	if err != nil {
+		return nil, errors.WithMessage(err, "getting user labels")
+	}
+
+	checked := map[content.LabelID]bool{}
+	if len(req.ArticleId) > 0 {
+		articles, err := service.ArticleRepo().ForUser(user,
+			content.IDs(req.ArticleId), content.IncludeLabels, content.IncludeHidden,
+		)
+		if err != nil {
+			return nil, errors.WithMessage(err, "getting user articles")
+		}
+
+		for _, a := range articles {
+			for _, id := range a.Labels {
+				checked[id] = true
+			}
+		}
+	}
+
+	lContent := labelsContent{}
+	for _, l := range labels {
+		lContent = append(lContent, label{
+			Id:      labelFeedID(l.ID),
+			Caption: l.Name,
+			Checked: checked[l.ID],
+		})
+	}
+
+	return lContent, nil
+}
+
+func setArticleLabel(req request, user content.User, service repo.Service) (interface{}, error) {
+	labelRepo := service.LabelRepo()
+
+	label, err := labelRepo.Get(feedLabelID(req.LabelId), user)
+	if err != nil {
+		if content.IsNoContent(err) {
+			return genericContent{Status: "OK", Updated: 0}, nil
+		}
+
+		return nil, errors.WithMessage(err, "getting user label")
+	}
+
+	if req.Assign {
+		err = labelRepo.Assign(label, req.ArticleIds)
+	} else {
+		err = labelRepo.Unassign(label, req.ArticleIds)
+	}
+
+	if err != nil {
+		return nil, errors.WithMessage(err, "setting article label")
+	}
+
+	return genericContent{Status: "OK", Updated: int64(len(req.ArticleIds))}, nil
+}
+
+// labelFeedID returns the virtual feed id of the label.
+func labelFeedID(id content.LabelID) content.FeedID {
+	return content.FeedID(LABEL_BASE_INDEX - 1 - int64(id))
+}
+
+// feedLabelID returns the id of the label behind a virtual feed id.
+func feedLabelID(id content.FeedID) content.LabelID {
+	return content.LabelID(LABEL_BASE_INDEX - 1 - int64(id))
+}
+
+func isLabelFeed(id content.FeedID) bool {
+	return id < LABEL_BASE_INDEX
+}
+
+// userLabels returns the user's labels, indexed by their id.
+func userLabels(service repo.Service, user content.User) (map[content.LabelID]content.Label, error) {
+	labels, err := service.LabelRepo().ForUser(user)
+	if err != nil {
+		return nil, errors.WithMessage(err, "getting user labels")
+	}
+
+	index := make(map[content.LabelID]content.Label, len(labels))
+	for _, l := range labels {
+		index[l.ID] = l
+	}
+
+	return index, nil
+}
+
+// userLabelIDs returns the ids of all of the user's labels.
+func userLabelIDs(service repo.Service, user content.User) ([]content.LabelID, error) {
+	labels, err := service.LabelRepo().ForUser(user)
+	if err != nil {
+		return nil, errors.WithMessage(err, "getting user labels")
+	}
+
+	ids := make([]content.LabelID, len(labels))
+	for i := range labels {
+		ids[i] = labels[i].ID
+	}
+
+	return ids, nil
+}
+
+// articleLabels returns the [feed id, caption, fg color, bg color] tuples
+// describing the article labels.
+func articleLabels(a content.Article, labels map[content.LabelID]content.Label) [][]interface{} {
+	var tuples [][]interface{}
+	for _, id := range a.Labels {
+		if l, ok := labels[id]; ok {
+			tuples = append(tuples, []interface{}{labelFeedID(l.ID), l.Name, "", ""})
+		}
+	}
+
+	return tuples
+}
+
+func init() {
+	actions["getLabels"] = getLabels
+	actions["setArticleLabel"] = setArticleLabel
+}
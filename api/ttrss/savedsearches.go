@@ -0,0 +1,210 @@
+package ttrss
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/urandom/readeef/content"
+	"github.com/urandom/readeef/content/repo"
+	"github.com/urandom/readeef/content/search"
+)
+
+// The maximum number of articles a saved search catchup marks as read, since
+// the search providers always limit their results.
+const savedSearchCatchupLimit = 1000
+
+// savedSearchFeedID returns the virtual feed id of the saved search.
+func savedSearchFeedID(id content.SavedSearchID) content.FeedID {
+	return content.FeedID(SAVED_SEARCH_BASE_INDEX - 1 - int64(id))
+}
+
+// feedSavedSearchID returns the id of the saved search behind a virtual feed
+// id.
+func feedSavedSearchID(id content.FeedID) content.SavedSearchID {
+	return content.SavedSearchID(SAVED_SEARCH_BASE_INDEX - 1 - int64(id))
+}
+
+func isSavedSearchFeed(id content.FeedID) bool {
+	return id < SAVED_SEARCH_BASE_INDEX && id > LABEL_BASE_INDEX
+}
+
+// userSavedSearches returns the user's saved searches whose virtual feed ids
+// don't reach into the label range. Without a search provider, there are
+// none.
+func userSavedSearches(service repo.Service, searchProvider search.Provider, user content.User) ([]content.SavedSearch, error) {
+	if searchProvider == nil {
+		return nil, nil
+	}
+
+	searches, err := service.SavedSearchRepo().ForUser(user)
+	if err != nil {
+		return nil, errors.WithMessage(err, "getting user saved searches")
+	}
+
+	var usable []content.SavedSearch
+	for _, s := range searches {
+		if isSavedSearchFeed(savedSearchFeedID(s.ID)) {
+			usable = append(usable, s)
+		}
+	}
+
+	return usable, nil
+}
+
+// feedSavedSearch returns the saved search behind the virtual feed id.
+func feedSavedSearch(
+	id content.FeedID,
+	user content.User,
+	service repo.Service,
+	searchProvider search.Provider,
+) (content.SavedSearch, error) {
+	if searchProvider == nil {
+		return content.SavedSearch{}, errors.WithStack(newErr("no search provider", "INCORRECT_USAGE"))
+	}
+
+	s, err := service.SavedSearchRepo().Get(feedSavedSearchID(id), user)
+	if err != nil {
+		return content.SavedSearch{}, errors.WithMessage(err, "getting user saved search")
+	}
+
+	return s, nil
+}
+
+func savedSearchUnread(
+	s content.SavedSearch,
+	user content.User,
+	service repo.Service,
+	searchProvider search.Provider,
+) (int64, error) {
+	count, err := search.CountSavedSearch(searchProvider, service, s, user,
+		content.UnreadOnly, content.Filters(content.GetUserFilters(user)),
+	)
+	if err != nil {
+		return 0, errors.WithMessage(err, "getting saved search unread count")
+	}
+
+	return count, nil
+}
+
+// savedSearchFeeds lists the user's saved searches as special feeds.
+func savedSearchFeeds(
+	req request,
+	user content.User,
+	service repo.Service,
+	searchProvider search.Provider,
+) (feedsContent, error) {
+	searches, err := userSavedSearches(service, searchProvider, user)
+	if err != nil {
+		return nil, err
+	}
+
+	fContent := feedsContent{}
+	for _, s := range searches {
+		unread, err := savedSearchUnread(s, user, service, searchProvider)
+		if err != nil {
+			return nil, err
+		}
+
+		if unread > 0 || !req.UnreadOnly {
+			fContent = append(fContent, feed{
+				Id:     savedSearchFeedID(s.ID),
+				Title:  s.Name,
+				Unread: unread,
+				CatId:  FAVORITE_ID,
+			})
+		}
+	}
+
+	return fContent, nil
+}
+
+// savedSearchCategoryFeeds returns the feed tree items of the user's saved
+// searches, listed in the special category.
+func savedSearchCategoryFeeds(
+	user content.User,
+	service repo.Service,
+	searchProvider search.Provider,
+) ([]category, error) {
+	searches, err := userSavedSearches(service, searchProvider, user)
+	if err != nil {
+		return nil, err
+	}
+
+	var items []category
+	for _, s := range searches {
+		id := savedSearchFeedID(s.ID)
+		item := category{BareId: id, Id: "FEED:" + strconv.FormatInt(int64(id), 10), Type: "feed", Name: s.Name}
+
+		if item.Unread, err = savedSearchUnread(s, user, service, searchProvider); err != nil {
+			return nil, err
+		}
+
+		items = append(items, item)
+	}
+
+	return items, nil
+}
+
+// savedSearchCounters returns the unread counters of the user's saved
+// searches.
+func savedSearchCounters(
+	user content.User,
+	service repo.Service,
+	searchProvider search.Provider,
+) (countersContent, error) {
+	searches, err := userSavedSearches(service, searchProvider, user)
+	if err != nil {
+		return nil, err
+	}
+
+	cContent := countersContent{}
+	for _, s := range searches {
+		unread, err := savedSearchUnread(s, user, service, searchProvider)
+		if err != nil {
+			return nil, err
+		}
+
+		cContent = append(cContent, counter{Id: int64(savedSearchFeedID(s.ID)), Counter: unread})
+	}
+
+	return cContent, nil
+}
+
+// catchupSavedSearch marks the unread articles found by the saved search
+// behind the virtual feed id as read, if they are older than the given time.
+func catchupSavedSearch(
+	req request,
+	user content.User,
+	service repo.Service,
+	searchProvider search.Provider,
+	before time.Time,
+) (interface{}, error) {
+	s, err := feedSavedSearch(req.FeedId, user, service, searchProvider)
+	if err != nil {
+		return nil, err
+	}
+
+	articles, err := search.SavedSearch(searchProvider, service, s, user,
+		content.UnreadOnly, content.Filters(content.GetUserFilters(user)),
+		content.TimeRange(time.Time{}, before), content.Paging(savedSearchCatchupLimit, 0),
+	)
+	if err != nil {
+		return nil, errors.WithMessage(err, "searching for saved search articles")
+	}
+
+	if len(articles) == 0 {
+		return genericContent{Status: "OK"}, nil
+	}
+
+	ids := make([]content.ArticleID, len(articles))
+	for i := range articles {
+		ids[i] = articles[i].ID
+	}
+
+	if err := service.ArticleRepo().Read(true, user, content.IDs(ids)); err != nil {
+		return nil, errors.WithMessage(err, "setting read state")
+	}
+
+	return genericContent{Status: "OK"}, nil
+}
@@ -1,6 +1,8 @@
 package ttrss
 
 import (
+	"net/url"
+	"strings"
 	"time"
 
 	"github.com/pkg/errors"
@@ -10,11 +12,21 @@ import (
 )
 
 type subscribeContent struct {
-	Status struct {
-		Code int `json:"code"`
-	} `json:"status"`
+	Status subscribeStatus `json:"status"`
 }
 
+type subscribeStatus struct {
+	Code    int    `json:"code"`
+	Message string `json:"message,omitempty"`
+}
+
+const (
+	SUBSCRIBE_EXISTS          = 0
+	SUBSCRIBE_ADDED           = 1
+	SUBSCRIBE_INVALID_URL     = 2
+	SUBSCRIBE_DOWNLOAD_FAILED = 5
+)
+
 func registerSettingActions(feedManager *readeef.FeedManager, update time.Duration) {
 	actions["getPref"] = func(req request, user content.User, service repo.Service) (interface{}, error) {
 		return getPref(req, user, update, service)
@@ -50,8 +62,25 @@ func getPref(
 	}
 }
 
+// shareToPublished adds an arbitrary link, with the content as its
+// description, to the user's published feed.
 func shareToPublished(req request, user content.User, service repo.Service) (interface{}, error) {
-	return nil, errors.WithStack(newErr("unsupported operation", "Publishing failed"))
+	publication := content.Publication{
+		UserLogin: user.Login, Title: req.Title, Link: req.Url, Description: req.Content,
+	}
+	if strings.TrimSpace(publication.Title) == "" {
+		publication.Title = publication.Link
+	}
+
+	if err := publication.Validate(); err != nil {
+		return nil, errors.WithStack(newErr(err.Error(), "Publishing failed"))
+	}
+
+	if err := service.PublicationRepo().Update(&publication); err != nil {
+		return nil, errors.WithMessage(err, "sharing link")
+	}
+
+	return genericContent{Status: "OK"}, nil
 }
 
 func subscribeToFeed(
@@ -60,12 +89,18 @@ func subscribeToFeed(
 	feedManager *readeef.FeedManager,
 	service repo.Service,
 ) (interface{}, error) {
+	if u, err := url.Parse(req.FeedUrl); err != nil || !u.IsAbs() || u.Scheme != "http" && u.Scheme != "https" {
+		return subscribeContent{Status: subscribeStatus{Code: SUBSCRIBE_INVALID_URL}}, nil
+	}
+
 	repo := service.FeedRepo()
 	feed, err := repo.FindByLink(req.FeedUrl)
 	if content.IsNoContent(err) {
 		feed, err = feedManager.AddFeedByLink(req.FeedUrl)
 		if err != nil {
-			return nil, errors.WithStack(newErr(err.Error(), "INCORRECT_USAGE"))
+			return subscribeContent{Status: subscribeStatus{
+				Code: SUBSCRIBE_DOWNLOAD_FAILED, Message: err.Error(),
+			}}, nil
 		}
 	} else {
 		if err != nil {
@@ -79,20 +114,34 @@ func subscribeToFeed(
 
 		for _, u := range users {
 			if u.Login == user.Login {
-				return subscribeContent{Status: struct {
-					Code int `json:"code"`
-				}{0}}, nil
+				return subscribeContent{Status: subscribeStatus{Code: SUBSCRIBE_EXISTS}}, nil
 			}
 		}
 	}
 
 	if err = repo.AttachTo(feed, user); err != nil {
+		if errors.Cause(err) == content.ErrPrivateFeed {
+			return subscribeContent{Status: subscribeStatus{
+				Code: SUBSCRIBE_DOWNLOAD_FAILED, Message: content.ErrPrivateFeed.Error(),
+			}}, nil
+		}
+
 		return nil, errors.WithMessage(err, "attaching feed to user")
 	}
 
-	return subscribeContent{Status: struct {
-		Code int `json:"code"`
-	}{1}}, nil
+	if req.CategoryId > 0 {
+		// An unknown category leaves the feed uncategorized
+		tag, err := service.TagRepo().Get(req.CategoryId, user)
+		if err == nil {
+			if err = repo.SetUserTags(feed, user, []*content.Tag{&tag}); err != nil {
+				return nil, errors.WithMessage(err, "setting feed category")
+			}
+		} else if !content.IsNoContent(err) {
+			return nil, errors.WithMessage(err, "getting user tag")
+		}
+	}
+
+	return subscribeContent{Status: subscribeStatus{Code: SUBSCRIBE_ADDED}}, nil
 }
 
 func unsubscribeFeed(
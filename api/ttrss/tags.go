@@ -1,7 +1,9 @@
 package ttrss
 
 import (
+	"sort"
 	"strconv"
+	"strings"
 
 	"github.com/pkg/errors"
 	"github.com/urandom/readeef/content"
@@ -76,19 +78,116 @@ func getCategories(req request, user content.User, service repo.Service) (interf
 		)
 	}
 
+	labels, err := service.LabelRepo().ForUser(user)
+	if err != nil {
+		return nil, errors.WithMessage(err, "getting user labels")
+	}
+
+	if len(labels) > 0 {
+		ids := make([]content.LabelID, len(labels))
+		for i := range labels {
+			ids[i] = labels[i].ID
+		}
+
+		count, err = articleRepo.Count(user,
+			content.UnreadOnly, content.LabelIDs(ids),
+			content.Filters(content.GetUserFilters(user)),
+		)
+		if err != nil {
+			return nil, errors.WithMessage(err, "getting unread labels count")
+		}
+
+		if count > 0 || !req.UnreadOnly {
+			cContent = append(cContent,
+				cat{Id: strconv.FormatInt(CAT_LABELS, 10), Title: "Labels", Unread: count},
+			)
+		}
+	}
+
 	return cContent, nil
 }
 
-func getLabels(req request, user content.User, service repo.Service) (interface{}, error) {
-	return []interface{}{}, nil
+// tagParents maps the id of each nested tag to the id of its closest
+// ancestor, where a tag containing a '/' is nested under the tag named after
+// the part before it. Top-level tags, and tags whose ancestors are not in
+// use, are not in the map.
+func tagParents(tags []content.Tag) map[content.TagID]content.TagID {
+	ids := make(map[string]content.TagID, len(tags))
+	for _, t := range tags {
+		ids[string(t.Value)] = t.ID
+	}
+
+	parents := map[content.TagID]content.TagID{}
+	for _, t := range tags {
+		path := string(t.Value)
+		for i := strings.LastIndex(path, "/"); i > 0; i = strings.LastIndex(path, "/") {
+			path = path[:i]
+			if id, ok := ids[path]; ok {
+				parents[t.ID] = id
+				break
+			}
+		}
+	}
+
+	return parents
+}
+
+// sortTags sorts the tags by their value, ignoring case.
+func sortTags(tags []content.Tag) {
+	sort.Slice(tags, func(i, j int) bool {
+		return strings.ToLower(string(tags[i].Value)) < strings.ToLower(string(tags[j].Value))
+	})
+}
+
+// nestedTagName returns the name of the tag relative to its parent.
+func nestedTagName(tag, parent content.Tag) string {
+	return strings.TrimPrefix(string(tag.Value), string(parent.Value)+"/")
 }
 
-func setArticleLabel(req request, user content.User, service repo.Service) (interface{}, error) {
-	return genericContent{Status: "OK", Updated: 0}, nil
+// categoryFeedIDs returns the ids of the feeds in the category, and if
+// requested, those in its nested categories.
+func categoryFeedIDs(
+	tag content.Tag,
+	nested bool,
+	user content.User,
+	service repo.Service,
+) ([]content.FeedID, error) {
+	tagRepo := service.TagRepo()
+
+	tags := []content.Tag{tag}
+	if nested {
+		all, err := tagRepo.ForUser(user)
+		if err != nil {
+			return nil, errors.WithMessage(err, "getting user tags")
+		}
+
+		prefix := string(tag.Value) + "/"
+		for _, t := range all {
+			if strings.HasPrefix(string(t.Value), prefix) {
+				tags = append(tags, t)
+			}
+		}
+	}
+
+	var ids []content.FeedID
+	seen := map[content.FeedID]bool{}
+	for _, t := range tags {
+		feedIDs, err := tagRepo.FeedIDs(t, user)
+		if err != nil {
+			return nil, errors.WithMessage(err, "getting tag feed ids")
+		}
+
+		for _, id := range feedIDs {
+			if !seen[id] {
+				seen[id] = true
+				ids = append(ids, id)
+			}
+		}
+	}
+
+	return ids, nil
 }
 
 func init() {
 	actions["getCategories"] = getCategories
-	actions["getLabels"] = getLabels
-	actions["setArticleLabel"] = setArticleLabel
 }
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+
+	"github.com/pkg/errors"
+	"github.com/urandom/readeef/config"
+	"github.com/urandom/readeef/content/repo/sql"
+	"github.com/urandom/readeef/content/retention"
+)
+
+var (
+	purgeDryRun  bool
+	purgeVerbose bool
+)
+
+func runPurge(config config.Config, args []string) error {
+	if purgeVerbose {
+		config.Log.Level = "debug"
+	}
+
+	log := initLog(config.Log)
+	service, err := sql.NewService(config.DB.Driver, config.DB.Connect, log)
+	if err != nil {
+		return errors.WithMessage(err, "creating content service")
+	}
+
+	purger := retention.New(config.Content, service, initSearchProvider(config.Content, service, log), log)
+	if !purger.Enabled() {
+		fmt.Println("No article retention limits configured")
+		return nil
+	}
+
+	results, err := purger.Purge(purgeDryRun)
+
+	total := 0
+	for _, r := range results {
+		total += len(r.Articles)
+		fmt.Printf("%s: %d articles\n", r.Feed, len(r.Articles))
+	}
+
+	if err != nil {
+		return errors.WithMessage(err, "purging articles")
+	}
+
+	if purgeDryRun {
+		fmt.Printf("%d articles would be purged\n", total)
+	} else {
+		fmt.Printf("%d articles purged\n", total)
+	}
+
+	return nil
+}
+
+func init() {
+	flags := flag.NewFlagSet("purge", flag.ExitOnError)
+	flags.BoolVar(&purgeDryRun, "dry-run", false, "only report the articles that would be purged")
+	flags.BoolVar(&purgeVerbose, "verbose", false, "verbose output")
+
+	commands = append(commands, Command{
+		Name:  "purge",
+		Desc:  "purge articles outside of the retention policy",
+		Flags: flags,
+		Run:   runPurge,
+	})
+}
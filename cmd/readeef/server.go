@@ -32,8 +32,11 @@ import (
 	"github.com/urandom/readeef/content/repo/eventable"
 	"github.com/urandom/readeef/content/repo/logging"
 	"github.com/urandom/readeef/content/repo/sql"
+	"github.com/urandom/readeef/content/retention"
 	"github.com/urandom/readeef/content/search"
 	"github.com/urandom/readeef/content/thumbnail"
+	"github.com/urandom/readeef/feed"
+	"github.com/urandom/readeef/fetch"
 	"github.com/urandom/readeef/log"
 	"github.com/urandom/readeef/popularity"
 	"github.com/urandom/readeef/web"
@@ -118,7 +121,9 @@ func runServer(cfg config.Config, args []string) error {
 		return errors.WithMessage(err, "initializing admin user")
 	}
 
-	feedManager := readeef.NewFeedManager(service.FeedRepo(), cfg, logger)
+	client := initHTTPClient(cfg.HTTP)
+
+	feedManager := readeef.NewFeedManager(service.FeedRepo(), service.FeedSettingsRepo(), service.FeedHealthRepo(), client, cfg, logger)
 
 	if processors, err := initFeedProcessors(cfg.FeedParser.Processors, cfg.FeedParser.ProxyHTTPURLTemplate, logger); err == nil {
 		for _, p := range processors {
@@ -130,7 +135,7 @@ func runServer(cfg config.Config, args []string) error {
 
 	searchProvider := initSearchProvider(cfg.Content, service, logger)
 
-	extractor, err := initArticleExtractor(cfg.Content, fs)
+	extractor, err := initArticleExtractor(cfg.Content, fs, client)
 	if err != nil {
 		return errors.WithMessage(err, "initializing content extract generator")
 	}
@@ -140,14 +145,16 @@ func runServer(cfg config.Config, args []string) error {
 		return errors.WithMessage(err, "initializing article processors")
 	}
 
-	thumbnailer, err := initThumbnailGenerator(service, cfg.Content, extractor, articleProcessors, logger)
+	thumbnailer, err := initThumbnailGenerator(service, cfg.Content, extractor, articleProcessors, client, logger)
 	if err != nil {
 		return errors.Wrap(err, "initializing thumbnail generator")
 	}
 
 	initPopularityScore(ctx, service, cfg.Popularity, logger)
 
-	initFeedMonitors(ctx, cfg.FeedManager, service, searchProvider, thumbnailer, logger)
+	retention.New(cfg.Content, service, searchProvider, logger).Schedule(ctx)
+
+	initFeedMonitors(ctx, cfg.FeedManager, service, searchProvider, extractor, thumbnailer, client, logger)
 
 	hubbub, err := initHubbub(cfg, service, feedManager, logger)
 	if err != nil {
@@ -158,7 +165,9 @@ func runServer(cfg config.Config, args []string) error {
 		feedManager.SetHubbub(hubbub)
 	}
 
-	handler, err = api.Mux(ctx, service, feedManager, searchProvider, extractor, fs, articleProcessors, cfg, logger, accessMiddleware)
+	hub := initHub(ctx, cfg, service, client, logger)
+
+	handler, err = api.Mux(ctx, service, feedManager, hub, searchProvider, extractor, fs, client, articleProcessors, cfg, logger, accessMiddleware)
 	if err != nil {
 		return errors.WithMessage(err, "creating api mux")
 	}
@@ -359,10 +368,20 @@ func initSearchProvider(config config.Content, service repo.Service, log log.Log
 	return searchProvider
 }
 
-func initArticleExtractor(config config.Content, fs http.FileSystem) (extract.Generator, error) {
+// initHTTPClient creates the client shared by everything that contacts the
+// remote sites, so that the per-host limits apply to all of their requests.
+func initHTTPClient(config config.HTTP) *http.Client {
+	return fetch.NewClient(fetch.Limits{
+		Concurrency:   config.HostConcurrency,
+		Interval:      config.Converted.HostInterval,
+		MaxRetryAfter: config.Converted.MaxRetryAfter,
+	}, config.Converted.Timeout)
+}
+
+func initArticleExtractor(config config.Content, fs http.FileSystem, client *http.Client) (extract.Generator, error) {
 	switch config.Extract.Generator {
 	case "readability":
-		if ce, err := extract.WithReadability(config.Extract.ReadabilityKey); err == nil {
+		if ce, err := extract.WithReadability(config.Extract.ReadabilityKey, client); err == nil {
 			return ce, nil
 		} else {
 			return nil, errors.WithMessage(err, "initializing Readability extract generator")
@@ -370,7 +389,7 @@ func initArticleExtractor(config config.Content, fs http.FileSystem) (extract.Ge
 	case "goose":
 		fallthrough
 	default:
-		if ce, err := extract.WithGoose("templates", fs); err == nil {
+		if ce, err := extract.WithGoose("templates", fs, client); err == nil {
 			return ce, nil
 		} else {
 			return nil, errors.WithMessage(err, "initializing Goose extract generator")
@@ -383,12 +402,13 @@ func initThumbnailGenerator(
 	config config.Content,
 	extract extract.Generator,
 	processors []processor.Article,
+	client *http.Client,
 	log log.Log,
 ) (thumbnail.Generator, error) {
 
 	switch config.Thumbnail.Generator {
 	case "extract":
-		if t, err := thumbnail.FromExtract(service.ThumbnailRepo(), service.ExtractRepo(), extract, processors, config.Thumbnail.Store, log); err == nil {
+		if t, err := thumbnail.FromExtract(service.ThumbnailRepo(), service.ExtractRepo(), extract, processors, client, config.Thumbnail.Store, log); err == nil {
 			return t, nil
 		} else {
 			return nil, errors.WithMessage(err, "initializing Extract thumbnail generator")
@@ -396,7 +416,7 @@ func initThumbnailGenerator(
 	case "description":
 		fallthrough
 	default:
-		return thumbnail.FromDescription(service.ThumbnailRepo(), config.Thumbnail.Store, log), nil
+		return thumbnail.FromDescription(service.ThumbnailRepo(), client, config.Thumbnail.Store, log), nil
 	}
 }
 
@@ -409,11 +429,14 @@ func initFeedMonitors(
 	config config.FeedManager,
 	service eventable.Service,
 	searchProvider search.Provider,
+	extractor extract.Generator,
 	thumbnailer thumbnail.Generator,
+	client *http.Client,
 	log log.Log,
 ) {
 	go monitor.Unread(ctx, service, log)
 	go monitor.UserFilters(service, log)
+	go monitor.Extractor(service, extractor, log)
 
 	for _, m := range config.Monitors {
 		switch m {
@@ -425,6 +448,9 @@ func initFeedMonitors(
 			if thumbnailer != nil {
 				go monitor.Thumbnailer(service, thumbnailer, log)
 			}
+		case "icons":
+			icons := feed.NewIconCache(service.FeedImageRepo(), config.Converted.IconRefreshInterval, client, log)
+			go monitor.Icons(service, icons, log)
 		}
 	}
 }
@@ -448,6 +474,30 @@ func initHubbub(
 	return nil, nil
 }
 
+func initHub(
+	ctx context.Context,
+	config config.Config,
+	service eventable.Service,
+	client *http.Client,
+	log log.Log,
+) *readeef.Hub {
+	if !config.Hub.Enabled {
+		return nil
+	}
+
+	if config.Hub.BaseURL == "" {
+		log.Printf("The WebSub hub is enabled, but its base-url is not set")
+		return nil
+	}
+
+	hub := readeef.NewHub(service, client, config, log)
+	hub.Start(ctx)
+
+	go monitor.Hub(service, hub, log)
+
+	return hub
+}
+
 func makeHTTPServer(mux http.Handler) *http.Server {
 	return &http.Server{
 		ReadTimeout: 5 * time.Second,
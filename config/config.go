@@ -14,9 +14,11 @@ type Config struct {
 	Log         Log         `toml:"log"`
 	API         API         `toml:"api"`
 	Timeout     Timeout     `toml:"timeout"`
+	HTTP        HTTP        `toml:"http"`
 	DB          DB          `toml:"db"`
 	Auth        Auth        `toml:"auth"`
 	Hubbub      Hubbub      `toml:"hubbub"`
+	Hub         Hub         `toml:"hub"`
 	Popularity  Popularity  `toml:"popularity"`
 	FeedParser  FeedParser  `toml:"feed-parser"`
 	FeedManager FeedManager `toml:"feed-manager"`
@@ -37,7 +39,7 @@ func Read(path string) (Config, error) {
 		return Config{}, err
 	}
 
-	for _, c := range []converter{&c.API, &c.Log, &c.Timeout, &c.FeedManager, &c.Popularity, &c.Content} {
+	for _, c := range []converter{&c.API, &c.Log, &c.Timeout, &c.HTTP, &c.Hubbub, &c.Hub, &c.FeedManager, &c.Popularity, &c.Content} {
 		c.Convert()
 	}
 
@@ -30,7 +30,7 @@ var DefaultCfg = `
 	formatter = "text" # text, json
 	access-file = ""   # stdout or a filename
 [api]
-	emulators = []     # ["tt-rss", "fever"]
+	emulators = []     # ["tt-rss", "fever", "greader", "nextcloud-news"]
 [api.limits]
 	articles-per-query = 200
 [db]
@@ -41,12 +41,28 @@ var DefaultCfg = `
 	token-storage-path = "./storage/token.db"
 [feed-manager]
 	update-interval = "30m"
-	monitors = ["index", "thumbnailer"]
+	min-update-interval = "10m"
+	max-update-interval = "12h"
+	update-workers = 10
+	icon-refresh-interval = "168h"
+	dead-after = "720h"
+	monitors = ["index", "thumbnailer", "icons"]
 [timeout]
 	connect = "1s"
 	read-write = "2s"
+[http]
+	timeout = "30s"
+	host-concurrency = 2
+	host-interval = "1s"
+	max-retry-after = "6h"
 [hubbub]
 	from = "readeef"
+	silence-timeout = "24h"
+[hub]
+	enabled = false
+	# base-url = "https://readeef.example.com/api"
+	check-interval = "5m"
+	lease-duration = "240h"
 [popularity]
 	delay = "5s"
 	# providers = ["Reddit", "Twitter"]
@@ -65,6 +81,11 @@ var DefaultCfg = `
 	proxy-http-url-template = "/proxy?url={{ . }}"
 [content.thumbnail]
 	store = true
+[content.retention]
+	# max-age = "2160h"
+	# max-count = 1000
+	min-keep = 50
+	interval = "24h"
 [ui]
 	path = "./rf-ng/ui"
 `
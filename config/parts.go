@@ -3,6 +3,7 @@ package config
 import (
 	"io"
 	"os"
+	"strings"
 	"time"
 
 	lumberjack "gopkg.in/natefinch/lumberjack.v2"
@@ -54,6 +55,25 @@ type Timeout struct {
 	} `toml:"-"`
 }
 
+// HTTP configures the client used for the outbound requests to remote sites,
+// such as fetching feeds, icons, thumbnails and article extracts.
+type HTTP struct {
+	Timeout string `toml:"timeout"`
+	// HostConcurrency caps the number of simultaneous requests to a host.
+	HostConcurrency int `toml:"host-concurrency"`
+	// HostInterval is the minimum time between two requests to a host.
+	HostInterval string `toml:"host-interval"`
+	// A host responding with a Retry-After header isn't contacted for the
+	// requested time, up to MaxRetryAfter.
+	MaxRetryAfter string `toml:"max-retry-after"`
+
+	Converted struct {
+		Timeout       time.Duration
+		HostInterval  time.Duration
+		MaxRetryAfter time.Duration
+	} `toml:"-"`
+}
+
 type DB struct {
 	Driver  string `toml:"driver"`
 	Connect string `toml:"connect"`
@@ -68,6 +88,31 @@ type Auth struct {
 type Hubbub struct {
 	CallbackURL string `toml:"callback-url"` // http://www.example.com
 	From        string `toml:"from"`
+
+	// A subscription whose hub hasn't been heard from for this long is
+	// considered silent, and its feed is polled as usual.
+	SilenceTimeout string `toml:"silence-timeout"`
+
+	Converted struct {
+		SilenceTimeout time.Duration
+	} `toml:"-"`
+}
+
+// Hub configures the built-in WebSub hub, which pushes the outbound feeds to
+// their subscribers.
+type Hub struct {
+	Enabled bool `toml:"enabled"`
+	// BaseURL is the URL of the API, as reachable by the subscribers, such
+	// as "https://readeef.example.com/api". Only the feeds under it may be
+	// subscribed to, and the hub is not started without it.
+	BaseURL       string `toml:"base-url"`
+	CheckInterval string `toml:"check-interval"`
+	LeaseDuration string `toml:"lease-duration"`
+
+	Converted struct {
+		CheckInterval time.Duration
+		LeaseDuration time.Duration
+	} `toml:"-"`
 }
 
 type Popularity struct {
@@ -100,12 +145,27 @@ type FeedParser struct {
 }
 
 type FeedManager struct {
-	UpdateInterval string `toml:"update-interval"`
+	UpdateInterval    string `toml:"update-interval"`
+	MinUpdateInterval string `toml:"min-update-interval"`
+	MaxUpdateInterval string `toml:"max-update-interval"`
+	// UpdateWorkers limits the number of feeds that are updated at once.
+	UpdateWorkers int `toml:"update-workers"`
+
+	IconRefreshInterval string `toml:"icon-refresh-interval"`
+
+	// DeadAfter is how long a feed may fail to update before it is marked as
+	// dead, and no longer updated. Feeds are never marked as dead for their
+	// failures when it is "0".
+	DeadAfter string `toml:"dead-after"`
 
 	Monitors []string `toml:"monitors"`
 
 	Converted struct {
-		UpdateInterval time.Duration
+		UpdateInterval      time.Duration
+		MinUpdateInterval   time.Duration
+		MaxUpdateInterval   time.Duration
+		IconRefreshInterval time.Duration
+		DeadAfter           time.Duration
 	} `toml:"-"`
 }
 
@@ -132,6 +192,30 @@ type Content struct {
 		ProxyHTTPURLTemplate string   `toml:"proxy-http-url-template"`
 	} `toml:"article"`
 
+	Retention struct {
+		MaxAge   string `toml:"max-age"`
+		MaxCount int    `toml:"max-count"`
+		MinKeep  int    `toml:"min-keep"`
+		Interval string `toml:"interval"`
+
+		// Per-feed overrides, matched by the feed link. An override
+		// replaces both global limits, a zero value disabling the limit.
+		Feeds []struct {
+			Link     string `toml:"link"`
+			MaxAge   string `toml:"max-age"`
+			MaxCount int    `toml:"max-count"`
+
+			Converted struct {
+				MaxAge time.Duration
+			} `toml:"-"`
+		} `toml:"feed"`
+
+		Converted struct {
+			MaxAge   time.Duration
+			Interval time.Duration
+		} `toml:"-"`
+	} `toml:"retention"`
+
 	// deprecated
 	ThumbnailGenerator string `toml:"thumbnail-generator"`
 }
@@ -175,6 +259,54 @@ func (c *Timeout) Convert() {
 	}
 }
 
+func (c *HTTP) Convert() {
+	if d, err := time.ParseDuration(c.Timeout); err == nil && d > 0 {
+		c.Converted.Timeout = d
+	} else {
+		c.Converted.Timeout = 30 * time.Second
+	}
+
+	if c.HostConcurrency <= 0 {
+		c.HostConcurrency = 2
+	}
+
+	if d, err := time.ParseDuration(c.HostInterval); err == nil && d >= 0 {
+		c.Converted.HostInterval = d
+	} else {
+		c.Converted.HostInterval = time.Second
+	}
+
+	if d, err := time.ParseDuration(c.MaxRetryAfter); err == nil && d >= 0 {
+		c.Converted.MaxRetryAfter = d
+	} else {
+		c.Converted.MaxRetryAfter = 6 * time.Hour
+	}
+}
+
+func (c *Hubbub) Convert() {
+	if d, err := time.ParseDuration(c.SilenceTimeout); err == nil && d > 0 {
+		c.Converted.SilenceTimeout = d
+	} else {
+		c.Converted.SilenceTimeout = 24 * time.Hour
+	}
+}
+
+func (c *Hub) Convert() {
+	c.BaseURL = strings.TrimSuffix(c.BaseURL, "/")
+
+	if d, err := time.ParseDuration(c.CheckInterval); err == nil && d > 0 {
+		c.Converted.CheckInterval = d
+	} else {
+		c.Converted.CheckInterval = 5 * time.Minute
+	}
+
+	if d, err := time.ParseDuration(c.LeaseDuration); err == nil && d > 0 {
+		c.Converted.LeaseDuration = d
+	} else {
+		c.Converted.LeaseDuration = 10 * 24 * time.Hour
+	}
+}
+
 func (c *Popularity) Convert() {
 	if d, err := time.ParseDuration(c.Delay); err == nil {
 		c.Converted.Delay = d
@@ -189,6 +321,34 @@ func (c *FeedManager) Convert() {
 	} else {
 		c.Converted.UpdateInterval = 30 * time.Minute
 	}
+
+	if d, err := time.ParseDuration(c.MinUpdateInterval); err == nil {
+		c.Converted.MinUpdateInterval = d
+	} else {
+		c.Converted.MinUpdateInterval = 10 * time.Minute
+	}
+
+	if d, err := time.ParseDuration(c.MaxUpdateInterval); err == nil {
+		c.Converted.MaxUpdateInterval = d
+	} else {
+		c.Converted.MaxUpdateInterval = 12 * time.Hour
+	}
+
+	if c.UpdateWorkers <= 0 {
+		c.UpdateWorkers = 10
+	}
+
+	if d, err := time.ParseDuration(c.IconRefreshInterval); err == nil {
+		c.Converted.IconRefreshInterval = d
+	} else {
+		c.Converted.IconRefreshInterval = 7 * 24 * time.Hour
+	}
+
+	if d, err := time.ParseDuration(c.DeadAfter); err == nil {
+		c.Converted.DeadAfter = d
+	} else {
+		c.Converted.DeadAfter = 30 * 24 * time.Hour
+	}
 }
 
 func (c *Content) Convert() {
@@ -199,4 +359,20 @@ func (c *Content) Convert() {
 			c.Thumbnail.Generator = "description"
 		}
 	}
+
+	if d, err := time.ParseDuration(c.Retention.MaxAge); err == nil {
+		c.Retention.Converted.MaxAge = d
+	}
+
+	if d, err := time.ParseDuration(c.Retention.Interval); err == nil && d > 0 {
+		c.Retention.Converted.Interval = d
+	} else {
+		c.Retention.Converted.Interval = 24 * time.Hour
+	}
+
+	for i := range c.Retention.Feeds {
+		if d, err := time.ParseDuration(c.Retention.Feeds[i].MaxAge); err == nil {
+			c.Retention.Feeds[i].Converted.MaxAge = d
+		}
+	}
 }
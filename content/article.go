@@ -20,13 +20,22 @@ type Article struct {
 	Description string    `json:"description"`
 	Link        string    `json:"link"`
 	Date        time.Time `json:"date"`
+	Author      string    `json:"author,omitempty"`
+
+	Categories []string  `db:"-" json:"categories,omitempty"`
+	Labels     []LabelID `db:"-" json:"labels,omitempty"`
+	Note       string    `db:"-" json:"note,omitempty"`
 
 	Read          bool   `json:"read"`
 	Favorite      bool   `json:"favorite"`
+	Hidden        bool   `json:"hidden,omitempty"`
+	Published     bool   `json:"published,omitempty"`
 	Score         int64  `json:"score,omitempty"`
 	Thumbnail     string `json:"thumbnail,omitempty"`
 	ThumbnailLink string `db:"thumbnail_link" json:"thumbnailLink,omitempty"`
 
+	Media []Media `db:"-" json:"media,omitempty"`
+
 	IsNew bool `json:"-"`
 
 	Hit struct {
@@ -62,24 +71,35 @@ type QueryOpt struct {
 
 // QueryOptions is the full range of options for querying articles.
 type QueryOptions struct {
-	Limit           int
-	Offset          int
-	ReadOnly        bool
-	UnreadOnly      bool
-	UnreadFirst     bool
-	FavoriteOnly    bool
-	UntaggedOnly    bool
-	IncludeScores   bool
-	HighScoredFirst bool
-	BeforeID        ArticleID
-	AfterID         ArticleID
-	BeforeDate      time.Time
-	AfterDate       time.Time
-	BeforeScore     int64
-	AfterScore      int64
-	IDs             []ArticleID
-	FeedIDs         []FeedID
-	Filters         []Filter
+	Limit             int
+	Offset            int
+	ReadOnly          bool
+	UnreadOnly        bool
+	UnreadFirst       bool
+	FavoriteOnly      bool
+	HiddenOnly        bool
+	PublishedOnly     bool
+	UntaggedOnly      bool
+	IncludeScores     bool
+	IncludeMedia      bool
+	IncludeCategories bool
+	IncludeHidden     bool
+	IncludeLabels     bool
+	IncludeNotes      bool
+	HighScoredFirst   bool
+	BeforeID          ArticleID
+	AfterID           ArticleID
+	BeforeDate        time.Time
+	AfterDate         time.Time
+	BeforeScore       int64
+	AfterScore        int64
+	IDs               []ArticleID
+	FeedIDs           []FeedID
+	Authors           []string
+	Categories        []string
+	LabelIDs          []LabelID
+	ChangedSince      time.Time
+	Filters           []Filter
 
 	SortField sortingField
 	SortOrder sortingOrder
@@ -129,6 +149,38 @@ func FeedIDs(ids []FeedID) QueryOpt {
 	}}
 }
 
+// Authors limits the query to articles written by any of the specified
+// authors.
+func Authors(authors []string) QueryOpt {
+	return QueryOpt{func(o *QueryOptions) {
+		o.Authors = authors
+	}}
+}
+
+// Categories limits the query to articles in any of the specified
+// categories.
+func Categories(categories []string) QueryOpt {
+	return QueryOpt{func(o *QueryOptions) {
+		o.Categories = categories
+	}}
+}
+
+// LabelIDs limits the query to articles the user has marked with any of the
+// specified labels.
+func LabelIDs(ids []LabelID) QueryOpt {
+	return QueryOpt{func(o *QueryOptions) {
+		o.LabelIDs = ids
+	}}
+}
+
+// ChangedSince limits the query to articles that have an entry in the user's
+// change log after the given time.
+func ChangedSince(t time.Time) QueryOpt {
+	return QueryOpt{func(o *QueryOptions) {
+		o.ChangedSince = t
+	}}
+}
+
 // TimeRange sets the minimum and maximum times of returned articles.
 func TimeRange(after, before time.Time) QueryOpt {
 	return QueryOpt{func(o *QueryOptions) {
@@ -181,6 +233,17 @@ var (
 		o.FavoriteOnly = true
 	}}
 
+	// HiddenOnly sets the query for hidden articles. Hidden articles are
+	// otherwise excluded from user queries.
+	HiddenOnly = QueryOpt{func(o *QueryOptions) {
+		o.HiddenOnly = true
+	}}
+
+	// PublishedOnly sets the query for articles the user has published.
+	PublishedOnly = QueryOpt{func(o *QueryOptions) {
+		o.PublishedOnly = true
+	}}
+
 	// UntaggedOnly sets the query for untagged articles.
 	UntaggedOnly = QueryOpt{func(o *QueryOptions) {
 		o.UntaggedOnly = true
@@ -191,6 +254,32 @@ var (
 		o.IncludeScores = true
 	}}
 
+	// IncludeMedia sets the query to return articles' media objects.
+	IncludeMedia = QueryOpt{func(o *QueryOptions) {
+		o.IncludeMedia = true
+	}}
+
+	// IncludeCategories sets the query to return articles' categories.
+	IncludeCategories = QueryOpt{func(o *QueryOptions) {
+		o.IncludeCategories = true
+	}}
+
+	// IncludeHidden sets the query to also return hidden articles.
+	IncludeHidden = QueryOpt{func(o *QueryOptions) {
+		o.IncludeHidden = true
+	}}
+
+	// IncludeLabels sets the query to return the ids of the user's labels
+	// on each article.
+	IncludeLabels = QueryOpt{func(o *QueryOptions) {
+		o.IncludeLabels = true
+	}}
+
+	// IncludeNotes sets the query to return the user's note on each article.
+	IncludeNotes = QueryOpt{func(o *QueryOptions) {
+		o.IncludeNotes = true
+	}}
+
 	// HighScoredFirst sets the query to return articles with high scores first.
 	HighScoredFirst = QueryOpt{func(o *QueryOptions) {
 		o.HighScoredFirst = true
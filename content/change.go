@@ -0,0 +1,49 @@
+package content
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"time"
+)
+
+type ChangeID int64
+
+// ChangeType is the kind of change an article went through for a user.
+type ChangeType string
+
+const (
+	ChangeInsert     ChangeType = "insert"
+	ChangeRead       ChangeType = "read"
+	ChangeUnread     ChangeType = "unread"
+	ChangeFavorite   ChangeType = "favorite"
+	ChangeUnfavorite ChangeType = "unfavorite"
+)
+
+// ArticleChange is an entry in a user's article change log. Insert changes
+// are logged when new articles are added to a subscribed feed, while the
+// rest are logged whenever the article state actually changes.
+type ArticleChange struct {
+	ID        ChangeID   `json:"id"`
+	ArticleID ArticleID  `db:"article_id" json:"articleID"`
+	Type      ChangeType `db:"change_type" json:"type"`
+	Date      time.Time  `db:"change_date" json:"date"`
+}
+
+func (c ArticleChange) String() string {
+	return fmt.Sprintf("%d: %s %d", c.ID, c.Type, c.ArticleID)
+}
+
+func (id *ChangeID) Scan(src interface{}) error {
+	asInt, ok := src.(int64)
+	if !ok {
+		return fmt.Errorf("Scan source '%#v' (%T) was not of type int64 (ChangeID)", src, src)
+	}
+
+	*id = ChangeID(asInt)
+
+	return nil
+}
+
+func (id ChangeID) Value() (driver.Value, error) {
+	return int64(id), nil
+}
@@ -4,6 +4,10 @@ import "github.com/pkg/errors"
 
 var (
 	ErrNoContent = errors.New("No content")
+
+	// ErrPrivateFeed is returned when attaching a feed that is fetched with
+	// the credentials of another user.
+	ErrPrivateFeed = errors.New("Feed is private")
 )
 
 func IsNoContent(err error) bool {
@@ -21,16 +21,20 @@ const (
 
 type goose struct {
 	template *template.Template
+	client   *http.Client
 	buf      bytes.Buffer
 }
 
-func WithGoose(templateDir string, fs http.FileSystem) (Generator, error) {
+// Some sites only serve their content to browsers
+const gooseUserAgent = "Mozilla/5.0 (Macintosh; Intel Mac OS X 10.16; rv:85.0) Gecko/20100101 Firefox/85.0"
+
+func WithGoose(templateDir string, fs http.FileSystem, client *http.Client) (Generator, error) {
 	tmpl, err := prepareTemplate(template.New("goose").Delims("{%", "%}"), fs, rawTmpl, gooseTmpl)
 	if err != nil {
 		return nil, errors.Wrap(err, "parsing goose template")
 	}
 
-	return goose{template: tmpl}, nil
+	return goose{template: tmpl, client: client}, nil
 }
 
 func (e goose) Generate(link string) (extract content.Extract, err error) {
@@ -40,9 +44,14 @@ func (e goose) Generate(link string) (extract content.Extract, err error) {
 		}
 	}()
 
+	raw, err := e.fetch(link)
+	if err != nil {
+		return extract, err
+	}
+
 	g := goOse.New()
 	/* TODO: preserve links */
-	formatted, err := g.ExtractFromURL(link)
+	formatted, err := g.ExtractFromRawHTML(raw, link)
 	if err != nil {
 		return extract, errors.Wrapf(err, "extracting from url: %s", link)
 	}
@@ -73,6 +82,31 @@ func (e goose) Generate(link string) (extract content.Extract, err error) {
 	return extract, err
 }
 
+func (e goose) fetch(link string) (string, error) {
+	req, err := http.NewRequest("GET", link, nil)
+	if err != nil {
+		return "", errors.Wrapf(err, "creating request for url: %s", link)
+	}
+	req.Header.Set("User-Agent", gooseUserAgent)
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return "", errors.Wrapf(err, "getting url: %s", link)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", errors.Errorf("getting url: %s: %s", link, resp.Status)
+	}
+
+	b, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return "", errors.Wrapf(err, "reading url: %s", link)
+	}
+
+	return string(b), nil
+}
+
 func prepareTemplate(t *template.Template, fs http.FileSystem, paths ...string) (*template.Template, error) {
 	for _, path := range paths {
 		f, err := fs.Open(path)
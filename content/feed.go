@@ -14,19 +14,26 @@ import (
 type FeedID int64
 
 type Feed struct {
-	ID             FeedID          `json:"id"`
-	Title          string          `json:"title"`
-	Description    string          `json:"description"`
-	Link           string          `json:"link"`
-	SiteLink       string          `db:"site_link" json:"-"`
-	HubLink        string          `db:"hub_link" json:"-"`
-	UpdateError    string          `db:"update_error" json:"updateError"`
-	SubscribeError string          `db:"subscribe_error" json:"subscribeError"`
-	TTL            time.Duration   `json:"-"`
-	SkipHours      map[int]bool    `json:"-"`
-	SkipDays       map[string]bool `json:"-"`
+	ID             FeedID    `json:"id"`
+	Title          string    `json:"title"`
+	Description    string    `json:"description"`
+	Link           string    `json:"link"`
+	SiteLink       string    `db:"site_link" json:"-"`
+	HubLink        string    `db:"hub_link" json:"-"`
+	UpdateError    string    `db:"update_error" json:"updateError"`
+	SubscribeError string    `db:"subscribe_error" json:"subscribeError"`
+	ETag           string    `db:"etag" json:"-"`
+	LastModified   string    `db:"last_modified" json:"-"`
+	NextUpdate     time.Time `db:"next_update" json:"-"`
+	// Dead feeds are gone, or have been failing for too long, and are no
+	// longer updated.
+	Dead      bool            `json:"dead"`
+	TTL       time.Duration   `json:"-"`
+	SkipHours map[int]bool    `json:"-"`
+	SkipDays  map[string]bool `json:"-"`
 
 	parsedArticles []Article
+	parsedImage    FeedImage
 }
 
 func (f Feed) Validate() error {
@@ -52,6 +59,14 @@ func (f *Feed) Refresh(pf parser.Feed) {
 	f.HubLink = pf.HubLink
 	f.UpdateError = ""
 
+	f.parsedImage = FeedImage{
+		FeedID: f.ID,
+		Title:  pf.Image.Title,
+		URL:    pf.Image.Url,
+		Width:  pf.Image.Width,
+		Height: pf.Image.Height,
+	}
+
 	f.parsedArticles = make([]Article, len(pf.Articles))
 
 	for i := range pf.Articles {
@@ -60,6 +75,8 @@ func (f *Feed) Refresh(pf parser.Feed) {
 			Description: pf.Articles[i].Description,
 			Link:        pf.Articles[i].Link,
 			Date:        pf.Articles[i].Date,
+			Author:      pf.Articles[i].Author,
+			Categories:  pf.Articles[i].Categories,
 		}
 		a.FeedID = f.ID
 
@@ -68,6 +85,19 @@ func (f *Feed) Refresh(pf parser.Feed) {
 			a.Guid.String = pf.Articles[i].Guid
 		}
 
+		for _, e := range pf.Articles[i].Enclosures {
+			a.Media = append(a.Media, Media{
+				Link:     e.Link,
+				Type:     e.Type,
+				Medium:   e.Medium,
+				Title:    e.Title,
+				Length:   e.Length,
+				Duration: int64(e.Duration.Seconds()),
+				Width:    e.Width,
+				Height:   e.Height,
+			})
+		}
+
 		f.parsedArticles[i] = a
 	}
 }
@@ -76,6 +106,11 @@ func (f Feed) ParsedArticles() (a []Article) {
 	return f.parsedArticles
 }
 
+// ParsedImage returns the image found during the last Refresh.
+func (f Feed) ParsedImage() FeedImage {
+	return f.parsedImage
+}
+
 func (f Feed) String() string {
 	return fmt.Sprintf("%d: %s", f.ID, f.Title)
 }
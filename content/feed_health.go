@@ -0,0 +1,116 @@
+package content
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// FetchErrorClass groups the failed fetches of a feed by their cause.
+type FetchErrorClass string
+
+const (
+	// The feed host could not be reached.
+	FetchErrorNetwork FetchErrorClass = "network"
+	// The feed host did not respond in time.
+	FetchErrorTimeout FetchErrorClass = "timeout"
+	// The feed host responded with an unexpected HTTP status.
+	FetchErrorHTTP FetchErrorClass = "http"
+	// The feed host asked for the fetches to be retried later.
+	FetchErrorRateLimited FetchErrorClass = "rate-limited"
+	// The response could not be parsed as a feed.
+	FetchErrorParse FetchErrorClass = "parse"
+)
+
+// The number of fetches kept in the history of each feed.
+const FeedFetchHistorySize = 100
+
+// FeedFetch records a single fetch of a feed.
+type FeedFetch struct {
+	FeedID FeedID    `db:"feed_id" json:"-"`
+	Time   time.Time `db:"fetch_time" json:"time"`
+	// StatusCode is 0 when the fetch failed before receiving a response.
+	StatusCode  int             `db:"status_code" json:"statusCode"`
+	Duration    time.Duration   `json:"-"`
+	Bytes       int64           `json:"bytes"`
+	NewArticles int             `db:"new_articles" json:"newArticles"`
+	ErrorClass  FetchErrorClass `db:"error_class" json:"errorClass,omitempty"`
+	Error       string          `json:"error,omitempty"`
+}
+
+// FeedHealth summarizes the fetches of a feed.
+type FeedHealth struct {
+	FeedID      FeedID    `db:"feed_id" json:"feedID"`
+	LastFetch   time.Time `db:"last_fetch" json:"lastFetch"`
+	LastSuccess time.Time `db:"last_success" json:"lastSuccess"`
+	// FailingSince is the time of the first of the consecutive failed
+	// fetches, and Failures their number.
+	FailingSince time.Time `db:"failing_since" json:"failingSince"`
+	Failures     int       `json:"failures"`
+
+	LastStatusCode int             `db:"last_status_code" json:"lastStatusCode"`
+	LastErrorClass FetchErrorClass `db:"last_error_class" json:"lastErrorClass,omitempty"`
+	LastError      string          `db:"last_error" json:"lastError,omitempty"`
+}
+
+func (f FeedFetch) Validate() error {
+	if f.FeedID == 0 {
+		return NewValidationError(errors.New("Feed fetch has no feed id"))
+	}
+
+	if f.Time.IsZero() {
+		return NewValidationError(errors.New("Feed fetch has no time"))
+	}
+
+	return nil
+}
+
+// Failed returns whether the fetch has failed.
+func (f FeedFetch) Failed() bool {
+	return f.ErrorClass != ""
+}
+
+func (f FeedFetch) String() string {
+	return fmt.Sprintf("fetch of feed %d at %s", f.FeedID, f.Time.Format(time.RFC3339))
+}
+
+func (f FeedFetch) MarshalJSON() ([]byte, error) {
+	type fetch FeedFetch
+
+	return json.Marshal(struct {
+		fetch
+		Duration int64 `json:"duration"`
+	}{fetch(f), int64(f.Duration / time.Millisecond)})
+}
+
+// Record returns the health after the given fetch.
+func (h FeedHealth) Record(f FeedFetch) FeedHealth {
+	h.FeedID = f.FeedID
+	h.LastFetch = f.Time
+	h.LastStatusCode = f.StatusCode
+	h.LastErrorClass = f.ErrorClass
+	h.LastError = f.Error
+
+	if f.Failed() {
+		if h.Failures == 0 {
+			h.FailingSince = f.Time
+		}
+		h.Failures++
+	} else {
+		h.LastSuccess = f.Time
+		h.FailingSince = time.Time{}
+		h.Failures = 0
+	}
+
+	return h
+}
+
+// Failing returns whether the last fetch of the feed has failed.
+func (h FeedHealth) Failing() bool {
+	return h.Failures > 0
+}
+
+func (h FeedHealth) String() string {
+	return fmt.Sprintf("health of feed %d", h.FeedID)
+}
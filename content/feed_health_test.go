@@ -0,0 +1,77 @@
+package content_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/urandom/readeef/content"
+)
+
+func TestFeedFetch_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		fetch   content.FeedFetch
+		wantErr bool
+	}{
+		{"valid", content.FeedFetch{FeedID: 1, Time: time.Now()}, false},
+		{"no feed id", content.FeedFetch{Time: time.Now()}, true},
+		{"no time", content.FeedFetch{FeedID: 1}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.fetch.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("FeedFetch.Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestFeedFetch_MarshalJSON(t *testing.T) {
+	f := content.FeedFetch{
+		FeedID: 1, Time: time.Date(2018, 1, 2, 3, 4, 5, 0, time.UTC), StatusCode: 503,
+		Duration: 1500 * time.Millisecond, Bytes: 42, ErrorClass: content.FetchErrorHTTP, Error: "HTTP Status: 503",
+	}
+
+	b, err := json.Marshal(f)
+	if err != nil {
+		t.Fatalf("FeedFetch.MarshalJSON() error = %v", err)
+	}
+
+	want := `{"time":"2018-01-02T03:04:05Z","statusCode":503,"bytes":42,"newArticles":0,"errorClass":"http","error":"HTTP Status: 503","duration":1500}`
+	if string(b) != want {
+		t.Errorf("FeedFetch.MarshalJSON() = %s, want %s", b, want)
+	}
+}
+
+func TestFeedHealth_Record(t *testing.T) {
+	start := time.Date(2018, 1, 2, 3, 4, 5, 0, time.UTC)
+	fetch := func(d time.Duration, class content.FetchErrorClass) content.FeedFetch {
+		return content.FeedFetch{FeedID: 1, Time: start.Add(d), StatusCode: 200, ErrorClass: class}
+	}
+
+	var h content.FeedHealth
+
+	h = h.Record(fetch(0, ""))
+	if h.Failing() || !h.LastSuccess.Equal(start) {
+		t.Fatalf("FeedHealth.Record() success = %#v", h)
+	}
+
+	h = h.Record(fetch(time.Hour, content.FetchErrorTimeout))
+	h = h.Record(fetch(2*time.Hour, content.FetchErrorNetwork))
+
+	want := content.FeedHealth{
+		FeedID: 1, LastFetch: start.Add(2 * time.Hour), LastSuccess: start,
+		FailingSince: start.Add(time.Hour), Failures: 2,
+		LastStatusCode: 200, LastErrorClass: content.FetchErrorNetwork,
+	}
+	if !reflect.DeepEqual(h, want) {
+		t.Fatalf("FeedHealth.Record() failures = %#v, want %#v", h, want)
+	}
+
+	h = h.Record(fetch(3*time.Hour, ""))
+	if h.Failing() || !h.FailingSince.IsZero() || h.LastErrorClass != "" || !h.LastSuccess.Equal(start.Add(3*time.Hour)) {
+		t.Errorf("FeedHealth.Record() recovery = %#v", h)
+	}
+}
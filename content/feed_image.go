@@ -0,0 +1,38 @@
+package content
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// FeedImage holds the image advertised by a feed, along with the cached
+// icon that represents the feed.
+type FeedImage struct {
+	FeedID    FeedID    `db:"feed_id" json:"-"`
+	Title     string    `json:"title,omitempty"`
+	URL       string    `json:"url,omitempty"`
+	Width     int       `json:"width,omitempty"`
+	Height    int       `json:"height,omitempty"`
+	Icon      []byte    `json:"-"`
+	IconType  string    `db:"icon_type" json:"-"`
+	FetchDate time.Time `db:"fetch_date" json:"-"`
+}
+
+func (i FeedImage) Validate() error {
+	if i.FeedID == 0 {
+		return NewValidationError(errors.New("Feed image has no feed id"))
+	}
+
+	return nil
+}
+
+// Stale reports whether the icon has not been fetched within the given
+// duration.
+func (i FeedImage) Stale(age time.Duration) bool {
+	return i.FetchDate.IsZero() || time.Since(i.FetchDate) > age
+}
+
+func (i FeedImage) String() string {
+	return fmt.Sprintf("%d: %s", i.FeedID, i.URL)
+}
@@ -0,0 +1,48 @@
+package content_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/urandom/readeef/content"
+)
+
+func TestFeedImage_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		FeedID  content.FeedID
+		wantErr bool
+	}{
+		{"valid", 1, false},
+		{"no feed id", 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			i := content.FeedImage{FeedID: tt.FeedID}
+			if err := i.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("FeedImage.Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestFeedImage_Stale(t *testing.T) {
+	tests := []struct {
+		name      string
+		FetchDate time.Time
+		age       time.Duration
+		want      bool
+	}{
+		{"never fetched", time.Time{}, time.Hour, true},
+		{"fresh", time.Now().Add(-time.Minute), time.Hour, false},
+		{"stale", time.Now().Add(-2 * time.Hour), time.Hour, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			i := content.FeedImage{FeedID: 1, FetchDate: tt.FetchDate}
+			if got := i.Stale(tt.age); got != tt.want {
+				t.Errorf("FeedImage.Stale() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
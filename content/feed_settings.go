@@ -0,0 +1,135 @@
+package content
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// The shortest custom update interval of a feed.
+const MinFeedUpdateInterval = time.Minute
+
+// FeedHeaders holds the extra HTTP headers sent when fetching a feed.
+type FeedHeaders map[string]string
+
+// FeedSettings holds the user-editable overrides of how a feed is fetched
+// and presented. The zero value keeps the configured defaults. As the feeds
+// are shared by all of their users, the settings belong to the user who set
+// them, and only the owner may change them.
+type FeedSettings struct {
+	FeedID FeedID `db:"feed_id" json:"-"`
+	Owner  Login  `json:"-"`
+
+	// UpdateInterval replaces the adaptive update interval when not 0.
+	UpdateInterval time.Duration `db:"update_interval" json:"-"`
+	Paused         bool          `json:"paused"`
+
+	UserAgent string `db:"user_agent" json:"userAgent"`
+
+	// The header values and the credentials of private feeds are never
+	// sent back to the clients.
+	Headers  FeedHeaders `json:"-"`
+	Username string      `json:"username"`
+	Password string      `json:"-"`
+	Cookie   string      `json:"-"`
+
+	ExtractContent bool `db:"extract_content" json:"extractContent"`
+}
+
+func (s FeedSettings) Validate() error {
+	if s.FeedID == 0 {
+		return NewValidationError(errors.New("Feed settings have no feed id"))
+	}
+
+	if s.UpdateInterval != 0 && s.UpdateInterval < MinFeedUpdateInterval {
+		return NewValidationError(fmt.Errorf("Feed update interval is shorter than %s", MinFeedUpdateInterval))
+	}
+
+	if s.Password != "" && s.Username == "" {
+		return NewValidationError(errors.New("Feed password given without a username"))
+	}
+
+	if strings.ContainsAny(s.UserAgent+s.Username+s.Password+s.Cookie, "\r\n") {
+		return NewValidationError(errors.New("Feed settings contain a line break"))
+	}
+
+	for k, v := range s.Headers {
+		if k == "" || strings.ContainsAny(k, " :\t\r\n") || strings.ContainsAny(v, "\r\n") {
+			return NewValidationError(fmt.Errorf("Invalid feed header %q", k))
+		}
+	}
+
+	return nil
+}
+
+// Private reports whether the feed is fetched with credentials, which may
+// only be used for the owner of the settings.
+func (s FeedSettings) Private() bool {
+	return s.Username != "" || s.Cookie != "" || len(s.Headers) > 0
+}
+
+// EditableBy reports whether the user may change the settings, which is
+// true for their owner, or for anyone when they have none.
+func (s FeedSettings) EditableBy(login Login) bool {
+	return s.Owner == "" || s.Owner == login
+}
+
+func (s FeedSettings) String() string {
+	return fmt.Sprintf("settings of feed %d", s.FeedID)
+}
+
+func (s FeedSettings) MarshalJSON() ([]byte, error) {
+	type settings FeedSettings
+
+	interval := ""
+	if s.UpdateInterval > 0 {
+		interval = s.UpdateInterval.String()
+	}
+
+	headers := make([]string, 0, len(s.Headers))
+	for k := range s.Headers {
+		headers = append(headers, k)
+	}
+	sort.Strings(headers)
+
+	return json.Marshal(struct {
+		settings
+		UpdateInterval string   `json:"updateInterval"`
+		HeaderNames    []string `json:"headerNames"`
+		HasPassword    bool     `json:"hasPassword"`
+		HasCookie      bool     `json:"hasCookie"`
+	}{settings(s), interval, headers, s.Password != "", s.Cookie != ""})
+}
+
+func (val *FeedHeaders) Scan(src interface{}) error {
+	var data []byte
+	switch t := src.(type) {
+	case nil:
+	case string:
+		data = []byte(t)
+	case []byte:
+		data = t
+	default:
+		return fmt.Errorf("Scan source '%#v' (%T) was not of type string (FeedHeaders)", src, src)
+	}
+
+	if len(data) == 0 {
+		*val = nil
+		return nil
+	}
+
+	return json.Unmarshal(data, val)
+}
+
+func (val FeedHeaders) Value() (driver.Value, error) {
+	if len(val) == 0 {
+		return "", nil
+	}
+
+	b, err := json.Marshal(val)
+	return string(b), err
+}
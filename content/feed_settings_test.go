@@ -0,0 +1,88 @@
+package content_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/urandom/readeef/content"
+)
+
+func TestFeedSettings_Validate(t *testing.T) {
+	tests := []struct {
+		name     string
+		settings content.FeedSettings
+		wantErr  bool
+	}{
+		{"valid", content.FeedSettings{FeedID: 1, UpdateInterval: time.Hour, Username: "user", Password: "pass", Headers: content.FeedHeaders{"X-Token": "abcd"}}, false},
+		{"defaults", content.FeedSettings{FeedID: 1}, false},
+		{"no feed id", content.FeedSettings{}, true},
+		{"short interval", content.FeedSettings{FeedID: 1, UpdateInterval: time.Second}, true},
+		{"password without username", content.FeedSettings{FeedID: 1, Password: "pass"}, true},
+		{"line break", content.FeedSettings{FeedID: 1, UserAgent: "agent\r\nX-Other: 1"}, true},
+		{"invalid header name", content.FeedSettings{FeedID: 1, Headers: content.FeedHeaders{"X Token": "abcd"}}, true},
+		{"invalid header value", content.FeedSettings{FeedID: 1, Headers: content.FeedHeaders{"X-Token": "ab\ncd"}}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.settings.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("FeedSettings.Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestFeedSettings_MarshalJSON(t *testing.T) {
+	s := content.FeedSettings{FeedID: 1, Owner: "user1", UpdateInterval: 90 * time.Minute, Username: "user", Password: "pass", Cookie: "session=abcd",
+		Headers: content.FeedHeaders{"X-Token": "abcd", "Accept": "application/atom+xml"}}
+
+	b, err := json.Marshal(s)
+	if err != nil {
+		t.Fatalf("FeedSettings.MarshalJSON() error = %v", err)
+	}
+
+	want := `{"paused":false,"userAgent":"","username":"user","extractContent":false,"updateInterval":"1h30m0s","headerNames":["Accept","X-Token"],"hasPassword":true,"hasCookie":true}`
+	if string(b) != want {
+		t.Errorf("FeedSettings.MarshalJSON() = %s, want %s", b, want)
+	}
+}
+
+func TestFeedSettings_Private(t *testing.T) {
+	tests := []struct {
+		name     string
+		settings content.FeedSettings
+		want     bool
+	}{
+		{"public", content.FeedSettings{FeedID: 1, UserAgent: "agent", Paused: true}, false},
+		{"username", content.FeedSettings{FeedID: 1, Username: "user"}, true},
+		{"cookie", content.FeedSettings{FeedID: 1, Cookie: "session=abcd"}, true},
+		{"headers", content.FeedSettings{FeedID: 1, Headers: content.FeedHeaders{"X-Token": "abcd"}}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.settings.Private(); got != tt.want {
+				t.Errorf("FeedSettings.Private() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFeedSettings_EditableBy(t *testing.T) {
+	tests := []struct {
+		name  string
+		owner content.Login
+		login content.Login
+		want  bool
+	}{
+		{"no owner", "", "user1", true},
+		{"owner", "user1", "user1", true},
+		{"other user", "user1", "user2", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := (content.FeedSettings{FeedID: 1, Owner: tt.owner}).EditableBy(tt.login); got != tt.want {
+				t.Errorf("FeedSettings.EditableBy() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
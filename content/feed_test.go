@@ -1,6 +1,7 @@
 package content_test
 
 import (
+	"reflect"
 	"testing"
 
 	"github.com/urandom/readeef/content"
@@ -45,9 +46,10 @@ func TestFeed_Refresh(t *testing.T) {
 		{"simple feed", content.Feed{}, parser.Feed{Title: "Title", Description: "Description", SiteLink: "http://sugr.org"}},
 		{"simple feed 2", content.Feed{Title: "Diff", Description: "Diff"}, parser.Feed{Title: "Title", Description: "Description", SiteLink: "http://sugr.org", HubLink: "http://hub.sugr.org"}},
 		{"with articles", content.Feed{}, parser.Feed{Title: "Title", Articles: []parser.Article{
-			{Title: "Title 1"},
+			{Title: "Title 1", Author: "John Doe", Categories: []string{"Go"}},
 			{Title: "Title 2"},
 		}}},
+		{"with image", content.Feed{ID: 1}, parser.Feed{Title: "Title", Image: parser.Image{Title: "Logo", Url: "http://sugr.org/logo.png", Width: 16, Height: 16}}},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -70,6 +72,10 @@ func TestFeed_Refresh(t *testing.T) {
 				t.Errorf("Feed.Refresh() hubLink want = %v, got %v", tt.parsed.HubLink, f.HubLink)
 			}
 
+			if image := f.ParsedImage(); image.FeedID != f.ID || image.URL != tt.parsed.Image.Url || image.Title != tt.parsed.Image.Title {
+				t.Errorf("Feed.Refresh() image want = %v, got %v", tt.parsed.Image, image)
+			}
+
 			if len(f.ParsedArticles()) != len(tt.parsed.Articles) {
 				t.Errorf("Feed.Refresh() articles want = %v, got %v", len(tt.parsed.Articles), len(f.ParsedArticles()))
 			}
@@ -78,6 +84,11 @@ func TestFeed_Refresh(t *testing.T) {
 				if a.Title != tt.parsed.Articles[i].Title {
 					t.Errorf("Feed.Refresh() article %d title want = %v, got %v", i, tt.parsed.Articles[i].Title, a.Title)
 				}
+
+				if a.Author != tt.parsed.Articles[i].Author || !reflect.DeepEqual(a.Categories, tt.parsed.Articles[i].Categories) {
+					t.Errorf("Feed.Refresh() article %d author/categories want = %v %v, got %v %v", i,
+						tt.parsed.Articles[i].Author, tt.parsed.Articles[i].Categories, a.Author, a.Categories)
+				}
 			}
 		})
 	}
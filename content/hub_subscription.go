@@ -0,0 +1,43 @@
+package content
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+)
+
+// The maximum length of a subscriber secret, as set by the WebSub spec.
+const maxHubSecretLength = 200
+
+// HubSubscription is a subscription of a downstream reader to one of the
+// outbound feeds, held by the built-in WebSub hub.
+type HubSubscription struct {
+	Topic       string    `db:"topic"`
+	Callback    string    `db:"callback"`
+	Secret      string    `db:"secret"`
+	LeaseExpiry time.Time `db:"lease_expiry"`
+}
+
+func (s HubSubscription) Validate() error {
+	for _, link := range []string{s.Topic, s.Callback} {
+		if u, err := url.Parse(link); err != nil || !u.IsAbs() || u.Scheme != "http" && u.Scheme != "https" {
+			return NewValidationError(errors.New("Invalid hub subscription topic or callback"))
+		}
+	}
+
+	if len(s.Secret) >= maxHubSecretLength {
+		return NewValidationError(errors.New("Hub subscription secret is too long"))
+	}
+
+	return nil
+}
+
+// Expired reports whether the lease of the subscription has run out.
+func (s HubSubscription) Expired() bool {
+	return !s.LeaseExpiry.After(time.Now())
+}
+
+func (s HubSubscription) String() string {
+	return fmt.Sprintf("%s -> %s", s.Topic, s.Callback)
+}
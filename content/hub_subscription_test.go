@@ -0,0 +1,41 @@
+package content_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/urandom/readeef/content"
+)
+
+func TestHubSubscription_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		sub     content.HubSubscription
+		wantErr bool
+	}{
+		{"valid", content.HubSubscription{Topic: "https://example.com/feed", Callback: "http://reader.com/cb"}, false},
+		{"secret", content.HubSubscription{Topic: "https://example.com/feed", Callback: "http://reader.com/cb", Secret: "s3cr3t"}, false},
+		{"no topic", content.HubSubscription{Callback: "http://reader.com/cb"}, true},
+		{"relative callback", content.HubSubscription{Topic: "https://example.com/feed", Callback: "/cb"}, true},
+		{"non-http callback", content.HubSubscription{Topic: "https://example.com/feed", Callback: "ftp://reader.com/cb"}, true},
+		{"long secret", content.HubSubscription{Topic: "https://example.com/feed", Callback: "http://reader.com/cb", Secret: strings.Repeat("s", 200)}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.sub.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("HubSubscription.Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestHubSubscription_Expired(t *testing.T) {
+	if !(content.HubSubscription{LeaseExpiry: time.Now().Add(-time.Minute)}).Expired() {
+		t.Errorf("HubSubscription.Expired() = false for a past lease")
+	}
+
+	if (content.HubSubscription{LeaseExpiry: time.Now().Add(time.Minute)}).Expired() {
+		t.Errorf("HubSubscription.Expired() = true for a future lease")
+	}
+}
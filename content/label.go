@@ -0,0 +1,49 @@
+package content
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+type LabelID int64
+
+// Label is a user-defined marker, which unlike a tag, is attached to
+// individual articles.
+type Label struct {
+	ID        LabelID `json:"id"`
+	UserLogin Login   `db:"user_login" json:"-"`
+	Name      string  `json:"name"`
+}
+
+func (l Label) Validate() error {
+	if l.UserLogin == "" {
+		return NewValidationError(errors.New("Label has no user"))
+	}
+
+	if strings.TrimSpace(l.Name) == "" {
+		return NewValidationError(errors.New("Label has no name"))
+	}
+
+	return nil
+}
+
+func (l Label) String() string {
+	return fmt.Sprintf("%d: %s", l.ID, l.Name)
+}
+
+func (id *LabelID) Scan(src interface{}) error {
+	asInt, ok := src.(int64)
+	if !ok {
+		return fmt.Errorf("Scan source '%#v' (%T) was not of type int64 (LabelID)", src, src)
+	}
+
+	*id = LabelID(asInt)
+
+	return nil
+}
+
+func (id LabelID) Value() (driver.Value, error) {
+	return int64(id), nil
+}
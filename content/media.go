@@ -0,0 +1,38 @@
+package content
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+)
+
+// Media is an enclosure or a Media RSS object attached to an article.
+type Media struct {
+	ID        int64     `json:"id"`
+	ArticleID ArticleID `db:"article_id" json:"-"`
+	Link      string    `json:"link"`
+	Type      string    `json:"type,omitempty"`
+	Medium    string    `json:"medium,omitempty"`
+	Title     string    `json:"title,omitempty"`
+	Length    int64     `json:"length,omitempty"`
+	// Duration is in seconds
+	Duration int64 `json:"duration,omitempty"`
+	Width    int   `json:"width,omitempty"`
+	Height   int   `json:"height,omitempty"`
+}
+
+func (m Media) Validate() error {
+	if m.Link == "" {
+		return NewValidationError(errors.New("Article media has no link"))
+	}
+
+	if u, err := url.Parse(m.Link); err != nil || !u.IsAbs() {
+		return NewValidationError(errors.New("Article media link is not absolute"))
+	}
+
+	return nil
+}
+
+func (m Media) String() string {
+	return fmt.Sprintf("%d: %s", m.ArticleID, m.Link)
+}
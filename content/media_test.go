@@ -0,0 +1,30 @@
+package content_test
+
+import (
+	"testing"
+
+	"github.com/urandom/readeef/content"
+)
+
+func TestMedia_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		Link    string
+		wantErr bool
+	}{
+		{"valid", "http://example.com/episode.mp3", false},
+		{"no link", "", true},
+		{"relative link", "/episode.mp3", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := content.Media{
+				ArticleID: 1,
+				Link:      tt.Link,
+			}
+			if err := m.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Media.Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
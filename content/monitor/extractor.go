@@ -0,0 +1,45 @@
+package monitor
+
+import (
+	"github.com/urandom/readeef/content"
+	"github.com/urandom/readeef/content/extract"
+	"github.com/urandom/readeef/content/repo/eventable"
+	"github.com/urandom/readeef/log"
+)
+
+// Extractor generates the extracts of the new articles of the feeds set to
+// always show their full content, so that they are ready when requested.
+func Extractor(service eventable.Service, generator extract.Generator, log log.Log) {
+	for event := range service.Listener() {
+		switch data := event.Data.(type) {
+		case eventable.FeedUpdateData:
+			go processExtractorEvent(data, service, generator, log)
+		}
+	}
+}
+
+func processExtractorEvent(data eventable.FeedUpdateData, service eventable.Service, generator extract.Generator, log log.Log) {
+	if len(data.NewArticles) == 0 {
+		return
+	}
+
+	settings, err := service.FeedSettingsRepo().Get(data.Feed)
+	if err != nil {
+		if !content.IsNoContent(err) {
+			log.Printf("Error getting settings of feed %s: %+v", data.Feed, err)
+		}
+		return
+	}
+
+	if !settings.ExtractContent {
+		return
+	}
+
+	log.Infof("Extracting the content of new articles of feed %s", data.Feed)
+
+	for _, a := range data.NewArticles {
+		if _, err := extract.Get(a, service.ExtractRepo(), generator, nil); err != nil {
+			log.Printf("Error extracting content of article %s: %+v", a, err)
+		}
+	}
+}
@@ -0,0 +1,36 @@
+package monitor
+
+import (
+	"github.com/urandom/readeef/content/repo/eventable"
+	"github.com/urandom/readeef/log"
+)
+
+type hubChecker interface {
+	Check()
+}
+
+// Hub schedules a check of the WebSub hub topics whenever new articles
+// arrive, feeds are removed, or articles are favored or hidden, since the
+// outbound feeds might have changed along with them. Read state changes are
+// ignored, as they are far too frequent to refetch all topics for them.
+func Hub(service eventable.Service, hub hubChecker, log log.Log) {
+	for event := range service.Listener() {
+		if !affectsHubTopics(event) {
+			continue
+		}
+
+		log.Debugf("Scheduling a hub check after a %s event", event.Name)
+		hub.Check()
+	}
+}
+
+func affectsHubTopics(event eventable.Event) bool {
+	switch data := event.Data.(type) {
+	case eventable.FeedUpdateData, eventable.FeedDeleteData, eventable.FeedMergeData:
+		return true
+	case eventable.ArticleStateData:
+		return data.State == eventable.ArticleStateFavor || data.State == eventable.ArticleStateHide
+	}
+
+	return false
+}
@@ -0,0 +1,25 @@
+package monitor
+
+import (
+	"github.com/urandom/readeef/content/repo/eventable"
+	"github.com/urandom/readeef/feed"
+	"github.com/urandom/readeef/log"
+)
+
+// Icons refreshes the cached feed icons whenever a feed gets new articles.
+func Icons(service eventable.Service, icons feed.IconCache, log log.Log) {
+	for event := range service.Listener() {
+		switch data := event.Data.(type) {
+		case eventable.FeedUpdateData:
+			go processIconsEvent(data, icons, log)
+		}
+	}
+}
+
+func processIconsEvent(data eventable.FeedUpdateData, icons feed.IconCache, log log.Log) {
+	log.Debugf("Checking icon for feed %s", data.Feed)
+
+	if _, err := icons.Get(data.Feed); err != nil {
+		log.Printf("Error caching icon for feed %s: %+v", data.Feed, err)
+	}
+}
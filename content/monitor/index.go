@@ -1,6 +1,7 @@
 package monitor
 
 import (
+	"github.com/urandom/readeef/content"
 	"github.com/urandom/readeef/content/repo/eventable"
 	"github.com/urandom/readeef/content/search"
 	"github.com/urandom/readeef/log"
@@ -10,18 +11,30 @@ func Index(service eventable.Service, provider search.Provider, log log.Log) {
 	for event := range service.Listener() {
 		switch data := event.Data.(type) {
 		case eventable.FeedUpdateData:
-			go processIndexUpdateEvent(data, provider, log)
+			go processIndexUpdateEvent(service, data, provider, log)
 		case eventable.FeedDeleteData:
 			go processIndexDeleteEvent(data, provider, log)
+		case eventable.FeedMergeData:
+			go processIndexMergeEvent(service, data, provider, log)
 		}
 	}
 }
 
-func processIndexUpdateEvent(data eventable.FeedUpdateData, provider search.Provider, log log.Log) {
+func processIndexUpdateEvent(
+	service eventable.Service,
+	data eventable.FeedUpdateData,
+	provider search.Provider,
+	log log.Log,
+) {
 	log.Infof("Updating article search index for feed %s", data.Feed)
 
 	if err := provider.BatchIndex(data.NewArticles, search.BatchAdd); err != nil {
 		log.Printf("Error adding articles from %s to search index: %+v", data.Feed, err)
+		return
+	}
+
+	if err := matchSavedSearches(service, provider, data); err != nil {
+		log.Printf("Error matching saved searches for feed %s: %+v", data.Feed, err)
 	}
 }
 
@@ -32,3 +45,29 @@ func processIndexDeleteEvent(data eventable.FeedDeleteData, provider search.Prov
 		log.Printf("Error removing feed %s from search index: %+v", data.Feed, err)
 	}
 }
+
+// processIndexMergeEvent reindexes the articles of the feed the merged feed
+// was merged into, as some of them have moved from the merged feed.
+func processIndexMergeEvent(
+	service eventable.Service,
+	data eventable.FeedMergeData,
+	provider search.Provider,
+	log log.Log,
+) {
+	log.Infof("Reindexing articles of feed %s merged into %s", data.Feed, data.Into)
+
+	if err := provider.RemoveFeed(data.Feed.ID); err != nil {
+		log.Printf("Error removing feed %s from search index: %+v", data.Feed, err)
+		return
+	}
+
+	articles, err := service.ArticleRepo().All(content.FeedIDs([]content.FeedID{data.Into.ID}), content.IncludeCategories)
+	if err != nil {
+		log.Printf("Error getting articles of feed %s: %+v", data.Into, err)
+		return
+	}
+
+	if err := provider.BatchIndex(articles, search.BatchAdd); err != nil {
+		log.Printf("Error adding articles from %s to search index: %+v", data.Into, err)
+	}
+}
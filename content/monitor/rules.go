@@ -0,0 +1,90 @@
+package monitor
+
+import (
+	"github.com/pkg/errors"
+	"github.com/urandom/readeef/content"
+	"github.com/urandom/readeef/content/repo"
+)
+
+// ruleMatches holds the ids of the articles each rule action applies to.
+type ruleMatches map[content.RuleAction][]content.ArticleID
+
+// matchRules evaluates the user's rules against the new articles of a feed.
+func matchRules(
+	service repo.Service,
+	user content.User,
+	feed content.Feed,
+	articles []content.Article,
+) (ruleMatches, error) {
+	rules, err := service.RuleRepo().ForUser(user)
+	if err != nil {
+		return nil, errors.WithMessage(err, "getting user rules")
+	}
+
+	matches := ruleMatches{}
+	if len(rules) == 0 {
+		return matches, nil
+	}
+
+	var tags []content.Tag
+	for _, rule := range rules {
+		if rule.Field == content.RuleFieldTag {
+			if tags, err = service.TagRepo().ForFeed(feed, user); err != nil {
+				return nil, errors.WithMessage(err, "getting feed tags")
+			}
+			break
+		}
+	}
+
+	for _, rule := range rules {
+		matcher, err := rule.Matcher()
+		if err != nil {
+			return nil, errors.WithMessage(err, "preparing rule "+rule.String())
+		}
+
+		for _, a := range articles {
+			if !matcher.Match(a, feed, tags) {
+				continue
+			}
+
+			for _, action := range rule.Actions {
+				matches[action] = appendUniqueID(matches[action], a.ID)
+			}
+		}
+	}
+
+	return matches, nil
+}
+
+// exclude returns the given ids that aren't covered by any of the actions.
+func (m ruleMatches) exclude(ids []content.ArticleID, actions ...content.RuleAction) []content.ArticleID {
+	excluded := map[content.ArticleID]struct{}{}
+	for _, action := range actions {
+		for _, id := range m[action] {
+			excluded[id] = struct{}{}
+		}
+	}
+
+	if len(excluded) == 0 {
+		return ids
+	}
+
+	res := make([]content.ArticleID, 0, len(ids))
+	for _, id := range ids {
+		if _, ok := excluded[id]; !ok {
+			res = append(res, id)
+		}
+	}
+
+	return res
+}
+
+func appendUniqueID(ids []content.ArticleID, id content.ArticleID) []content.ArticleID {
+	for i := range ids {
+		if ids[i] == id {
+			return ids
+		}
+	}
+
+	return append(ids, id)
+}
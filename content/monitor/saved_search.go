@@ -0,0 +1,82 @@
+package monitor
+
+import (
+	"github.com/pkg/errors"
+	"github.com/urandom/readeef/content"
+	"github.com/urandom/readeef/content/repo/eventable"
+	"github.com/urandom/readeef/content/search"
+)
+
+// matchSavedSearches notifies the users subscribed to the updated feed of
+// the new articles found by their saved searches. It expects the articles
+// to already be indexed.
+func matchSavedSearches(service eventable.Service, provider search.Provider, data eventable.FeedUpdateData) error {
+	if len(data.NewArticles) == 0 {
+		return nil
+	}
+
+	newIDs := map[content.ArticleID]struct{}{}
+	for _, a := range data.NewArticles {
+		newIDs[a.ID] = struct{}{}
+	}
+
+	users, err := service.FeedRepo().Users(data.Feed)
+	if err != nil {
+		return errors.WithMessage(err, "getting feed users")
+	}
+
+	for _, user := range users {
+		searches, err := service.SavedSearchRepo().ForUser(user)
+		if err != nil {
+			return errors.WithMessage(err, "getting user saved searches")
+		}
+
+		for _, s := range searches {
+			ids, err := search.SavedSearchFeedIDs(service, s, user)
+			if err != nil {
+				return err
+			}
+
+			if ids != nil && !containsFeedID(ids, data.Feed.ID) {
+				continue
+			}
+
+			// The providers can't be limited to a list of ids. Since the new
+			// articles have the highest ids in the feed, they are instead
+			// among the newest matches of the feed.
+			articles, err := provider.Search(s.Query, user,
+				content.FeedIDs([]content.FeedID{data.Feed.ID}),
+				content.Sorting(content.SortByID, content.DescendingOrder),
+				content.Paging(len(data.NewArticles), 0),
+			)
+			if err != nil {
+				return errors.WithMessage(err, "searching for saved search "+s.String())
+			}
+
+			var matched []content.ArticleID
+			for _, a := range articles {
+				if _, ok := newIDs[a.ID]; ok {
+					matched = append(matched, a.ID)
+				}
+			}
+
+			if len(matched) > 0 {
+				service.Dispatch(eventable.SavedSearchMatchEvent, eventable.SavedSearchMatchData{
+					User: user.Login, SavedSearchID: s.ID, ArticleIDs: matched,
+				})
+			}
+		}
+	}
+
+	return nil
+}
+
+func containsFeedID(ids []content.FeedID, id content.FeedID) bool {
+	for i := range ids {
+		if ids[i] == id {
+			return true
+		}
+	}
+
+	return false
+}
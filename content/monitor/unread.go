@@ -5,26 +5,20 @@ import (
 	"time"
 
 	"github.com/urandom/readeef/content"
+	"github.com/urandom/readeef/content/repo"
 	"github.com/urandom/readeef/content/repo/eventable"
 	"github.com/urandom/readeef/log"
 )
 
+// How often the stale unread records and article changes are removed.
+const staleRecordsInterval = 24 * time.Hour
+
 func Unread(ctx context.Context, service eventable.Service, log log.Log) {
 	// Grab the non-eventable article repo. We don't want to notify on the
 	// initial unread mark.
 	articleRepo := service.Service.ArticleRepo()
 
-	go func() {
-		articleRepo.RemoveStaleUnreadRecords()
-
-		ticker := time.NewTicker(24 * time.Hour)
-		select {
-		case <-ctx.Done():
-			return
-		case <-ticker.C:
-			articleRepo.RemoveStaleUnreadRecords()
-		}
-	}()
+	go removeStaleRecords(ctx, articleRepo, staleRecordsInterval, log)
 
 	userRepo := service.UserRepo()
 	for event := range service.Listener() {
@@ -44,13 +38,60 @@ func Unread(ctx context.Context, service eventable.Service, log log.Log) {
 			}
 
 			for _, user := range users {
-				if err := articleRepo.Read(
-					false, user, content.IDs(ids),
-					content.Filters(content.GetUserFilters(user)),
-				); err != nil {
-					log.Printf("Error marking new articles as unread: %+v", err)
+				// Rules are applied here, rather than in a separate monitor,
+				// so that they can't race with the unread marking.
+				matches, err := matchRules(service.Service, user, data.Feed, data.NewArticles)
+				if err != nil {
+					log.Printf("Error matching rules of user %s: %+v", user, err)
+				}
+
+				unread := matches.exclude(ids, content.RuleActionRead, content.RuleActionHide)
+				if len(unread) > 0 {
+					if err := articleRepo.Read(
+						false, user, content.IDs(unread),
+						content.Filters(content.GetUserFilters(user)),
+					); err != nil {
+						log.Printf("Error marking new articles as unread: %+v", err)
+					}
+				}
+
+				if favorite := matches[content.RuleActionFavorite]; len(favorite) > 0 {
+					log.Infof("Favoring %d new feed %s articles for user %s by rule", len(favorite), data.Feed, user)
+					if err := articleRepo.Favor(true, user, content.IDs(favorite)); err != nil {
+						log.Printf("Error favoring new articles by rule: %+v", err)
+					}
+				}
+
+				if hidden := matches[content.RuleActionHide]; len(hidden) > 0 {
+					log.Infof("Hiding %d new feed %s articles for user %s by rule", len(hidden), data.Feed, user)
+					if err := articleRepo.Hide(true, user, content.IDs(hidden)); err != nil {
+						log.Printf("Error hiding new articles by rule: %+v", err)
+					}
 				}
 			}
 		}
 	}
 }
+
+// removeStaleRecords removes the stale unread records and article changes
+// right away, and then periodically until the context is done.
+func removeStaleRecords(ctx context.Context, articleRepo repo.Article, interval time.Duration, log log.Log) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := articleRepo.RemoveStaleUnreadRecords(); err != nil {
+			log.Printf("Error removing stale unread records: %+v", err)
+		}
+
+		if err := articleRepo.RemoveStaleChanges(); err != nil {
+			log.Printf("Error removing stale article changes: %+v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package monitor
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/urandom/readeef/config"
+	"github.com/urandom/readeef/content/repo/mock_repo"
+	"github.com/urandom/readeef/log"
+)
+
+func Test_removeStaleRecords(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	cfg := config.Log{}
+	cfg.Converted.Writer = os.Stderr
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	calls := make(chan struct{}, 10)
+	repo := mock_repo.NewMockArticle(ctrl)
+	repo.EXPECT().RemoveStaleUnreadRecords().Return(nil).MinTimes(3)
+	repo.EXPECT().RemoveStaleChanges().DoAndReturn(func() error {
+		calls <- struct{}{}
+		return nil
+	}).MinTimes(3)
+
+	done := make(chan struct{})
+	go func() {
+		removeStaleRecords(ctx, repo, 5*time.Millisecond, log.WithStd(cfg))
+		close(done)
+	}()
+
+	// The records are removed right away, and then on every tick
+	for i := 0; i < 3; i++ {
+		select {
+		case <-calls:
+		case <-time.After(time.Second):
+			t.Fatalf("removeStaleRecords() removed stale records %d times, want 3", i)
+		}
+	}
+
+	cancel()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatalf("removeStaleRecords() did not return after the context was done")
+	}
+}
@@ -0,0 +1,66 @@
+package content
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+type PublicationID int64
+
+// Publication is an entry in a user's published feed. It is either a copy of
+// an article the user has published, or an arbitrary link the user has
+// shared, in which case it has no article id.
+type Publication struct {
+	ID        PublicationID `json:"id"`
+	UserLogin Login         `db:"user_login" json:"-"`
+	ArticleID ArticleID     `db:"article_id" json:"articleID,omitempty"`
+
+	Title       string    `json:"title"`
+	Link        string    `json:"link"`
+	Description string    `json:"description,omitempty"`
+	Note        string    `json:"note,omitempty"`
+	Date        time.Time `json:"date"`
+}
+
+func (p Publication) Validate() error {
+	if p.UserLogin == "" {
+		return NewValidationError(errors.New("Publication has no user"))
+	}
+
+	if p.Link == "" {
+		return NewValidationError(errors.New("Publication has no link"))
+	}
+
+	if u, err := url.Parse(p.Link); err != nil || !u.IsAbs() {
+		return NewValidationError(errors.New("Publication link is not absolute"))
+	}
+
+	if strings.TrimSpace(p.Title) == "" {
+		return NewValidationError(errors.New("Publication has no title"))
+	}
+
+	return nil
+}
+
+func (p Publication) String() string {
+	return fmt.Sprintf("%d: %s", p.ID, p.Link)
+}
+
+func (id *PublicationID) Scan(src interface{}) error {
+	asInt, ok := src.(int64)
+	if !ok {
+		return fmt.Errorf("Scan source '%#v' (%T) was not of type int64 (PublicationID)", src, src)
+	}
+
+	*id = PublicationID(asInt)
+
+	return nil
+}
+
+func (id PublicationID) Value() (driver.Value, error) {
+	return int64(id), nil
+}
@@ -0,0 +1,28 @@
+package content_test
+
+import (
+	"testing"
+
+	"github.com/urandom/readeef/content"
+)
+
+func TestPublication_Validate(t *testing.T) {
+	tests := []struct {
+		name        string
+		publication content.Publication
+		wantErr     bool
+	}{
+		{"valid", content.Publication{UserLogin: "user1", Title: "Go", Link: "https://golang.org"}, false},
+		{"no user", content.Publication{Title: "Go", Link: "https://golang.org"}, true},
+		{"no link", content.Publication{UserLogin: "user1", Title: "Go"}, true},
+		{"relative link", content.Publication{UserLogin: "user1", Title: "Go", Link: "/blog"}, true},
+		{"no title", content.Publication{UserLogin: "user1", Title: " ", Link: "https://golang.org"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.publication.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Publication.Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -13,6 +13,16 @@ type Article interface {
 
 	Read(bool, content.User, ...content.QueryOpt) error
 	Favor(bool, content.User, ...content.QueryOpt) error
+	Hide(bool, content.User, ...content.QueryOpt) error
+
+	SetNote(content.User, content.ArticleID, string) error
 
 	RemoveStaleUnreadRecords() error
+
+	Changes(content.User, content.ChangeID, int) ([]content.ArticleChange, error)
+	ChangeRange() (content.ChangeID, content.ChangeID, error)
+	RemoveStaleChanges() error
+
+	Purgeable(content.Feed, content.Retention) ([]content.ArticleID, error)
+	Delete([]content.ArticleID) error
 }
@@ -1,6 +1,7 @@
 package repo_test
 
 import (
+	"reflect"
 	"sort"
 	"strings"
 	"sync"
@@ -8,6 +9,7 @@ import (
 	"time"
 
 	"github.com/urandom/readeef/content"
+	"github.com/urandom/readeef/parser"
 )
 
 var (
@@ -232,6 +234,63 @@ func Test_articleRepo_All(t *testing.T) {
 	}
 }
 
+func Test_articleRepo_All_media(t *testing.T) {
+	skipTest(t)
+	setupArticle()
+
+	r := service.ArticleRepo()
+	got, err := r.All(content.IDs([]content.ArticleID{articles[0].ID, articles[1].ID}), content.IncludeMedia)
+	if err != nil {
+		t.Fatalf("articleRepo.All() error = %v", err)
+	}
+
+	for _, a := range got {
+		switch a.Title {
+		case "Article 1":
+			if len(a.Media) != 1 {
+				t.Fatalf("articleRepo.All() media = %#v, want 1 object", a.Media)
+			}
+
+			m := a.Media[0]
+			if m.ArticleID != a.ID || m.Link != "http://sugr.org/1/a/1/episode.mp3" || m.Type != "audio/mpeg" || m.Length != 1024 || m.Duration != 90 {
+				t.Errorf("articleRepo.All() media = %#v", m)
+			}
+		default:
+			if len(a.Media) != 0 {
+				t.Errorf("articleRepo.All() unexpected media = %#v", a.Media)
+			}
+		}
+	}
+}
+
+func Test_articleRepo_All_categories(t *testing.T) {
+	skipTest(t)
+	setupArticle()
+
+	r := service.ArticleRepo()
+	got, err := r.All(content.IDs([]content.ArticleID{articles[0].ID, articles[1].ID}), content.IncludeCategories)
+	if err != nil {
+		t.Fatalf("articleRepo.All() error = %v", err)
+	}
+
+	for _, a := range got {
+		switch a.Title {
+		case "Article 1":
+			if a.Author != "John Doe" {
+				t.Errorf("articleRepo.All() author = %q", a.Author)
+			}
+
+			if want := []string{"Feeds", "Go"}; !reflect.DeepEqual(a.Categories, want) {
+				t.Errorf("articleRepo.All() categories = %v, want %v", a.Categories, want)
+			}
+		default:
+			if a.Author != "" || len(a.Categories) != 0 {
+				t.Errorf("articleRepo.All() unexpected author %q and categories %v", a.Author, a.Categories)
+			}
+		}
+	}
+}
+
 func Test_articleRepo_Count(t *testing.T) {
 	skipTest(t)
 	setupArticle()
@@ -261,6 +320,11 @@ func Test_articleRepo_Count(t *testing.T) {
 		{"favorite for user 1", args{user1, []content.QueryOpt{content.FavoriteOnly}}, 3, false},
 		{"untagged for user 1", args{user1, []content.QueryOpt{content.UntaggedOnly}}, 0, false},
 		{"untagged for user 2", args{user2, []content.QueryOpt{content.UntaggedOnly}}, 5, false},
+		{"author for user 1", args{user1, []content.QueryOpt{content.Authors([]string{"John Doe"})}}, 2, false},
+		{"author for user 2", args{user2, []content.QueryOpt{content.Authors([]string{"John Doe", "Jane Doe"})}}, 1, false},
+		{"category for user 1", args{user1, []content.QueryOpt{content.Categories([]string{"GO"})}}, 2, false},
+		{"categories for user 1", args{user1, []content.QueryOpt{content.Categories([]string{"feeds", "none"})}}, 1, false},
+		{"author and category for user 1", args{user1, []content.QueryOpt{content.Authors([]string{"john doe"}), content.Categories([]string{"feeds"})}}, 1, false},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -485,3 +549,388 @@ func Test_articleRepo_Favor(t *testing.T) {
 		})
 	}
 }
+
+func Test_articleRepo_Hide(t *testing.T) {
+	skipTest(t)
+	setupArticle()
+
+	r := service.ArticleRepo()
+	user := content.User{Login: user1}
+	hidden := []content.ArticleID{articles[0].ID, articles[1].ID}
+
+	total, err := r.Count(user)
+	if err != nil {
+		t.Fatalf("articleRepo.Hide() preliminary count error = %v", err)
+	}
+
+	if err := r.Hide(true, user, content.IDs(hidden)); err != nil {
+		t.Fatalf("articleRepo.Hide() error = %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		opts  []content.QueryOpt
+		count int64
+	}{
+		{"visible", nil, total - 2},
+		{"hidden only", []content.QueryOpt{content.HiddenOnly}, 2},
+		{"include hidden", []content.QueryOpt{content.IncludeHidden}, total},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			count, err := r.Count(user, tt.opts...)
+			if err != nil {
+				t.Errorf("articleRepo.Count() error = %v", err)
+				return
+			}
+
+			if count != tt.count {
+				t.Errorf("articleRepo.Count() = %d, want %d", count, tt.count)
+			}
+
+			ids, err := r.IDs(user, tt.opts...)
+			if err != nil {
+				t.Errorf("articleRepo.IDs() error = %v", err)
+				return
+			}
+
+			if int64(len(ids)) != tt.count {
+				t.Errorf("articleRepo.IDs() = %v, want %d ids", ids, tt.count)
+			}
+
+			got, err := r.ForUser(user, tt.opts...)
+			if err != nil {
+				t.Errorf("articleRepo.ForUser() error = %v", err)
+				return
+			}
+
+			if int64(len(got)) != tt.count {
+				t.Errorf("articleRepo.ForUser() = %v, want %d articles", got, tt.count)
+			}
+
+			for _, a := range got {
+				if a.Hidden != (a.ID == hidden[0] || a.ID == hidden[1]) {
+					t.Errorf("articleRepo.ForUser() article %s hidden state %v", a, a.Hidden)
+				}
+			}
+		})
+	}
+
+	if err := r.Hide(false, user, content.HiddenOnly); err != nil {
+		t.Fatalf("articleRepo.Hide() error = %v", err)
+	}
+
+	if count, err := r.Count(user); err != nil || count != total {
+		t.Errorf("articleRepo.Hide() count after unhiding = %d, %v, want %d", count, err, total)
+	}
+}
+
+func Test_articleRepo_PurgeableDelete(t *testing.T) {
+	skipTest(t)
+	setupArticle()
+
+	u1 := content.User{Login: user1}
+	feed := content.Feed{Link: "http://sugr.org/purge", Title: "purge"}
+	feed.Refresh(parser.Feed{Title: "purge", Articles: []parser.Article{
+		{Title: "Purge 1", Link: "http://sugr.org/purge/1", Date: time.Now().Add(-50 * time.Hour)},
+		{Title: "Purge 2", Link: "http://sugr.org/purge/2", Date: time.Now().Add(-40 * time.Hour)},
+		{Title: "Purge 3", Link: "http://sugr.org/purge/3", Date: time.Now().Add(-30 * time.Hour)},
+		{Title: "Purge 4", Link: "http://sugr.org/purge/4", Date: time.Now().Add(-20 * time.Hour)},
+		{Title: "Purge 5", Link: "http://sugr.org/purge/5", Date: time.Now().Add(-10 * time.Hour)},
+		{Title: "Purge 6", Link: "http://sugr.org/purge/6", Date: time.Now()},
+	}})
+	createFeed(&feed, u1)
+	defer service.FeedRepo().Delete(feed)
+
+	r := service.ArticleRepo()
+	all, err := r.All(content.FeedIDs([]content.FeedID{feed.ID}))
+	if err != nil {
+		t.Fatalf("articleRepo.All() error = %v", err)
+	}
+
+	ids := map[string]content.ArticleID{}
+	for _, a := range all {
+		ids[a.Title] = a.ID
+	}
+
+	if err := r.Favor(true, u1, content.IDs([]content.ArticleID{ids["Purge 1"]})); err != nil {
+		t.Fatalf("articleRepo.Favor() error = %v", err)
+	}
+	if err := r.Read(false, u1, content.IDs([]content.ArticleID{ids["Purge 2"]})); err != nil {
+		t.Fatalf("articleRepo.Read() error = %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		retention content.Retention
+		want      []string
+	}{
+		{"disabled", content.Retention{MinKeep: 2}, nil},
+		{"max age", content.Retention{MaxAge: 24 * time.Hour, MinKeep: 2}, []string{"Purge 2", "Purge 3"}},
+		{"max age with min keep", content.Retention{MaxAge: time.Hour, MinKeep: 3}, []string{"Purge 2", "Purge 3"}},
+		{"max count", content.Retention{MaxCount: 3}, []string{"Purge 2", "Purge 3"}},
+		{"max count with min keep", content.Retention{MaxCount: 1, MinKeep: 4}, []string{"Purge 2"}},
+		{"max age or count", content.Retention{MaxAge: 15 * time.Hour, MaxCount: 5}, []string{"Purge 2", "Purge 3", "Purge 4"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := r.Purgeable(feed, tt.retention)
+			if err != nil {
+				t.Fatalf("articleRepo.Purgeable() error = %v", err)
+			}
+
+			want := []content.ArticleID{}
+			for _, title := range tt.want {
+				want = append(want, ids[title])
+			}
+
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("articleRepo.Purgeable() = %v, want %v", got, want)
+			}
+		})
+	}
+
+	label := content.Label{UserLogin: u1.Login, Name: "purge"}
+	if err := service.LabelRepo().Update(&label); err != nil {
+		t.Fatalf("labelRepo.Update() error = %v", err)
+	}
+	defer service.LabelRepo().Delete(label)
+
+	if err := service.LabelRepo().Assign(label, []content.ArticleID{ids["Purge 2"]}); err != nil {
+		t.Fatalf("labelRepo.Assign() error = %v", err)
+	}
+	if err := r.SetNote(u1, ids["Purge 3"], "keep"); err != nil {
+		t.Fatalf("articleRepo.SetNote() error = %v", err)
+	}
+
+	if got, err := r.Purgeable(feed, content.Retention{MaxAge: 24 * time.Hour, MinKeep: 2}); err != nil || len(got) != 0 {
+		t.Errorf("articleRepo.Purgeable() labelled and noted = %v, err = %v, want none", got, err)
+	}
+
+	if _, err := r.Purgeable(content.Feed{}, content.Retention{MaxCount: 1}); err == nil {
+		t.Errorf("articleRepo.Purgeable() expected error for an invalid feed")
+	}
+
+	if err := r.Delete([]content.ArticleID{ids["Purge 2"], ids["Purge 3"]}); err != nil {
+		t.Fatalf("articleRepo.Delete() error = %v", err)
+	}
+
+	remaining, err := r.All(content.FeedIDs([]content.FeedID{feed.ID}))
+	if err != nil {
+		t.Fatalf("articleRepo.All() error = %v", err)
+	}
+
+	titles := []string{}
+	for _, a := range remaining {
+		titles = append(titles, a.Title)
+	}
+	sort.Strings(titles)
+
+	if want := []string{"Purge 1", "Purge 4", "Purge 5", "Purge 6"}; !reflect.DeepEqual(titles, want) {
+		t.Errorf("articleRepo.Delete() remaining = %v, want %v", titles, want)
+	}
+
+	if count, err := r.Count(u1, content.UnreadOnly, content.FeedIDs([]content.FeedID{feed.ID})); err != nil || count != 0 {
+		t.Errorf("articleRepo.Delete() unread count = %d, err = %v", count, err)
+	}
+}
+
+func Test_articleRepo_Changes(t *testing.T) {
+	skipTest(t)
+	setupArticle()
+
+	u1 := content.User{Login: user1}
+	u2 := content.User{Login: user2}
+	feed := content.Feed{Link: "http://sugr.org/changes", Title: "changes"}
+	feed.Refresh(parser.Feed{Title: "changes", Articles: []parser.Article{
+		{Title: "Changes 1", Link: "http://sugr.org/changes/1", Date: time.Now().Add(-2 * time.Hour)},
+		{Title: "Changes 2", Link: "http://sugr.org/changes/2", Date: time.Now().Add(-time.Hour)},
+	}})
+	createFeed(&feed, u1)
+	defer service.FeedRepo().Delete(feed)
+
+	r := service.ArticleRepo()
+
+	_, since, err := r.ChangeRange()
+	if err != nil {
+		t.Fatalf("articleRepo.ChangeRange() error = %v", err)
+	}
+
+	all, err := r.All(content.FeedIDs([]content.FeedID{feed.ID}))
+	if err != nil {
+		t.Fatalf("articleRepo.All() error = %v", err)
+	}
+
+	ids := map[string]content.ArticleID{}
+	for _, a := range all {
+		ids[a.Title] = a.ID
+	}
+
+	first := content.IDs([]content.ArticleID{ids["Changes 1"]})
+	second := content.IDs([]content.ArticleID{ids["Changes 2"]})
+
+	steps := []struct {
+		name string
+		f    func() error
+	}{
+		{"unread first", func() error { return r.Read(false, u1, first) }},
+		{"unread first again", func() error { return r.Read(false, u1, first) }},
+		{"read first", func() error { return r.Read(true, u1, first) }},
+		{"read second", func() error { return r.Read(true, u1, second) }},
+		{"favor second", func() error { return r.Favor(true, u1, second) }},
+		{"unfavor second", func() error { return r.Favor(false, u1, second) }},
+		{"add third", func() error {
+			feed.Refresh(parser.Feed{Title: "changes", Articles: []parser.Article{
+				{Title: "Changes 3", Link: "http://sugr.org/changes/3", Date: time.Now()},
+			}})
+			articles, err := service.FeedRepo().Update(&feed)
+			if err == nil && len(articles) == 1 {
+				ids["Changes 3"] = articles[0].ID
+			}
+			return err
+		}},
+	}
+	for _, s := range steps {
+		if err := s.f(); err != nil {
+			t.Fatalf("%s error = %v", s.name, err)
+		}
+	}
+
+	want := []struct {
+		title string
+		typ   content.ChangeType
+	}{
+		{"Changes 1", content.ChangeUnread},
+		{"Changes 1", content.ChangeRead},
+		{"Changes 2", content.ChangeFavorite},
+		{"Changes 2", content.ChangeUnfavorite},
+		{"Changes 3", content.ChangeInsert},
+	}
+
+	changes, err := r.Changes(u1, since, 100)
+	if err != nil {
+		t.Fatalf("articleRepo.Changes() error = %v", err)
+	}
+
+	if len(changes) != len(want) {
+		t.Fatalf("articleRepo.Changes() = %v, want %d changes", changes, len(want))
+	}
+
+	for i, w := range want {
+		if changes[i].ArticleID != ids[w.title] || changes[i].Type != w.typ {
+			t.Errorf("articleRepo.Changes() change %d = %s, want %s %s", i, changes[i], w.typ, w.title)
+		}
+
+		if i > 0 && changes[i].ID <= changes[i-1].ID {
+			t.Errorf("articleRepo.Changes() change %d id %d not ascending", i, changes[i].ID)
+		}
+	}
+
+	if limited, err := r.Changes(u1, since, 2); err != nil || !reflect.DeepEqual(limited, changes[:2]) {
+		t.Errorf("articleRepo.Changes() limited = %v, err = %v", limited, err)
+	}
+
+	if rest, err := r.Changes(u1, changes[2].ID, 100); err != nil || !reflect.DeepEqual(rest, changes[3:]) {
+		t.Errorf("articleRepo.Changes() since = %v, err = %v", rest, err)
+	}
+
+	if other, err := r.Changes(u2, since, 100); err != nil || len(other) != 0 {
+		t.Errorf("articleRepo.Changes() other user = %v, err = %v", other, err)
+	}
+
+	feedIDs := content.FeedIDs([]content.FeedID{feed.ID})
+	if changed, err := r.IDs(u1, feedIDs, content.ChangedSince(time.Now().Add(-time.Hour))); err != nil || len(changed) != 3 {
+		t.Errorf("articleRepo.IDs() changed since an hour ago = %v, err = %v", changed, err)
+	}
+
+	if changed, err := r.IDs(u1, feedIDs, content.ChangedSince(time.Now().Add(time.Hour))); err != nil || len(changed) != 0 {
+		t.Errorf("articleRepo.IDs() changed since an hour later = %v, err = %v", changed, err)
+	}
+
+	oldest, newest, err := r.ChangeRange()
+	if err != nil {
+		t.Fatalf("articleRepo.ChangeRange() error = %v", err)
+	}
+
+	if oldest == 0 || oldest > changes[0].ID || newest != changes[len(changes)-1].ID {
+		t.Errorf("articleRepo.ChangeRange() = %d, %d, want <= %d, %d", oldest, newest, changes[0].ID, changes[len(changes)-1].ID)
+	}
+
+	if err := r.RemoveStaleChanges(); err != nil {
+		t.Fatalf("articleRepo.RemoveStaleChanges() error = %v", err)
+	}
+
+	if fresh, err := r.Changes(u1, since, 100); err != nil || len(fresh) != len(changes) {
+		t.Errorf("articleRepo.RemoveStaleChanges() removed fresh changes: %v, err = %v", fresh, err)
+	}
+
+	if _, err := r.Changes(content.User{}, 0, 1); err == nil {
+		t.Errorf("articleRepo.Changes() expected error for an invalid user")
+	}
+}
+
+func Test_articleRepo_SetNote(t *testing.T) {
+	skipTest(t)
+	setupArticle()
+
+	u1 := content.User{Login: user1}
+	u2 := content.User{Login: user2}
+	feed := content.Feed{Link: "http://sugr.org/notes", Title: "notes"}
+	feed.Refresh(parser.Feed{Title: "notes", Articles: []parser.Article{
+		{Title: "Notes 1", Link: "http://sugr.org/notes/1", Date: time.Now()},
+	}})
+	createFeed(&feed, u1)
+	defer service.FeedRepo().Delete(feed)
+
+	r := service.ArticleRepo()
+
+	all, err := r.All(content.FeedIDs([]content.FeedID{feed.ID}))
+	if err != nil || len(all) != 1 {
+		t.Fatalf("articleRepo.All() = %v, error = %v", all, err)
+	}
+	id := all[0].ID
+
+	note := func(user content.User) string {
+		articles, err := r.ForUser(user, content.IDs([]content.ArticleID{id}), content.IncludeNotes)
+		if err != nil {
+			t.Fatalf("articleRepo.ForUser() error = %v", err)
+		}
+
+		if len(articles) == 0 {
+			return ""
+		}
+
+		return articles[0].Note
+	}
+
+	tests := []struct {
+		name    string
+		user    content.User
+		note    string
+		want    string
+		wantErr bool
+	}{
+		{name: "invalid user", note: "note", wantErr: true},
+		{name: "unsubscribed user", user: u2, note: "note", wantErr: true},
+		{name: "create", user: u1, note: "first note", want: "first note"},
+		{name: "update", user: u1, note: "second note", want: "second note"},
+		{name: "remove", user: u1, note: "", want: ""},
+		{name: "remove missing", user: u1, note: "", want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := r.SetNote(tt.user, id, tt.note); (err != nil) != tt.wantErr {
+				t.Errorf("articleRepo.SetNote() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if tt.wantErr {
+				return
+			}
+
+			if got := note(tt.user); got != tt.want {
+				t.Errorf("articleRepo.SetNote() note = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -9,8 +9,10 @@ import (
 const (
 	ArticleStateEvent = "article-state-change"
 
-	read  = "read"
-	favor = "favor"
+	// The states of an ArticleStateData
+	ArticleStateRead  = "read"
+	ArticleStateFavor = "favor"
+	ArticleStateHide  = "hide"
 )
 
 type ArticleStateData struct {
@@ -41,7 +43,7 @@ func (r articleRepo) Read(state bool, user content.User, opts ...content.QueryOp
 
 		r.eventBus.Dispatch(
 			ArticleStateEvent,
-			ArticleStateData{user.Login, read, state, convertOptions(o)},
+			ArticleStateData{user.Login, ArticleStateRead, state, convertOptions(o)},
 		)
 
 		r.log.Debugf("Dispatch of article read state event end")
@@ -61,7 +63,7 @@ func (r articleRepo) Favor(state bool, user content.User, opts ...content.QueryO
 
 		r.eventBus.Dispatch(
 			ArticleStateEvent,
-			ArticleStateData{user.Login, favor, state, convertOptions(o)},
+			ArticleStateData{user.Login, ArticleStateFavor, state, convertOptions(o)},
 		)
 
 		r.log.Debugf("Dispatch of article favor state event end")
@@ -70,6 +72,26 @@ func (r articleRepo) Favor(state bool, user content.User, opts ...content.QueryO
 	return err
 }
 
+func (r articleRepo) Hide(state bool, user content.User, opts ...content.QueryOpt) error {
+	err := r.Article.Hide(state, user, opts...)
+
+	if err == nil {
+		r.log.Debugf("Dispatching article hide state event")
+
+		o := content.QueryOptions{}
+		o.Apply(opts)
+
+		r.eventBus.Dispatch(
+			ArticleStateEvent,
+			ArticleStateData{user.Login, ArticleStateHide, state, convertOptions(o)},
+		)
+
+		r.log.Debugf("Dispatch of article hide state event end")
+	}
+
+	return err
+}
+
 func convertOptions(o content.QueryOptions) map[string]interface{} {
 	data := map[string]interface{}{}
 
@@ -85,6 +107,10 @@ func convertOptions(o content.QueryOptions) map[string]interface{} {
 		data["favoriteOnly"] = true
 	}
 
+	if o.HiddenOnly {
+		data["hiddenOnly"] = true
+	}
+
 	if o.UntaggedOnly {
 		data["untaggedOnly"] = true
 	}
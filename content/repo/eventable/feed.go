@@ -12,6 +12,9 @@ const (
 	FeedUpdateEvent  = "feed-update"
 	FeedDeleteEvent  = "feed-delete"
 	FeedSetTagsEvent = "feed-set-tags"
+	FeedRefreshEvent = "feed-refresh"
+	FeedMergeEvent   = "feed-merge"
+	FeedDeadEvent    = "feed-dead"
 )
 
 type FeedUpdateData struct {
@@ -79,6 +82,58 @@ func (f FeedSetTagsData) FeedID() content.FeedID {
 	return f.Feed.ID
 }
 
+// FeedMergeData is sent when the users of a feed have been moved to another
+// one, usually after the feed has been permanently redirected to it.
+type FeedMergeData struct {
+	Feed content.Feed
+	Into content.Feed
+}
+
+func (f FeedMergeData) MarshalJSON() ([]byte, error) {
+	data := map[string]interface{}{}
+
+	data["feedID"] = f.Feed.ID
+	data["intoID"] = f.Into.ID
+	data["link"] = f.Into.Link
+
+	return json.Marshal(data)
+}
+
+func (f FeedMergeData) FeedID() content.FeedID {
+	return f.Feed.ID
+}
+
+// FeedDeadData is sent when a feed is marked as dead, and is no longer
+// updated.
+type FeedDeadData struct {
+	Feed content.Feed
+}
+
+func (f FeedDeadData) MarshalJSON() ([]byte, error) {
+	data := map[string]interface{}{}
+
+	data["feedID"] = f.Feed.ID
+	data["error"] = f.Feed.UpdateError
+
+	return json.Marshal(data)
+}
+
+func (f FeedDeadData) FeedID() content.FeedID {
+	return f.Feed.ID
+}
+
+// FeedRefreshData holds the outcome of a feed refresh requested by a user.
+type FeedRefreshData struct {
+	User        content.Login  `json:"user"`
+	FeedID      content.FeedID `json:"feedID"`
+	NewArticles int            `json:"newArticles"`
+	Error       string         `json:"error,omitempty"`
+}
+
+func (f FeedRefreshData) UserLogin() content.Login {
+	return f.User
+}
+
 type feedRepo struct {
 	repo.Feed
 	eventBus bus
@@ -86,6 +141,15 @@ type feedRepo struct {
 }
 
 func (r feedRepo) Update(feed *content.Feed) ([]content.Article, error) {
+	// Dead feeds are no longer updated, so the event is only sent when a
+	// feed is first marked as such.
+	died := feed.Dead
+	if died && feed.ID != 0 {
+		if stored, err := r.Feed.Get(feed.ID, content.User{}); err == nil && stored.Dead {
+			died = false
+		}
+	}
+
 	articles, err := r.Feed.Update(feed)
 
 	if err == nil && len(articles) > 0 {
@@ -99,6 +163,17 @@ func (r feedRepo) Update(feed *content.Feed) ([]content.Article, error) {
 		r.log.Debugf("Dispatch of feed update event end")
 	}
 
+	if err == nil && died {
+		r.log.Debugf("Dispatching feed dead event")
+
+		r.eventBus.Dispatch(
+			FeedDeadEvent,
+			FeedDeadData{*feed},
+		)
+
+		r.log.Debugf("Dispatch of feed dead event end")
+	}
+
 	return articles, err
 }
 
@@ -119,6 +194,23 @@ func (r feedRepo) Delete(feed content.Feed) error {
 	return err
 }
 
+func (r feedRepo) Merge(feed, into content.Feed) error {
+	err := r.Feed.Merge(feed, into)
+
+	if err == nil {
+		r.log.Debugf("Dispatching feed merge event")
+
+		r.eventBus.Dispatch(
+			FeedMergeEvent,
+			FeedMergeData{feed, into},
+		)
+
+		r.log.Debugf("Dispatch of feed merge event end")
+	}
+
+	return err
+}
+
 func (r feedRepo) SetUserTags(feed content.Feed, user content.User, tags []*content.Tag) error {
 	err := r.Feed.SetUserTags(feed, user, tags)
 
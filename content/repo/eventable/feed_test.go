@@ -0,0 +1,64 @@
+package eventable
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/urandom/readeef/config"
+	"github.com/urandom/readeef/content"
+	"github.com/urandom/readeef/content/repo/mock_repo"
+	"github.com/urandom/readeef/log"
+)
+
+func Test_feedRepo_Update_dead(t *testing.T) {
+	tests := []struct {
+		name       string
+		feed       content.Feed
+		storedDead bool
+		wantEvent  bool
+	}{
+		{"alive", content.Feed{ID: 1}, false, false},
+		{"marked dead", content.Feed{ID: 1, Dead: true}, false, true},
+		{"already dead", content.Feed{ID: 1, Dead: true}, true, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			mockRepo := mock_repo.NewMockFeed(ctrl)
+			if tt.feed.Dead {
+				mockRepo.EXPECT().Get(tt.feed.ID, content.User{}).Return(content.Feed{ID: tt.feed.ID, Dead: tt.storedDead}, nil)
+			}
+			mockRepo.EXPECT().Update(&tt.feed).Return(nil, nil)
+
+			b := newBus(ctx)
+			l := b.Listener()
+
+			cfg := config.Log{}
+			cfg.Converted.Writer = os.Stderr
+
+			r := feedRepo{mockRepo, b, log.WithStd(cfg)}
+			if _, err := r.Update(&tt.feed); err != nil {
+				t.Fatalf("feedRepo.Update() error = %v", err)
+			}
+
+			select {
+			case e := <-l:
+				if !tt.wantEvent || e.Name != FeedDeadEvent {
+					t.Errorf("feedRepo.Update() event = %#v, want event %v", e, tt.wantEvent)
+				}
+			case <-time.After(50 * time.Millisecond):
+				if tt.wantEvent {
+					t.Errorf("feedRepo.Update() no feed dead event")
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,19 @@
+package eventable
+
+import "github.com/urandom/readeef/content"
+
+const (
+	SavedSearchMatchEvent = "saved-search-match"
+)
+
+// SavedSearchMatchData holds the new articles that were found by a user's
+// saved search.
+type SavedSearchMatchData struct {
+	User          content.Login         `json:"user"`
+	SavedSearchID content.SavedSearchID `json:"savedSearchID"`
+	ArticleIDs    []content.ArticleID   `json:"articleIDs"`
+}
+
+func (e SavedSearchMatchData) UserLogin() content.Login {
+	return e.User
+}
@@ -29,6 +29,12 @@ func (s Service) Listener() Stream {
 	return s.eventBus.Listener()
 }
 
+// Dispatch sends an event that isn't the result of a repository change to
+// the listeners.
+func (s Service) Dispatch(name string, data interface{}) {
+	s.eventBus.Dispatch(name, data)
+}
+
 func (s Service) ArticleRepo() repo.Article {
 	return s.article
 }
@@ -16,6 +16,7 @@ type Feed interface {
 
 	Update(*content.Feed) ([]content.Article, error)
 	Delete(content.Feed) error
+	Merge(content.Feed, content.Feed) error
 
 	Users(content.Feed) ([]content.User, error)
 	AttachTo(content.Feed, content.User) error
@@ -0,0 +1,13 @@
+package repo
+
+import "github.com/urandom/readeef/content"
+
+// FeedHealth allows recording feed fetches, and fetching the content.FeedHealth
+// and content.FeedFetch objects
+type FeedHealth interface {
+	Get(content.Feed) (content.FeedHealth, error)
+	History(content.Feed, int) ([]content.FeedFetch, error)
+	Failing() ([]content.FeedHealth, error)
+
+	Record(content.FeedFetch) (content.FeedHealth, error)
+}
@@ -0,0 +1,111 @@
+package repo_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/urandom/readeef/content"
+)
+
+func Test_feedHealthRepo(t *testing.T) {
+	skipTest(t)
+	setupFeed()
+
+	r := service.FeedHealthRepo()
+
+	if _, err := r.Get(feed1); errors.Cause(err) != content.ErrNoContent {
+		t.Fatalf("feedHealthRepo.Get() error = %v, wanted no content", err)
+	}
+
+	if _, err := r.Record(content.FeedFetch{FeedID: feed1.ID}); err == nil {
+		t.Errorf("feedHealthRepo.Record() invalid fetch error = nil")
+	}
+
+	start := time.Now().UTC().Truncate(time.Second).Add(-time.Hour)
+
+	fetches := []content.FeedFetch{
+		{FeedID: feed1.ID, Time: start, StatusCode: 200, Duration: time.Second, Bytes: 1024, NewArticles: 2},
+		{FeedID: feed1.ID, Time: start.Add(time.Minute), StatusCode: 503, Duration: time.Second, ErrorClass: content.FetchErrorHTTP, Error: "HTTP Status: 503"},
+		{FeedID: feed1.ID, Time: start.Add(2 * time.Minute), Duration: time.Second, ErrorClass: content.FetchErrorTimeout, Error: "timeout"},
+		{FeedID: feed2.ID, Time: start, StatusCode: 200, Duration: time.Second, Bytes: 512},
+	}
+
+	var health content.FeedHealth
+	for _, f := range fetches {
+		h, err := r.Record(f)
+		if err != nil {
+			t.Fatalf("feedHealthRepo.Record() error = %v", err)
+		}
+		if f.FeedID == feed1.ID {
+			health = h
+		}
+	}
+
+	want := content.FeedHealth{
+		FeedID: feed1.ID, LastFetch: start.Add(2 * time.Minute), LastSuccess: start,
+		FailingSince: start.Add(time.Minute), Failures: 2,
+		LastErrorClass: content.FetchErrorTimeout, LastError: "timeout",
+	}
+	if !sameHealth(health, want) {
+		t.Errorf("feedHealthRepo.Record() = %#v, want %#v", health, want)
+	}
+
+	got, err := r.Get(feed1)
+	if err != nil {
+		t.Fatalf("feedHealthRepo.Get() error = %v", err)
+	}
+
+	if !sameHealth(got, want) {
+		t.Errorf("feedHealthRepo.Get() = %#v, want %#v", got, want)
+	}
+
+	history, err := r.History(feed1, 2)
+	if err != nil {
+		t.Fatalf("feedHealthRepo.History() error = %v", err)
+	}
+
+	if len(history) != 2 || !history[0].Time.Equal(fetches[2].Time) || !history[1].Time.Equal(fetches[1].Time) {
+		t.Fatalf("feedHealthRepo.History() = %#v", history)
+	}
+
+	if history[1].StatusCode != 503 || history[1].Duration != time.Second || history[1].ErrorClass != content.FetchErrorHTTP {
+		t.Errorf("feedHealthRepo.History() = %#v, want %#v", history[1], fetches[1])
+	}
+
+	failing, err := r.Failing()
+	if err != nil {
+		t.Fatalf("feedHealthRepo.Failing() error = %v", err)
+	}
+
+	if len(failing) != 1 || failing[0].FeedID != feed1.ID {
+		t.Errorf("feedHealthRepo.Failing() = %#v", failing)
+	}
+
+	// Only the latest fetches are kept
+	for i := 0; i < content.FeedFetchHistorySize; i++ {
+		if _, err := r.Record(content.FeedFetch{FeedID: feed2.ID, Time: start.Add(time.Duration(i+1) * time.Second), StatusCode: 304}); err != nil {
+			t.Fatalf("feedHealthRepo.Record() error = %v", err)
+		}
+	}
+
+	if history, err = r.History(feed2, 2*content.FeedFetchHistorySize); err != nil {
+		t.Fatalf("feedHealthRepo.History() error = %v", err)
+	}
+
+	if len(history) != content.FeedFetchHistorySize || history[len(history)-1].StatusCode != 304 {
+		t.Errorf("feedHealthRepo.History() len = %d, want %d", len(history), content.FeedFetchHistorySize)
+	}
+}
+
+// sameHealth compares the health while ignoring the time locations, which
+// depend on the database.
+func sameHealth(a, b content.FeedHealth) bool {
+	if !a.LastFetch.Equal(b.LastFetch) || !a.LastSuccess.Equal(b.LastSuccess) || !a.FailingSince.Equal(b.FailingSince) {
+		return false
+	}
+
+	a.LastFetch, a.LastSuccess, a.FailingSince = b.LastFetch, b.LastSuccess, b.FailingSince
+
+	return a == b
+}
@@ -0,0 +1,9 @@
+package repo
+
+import "github.com/urandom/readeef/content"
+
+// FeedImage allows fetching and manipulating content.FeedImage objects
+type FeedImage interface {
+	Get(content.Feed) (content.FeedImage, error)
+	Update(content.FeedImage) error
+}
@@ -0,0 +1,102 @@
+package repo_test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/urandom/readeef/content"
+)
+
+func Test_feedImageRepo_Get(t *testing.T) {
+	skipTest(t)
+	setupFeed()
+
+	date := time.Now().Truncate(time.Second)
+
+	tests := []struct {
+		name      string
+		feed      content.Feed
+		want      content.FeedImage
+		wantErr   bool
+		noContent bool
+	}{
+		{"valid", feed1, content.FeedImage{FeedID: feed1.ID, Title: "image", URL: "http://sugr.org/image.png", Width: 16, Height: 16, Icon: []byte("icon"), IconType: "image/png", FetchDate: date}, false, false},
+		{"invalid", content.Feed{}, content.FeedImage{}, true, false},
+		{"no content", feed2, content.FeedImage{}, true, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := service.FeedImageRepo()
+			if !tt.noContent && !tt.wantErr {
+				if err := r.Update(tt.want); err != nil {
+					t.Errorf("feedImageRepo.Get() preliminary update error = %v", err)
+					return
+				}
+			}
+			got, err := r.Get(tt.feed)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("feedImageRepo.Get() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if tt.noContent && errors.Cause(err) != content.ErrNoContent {
+				t.Errorf("feedImageRepo.Get() error = %v, wanted no content", err)
+				return
+			}
+
+			if !got.FetchDate.Equal(tt.want.FetchDate) {
+				t.Errorf("feedImageRepo.Get() fetch date = %v, want %v", got.FetchDate, tt.want.FetchDate)
+			}
+
+			got.FetchDate, tt.want.FetchDate = time.Time{}, time.Time{}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("feedImageRepo.Get() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_feedImageRepo_Update(t *testing.T) {
+	skipTest(t)
+	setupFeed()
+
+	tests := []struct {
+		name    string
+		feed    content.Feed
+		image   content.FeedImage
+		wantErr bool
+	}{
+		{"create", feed1, content.FeedImage{FeedID: feed1.ID, URL: "http://sugr.org/image.png", FetchDate: time.Now().Truncate(time.Second)}, false},
+		{"update", feed1, content.FeedImage{FeedID: feed1.ID, Icon: []byte("icon"), IconType: "image/x-icon", FetchDate: time.Now().Truncate(time.Second)}, false},
+		{"invalid", content.Feed{}, content.FeedImage{URL: "http://sugr.org/image.png"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := service.FeedImageRepo()
+			if err := r.Update(tt.image); (err != nil) != tt.wantErr {
+				t.Errorf("feedImageRepo.Update() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr {
+				return
+			}
+
+			got, err := r.Get(tt.feed)
+			if err != nil {
+				t.Errorf("feedImageRepo.Update() post fetch error = %v", err)
+				return
+			}
+
+			if !got.FetchDate.Equal(tt.image.FetchDate) {
+				t.Errorf("feedImageRepo.Update() fetch date = %v, want %v", got.FetchDate, tt.image.FetchDate)
+			}
+
+			got.FetchDate, tt.image.FetchDate = time.Time{}, time.Time{}
+			if !reflect.DeepEqual(got, tt.image) {
+				t.Errorf("feedImageRepo.Update() = %v, want %v", got, tt.image)
+			}
+		})
+	}
+}
@@ -0,0 +1,10 @@
+package repo
+
+import "github.com/urandom/readeef/content"
+
+// FeedSettings allows fetching and manipulating content.FeedSettings objects
+type FeedSettings interface {
+	Get(content.Feed) (content.FeedSettings, error)
+
+	Update(content.FeedSettings) error
+}
@@ -0,0 +1,102 @@
+package repo_test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/urandom/readeef/content"
+)
+
+func Test_feedSettingsRepo(t *testing.T) {
+	skipTest(t)
+	setupFeed()
+
+	r := service.FeedSettingsRepo()
+
+	if _, err := r.Get(feed2); errors.Cause(err) != content.ErrNoContent {
+		t.Fatalf("feedSettingsRepo.Get() error = %v, wanted no content", err)
+	}
+
+	if err := r.Update(content.FeedSettings{FeedID: feed2.ID, UpdateInterval: time.Second}); err == nil {
+		t.Errorf("feedSettingsRepo.Update() invalid settings error = nil")
+	}
+
+	settings := content.FeedSettings{
+		FeedID:         feed2.ID,
+		Owner:          user1,
+		UpdateInterval: time.Hour,
+		UserAgent:      "readeef-test",
+		Headers:        content.FeedHeaders{"X-Token": "abcd"},
+		Username:       "user",
+		Password:       "pass",
+		ExtractContent: true,
+	}
+
+	if err := r.Update(settings); err != nil {
+		t.Fatalf("feedSettingsRepo.Update() error = %v", err)
+	}
+
+	got, err := r.Get(feed2)
+	if err != nil {
+		t.Fatalf("feedSettingsRepo.Get() error = %v", err)
+	}
+
+	if !reflect.DeepEqual(got, settings) {
+		t.Errorf("feedSettingsRepo.Get() = %#v, want %#v", got, settings)
+	}
+
+	settings.Paused = true
+	settings.Headers = nil
+	settings.Username, settings.Password, settings.Cookie = "", "", "session=abcd"
+
+	if err := r.Update(settings); err != nil {
+		t.Fatalf("feedSettingsRepo.Update() error = %v", err)
+	}
+
+	if got, err = r.Get(feed2); err != nil {
+		t.Fatalf("feedSettingsRepo.Get() error = %v", err)
+	}
+
+	if !reflect.DeepEqual(got, settings) {
+		t.Errorf("feedSettingsRepo.Get() = %#v, want %#v", got, settings)
+	}
+}
+
+func Test_feedSettingsRepo_private(t *testing.T) {
+	skipTest(t)
+	setupFeed()
+
+	u1, u2 := content.User{Login: user1}, content.User{Login: user2}
+	feed := content.Feed{Link: "http://sugr.org/private", Title: "private"}
+	createFeed(&feed, u1)
+
+	r := service.FeedRepo()
+	defer r.Delete(feed)
+
+	settings := content.FeedSettings{FeedID: feed.ID, Owner: user1, Cookie: "session=abcd"}
+	if err := service.FeedSettingsRepo().Update(settings); err != nil {
+		t.Fatalf("feedSettingsRepo.Update() error = %v", err)
+	}
+
+	if err := r.AttachTo(feed, u2); errors.Cause(err) != content.ErrPrivateFeed {
+		t.Errorf("feedRepo.AttachTo() other user error = %v, want %v", err, content.ErrPrivateFeed)
+	}
+
+	if err := r.AttachTo(feed, u1); err != nil {
+		t.Errorf("feedRepo.AttachTo() owner error = %v", err)
+	}
+
+	if err := r.DetachFrom(feed, u1); err != nil {
+		t.Fatalf("feedRepo.DetachFrom() error = %v", err)
+	}
+
+	if _, err := service.FeedSettingsRepo().Get(feed); errors.Cause(err) != content.ErrNoContent {
+		t.Errorf("feedSettingsRepo.Get() after the owner left error = %v, wanted no content", err)
+	}
+
+	if err := r.AttachTo(feed, u2); err != nil {
+		t.Errorf("feedRepo.AttachTo() after the owner left error = %v", err)
+	}
+}
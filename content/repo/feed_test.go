@@ -447,6 +447,123 @@ func Test_feedRepo_SetUserTags(t *testing.T) {
 		})
 	}
 }
+func Test_feedRepo_Merge(t *testing.T) {
+	skipTest(t)
+	setupFeed()
+
+	r := service.FeedRepo()
+	u1, u2 := content.User{Login: user1}, content.User{Login: user2}
+
+	feed := content.Feed{Link: "http://sugr.org/20"}
+	feed.Refresh(parser.Feed{Articles: []parser.Article{
+		{Title: "Article 20", Link: "http://sugr.org/20/a/20"},
+		{Title: "Article 21", Link: "http://sugr.org/20/a/21"},
+	}})
+	createFeed(&feed, u1, u2)
+
+	into := content.Feed{Link: "http://sugr.org/21"}
+	into.Refresh(parser.Feed{Articles: []parser.Article{
+		{Title: "Article 20", Link: "http://sugr.org/20/a/20"},
+	}})
+	createFeed(&into, u1)
+
+	articles := map[string]content.ArticleID{}
+	for _, f := range []content.Feed{feed, into} {
+		got, err := service.ArticleRepo().All(content.FeedIDs([]content.FeedID{f.ID}))
+		if err != nil {
+			t.Fatalf("articleRepo.All() error = %v", err)
+		}
+
+		for _, a := range got {
+			articles[fmt.Sprintf("%d %s", a.FeedID, a.Title)] = a.ID
+		}
+	}
+
+	shared := articles[fmt.Sprintf("%d Article 20", feed.ID)]
+	moved := articles[fmt.Sprintf("%d Article 21", feed.ID)]
+	counterpart := articles[fmt.Sprintf("%d Article 20", into.ID)]
+
+	if err := service.ArticleRepo().Favor(true, u2, content.IDs([]content.ArticleID{shared, moved})); err != nil {
+		t.Fatalf("articleRepo.Favor() error = %v", err)
+	}
+
+	if err := service.ArticleRepo().SetNote(u2, shared, "shared note"); err != nil {
+		t.Fatalf("articleRepo.SetNote() error = %v", err)
+	}
+
+	if err := service.FeedSettingsRepo().Update(content.FeedSettings{FeedID: feed.ID, UserAgent: "merged"}); err != nil {
+		t.Fatalf("feedSettingsRepo.Update() error = %v", err)
+	}
+
+	if err := r.SetUserTags(feed, u1, []*content.Tag{{Value: "tag 30"}}); err != nil {
+		t.Fatalf("feedRepo.SetUserTags() error = %v", err)
+	}
+
+	search := content.SavedSearch{UserLogin: user2, Name: "merged", Query: "merged", FeedID: feed.ID}
+	if err := service.SavedSearchRepo().Update(&search); err != nil {
+		t.Fatalf("savedSearchRepo.Update() error = %v", err)
+	}
+
+	if err := r.Merge(feed, feed); err == nil {
+		t.Errorf("feedRepo.Merge() into itself expected error")
+	}
+
+	if err := r.Merge(feed, into); err != nil {
+		t.Fatalf("feedRepo.Merge() error = %v", err)
+	}
+
+	if _, err := r.Get(feed.ID, content.User{}); !content.IsNoContent(err) {
+		t.Errorf("feedRepo.Get() merged feed error = %v, want no content", err)
+	}
+
+	if users, err := r.Users(into); err != nil {
+		t.Errorf("feedRepo.Users() error = %v", err)
+	} else if len(users) != 2 {
+		t.Errorf("feedRepo.Users() count = %d, want 2", len(users))
+	}
+
+	if tags, err := service.TagRepo().ForFeed(into, u1); err != nil {
+		t.Errorf("tagRepo.ForFeed() error = %v", err)
+	} else if len(tags) != 1 || tags[0].Value != "tag 30" {
+		t.Errorf("tagRepo.ForFeed() = %#v, want tag 30", tags)
+	}
+
+	favorites, err := service.ArticleRepo().ForUser(u2, content.FavoriteOnly, content.IncludeNotes, content.FeedIDs([]content.FeedID{into.ID}))
+	if err != nil {
+		t.Fatalf("articleRepo.ForUser() error = %v", err)
+	}
+
+	sort.Slice(favorites, func(i, j int) bool {
+		return favorites[i].Title < favorites[j].Title
+	})
+
+	if len(favorites) != 2 || favorites[0].ID != counterpart || favorites[1].ID != moved {
+		t.Errorf("articleRepo.ForUser() favorites = %#v, want %d and %d", favorites, counterpart, moved)
+	} else if favorites[0].Note != "shared note" {
+		t.Errorf("articleRepo.ForUser() note = %q, want %q", favorites[0].Note, "shared note")
+	}
+
+	if settings, err := service.FeedSettingsRepo().Get(into); err != nil {
+		t.Errorf("feedSettingsRepo.Get() error = %v", err)
+	} else if settings.UserAgent != "merged" {
+		t.Errorf("feedSettingsRepo.Get() user agent = %q, want %q", settings.UserAgent, "merged")
+	}
+
+	if got, err := service.SavedSearchRepo().Get(search.ID, u2); err != nil {
+		t.Errorf("savedSearchRepo.Get() error = %v", err)
+	} else if got.FeedID != into.ID {
+		t.Errorf("savedSearchRepo.Get() feed id = %d, want %d", got.FeedID, into.ID)
+	}
+
+	if err := service.SavedSearchRepo().Delete(search); err != nil {
+		t.Errorf("savedSearchRepo.Delete() error = %v", err)
+	}
+
+	if err := r.Delete(into); err != nil {
+		t.Errorf("feedRepo.Delete() error %v", err)
+	}
+}
+
 func createFeed(feed *content.Feed, users ...content.User) {
 	r := service.FeedRepo()
 
@@ -473,7 +590,9 @@ func setupFeed() {
 		u2 := content.User{Login: user2}
 
 		feed1.Refresh(parser.Feed{Title: "feed 1", Articles: []parser.Article{
-			{Title: "Article 1", Description: "Description 1", Link: "http://sugr.org/1/a/1", Date: time.Now()},
+			{Title: "Article 1", Description: "Description 1", Link: "http://sugr.org/1/a/1", Date: time.Now(), Author: "John Doe", Categories: []string{"Go", "Feeds"}, Enclosures: []parser.Enclosure{
+				{Link: "http://sugr.org/1/a/1/episode.mp3", Type: "audio/mpeg", Medium: "audio", Length: 1024, Duration: 90 * time.Second},
+			}},
 			{Title: "Article 2", Description: "Description 2", Link: "http://sugr.org/1/a/2", Date: time.Now().Add(-1 * time.Hour)},
 			{Title: "Article 3", Description: "Description 3", Link: "http://sugr.org/1/a/3", Date: time.Now().Add(-2 * time.Hour)},
 			{Title: "Article 4", Description: "Description 4", Link: "http://sugr.org/1/a/4", Date: time.Now().Add(-3 * time.Hour)},
@@ -481,7 +600,7 @@ func setupFeed() {
 
 		feed2.Refresh(parser.Feed{Title: "feed 2", Articles: []parser.Article{
 			{Title: "Article 5", Description: "Description 5", Link: "http://sugr.org/2/a/5", Date: time.Now().Add(-1 * time.Hour)},
-			{Title: "Article 6", Description: "Description 6", Link: "http://sugr.org/2/a/6", Date: time.Now().Add(-2 * time.Hour)},
+			{Title: "Article 6", Description: "Description 6", Link: "http://sugr.org/2/a/6", Date: time.Now().Add(-2 * time.Hour), Author: "john doe", Categories: []string{"go"}},
 			{Title: "Article 7", Description: "Description 7", Link: "http://sugr.org/2/a/7", Date: time.Now().Add(-3 * time.Hour)},
 			{Title: "Article 8", Description: "Description 8", Link: "http://sugr.org/2/a/8", Date: time.Now().Add(-4 * time.Hour)},
 			{Title: "Article 9", Description: "Description 9", Link: "http://sugr.org/2/a/9", Date: time.Now().Add(-5 * time.Hour)},
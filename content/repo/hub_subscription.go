@@ -0,0 +1,18 @@
+package repo
+
+import (
+	"time"
+
+	"github.com/urandom/readeef/content"
+)
+
+// HubSubscription allows fetching and manipulating content.HubSubscription
+// objects
+type HubSubscription interface {
+	All() ([]content.HubSubscription, error)
+	ForTopic(string) ([]content.HubSubscription, error)
+
+	Update(content.HubSubscription) error
+	Delete(content.HubSubscription) error
+	DeleteExpired(time.Time) error
+}
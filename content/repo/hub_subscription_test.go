@@ -0,0 +1,98 @@
+package repo_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/urandom/readeef/content"
+)
+
+func Test_hubSubscriptionRepo(t *testing.T) {
+	skipTest(t)
+
+	r := service.HubSubscriptionRepo()
+
+	if err := r.Update(content.HubSubscription{Topic: "topic", Callback: "http://sub.example.com"}); err == nil {
+		t.Errorf("hubSubscriptionRepo.Update() invalid subscription error = nil")
+	}
+
+	now := time.Now().Truncate(time.Second)
+	sub1 := content.HubSubscription{
+		Topic:       "http://example.com/api/v2/published/user1/token/atom",
+		Callback:    "http://sub1.example.com/callback",
+		Secret:      "secret",
+		LeaseExpiry: now.Add(time.Hour),
+	}
+	sub2 := content.HubSubscription{
+		Topic:       sub1.Topic,
+		Callback:    "http://sub2.example.com/callback",
+		LeaseExpiry: now.Add(-time.Hour),
+	}
+	sub3 := content.HubSubscription{
+		Topic:       "http://example.com/api/v2/published/user2/token/rss",
+		Callback:    sub1.Callback,
+		LeaseExpiry: now.Add(time.Hour),
+	}
+
+	for _, s := range []content.HubSubscription{sub1, sub2, sub3} {
+		if err := r.Update(s); err != nil {
+			t.Fatalf("hubSubscriptionRepo.Update() error = %v", err)
+		}
+	}
+
+	sub1.Secret = "renewed"
+	sub1.LeaseExpiry = now.Add(2 * time.Hour)
+	if err := r.Update(sub1); err != nil {
+		t.Fatalf("hubSubscriptionRepo.Update() renew error = %v", err)
+	}
+
+	all, err := r.All()
+	if err != nil {
+		t.Fatalf("hubSubscriptionRepo.All() error = %v", err)
+	}
+
+	if len(all) != 3 {
+		t.Fatalf("hubSubscriptionRepo.All() = %v, want 3 subscriptions", all)
+	}
+
+	subs, err := r.ForTopic(sub1.Topic)
+	if err != nil {
+		t.Fatalf("hubSubscriptionRepo.ForTopic() error = %v", err)
+	}
+
+	if len(subs) != 2 {
+		t.Fatalf("hubSubscriptionRepo.ForTopic() = %v, want 2 subscriptions", subs)
+	}
+
+	if subs[0].Callback != sub1.Callback || subs[0].Secret != "renewed" || !subs[0].LeaseExpiry.Equal(sub1.LeaseExpiry) {
+		t.Errorf("hubSubscriptionRepo.ForTopic() = %v, want %v", subs[0], sub1)
+	}
+
+	if err := r.DeleteExpired(now); err != nil {
+		t.Fatalf("hubSubscriptionRepo.DeleteExpired() error = %v", err)
+	}
+
+	if subs, err = r.ForTopic(sub1.Topic); err != nil {
+		t.Fatalf("hubSubscriptionRepo.ForTopic() error = %v", err)
+	}
+
+	if len(subs) != 1 || subs[0].Callback != sub1.Callback {
+		t.Errorf("hubSubscriptionRepo.DeleteExpired() left %v, want only %v", subs, sub1)
+	}
+
+	if err := r.Delete(sub3); err != nil {
+		t.Fatalf("hubSubscriptionRepo.Delete() error = %v", err)
+	}
+
+	if subs, err = r.ForTopic(sub3.Topic); err != nil {
+		t.Fatalf("hubSubscriptionRepo.ForTopic() error = %v", err)
+	}
+
+	if len(subs) != 0 {
+		t.Errorf("hubSubscriptionRepo.Delete() left %v", subs)
+	}
+
+	if err := r.Delete(sub1); err != nil {
+		t.Fatalf("hubSubscriptionRepo.Delete() error = %v", err)
+	}
+}
@@ -0,0 +1,15 @@
+package repo
+
+import "github.com/urandom/readeef/content"
+
+// Label allows fetching and manipulating content.Label objects
+type Label interface {
+	Get(content.LabelID, content.User) (content.Label, error)
+	ForUser(content.User) ([]content.Label, error)
+
+	Update(*content.Label) error
+	Delete(content.Label) error
+
+	Assign(content.Label, []content.ArticleID) error
+	Unassign(content.Label, []content.ArticleID) error
+}
@@ -0,0 +1,151 @@
+package repo_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/urandom/readeef/content"
+)
+
+func Test_labelRepo(t *testing.T) {
+	skipTest(t)
+	setupArticle()
+
+	r := service.LabelRepo()
+	articleRepo := service.ArticleRepo()
+	user := content.User{Login: user1}
+
+	if err := r.Update(&content.Label{UserLogin: user1}); err == nil {
+		t.Errorf("labelRepo.Update() invalid label error = nil")
+	}
+
+	label := content.Label{UserLogin: user1, Name: "Later"}
+	if err := r.Update(&label); err != nil {
+		t.Fatalf("labelRepo.Update() error = %v", err)
+	}
+
+	if label.ID == 0 {
+		t.Fatalf("labelRepo.Update() did not set label id")
+	}
+
+	if err := r.Update(&content.Label{UserLogin: user1, Name: "Later"}); err == nil {
+		t.Errorf("labelRepo.Update() duplicate name error = nil")
+	}
+
+	got, err := r.Get(label.ID, user)
+	if err != nil {
+		t.Fatalf("labelRepo.Get() error = %v", err)
+	}
+
+	if !reflect.DeepEqual(got, label) {
+		t.Errorf("labelRepo.Get() = %v, want %v", got, label)
+	}
+
+	if _, err := r.Get(label.ID, content.User{Login: user2}); errors.Cause(err) != content.ErrNoContent {
+		t.Errorf("labelRepo.Get() other user error = %v, wanted no content", err)
+	}
+
+	label.Name = "Read later"
+	if err := r.Update(&label); err != nil {
+		t.Fatalf("labelRepo.Update() error = %v", err)
+	}
+
+	labels, err := r.ForUser(user)
+	if err != nil {
+		t.Fatalf("labelRepo.ForUser() error = %v", err)
+	}
+
+	if want := []content.Label{label}; !reflect.DeepEqual(labels, want) {
+		t.Errorf("labelRepo.ForUser() = %v, want %v", labels, want)
+	}
+
+	other := content.Label{UserLogin: user2, Name: "Read later"}
+	if err := r.Update(&other); err != nil {
+		t.Fatalf("labelRepo.Update() other user error = %v", err)
+	}
+
+	labelled := []content.ArticleID{articles[0].ID, articles[4].ID}
+	if err := r.Assign(label, labelled); err != nil {
+		t.Fatalf("labelRepo.Assign() error = %v", err)
+	}
+
+	// Assigning twice is a no-op, while the other user can't label articles
+	// from feeds they aren't subscribed to.
+	if err := r.Assign(label, labelled[:1]); err != nil {
+		t.Fatalf("labelRepo.Assign() error = %v", err)
+	}
+
+	if err := r.Assign(other, labelled); err != nil {
+		t.Fatalf("labelRepo.Assign() other user error = %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		user  content.Login
+		label content.LabelID
+		want  []content.ArticleID
+	}{
+		{"user1", user1, label.ID, labelled},
+		{"user2", user2, other.ID, labelled[1:]},
+		{"user2 with user1 label", user2, label.ID, []content.ArticleID{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u := content.User{Login: tt.user}
+			opts := []content.QueryOpt{
+				content.LabelIDs([]content.LabelID{tt.label}), content.IncludeLabels,
+				content.Sorting(content.SortByID, content.AscendingOrder),
+			}
+
+			got, err := articleRepo.ForUser(u, opts...)
+			if err != nil {
+				t.Fatalf("articleRepo.ForUser() error = %v", err)
+			}
+
+			ids := []content.ArticleID{}
+			for _, a := range got {
+				ids = append(ids, a.ID)
+
+				if !reflect.DeepEqual(a.Labels, []content.LabelID{tt.label}) {
+					t.Errorf("articleRepo.ForUser() article %s labels = %v", a, a.Labels)
+				}
+			}
+
+			if !reflect.DeepEqual(ids, tt.want) {
+				t.Errorf("articleRepo.ForUser() = %v, want %v", ids, tt.want)
+			}
+
+			count, err := articleRepo.Count(u, opts...)
+			if err != nil {
+				t.Fatalf("articleRepo.Count() error = %v", err)
+			}
+
+			if count != int64(len(tt.want)) {
+				t.Errorf("articleRepo.Count() = %d, want %d", count, len(tt.want))
+			}
+		})
+	}
+
+	if err := r.Unassign(label, labelled[:1]); err != nil {
+		t.Fatalf("labelRepo.Unassign() error = %v", err)
+	}
+
+	if count, err := articleRepo.Count(user, content.LabelIDs([]content.LabelID{label.ID})); err != nil || count != 1 {
+		t.Errorf("labelRepo.Unassign() count = %d, %v, want 1", count, err)
+	}
+
+	for _, l := range []content.Label{label, other} {
+		if err := r.Delete(l); err != nil {
+			t.Fatalf("labelRepo.Delete() error = %v", err)
+		}
+	}
+
+	if _, err := r.Get(label.ID, user); errors.Cause(err) != content.ErrNoContent {
+		t.Errorf("labelRepo.Get() after delete error = %v, wanted no content", err)
+	}
+
+	if count, err := articleRepo.Count(user, content.LabelIDs([]content.LabelID{label.ID})); err != nil || count != 0 {
+		t.Errorf("labelRepo.Delete() count = %d, %v, want 0", count, err)
+	}
+}
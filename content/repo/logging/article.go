@@ -74,6 +74,26 @@ func (r articleRepo) Favor(state bool, user content.User, opts ...content.QueryO
 	return err
 }
 
+func (r articleRepo) Hide(state bool, user content.User, opts ...content.QueryOpt) error {
+	start := time.Now()
+
+	err := r.Article.Hide(state, user, opts...)
+
+	r.log.Infof("repo.Article.Hide took %s", time.Now().Sub(start))
+
+	return err
+}
+
+func (r articleRepo) SetNote(user content.User, id content.ArticleID, note string) error {
+	start := time.Now()
+
+	err := r.Article.SetNote(user, id, note)
+
+	r.log.Infof("repo.Article.SetNote took %s", time.Now().Sub(start))
+
+	return err
+}
+
 func (r articleRepo) RemoveStaleUnreadRecords() error {
 	start := time.Now()
 
@@ -83,3 +103,53 @@ func (r articleRepo) RemoveStaleUnreadRecords() error {
 
 	return err
 }
+
+func (r articleRepo) Changes(user content.User, since content.ChangeID, limit int) ([]content.ArticleChange, error) {
+	start := time.Now()
+
+	changes, err := r.Article.Changes(user, since, limit)
+
+	r.log.Infof("repo.Article.Changes took %s", time.Now().Sub(start))
+
+	return changes, err
+}
+
+func (r articleRepo) ChangeRange() (content.ChangeID, content.ChangeID, error) {
+	start := time.Now()
+
+	first, last, err := r.Article.ChangeRange()
+
+	r.log.Infof("repo.Article.ChangeRange took %s", time.Now().Sub(start))
+
+	return first, last, err
+}
+
+func (r articleRepo) RemoveStaleChanges() error {
+	start := time.Now()
+
+	err := r.Article.RemoveStaleChanges()
+
+	r.log.Infof("repo.Article.RemoveStaleChanges took %s", time.Now().Sub(start))
+
+	return err
+}
+
+func (r articleRepo) Purgeable(feed content.Feed, retention content.Retention) ([]content.ArticleID, error) {
+	start := time.Now()
+
+	ids, err := r.Article.Purgeable(feed, retention)
+
+	r.log.Infof("repo.Article.Purgeable took %s", time.Now().Sub(start))
+
+	return ids, err
+}
+
+func (r articleRepo) Delete(ids []content.ArticleID) error {
+	start := time.Now()
+
+	err := r.Article.Delete(ids)
+
+	r.log.Infof("repo.Article.Delete took %s", time.Now().Sub(start))
+
+	return err
+}
@@ -104,6 +104,16 @@ func (r feedRepo) Delete(feed content.Feed) error {
 	return err
 }
 
+func (r feedRepo) Merge(feed, into content.Feed) error {
+	start := time.Now()
+
+	err := r.Feed.Merge(feed, into)
+
+	r.log.Infof("repo.Feed.Merge took %s", time.Now().Sub(start))
+
+	return err
+}
+
 func (r feedRepo) Users(feed content.Feed) ([]content.User, error) {
 	start := time.Now()
 
@@ -0,0 +1,55 @@
+package logging
+
+import (
+	"time"
+
+	"github.com/urandom/readeef/content"
+	"github.com/urandom/readeef/content/repo"
+	"github.com/urandom/readeef/log"
+)
+
+type feedHealthRepo struct {
+	repo.FeedHealth
+
+	log log.Log
+}
+
+func (r feedHealthRepo) Get(feed content.Feed) (content.FeedHealth, error) {
+	start := time.Now()
+
+	health, err := r.FeedHealth.Get(feed)
+
+	r.log.Infof("repo.FeedHealth.Get took %s", time.Now().Sub(start))
+
+	return health, err
+}
+
+func (r feedHealthRepo) History(feed content.Feed, limit int) ([]content.FeedFetch, error) {
+	start := time.Now()
+
+	fetches, err := r.FeedHealth.History(feed, limit)
+
+	r.log.Infof("repo.FeedHealth.History took %s", time.Now().Sub(start))
+
+	return fetches, err
+}
+
+func (r feedHealthRepo) Failing() ([]content.FeedHealth, error) {
+	start := time.Now()
+
+	health, err := r.FeedHealth.Failing()
+
+	r.log.Infof("repo.FeedHealth.Failing took %s", time.Now().Sub(start))
+
+	return health, err
+}
+
+func (r feedHealthRepo) Record(fetch content.FeedFetch) (content.FeedHealth, error) {
+	start := time.Now()
+
+	health, err := r.FeedHealth.Record(fetch)
+
+	r.log.Infof("repo.FeedHealth.Record took %s", time.Now().Sub(start))
+
+	return health, err
+}
@@ -0,0 +1,35 @@
+package logging
+
+import (
+	"time"
+
+	"github.com/urandom/readeef/content"
+	"github.com/urandom/readeef/content/repo"
+	"github.com/urandom/readeef/log"
+)
+
+type feedImageRepo struct {
+	repo.FeedImage
+
+	log log.Log
+}
+
+func (r feedImageRepo) Get(feed content.Feed) (content.FeedImage, error) {
+	start := time.Now()
+
+	image, err := r.FeedImage.Get(feed)
+
+	r.log.Infof("repo.FeedImage.Get took %s", time.Now().Sub(start))
+
+	return image, err
+}
+
+func (r feedImageRepo) Update(image content.FeedImage) error {
+	start := time.Now()
+
+	err := r.FeedImage.Update(image)
+
+	r.log.Infof("repo.FeedImage.Update took %s", time.Now().Sub(start))
+
+	return err
+}
@@ -0,0 +1,35 @@
+package logging
+
+import (
+	"time"
+
+	"github.com/urandom/readeef/content"
+	"github.com/urandom/readeef/content/repo"
+	"github.com/urandom/readeef/log"
+)
+
+type feedSettingsRepo struct {
+	repo.FeedSettings
+
+	log log.Log
+}
+
+func (r feedSettingsRepo) Get(feed content.Feed) (content.FeedSettings, error) {
+	start := time.Now()
+
+	settings, err := r.FeedSettings.Get(feed)
+
+	r.log.Infof("repo.FeedSettings.Get took %s", time.Now().Sub(start))
+
+	return settings, err
+}
+
+func (r feedSettingsRepo) Update(settings content.FeedSettings) error {
+	start := time.Now()
+
+	err := r.FeedSettings.Update(settings)
+
+	r.log.Infof("repo.FeedSettings.Update took %s", time.Now().Sub(start))
+
+	return err
+}
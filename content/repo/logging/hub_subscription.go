@@ -0,0 +1,65 @@
+package logging
+
+import (
+	"time"
+
+	"github.com/urandom/readeef/content"
+	"github.com/urandom/readeef/content/repo"
+	"github.com/urandom/readeef/log"
+)
+
+type hubSubscriptionRepo struct {
+	repo.HubSubscription
+
+	log log.Log
+}
+
+func (r hubSubscriptionRepo) All() ([]content.HubSubscription, error) {
+	start := time.Now()
+
+	subscriptions, err := r.HubSubscription.All()
+
+	r.log.Infof("repo.HubSubscription.All took %s", time.Now().Sub(start))
+
+	return subscriptions, err
+}
+
+func (r hubSubscriptionRepo) ForTopic(topic string) ([]content.HubSubscription, error) {
+	start := time.Now()
+
+	subscriptions, err := r.HubSubscription.ForTopic(topic)
+
+	r.log.Infof("repo.HubSubscription.ForTopic took %s", time.Now().Sub(start))
+
+	return subscriptions, err
+}
+
+func (r hubSubscriptionRepo) Update(subscription content.HubSubscription) error {
+	start := time.Now()
+
+	err := r.HubSubscription.Update(subscription)
+
+	r.log.Infof("repo.HubSubscription.Update took %s", time.Now().Sub(start))
+
+	return err
+}
+
+func (r hubSubscriptionRepo) Delete(subscription content.HubSubscription) error {
+	start := time.Now()
+
+	err := r.HubSubscription.Delete(subscription)
+
+	r.log.Infof("repo.HubSubscription.Delete took %s", time.Now().Sub(start))
+
+	return err
+}
+
+func (r hubSubscriptionRepo) DeleteExpired(before time.Time) error {
+	start := time.Now()
+
+	err := r.HubSubscription.DeleteExpired(before)
+
+	r.log.Infof("repo.HubSubscription.DeleteExpired took %s", time.Now().Sub(start))
+
+	return err
+}
@@ -0,0 +1,75 @@
+package logging
+
+import (
+	"time"
+
+	"github.com/urandom/readeef/content"
+	"github.com/urandom/readeef/content/repo"
+	"github.com/urandom/readeef/log"
+)
+
+type labelRepo struct {
+	repo.Label
+
+	log log.Log
+}
+
+func (r labelRepo) Get(id content.LabelID, user content.User) (content.Label, error) {
+	start := time.Now()
+
+	label, err := r.Label.Get(id, user)
+
+	r.log.Infof("repo.Label.Get took %s", time.Now().Sub(start))
+
+	return label, err
+}
+
+func (r labelRepo) ForUser(user content.User) ([]content.Label, error) {
+	start := time.Now()
+
+	labels, err := r.Label.ForUser(user)
+
+	r.log.Infof("repo.Label.ForUser took %s", time.Now().Sub(start))
+
+	return labels, err
+}
+
+func (r labelRepo) Update(label *content.Label) error {
+	start := time.Now()
+
+	err := r.Label.Update(label)
+
+	r.log.Infof("repo.Label.Update took %s", time.Now().Sub(start))
+
+	return err
+}
+
+func (r labelRepo) Delete(label content.Label) error {
+	start := time.Now()
+
+	err := r.Label.Delete(label)
+
+	r.log.Infof("repo.Label.Delete took %s", time.Now().Sub(start))
+
+	return err
+}
+
+func (r labelRepo) Assign(label content.Label, ids []content.ArticleID) error {
+	start := time.Now()
+
+	err := r.Label.Assign(label, ids)
+
+	r.log.Infof("repo.Label.Assign took %s", time.Now().Sub(start))
+
+	return err
+}
+
+func (r labelRepo) Unassign(label content.Label, ids []content.ArticleID) error {
+	start := time.Now()
+
+	err := r.Label.Unassign(label, ids)
+
+	r.log.Infof("repo.Label.Unassign took %s", time.Now().Sub(start))
+
+	return err
+}
@@ -0,0 +1,75 @@
+package logging
+
+import (
+	"time"
+
+	"github.com/urandom/readeef/content"
+	"github.com/urandom/readeef/content/repo"
+	"github.com/urandom/readeef/log"
+)
+
+type publicationRepo struct {
+	repo.Publication
+
+	log log.Log
+}
+
+func (r publicationRepo) Get(id content.PublicationID, user content.User) (content.Publication, error) {
+	start := time.Now()
+
+	publication, err := r.Publication.Get(id, user)
+
+	r.log.Infof("repo.Publication.Get took %s", time.Now().Sub(start))
+
+	return publication, err
+}
+
+func (r publicationRepo) ForUser(user content.User, limit, offset int) ([]content.Publication, error) {
+	start := time.Now()
+
+	publications, err := r.Publication.ForUser(user, limit, offset)
+
+	r.log.Infof("repo.Publication.ForUser took %s", time.Now().Sub(start))
+
+	return publications, err
+}
+
+func (r publicationRepo) Update(publication *content.Publication) error {
+	start := time.Now()
+
+	err := r.Publication.Update(publication)
+
+	r.log.Infof("repo.Publication.Update took %s", time.Now().Sub(start))
+
+	return err
+}
+
+func (r publicationRepo) Delete(publication content.Publication) error {
+	start := time.Now()
+
+	err := r.Publication.Delete(publication)
+
+	r.log.Infof("repo.Publication.Delete took %s", time.Now().Sub(start))
+
+	return err
+}
+
+func (r publicationRepo) Publish(user content.User, ids []content.ArticleID) error {
+	start := time.Now()
+
+	err := r.Publication.Publish(user, ids)
+
+	r.log.Infof("repo.Publication.Publish took %s", time.Now().Sub(start))
+
+	return err
+}
+
+func (r publicationRepo) Unpublish(user content.User, ids []content.ArticleID) error {
+	start := time.Now()
+
+	err := r.Publication.Unpublish(user, ids)
+
+	r.log.Infof("repo.Publication.Unpublish took %s", time.Now().Sub(start))
+
+	return err
+}
@@ -0,0 +1,55 @@
+package logging
+
+import (
+	"time"
+
+	"github.com/urandom/readeef/content"
+	"github.com/urandom/readeef/content/repo"
+	"github.com/urandom/readeef/log"
+)
+
+type ruleRepo struct {
+	repo.Rule
+
+	log log.Log
+}
+
+func (r ruleRepo) Get(id content.RuleID, user content.User) (content.Rule, error) {
+	start := time.Now()
+
+	rule, err := r.Rule.Get(id, user)
+
+	r.log.Infof("repo.Rule.Get took %s", time.Now().Sub(start))
+
+	return rule, err
+}
+
+func (r ruleRepo) ForUser(user content.User) ([]content.Rule, error) {
+	start := time.Now()
+
+	rules, err := r.Rule.ForUser(user)
+
+	r.log.Infof("repo.Rule.ForUser took %s", time.Now().Sub(start))
+
+	return rules, err
+}
+
+func (r ruleRepo) Update(rule *content.Rule) error {
+	start := time.Now()
+
+	err := r.Rule.Update(rule)
+
+	r.log.Infof("repo.Rule.Update took %s", time.Now().Sub(start))
+
+	return err
+}
+
+func (r ruleRepo) Delete(rule content.Rule) error {
+	start := time.Now()
+
+	err := r.Rule.Delete(rule)
+
+	r.log.Infof("repo.Rule.Delete took %s", time.Now().Sub(start))
+
+	return err
+}
@@ -0,0 +1,55 @@
+package logging
+
+import (
+	"time"
+
+	"github.com/urandom/readeef/content"
+	"github.com/urandom/readeef/content/repo"
+	"github.com/urandom/readeef/log"
+)
+
+type savedSearchRepo struct {
+	repo.SavedSearch
+
+	log log.Log
+}
+
+func (r savedSearchRepo) Get(id content.SavedSearchID, user content.User) (content.SavedSearch, error) {
+	start := time.Now()
+
+	search, err := r.SavedSearch.Get(id, user)
+
+	r.log.Infof("repo.SavedSearch.Get took %s", time.Now().Sub(start))
+
+	return search, err
+}
+
+func (r savedSearchRepo) ForUser(user content.User) ([]content.SavedSearch, error) {
+	start := time.Now()
+
+	searches, err := r.SavedSearch.ForUser(user)
+
+	r.log.Infof("repo.SavedSearch.ForUser took %s", time.Now().Sub(start))
+
+	return searches, err
+}
+
+func (r savedSearchRepo) Update(search *content.SavedSearch) error {
+	start := time.Now()
+
+	err := r.SavedSearch.Update(search)
+
+	r.log.Infof("repo.SavedSearch.Update took %s", time.Now().Sub(start))
+
+	return err
+}
+
+func (r savedSearchRepo) Delete(search content.SavedSearch) error {
+	start := time.Now()
+
+	err := r.SavedSearch.Delete(search)
+
+	r.log.Infof("repo.SavedSearch.Delete took %s", time.Now().Sub(start))
+
+	return err
+}
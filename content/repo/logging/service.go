@@ -11,6 +11,14 @@ type Service struct {
 	article      articleRepo
 	extract      extractRepo
 	feed         feedRepo
+	feedHealth   feedHealthRepo
+	feedImage    feedImageRepo
+	feedSettings feedSettingsRepo
+	hub          hubSubscriptionRepo
+	label        labelRepo
+	publication  publicationRepo
+	rule         ruleRepo
+	savedSearch  savedSearchRepo
 	scores       scoresRepo
 	subscription subscriptionRepo
 	tag          tagRepo
@@ -24,6 +32,14 @@ func NewService(s repo.Service, log log.Log) Service {
 		articleRepo{s.ArticleRepo(), log},
 		extractRepo{s.ExtractRepo(), log},
 		feedRepo{s.FeedRepo(), log},
+		feedHealthRepo{s.FeedHealthRepo(), log},
+		feedImageRepo{s.FeedImageRepo(), log},
+		feedSettingsRepo{s.FeedSettingsRepo(), log},
+		hubSubscriptionRepo{s.HubSubscriptionRepo(), log},
+		labelRepo{s.LabelRepo(), log},
+		publicationRepo{s.PublicationRepo(), log},
+		ruleRepo{s.RuleRepo(), log},
+		savedSearchRepo{s.SavedSearchRepo(), log},
 		scoresRepo{s.ScoresRepo(), log},
 		subscriptionRepo{s.SubscriptionRepo(), log},
 		tagRepo{s.TagRepo(), log},
@@ -44,6 +60,38 @@ func (s Service) FeedRepo() repo.Feed {
 	return s.feed
 }
 
+func (s Service) FeedHealthRepo() repo.FeedHealth {
+	return s.feedHealth
+}
+
+func (s Service) FeedImageRepo() repo.FeedImage {
+	return s.feedImage
+}
+
+func (s Service) FeedSettingsRepo() repo.FeedSettings {
+	return s.feedSettings
+}
+
+func (s Service) HubSubscriptionRepo() repo.HubSubscription {
+	return s.hub
+}
+
+func (s Service) LabelRepo() repo.Label {
+	return s.label
+}
+
+func (s Service) PublicationRepo() repo.Publication {
+	return s.publication
+}
+
+func (s Service) RuleRepo() repo.Rule {
+	return s.rule
+}
+
+func (s Service) SavedSearchRepo() repo.SavedSearch {
+	return s.savedSearch
+}
+
 func (s Service) ScoresRepo() repo.Scores {
 	return s.scores
 }
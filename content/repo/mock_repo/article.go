@@ -68,6 +68,18 @@ func (mr *MockArticleMockRecorder) Count(arg0 interface{}, arg1 ...interface{})
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Count", reflect.TypeOf((*MockArticle)(nil).Count), varargs...)
 }
 
+// Delete mocks base method
+func (m *MockArticle) Delete(arg0 []content.ArticleID) error {
+	ret := m.ctrl.Call(m, "Delete", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete
+func (mr *MockArticleMockRecorder) Delete(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockArticle)(nil).Delete), arg0)
+}
+
 // Favor mocks base method
 func (m *MockArticle) Favor(arg0 bool, arg1 content.User, arg2 ...content.QueryOpt) error {
 	varargs := []interface{}{arg0, arg1}
@@ -103,6 +115,23 @@ func (mr *MockArticleMockRecorder) ForUser(arg0 interface{}, arg1 ...interface{}
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ForUser", reflect.TypeOf((*MockArticle)(nil).ForUser), varargs...)
 }
 
+// Hide mocks base method
+func (m *MockArticle) Hide(arg0 bool, arg1 content.User, arg2 ...content.QueryOpt) error {
+	varargs := []interface{}{arg0, arg1}
+	for _, a := range arg2 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Hide", varargs...)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Hide indicates an expected call of Hide
+func (mr *MockArticleMockRecorder) Hide(arg0, arg1 interface{}, arg2 ...interface{}) *gomock.Call {
+	varargs := append([]interface{}{arg0, arg1}, arg2...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Hide", reflect.TypeOf((*MockArticle)(nil).Hide), varargs...)
+}
+
 // IDs mocks base method
 func (m *MockArticle) IDs(arg0 content.User, arg1 ...content.QueryOpt) ([]content.ArticleID, error) {
 	varargs := []interface{}{arg0}
@@ -121,6 +150,58 @@ func (mr *MockArticleMockRecorder) IDs(arg0 interface{}, arg1 ...interface{}) *g
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IDs", reflect.TypeOf((*MockArticle)(nil).IDs), varargs...)
 }
 
+// Changes mocks base method
+func (m *MockArticle) Changes(arg0 content.User, arg1 content.ChangeID, arg2 int) ([]content.ArticleChange, error) {
+	ret := m.ctrl.Call(m, "Changes", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]content.ArticleChange)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Changes indicates an expected call of Changes
+func (mr *MockArticleMockRecorder) Changes(arg0, arg1, arg2 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Changes", reflect.TypeOf((*MockArticle)(nil).Changes), arg0, arg1, arg2)
+}
+
+// ChangeRange mocks base method
+func (m *MockArticle) ChangeRange() (content.ChangeID, content.ChangeID, error) {
+	ret := m.ctrl.Call(m, "ChangeRange")
+	ret0, _ := ret[0].(content.ChangeID)
+	ret1, _ := ret[1].(content.ChangeID)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// ChangeRange indicates an expected call of ChangeRange
+func (mr *MockArticleMockRecorder) ChangeRange() *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChangeRange", reflect.TypeOf((*MockArticle)(nil).ChangeRange))
+}
+
+// RemoveStaleChanges mocks base method
+func (m *MockArticle) RemoveStaleChanges() error {
+	ret := m.ctrl.Call(m, "RemoveStaleChanges")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemoveStaleChanges indicates an expected call of RemoveStaleChanges
+func (mr *MockArticleMockRecorder) RemoveStaleChanges() *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveStaleChanges", reflect.TypeOf((*MockArticle)(nil).RemoveStaleChanges))
+}
+
+// Purgeable mocks base method
+func (m *MockArticle) Purgeable(arg0 content.Feed, arg1 content.Retention) ([]content.ArticleID, error) {
+	ret := m.ctrl.Call(m, "Purgeable", arg0, arg1)
+	ret0, _ := ret[0].([]content.ArticleID)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Purgeable indicates an expected call of Purgeable
+func (mr *MockArticleMockRecorder) Purgeable(arg0, arg1 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Purgeable", reflect.TypeOf((*MockArticle)(nil).Purgeable), arg0, arg1)
+}
+
 // Read mocks base method
 func (m *MockArticle) Read(arg0 bool, arg1 content.User, arg2 ...content.QueryOpt) error {
 	varargs := []interface{}{arg0, arg1}
@@ -149,3 +230,15 @@ func (m *MockArticle) RemoveStaleUnreadRecords() error {
 func (mr *MockArticleMockRecorder) RemoveStaleUnreadRecords() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveStaleUnreadRecords", reflect.TypeOf((*MockArticle)(nil).RemoveStaleUnreadRecords))
 }
+
+// SetNote mocks base method
+func (m *MockArticle) SetNote(arg0 content.User, arg1 content.ArticleID, arg2 string) error {
+	ret := m.ctrl.Call(m, "SetNote", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetNote indicates an expected call of SetNote
+func (mr *MockArticleMockRecorder) SetNote(arg0, arg1, arg2 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetNote", reflect.TypeOf((*MockArticle)(nil).SetNote), arg0, arg1, arg2)
+}
@@ -57,6 +57,30 @@ func (mr *MockServiceMockRecorder) ExtractRepo() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExtractRepo", reflect.TypeOf((*MockService)(nil).ExtractRepo))
 }
 
+// FeedHealthRepo mocks base method
+func (m *MockService) FeedHealthRepo() repo.FeedHealth {
+	ret := m.ctrl.Call(m, "FeedHealthRepo")
+	ret0, _ := ret[0].(repo.FeedHealth)
+	return ret0
+}
+
+// FeedHealthRepo indicates an expected call of FeedHealthRepo
+func (mr *MockServiceMockRecorder) FeedHealthRepo() *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FeedHealthRepo", reflect.TypeOf((*MockService)(nil).FeedHealthRepo))
+}
+
+// FeedImageRepo mocks base method
+func (m *MockService) FeedImageRepo() repo.FeedImage {
+	ret := m.ctrl.Call(m, "FeedImageRepo")
+	ret0, _ := ret[0].(repo.FeedImage)
+	return ret0
+}
+
+// FeedImageRepo indicates an expected call of FeedImageRepo
+func (mr *MockServiceMockRecorder) FeedImageRepo() *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FeedImageRepo", reflect.TypeOf((*MockService)(nil).FeedImageRepo))
+}
+
 // FeedRepo mocks base method
 func (m *MockService) FeedRepo() repo.Feed {
 	ret := m.ctrl.Call(m, "FeedRepo")
@@ -69,6 +93,78 @@ func (mr *MockServiceMockRecorder) FeedRepo() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FeedRepo", reflect.TypeOf((*MockService)(nil).FeedRepo))
 }
 
+// FeedSettingsRepo mocks base method
+func (m *MockService) FeedSettingsRepo() repo.FeedSettings {
+	ret := m.ctrl.Call(m, "FeedSettingsRepo")
+	ret0, _ := ret[0].(repo.FeedSettings)
+	return ret0
+}
+
+// FeedSettingsRepo indicates an expected call of FeedSettingsRepo
+func (mr *MockServiceMockRecorder) FeedSettingsRepo() *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FeedSettingsRepo", reflect.TypeOf((*MockService)(nil).FeedSettingsRepo))
+}
+
+// HubSubscriptionRepo mocks base method
+func (m *MockService) HubSubscriptionRepo() repo.HubSubscription {
+	ret := m.ctrl.Call(m, "HubSubscriptionRepo")
+	ret0, _ := ret[0].(repo.HubSubscription)
+	return ret0
+}
+
+// HubSubscriptionRepo indicates an expected call of HubSubscriptionRepo
+func (mr *MockServiceMockRecorder) HubSubscriptionRepo() *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HubSubscriptionRepo", reflect.TypeOf((*MockService)(nil).HubSubscriptionRepo))
+}
+
+// LabelRepo mocks base method
+func (m *MockService) LabelRepo() repo.Label {
+	ret := m.ctrl.Call(m, "LabelRepo")
+	ret0, _ := ret[0].(repo.Label)
+	return ret0
+}
+
+// LabelRepo indicates an expected call of LabelRepo
+func (mr *MockServiceMockRecorder) LabelRepo() *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LabelRepo", reflect.TypeOf((*MockService)(nil).LabelRepo))
+}
+
+// PublicationRepo mocks base method
+func (m *MockService) PublicationRepo() repo.Publication {
+	ret := m.ctrl.Call(m, "PublicationRepo")
+	ret0, _ := ret[0].(repo.Publication)
+	return ret0
+}
+
+// PublicationRepo indicates an expected call of PublicationRepo
+func (mr *MockServiceMockRecorder) PublicationRepo() *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PublicationRepo", reflect.TypeOf((*MockService)(nil).PublicationRepo))
+}
+
+// RuleRepo mocks base method
+func (m *MockService) RuleRepo() repo.Rule {
+	ret := m.ctrl.Call(m, "RuleRepo")
+	ret0, _ := ret[0].(repo.Rule)
+	return ret0
+}
+
+// RuleRepo indicates an expected call of RuleRepo
+func (mr *MockServiceMockRecorder) RuleRepo() *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RuleRepo", reflect.TypeOf((*MockService)(nil).RuleRepo))
+}
+
+// SavedSearchRepo mocks base method
+func (m *MockService) SavedSearchRepo() repo.SavedSearch {
+	ret := m.ctrl.Call(m, "SavedSearchRepo")
+	ret0, _ := ret[0].(repo.SavedSearch)
+	return ret0
+}
+
+// SavedSearchRepo indicates an expected call of SavedSearchRepo
+func (mr *MockServiceMockRecorder) SavedSearchRepo() *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SavedSearchRepo", reflect.TypeOf((*MockService)(nil).SavedSearchRepo))
+}
+
 // ScoresRepo mocks base method
 func (m *MockService) ScoresRepo() repo.Scores {
 	ret := m.ctrl.Call(m, "ScoresRepo")
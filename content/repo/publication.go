@@ -0,0 +1,15 @@
+package repo
+
+import "github.com/urandom/readeef/content"
+
+// Publication allows fetching and manipulating content.Publication objects
+type Publication interface {
+	Get(content.PublicationID, content.User) (content.Publication, error)
+	ForUser(content.User, int, int) ([]content.Publication, error)
+
+	Update(*content.Publication) error
+	Delete(content.Publication) error
+
+	Publish(content.User, []content.ArticleID) error
+	Unpublish(content.User, []content.ArticleID) error
+}
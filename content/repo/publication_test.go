@@ -0,0 +1,144 @@
+package repo_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/urandom/readeef/content"
+)
+
+func Test_publicationRepo(t *testing.T) {
+	skipTest(t)
+	setupArticle()
+
+	r := service.PublicationRepo()
+	articleRepo := service.ArticleRepo()
+	user := content.User{Login: user1}
+
+	if err := r.Update(&content.Publication{UserLogin: user1, Title: "Shared"}); err == nil {
+		t.Errorf("publicationRepo.Update() invalid publication error = nil")
+	}
+
+	shared := content.Publication{
+		UserLogin: user1, Title: "Shared", Link: "https://example.com/shared",
+		Note: "Worth a read",
+	}
+	if err := r.Update(&shared); err != nil {
+		t.Fatalf("publicationRepo.Update() error = %v", err)
+	}
+
+	if shared.ID == 0 || shared.Date.IsZero() {
+		t.Fatalf("publicationRepo.Update() did not set id or date: %#v", shared)
+	}
+
+	got, err := r.Get(shared.ID, user)
+	if err != nil {
+		t.Fatalf("publicationRepo.Get() error = %v", err)
+	}
+
+	if got.ID != shared.ID || got.Title != shared.Title || got.Link != shared.Link || got.Note != shared.Note || got.ArticleID != 0 {
+		t.Errorf("publicationRepo.Get() = %#v, want %#v", got, shared)
+	}
+
+	if _, err := r.Get(shared.ID, content.User{Login: user2}); errors.Cause(err) != content.ErrNoContent {
+		t.Errorf("publicationRepo.Get() other user error = %v, wanted no content", err)
+	}
+
+	shared.Note = "A must read"
+	if err := r.Update(&shared); err != nil {
+		t.Fatalf("publicationRepo.Update() error = %v", err)
+	}
+
+	published := []content.ArticleID{articles[0].ID, articles[4].ID}
+	if err := r.Publish(user, published); err != nil {
+		t.Fatalf("publicationRepo.Publish() error = %v", err)
+	}
+
+	// Publishing twice is a no-op, while the other user can't publish
+	// articles from feeds they aren't subscribed to.
+	if err := r.Publish(user, published[:1]); err != nil {
+		t.Fatalf("publicationRepo.Publish() error = %v", err)
+	}
+
+	if err := r.Publish(content.User{Login: user2}, published); err != nil {
+		t.Fatalf("publicationRepo.Publish() other user error = %v", err)
+	}
+
+	publications, err := r.ForUser(user, 10, 0)
+	if err != nil {
+		t.Fatalf("publicationRepo.ForUser() error = %v", err)
+	}
+
+	if len(publications) != 3 {
+		t.Fatalf("publicationRepo.ForUser() = %v, want 3 publications", publications)
+	}
+
+	byArticle := map[content.ArticleID]content.Publication{}
+	for _, p := range publications {
+		byArticle[p.ArticleID] = p
+	}
+
+	if p := byArticle[0]; p.ID != shared.ID || p.Note != shared.Note {
+		t.Errorf("publicationRepo.ForUser() shared = %#v, want %#v", p, shared)
+	}
+
+	if p := byArticle[articles[0].ID]; p.Title != articles[0].Title || p.Link != articles[0].Link {
+		t.Errorf("publicationRepo.ForUser() article publication = %#v, want a copy of %s", p, articles[0])
+	}
+
+	if publications, err := r.ForUser(user, 2, 2); err != nil || len(publications) != 1 {
+		t.Errorf("publicationRepo.ForUser() paged = %v, %v, want 1 publication", publications, err)
+	}
+
+	if publications, err := r.ForUser(content.User{Login: user2}, 10, 0); err != nil || len(publications) != 1 {
+		t.Errorf("publicationRepo.ForUser() other user = %v, %v, want 1 publication", publications, err)
+	}
+
+	opts := []content.QueryOpt{content.PublishedOnly, content.Sorting(content.SortByID, content.AscendingOrder)}
+	got2, err := articleRepo.ForUser(user, opts...)
+	if err != nil {
+		t.Fatalf("articleRepo.ForUser() error = %v", err)
+	}
+
+	ids := []content.ArticleID{}
+	for _, a := range got2 {
+		ids = append(ids, a.ID)
+
+		if !a.Published {
+			t.Errorf("articleRepo.ForUser() article %s is not published", a)
+		}
+	}
+
+	if !reflect.DeepEqual(ids, published) {
+		t.Errorf("articleRepo.ForUser() = %v, want %v", ids, published)
+	}
+
+	if count, err := articleRepo.Count(user, content.PublishedOnly); err != nil || count != 2 {
+		t.Errorf("articleRepo.Count() = %d, %v, want 2", count, err)
+	}
+
+	if err := r.Unpublish(user, published[:1]); err != nil {
+		t.Fatalf("publicationRepo.Unpublish() error = %v", err)
+	}
+
+	if ids, err := articleRepo.IDs(user, content.PublishedOnly); err != nil || !reflect.DeepEqual(ids, published[1:]) {
+		t.Errorf("publicationRepo.Unpublish() ids = %v, %v, want %v", ids, err, published[1:])
+	}
+
+	if err := r.Delete(shared); err != nil {
+		t.Fatalf("publicationRepo.Delete() error = %v", err)
+	}
+
+	if _, err := r.Get(shared.ID, user); errors.Cause(err) != content.ErrNoContent {
+		t.Errorf("publicationRepo.Get() after delete error = %v, wanted no content", err)
+	}
+
+	if err := r.Unpublish(user, published[1:]); err != nil {
+		t.Fatalf("publicationRepo.Unpublish() error = %v", err)
+	}
+
+	if err := r.Unpublish(content.User{Login: user2}, published); err != nil {
+		t.Fatalf("publicationRepo.Unpublish() other user error = %v", err)
+	}
+}
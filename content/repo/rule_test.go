@@ -0,0 +1,79 @@
+package repo_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/urandom/readeef/content"
+)
+
+func Test_ruleRepo(t *testing.T) {
+	skipTest(t)
+	setupUser()
+
+	r := service.RuleRepo()
+	user := content.User{Login: user1}
+
+	rule := content.Rule{
+		UserLogin: user1,
+		Name:      "Go releases",
+		Field:     content.RuleFieldTitle,
+		Match:     content.RuleMatchRegex,
+		Value:     `(?i)go 1\.\d+ is released`,
+		Actions:   content.RuleActions{content.RuleActionRead, content.RuleActionFavorite},
+	}
+
+	if err := r.Update(&content.Rule{UserLogin: user1}); err == nil {
+		t.Errorf("ruleRepo.Update() invalid rule error = nil")
+	}
+
+	if err := r.Update(&rule); err != nil {
+		t.Fatalf("ruleRepo.Update() error = %v", err)
+	}
+
+	if rule.ID == 0 {
+		t.Fatalf("ruleRepo.Update() did not set rule id")
+	}
+
+	got, err := r.Get(rule.ID, user)
+	if err != nil {
+		t.Fatalf("ruleRepo.Get() error = %v", err)
+	}
+
+	if !reflect.DeepEqual(got, rule) {
+		t.Errorf("ruleRepo.Get() = %v, want %v", got, rule)
+	}
+
+	if _, err := r.Get(rule.ID, content.User{Login: user2}); errors.Cause(err) != content.ErrNoContent {
+		t.Errorf("ruleRepo.Get() other user error = %v, wanted no content", err)
+	}
+
+	rule.Actions = content.RuleActions{content.RuleActionHide}
+	if err := r.Update(&rule); err != nil {
+		t.Fatalf("ruleRepo.Update() error = %v", err)
+	}
+
+	other := rule
+	other.UserLogin = user2
+	if err := r.Update(&other); errors.Cause(err) != content.ErrNoContent {
+		t.Errorf("ruleRepo.Update() other user error = %v, wanted no content", err)
+	}
+
+	rules, err := r.ForUser(user)
+	if err != nil {
+		t.Fatalf("ruleRepo.ForUser() error = %v", err)
+	}
+
+	if want := []content.Rule{rule}; !reflect.DeepEqual(rules, want) {
+		t.Errorf("ruleRepo.ForUser() = %v, want %v", rules, want)
+	}
+
+	if err := r.Delete(rule); err != nil {
+		t.Fatalf("ruleRepo.Delete() error = %v", err)
+	}
+
+	if _, err := r.Get(rule.ID, user); errors.Cause(err) != content.ErrNoContent {
+		t.Errorf("ruleRepo.Get() after delete error = %v, wanted no content", err)
+	}
+}
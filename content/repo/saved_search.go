@@ -0,0 +1,12 @@
+package repo
+
+import "github.com/urandom/readeef/content"
+
+// SavedSearch allows fetching and manipulating content.SavedSearch objects
+type SavedSearch interface {
+	Get(content.SavedSearchID, content.User) (content.SavedSearch, error)
+	ForUser(content.User) ([]content.SavedSearch, error)
+
+	Update(*content.SavedSearch) error
+	Delete(content.SavedSearch) error
+}
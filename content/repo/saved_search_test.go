@@ -0,0 +1,80 @@
+package repo_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/urandom/readeef/content"
+)
+
+func Test_savedSearchRepo(t *testing.T) {
+	skipTest(t)
+	setupUser()
+
+	r := service.SavedSearchRepo()
+	user := content.User{Login: user1}
+
+	if err := r.Update(&content.SavedSearch{UserLogin: user1, Name: "Go"}); err == nil {
+		t.Errorf("savedSearchRepo.Update() invalid saved search error = nil")
+	}
+
+	search := content.SavedSearch{UserLogin: user1, Name: "Go", Query: "golang", FeedID: 2}
+	if err := r.Update(&search); err != nil {
+		t.Fatalf("savedSearchRepo.Update() error = %v", err)
+	}
+
+	if search.ID == 0 {
+		t.Fatalf("savedSearchRepo.Update() did not set saved search id")
+	}
+
+	if err := r.Update(&content.SavedSearch{UserLogin: user1, Name: "Go", Query: "go"}); err == nil {
+		t.Errorf("savedSearchRepo.Update() duplicate name error = nil")
+	}
+
+	got, err := r.Get(search.ID, user)
+	if err != nil {
+		t.Fatalf("savedSearchRepo.Get() error = %v", err)
+	}
+
+	if !reflect.DeepEqual(got, search) {
+		t.Errorf("savedSearchRepo.Get() = %v, want %v", got, search)
+	}
+
+	if _, err := r.Get(search.ID, content.User{Login: user2}); errors.Cause(err) != content.ErrNoContent {
+		t.Errorf("savedSearchRepo.Get() other user error = %v, wanted no content", err)
+	}
+
+	search.Query, search.FeedID, search.TagID = "golang OR gopher", 0, 3
+	if err := r.Update(&search); err != nil {
+		t.Fatalf("savedSearchRepo.Update() error = %v", err)
+	}
+
+	other := content.SavedSearch{UserLogin: user1, Name: "About rust", Query: "rust"}
+	if err := r.Update(&other); err != nil {
+		t.Fatalf("savedSearchRepo.Update() error = %v", err)
+	}
+
+	searches, err := r.ForUser(user)
+	if err != nil {
+		t.Fatalf("savedSearchRepo.ForUser() error = %v", err)
+	}
+
+	if want := []content.SavedSearch{other, search}; !reflect.DeepEqual(searches, want) {
+		t.Errorf("savedSearchRepo.ForUser() = %v, want %v", searches, want)
+	}
+
+	if searches, err := r.ForUser(content.User{Login: user2}); err != nil || len(searches) != 0 {
+		t.Errorf("savedSearchRepo.ForUser() other user = %v, %v", searches, err)
+	}
+
+	for _, s := range []content.SavedSearch{search, other} {
+		if err := r.Delete(s); err != nil {
+			t.Fatalf("savedSearchRepo.Delete() error = %v", err)
+		}
+	}
+
+	if _, err := r.Get(search.ID, user); errors.Cause(err) != content.ErrNoContent {
+		t.Errorf("savedSearchRepo.Get() after delete error = %v, wanted no content", err)
+	}
+}
@@ -5,9 +5,17 @@ type Service interface {
 	UserRepo() User
 	TagRepo() Tag
 	FeedRepo() Feed
+	FeedImageRepo() FeedImage
+	FeedSettingsRepo() FeedSettings
+	FeedHealthRepo() FeedHealth
 	SubscriptionRepo() Subscription
 	ArticleRepo() Article
 	ExtractRepo() Extract
 	ThumbnailRepo() Thumbnail
 	ScoresRepo() Scores
+	RuleRepo() Rule
+	LabelRepo() Label
+	PublicationRepo() Publication
+	SavedSearchRepo() SavedSearch
+	HubSubscriptionRepo() HubSubscription
 }
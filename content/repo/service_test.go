@@ -36,4 +36,32 @@ func TestService(t *testing.T) {
 	if service.ScoresRepo() == nil {
 		t.Fatal("service.ScoresRepo() = nil")
 	}
+
+	if service.RuleRepo() == nil {
+		t.Fatal("service.RuleRepo() = nil")
+	}
+
+	if service.LabelRepo() == nil {
+		t.Fatal("service.LabelRepo() = nil")
+	}
+
+	if service.PublicationRepo() == nil {
+		t.Fatal("service.PublicationRepo() = nil")
+	}
+
+	if service.SavedSearchRepo() == nil {
+		t.Fatal("service.SavedSearchRepo() = nil")
+	}
+
+	if service.FeedSettingsRepo() == nil {
+		t.Fatal("service.FeedSettingsRepo() = nil")
+	}
+
+	if service.FeedHealthRepo() == nil {
+		t.Fatal("service.FeedHealthRepo() = nil")
+	}
+
+	if service.HubSubscriptionRepo() == nil {
+		t.Fatal("service.HubSubscriptionRepo() = nil")
+	}
 }
@@ -20,10 +20,18 @@ var (
 	getArticlesTemplate         *template.Template
 	getArticleIDsTemplate       *template.Template
 	articleCountTemplate        *template.Template
+	articleMediaTemplate        *template.Template
+	articleCategoriesTemplate   *template.Template
+	articleLabelsTemplate       *template.Template
+	articleNotesTemplate        *template.Template
+	purgeableArticlesTemplate   *template.Template
+	deleteArticlesTemplate      *template.Template
 	readStateInsertTemplate     *template.Template
 	readStateDeleteTemplate     *template.Template
 	favoriteStateInsertTemplate *template.Template
 	favoriteStateDeleteTemplate *template.Template
+	hiddenStateInsertTemplate   *template.Template
+	hiddenStateDeleteTemplate   *template.Template
 )
 
 type articleRepo struct {
@@ -41,22 +49,87 @@ type getArticlesData struct {
 }
 
 const (
-	userLogin         = "user_login"
-	beforeID          = "before_id"
-	afterID           = "after_id"
-	beforeDate        = "before_date"
-	afterDate         = "after_date"
-	beforeScore       = "before_score"
-	afterScore        = "after_score"
-	idPrefix          = "id"
-	feedIDPRefix      = "feed_id"
-	limit             = "limit"
-	offset            = "offset"
-	filterURLPrefix   = "filterURL"
-	filterTitlePrefix = "filterTitle"
-	filterIDPrefix    = "filterID"
+	userLogin             = "user_login"
+	beforeID              = "before_id"
+	afterID               = "after_id"
+	beforeDate            = "before_date"
+	afterDate             = "after_date"
+	beforeScore           = "before_score"
+	afterScore            = "after_score"
+	idPrefix              = "id"
+	feedIDPRefix          = "feed_id"
+	limit                 = "limit"
+	offset                = "offset"
+	filterURLPrefix       = "filterURL"
+	filterTitlePrefix     = "filterTitle"
+	filterIDPrefix        = "filterID"
+	mediaArticlePrefix    = "media_article_id"
+	categoryArticlePrefix = "category_article_id"
+	labelArticlePrefix    = "label_article_id"
+	labelIDPrefix         = "label_id"
+	noteArticlePrefix     = "note_article_id"
+	changedSince          = "changed_since"
+	authorPrefix          = "author"
+	categoryPrefix        = "category"
+	deleteIDPrefix        = "delete_id"
+
+	// The maximum number of ids bound in a single delete statement.
+	deleteBatchSize = 500
 )
 
+// Tables holding article data that has to be removed along with the
+// articles themselves.
+var articleRelatedTables = []string{
+	"users_articles_unread", "users_articles_favorite", "users_articles_hidden", "articles_scores",
+	"articles_thumbnails", "articles_extracts", "articles_media",
+	"articles_categories", "articles_labels", "users_articles_notes",
+}
+
+type articleCategoryArgs struct {
+	Category    string         `db:"category"`
+	FeedID      content.FeedID `db:"feed_id"`
+	ArticleLink string         `db:"article_link"`
+}
+
+type articleCategory struct {
+	ArticleID content.ArticleID `db:"article_id"`
+	Category  string            `db:"category"`
+}
+
+type articleLabel struct {
+	ArticleID content.ArticleID `db:"article_id"`
+	LabelID   content.LabelID   `db:"label_id"`
+}
+
+type articleNote struct {
+	ArticleID content.ArticleID `db:"article_id"`
+	Note      string            `db:"note"`
+}
+
+type articleNoteArgs struct {
+	UserLogin content.Login     `db:"user_login"`
+	ArticleID content.ArticleID `db:"article_id"`
+	Note      string            `db:"note"`
+}
+
+type deleteArticlesData struct {
+	Table string
+	Where string
+}
+
+type purgeableArgs struct {
+	FeedID     content.FeedID `db:"feed_id"`
+	MinKeep    int            `db:"min_keep"`
+	MaxCount   int            `db:"max_count"`
+	BeforeDate time.Time      `db:"before_date"`
+}
+
+type articleMediaArgs struct {
+	content.Media
+	FeedID      content.FeedID `db:"feed_id"`
+	ArticleLink string         `db:"article_link"`
+}
+
 // ForUser returns all user articles restricted by the QueryOptions
 func (r articleRepo) ForUser(user content.User, opts ...content.QueryOpt) ([]content.Article, error) {
 	articles := []content.Article{}
@@ -117,10 +190,22 @@ func (r articleRepo) All(opts ...content.QueryOpt) ([]content.Article, error) {
 	if err = r.db.WithNamedStmt(sql, nil, func(stmt *sqlx.NamedStmt) error {
 		return stmt.Select(&articles, args)
 	}); err != nil {
-		err = errors.Wrap(err, "getting articles")
+		return []content.Article{}, errors.Wrap(err, "getting articles")
 	}
 
-	return articles, err
+	if o.IncludeCategories {
+		if err = getArticleCategories(articles, r.db, r.log); err != nil {
+			return []content.Article{}, errors.WithMessage(err, "getting articles categories")
+		}
+	}
+
+	if o.IncludeMedia {
+		if err = getArticleMedia(articles, r.db, r.log); err != nil {
+			return []content.Article{}, errors.WithMessage(err, "getting articles media")
+		}
+	}
+
+	return articles, nil
 }
 
 func (r articleRepo) Count(user content.User, opts ...content.QueryOpt) (int64, error) {
@@ -159,6 +244,9 @@ func (r articleRepo) Count(user content.User, opts ...content.QueryOpt) (int64,
 	if o.ReadOnly || o.UnreadOnly {
 		renderData.Join += s.Article.StateUnreadJoin
 	}
+	if o.HiddenOnly || !o.IncludeHidden {
+		renderData.Join += s.Article.StateHiddenJoin
+	}
 
 	o.IncludeScores = false
 	o.UnreadFirst = false
@@ -223,6 +311,9 @@ func (r articleRepo) IDs(user content.User, opts ...content.QueryOpt) ([]content
 	if o.ReadOnly || o.UnreadOnly || o.UnreadFirst {
 		renderData.Join += s.Article.StateUnreadJoin
 	}
+	if o.HiddenOnly || !o.IncludeHidden {
+		renderData.Join += s.Article.StateHiddenJoin
+	}
 
 	if o.UnreadFirst {
 		renderData.Columns += ", " + s.Article.StateReadColumn
@@ -272,27 +363,226 @@ func (r articleRepo) Favor(
 	return articleStateSet(favoriteState, state, user, r.db, r.log, opts)
 }
 
+func (r articleRepo) Hide(
+	state bool,
+	user content.User,
+	opts ...content.QueryOpt,
+) error {
+	return articleStateSet(hiddenState, state, user, r.db, r.log, opts)
+}
+
+// SetNote sets the user's note on the article. An empty note removes it.
+func (r articleRepo) SetNote(user content.User, id content.ArticleID, note string) error {
+	if err := user.Validate(); err != nil {
+		return errors.WithMessage(err, "validating user")
+	}
+
+	r.log.Infof("Setting note for article %d and user %s", id, user)
+
+	args := articleNoteArgs{UserLogin: user.Login, ArticleID: id, Note: note}
+	s := r.db.SQL()
+
+	return r.db.WithTx(func(tx *sqlx.Tx) error {
+		if note == "" {
+			return r.db.WithNamedStmt(s.Article.DeleteNote, tx, func(stmt *sqlx.NamedStmt) error {
+				if _, err := stmt.Exec(args); err != nil {
+					return errors.Wrap(err, "executing article note delete stmt")
+				}
+
+				return nil
+			})
+		}
+
+		return r.db.WithNamedStmt(s.Article.UpdateNote, tx, func(stmt *sqlx.NamedStmt) error {
+			res, err := stmt.Exec(args)
+			if err != nil {
+				return errors.Wrap(err, "executing article note update stmt")
+			}
+
+			if num, err := res.RowsAffected(); err == nil && num > 0 {
+				return nil
+			}
+
+			return r.db.WithNamedStmt(s.Article.CreateNote, tx, func(stmt *sqlx.NamedStmt) error {
+				res, err := stmt.Exec(args)
+				if err != nil {
+					return errors.Wrap(err, "executing article note create stmt")
+				}
+
+				if num, err := res.RowsAffected(); err == nil && num == 0 {
+					return errors.Wrapf(content.ErrNoContent, "article %d not found for user %s", id, user)
+				}
+
+				return nil
+			})
+		})
+	})
+}
+
 type staleArgs struct {
-	InsertDate time.Time `db:"insert_date"`
+	InsertDate time.Time          `db:"insert_date"`
+	ChangeType content.ChangeType `db:"change_type"`
 }
 
+// RemoveStaleUnreadRecords marks articles that have been unread for more
+// than a month as read, logging the change.
 func (r articleRepo) RemoveStaleUnreadRecords() error {
 	r.log.Infof("Removing stale unread article records")
 
-	if err := r.db.WithNamedStmt(
-		r.db.SQL().Article.DeleteStaleUnreadRecords,
-		nil,
-		func(stmt *sqlx.NamedStmt) error {
-			_, err := stmt.Exec(staleArgs{time.Now().AddDate(0, -1, 0)})
+	args := staleArgs{time.Now().AddDate(0, -1, 0), content.ChangeRead}
+	s := r.db.SQL()
+
+	if err := r.db.WithTx(func(tx *sqlx.Tx) error {
+		if err := r.db.WithNamedStmt(s.Change.CreateForStale, tx, func(stmt *sqlx.NamedStmt) error {
+			_, err := stmt.Exec(args)
 			return err
-		},
-	); err != nil {
+		}); err != nil {
+			return errors.Wrap(err, "creating stale unread article changes")
+		}
+
+		return r.db.WithNamedStmt(s.Article.DeleteStaleUnreadRecords, tx, func(stmt *sqlx.NamedStmt) error {
+			_, err := stmt.Exec(args)
+			return err
+		})
+	}); err != nil {
 		return errors.Wrap(err, "removing stale unread article records")
 	}
 
 	return nil
 }
 
+// Purgeable returns the ids of the feed articles that fall outside of the
+// retention limits. Favorite, published, labelled and annotated articles, and
+// the newest retention.MinKeep articles, are never returned.
+func (r articleRepo) Purgeable(feed content.Feed, retention content.Retention) ([]content.ArticleID, error) {
+	if err := feed.Validate(); err != nil {
+		return []content.ArticleID{}, errors.WithMessage(err, "validating feed")
+	}
+
+	if !retention.Enabled() {
+		return []content.ArticleID{}, nil
+	}
+
+	r.log.Infof("Getting purgeable articles for feed %s with %s", feed, retention)
+
+	s := r.db.SQL()
+
+	var err error
+	if purgeableArticlesTemplate == nil {
+		purgeableArticlesTemplate, err = template.New("purgeable-articles-sql").
+			Parse(s.Article.PurgeableTemplate)
+
+		if err != nil {
+			return []content.ArticleID{}, errors.Wrap(err, "generating purgeable-articles template")
+		}
+	}
+
+	args := purgeableArgs{FeedID: feed.ID, MinKeep: retention.MinKeep, MaxCount: retention.MaxCount}
+	if args.MinKeep < 0 {
+		args.MinKeep = 0
+	}
+
+	var where []string
+	if retention.MaxAge > 0 {
+		where = append(where, s.Article.PurgeMaxAgeWhere)
+		args.BeforeDate = time.Now().Add(-retention.MaxAge)
+	}
+
+	if retention.MaxCount > 0 {
+		where = append(where, s.Article.PurgeMaxCountWhere)
+	}
+
+	buf := pool.Buffer.Get()
+	defer pool.Buffer.Put(buf)
+
+	if err := purgeableArticlesTemplate.Execute(buf, getArticlesData{Where: strings.Join(where, " OR ")}); err != nil {
+		return []content.ArticleID{}, errors.Wrap(err, "executing purgeable-articles template")
+	}
+
+	r.log.Debugf("Purgeable articles SQL:\n%s\nArgs:%v\n", buf.String(), args)
+
+	var ids []content.ArticleID
+	if err = r.db.WithNamedStmt(buf.String(), nil, func(stmt *sqlx.NamedStmt) error {
+		return stmt.Select(&ids, args)
+	}); err != nil {
+		return []content.ArticleID{}, errors.Wrap(err, "getting purgeable articles")
+	}
+
+	return ids, nil
+}
+
+// Delete removes the given articles, along with their state, scores,
+// thumbnails, extracts and media.
+func (r articleRepo) Delete(ids []content.ArticleID) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	r.log.Infof("Deleting %d articles", len(ids))
+
+	var err error
+	if deleteArticlesTemplate == nil {
+		deleteArticlesTemplate, err = template.New("delete-articles-sql").
+			Parse(r.db.SQL().Article.DeleteTemplate)
+
+		if err != nil {
+			return errors.Wrap(err, "generating delete-articles template")
+		}
+	}
+
+	return r.db.WithTx(func(tx *sqlx.Tx) error {
+		for start := 0; start < len(ids); start += deleteBatchSize {
+			end := start + deleteBatchSize
+			if end > len(ids) {
+				end = len(ids)
+			}
+
+			if err := deleteArticles(ids[start:end], tx, r.db); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+func deleteArticles(ids []content.ArticleID, tx *sqlx.Tx, dbo *db.DB) error {
+	args := make(map[string]interface{}, len(ids))
+	for i := range ids {
+		args[fmt.Sprintf("%s%d", deleteIDPrefix, i)] = ids[i]
+	}
+
+	for _, table := range articleRelatedTables {
+		if err := deleteArticlesFrom(table, "article_id", args, tx, dbo); err != nil {
+			return err
+		}
+	}
+
+	return deleteArticlesFrom("articles", "id", args, tx, dbo)
+}
+
+func deleteArticlesFrom(table, column string, args map[string]interface{}, tx *sqlx.Tx, dbo *db.DB) error {
+	buf := pool.Buffer.Get()
+	defer pool.Buffer.Put(buf)
+
+	data := deleteArticlesData{
+		Table: table,
+		Where: dbo.WhereMultipleORs(column, deleteIDPrefix, len(args), true),
+	}
+	if err := deleteArticlesTemplate.Execute(buf, data); err != nil {
+		return errors.Wrap(err, "executing delete-articles template")
+	}
+
+	if err := dbo.WithNamedStmt(buf.String(), tx, func(stmt *sqlx.NamedStmt) error {
+		_, err := stmt.Exec(args)
+		return err
+	}); err != nil {
+		return errors.Wrapf(err, "deleting articles from %s", table)
+	}
+
+	return nil
+}
+
 func getArticles(login content.Login, dbo *db.DB, log log.Log, opts content.QueryOptions) ([]content.Article, error) {
 	var err error
 	if getArticlesTemplate == nil {
@@ -331,14 +621,247 @@ func getArticles(login content.Login, dbo *db.DB, log log.Log, opts content.Quer
 		return []content.Article{}, errors.Wrap(err, "getting articles")
 	}
 
+	if opts.IncludeCategories {
+		if err := getArticleCategories(articles, dbo, log); err != nil {
+			return []content.Article{}, errors.WithMessage(err, "getting articles categories")
+		}
+	}
+
+	if opts.IncludeMedia {
+		if err := getArticleMedia(articles, dbo, log); err != nil {
+			return []content.Article{}, errors.WithMessage(err, "getting articles media")
+		}
+	}
+
+	if opts.IncludeLabels && login != "" {
+		if err := getArticleLabels(login, articles, dbo, log); err != nil {
+			return []content.Article{}, errors.WithMessage(err, "getting articles labels")
+		}
+	}
+
+	if opts.IncludeNotes && login != "" {
+		if err := getArticleNotes(login, articles, dbo, log); err != nil {
+			return []content.Article{}, errors.WithMessage(err, "getting articles notes")
+		}
+	}
+
 	return articles, nil
 }
 
+func getArticleMedia(articles []content.Article, dbo *db.DB, log log.Log) error {
+	if len(articles) == 0 {
+		return nil
+	}
+
+	var err error
+	if articleMediaTemplate == nil {
+		articleMediaTemplate, err = template.New("article-media-sql").
+			Parse(dbo.SQL().Article.GetMediaTemplate)
+
+		if err != nil {
+			return errors.Wrap(err, "generating article-media template")
+		}
+	}
+
+	index := make(map[content.ArticleID]int, len(articles))
+	args := make(map[string]interface{}, len(articles))
+	for i := range articles {
+		index[articles[i].ID] = i
+		args[fmt.Sprintf("%s%d", mediaArticlePrefix, i)] = articles[i].ID
+	}
+
+	renderData := getArticlesData{
+		Where: "WHERE " + dbo.WhereMultipleORs("am.article_id", mediaArticlePrefix, len(articles), true),
+	}
+
+	buf := pool.Buffer.Get()
+	defer pool.Buffer.Put(buf)
+
+	if err := articleMediaTemplate.Execute(buf, renderData); err != nil {
+		return errors.Wrap(err, "executing article-media template")
+	}
+
+	log.Debugf("Article media SQL:\n%s\nArgs:%v\n", buf.String(), args)
+
+	var media []content.Media
+	if err := dbo.WithNamedStmt(buf.String(), nil, func(stmt *sqlx.NamedStmt) error {
+		return stmt.Select(&media, args)
+	}); err != nil {
+		return errors.Wrap(err, "getting article media")
+	}
+
+	for _, m := range media {
+		if i, ok := index[m.ArticleID]; ok {
+			articles[i].Media = append(articles[i].Media, m)
+		}
+	}
+
+	return nil
+}
+
+func getArticleCategories(articles []content.Article, dbo *db.DB, log log.Log) error {
+	if len(articles) == 0 {
+		return nil
+	}
+
+	var err error
+	if articleCategoriesTemplate == nil {
+		articleCategoriesTemplate, err = template.New("article-categories-sql").
+			Parse(dbo.SQL().Article.GetCategoriesTemplate)
+
+		if err != nil {
+			return errors.Wrap(err, "generating article-categories template")
+		}
+	}
+
+	index := make(map[content.ArticleID]int, len(articles))
+	args := make(map[string]interface{}, len(articles))
+	for i := range articles {
+		index[articles[i].ID] = i
+		args[fmt.Sprintf("%s%d", categoryArticlePrefix, i)] = articles[i].ID
+	}
+
+	renderData := getArticlesData{
+		Where: "WHERE " + dbo.WhereMultipleORs("acat.article_id", categoryArticlePrefix, len(articles), true),
+	}
+
+	buf := pool.Buffer.Get()
+	defer pool.Buffer.Put(buf)
+
+	if err := articleCategoriesTemplate.Execute(buf, renderData); err != nil {
+		return errors.Wrap(err, "executing article-categories template")
+	}
+
+	log.Debugf("Article categories SQL:\n%s\nArgs:%v\n", buf.String(), args)
+
+	var categories []articleCategory
+	if err := dbo.WithNamedStmt(buf.String(), nil, func(stmt *sqlx.NamedStmt) error {
+		return stmt.Select(&categories, args)
+	}); err != nil {
+		return errors.Wrap(err, "getting article categories")
+	}
+
+	for _, c := range categories {
+		if i, ok := index[c.ArticleID]; ok {
+			articles[i].Categories = append(articles[i].Categories, c.Category)
+		}
+	}
+
+	return nil
+}
+
+func getArticleLabels(login content.Login, articles []content.Article, dbo *db.DB, log log.Log) error {
+	if len(articles) == 0 {
+		return nil
+	}
+
+	var err error
+	if articleLabelsTemplate == nil {
+		articleLabelsTemplate, err = template.New("article-labels-sql").
+			Parse(dbo.SQL().Article.GetLabelsTemplate)
+
+		if err != nil {
+			return errors.Wrap(err, "generating article-labels template")
+		}
+	}
+
+	index := make(map[content.ArticleID]int, len(articles))
+	args := make(map[string]interface{}, len(articles)+1)
+	args[userLogin] = login
+	for i := range articles {
+		index[articles[i].ID] = i
+		args[fmt.Sprintf("%s%d", labelArticlePrefix, i)] = articles[i].ID
+	}
+
+	renderData := getArticlesData{
+		Where: "WHERE l.user_login = :user_login AND " +
+			dbo.WhereMultipleORs("al.article_id", labelArticlePrefix, len(articles), true),
+	}
+
+	buf := pool.Buffer.Get()
+	defer pool.Buffer.Put(buf)
+
+	if err := articleLabelsTemplate.Execute(buf, renderData); err != nil {
+		return errors.Wrap(err, "executing article-labels template")
+	}
+
+	log.Debugf("Article labels SQL:\n%s\nArgs:%v\n", buf.String(), args)
+
+	var labels []articleLabel
+	if err := dbo.WithNamedStmt(buf.String(), nil, func(stmt *sqlx.NamedStmt) error {
+		return stmt.Select(&labels, args)
+	}); err != nil {
+		return errors.Wrap(err, "getting article labels")
+	}
+
+	for _, l := range labels {
+		if i, ok := index[l.ArticleID]; ok {
+			articles[i].Labels = append(articles[i].Labels, l.LabelID)
+		}
+	}
+
+	return nil
+}
+
+func getArticleNotes(login content.Login, articles []content.Article, dbo *db.DB, log log.Log) error {
+	if len(articles) == 0 {
+		return nil
+	}
+
+	var err error
+	if articleNotesTemplate == nil {
+		articleNotesTemplate, err = template.New("article-notes-sql").
+			Parse(dbo.SQL().Article.GetNotesTemplate)
+
+		if err != nil {
+			return errors.Wrap(err, "generating article-notes template")
+		}
+	}
+
+	index := make(map[content.ArticleID]int, len(articles))
+	args := make(map[string]interface{}, len(articles)+1)
+	args[userLogin] = login
+	for i := range articles {
+		index[articles[i].ID] = i
+		args[fmt.Sprintf("%s%d", noteArticlePrefix, i)] = articles[i].ID
+	}
+
+	renderData := getArticlesData{
+		Where: "WHERE an.user_login = :user_login AND " +
+			dbo.WhereMultipleORs("an.article_id", noteArticlePrefix, len(articles), true),
+	}
+
+	buf := pool.Buffer.Get()
+	defer pool.Buffer.Put(buf)
+
+	if err := articleNotesTemplate.Execute(buf, renderData); err != nil {
+		return errors.Wrap(err, "executing article-notes template")
+	}
+
+	log.Debugf("Article notes SQL:\n%s\nArgs:%v\n", buf.String(), args)
+
+	var notes []articleNote
+	if err := dbo.WithNamedStmt(buf.String(), nil, func(stmt *sqlx.NamedStmt) error {
+		return stmt.Select(&notes, args)
+	}); err != nil {
+		return errors.Wrap(err, "getting article notes")
+	}
+
+	for _, n := range notes {
+		if i, ok := index[n.ArticleID]; ok {
+			articles[i].Note = n.Note
+		}
+	}
+
+	return nil
+}
+
 type stateType int
 
 const (
 	readState     stateType = iota
 	favoriteState stateType = iota
+	hiddenState   stateType = iota
 )
 
 func articleStateSet(
@@ -361,6 +884,7 @@ func articleStateSet(
 	o.Apply(opts)
 
 	var tmpl *template.Template
+	var change stateChangeData
 
 	switch stateType {
 	case readState:
@@ -368,19 +892,35 @@ func articleStateSet(
 
 		if state {
 			tmpl = readStateDeleteTemplate
+			change = stateChangeData{Type: content.ChangeRead, Table: "users_articles_unread", Existing: true}
 		} else {
 			tmpl = readStateInsertTemplate
+			change = stateChangeData{Type: content.ChangeUnread, Table: "users_articles_unread"}
 		}
 	case favoriteState:
 		log.Infof("Setting articles favorite state")
 
 		if state {
 			tmpl = favoriteStateInsertTemplate
+			change = stateChangeData{Type: content.ChangeFavorite, Table: "users_articles_favorite"}
 		} else {
 			tmpl = favoriteStateDeleteTemplate
+			change = stateChangeData{Type: content.ChangeUnfavorite, Table: "users_articles_favorite", Existing: true}
+		}
+	case hiddenState:
+		log.Infof("Setting articles hidden state")
+
+		if state {
+			tmpl = hiddenStateInsertTemplate
+		} else {
+			tmpl = hiddenStateDeleteTemplate
 		}
 	}
 
+	// State changes apply to hidden articles as well, unless they are
+	// explicitly restricted to them.
+	o.IncludeHidden = !o.HiddenOnly
+
 	s := db.SQL()
 	renderData := getArticlesData{}
 	var args map[string]interface{}
@@ -392,6 +932,9 @@ func articleStateSet(
 	if o.ReadOnly || o.UnreadOnly {
 		renderData.Join += s.Article.StateUnreadJoin
 	}
+	if o.HiddenOnly {
+		renderData.Join += s.Article.StateHiddenJoin
+	}
 
 	buf := pool.Buffer.Get()
 	defer pool.Buffer.Put(buf)
@@ -400,15 +943,40 @@ func articleStateSet(
 		return errors.Wrap(err, "executing article state template")
 	}
 
-	log.Debugf("Articles state SQL:\n%s\nArgs:%v\n", buf.String(), args)
-	if err := db.WithNamedStmt(buf.String(), nil, func(stmt *sqlx.NamedStmt) error {
-		_, err := stmt.Exec(args)
-		return err
-	}); err != nil {
-		return errors.Wrap(err, "executing article state statement")
+	var changeSQL string
+	if change.Type != "" {
+		change.Join, change.Where = renderData.Join, renderData.Where
+		args[changeType] = change.Type
+
+		var err error
+		if changeSQL, err = renderStateChange(change, s); err != nil {
+			return err
+		}
 	}
 
-	return nil
+	log.Debugf("Articles state SQL:\n%s\nArgs:%v\n", buf.String(), args)
+
+	// The changes are logged before the state is set, as they are deduced
+	// from the current state.
+	return db.WithTx(func(tx *sqlx.Tx) error {
+		if changeSQL != "" {
+			if err := db.WithNamedStmt(changeSQL, tx, func(stmt *sqlx.NamedStmt) error {
+				_, err := stmt.Exec(args)
+				return err
+			}); err != nil {
+				return errors.Wrap(err, "executing article state change statement")
+			}
+		}
+
+		if err := db.WithNamedStmt(buf.String(), tx, func(stmt *sqlx.NamedStmt) error {
+			_, err := stmt.Exec(args)
+			return err
+		}); err != nil {
+			return errors.Wrap(err, "executing article state statement")
+		}
+
+		return nil
+	})
 }
 
 func constructSQLQueryOptions(
@@ -450,6 +1018,12 @@ func constructSQLQueryOptions(
 		if opts.FavoriteOnly {
 			whereSlice = append(whereSlice, "af.article_id IS NOT NULL")
 		}
+
+		if opts.HiddenOnly {
+			whereSlice = append(whereSlice, "ah.article_id IS NOT NULL")
+		} else if !opts.IncludeHidden {
+			whereSlice = append(whereSlice, "ah.article_id IS NULL")
+		}
 	}
 
 	if clause := createRowValueClause(opts.BeforeID, opts.BeforeDate, opts.BeforeScore, "before", args); clause != "" {
@@ -480,6 +1054,36 @@ func constructSQLQueryOptions(
 		}
 	}
 
+	if len(opts.Authors) > 0 {
+		whereSlice = append(whereSlice, fmt.Sprintf(
+			"LOWER(a.author) IN (%s)", lowerStringArgs(authorPrefix, opts.Authors, args),
+		))
+	}
+
+	if len(opts.Categories) > 0 {
+		whereSlice = append(whereSlice, fmt.Sprintf(
+			s.Article.CategoriesWhere, lowerStringArgs(categoryPrefix, opts.Categories, args),
+		))
+	}
+
+	if hasUser && len(opts.LabelIDs) > 0 {
+		whereSlice = append(whereSlice, fmt.Sprintf(
+			s.Article.LabelsWhere, db.WhereMultipleORs("al.label_id", labelIDPrefix, len(opts.LabelIDs), true),
+		))
+		for i := range opts.LabelIDs {
+			args[fmt.Sprintf("%s%d", labelIDPrefix, i)] = opts.LabelIDs[i]
+		}
+	}
+
+	if hasUser && opts.PublishedOnly {
+		whereSlice = append(whereSlice, s.Article.PublishedWhere)
+	}
+
+	if hasUser && !opts.ChangedSince.IsZero() {
+		whereSlice = append(whereSlice, s.Change.ArticleWhere)
+		args[changedSince] = opts.ChangedSince.UTC()
+	}
+
 	for i, f := range opts.Filters {
 		if !f.Valid() {
 			continue
@@ -599,6 +1203,19 @@ func constructSQLQueryOptions(
 	return join, where, order, paging, args
 }
 
+// lowerStringArgs adds the lowercased values as named arguments, returning
+// the comma separated argument names.
+func lowerStringArgs(prefix string, values []string, args map[string]interface{}) string {
+	names := make([]string, len(values))
+	for i := range values {
+		name := fmt.Sprintf("%s%d", prefix, i)
+		args[name] = strings.ToLower(values[i])
+		names[i] = ":" + name
+	}
+
+	return strings.Join(names, ", ")
+}
+
 func updateArticle(a content.Article, tx *sqlx.Tx, db *db.DB, log log.Log) (content.Article, error) {
 	if err := a.Validate(); err != nil && a.ID != 0 {
 		return content.Article{}, errors.WithMessage(err, "validating article")
@@ -630,6 +1247,39 @@ func updateArticle(a content.Article, tx *sqlx.Tx, db *db.DB, log log.Log) (cont
 		return nil
 	})
 
+	if len(a.Categories) > 0 {
+		if err := db.WithNamedStmt(s.Article.CreateCategory, tx, func(stmt *sqlx.NamedStmt) error {
+			for _, c := range a.Categories {
+				if _, err := stmt.Exec(articleCategoryArgs{Category: c, FeedID: a.FeedID, ArticleLink: a.Link}); err != nil {
+					return errors.Wrapf(err, "executing article category create statement for %s", c)
+				}
+			}
+
+			return nil
+		}); err != nil {
+			return content.Article{}, errors.WithMessage(err, "creating article categories")
+		}
+	}
+
+	if len(a.Media) > 0 {
+		if err := db.WithNamedStmt(s.Article.CreateMedia, tx, func(stmt *sqlx.NamedStmt) error {
+			for _, m := range a.Media {
+				if err := m.Validate(); err != nil {
+					log.Infof("Skipping media %s of article %s: %v", m.Link, a, err)
+					continue
+				}
+
+				if _, err := stmt.Exec(articleMediaArgs{Media: m, FeedID: a.FeedID, ArticleLink: a.Link}); err != nil {
+					return errors.Wrapf(err, "executing article media create statement for %s", m.Link)
+				}
+			}
+
+			return nil
+		}); err != nil {
+			return content.Article{}, errors.WithMessage(err, "creating article media")
+		}
+	}
+
 	return a, nil
 }
 
@@ -671,6 +1321,24 @@ func instantiateStateTemplates(s db.SqlStmts) error {
 		}
 	}
 
+	if hiddenStateInsertTemplate == nil {
+		hiddenStateInsertTemplate, err = template.New("hidden-state-insert-sql").
+			Parse(s.Article.HiddenStateInsertTemplate)
+
+		if err != nil {
+			return errors.Wrap(err, "generating hidden-state-insert template")
+		}
+	}
+
+	if hiddenStateDeleteTemplate == nil {
+		hiddenStateDeleteTemplate, err = template.New("hidden-state-delete-sql").
+			Parse(s.Article.HiddenStateDeleteTemplate)
+
+		if err != nil {
+			return errors.Wrap(err, "generating hidden-state-delete template")
+		}
+	}
+
 	return nil
 }
 
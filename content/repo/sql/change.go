@@ -0,0 +1,137 @@
+package sql
+
+import (
+	"text/template"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+	"github.com/urandom/readeef/content"
+	"github.com/urandom/readeef/content/repo/sql/db"
+	"github.com/urandom/readeef/pool"
+)
+
+const (
+	changeType = "change_type"
+)
+
+var (
+	stateChangeTemplate *template.Template
+)
+
+type stateChangeData struct {
+	Join  string
+	Where string
+
+	Type content.ChangeType
+	// Table is the state table of the change.
+	Table string
+	// Existing is true when the change removes rows from the state table.
+	Existing bool
+}
+
+type changesArgs struct {
+	UserLogin content.Login    `db:"user_login"`
+	ID        content.ChangeID `db:"id"`
+	Limit     int              `db:"limit"`
+}
+
+type changeRange struct {
+	First content.ChangeID `db:"first_id"`
+	Last  content.ChangeID `db:"last_id"`
+}
+
+type articleChangeArgs struct {
+	ArticleID  content.ArticleID  `db:"article_id"`
+	FeedID     content.FeedID     `db:"feed_id"`
+	ChangeType content.ChangeType `db:"change_type"`
+}
+
+type staleChangeArgs struct {
+	ChangeDate time.Time `db:"change_date"`
+}
+
+// Changes returns up to limit changes of the user's articles, logged after
+// the one with the given id.
+func (r articleRepo) Changes(user content.User, since content.ChangeID, limit int) ([]content.ArticleChange, error) {
+	if err := user.Validate(); err != nil {
+		return []content.ArticleChange{}, errors.WithMessage(err, "validating user")
+	}
+
+	r.log.Infof("Getting article changes for %s since %d", user, since)
+
+	changes := []content.ArticleChange{}
+	if err := r.db.WithNamedStmt(r.db.SQL().Change.Get, nil, func(stmt *sqlx.NamedStmt) error {
+		return stmt.Select(&changes, changesArgs{UserLogin: user.Login, ID: since, Limit: limit})
+	}); err != nil {
+		return []content.ArticleChange{}, errors.Wrapf(err, "getting user %s article changes", user)
+	}
+
+	return changes, nil
+}
+
+// ChangeRange returns the ids of the oldest and newest changes in the log,
+// regardless of the user. Both are 0 if the log is empty.
+func (r articleRepo) ChangeRange() (content.ChangeID, content.ChangeID, error) {
+	r.log.Infof("Getting article change range")
+
+	var cr changeRange
+	if err := r.db.WithStmt(r.db.SQL().Change.Range, nil, func(stmt *sqlx.Stmt) error {
+		return stmt.Get(&cr)
+	}); err != nil {
+		return 0, 0, errors.Wrap(err, "getting article change range")
+	}
+
+	return cr.First, cr.Last, nil
+}
+
+func (r articleRepo) RemoveStaleChanges() error {
+	r.log.Infof("Removing stale article changes")
+
+	if err := r.db.WithNamedStmt(
+		r.db.SQL().Change.DeleteStale,
+		nil,
+		func(stmt *sqlx.NamedStmt) error {
+			_, err := stmt.Exec(staleChangeArgs{time.Now().AddDate(0, -1, 0)})
+			return err
+		},
+	); err != nil {
+		return errors.Wrap(err, "removing stale article changes")
+	}
+
+	return nil
+}
+
+// createArticleChanges logs the insertion of a new article for all users
+// subscribed to its feed.
+func createArticleChanges(a content.Article, tx *sqlx.Tx, db *db.DB) error {
+	if err := db.WithNamedStmt(db.SQL().Change.CreateForArticle, tx, func(stmt *sqlx.NamedStmt) error {
+		_, err := stmt.Exec(articleChangeArgs{ArticleID: a.ID, FeedID: a.FeedID, ChangeType: content.ChangeInsert})
+		return err
+	}); err != nil {
+		return errors.Wrapf(err, "creating article %s insert changes", a)
+	}
+
+	return nil
+}
+
+func renderStateChange(data stateChangeData, s db.SqlStmts) (string, error) {
+	if stateChangeTemplate == nil {
+		var err error
+		stateChangeTemplate, err = template.New("state-change-sql").
+			Parse(s.Change.StateChangeTemplate)
+
+		if err != nil {
+			return "", errors.Wrap(err, "generating state-change template")
+		}
+	}
+
+	buf := pool.Buffer.Get()
+	defer pool.Buffer.Put(buf)
+
+	if err := stateChangeTemplate.Execute(buf, data); err != nil {
+		return "", errors.Wrap(err, "executing state-change template")
+	}
+
+	return buf.String(), nil
+}
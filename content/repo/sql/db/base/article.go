@@ -11,37 +11,163 @@ func init() {
 	sqlStmts.Article.StateReadColumn = stateReadColumn
 	sqlStmts.Article.StateUnreadJoin = stateUnreadJoin
 	sqlStmts.Article.StateFavoriteJoin = stateFavoriteJoin
+	sqlStmts.Article.StateHiddenJoin = stateHiddenJoin
 	sqlStmts.Article.GetIDsTemplate = getArticleIDsTemplate
 	sqlStmts.Article.DeleteStaleUnreadRecords = deleteStaleUnreadRecords
 	sqlStmts.Article.GetScoreJoin = getArticlesScoreJoin
 	sqlStmts.Article.GetUntaggedJoin = getArticlesUntaggedJoin
+	sqlStmts.Article.GetMediaTemplate = getArticleMediaTemplate
+	sqlStmts.Article.CreateMedia = createArticleMedia
+	sqlStmts.Article.GetCategoriesTemplate = getArticleCategoriesTemplate
+	sqlStmts.Article.CreateCategory = createArticleCategory
+	sqlStmts.Article.CategoriesWhere = articleCategoriesWhere
+	sqlStmts.Article.GetLabelsTemplate = getArticleLabelsTemplate
+	sqlStmts.Article.LabelsWhere = articleLabelsWhere
+	sqlStmts.Article.PublishedWhere = articlePublishedWhere
+	sqlStmts.Article.GetNotesTemplate = getArticleNotesTemplate
+	sqlStmts.Article.CreateNote = createArticleNote
+	sqlStmts.Article.UpdateNote = updateArticleNote
+	sqlStmts.Article.DeleteNote = deleteArticleNote
+	sqlStmts.Article.PurgeableTemplate = purgeableArticlesTemplate
+	sqlStmts.Article.PurgeMaxAgeWhere = purgeArticlesMaxAgeWhere
+	sqlStmts.Article.PurgeMaxCountWhere = purgeArticlesMaxCountWhere
+	sqlStmts.Article.DeleteTemplate = deleteArticlesTemplate
 
 	sqlStmts.Article.ReadStateInsertTemplate = readStateInsertTemplate
 	sqlStmts.Article.ReadStateDeleteTemplate = readStateDeleteTemplate
 	sqlStmts.Article.FavoriteStateInsertTemplate = favoriteStateInsertTemplate
 	sqlStmts.Article.FavoriteStateDeleteTemplate = favoriteStateDeleteTemplate
+	sqlStmts.Article.HiddenStateInsertTemplate = hiddenStateInsertTemplate
+	sqlStmts.Article.HiddenStateDeleteTemplate = hiddenStateDeleteTemplate
 }
 
 const (
 	createFeedArticle = `
-INSERT INTO articles(feed_id, link, guid, title, description, date)
-	SELECT :feed_id, :link, :guid, :title, :description, :date EXCEPT
-	SELECT feed_id, link, CAST(:guid AS TEXT), CAST(:title as TEXT), CAST(:description AS TEXT), CAST(:date AS TIMESTAMP WITH TIME ZONE)
+INSERT INTO articles(feed_id, link, guid, title, description, date, author)
+	SELECT :feed_id, :link, :guid, :title, :description, :date, :author EXCEPT
+	SELECT feed_id, link, CAST(:guid AS TEXT), CAST(:title as TEXT), CAST(:description AS TEXT), CAST(:date AS TIMESTAMP WITH TIME ZONE), CAST(:author AS TEXT)
 	FROM articles WHERE feed_id = :feed_id AND link = :link
 `
 
 	updateFeedArticle = `
-UPDATE articles SET title = :title, description = :description, date = :date, guid = :guid, link = :link
+UPDATE articles SET title = :title, description = :description, date = :date, guid = :guid, link = :link, author = :author
 	WHERE feed_id = :feed_id AND (guid = :guid OR link = :link)
 `
-	articleCountTemplate = `
+	getArticleMediaTemplate = `
+SELECT am.id, am.article_id, am.link, am.type, am.medium, am.title,
+	am.length, am.duration, am.width, am.height
+FROM articles_media am
+{{ .Where }}
+ORDER BY am.article_id, am.id
+`
+	createArticleMedia = `
+INSERT INTO articles_media(article_id, link, type, medium, title, length, duration, width, height)
+SELECT a.id, CAST(:link AS TEXT), CAST(:type AS TEXT), CAST(:medium AS TEXT), CAST(:title AS TEXT),
+	CAST(:length AS BIGINT), CAST(:duration AS INTEGER), CAST(:width AS INTEGER), CAST(:height AS INTEGER)
+FROM articles a
+WHERE a.feed_id = :feed_id AND a.link = :article_link
+	AND NOT EXISTS (
+		SELECT 1 FROM articles_media am WHERE am.article_id = a.id AND am.link = :link
+	)
+`
+	getArticleCategoriesTemplate = `
+SELECT acat.article_id, acat.category
+FROM articles_categories acat
+{{ .Where }}
+ORDER BY acat.article_id, acat.category
+`
+	createArticleCategory = `
+INSERT INTO articles_categories(article_id, category)
+SELECT a.id, CAST(:category AS TEXT)
+FROM articles a
+WHERE a.feed_id = :feed_id AND a.link = :article_link
+	AND NOT EXISTS (
+		SELECT 1 FROM articles_categories acat WHERE acat.article_id = a.id AND acat.category = :category
+	)
+`
+	articleCategoriesWhere = `
+EXISTS (
+	SELECT 1 FROM articles_categories acat
+	WHERE acat.article_id = a.id AND LOWER(acat.category) IN (%s)
+)
+`
+	getArticleLabelsTemplate = `
+SELECT al.article_id, al.label_id
+FROM articles_labels al INNER JOIN labels l
+	ON al.label_id = l.id
+{{ .Where }}
+ORDER BY al.article_id, LOWER(l.name)
+`
+	articleLabelsWhere = `
+EXISTS (
+	SELECT 1 FROM articles_labels al INNER JOIN labels l
+		ON al.label_id = l.id
+	WHERE al.article_id = a.id AND l.user_login = :user_login AND %s
+)
+`
+	articlePublishedWhere = `
+EXISTS (
+	SELECT 1 FROM publications p
+	WHERE p.article_id = a.id AND p.user_login = :user_login
+)
+`
+	getArticleNotesTemplate = `
+SELECT an.article_id, an.note
+FROM users_articles_notes an
+{{ .Where }}
+`
+	createArticleNote = `
+INSERT INTO users_articles_notes(user_login, article_id, note)
+SELECT uf.user_login, a.id, CAST(:note AS TEXT)
+FROM users_feeds uf INNER JOIN articles a
+	ON uf.feed_id = a.feed_id
+WHERE uf.user_login = :user_login AND a.id = :article_id
+`
+	updateArticleNote = `
+UPDATE users_articles_notes SET note = :note WHERE user_login = :user_login AND article_id = :article_id
+`
+	deleteArticleNote = `
+DELETE FROM users_articles_notes WHERE user_login = :user_login AND article_id = :article_id
+`
+	purgeableArticlesTemplate = `
+SELECT a.id
+FROM articles a
+WHERE a.feed_id = :feed_id
+	AND NOT EXISTS (
+		SELECT 1 FROM users_articles_favorite uaf WHERE uaf.article_id = a.id
+	)
+	AND NOT EXISTS (
+		SELECT 1 FROM publications p WHERE p.article_id = a.id
+	)
+	AND NOT EXISTS (
+		SELECT 1 FROM articles_labels al WHERE al.article_id = a.id
+	)
+	AND NOT EXISTS (
+		SELECT 1 FROM users_articles_notes uan WHERE uan.article_id = a.id
+	)
+	AND a.id NOT IN (
+		SELECT ak.id FROM articles ak WHERE ak.feed_id = :feed_id
+		ORDER BY ak.id DESC LIMIT :min_keep
+	)
+	AND ({{ .Where }})
+ORDER BY a.id
+`
+	purgeArticlesMaxAgeWhere   = `a.date < :before_date`
+	purgeArticlesMaxCountWhere = `
+a.id NOT IN (
+	SELECT ac.id FROM articles ac WHERE ac.feed_id = :feed_id
+	ORDER BY ac.id DESC LIMIT :max_count
+)
+`
+	deleteArticlesTemplate = `DELETE FROM {{ .Table }} WHERE {{ .Where }}`
+	articleCountTemplate   = `
 SELECT count(*)
 FROM articles a
 {{ .Join }}
 {{ .Where }}
 `
 	getArticlesUserlessTemplate = `
-SELECT a.feed_id, a.id, a.title, a.description, a.link, a.date, a.guid,
+SELECT a.feed_id, a.id, a.title, a.description, a.link, a.date, a.guid, a.author,
 	COALESCE(at.thumbnail, '') as thumbnail,
 	COALESCE(at.link, '') as thumbnail_link
 	{{ .Columns }}
@@ -54,9 +180,11 @@ LEFT OUTER JOIN articles_thumbnails at
 {{ .Limit }}
 `
 	getArticlesTemplate = `
-SELECT a.feed_id, a.id, a.title, a.description, a.link, a.date, a.guid,
+SELECT a.feed_id, a.id, a.title, a.description, a.link, a.date, a.guid, a.author,
 	CASE WHEN au.article_id IS NULL THEN 1 ELSE 0 END AS read,
 	CASE WHEN af.article_id IS NULL THEN 0 ELSE 1 END AS favorite,
+	CASE WHEN ah.article_id IS NULL THEN 0 ELSE 1 END AS hidden,
+	CASE WHEN ap.id IS NULL THEN 0 ELSE 1 END AS published,
 	COALESCE(at.thumbnail, '') as thumbnail,
 	COALESCE(at.link, '') as thumbnail_link
 	{{ .Columns }}
@@ -68,6 +196,10 @@ LEFT OUTER JOIN users_articles_unread au
     ON a.id = au.article_id AND uf.user_login = au.user_login
 LEFT OUTER JOIN users_articles_favorite af
     ON a.id = af.article_id AND uf.user_login = af.user_login
+LEFT OUTER JOIN users_articles_hidden ah
+    ON a.id = ah.article_id AND uf.user_login = ah.user_login
+LEFT OUTER JOIN publications ap
+    ON a.id = ap.article_id AND uf.user_login = ap.user_login
 LEFT OUTER JOIN articles_thumbnails at
     ON a.id = at.article_id
 {{ .Where }}
@@ -83,6 +215,10 @@ INNER JOIN users_feeds uf
 	stateFavoriteJoin = `
 LEFT OUTER JOIN users_articles_favorite af
 	ON a.id = af.article_id AND af.user_login = uf.user_login
+`
+	stateHiddenJoin = `
+LEFT OUTER JOIN users_articles_hidden ah
+	ON a.id = ah.article_id AND ah.user_login = uf.user_login
 `
 	stateUnreadJoin = `
 LEFT OUTER JOIN users_articles_unread au
@@ -151,5 +287,27 @@ DELETE FROM users_articles_favorite WHERE user_login = :user_login AND article_i
 	{{ .Join }}
 	{{ .Where }}
 )
+`
+	hiddenStateInsertTemplate = `
+INSERT INTO users_articles_hidden (user_login, article_id)
+SELECT uf.user_login, a.id
+FROM users_feeds uf
+INNER JOIN articles a
+	ON uf.feed_id = a.feed_id AND uf.user_login = :user_login
+{{ .Join }}
+{{ .Where }}
+EXCEPT SELECT ah.user_login, ah.article_id
+FROM users_articles_hidden ah
+WHERE ah.user_login = :user_login
+`
+	hiddenStateDeleteTemplate = `
+DELETE FROM users_articles_hidden WHERE user_login = :user_login AND article_id IN (
+	SELECT a.id
+	FROM users_feeds uf INNER JOIN articles a
+		ON uf.feed_id = a.feed_id
+		AND uf.user_login = :user_login
+	{{ .Join }}
+	{{ .Where }}
+)
 `
 )
@@ -0,0 +1,61 @@
+package base
+
+func init() {
+	sqlStmts.Change.Get = getUserChanges
+	sqlStmts.Change.Range = getChangeRange
+	sqlStmts.Change.CreateForArticle = createArticleChanges
+	sqlStmts.Change.CreateForStale = createStaleUnreadChanges
+	sqlStmts.Change.StateChangeTemplate = stateChangeTemplate
+	sqlStmts.Change.ArticleWhere = changedArticleWhere
+	sqlStmts.Change.DeleteStale = deleteStaleChanges
+}
+
+const (
+	getUserChanges = `
+SELECT c.id, c.article_id, c.change_type, c.change_date
+FROM users_articles_changes c
+WHERE c.user_login = :user_login AND c.id > :id
+ORDER BY c.id
+LIMIT :limit
+`
+	getChangeRange = `
+SELECT COALESCE(MIN(c.id), 0) AS first_id, COALESCE(MAX(c.id), 0) AS last_id
+FROM users_articles_changes c
+`
+	createArticleChanges = `
+INSERT INTO users_articles_changes (user_login, article_id, change_type)
+SELECT uf.user_login, CAST(:article_id AS BIGINT), CAST(:change_type AS TEXT)
+FROM users_feeds uf
+WHERE uf.feed_id = :feed_id
+`
+	createStaleUnreadChanges = `
+INSERT INTO users_articles_changes (user_login, article_id, change_type)
+SELECT au.user_login, au.article_id, CAST(:change_type AS TEXT)
+FROM users_articles_unread au
+WHERE au.insert_date < :insert_date
+`
+	// The changed articles are the matching ones that are not yet in the
+	// state table when inserting into it, or are in it when deleting.
+	stateChangeTemplate = `
+INSERT INTO users_articles_changes (user_login, article_id, change_type)
+SELECT sc.user_login, sc.article_id, CAST(:change_type AS TEXT) FROM (
+	SELECT uf.user_login, a.id AS article_id
+	FROM users_feeds uf
+	INNER JOIN articles a
+		ON uf.feed_id = a.feed_id AND uf.user_login = :user_login
+	{{ .Join }}
+	{{ .Where }}
+	{{ if .Existing }}INTERSECT{{ else }}EXCEPT{{ end }}
+	SELECT s.user_login, s.article_id
+	FROM {{ .Table }} s
+	WHERE s.user_login = :user_login
+) sc
+`
+	changedArticleWhere = `
+EXISTS (
+	SELECT 1 FROM users_articles_changes c
+	WHERE c.article_id = a.id AND c.user_login = :user_login AND c.change_date > :changed_since
+)
+`
+	deleteStaleChanges = `DELETE FROM users_articles_changes WHERE change_date < :change_date`
+)
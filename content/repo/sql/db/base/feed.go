@@ -18,14 +18,24 @@ func init() {
 	sqlStmts.Feed.Detach = deleteUserFeed
 	sqlStmts.Feed.CreateUserTag = createUserFeedTag
 	sqlStmts.Feed.DeleteUserTags = deleteUserFeedTags
+	sqlStmts.Feed.MergeUsers = mergeUserFeeds
+	sqlStmts.Feed.MergeUserTags = mergeUserFeedTags
+	sqlStmts.Feed.MergeSavedSearches = mergeFeedSavedSearches
+	sqlStmts.Feed.MergeSettings = mergeFeedSettings
+	sqlStmts.Feed.MergeUnread = mergeUserArticlesUnread
+	sqlStmts.Feed.MergeFavorite = mergeUserArticlesFavorite
+	sqlStmts.Feed.MergeHidden = mergeUserArticlesHidden
+	sqlStmts.Feed.MergeNotes = mergeUserArticlesNotes
+	sqlStmts.Feed.MergeLabels = mergeArticlesLabels
+	sqlStmts.Feed.MergeArticles = mergeFeedArticles
 }
 
 const (
 	feedIDs    = `SELECT id FROM feeds`
 	createFeed = `
-INSERT INTO feeds(link, title, description, hub_link, site_link, update_error, subscribe_error)
-SELECT :link, :title, :description, :hub_link, :site_link, :update_error, :subscribe_error EXCEPT SELECT link, title, description, hub_link, site_link, update_error, subscribe_error FROM feeds WHERE link = :link`
-	updateFeed = `UPDATE feeds SET link = :link, title = :title, description = :description, hub_link = :hub_link, site_link = :site_link, update_error = :update_error, subscribe_error = :subscribe_error WHERE id = :id`
+INSERT INTO feeds(link, title, description, hub_link, site_link, update_error, subscribe_error, etag, last_modified, next_update, dead)
+SELECT :link, :title, :description, :hub_link, :site_link, :update_error, :subscribe_error, :etag, :last_modified, :next_update, :dead EXCEPT SELECT link, title, description, hub_link, site_link, update_error, subscribe_error, etag, last_modified, next_update, dead FROM feeds WHERE link = :link`
+	updateFeed = `UPDATE feeds SET link = :link, title = :title, description = :description, hub_link = :hub_link, site_link = :site_link, update_error = :update_error, subscribe_error = :subscribe_error, etag = :etag, last_modified = :last_modified, next_update = :next_update, dead = :dead WHERE id = :id`
 	deleteFeed = `DELETE FROM feeds WHERE id = :id`
 
 	getFeedUsers = `
@@ -50,24 +60,103 @@ INSERT INTO users_feeds_tags(user_login, feed_id, tag_id)
 DELETE FROM users_feeds_tags WHERE user_login = :user_login AND feed_id = :feed_id
 `
 
-	getFeed       = `SELECT link, title, description, hub_link, site_link, update_error, subscribe_error FROM feeds WHERE id = :id`
-	getFeedByLink = `SELECT id, title, description, hub_link, site_link, update_error, subscribe_error FROM feeds WHERE link = :link`
+	mergeUserFeeds = `
+INSERT INTO users_feeds(user_login, feed_id)
+SELECT uf.user_login, CAST(:into_id AS BIGINT) FROM users_feeds uf WHERE uf.feed_id = :id
+EXCEPT SELECT user_login, feed_id FROM users_feeds WHERE feed_id = :into_id
+`
+	mergeUserFeedTags = `
+INSERT INTO users_feeds_tags(user_login, feed_id, tag_id)
+SELECT uft.user_login, CAST(:into_id AS BIGINT), uft.tag_id FROM users_feeds_tags uft WHERE uft.feed_id = :id
+EXCEPT SELECT user_login, feed_id, tag_id FROM users_feeds_tags WHERE feed_id = :into_id
+`
+	mergeFeedSavedSearches = `UPDATE saved_searches SET feed_id = :into_id WHERE feed_id = :id`
+	mergeFeedSettings      = `
+UPDATE feed_settings SET feed_id = :into_id
+WHERE feed_id = :id AND NOT EXISTS (SELECT 1 FROM feed_settings fs WHERE fs.feed_id = :into_id)
+`
+	// The articles of the merged feed that are also in the other one, as
+	// matched by their guid or link, pass their user state to their
+	// counterparts.
+	mergedArticles = `
+SELECT a.id AS from_id, MIN(b.id) AS to_id
+FROM articles a, articles b
+WHERE a.feed_id = :id AND b.feed_id = :into_id AND (a.guid = b.guid OR a.link = b.link)
+GROUP BY a.id
+`
+	mergeUserArticlesUnread = `
+INSERT INTO users_articles_unread(user_login, article_id, insert_date)
+SELECT uau.user_login, m.to_id, MIN(uau.insert_date)
+FROM users_articles_unread uau, (` + mergedArticles + `) m
+WHERE uau.article_id = m.from_id AND NOT EXISTS (
+	SELECT 1 FROM users_articles_unread x WHERE x.user_login = uau.user_login AND x.article_id = m.to_id
+)
+GROUP BY uau.user_login, m.to_id
+`
+	mergeUserArticlesFavorite = `
+INSERT INTO users_articles_favorite(user_login, article_id)
+SELECT DISTINCT uaf.user_login, m.to_id
+FROM users_articles_favorite uaf, (` + mergedArticles + `) m
+WHERE uaf.article_id = m.from_id AND NOT EXISTS (
+	SELECT 1 FROM users_articles_favorite x WHERE x.user_login = uaf.user_login AND x.article_id = m.to_id
+)
+`
+	mergeUserArticlesHidden = `
+INSERT INTO users_articles_hidden(user_login, article_id)
+SELECT DISTINCT uah.user_login, m.to_id
+FROM users_articles_hidden uah, (` + mergedArticles + `) m
+WHERE uah.article_id = m.from_id AND NOT EXISTS (
+	SELECT 1 FROM users_articles_hidden x WHERE x.user_login = uah.user_login AND x.article_id = m.to_id
+)
+`
+	mergeUserArticlesNotes = `
+INSERT INTO users_articles_notes(user_login, article_id, note)
+SELECT uan.user_login, m.to_id, MIN(uan.note)
+FROM users_articles_notes uan, (` + mergedArticles + `) m
+WHERE uan.article_id = m.from_id AND NOT EXISTS (
+	SELECT 1 FROM users_articles_notes x WHERE x.user_login = uan.user_login AND x.article_id = m.to_id
+)
+GROUP BY uan.user_login, m.to_id
+`
+	mergeArticlesLabels = `
+INSERT INTO articles_labels(label_id, article_id)
+SELECT DISTINCT al.label_id, m.to_id
+FROM articles_labels al, (` + mergedArticles + `) m
+WHERE al.article_id = m.from_id AND NOT EXISTS (
+	SELECT 1 FROM articles_labels x WHERE x.label_id = al.label_id AND x.article_id = m.to_id
+)
+`
+	// The rest of the articles are moved, keeping their ids along with all
+	// of their state.
+	mergeFeedArticles = `
+UPDATE articles SET feed_id = :into_id
+WHERE feed_id = :id AND NOT EXISTS (
+	SELECT 1 FROM articles b
+	WHERE b.feed_id = :into_id AND (b.guid = articles.guid OR b.link = articles.link)
+)
+`
+
+	getFeed       = `SELECT link, title, description, hub_link, site_link, update_error, subscribe_error, etag, last_modified, next_update, dead FROM feeds WHERE id = :id`
+	getFeedByLink = `SELECT id, title, description, hub_link, site_link, update_error, subscribe_error, etag, last_modified, next_update, dead FROM feeds WHERE link = :link`
 	getUserFeed   = `
-SELECT f.id, f.link, f.title, f.description, f.link, f.hub_link, f.site_link, f.update_error, f.subscribe_error
+SELECT f.id, f.link, f.title, f.description, f.link, f.hub_link, f.site_link, f.update_error, f.subscribe_error,
+	f.etag, f.last_modified, f.next_update, f.dead
 FROM feeds f, users_feeds uf
 WHERE f.id = uf.feed_id
 	AND f.id = :id AND uf.user_login = :user_login
 `
-	getFeeds     = `SELECT id, link, title, description, hub_link, site_link, update_error, subscribe_error FROM feeds`
+	getFeeds     = `SELECT id, link, title, description, hub_link, site_link, update_error, subscribe_error, etag, last_modified, next_update, dead FROM feeds`
 	getUserFeeds = `
-SELECT f.id, f.link, f.title, f.description, f.link, f.hub_link, f.site_link, f.update_error, f.subscribe_error
+SELECT f.id, f.link, f.title, f.description, f.link, f.hub_link, f.site_link, f.update_error, f.subscribe_error,
+	f.etag, f.last_modified, f.next_update, f.dead
 FROM feeds f, users_feeds uf
 WHERE f.id = uf.feed_id
 	AND uf.user_login = :user_login
 ORDER BY LOWER(f.title)
 `
 	getUserTagFeeds = `
-SELECT f.id, f.link, f.title, f.description, f.link, f.hub_link, f.site_link, f.update_error, f.subscribe_error
+SELECT f.id, f.link, f.title, f.description, f.link, f.hub_link, f.site_link, f.update_error, f.subscribe_error,
+	f.etag, f.last_modified, f.next_update, f.dead
 FROM feeds f, users_feeds_tags uft, tags t
 WHERE f.id = uft.feed_id
 	AND t.id = uft.tag_id
@@ -75,7 +164,8 @@ WHERE f.id = uft.feed_id
 ORDER BY LOWER(f.title)
 `
 	getUnsubscribedFeeds = `
-SELECT f.id, f.link, f.title, f.description, f.hub_link, f.site_link, f.update_error, f.subscribe_error
+SELECT f.id, f.link, f.title, f.description, f.hub_link, f.site_link, f.update_error, f.subscribe_error,
+	f.etag, f.last_modified, f.next_update, f.dead
 	FROM feeds f LEFT OUTER JOIN hubbub_subscriptions hs
 	ON f.id = hs.feed_id AND hs.subscription_failure = '1'
 	ORDER BY f.title
@@ -0,0 +1,50 @@
+package base
+
+func init() {
+	sqlStmts.FeedHealth.Get = getFeedHealth
+	sqlStmts.FeedHealth.Failing = getFailingFeedHealth
+	sqlStmts.FeedHealth.History = getFeedFetches
+	sqlStmts.FeedHealth.Create = createFeedHealth
+	sqlStmts.FeedHealth.Update = updateFeedHealth
+	sqlStmts.FeedHealth.CreateFetch = createFeedFetch
+	sqlStmts.FeedHealth.PruneFetches = pruneFeedFetches
+}
+
+const (
+	getFeedHealth = `
+SELECT feed_id, last_fetch, last_success, failing_since, failures, last_status_code, last_error_class, last_error
+FROM feed_health WHERE feed_id = :feed_id
+`
+	getFailingFeedHealth = `
+SELECT feed_id, last_fetch, last_success, failing_since, failures, last_status_code, last_error_class, last_error
+FROM feed_health WHERE failures > 0
+ORDER BY failing_since, feed_id
+`
+	getFeedFetches = `
+SELECT feed_id, fetch_time, status_code, duration, bytes, new_articles, error_class, error
+FROM feed_fetches WHERE feed_id = :feed_id
+ORDER BY fetch_time DESC
+LIMIT :limit
+`
+	createFeedHealth = `
+INSERT INTO feed_health(feed_id, last_fetch, last_success, failing_since, failures, last_status_code, last_error_class, last_error)
+VALUES(:feed_id, :last_fetch, :last_success, :failing_since, :failures, :last_status_code, :last_error_class, :last_error)
+`
+	updateFeedHealth = `
+UPDATE feed_health SET last_fetch = :last_fetch, last_success = :last_success, failing_since = :failing_since,
+	failures = :failures, last_status_code = :last_status_code, last_error_class = :last_error_class,
+	last_error = :last_error
+WHERE feed_id = :feed_id
+`
+	createFeedFetch = `
+INSERT INTO feed_fetches(feed_id, fetch_time, status_code, duration, bytes, new_articles, error_class, error)
+VALUES(:feed_id, :fetch_time, :status_code, :duration, :bytes, :new_articles, :error_class, :error)
+`
+	pruneFeedFetches = `
+DELETE FROM feed_fetches
+WHERE feed_id = :feed_id AND fetch_time NOT IN (
+	SELECT ff.fetch_time FROM feed_fetches ff WHERE ff.feed_id = :feed_id
+	ORDER BY ff.fetch_time DESC LIMIT :limit
+)
+`
+)
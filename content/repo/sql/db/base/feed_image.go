@@ -0,0 +1,24 @@
+package base
+
+func init() {
+	sqlStmts.FeedImage.Get = getFeedImage
+	sqlStmts.FeedImage.Create = createFeedImage
+	sqlStmts.FeedImage.Update = updateFeedImage
+}
+
+const (
+	getFeedImage = `
+SELECT fi.feed_id, fi.title, fi.url, fi.width, fi.height, fi.icon, fi.icon_type, fi.fetch_date
+FROM feed_images fi
+WHERE fi.feed_id = :feed_id
+`
+	createFeedImage = `
+INSERT INTO feed_images(feed_id, title, url, width, height, icon, icon_type, fetch_date)
+VALUES(:feed_id, :title, :url, :width, :height, :icon, :icon_type, :fetch_date)
+`
+	updateFeedImage = `
+UPDATE feed_images SET title = :title, url = :url, width = :width, height = :height,
+	icon = :icon, icon_type = :icon_type, fetch_date = :fetch_date
+WHERE feed_id = :feed_id
+`
+)
@@ -0,0 +1,29 @@
+package base
+
+func init() {
+	sqlStmts.FeedSettings.Get = getFeedSettings
+	sqlStmts.FeedSettings.Create = createFeedSettings
+	sqlStmts.FeedSettings.Update = updateFeedSettings
+	sqlStmts.FeedSettings.DeleteOwned = deleteOwnedFeedSettings
+}
+
+const (
+	getFeedSettings = `
+SELECT feed_id, COALESCE(owner, '') AS owner, update_interval, paused, user_agent, headers, username, password, cookie,
+	extract_content
+FROM feed_settings WHERE feed_id = :feed_id
+`
+	createFeedSettings = `
+INSERT INTO feed_settings(feed_id, owner, update_interval, paused, user_agent, headers, username, password, cookie,
+	extract_content)
+VALUES(:feed_id, NULLIF(:owner, ''), :update_interval, :paused, :user_agent, :headers, :username, :password, :cookie,
+	:extract_content)
+`
+	updateFeedSettings = `
+UPDATE feed_settings SET owner = NULLIF(:owner, ''), update_interval = :update_interval, paused = :paused, user_agent = :user_agent,
+	headers = :headers, username = :username, password = :password, cookie = :cookie,
+	extract_content = :extract_content
+WHERE feed_id = :feed_id
+`
+	deleteOwnedFeedSettings = `DELETE FROM feed_settings WHERE feed_id = :id AND owner = :user_login`
+)
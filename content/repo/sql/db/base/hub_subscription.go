@@ -0,0 +1,30 @@
+package base
+
+func init() {
+	sqlStmts.HubSubscription.All = getHubSubscriptions
+	sqlStmts.HubSubscription.ForTopic = getTopicHubSubscriptions
+	sqlStmts.HubSubscription.Create = createHubSubscription
+	sqlStmts.HubSubscription.Update = updateHubSubscription
+	sqlStmts.HubSubscription.Delete = deleteHubSubscription
+	sqlStmts.HubSubscription.DeleteExpired = deleteExpiredHubSubscriptions
+}
+
+const (
+	getHubSubscriptions = `
+SELECT topic, callback, secret, lease_expiry FROM hub_subscriptions ORDER BY topic, callback
+`
+	getTopicHubSubscriptions = `
+SELECT topic, callback, secret, lease_expiry FROM hub_subscriptions WHERE topic = :topic ORDER BY callback
+`
+	createHubSubscription = `
+INSERT INTO hub_subscriptions(topic, callback, secret, lease_expiry)
+VALUES(:topic, :callback, :secret, :lease_expiry)
+`
+	updateHubSubscription = `
+UPDATE hub_subscriptions SET secret = :secret, lease_expiry = :lease_expiry
+WHERE topic = :topic AND callback = :callback
+`
+	deleteHubSubscription = `DELETE FROM hub_subscriptions WHERE topic = :topic AND callback = :callback`
+
+	deleteExpiredHubSubscriptions = `DELETE FROM hub_subscriptions WHERE lease_expiry <= :lease_expiry`
+)
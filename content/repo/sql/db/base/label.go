@@ -0,0 +1,54 @@
+package base
+
+func init() {
+	sqlStmts.Label.Get = getUserLabel
+	sqlStmts.Label.AllForUser = getUserLabels
+	sqlStmts.Label.Create = createUserLabel
+	sqlStmts.Label.Update = updateUserLabel
+	sqlStmts.Label.Delete = deleteUserLabel
+	sqlStmts.Label.Assign = assignUserLabel
+	sqlStmts.Label.Unassign = unassignUserLabel
+}
+
+const (
+	getUserLabel = `
+SELECT l.id, l.user_login, l.name
+FROM labels l
+WHERE l.id = :id AND l.user_login = :user_login
+`
+	getUserLabels = `
+SELECT l.id, l.user_login, l.name
+FROM labels l
+WHERE l.user_login = :user_login
+ORDER BY LOWER(l.name)
+`
+	createUserLabel = `
+INSERT INTO labels(user_login, name)
+VALUES(:user_login, :name)
+`
+	updateUserLabel = `
+UPDATE labels SET name = :name
+WHERE id = :id AND user_login = :user_login
+`
+	deleteUserLabel = `DELETE FROM labels WHERE id = :id AND user_login = :user_login`
+
+	assignUserLabel = `
+INSERT INTO articles_labels(label_id, article_id)
+SELECT l.id, a.id
+FROM labels l
+	INNER JOIN users_feeds uf
+		ON l.user_login = uf.user_login
+	INNER JOIN articles a
+		ON uf.feed_id = a.feed_id
+WHERE l.id = :id AND l.user_login = :user_login AND a.id = :article_id
+	AND NOT EXISTS (
+		SELECT 1 FROM articles_labels al WHERE al.label_id = l.id AND al.article_id = a.id
+	)
+`
+	unassignUserLabel = `
+DELETE FROM articles_labels
+WHERE article_id = :article_id AND label_id IN (
+	SELECT id FROM labels WHERE id = :id AND user_login = :user_login
+)
+`
+)
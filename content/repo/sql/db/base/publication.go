@@ -0,0 +1,51 @@
+package base
+
+func init() {
+	sqlStmts.Publication.Get = getUserPublication
+	sqlStmts.Publication.AllForUser = getUserPublications
+	sqlStmts.Publication.Create = createUserPublication
+	sqlStmts.Publication.Update = updateUserPublication
+	sqlStmts.Publication.Delete = deleteUserPublication
+	sqlStmts.Publication.Publish = publishUserArticle
+	sqlStmts.Publication.Unpublish = unpublishUserArticle
+}
+
+const (
+	getUserPublication = `
+SELECT p.id, p.user_login, COALESCE(p.article_id, 0) AS article_id,
+	p.title, p.link, p.description, p.note, p.publish_date AS date
+FROM publications p
+WHERE p.id = :id AND p.user_login = :user_login
+`
+	getUserPublications = `
+SELECT p.id, p.user_login, COALESCE(p.article_id, 0) AS article_id,
+	p.title, p.link, p.description, p.note, p.publish_date AS date
+FROM publications p
+WHERE p.user_login = :user_login
+ORDER BY p.publish_date DESC, p.id DESC
+LIMIT :limit OFFSET :offset
+`
+	createUserPublication = `
+INSERT INTO publications(user_login, title, link, description, note, publish_date)
+VALUES(:user_login, :title, :link, :description, :note, :date)
+`
+	updateUserPublication = `
+UPDATE publications SET title = :title, link = :link, description = :description, note = :note
+WHERE id = :id AND user_login = :user_login
+`
+	deleteUserPublication = `DELETE FROM publications WHERE id = :id AND user_login = :user_login`
+
+	publishUserArticle = `
+INSERT INTO publications(user_login, article_id, title, link, description, publish_date)
+SELECT uf.user_login, a.id, a.title, a.link, a.description, CURRENT_TIMESTAMP
+FROM users_feeds uf INNER JOIN articles a
+	ON uf.feed_id = a.feed_id
+WHERE uf.user_login = :user_login AND a.id = :article_id
+	AND NOT EXISTS (
+		SELECT 1 FROM publications p WHERE p.user_login = uf.user_login AND p.article_id = a.id
+	)
+`
+	unpublishUserArticle = `
+DELETE FROM publications WHERE user_login = :user_login AND article_id = :article_id
+`
+)
@@ -0,0 +1,32 @@
+package base
+
+func init() {
+	sqlStmts.Rule.Get = getUserRule
+	sqlStmts.Rule.AllForUser = getUserRules
+	sqlStmts.Rule.Create = createUserRule
+	sqlStmts.Rule.Update = updateUserRule
+	sqlStmts.Rule.Delete = deleteUserRule
+}
+
+const (
+	getUserRule = `
+SELECT r.id, r.user_login, r.name, r.field, r.match_type, r.value, r.actions
+FROM rules r
+WHERE r.id = :id AND r.user_login = :user_login
+`
+	getUserRules = `
+SELECT r.id, r.user_login, r.name, r.field, r.match_type, r.value, r.actions
+FROM rules r
+WHERE r.user_login = :user_login
+ORDER BY r.id
+`
+	createUserRule = `
+INSERT INTO rules(user_login, name, field, match_type, value, actions)
+VALUES(:user_login, :name, :field, :match_type, :value, :actions)
+`
+	updateUserRule = `
+UPDATE rules SET name = :name, field = :field, match_type = :match_type, value = :value, actions = :actions
+WHERE id = :id AND user_login = :user_login
+`
+	deleteUserRule = `DELETE FROM rules WHERE id = :id AND user_login = :user_login`
+)
@@ -0,0 +1,32 @@
+package base
+
+func init() {
+	sqlStmts.SavedSearch.Get = getUserSavedSearch
+	sqlStmts.SavedSearch.AllForUser = getUserSavedSearches
+	sqlStmts.SavedSearch.Create = createUserSavedSearch
+	sqlStmts.SavedSearch.Update = updateUserSavedSearch
+	sqlStmts.SavedSearch.Delete = deleteUserSavedSearch
+}
+
+const (
+	getUserSavedSearch = `
+SELECT s.id, s.user_login, s.name, s.query, s.feed_id, s.tag_id
+FROM saved_searches s
+WHERE s.id = :id AND s.user_login = :user_login
+`
+	getUserSavedSearches = `
+SELECT s.id, s.user_login, s.name, s.query, s.feed_id, s.tag_id
+FROM saved_searches s
+WHERE s.user_login = :user_login
+ORDER BY LOWER(s.name)
+`
+	createUserSavedSearch = `
+INSERT INTO saved_searches(user_login, name, query, feed_id, tag_id)
+VALUES(:user_login, :name, :query, :feed_id, :tag_id)
+`
+	updateUserSavedSearch = `
+UPDATE saved_searches SET name = :name, query = :query, feed_id = :feed_id, tag_id = :tag_id
+WHERE id = :id AND user_login = :user_login
+`
+	deleteUserSavedSearch = `DELETE FROM saved_searches WHERE id = :id AND user_login = :user_login`
+)
@@ -9,20 +9,21 @@ func init() {
 
 const (
 	getFeedHubbubSubscription = `
-SELECT link, lease_duration, verification_time, subscription_failure
+SELECT link, lease_duration, verification_time, subscription_failure, secret, last_push
 FROM hubbub_subscriptions WHERE feed_id = :feed_id`
 	getHubbubSubscriptions = `
-SELECT link, feed_id, lease_duration, verification_time, subscription_failure
+SELECT link, feed_id, lease_duration, verification_time, subscription_failure, secret, last_push
 	FROM hubbub_subscriptions`
 
 	createHubbubSubscription = `
-INSERT INTO hubbub_subscriptions(feed_id, link, lease_duration, verification_time, subscription_failure)
-	SELECT :feed_id, :link, :lease_duration, :verification_time, :subscription_failure EXCEPT
-	SELECT feed_id, link, lease_duration, verification_time, subscription_failure
+INSERT INTO hubbub_subscriptions(feed_id, link, lease_duration, verification_time, subscription_failure, secret, last_push)
+	SELECT :feed_id, :link, :lease_duration, :verification_time, :subscription_failure, :secret, :last_push EXCEPT
+	SELECT feed_id, link, lease_duration, verification_time, subscription_failure, secret, last_push
 		FROM hubbub_subscriptions WHERE feed_id = :feed_id
 `
 	updateHubbubSubscription = `
 UPDATE hubbub_subscriptions SET link = :link, lease_duration = :lease_duration,
-	verification_time = :verification_time, subscription_failure = :subscription_failure WHERE feed_id = :feed_id 
+	verification_time = :verification_time, subscription_failure = :subscription_failure,
+	secret = :secret, last_push = :last_push WHERE feed_id = :feed_id
 `
 )
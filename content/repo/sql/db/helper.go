@@ -24,15 +24,44 @@ type ArticleStmts struct {
 	StateReadColumn          string
 	StateUnreadJoin          string
 	StateFavoriteJoin        string
+	StateHiddenJoin          string
 	GetIDsTemplate           string
 	DeleteStaleUnreadRecords string
 	GetScoreJoin             string
 	GetUntaggedJoin          string
+	GetMediaTemplate         string
+	CreateMedia              string
+	GetCategoriesTemplate    string
+	CreateCategory           string
+	CategoriesWhere          string
+	GetLabelsTemplate        string
+	LabelsWhere              string
+	PublishedWhere           string
+	GetNotesTemplate         string
+	CreateNote               string
+	UpdateNote               string
+	DeleteNote               string
+	PurgeableTemplate        string
+	PurgeMaxAgeWhere         string
+	PurgeMaxCountWhere       string
+	DeleteTemplate           string
 
 	ReadStateInsertTemplate     string
 	ReadStateDeleteTemplate     string
 	FavoriteStateInsertTemplate string
 	FavoriteStateDeleteTemplate string
+	HiddenStateInsertTemplate   string
+	HiddenStateDeleteTemplate   string
+}
+
+type ChangeStmts struct {
+	Get                 string
+	Range               string
+	CreateForArticle    string
+	CreateForStale      string
+	StateChangeTemplate string
+	ArticleWhere        string
+	DeleteStale         string
 }
 
 type ExtractStmts struct {
@@ -60,6 +89,88 @@ type FeedStmts struct {
 	Detach         string
 	CreateUserTag  string
 	DeleteUserTags string
+
+	MergeUsers         string
+	MergeUserTags      string
+	MergeSavedSearches string
+	MergeSettings      string
+	MergeUnread        string
+	MergeFavorite      string
+	MergeHidden        string
+	MergeNotes         string
+	MergeLabels        string
+	MergeArticles      string
+}
+
+type FeedHealthStmts struct {
+	Get     string
+	Failing string
+	History string
+
+	Create       string
+	Update       string
+	CreateFetch  string
+	PruneFetches string
+}
+
+type FeedSettingsStmts struct {
+	Get    string
+	Create string
+	Update string
+
+	DeleteOwned string
+}
+
+type HubSubscriptionStmts struct {
+	All      string
+	ForTopic string
+
+	Create        string
+	Update        string
+	Delete        string
+	DeleteExpired string
+}
+
+type LabelStmts struct {
+	Get        string
+	AllForUser string
+
+	Create string
+	Update string
+	Delete string
+
+	Assign   string
+	Unassign string
+}
+
+type PublicationStmts struct {
+	Get        string
+	AllForUser string
+
+	Create string
+	Update string
+	Delete string
+
+	Publish   string
+	Unpublish string
+}
+
+type SavedSearchStmts struct {
+	Get        string
+	AllForUser string
+
+	Create string
+	Update string
+	Delete string
+}
+
+type RuleStmts struct {
+	Get        string
+	AllForUser string
+
+	Create string
+	Update string
+	Delete string
 }
 
 type ScoresStmts struct {
@@ -76,6 +187,12 @@ type SubscriptionStmts struct {
 	Update string
 }
 
+type FeedImageStmts struct {
+	Get    string
+	Create string
+	Update string
+}
+
 type TagStmts struct {
 	Get            string
 	GetByValue     string
@@ -103,14 +220,23 @@ type UserStmts struct {
 }
 
 type SqlStmts struct {
-	Article      ArticleStmts
-	Extract      ExtractStmts
-	Feed         FeedStmts
-	Scores       ScoresStmts
-	Subscription SubscriptionStmts
-	Tag          TagStmts
-	Thumbnail    ThumbnailStmts
-	User         UserStmts
+	Article         ArticleStmts
+	Change          ChangeStmts
+	Extract         ExtractStmts
+	Feed            FeedStmts
+	FeedHealth      FeedHealthStmts
+	FeedImage       FeedImageStmts
+	FeedSettings    FeedSettingsStmts
+	HubSubscription HubSubscriptionStmts
+	Label           LabelStmts
+	Publication     PublicationStmts
+	Rule            RuleStmts
+	SavedSearch     SavedSearchStmts
+	Scores          ScoresStmts
+	Subscription    SubscriptionStmts
+	Tag             TagStmts
+	Thumbnail       ThumbnailStmts
+	User            UserStmts
 }
 
 func Register(driver string, helper Helper) {
@@ -49,6 +49,20 @@ func (h Helper) Upgrade(db *db.DB, old, new int) error {
 			err = upgrade2to3(db)
 		case 3:
 			err = upgrade3to4(db)
+		case 4:
+			err = upgrade4to5(db)
+		case 5:
+			err = upgrade5to6(db)
+		case 6:
+			err = upgrade6to7(db)
+		case 7:
+			err = upgrade7to8(db)
+		case 8:
+			err = upgrade8to9(db)
+		case 9:
+			err = upgrade9to10(db)
+		case 10:
+			err = upgrade10to11(db)
 		}
 
 		if err != nil {
@@ -177,6 +191,139 @@ func upgrade3to4(db *db.DB) error {
 	return tx.Commit()
 }
 
+func upgrade4to5(db *db.DB) error {
+	tx, err := db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(upgrade4To5AddFeedETag)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(upgrade4To5AddFeedLastModified)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func upgrade5to6(db *db.DB) error {
+	tx, err := db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(upgrade5To6AddFeedNextUpdate)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(upgrade5To6PopulateFeedNextUpdate)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func upgrade6to7(db *db.DB) error {
+	tx, err := db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// The old feed_images table was never populated
+	_, err = tx.Exec(upgrade6To7DropFeedImages)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(createFeedImages)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func upgrade7to8(db *db.DB) error {
+	tx, err := db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(upgrade7To8AddArticleAuthor)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func upgrade8to9(db *db.DB) error {
+	tx, err := db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(upgrade8To9AddSubscriptionSecret)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(upgrade8To9AddSubscriptionLastPush)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(upgrade8To9PopulateSubscriptionLastPush)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func upgrade9to10(db *db.DB) error {
+	tx, err := db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(upgrade9To10AddFeedDead)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// upgrade10to11 adds the owner of the feed settings, which is already
+// present when the table was created by the current init statements.
+func upgrade10to11(db *db.DB) error {
+	tx, err := db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(upgrade10To11AddSettingsOwner)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
 func init() {
 	helper := &Helper{Helper: base.NewHelper()}
 
@@ -189,7 +336,8 @@ func init() {
 
 const (
 	getUserFeeds = `
-SELECT f.id, f.link, f.title, f.description, f.link, f.hub_link, f.site_link, f.update_error, f.subscribe_error
+SELECT f.id, f.link, f.title, f.description, f.link, f.hub_link, f.site_link, f.update_error, f.subscribe_error,
+	f.etag, f.last_modified, f.next_update, f.dead
 FROM feeds f, users_feeds uf
 WHERE f.id = uf.feed_id
 	AND uf.user_login = :user_login
@@ -231,4 +379,18 @@ SELECT uft.user_login, uft.feed_id, t.id
 FROM tags t INNER JOIN users_feeds_tags2 uft
 	ON t.value = uft.tag
 `
+	upgrade4To5AddFeedETag            = `ALTER TABLE feeds ADD COLUMN etag TEXT NOT NULL DEFAULT ''`
+	upgrade4To5AddFeedLastModified    = `ALTER TABLE feeds ADD COLUMN last_modified TEXT NOT NULL DEFAULT ''`
+	upgrade5To6AddFeedNextUpdate      = `ALTER TABLE feeds ADD COLUMN next_update TIMESTAMP WITH TIME ZONE`
+	upgrade5To6PopulateFeedNextUpdate = `UPDATE feeds SET next_update = CURRENT_TIMESTAMP`
+	upgrade6To7DropFeedImages         = `DROP TABLE feed_images`
+	upgrade7To8AddArticleAuthor       = `ALTER TABLE articles ADD COLUMN author TEXT NOT NULL DEFAULT ''`
+
+	upgrade8To9AddSubscriptionSecret        = `ALTER TABLE hubbub_subscriptions ADD COLUMN secret TEXT NOT NULL DEFAULT ''`
+	upgrade8To9AddSubscriptionLastPush      = `ALTER TABLE hubbub_subscriptions ADD COLUMN last_push TIMESTAMP WITH TIME ZONE`
+	upgrade8To9PopulateSubscriptionLastPush = `UPDATE hubbub_subscriptions SET last_push = verification_time`
+
+	upgrade9To10AddFeedDead = `ALTER TABLE feeds ADD COLUMN dead BOOLEAN NOT NULL DEFAULT 'f'`
+
+	upgrade10To11AddSettingsOwner = `ALTER TABLE feed_settings ADD COLUMN IF NOT EXISTS owner TEXT REFERENCES users(login) ON DELETE CASCADE`
 )
@@ -1,5 +1,21 @@
 package postgres
 
+const (
+	createFeedImages = `
+CREATE TABLE IF NOT EXISTS feed_images (
+	feed_id INTEGER PRIMARY KEY,
+	title TEXT NOT NULL DEFAULT '',
+	url TEXT NOT NULL DEFAULT '',
+	width INTEGER NOT NULL DEFAULT 0,
+	height INTEGER NOT NULL DEFAULT 0,
+	icon BYTEA,
+	icon_type TEXT NOT NULL DEFAULT '',
+	fetch_date TIMESTAMP WITH TIME ZONE NOT NULL,
+
+	FOREIGN KEY(feed_id) REFERENCES feeds(id) ON DELETE CASCADE
+)`
+)
+
 var (
 	initSQL = []string{`
 CREATE TABLE IF NOT EXISTS readeef (
@@ -26,18 +42,13 @@ CREATE TABLE IF NOT EXISTS feeds (
 	hub_link TEXT,
 	site_link TEXT,
 	update_error TEXT,
-	subscribe_error TEXT
-)`, `
-CREATE TABLE IF NOT EXISTS feed_images (
-	id SERIAL PRIMARY KEY,
-	feed_id INTEGER NOT NULL,
-	title TEXT,
-	url TEXT,
-	width INTEGER,
-	height INTEGER,
-
-	FOREIGN KEY(feed_id) REFERENCES feeds(id) ON DELETE CASCADE
-)`, `
+	subscribe_error TEXT,
+	etag TEXT NOT NULL DEFAULT '',
+	last_modified TEXT NOT NULL DEFAULT '',
+	next_update TIMESTAMP WITH TIME ZONE,
+	dead BOOLEAN NOT NULL DEFAULT 'f'
+)`,
+		createFeedImages, `
 CREATE TABLE IF NOT EXISTS articles (
 	id BIGSERIAL PRIMARY KEY,
 	feed_id INTEGER,
@@ -46,6 +57,7 @@ CREATE TABLE IF NOT EXISTS articles (
 	title TEXT,
 	description TEXT,
 	date TIMESTAMP WITH TIME ZONE,
+	author TEXT NOT NULL DEFAULT '',
 
 	UNIQUE(feed_id, link),
 	UNIQUE(feed_id, guid),
@@ -90,6 +102,83 @@ CREATE TABLE IF NOT EXISTS users_articles_favorite (
 	FOREIGN KEY(user_login) REFERENCES users(login) ON DELETE CASCADE,
 	FOREIGN KEY(article_id) REFERENCES articles(id) ON DELETE CASCADE
 )`, `
+CREATE TABLE IF NOT EXISTS users_articles_hidden (
+	user_login TEXT,
+	article_id BIGINT,
+
+	PRIMARY KEY(user_login, article_id),
+	FOREIGN KEY(user_login) REFERENCES users(login) ON DELETE CASCADE,
+	FOREIGN KEY(article_id) REFERENCES articles(id) ON DELETE CASCADE
+)`, `
+CREATE TABLE IF NOT EXISTS users_articles_notes (
+	user_login TEXT,
+	article_id BIGINT,
+	note TEXT,
+
+	PRIMARY KEY(user_login, article_id),
+	FOREIGN KEY(user_login) REFERENCES users(login) ON DELETE CASCADE,
+	FOREIGN KEY(article_id) REFERENCES articles(id) ON DELETE CASCADE
+)`, `
+CREATE TABLE IF NOT EXISTS users_articles_changes (
+	id BIGSERIAL PRIMARY KEY,
+	user_login TEXT NOT NULL,
+	article_id BIGINT NOT NULL,
+	change_type TEXT NOT NULL,
+	change_date TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+
+	FOREIGN KEY(user_login) REFERENCES users(login) ON DELETE CASCADE
+)`, `
+CREATE TABLE IF NOT EXISTS rules (
+	id SERIAL PRIMARY KEY,
+	user_login TEXT NOT NULL,
+	name TEXT NOT NULL DEFAULT '',
+	field TEXT NOT NULL,
+	match_type TEXT NOT NULL,
+	value TEXT NOT NULL,
+	actions TEXT NOT NULL,
+
+	FOREIGN KEY(user_login) REFERENCES users(login) ON DELETE CASCADE
+)`, `
+CREATE TABLE IF NOT EXISTS labels (
+	id SERIAL PRIMARY KEY,
+	user_login TEXT NOT NULL,
+	name TEXT NOT NULL,
+
+	UNIQUE(user_login, name),
+	FOREIGN KEY(user_login) REFERENCES users(login) ON DELETE CASCADE
+)`, `
+CREATE TABLE IF NOT EXISTS articles_labels (
+	label_id INTEGER,
+	article_id BIGINT,
+
+	PRIMARY KEY(label_id, article_id),
+	FOREIGN KEY(label_id) REFERENCES labels(id) ON DELETE CASCADE,
+	FOREIGN KEY(article_id) REFERENCES articles(id) ON DELETE CASCADE
+)`, `
+CREATE TABLE IF NOT EXISTS publications (
+	id SERIAL PRIMARY KEY,
+	user_login TEXT NOT NULL,
+	article_id BIGINT,
+	title TEXT NOT NULL,
+	link TEXT NOT NULL,
+	description TEXT NOT NULL DEFAULT '',
+	note TEXT NOT NULL DEFAULT '',
+	publish_date TIMESTAMP WITH TIME ZONE NOT NULL,
+
+	UNIQUE(user_login, article_id),
+	FOREIGN KEY(user_login) REFERENCES users(login) ON DELETE CASCADE
+)`, `
+CREATE TABLE IF NOT EXISTS saved_searches (
+	id SERIAL PRIMARY KEY,
+	user_login TEXT NOT NULL,
+	name TEXT NOT NULL,
+	query TEXT NOT NULL,
+	feed_id INTEGER NOT NULL DEFAULT 0,
+	tag_id INTEGER NOT NULL DEFAULT 0,
+
+	UNIQUE(user_login, name),
+	FOREIGN KEY(user_login) REFERENCES users(login) ON DELETE CASCADE
+)`, `
 CREATE TABLE IF NOT EXISTS articles_scores (
 	article_id BIGINT,
 	score  BIGINT,
@@ -121,16 +210,89 @@ CREATE TABLE IF NOT EXISTS articles_extracts (
 	PRIMARY KEY(article_id),
 	FOREIGN KEY(article_id) REFERENCES articles(id) ON DELETE CASCADE
 )`, `
+CREATE TABLE IF NOT EXISTS articles_media (
+	id BIGSERIAL PRIMARY KEY,
+	article_id BIGINT NOT NULL,
+	link TEXT NOT NULL,
+	type TEXT,
+	medium TEXT,
+	title TEXT,
+	length BIGINT,
+	duration INTEGER,
+	width INTEGER,
+	height INTEGER,
+
+	UNIQUE(article_id, link),
+	FOREIGN KEY(article_id) REFERENCES articles(id) ON DELETE CASCADE
+)`, `
+CREATE TABLE IF NOT EXISTS articles_categories (
+	article_id BIGINT NOT NULL,
+	category TEXT NOT NULL,
+
+	PRIMARY KEY(article_id, category),
+	FOREIGN KEY(article_id) REFERENCES articles(id) ON DELETE CASCADE
+)`, `
 CREATE TABLE IF NOT EXISTS hubbub_subscriptions (
 	feed_id INTEGER,
 	link TEXT,
 	lease_duration BIGINT,
 	verification_time TIMESTAMP WITH TIME ZONE,
 	subscription_failure BOOLEAN DEFAULT 'f',
+	secret TEXT NOT NULL DEFAULT '',
+	last_push TIMESTAMP WITH TIME ZONE,
+
+	PRIMARY KEY(feed_id),
+	FOREIGN KEY(feed_id) REFERENCES feeds(id) ON DELETE CASCADE
+)`, `
+CREATE TABLE IF NOT EXISTS feed_settings (
+	feed_id INTEGER NOT NULL,
+	owner TEXT,
+	update_interval BIGINT NOT NULL DEFAULT 0,
+	paused BOOLEAN NOT NULL DEFAULT 'f',
+	user_agent TEXT NOT NULL DEFAULT '',
+	headers TEXT NOT NULL DEFAULT '',
+	username TEXT NOT NULL DEFAULT '',
+	password TEXT NOT NULL DEFAULT '',
+	cookie TEXT NOT NULL DEFAULT '',
+	extract_content BOOLEAN NOT NULL DEFAULT 'f',
+
+	PRIMARY KEY(feed_id),
+	FOREIGN KEY(feed_id) REFERENCES feeds(id) ON DELETE CASCADE,
+	FOREIGN KEY(owner) REFERENCES users(login) ON DELETE CASCADE
+)`, `
+CREATE TABLE IF NOT EXISTS feed_health (
+	feed_id INTEGER NOT NULL,
+	last_fetch TIMESTAMP WITH TIME ZONE NOT NULL,
+	last_success TIMESTAMP WITH TIME ZONE NOT NULL,
+	failing_since TIMESTAMP WITH TIME ZONE NOT NULL,
+	failures INTEGER NOT NULL DEFAULT 0,
+	last_status_code INTEGER NOT NULL DEFAULT 0,
+	last_error_class TEXT NOT NULL DEFAULT '',
+	last_error TEXT NOT NULL DEFAULT '',
 
 	PRIMARY KEY(feed_id),
 	FOREIGN KEY(feed_id) REFERENCES feeds(id) ON DELETE CASCADE
 )`, `
+CREATE TABLE IF NOT EXISTS feed_fetches (
+	feed_id INTEGER NOT NULL,
+	fetch_time TIMESTAMP WITH TIME ZONE NOT NULL,
+	status_code INTEGER NOT NULL DEFAULT 0,
+	duration BIGINT NOT NULL DEFAULT 0,
+	bytes BIGINT NOT NULL DEFAULT 0,
+	new_articles INTEGER NOT NULL DEFAULT 0,
+	error_class TEXT NOT NULL DEFAULT '',
+	error TEXT NOT NULL DEFAULT '',
+
+	FOREIGN KEY(feed_id) REFERENCES feeds(id) ON DELETE CASCADE
+)`, `
+CREATE TABLE IF NOT EXISTS hub_subscriptions (
+	topic TEXT NOT NULL,
+	callback TEXT NOT NULL,
+	secret TEXT NOT NULL DEFAULT '',
+	lease_expiry TIMESTAMP WITH TIME ZONE NOT NULL,
+
+	PRIMARY KEY(topic, callback)
+)`, `
 CREATE INDEX IF NOT EXISTS articles_feed_id_idx ON articles (feed_id);
 `, `
 CREATE INDEX IF NOT EXISTS articles_title_idx ON articles (LOWER(title));
@@ -138,6 +300,24 @@ CREATE INDEX IF NOT EXISTS articles_title_idx ON articles (LOWER(title));
 CREATE INDEX IF NOT EXISTS articles_link_idx ON articles (LOWER(link));
 `, `
 CREATE INDEX IF NOT EXISTS articles_date_idx ON articles (date);
+`, `
+CREATE INDEX IF NOT EXISTS articles_categories_category_idx ON articles_categories (LOWER(category));
+`, `
+CREATE INDEX IF NOT EXISTS rules_user_login_idx ON rules (user_login);
+`, `
+CREATE INDEX IF NOT EXISTS articles_labels_article_id_idx ON articles_labels (article_id);
+`, `
+CREATE INDEX IF NOT EXISTS publications_user_login_idx ON publications (user_login, publish_date);
+`, `
+CREATE INDEX IF NOT EXISTS publications_article_id_idx ON publications (article_id);
+`, `
+CREATE INDEX IF NOT EXISTS users_articles_changes_user_login_idx ON users_articles_changes (user_login, id);
+`, `
+CREATE INDEX IF NOT EXISTS users_articles_changes_change_date_idx ON users_articles_changes (change_date);
+`, `
+CREATE INDEX IF NOT EXISTS users_articles_changes_article_id_idx ON users_articles_changes (article_id);
+`, `
+CREATE INDEX IF NOT EXISTS feed_fetches_feed_id_idx ON feed_fetches (feed_id, fetch_time);
 `,
 	}
 )
@@ -1,5 +1,21 @@
 package sqlite3
 
+const (
+	createFeedImages = `
+CREATE TABLE IF NOT EXISTS feed_images (
+	feed_id INTEGER PRIMARY KEY,
+	title TEXT NOT NULL DEFAULT '',
+	url TEXT NOT NULL DEFAULT '',
+	width INTEGER NOT NULL DEFAULT 0,
+	height INTEGER NOT NULL DEFAULT 0,
+	icon BLOB,
+	icon_type TEXT NOT NULL DEFAULT '',
+	fetch_date TIMESTAMP NOT NULL,
+
+	FOREIGN KEY(feed_id) REFERENCES feeds(id) ON DELETE CASCADE
+)`
+)
+
 var (
 	initSQL = []string{`
 PRAGMA foreign_keys = ON`, `
@@ -28,18 +44,13 @@ CREATE TABLE IF NOT EXISTS feeds (
 	hub_link TEXT,
 	site_link TEXT,
 	update_error TEXT,
-	subscribe_error TEXT
-)`, `
-CREATE TABLE IF NOT EXISTS feed_images (
-	id INTEGER PRIMARY KEY,
-	feed_id INTEGER NOT NULL,
-	title TEXT,
-	url TEXT,
-	width INTEGER,
-	height INTEGER,
-
-	FOREIGN KEY(feed_id) REFERENCES feeds(id) ON DELETE CASCADE
-)`, `
+	subscribe_error TEXT,
+	etag TEXT NOT NULL DEFAULT '',
+	last_modified TEXT NOT NULL DEFAULT '',
+	next_update TIMESTAMP,
+	dead INTEGER NOT NULL DEFAULT 0
+)`,
+		createFeedImages, `
 CREATE TABLE IF NOT EXISTS articles (
 	id INTEGER PRIMARY KEY,
 	feed_id INTEGER,
@@ -48,6 +59,7 @@ CREATE TABLE IF NOT EXISTS articles (
 	title TEXT,
 	description TEXT,
 	date TIMESTAMP,
+	author TEXT NOT NULL DEFAULT '',
 
 	UNIQUE(feed_id, link),
 	UNIQUE(feed_id, guid),
@@ -92,6 +104,83 @@ CREATE TABLE IF NOT EXISTS users_articles_favorite (
 	FOREIGN KEY(user_login) REFERENCES users(login) ON DELETE CASCADE,
 	FOREIGN KEY(article_id) REFERENCES articles(id) ON DELETE CASCADE
 )`, `
+CREATE TABLE IF NOT EXISTS users_articles_hidden (
+	user_login TEXT,
+	article_id BIGINT,
+
+	PRIMARY KEY(user_login, article_id),
+	FOREIGN KEY(user_login) REFERENCES users(login) ON DELETE CASCADE,
+	FOREIGN KEY(article_id) REFERENCES articles(id) ON DELETE CASCADE
+)`, `
+CREATE TABLE IF NOT EXISTS users_articles_notes (
+	user_login TEXT,
+	article_id BIGINT,
+	note TEXT,
+
+	PRIMARY KEY(user_login, article_id),
+	FOREIGN KEY(user_login) REFERENCES users(login) ON DELETE CASCADE,
+	FOREIGN KEY(article_id) REFERENCES articles(id) ON DELETE CASCADE
+)`, `
+CREATE TABLE IF NOT EXISTS users_articles_changes (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	user_login TEXT NOT NULL,
+	article_id BIGINT NOT NULL,
+	change_type TEXT NOT NULL,
+	change_date TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+	FOREIGN KEY(user_login) REFERENCES users(login) ON DELETE CASCADE
+)`, `
+CREATE TABLE IF NOT EXISTS rules (
+	id INTEGER PRIMARY KEY,
+	user_login TEXT NOT NULL,
+	name TEXT NOT NULL DEFAULT '',
+	field TEXT NOT NULL,
+	match_type TEXT NOT NULL,
+	value TEXT NOT NULL,
+	actions TEXT NOT NULL,
+
+	FOREIGN KEY(user_login) REFERENCES users(login) ON DELETE CASCADE
+)`, `
+CREATE TABLE IF NOT EXISTS labels (
+	id INTEGER PRIMARY KEY,
+	user_login TEXT NOT NULL,
+	name TEXT NOT NULL,
+
+	UNIQUE(user_login, name),
+	FOREIGN KEY(user_login) REFERENCES users(login) ON DELETE CASCADE
+)`, `
+CREATE TABLE IF NOT EXISTS articles_labels (
+	label_id INTEGER,
+	article_id BIGINT,
+
+	PRIMARY KEY(label_id, article_id),
+	FOREIGN KEY(label_id) REFERENCES labels(id) ON DELETE CASCADE,
+	FOREIGN KEY(article_id) REFERENCES articles(id) ON DELETE CASCADE
+)`, `
+CREATE TABLE IF NOT EXISTS publications (
+	id INTEGER PRIMARY KEY,
+	user_login TEXT NOT NULL,
+	article_id BIGINT,
+	title TEXT NOT NULL,
+	link TEXT NOT NULL,
+	description TEXT NOT NULL DEFAULT '',
+	note TEXT NOT NULL DEFAULT '',
+	publish_date TIMESTAMP NOT NULL,
+
+	UNIQUE(user_login, article_id),
+	FOREIGN KEY(user_login) REFERENCES users(login) ON DELETE CASCADE
+)`, `
+CREATE TABLE IF NOT EXISTS saved_searches (
+	id INTEGER PRIMARY KEY,
+	user_login TEXT NOT NULL,
+	name TEXT NOT NULL,
+	query TEXT NOT NULL,
+	feed_id INTEGER NOT NULL DEFAULT 0,
+	tag_id INTEGER NOT NULL DEFAULT 0,
+
+	UNIQUE(user_login, name),
+	FOREIGN KEY(user_login) REFERENCES users(login) ON DELETE CASCADE
+)`, `
 CREATE TABLE IF NOT EXISTS articles_scores (
 	article_id BIGINT,
 	score  INTEGER,
@@ -123,16 +212,89 @@ CREATE TABLE IF NOT EXISTS articles_extracts (
 	PRIMARY KEY(article_id),
 	FOREIGN KEY(article_id) REFERENCES articles(id) ON DELETE CASCADE
 )`, `
+CREATE TABLE IF NOT EXISTS articles_media (
+	id INTEGER PRIMARY KEY,
+	article_id BIGINT NOT NULL,
+	link TEXT NOT NULL,
+	type TEXT,
+	medium TEXT,
+	title TEXT,
+	length BIGINT,
+	duration INTEGER,
+	width INTEGER,
+	height INTEGER,
+
+	UNIQUE(article_id, link),
+	FOREIGN KEY(article_id) REFERENCES articles(id) ON DELETE CASCADE
+)`, `
+CREATE TABLE IF NOT EXISTS articles_categories (
+	article_id INTEGER NOT NULL,
+	category TEXT NOT NULL,
+
+	PRIMARY KEY(article_id, category),
+	FOREIGN KEY(article_id) REFERENCES articles(id) ON DELETE CASCADE
+)`, `
 CREATE TABLE IF NOT EXISTS hubbub_subscriptions (
 	feed_id INTEGER,
 	link TEXT,
 	lease_duration INTEGER,
 	verification_time TIMESTAMP,
 	subscription_failure INTEGER DEFAULT 0,
+	secret TEXT NOT NULL DEFAULT '',
+	last_push TIMESTAMP,
 
 	PRIMARY KEY(feed_id),
 	FOREIGN KEY(feed_id) REFERENCES feeds(id) ON DELETE CASCADE
 )`, `
+CREATE TABLE IF NOT EXISTS feed_settings (
+	feed_id INTEGER NOT NULL,
+	owner TEXT,
+	update_interval INTEGER NOT NULL DEFAULT 0,
+	paused INTEGER NOT NULL DEFAULT 0,
+	user_agent TEXT NOT NULL DEFAULT '',
+	headers TEXT NOT NULL DEFAULT '',
+	username TEXT NOT NULL DEFAULT '',
+	password TEXT NOT NULL DEFAULT '',
+	cookie TEXT NOT NULL DEFAULT '',
+	extract_content INTEGER NOT NULL DEFAULT 0,
+
+	PRIMARY KEY(feed_id),
+	FOREIGN KEY(feed_id) REFERENCES feeds(id) ON DELETE CASCADE,
+	FOREIGN KEY(owner) REFERENCES users(login) ON DELETE CASCADE
+)`, `
+CREATE TABLE IF NOT EXISTS feed_health (
+	feed_id INTEGER NOT NULL,
+	last_fetch TIMESTAMP NOT NULL,
+	last_success TIMESTAMP NOT NULL,
+	failing_since TIMESTAMP NOT NULL,
+	failures INTEGER NOT NULL DEFAULT 0,
+	last_status_code INTEGER NOT NULL DEFAULT 0,
+	last_error_class TEXT NOT NULL DEFAULT '',
+	last_error TEXT NOT NULL DEFAULT '',
+
+	PRIMARY KEY(feed_id),
+	FOREIGN KEY(feed_id) REFERENCES feeds(id) ON DELETE CASCADE
+)`, `
+CREATE TABLE IF NOT EXISTS feed_fetches (
+	feed_id INTEGER NOT NULL,
+	fetch_time TIMESTAMP NOT NULL,
+	status_code INTEGER NOT NULL DEFAULT 0,
+	duration INTEGER NOT NULL DEFAULT 0,
+	bytes INTEGER NOT NULL DEFAULT 0,
+	new_articles INTEGER NOT NULL DEFAULT 0,
+	error_class TEXT NOT NULL DEFAULT '',
+	error TEXT NOT NULL DEFAULT '',
+
+	FOREIGN KEY(feed_id) REFERENCES feeds(id) ON DELETE CASCADE
+)`, `
+CREATE TABLE IF NOT EXISTS hub_subscriptions (
+	topic TEXT NOT NULL,
+	callback TEXT NOT NULL,
+	secret TEXT NOT NULL DEFAULT '',
+	lease_expiry TIMESTAMP NOT NULL,
+
+	PRIMARY KEY(topic, callback)
+)`, `
 CREATE INDEX IF NOT EXISTS articles_feed_id_idx ON articles (feed_id);
 `, `
 CREATE INDEX IF NOT EXISTS articles_title_idx ON articles (LOWER(title));
@@ -140,6 +302,24 @@ CREATE INDEX IF NOT EXISTS articles_title_idx ON articles (LOWER(title));
 CREATE INDEX IF NOT EXISTS articles_link_idx ON articles (LOWER(link));
 `, `
 CREATE INDEX IF NOT EXISTS articles_date_idx ON articles (date);
+`, `
+CREATE INDEX IF NOT EXISTS articles_categories_category_idx ON articles_categories (LOWER(category));
+`, `
+CREATE INDEX IF NOT EXISTS rules_user_login_idx ON rules (user_login);
+`, `
+CREATE INDEX IF NOT EXISTS articles_labels_article_id_idx ON articles_labels (article_id);
+`, `
+CREATE INDEX IF NOT EXISTS publications_user_login_idx ON publications (user_login, publish_date);
+`, `
+CREATE INDEX IF NOT EXISTS publications_article_id_idx ON publications (article_id);
+`, `
+CREATE INDEX IF NOT EXISTS users_articles_changes_user_login_idx ON users_articles_changes (user_login, id);
+`, `
+CREATE INDEX IF NOT EXISTS users_articles_changes_change_date_idx ON users_articles_changes (change_date);
+`, `
+CREATE INDEX IF NOT EXISTS users_articles_changes_article_id_idx ON users_articles_changes (article_id);
+`, `
+CREATE INDEX IF NOT EXISTS feed_fetches_feed_id_idx ON feed_fetches (feed_id, fetch_time);
 `,
 	}
 )
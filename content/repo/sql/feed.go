@@ -217,6 +217,66 @@ func (r feedRepo) Delete(feed content.Feed) error {
 	})
 }
 
+type feedMerge struct {
+	ID     content.FeedID `db:"id"`
+	IntoID content.FeedID `db:"into_id"`
+}
+
+// Merge moves the users of the feed, along with their tags and saved
+// searches, its settings and its articles to the other feed, and deletes the
+// feed. Articles already present in the other feed are dropped, after their
+// user state is carried over to their counterparts.
+func (r feedRepo) Merge(feed, into content.Feed) error {
+	if err := feed.Validate(); err != nil {
+		return errors.WithMessage(err, "validating feed")
+	}
+
+	if err := into.Validate(); err != nil {
+		return errors.WithMessage(err, "validating merged feed")
+	}
+
+	if feed.ID == into.ID {
+		return errors.Errorf("merging feed %s into itself", feed)
+	}
+
+	r.log.Infof("Merging feed %s into %s", feed, into)
+
+	return r.db.WithTx(func(tx *sqlx.Tx) error {
+		s := r.db.SQL()
+		args := feedMerge{ID: feed.ID, IntoID: into.ID}
+
+		for _, stmt := range []struct {
+			query string
+			name  string
+		}{
+			{s.Feed.MergeUsers, "users"},
+			{s.Feed.MergeUserTags, "user tags"},
+			{s.Feed.MergeSavedSearches, "saved searches"},
+			{s.Feed.MergeSettings, "settings"},
+			{s.Feed.MergeUnread, "unread articles"},
+			{s.Feed.MergeFavorite, "favorite articles"},
+			{s.Feed.MergeHidden, "hidden articles"},
+			{s.Feed.MergeNotes, "article notes"},
+			{s.Feed.MergeLabels, "article labels"},
+			{s.Feed.MergeArticles, "articles"},
+		} {
+			if err := r.db.WithNamedStmt(stmt.query, tx, func(nstmt *sqlx.NamedStmt) error {
+				_, err := nstmt.Exec(args)
+				return err
+			}); err != nil {
+				return errors.Wrapf(err, "merging feed %s", stmt.name)
+			}
+		}
+
+		return r.db.WithNamedStmt(s.Feed.Delete, tx, func(stmt *sqlx.NamedStmt) error {
+			if _, err := stmt.Exec(feed); err != nil {
+				return errors.Wrap(err, "executing feed delete stmt")
+			}
+			return nil
+		})
+	})
+}
+
 func (r feedRepo) Users(feed content.Feed) ([]content.User, error) {
 	if err := feed.Validate(); err != nil {
 		return []content.User{}, errors.WithMessage(err, "validating feed")
@@ -245,14 +305,31 @@ func (r feedRepo) AttachTo(feed content.Feed, user content.User) error {
 
 	r.log.Infof("Attaching feed %s to %s", feed, user)
 
-	if err := r.db.WithNamedStmt(r.db.SQL().Feed.Attach, nil, func(stmt *sqlx.NamedStmt) error {
-		_, err := stmt.Exec(feedQuery{UserLogin: user.Login, ID: feed.ID})
-		return err
-	}); err != nil {
-		return errors.Wrap(err, "executing feed attach stmt")
-	}
+	return r.db.WithTx(func(tx *sqlx.Tx) error {
+		s := r.db.SQL()
 
-	return nil
+		// The feeds fetched with credentials are only available to the
+		// user that set them
+		settings := content.FeedSettings{FeedID: feed.ID}
+		if err := r.db.WithNamedStmt(s.FeedSettings.Get, tx, func(stmt *sqlx.NamedStmt) error {
+			return stmt.Get(&settings, settings)
+		}); err != nil && err != sql.ErrNoRows {
+			return errors.Wrap(err, "getting feed settings")
+		}
+
+		if settings.Private() && settings.Owner != user.Login {
+			return errors.Wrapf(content.ErrPrivateFeed, "attaching feed %s to %s", feed, user)
+		}
+
+		if err := r.db.WithNamedStmt(s.Feed.Attach, tx, func(stmt *sqlx.NamedStmt) error {
+			_, err := stmt.Exec(feedQuery{UserLogin: user.Login, ID: feed.ID})
+			return err
+		}); err != nil {
+			return errors.Wrap(err, "executing feed attach stmt")
+		}
+
+		return nil
+	})
 }
 
 func (r feedRepo) DetachFrom(feed content.Feed, user content.User) error {
@@ -266,14 +343,27 @@ func (r feedRepo) DetachFrom(feed content.Feed, user content.User) error {
 
 	r.log.Infof("Detaching feed %s from %s", feed, user)
 
-	if err := r.db.WithNamedStmt(r.db.SQL().Feed.Detach, nil, func(stmt *sqlx.NamedStmt) error {
-		_, err := stmt.Exec(feedQuery{UserLogin: user.Login, ID: feed.ID})
-		return err
-	}); err != nil {
-		return errors.Wrap(err, "executing feed detach stmt")
-	}
+	return r.db.WithTx(func(tx *sqlx.Tx) error {
+		s := r.db.SQL()
+		args := feedQuery{UserLogin: user.Login, ID: feed.ID}
 
-	return nil
+		if err := r.db.WithNamedStmt(s.Feed.Detach, tx, func(stmt *sqlx.NamedStmt) error {
+			_, err := stmt.Exec(args)
+			return err
+		}); err != nil {
+			return errors.Wrap(err, "executing feed detach stmt")
+		}
+
+		// The settings, along with any credentials, leave with their owner
+		if err := r.db.WithNamedStmt(s.FeedSettings.DeleteOwned, tx, func(stmt *sqlx.NamedStmt) error {
+			_, err := stmt.Exec(args)
+			return err
+		}); err != nil {
+			return errors.Wrap(err, "executing owned feed settings delete stmt")
+		}
+
+		return nil
+	})
 }
 
 type userFeedTag struct {
@@ -371,6 +461,10 @@ func (r feedRepo) updateFeedArticles(feed content.Feed, tx *sqlx.Tx) ([]content.
 		}
 
 		if a.IsNew {
+			if err := createArticleChanges(a, tx, r.db); err != nil {
+				return []content.Article{}, errors.WithMessage(err, "updating feed articles")
+			}
+
 			articles = append(articles, a)
 		}
 	}
@@ -0,0 +1,153 @@
+package sql
+
+import (
+	"database/sql"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+	"github.com/urandom/readeef/content"
+	"github.com/urandom/readeef/content/repo/sql/db"
+	"github.com/urandom/readeef/log"
+)
+
+type feedHealthRepo struct {
+	db *db.DB
+
+	log log.Log
+}
+
+type feedFetchesArgs struct {
+	FeedID content.FeedID `db:"feed_id"`
+	Limit  int            `db:"limit"`
+}
+
+func (r feedHealthRepo) Get(feed content.Feed) (content.FeedHealth, error) {
+	if err := feed.Validate(); err != nil {
+		return content.FeedHealth{}, errors.WithMessage(err, "validating feed")
+	}
+
+	r.log.Infof("Getting health of feed %s", feed)
+
+	health, err := r.get(feed.ID, nil)
+	if err != nil {
+		return content.FeedHealth{}, errors.Wrapf(err, "getting health of feed %s", feed)
+	}
+
+	return health, nil
+}
+
+// History returns up to limit of the latest fetches of the feed, starting
+// with the newest one.
+func (r feedHealthRepo) History(feed content.Feed, limit int) ([]content.FeedFetch, error) {
+	if err := feed.Validate(); err != nil {
+		return nil, errors.WithMessage(err, "validating feed")
+	}
+
+	r.log.Infof("Getting fetch history of feed %s", feed)
+
+	var fetches []content.FeedFetch
+	if err := r.db.WithNamedStmt(r.db.SQL().FeedHealth.History, nil, func(stmt *sqlx.NamedStmt) error {
+		return stmt.Select(&fetches, feedFetchesArgs{FeedID: feed.ID, Limit: limit})
+	}); err != nil {
+		return nil, errors.Wrapf(err, "getting fetch history of feed %s", feed)
+	}
+
+	return fetches, nil
+}
+
+// Failing returns the health of the feeds whose last fetch has failed,
+// starting with the ones that have been failing the longest.
+func (r feedHealthRepo) Failing() ([]content.FeedHealth, error) {
+	r.log.Infoln("Getting the health of failing feeds")
+
+	var health []content.FeedHealth
+	if err := r.db.WithStmt(r.db.SQL().FeedHealth.Failing, nil, func(stmt *sqlx.Stmt) error {
+		return stmt.Select(&health)
+	}); err != nil {
+		return nil, errors.Wrap(err, "getting the health of failing feeds")
+	}
+
+	return health, nil
+}
+
+// Record stores the fetch in the feed's history, and returns the updated
+// health of the feed. Only the latest content.FeedFetchHistorySize fetches
+// are kept.
+func (r feedHealthRepo) Record(fetch content.FeedFetch) (content.FeedHealth, error) {
+	if err := fetch.Validate(); err != nil {
+		return content.FeedHealth{}, errors.WithMessage(err, "validating feed fetch")
+	}
+
+	r.log.Infof("Recording %s", fetch)
+
+	var health content.FeedHealth
+	if err := r.db.WithTx(func(tx *sqlx.Tx) error {
+		s := r.db.SQL()
+
+		current, err := r.get(fetch.FeedID, tx)
+		if err != nil && err != content.ErrNoContent {
+			return err
+		}
+
+		health = current.Record(fetch)
+
+		if err := r.db.WithNamedStmt(s.FeedHealth.Update, tx, func(stmt *sqlx.NamedStmt) error {
+			res, err := stmt.Exec(health)
+			if err != nil {
+				return errors.Wrap(err, "executing feed health update stmt")
+			}
+
+			if num, err := res.RowsAffected(); err == nil && num > 0 {
+				return nil
+			}
+
+			return r.db.WithNamedStmt(s.FeedHealth.Create, tx, func(stmt *sqlx.NamedStmt) error {
+				if _, err := stmt.Exec(health); err != nil {
+					return errors.Wrap(err, "executing feed health create stmt")
+				}
+
+				return nil
+			})
+		}); err != nil {
+			return err
+		}
+
+		if err := r.db.WithNamedStmt(s.FeedHealth.CreateFetch, tx, func(stmt *sqlx.NamedStmt) error {
+			if _, err := stmt.Exec(fetch); err != nil {
+				return errors.Wrap(err, "executing feed fetch create stmt")
+			}
+
+			return nil
+		}); err != nil {
+			return err
+		}
+
+		return r.db.WithNamedStmt(s.FeedHealth.PruneFetches, tx, func(stmt *sqlx.NamedStmt) error {
+			args := feedFetchesArgs{FeedID: fetch.FeedID, Limit: content.FeedFetchHistorySize}
+			if _, err := stmt.Exec(args); err != nil {
+				return errors.Wrap(err, "executing feed fetch prune stmt")
+			}
+
+			return nil
+		})
+	}); err != nil {
+		return content.FeedHealth{}, errors.WithMessage(err, "recording feed fetch")
+	}
+
+	return health, nil
+}
+
+func (r feedHealthRepo) get(id content.FeedID, tx *sqlx.Tx) (content.FeedHealth, error) {
+	health := content.FeedHealth{FeedID: id}
+	if err := r.db.WithNamedStmt(r.db.SQL().FeedHealth.Get, tx, func(stmt *sqlx.NamedStmt) error {
+		return stmt.Get(&health, health)
+	}); err != nil {
+		if err == sql.ErrNoRows {
+			return content.FeedHealth{}, content.ErrNoContent
+		}
+
+		return content.FeedHealth{}, err
+	}
+
+	return health, nil
+}
@@ -0,0 +1,68 @@
+package sql
+
+import (
+	"database/sql"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+	"github.com/urandom/readeef/content"
+	"github.com/urandom/readeef/content/repo/sql/db"
+	"github.com/urandom/readeef/log"
+)
+
+type feedImageRepo struct {
+	db *db.DB
+
+	log log.Log
+}
+
+func (r feedImageRepo) Get(feed content.Feed) (content.FeedImage, error) {
+	if err := feed.Validate(); err != nil {
+		return content.FeedImage{}, errors.WithMessage(err, "validating feed")
+	}
+
+	r.log.Infof("Getting image for feed %s", feed)
+
+	image := content.FeedImage{FeedID: feed.ID}
+	if err := r.db.WithNamedStmt(r.db.SQL().FeedImage.Get, nil, func(stmt *sqlx.NamedStmt) error {
+		return stmt.Get(&image, image)
+	}); err != nil {
+		if err == sql.ErrNoRows {
+			err = content.ErrNoContent
+		}
+
+		return content.FeedImage{}, errors.Wrapf(err, "getting image for feed %s", feed)
+	}
+
+	return image, nil
+}
+
+func (r feedImageRepo) Update(image content.FeedImage) error {
+	if err := image.Validate(); err != nil {
+		return errors.WithMessage(err, "validating feed image")
+	}
+
+	r.log.Infof("Updating feed image %s", image)
+
+	return r.db.WithTx(func(tx *sqlx.Tx) error {
+		s := r.db.SQL()
+		return r.db.WithNamedStmt(s.FeedImage.Update, tx, func(stmt *sqlx.NamedStmt) error {
+			res, err := stmt.Exec(image)
+			if err != nil {
+				return errors.Wrap(err, "executing feed image update stmt")
+			}
+
+			if num, err := res.RowsAffected(); err == nil && num > 0 {
+				return nil
+			}
+
+			return r.db.WithNamedStmt(s.FeedImage.Create, tx, func(stmt *sqlx.NamedStmt) error {
+				if _, err := stmt.Exec(image); err != nil {
+					return errors.Wrap(err, "executing feed image create stmt")
+				}
+
+				return nil
+			})
+		})
+	})
+}
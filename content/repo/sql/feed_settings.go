@@ -0,0 +1,69 @@
+package sql
+
+import (
+	"database/sql"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+	"github.com/urandom/readeef/content"
+	"github.com/urandom/readeef/content/repo/sql/db"
+	"github.com/urandom/readeef/log"
+)
+
+type feedSettingsRepo struct {
+	db *db.DB
+
+	log log.Log
+}
+
+func (r feedSettingsRepo) Get(feed content.Feed) (content.FeedSettings, error) {
+	if err := feed.Validate(); err != nil {
+		return content.FeedSettings{}, errors.WithMessage(err, "validating feed")
+	}
+
+	r.log.Infof("Getting settings of feed %s", feed)
+
+	settings := content.FeedSettings{FeedID: feed.ID}
+	if err := r.db.WithNamedStmt(r.db.SQL().FeedSettings.Get, nil, func(stmt *sqlx.NamedStmt) error {
+		return stmt.Get(&settings, settings)
+	}); err != nil {
+		if err == sql.ErrNoRows {
+			err = content.ErrNoContent
+		}
+
+		return content.FeedSettings{}, errors.Wrapf(err, "getting settings of feed %s", feed)
+	}
+
+	return settings, nil
+}
+
+func (r feedSettingsRepo) Update(settings content.FeedSettings) error {
+	if err := settings.Validate(); err != nil {
+		return errors.WithMessage(err, "validating feed settings")
+	}
+
+	r.log.Infof("Updating %s", settings)
+
+	return r.db.WithTx(func(tx *sqlx.Tx) error {
+		s := r.db.SQL()
+
+		return r.db.WithNamedStmt(s.FeedSettings.Update, tx, func(stmt *sqlx.NamedStmt) error {
+			res, err := stmt.Exec(settings)
+			if err != nil {
+				return errors.Wrap(err, "executing feed settings update stmt")
+			}
+
+			if num, err := res.RowsAffected(); err == nil && num > 0 {
+				return nil
+			}
+
+			return r.db.WithNamedStmt(s.FeedSettings.Create, tx, func(stmt *sqlx.NamedStmt) error {
+				if _, err := stmt.Exec(settings); err != nil {
+					return errors.Wrap(err, "executing feed settings create stmt")
+				}
+
+				return nil
+			})
+		})
+	})
+}
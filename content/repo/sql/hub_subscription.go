@@ -0,0 +1,113 @@
+package sql
+
+import (
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+	"github.com/urandom/readeef/content"
+	"github.com/urandom/readeef/content/repo/sql/db"
+	"github.com/urandom/readeef/log"
+)
+
+type hubSubscriptionRepo struct {
+	db *db.DB
+
+	log log.Log
+}
+
+type hubSubscriptionExpiryArgs struct {
+	LeaseExpiry time.Time `db:"lease_expiry"`
+}
+
+func (r hubSubscriptionRepo) All() ([]content.HubSubscription, error) {
+	r.log.Infoln("Getting all hub subscriptions")
+
+	var subscriptions []content.HubSubscription
+	if err := r.db.WithStmt(r.db.SQL().HubSubscription.All, nil, func(stmt *sqlx.Stmt) error {
+		return stmt.Select(&subscriptions)
+	}); err != nil {
+		return []content.HubSubscription{}, errors.Wrap(err, "getting hub subscriptions")
+	}
+
+	return subscriptions, nil
+}
+
+func (r hubSubscriptionRepo) ForTopic(topic string) ([]content.HubSubscription, error) {
+	r.log.Infof("Getting hub subscriptions for topic %s", topic)
+
+	var subscriptions []content.HubSubscription
+	if err := r.db.WithNamedStmt(r.db.SQL().HubSubscription.ForTopic, nil, func(stmt *sqlx.NamedStmt) error {
+		return stmt.Select(&subscriptions, content.HubSubscription{Topic: topic})
+	}); err != nil {
+		return []content.HubSubscription{}, errors.Wrapf(err, "getting hub subscriptions for topic %s", topic)
+	}
+
+	return subscriptions, nil
+}
+
+// Update creates the subscription of the callback to the topic, or renews its
+// secret and lease.
+func (r hubSubscriptionRepo) Update(subscription content.HubSubscription) error {
+	if err := subscription.Validate(); err != nil {
+		return errors.WithMessage(err, "validating hub subscription")
+	}
+
+	r.log.Infof("Updating hub subscription %s", subscription)
+
+	return r.db.WithTx(func(tx *sqlx.Tx) error {
+		s := r.db.SQL()
+
+		return r.db.WithNamedStmt(s.HubSubscription.Update, tx, func(stmt *sqlx.NamedStmt) error {
+			res, err := stmt.Exec(subscription)
+			if err != nil {
+				return errors.Wrap(err, "executing hub subscription update stmt")
+			}
+
+			if num, err := res.RowsAffected(); err == nil && num > 0 {
+				return nil
+			}
+
+			return r.db.WithNamedStmt(s.HubSubscription.Create, tx, func(stmt *sqlx.NamedStmt) error {
+				if _, err := stmt.Exec(subscription); err != nil {
+					return errors.Wrap(err, "executing hub subscription create stmt")
+				}
+
+				return nil
+			})
+		})
+	})
+}
+
+func (r hubSubscriptionRepo) Delete(subscription content.HubSubscription) error {
+	if err := subscription.Validate(); err != nil {
+		return errors.WithMessage(err, "validating hub subscription")
+	}
+
+	r.log.Infof("Deleting hub subscription %s", subscription)
+
+	return r.db.WithTx(func(tx *sqlx.Tx) error {
+		return r.db.WithNamedStmt(r.db.SQL().HubSubscription.Delete, tx, func(stmt *sqlx.NamedStmt) error {
+			if _, err := stmt.Exec(subscription); err != nil {
+				return errors.Wrap(err, "executing hub subscription delete stmt")
+			}
+
+			return nil
+		})
+	})
+}
+
+// DeleteExpired removes the subscriptions whose lease has expired by the
+// given time.
+func (r hubSubscriptionRepo) DeleteExpired(t time.Time) error {
+	r.log.Infof("Deleting hub subscriptions expired by %s", t)
+
+	if err := r.db.WithNamedStmt(r.db.SQL().HubSubscription.DeleteExpired, nil, func(stmt *sqlx.NamedStmt) error {
+		_, err := stmt.Exec(hubSubscriptionExpiryArgs{t})
+		return err
+	}); err != nil {
+		return errors.Wrap(err, "deleting expired hub subscriptions")
+	}
+
+	return nil
+}
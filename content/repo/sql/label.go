@@ -0,0 +1,154 @@
+package sql
+
+import (
+	"database/sql"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+	"github.com/urandom/readeef/content"
+	"github.com/urandom/readeef/content/repo/sql/db"
+	"github.com/urandom/readeef/log"
+)
+
+type labelRepo struct {
+	db *db.DB
+
+	log log.Log
+}
+
+type labelArticleArgs struct {
+	ID        content.LabelID   `db:"id"`
+	UserLogin content.Login     `db:"user_login"`
+	ArticleID content.ArticleID `db:"article_id"`
+}
+
+func (r labelRepo) Get(id content.LabelID, user content.User) (content.Label, error) {
+	if err := user.Validate(); err != nil {
+		return content.Label{}, errors.WithMessage(err, "validating user")
+	}
+
+	r.log.Infof("Getting label %d for %s", id, user)
+
+	label := content.Label{ID: id, UserLogin: user.Login}
+	if err := r.db.WithNamedStmt(r.db.SQL().Label.Get, nil, func(stmt *sqlx.NamedStmt) error {
+		return stmt.Get(&label, label)
+	}); err != nil {
+		if err == sql.ErrNoRows {
+			err = content.ErrNoContent
+		}
+
+		return content.Label{}, errors.Wrapf(err, "getting label %d", id)
+	}
+
+	return label, nil
+}
+
+func (r labelRepo) ForUser(user content.User) ([]content.Label, error) {
+	if err := user.Validate(); err != nil {
+		return []content.Label{}, errors.WithMessage(err, "validating user")
+	}
+
+	r.log.Infof("Getting labels for %s", user)
+
+	var labels []content.Label
+	if err := r.db.WithNamedStmt(r.db.SQL().Label.AllForUser, nil, func(stmt *sqlx.NamedStmt) error {
+		return stmt.Select(&labels, content.Label{UserLogin: user.Login})
+	}); err != nil {
+		return []content.Label{}, errors.Wrapf(err, "getting user %s labels", user)
+	}
+
+	return labels, nil
+}
+
+// Update creates a new label if it doesn't have an id, or renames the
+// existing one.
+func (r labelRepo) Update(label *content.Label) error {
+	if err := label.Validate(); err != nil {
+		return errors.WithMessage(err, "validating label")
+	}
+
+	r.log.Infof("Updating label %s", label)
+
+	return r.db.WithTx(func(tx *sqlx.Tx) error {
+		s := r.db.SQL()
+
+		if label.ID == 0 {
+			id, err := r.db.CreateWithID(tx, s.Label.Create, label)
+			if err != nil {
+				return errors.Wrap(err, "executing label create stmt")
+			}
+
+			label.ID = content.LabelID(id)
+
+			return nil
+		}
+
+		return r.db.WithNamedStmt(s.Label.Update, tx, func(stmt *sqlx.NamedStmt) error {
+			res, err := stmt.Exec(label)
+			if err != nil {
+				return errors.Wrap(err, "executing label update stmt")
+			}
+
+			if num, err := res.RowsAffected(); err == nil && num == 0 {
+				return errors.Wrapf(content.ErrNoContent, "updating label %s", label)
+			}
+
+			return nil
+		})
+	})
+}
+
+func (r labelRepo) Delete(label content.Label) error {
+	if label.ID == 0 || label.UserLogin == "" {
+		return content.NewValidationError(errors.New("Label has no id or user"))
+	}
+
+	r.log.Infof("Deleting label %s", label)
+
+	return r.db.WithNamedStmt(r.db.SQL().Label.Delete, nil, func(stmt *sqlx.NamedStmt) error {
+		if _, err := stmt.Exec(label); err != nil {
+			return errors.Wrap(err, "executing label delete stmt")
+		}
+
+		return nil
+	})
+}
+
+// Assign marks the articles with the label. Articles from feeds the label's
+// user isn't subscribed to are ignored.
+func (r labelRepo) Assign(label content.Label, ids []content.ArticleID) error {
+	r.log.Infof("Assigning label %s to %d articles", label, len(ids))
+
+	return r.setArticles(label, ids, r.db.SQL().Label.Assign)
+}
+
+// Unassign removes the label from the articles.
+func (r labelRepo) Unassign(label content.Label, ids []content.ArticleID) error {
+	r.log.Infof("Unassigning label %s from %d articles", label, len(ids))
+
+	return r.setArticles(label, ids, r.db.SQL().Label.Unassign)
+}
+
+func (r labelRepo) setArticles(label content.Label, ids []content.ArticleID, query string) error {
+	if label.ID == 0 || label.UserLogin == "" {
+		return content.NewValidationError(errors.New("Label has no id or user"))
+	}
+
+	if len(ids) == 0 {
+		return nil
+	}
+
+	return r.db.WithTx(func(tx *sqlx.Tx) error {
+		return r.db.WithNamedStmt(query, tx, func(stmt *sqlx.NamedStmt) error {
+			args := labelArticleArgs{ID: label.ID, UserLogin: label.UserLogin}
+			for _, id := range ids {
+				args.ArticleID = id
+				if _, err := stmt.Exec(args); err != nil {
+					return errors.Wrapf(err, "executing label article stmt for article %d", id)
+				}
+			}
+
+			return nil
+		})
+	})
+}
@@ -0,0 +1,167 @@
+package sql
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+	"github.com/urandom/readeef/content"
+	"github.com/urandom/readeef/content/repo/sql/db"
+	"github.com/urandom/readeef/log"
+)
+
+type publicationRepo struct {
+	db *db.DB
+
+	log log.Log
+}
+
+type publicationsArgs struct {
+	UserLogin content.Login `db:"user_login"`
+	Limit     int           `db:"limit"`
+	Offset    int           `db:"offset"`
+}
+
+type publicationArticleArgs struct {
+	UserLogin content.Login     `db:"user_login"`
+	ArticleID content.ArticleID `db:"article_id"`
+}
+
+func (r publicationRepo) Get(id content.PublicationID, user content.User) (content.Publication, error) {
+	if err := user.Validate(); err != nil {
+		return content.Publication{}, errors.WithMessage(err, "validating user")
+	}
+
+	r.log.Infof("Getting publication %d for %s", id, user)
+
+	publication := content.Publication{ID: id, UserLogin: user.Login}
+	if err := r.db.WithNamedStmt(r.db.SQL().Publication.Get, nil, func(stmt *sqlx.NamedStmt) error {
+		return stmt.Get(&publication, publication)
+	}); err != nil {
+		if err == sql.ErrNoRows {
+			err = content.ErrNoContent
+		}
+
+		return content.Publication{}, errors.Wrapf(err, "getting publication %d", id)
+	}
+
+	return publication, nil
+}
+
+// ForUser returns the user's publications, newest first.
+func (r publicationRepo) ForUser(user content.User, limit, offset int) ([]content.Publication, error) {
+	if err := user.Validate(); err != nil {
+		return []content.Publication{}, errors.WithMessage(err, "validating user")
+	}
+
+	r.log.Infof("Getting publications for %s", user)
+
+	var publications []content.Publication
+	if err := r.db.WithNamedStmt(r.db.SQL().Publication.AllForUser, nil, func(stmt *sqlx.NamedStmt) error {
+		return stmt.Select(&publications, publicationsArgs{UserLogin: user.Login, Limit: limit, Offset: offset})
+	}); err != nil {
+		return []content.Publication{}, errors.Wrapf(err, "getting user %s publications", user)
+	}
+
+	return publications, nil
+}
+
+// Update shares a new link if the publication doesn't have an id, or
+// changes the existing one.
+func (r publicationRepo) Update(publication *content.Publication) error {
+	if err := publication.Validate(); err != nil {
+		return errors.WithMessage(err, "validating publication")
+	}
+
+	r.log.Infof("Updating publication %s", publication)
+
+	return r.db.WithTx(func(tx *sqlx.Tx) error {
+		s := r.db.SQL()
+
+		if publication.ID == 0 {
+			publication.ArticleID = 0
+			if publication.Date.IsZero() {
+				publication.Date = time.Now()
+			}
+
+			id, err := r.db.CreateWithID(tx, s.Publication.Create, publication)
+			if err != nil {
+				return errors.Wrap(err, "executing publication create stmt")
+			}
+
+			publication.ID = content.PublicationID(id)
+
+			return nil
+		}
+
+		return r.db.WithNamedStmt(s.Publication.Update, tx, func(stmt *sqlx.NamedStmt) error {
+			res, err := stmt.Exec(publication)
+			if err != nil {
+				return errors.Wrap(err, "executing publication update stmt")
+			}
+
+			if num, err := res.RowsAffected(); err == nil && num == 0 {
+				return errors.Wrapf(content.ErrNoContent, "updating publication %s", publication)
+			}
+
+			return nil
+		})
+	})
+}
+
+func (r publicationRepo) Delete(publication content.Publication) error {
+	if publication.ID == 0 || publication.UserLogin == "" {
+		return content.NewValidationError(errors.New("Publication has no id or user"))
+	}
+
+	r.log.Infof("Deleting publication %s", publication)
+
+	return r.db.WithNamedStmt(r.db.SQL().Publication.Delete, nil, func(stmt *sqlx.NamedStmt) error {
+		if _, err := stmt.Exec(publication); err != nil {
+			return errors.Wrap(err, "executing publication delete stmt")
+		}
+
+		return nil
+	})
+}
+
+// Publish adds copies of the articles to the user's publications. Articles
+// from feeds the user isn't subscribed to, and ones that are already
+// published, are ignored.
+func (r publicationRepo) Publish(user content.User, ids []content.ArticleID) error {
+	r.log.Infof("Publishing %d articles for %s", len(ids), user)
+
+	return r.setArticles(user, ids, r.db.SQL().Publication.Publish)
+}
+
+// Unpublish removes the articles from the user's publications.
+func (r publicationRepo) Unpublish(user content.User, ids []content.ArticleID) error {
+	r.log.Infof("Unpublishing %d articles for %s", len(ids), user)
+
+	return r.setArticles(user, ids, r.db.SQL().Publication.Unpublish)
+}
+
+func (r publicationRepo) setArticles(user content.User, ids []content.ArticleID, query string) error {
+	if err := user.Validate(); err != nil {
+		return errors.WithMessage(err, "validating user")
+	}
+
+	if len(ids) == 0 {
+		return nil
+	}
+
+	return r.db.WithTx(func(tx *sqlx.Tx) error {
+		return r.db.WithNamedStmt(query, tx, func(stmt *sqlx.NamedStmt) error {
+			args := publicationArticleArgs{UserLogin: user.Login}
+			for _, id := range ids {
+				args.ArticleID = id
+				if _, err := stmt.Exec(args); err != nil {
+					return errors.Wrapf(err, "executing publication article stmt for article %d", id)
+				}
+			}
+
+			return nil
+		})
+	})
+}
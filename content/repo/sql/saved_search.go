@@ -0,0 +1,109 @@
+package sql
+
+import (
+	"database/sql"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+	"github.com/urandom/readeef/content"
+	"github.com/urandom/readeef/content/repo/sql/db"
+	"github.com/urandom/readeef/log"
+)
+
+type savedSearchRepo struct {
+	db *db.DB
+
+	log log.Log
+}
+
+func (r savedSearchRepo) Get(id content.SavedSearchID, user content.User) (content.SavedSearch, error) {
+	if err := user.Validate(); err != nil {
+		return content.SavedSearch{}, errors.WithMessage(err, "validating user")
+	}
+
+	r.log.Infof("Getting saved search %d for %s", id, user)
+
+	search := content.SavedSearch{ID: id, UserLogin: user.Login}
+	if err := r.db.WithNamedStmt(r.db.SQL().SavedSearch.Get, nil, func(stmt *sqlx.NamedStmt) error {
+		return stmt.Get(&search, search)
+	}); err != nil {
+		if err == sql.ErrNoRows {
+			err = content.ErrNoContent
+		}
+
+		return content.SavedSearch{}, errors.Wrapf(err, "getting saved search %d", id)
+	}
+
+	return search, nil
+}
+
+func (r savedSearchRepo) ForUser(user content.User) ([]content.SavedSearch, error) {
+	if err := user.Validate(); err != nil {
+		return []content.SavedSearch{}, errors.WithMessage(err, "validating user")
+	}
+
+	r.log.Infof("Getting saved searches for %s", user)
+
+	var searches []content.SavedSearch
+	if err := r.db.WithNamedStmt(r.db.SQL().SavedSearch.AllForUser, nil, func(stmt *sqlx.NamedStmt) error {
+		return stmt.Select(&searches, content.SavedSearch{UserLogin: user.Login})
+	}); err != nil {
+		return []content.SavedSearch{}, errors.Wrapf(err, "getting user %s saved searches", user)
+	}
+
+	return searches, nil
+}
+
+// Update creates a new saved search if it doesn't have an id, or changes
+// the existing one.
+func (r savedSearchRepo) Update(search *content.SavedSearch) error {
+	if err := search.Validate(); err != nil {
+		return errors.WithMessage(err, "validating saved search")
+	}
+
+	r.log.Infof("Updating saved search %s", search)
+
+	return r.db.WithTx(func(tx *sqlx.Tx) error {
+		s := r.db.SQL()
+
+		if search.ID == 0 {
+			id, err := r.db.CreateWithID(tx, s.SavedSearch.Create, search)
+			if err != nil {
+				return errors.Wrap(err, "executing saved search create stmt")
+			}
+
+			search.ID = content.SavedSearchID(id)
+
+			return nil
+		}
+
+		return r.db.WithNamedStmt(s.SavedSearch.Update, tx, func(stmt *sqlx.NamedStmt) error {
+			res, err := stmt.Exec(search)
+			if err != nil {
+				return errors.Wrap(err, "executing saved search update stmt")
+			}
+
+			if num, err := res.RowsAffected(); err == nil && num == 0 {
+				return errors.Wrapf(content.ErrNoContent, "updating saved search %s", search)
+			}
+
+			return nil
+		})
+	})
+}
+
+func (r savedSearchRepo) Delete(search content.SavedSearch) error {
+	if search.ID == 0 || search.UserLogin == "" {
+		return content.NewValidationError(errors.New("Saved search has no id or user"))
+	}
+
+	r.log.Infof("Deleting saved search %s", search)
+
+	return r.db.WithNamedStmt(r.db.SQL().SavedSearch.Delete, nil, func(stmt *sqlx.NamedStmt) error {
+		if _, err := stmt.Exec(search); err != nil {
+			return errors.Wrap(err, "executing saved search delete stmt")
+		}
+
+		return nil
+	})
+}
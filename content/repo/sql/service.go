@@ -13,11 +13,19 @@ type Service struct {
 	user         repo.User
 	tag          repo.Tag
 	feed         repo.Feed
+	feedImage    repo.FeedImage
+	feedSettings repo.FeedSettings
+	feedHealth   repo.FeedHealth
 	subscription repo.Subscription
 	article      repo.Article
 	extract      repo.Extract
 	scores       repo.Scores
 	thumbnail    repo.Thumbnail
+	rule         repo.Rule
+	label        repo.Label
+	publication  repo.Publication
+	savedSearch  repo.SavedSearch
+	hub          repo.HubSubscription
 }
 
 func NewService(driver, source string, log log.Log) (Service, error) {
@@ -33,11 +41,19 @@ func NewService(driver, source string, log log.Log) (Service, error) {
 			user:         userRepo{db, log},
 			tag:          tagRepo{db, log},
 			feed:         feedRepo{db, log},
+			feedImage:    feedImageRepo{db, log},
+			feedSettings: feedSettingsRepo{db, log},
+			feedHealth:   feedHealthRepo{db, log},
 			subscription: subscriptionRepo{db, log},
 			article:      articleRepo{db, log},
 			extract:      extractRepo{db, log},
 			scores:       scoresRepo{db, log},
 			thumbnail:    thumbnailRepo{db, log},
+			rule:         ruleRepo{db, log},
+			label:        labelRepo{db, log},
+			publication:  publicationRepo{db, log},
+			savedSearch:  savedSearchRepo{db, log},
+			hub:          hubSubscriptionRepo{db, log},
 		}, nil
 	default:
 		panic(fmt.Sprintf("Cannot provide a repo for driver '%s'\n", driver))
@@ -56,6 +72,10 @@ func (s Service) FeedRepo() repo.Feed {
 	return s.feed
 }
 
+func (s Service) FeedImageRepo() repo.FeedImage {
+	return s.feedImage
+}
+
 func (s Service) SubscriptionRepo() repo.Subscription {
 	return s.subscription
 }
@@ -75,3 +95,31 @@ func (s Service) ScoresRepo() repo.Scores {
 func (s Service) ThumbnailRepo() repo.Thumbnail {
 	return s.thumbnail
 }
+
+func (s Service) RuleRepo() repo.Rule {
+	return s.rule
+}
+
+func (s Service) LabelRepo() repo.Label {
+	return s.label
+}
+
+func (s Service) PublicationRepo() repo.Publication {
+	return s.publication
+}
+
+func (s Service) SavedSearchRepo() repo.SavedSearch {
+	return s.savedSearch
+}
+
+func (s Service) HubSubscriptionRepo() repo.HubSubscription {
+	return s.hub
+}
+
+func (s Service) FeedSettingsRepo() repo.FeedSettings {
+	return s.feedSettings
+}
+
+func (s Service) FeedHealthRepo() repo.FeedHealth {
+	return s.feedHealth
+}
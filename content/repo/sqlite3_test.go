@@ -23,6 +23,8 @@ func TestMain(m *testing.M) {
 	db.Exec("DELETE FROM feed_images")
 	db.Exec("DELETE FROM feeds")
 	db.Exec("DELETE FROM hubbub_subscriptions")
+	db.Exec("DELETE FROM labels")
+	db.Exec("DELETE FROM rules")
 	db.Exec("DELETE FROM users")
 	db.Exec("DELETE FROM users_articles_states")
 	db.Exec("DELETE FROM users_feeds")
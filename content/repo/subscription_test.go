@@ -4,6 +4,7 @@ import (
 	"sort"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/pkg/errors"
 	"github.com/urandom/readeef/content"
@@ -100,6 +101,7 @@ func Test_subscriptionRepo_Update(t *testing.T) {
 		wantErr      bool
 	}{
 		{"valid", feed1, content.Subscription{FeedID: feed1.ID, Link: "http://sugr.org"}, false},
+		{"secret", feed1, content.Subscription{FeedID: feed1.ID, Link: "http://sugr.org", Secret: "secret", LastPush: time.Now().Truncate(time.Second)}, false},
 		{"invalid 1", content.Feed{}, content.Subscription{Link: "http://sugr.org"}, true},
 		{"invalid 2", content.Feed{}, content.Subscription{FeedID: feed1.ID}, true},
 	}
@@ -149,5 +151,7 @@ func subscriptionsEqual(a, b content.Subscription) bool {
 		a.Link == b.Link &&
 		a.LeaseDuration == b.LeaseDuration &&
 		a.SubscriptionFailure == b.SubscriptionFailure &&
-		a.VerificationTime.Equal(b.VerificationTime)
+		a.VerificationTime.Equal(b.VerificationTime) &&
+		a.Secret == b.Secret &&
+		a.LastPush.Equal(b.LastPush)
 }
@@ -0,0 +1,26 @@
+package content
+
+import (
+	"fmt"
+	"time"
+)
+
+// Retention describes how many articles of a feed are kept around. Articles
+// older than MaxAge, or beyond the newest MaxCount, may be purged. A zero
+// value disables the respective limit. The newest MinKeep articles are
+// always kept, so that duplicates can still be detected when the feed is
+// updated.
+type Retention struct {
+	MaxAge   time.Duration
+	MaxCount int
+	MinKeep  int
+}
+
+// Enabled reports whether any of the retention limits are set.
+func (r Retention) Enabled() bool {
+	return r.MaxAge > 0 || r.MaxCount > 0
+}
+
+func (r Retention) String() string {
+	return fmt.Sprintf("max age: %s, max count: %d, min keep: %d", r.MaxAge, r.MaxCount, r.MinKeep)
+}
@@ -0,0 +1,161 @@
+package retention
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/urandom/readeef/config"
+	"github.com/urandom/readeef/content"
+	"github.com/urandom/readeef/content/repo"
+	"github.com/urandom/readeef/content/search"
+	"github.com/urandom/readeef/log"
+)
+
+// Purger removes articles that fall outside of the configured retention
+// policy, along with their search index entries.
+type Purger struct {
+	retention content.Retention
+	feeds     map[string]content.Retention
+	interval  time.Duration
+
+	service  repo.Service
+	provider search.Provider
+	log      log.Log
+}
+
+// Result holds the articles of a feed that were, or in dry-run mode would
+// have been, purged.
+type Result struct {
+	Feed     content.Feed
+	Articles []content.ArticleID
+}
+
+func New(config config.Content, service repo.Service, provider search.Provider, log log.Log) Purger {
+	p := Purger{
+		retention: content.Retention{
+			MaxAge:   config.Retention.Converted.MaxAge,
+			MaxCount: config.Retention.MaxCount,
+			MinKeep:  config.Retention.MinKeep,
+		},
+		feeds:    map[string]content.Retention{},
+		interval: config.Retention.Converted.Interval,
+		service:  service,
+		provider: provider,
+		log:      log,
+	}
+
+	for _, f := range config.Retention.Feeds {
+		p.feeds[f.Link] = content.Retention{
+			MaxAge:   f.Converted.MaxAge,
+			MaxCount: f.MaxCount,
+			MinKeep:  p.retention.MinKeep,
+		}
+	}
+
+	return p
+}
+
+// Policy returns the retention policy for the given feed.
+func (p Purger) Policy(feed content.Feed) content.Retention {
+	if r, ok := p.feeds[feed.Link]; ok {
+		return r
+	}
+
+	return p.retention
+}
+
+// Enabled reports whether the global policy, or any of the feed overrides,
+// sets a retention limit.
+func (p Purger) Enabled() bool {
+	if p.retention.Enabled() {
+		return true
+	}
+
+	for _, r := range p.feeds {
+		if r.Enabled() {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Purge removes the articles of every feed that fall outside of the feed's
+// retention policy. In dry-run mode, the articles are only collected.
+func (p Purger) Purge(dryRun bool) ([]Result, error) {
+	feeds, err := p.service.FeedRepo().All()
+	if err != nil {
+		return nil, errors.WithMessage(err, "getting feeds")
+	}
+
+	articleRepo := p.service.ArticleRepo()
+
+	results := []Result{}
+	for _, feed := range feeds {
+		policy := p.Policy(feed)
+		if !policy.Enabled() {
+			continue
+		}
+
+		ids, err := articleRepo.Purgeable(feed, policy)
+		if err != nil {
+			return results, errors.WithMessage(err, fmt.Sprintf("getting purgeable articles of feed %s", feed))
+		}
+
+		if len(ids) == 0 {
+			continue
+		}
+
+		results = append(results, Result{Feed: feed, Articles: ids})
+
+		if dryRun {
+			continue
+		}
+
+		p.log.Infof("Purging %d articles of feed %s", len(ids), feed)
+
+		if err := articleRepo.Delete(ids); err != nil {
+			return results, errors.WithMessage(err, fmt.Sprintf("deleting articles of feed %s", feed))
+		}
+
+		if p.provider != nil {
+			articles := make([]content.Article, len(ids))
+			for i := range ids {
+				articles[i] = content.Article{ID: ids[i], FeedID: feed.ID}
+			}
+
+			if err := p.provider.BatchIndex(articles, search.BatchDelete); err != nil {
+				p.log.Printf("Error removing purged articles of feed %s from the search index: %+v", feed, err)
+			}
+		}
+	}
+
+	return results, nil
+}
+
+// Schedule periodically purges articles until the context is canceled.
+func (p Purger) Schedule(ctx context.Context) {
+	if !p.Enabled() {
+		p.log.Infoln("No article retention limits configured")
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(p.interval)
+		defer ticker.Stop()
+
+		for {
+			if _, err := p.Purge(false); err != nil {
+				p.log.Printf("Error purging articles: %+v", err)
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
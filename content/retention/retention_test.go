@@ -0,0 +1,109 @@
+package retention
+
+import (
+	"io/ioutil"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/urandom/readeef/config"
+	"github.com/urandom/readeef/content"
+	"github.com/urandom/readeef/content/repo/mock_repo"
+	"github.com/urandom/readeef/log"
+)
+
+const testConfigData = `
+[content.retention]
+	max-age = "720h"
+	max-count = 100
+
+[[content.retention.feed]]
+	link = "http://sugr.org/kept"
+`
+
+func testConfig(t *testing.T) config.Content {
+	f, err := ioutil.TempFile("", "readeef-retention")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(f.Name())
+
+	if _, err := f.WriteString(testConfigData); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	cfg, err := config.Read(f.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return cfg.Content
+}
+
+func testLog() log.Log {
+	cfg := config.Log{}
+	cfg.Converted.Writer = os.Stderr
+
+	return log.WithStd(cfg)
+}
+
+func TestPurger_Policy(t *testing.T) {
+	p := New(testConfig(t), nil, nil, testLog())
+
+	tests := []struct {
+		name string
+		feed content.Feed
+		want content.Retention
+	}{
+		{"global", content.Feed{Link: "http://sugr.org/other"}, content.Retention{MaxAge: 720 * time.Hour, MaxCount: 100, MinKeep: 50}},
+		{"override", content.Feed{Link: "http://sugr.org/kept"}, content.Retention{MinKeep: 50}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := p.Policy(tt.feed); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Purger.Policy() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if !p.Enabled() {
+		t.Errorf("Purger.Enabled() = false")
+	}
+}
+
+func TestPurger_Purge(t *testing.T) {
+	for _, dryRun := range []bool{true, false} {
+		ctrl := gomock.NewController(t)
+
+		service := mock_repo.NewMockService(ctrl)
+		feedRepo := mock_repo.NewMockFeed(ctrl)
+		articleRepo := mock_repo.NewMockArticle(ctrl)
+
+		global := content.Feed{ID: 1, Link: "http://sugr.org/other"}
+		kept := content.Feed{ID: 2, Link: "http://sugr.org/kept"}
+		ids := []content.ArticleID{4, 5}
+
+		service.EXPECT().FeedRepo().Return(feedRepo)
+		service.EXPECT().ArticleRepo().Return(articleRepo)
+		feedRepo.EXPECT().All().Return([]content.Feed{global, kept}, nil)
+		articleRepo.EXPECT().Purgeable(global, content.Retention{MaxAge: 720 * time.Hour, MaxCount: 100, MinKeep: 50}).Return(ids, nil)
+		if !dryRun {
+			articleRepo.EXPECT().Delete(ids).Return(nil)
+		}
+
+		p := New(testConfig(t), service, nil, testLog())
+		got, err := p.Purge(dryRun)
+		if err != nil {
+			t.Fatalf("Purger.Purge() error = %v", err)
+		}
+
+		if want := []Result{{Feed: global, Articles: ids}}; !reflect.DeepEqual(got, want) {
+			t.Errorf("Purger.Purge() = %v, want %v", got, want)
+		}
+
+		ctrl.Finish()
+	}
+}
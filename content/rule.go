@@ -0,0 +1,283 @@
+package content
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+type RuleID int64
+
+// RuleField is the part of an article a rule condition is evaluated against.
+type RuleField string
+
+// RuleMatch is the way a rule condition value is matched against a field.
+type RuleMatch string
+
+// RuleAction is an action performed on the articles a rule matches.
+type RuleAction string
+
+// RuleActions is the list of actions of a rule, stored as a comma separated
+// string.
+type RuleActions []RuleAction
+
+const (
+	RuleFieldTitle       RuleField = "title"
+	RuleFieldDescription RuleField = "description"
+	RuleFieldLink        RuleField = "link"
+	RuleFieldAuthor      RuleField = "author"
+	RuleFieldCategory    RuleField = "category"
+	RuleFieldFeed        RuleField = "feed"
+	RuleFieldTag         RuleField = "tag"
+
+	RuleMatchSubstring RuleMatch = "substring"
+	RuleMatchRegex     RuleMatch = "regex"
+	RuleMatchTerms     RuleMatch = "terms"
+
+	RuleActionRead     RuleAction = "read"
+	RuleActionFavorite RuleAction = "favorite"
+	RuleActionHide     RuleAction = "hide"
+)
+
+// Rule is a user-defined condition on incoming articles, along with the
+// actions to perform on the articles that satisfy it.
+type Rule struct {
+	ID        RuleID      `json:"id"`
+	UserLogin Login       `db:"user_login" json:"-"`
+	Name      string      `json:"name"`
+	Field     RuleField   `json:"field"`
+	Match     RuleMatch   `db:"match_type" json:"match"`
+	Value     string      `json:"value"`
+	Actions   RuleActions `json:"actions"`
+}
+
+// RuleMatcher evaluates the condition of a rule against articles.
+type RuleMatcher struct {
+	Rule
+
+	value  string
+	regexp *regexp.Regexp
+	terms  []ruleTerm
+}
+
+type ruleTerm struct {
+	value   string
+	negated bool
+}
+
+func (r Rule) Validate() error {
+	if r.UserLogin == "" {
+		return NewValidationError(errors.New("Rule has no user"))
+	}
+
+	switch r.Field {
+	case RuleFieldTitle, RuleFieldDescription, RuleFieldLink, RuleFieldAuthor,
+		RuleFieldCategory, RuleFieldFeed, RuleFieldTag:
+	default:
+		return NewValidationError(fmt.Errorf("Unknown rule field '%s'", r.Field))
+	}
+
+	if strings.TrimSpace(r.Value) == "" {
+		return NewValidationError(errors.New("Rule has no value"))
+	}
+
+	switch r.Match {
+	case RuleMatchSubstring:
+	case RuleMatchRegex:
+		if _, err := regexp.Compile(r.Value); err != nil {
+			return NewValidationError(fmt.Errorf("Invalid rule regular expression: %v", err))
+		}
+	case RuleMatchTerms:
+		if len(parseRuleTerms(r.Value)) == 0 {
+			return NewValidationError(errors.New("Rule has no terms"))
+		}
+	default:
+		return NewValidationError(fmt.Errorf("Unknown rule match type '%s'", r.Match))
+	}
+
+	if len(r.Actions) == 0 {
+		return NewValidationError(errors.New("Rule has no actions"))
+	}
+
+	for _, a := range r.Actions {
+		switch a {
+		case RuleActionRead, RuleActionFavorite, RuleActionHide:
+		default:
+			return NewValidationError(fmt.Errorf("Unknown rule action '%s'", a))
+		}
+	}
+
+	return nil
+}
+
+// Has reports whether the rule performs the given action.
+func (r Rule) Has(action RuleAction) bool {
+	for _, a := range r.Actions {
+		if a == action {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (r Rule) String() string {
+	return fmt.Sprintf("%d: %s %s %q", r.ID, r.Field, r.Match, r.Value)
+}
+
+// Matcher prepares the rule condition for evaluation.
+func (r Rule) Matcher() (RuleMatcher, error) {
+	if err := r.Validate(); err != nil {
+		return RuleMatcher{}, err
+	}
+
+	m := RuleMatcher{Rule: r, value: strings.ToLower(r.Value)}
+
+	switch r.Match {
+	case RuleMatchRegex:
+		m.regexp = regexp.MustCompile(r.Value)
+	case RuleMatchTerms:
+		m.terms = parseRuleTerms(m.value)
+	}
+
+	return m, nil
+}
+
+// Match reports whether the article satisfies the rule condition. The feed
+// and the user's tags for it are used for the respective rule fields.
+func (m RuleMatcher) Match(a Article, feed Feed, tags []Tag) bool {
+	var values []string
+
+	switch m.Field {
+	case RuleFieldTitle:
+		values = []string{a.Title}
+	case RuleFieldDescription:
+		values = []string{a.Description}
+	case RuleFieldLink:
+		values = []string{a.Link}
+	case RuleFieldAuthor:
+		values = []string{a.Author}
+	case RuleFieldCategory:
+		values = a.Categories
+	case RuleFieldFeed:
+		values = []string{feed.Title, feed.Link}
+	case RuleFieldTag:
+		values = make([]string, len(tags))
+		for i := range tags {
+			values[i] = string(tags[i].Value)
+		}
+	}
+
+	for _, v := range values {
+		if m.matchValue(v) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (m RuleMatcher) matchValue(v string) bool {
+	switch m.Rule.Match {
+	case RuleMatchSubstring:
+		return strings.Contains(strings.ToLower(v), m.value)
+	case RuleMatchRegex:
+		return m.regexp.MatchString(v)
+	case RuleMatchTerms:
+		v = strings.ToLower(v)
+		for _, t := range m.terms {
+			if strings.Contains(v, t.value) == t.negated {
+				return false
+			}
+		}
+
+		return true
+	}
+
+	return false
+}
+
+// parseRuleTerms splits a value into its whitespace separated terms.
+// Double-quoted phrases are kept as a single term, and terms prefixed with
+// a '-' must not be present. A field matches when it contains all of the
+// other terms as case-insensitive substrings. Unlike the queries of the
+// search providers, there are no boolean operators, field prefixes,
+// wildcards or stemming.
+func parseRuleTerms(input string) []ruleTerm {
+	var terms []ruleTerm
+
+	for input = strings.TrimSpace(input); input != ""; input = strings.TrimSpace(input) {
+		term := ruleTerm{}
+		if input[0] == '-' {
+			term.negated = true
+			input = input[1:]
+		}
+
+		var value string
+		if strings.HasPrefix(input, `"`) {
+			if end := strings.Index(input[1:], `"`); end != -1 {
+				value, input = input[1:end+1], input[end+2:]
+			} else {
+				value, input = input[1:], ""
+			}
+		} else if end := strings.IndexAny(input, " \t\n"); end != -1 {
+			value, input = input[:end], input[end:]
+		} else {
+			value, input = input, ""
+		}
+
+		if value = strings.ToLower(strings.TrimSpace(value)); value != "" {
+			term.value = value
+			terms = append(terms, term)
+		}
+	}
+
+	return terms
+}
+
+func (id *RuleID) Scan(src interface{}) error {
+	asInt, ok := src.(int64)
+	if !ok {
+		return fmt.Errorf("Scan source '%#v' (%T) was not of type int64 (RuleID)", src, src)
+	}
+
+	*id = RuleID(asInt)
+
+	return nil
+}
+
+func (id RuleID) Value() (driver.Value, error) {
+	return int64(id), nil
+}
+
+func (val *RuleActions) Scan(src interface{}) error {
+	var data string
+	switch t := src.(type) {
+	case string:
+		data = t
+	case []byte:
+		data = string(t)
+	default:
+		return fmt.Errorf("Scan source '%#v' (%T) was not of type string (RuleActions)", src, src)
+	}
+
+	*val = RuleActions{}
+	for _, a := range strings.Split(data, ",") {
+		if a != "" {
+			*val = append(*val, RuleAction(a))
+		}
+	}
+
+	return nil
+}
+
+func (val RuleActions) Value() (driver.Value, error) {
+	actions := make([]string, len(val))
+	for i := range val {
+		actions[i] = string(val[i])
+	}
+
+	return strings.Join(actions, ","), nil
+}
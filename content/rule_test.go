@@ -0,0 +1,114 @@
+package content_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/urandom/readeef/content"
+)
+
+func TestRule_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		rule    content.Rule
+		wantErr bool
+	}{
+		{"valid", content.Rule{UserLogin: "user1", Field: content.RuleFieldTitle, Match: content.RuleMatchSubstring, Value: "go", Actions: content.RuleActions{content.RuleActionRead}}, false},
+		{"no user", content.Rule{Field: content.RuleFieldTitle, Match: content.RuleMatchSubstring, Value: "go", Actions: content.RuleActions{content.RuleActionRead}}, true},
+		{"unknown field", content.Rule{UserLogin: "user1", Field: "body", Match: content.RuleMatchSubstring, Value: "go", Actions: content.RuleActions{content.RuleActionRead}}, true},
+		{"unknown match", content.Rule{UserLogin: "user1", Field: content.RuleFieldTitle, Match: "exact", Value: "go", Actions: content.RuleActions{content.RuleActionRead}}, true},
+		{"no value", content.Rule{UserLogin: "user1", Field: content.RuleFieldTitle, Match: content.RuleMatchSubstring, Value: " ", Actions: content.RuleActions{content.RuleActionRead}}, true},
+		{"invalid regex", content.Rule{UserLogin: "user1", Field: content.RuleFieldTitle, Match: content.RuleMatchRegex, Value: "go(", Actions: content.RuleActions{content.RuleActionRead}}, true},
+		{"empty terms", content.Rule{UserLogin: "user1", Field: content.RuleFieldTitle, Match: content.RuleMatchTerms, Value: `""`, Actions: content.RuleActions{content.RuleActionRead}}, true},
+		{"no actions", content.Rule{UserLogin: "user1", Field: content.RuleFieldTitle, Match: content.RuleMatchSubstring, Value: "go"}, true},
+		{"unknown action", content.Rule{UserLogin: "user1", Field: content.RuleFieldTitle, Match: content.RuleMatchSubstring, Value: "go", Actions: content.RuleActions{"delete"}}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.rule.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Rule.Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRuleMatcher_Match(t *testing.T) {
+	article := content.Article{
+		Title:       "Go 1.10 is released",
+		Description: "<p>The Go team is happy to announce</p>",
+		Link:        "https://blog.golang.org/go1.10",
+		Author:      "Andrew Gerrand",
+		Categories:  []string{"Release", "Go"},
+	}
+	feed := content.Feed{Title: "The Go Blog", Link: "https://blog.golang.org/feed.atom"}
+	tags := []content.Tag{{ID: 1, Value: "programming"}}
+
+	tests := []struct {
+		name  string
+		field content.RuleField
+		match content.RuleMatch
+		value string
+		want  bool
+	}{
+		{"title substring", content.RuleFieldTitle, content.RuleMatchSubstring, "RELEASED", true},
+		{"title substring mismatch", content.RuleFieldTitle, content.RuleMatchSubstring, "rust", false},
+		{"description substring", content.RuleFieldDescription, content.RuleMatchSubstring, "happy", true},
+		{"link regex", content.RuleFieldLink, content.RuleMatchRegex, `^https://blog\.golang\.org/`, true},
+		{"link regex case", content.RuleFieldLink, content.RuleMatchRegex, `^HTTPS`, false},
+		{"author substring", content.RuleFieldAuthor, content.RuleMatchSubstring, "gerrand", true},
+		{"category regex", content.RuleFieldCategory, content.RuleMatchRegex, `^Go$`, true},
+		{"feed title", content.RuleFieldFeed, content.RuleMatchSubstring, "go blog", true},
+		{"feed link", content.RuleFieldFeed, content.RuleMatchSubstring, "feed.atom", true},
+		{"tag", content.RuleFieldTag, content.RuleMatchSubstring, "programming", true},
+		{"tag mismatch", content.RuleFieldTag, content.RuleMatchSubstring, "news", false},
+		{"terms terms", content.RuleFieldTitle, content.RuleMatchTerms, "go released", true},
+		{"terms phrase", content.RuleFieldTitle, content.RuleMatchTerms, `"is released"`, true},
+		{"terms phrase mismatch", content.RuleFieldTitle, content.RuleMatchTerms, `"go released"`, false},
+		{"terms negated", content.RuleFieldTitle, content.RuleMatchTerms, "go -beta", true},
+		{"terms negated mismatch", content.RuleFieldTitle, content.RuleMatchTerms, "go -1.10", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule := content.Rule{
+				UserLogin: "user1", Field: tt.field, Match: tt.match, Value: tt.value,
+				Actions: content.RuleActions{content.RuleActionRead},
+			}
+
+			m, err := rule.Matcher()
+			if err != nil {
+				t.Fatalf("Rule.Matcher() error = %v", err)
+			}
+
+			if got := m.Match(article, feed, tags); got != tt.want {
+				t.Errorf("RuleMatcher.Match() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRuleActions_Scan(t *testing.T) {
+	tests := []struct {
+		name    string
+		src     interface{}
+		want    content.RuleActions
+		wantErr bool
+	}{
+		{"string", "read,hide", content.RuleActions{content.RuleActionRead, content.RuleActionHide}, false},
+		{"bytes", []byte("favorite"), content.RuleActions{content.RuleActionFavorite}, false},
+		{"empty", "", content.RuleActions{}, false},
+		{"invalid", 1, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got content.RuleActions
+			if err := got.Scan(tt.src); (err != nil) != tt.wantErr {
+				t.Errorf("RuleActions.Scan() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("RuleActions.Scan() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
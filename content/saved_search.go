@@ -0,0 +1,60 @@
+package content
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+type SavedSearchID int64
+
+// SavedSearch is a named search query, optionally limited to a feed or to
+// the feeds of a tag, whose results are shown like the articles of a feed.
+type SavedSearch struct {
+	ID        SavedSearchID `json:"id"`
+	UserLogin Login         `db:"user_login" json:"-"`
+	Name      string        `json:"name"`
+	Query     string        `json:"query"`
+	FeedID    FeedID        `db:"feed_id" json:"feedID,omitempty"`
+	TagID     TagID         `db:"tag_id" json:"tagID,omitempty"`
+}
+
+func (s SavedSearch) Validate() error {
+	if s.UserLogin == "" {
+		return NewValidationError(errors.New("Saved search has no user"))
+	}
+
+	if strings.TrimSpace(s.Name) == "" {
+		return NewValidationError(errors.New("Saved search has no name"))
+	}
+
+	if strings.TrimSpace(s.Query) == "" {
+		return NewValidationError(errors.New("Saved search has no query"))
+	}
+
+	if s.FeedID != 0 && s.TagID != 0 {
+		return NewValidationError(errors.New("Saved search is limited to both a feed and a tag"))
+	}
+
+	return nil
+}
+
+func (s SavedSearch) String() string {
+	return fmt.Sprintf("%d: %s", s.ID, s.Name)
+}
+
+func (id *SavedSearchID) Scan(src interface{}) error {
+	asInt, ok := src.(int64)
+	if !ok {
+		return fmt.Errorf("Scan source '%#v' (%T) was not of type int64 (SavedSearchID)", src, src)
+	}
+
+	*id = SavedSearchID(asInt)
+
+	return nil
+}
+
+func (id SavedSearchID) Value() (driver.Value, error) {
+	return int64(id), nil
+}
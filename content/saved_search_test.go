@@ -0,0 +1,30 @@
+package content_test
+
+import (
+	"testing"
+
+	"github.com/urandom/readeef/content"
+)
+
+func TestSavedSearch_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		search  content.SavedSearch
+		wantErr bool
+	}{
+		{"valid", content.SavedSearch{UserLogin: "user1", Name: "Go", Query: "golang"}, false},
+		{"feed", content.SavedSearch{UserLogin: "user1", Name: "Go", Query: "golang", FeedID: 1}, false},
+		{"tag", content.SavedSearch{UserLogin: "user1", Name: "Go", Query: "golang", TagID: 1}, false},
+		{"no user", content.SavedSearch{Name: "Go", Query: "golang"}, true},
+		{"no name", content.SavedSearch{UserLogin: "user1", Name: " ", Query: "golang"}, true},
+		{"no query", content.SavedSearch{UserLogin: "user1", Name: "Go"}, true},
+		{"feed and tag", content.SavedSearch{UserLogin: "user1", Name: "Go", Query: "golang", FeedID: 1, TagID: 1}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.search.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("SavedSearch.Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -34,6 +34,8 @@ type indexArticle struct {
 	Description string    `json:"description"`
 	Link        string    `json:"link"`
 	Date        time.Time `json:"date"`
+	Author      string    `json:"author"`
+	Categories  []string  `json:"categories"`
 }
 
 func NewBleve(path string, size int64, service repo.Service, log log.Log) (bleveSearch, error) {
@@ -127,6 +129,14 @@ func (b bleveSearch) Search(
 		conjunct = append(conjunct, q)
 	}
 
+	if len(o.Authors) > 0 {
+		conjunct = append(conjunct, bleveMatchAny("author", o.Authors))
+	}
+
+	if len(o.Categories) > 0 {
+		conjunct = append(conjunct, bleveMatchAny("categories", o.Categories))
+	}
+
 	if o.BeforeID > 0 || o.AfterID > 0 {
 		start := float64(o.AfterID)
 		end := float64(o.BeforeID)
@@ -196,6 +206,15 @@ func (b bleveSearch) Search(
 	if o.UnreadOnly {
 		queryOpts = append(queryOpts, content.UnreadOnly)
 	}
+	if len(o.Authors) > 0 {
+		queryOpts = append(queryOpts, content.Authors(o.Authors))
+	}
+	if len(o.Categories) > 0 {
+		queryOpts = append(queryOpts, content.Categories(o.Categories))
+	}
+	if o.IncludeCategories {
+		queryOpts = append(queryOpts, content.IncludeCategories)
+	}
 
 	articles, err := b.service.ArticleRepo().ForUser(u, queryOpts...)
 	if err != nil {
@@ -285,6 +304,20 @@ func (b bleveSearch) RemoveFeed(id content.FeedID) error {
 	return b.BatchIndex(articles, BatchDelete)
 }
 
+// bleveMatchAny returns a query matching any of the phrases in the given
+// field.
+func bleveMatchAny(field string, phrases []string) query.Query {
+	queries := make([]query.Query, len(phrases))
+	for i, p := range phrases {
+		q := query.NewMatchPhraseQuery(p)
+		q.SetField(field)
+
+		queries[i] = q
+	}
+
+	return query.NewDisjunctionQuery(queries)
+}
+
 func prepareArticle(article content.Article) (string, indexArticle) {
 	id := strconv.FormatInt(int64(article.ID), 10)
 	ia := indexArticle{
@@ -292,6 +325,8 @@ func prepareArticle(article content.Article) (string, indexArticle) {
 		ArticleID:   int64(article.ID),
 		Title:       html.UnescapeString(StripTags(article.Title)),
 		Description: html.UnescapeString(StripTags(article.Description)),
+		Author:      article.Author,
+		Categories:  article.Categories,
 		Link:        article.Link, Date: article.Date,
 	}
 
@@ -100,6 +100,14 @@ func (e elasticSearch) Search(
 		)
 	}
 
+	if len(o.Authors) > 0 {
+		filter = append(filter, elasticMatchAny("author", o.Authors))
+	}
+
+	if len(o.Categories) > 0 {
+		filter = append(filter, elasticMatchAny("categories", o.Categories))
+	}
+
 	if o.BeforeID > 0 || o.AfterID > 0 {
 		filter = append(
 			filter,
@@ -156,6 +164,15 @@ func (e elasticSearch) Search(
 	if o.UnreadOnly {
 		queryOpts = append(queryOpts, content.UnreadOnly)
 	}
+	if len(o.Authors) > 0 {
+		queryOpts = append(queryOpts, content.Authors(o.Authors))
+	}
+	if len(o.Categories) > 0 {
+		queryOpts = append(queryOpts, content.Categories(o.Categories))
+	}
+	if o.IncludeCategories {
+		queryOpts = append(queryOpts, content.IncludeCategories)
+	}
 
 	articles, err := e.service.ArticleRepo().ForUser(u, queryOpts...)
 	if err != nil {
@@ -177,6 +194,17 @@ func (e elasticSearch) Search(
 	return articles, nil
 }
 
+// elasticMatchAny returns a query matching any of the phrases in the given
+// field.
+func elasticMatchAny(field string, phrases []string) elastic.Query {
+	queries := make([]elastic.Query, len(phrases))
+	for i, p := range phrases {
+		queries[i] = elastic.NewMatchPhraseQuery(field, p)
+	}
+
+	return elastic.NewBoolQuery().Should(queries...)
+}
+
 func (e elasticSearch) BatchIndex(articles []content.Article, op indexOperation) error {
 	if len(articles) == 0 {
 		return nil
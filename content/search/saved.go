@@ -0,0 +1,101 @@
+package search
+
+import (
+	"github.com/pkg/errors"
+	"github.com/urandom/readeef/content"
+	"github.com/urandom/readeef/content/repo"
+)
+
+// The maximum number of results a saved search count considers, since the
+// providers can't count their matches.
+const savedSearchCountLimit = 1000
+
+// Searcher is the part of the Provider that performs queries.
+type Searcher interface {
+	Search(string, content.User, ...content.QueryOpt) ([]content.Article, error)
+}
+
+// SavedSearchFeedIDs returns the ids of the feeds the saved search is limited
+// to, or nil if it isn't limited. The ids are empty if the feed or tag the
+// search is limited to no longer exists.
+func SavedSearchFeedIDs(service repo.Service, s content.SavedSearch, user content.User) ([]content.FeedID, error) {
+	switch {
+	case s.FeedID != 0:
+		if _, err := service.FeedRepo().Get(s.FeedID, user); err != nil {
+			if content.IsNoContent(err) {
+				return []content.FeedID{}, nil
+			}
+
+			return nil, errors.WithMessage(err, "getting saved search feed")
+		}
+
+		return []content.FeedID{s.FeedID}, nil
+	case s.TagID != 0:
+		tag, err := service.TagRepo().Get(s.TagID, user)
+		if err != nil {
+			if content.IsNoContent(err) {
+				return []content.FeedID{}, nil
+			}
+
+			return nil, errors.WithMessage(err, "getting saved search tag")
+		}
+
+		ids, err := service.TagRepo().FeedIDs(tag, user)
+		if err != nil {
+			return nil, errors.WithMessage(err, "getting saved search tag feed ids")
+		}
+
+		if ids == nil {
+			ids = []content.FeedID{}
+		}
+
+		return ids, nil
+	}
+
+	return nil, nil
+}
+
+// SavedSearch performs the saved search query for its user, within the feeds
+// it is limited to.
+func SavedSearch(
+	searcher Searcher,
+	service repo.Service,
+	s content.SavedSearch,
+	user content.User,
+	opts ...content.QueryOpt,
+) ([]content.Article, error) {
+	ids, err := SavedSearchFeedIDs(service, s, user)
+	if err != nil {
+		return nil, err
+	}
+
+	if ids != nil {
+		if len(ids) == 0 {
+			return []content.Article{}, nil
+		}
+
+		opts = append(opts, content.FeedIDs(ids))
+	}
+
+	articles, err := searcher.Search(s.Query, user, opts...)
+	if err != nil {
+		return nil, errors.WithMessage(err, "searching for saved search "+s.String())
+	}
+
+	return articles, nil
+}
+
+// CountSavedSearch returns the number of articles the saved search finds,
+// up to a limit.
+func CountSavedSearch(
+	searcher Searcher,
+	service repo.Service,
+	s content.SavedSearch,
+	user content.User,
+	opts ...content.QueryOpt,
+) (int64, error) {
+	articles, err := SavedSearch(searcher, service, s, user,
+		append(opts, content.Paging(savedSearchCountLimit, 0))...)
+
+	return int64(len(articles)), err
+}
@@ -27,7 +27,7 @@ func Reindex(p Provider, repo repo.Article) error {
 	offset := 0
 
 	for {
-		articles, err := repo.All(content.Paging(limit, offset))
+		articles, err := repo.All(content.Paging(limit, offset), content.IncludeCategories)
 		if err != nil {
 			return errors.WithMessage(err, fmt.Sprintf(
 				"getting articles in window %d-%d", offset, offset+limit,
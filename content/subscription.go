@@ -13,8 +13,24 @@ type Subscription struct {
 	LeaseDuration       int64     `db:"lease_duration"`
 	VerificationTime    time.Time `db:"verification_time"`
 	SubscriptionFailure bool      `db:"subscription_failure"`
+	Secret              string    `db:"secret"`
+	LastPush            time.Time `db:"last_push"`
 }
 
+// SubscriptionState describes whether the hub of a subscription can be relied
+// upon for the feed updates.
+type SubscriptionState string
+
+const (
+	// The hub hasn't verified the subscription yet, or has denied it.
+	SubscriptionStateUnverified SubscriptionState = "unverified"
+	// The lease of the subscription has run out without a renewal.
+	SubscriptionStateExpired SubscriptionState = "expired"
+	// The hub hasn't pushed any content for too long.
+	SubscriptionStateSilent SubscriptionState = "silent"
+	SubscriptionStateActive SubscriptionState = "active"
+)
+
 func (s Subscription) Validate() error {
 	if s.FeedID == 0 {
 		return NewValidationError(errors.New("Invalid feed id"))
@@ -31,6 +47,36 @@ func (s Subscription) Validate() error {
 	return nil
 }
 
+// LeaseExpiry returns the time the lease of the verified subscription runs
+// out.
+func (s Subscription) LeaseExpiry() time.Time {
+	return s.VerificationTime.Add(time.Duration(s.LeaseDuration))
+}
+
+// State returns the state of the subscription, which is considered silent if
+// the hub hasn't been heard from during the given duration.
+func (s Subscription) State(silence time.Duration) SubscriptionState {
+	now := time.Now()
+
+	switch {
+	case s.SubscriptionFailure:
+		return SubscriptionStateUnverified
+	case s.LeaseDuration > 0 && !s.LeaseExpiry().After(now):
+		return SubscriptionStateExpired
+	}
+
+	heard := s.VerificationTime
+	if s.LastPush.After(heard) {
+		heard = s.LastPush
+	}
+
+	if silence > 0 && heard.Add(silence).Before(now) {
+		return SubscriptionStateSilent
+	}
+
+	return SubscriptionStateActive
+}
+
 func (s Subscription) String() string {
 	return fmt.Sprintf("%s: %d", s.Link, s.FeedID)
 }
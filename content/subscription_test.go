@@ -2,6 +2,7 @@ package content_test
 
 import (
 	"testing"
+	"time"
 
 	"github.com/urandom/readeef/content"
 )
@@ -34,3 +35,27 @@ func TestSubscription_Validate(t *testing.T) {
 		})
 	}
 }
+
+func TestSubscription_State(t *testing.T) {
+	now := time.Now()
+	lease := int64(24 * time.Hour)
+
+	tests := []struct {
+		name string
+		s    content.Subscription
+		want content.SubscriptionState
+	}{
+		{"unverified", content.Subscription{SubscriptionFailure: true, VerificationTime: now, LeaseDuration: lease}, content.SubscriptionStateUnverified},
+		{"expired", content.Subscription{VerificationTime: now.Add(-25 * time.Hour), LeaseDuration: lease}, content.SubscriptionStateExpired},
+		{"silent", content.Subscription{VerificationTime: now.Add(-20 * time.Hour), LeaseDuration: lease}, content.SubscriptionStateSilent},
+		{"recent push", content.Subscription{VerificationTime: now.Add(-20 * time.Hour), LastPush: now.Add(-time.Hour), LeaseDuration: lease}, content.SubscriptionStateActive},
+		{"no lease", content.Subscription{VerificationTime: now.Add(-time.Hour)}, content.SubscriptionStateActive},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.s.State(12 * time.Hour); got != tt.want {
+				t.Errorf("Subscription.State() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -2,6 +2,7 @@ package thumbnail
 
 import (
 	"fmt"
+	"net/http"
 	"strings"
 
 	"github.com/pkg/errors"
@@ -11,13 +12,14 @@ import (
 )
 
 type description struct {
-	repo  repo.Thumbnail
-	store bool
-	log   log.Log
+	repo   repo.Thumbnail
+	client *http.Client
+	store  bool
+	log    log.Log
 }
 
-func FromDescription(repo repo.Thumbnail, store bool, log log.Log) Generator {
-	return description{repo: repo, store: store, log: log}
+func FromDescription(repo repo.Thumbnail, client *http.Client, store bool, log log.Log) Generator {
+	return description{repo: repo, client: client, store: store, log: log}
 }
 
 func (t description) Generate(a content.Article) error {
@@ -26,7 +28,7 @@ func (t description) Generate(a content.Article) error {
 	t.log.Debugf("Generating thumbnail for article %s from description", a)
 
 	thumbnail.Thumbnail, thumbnail.Link =
-		generateThumbnailFromDescription(strings.NewReader(a.Description))
+		generateThumbnailFromDescription(t.client, strings.NewReader(a.Description))
 
 	if !t.store {
 		thumbnail.Thumbnail = ""
@@ -3,6 +3,7 @@ package thumbnail
 import (
 	"fmt"
 	_ "image/png"
+	"net/http"
 	"strings"
 
 	"github.com/pkg/errors"
@@ -18,6 +19,7 @@ type ext struct {
 	extractRepo repo.Extract
 	generator   extract.Generator
 	processors  []processor.Article
+	client      *http.Client
 	store       bool
 	log         log.Log
 }
@@ -27,6 +29,7 @@ func FromExtract(
 	extractRepo repo.Extract,
 	g extract.Generator,
 	processors []processor.Article,
+	client *http.Client,
 	store bool,
 	log log.Log,
 ) (Generator, error) {
@@ -36,7 +39,7 @@ func FromExtract(
 
 	processors = filterProcessors(processors)
 
-	return ext{repo: repo, extractRepo: extractRepo, generator: g, processors: processors, store: store, log: log}, nil
+	return ext{repo: repo, extractRepo: extractRepo, generator: g, processors: processors, client: client, store: store, log: log}, nil
 }
 
 func (t ext) Generate(a content.Article) error {
@@ -45,7 +48,7 @@ func (t ext) Generate(a content.Article) error {
 	t.log.Debugf("Generating thumbnail for article %s from extract", a)
 
 	thumbnail.Thumbnail, thumbnail.Link =
-		generateThumbnailFromDescription(strings.NewReader(a.Description))
+		generateThumbnailFromDescription(t.client, strings.NewReader(a.Description))
 
 	if thumbnail.Link == "" {
 		t.log.Debugf("%s description doesn't contain suitable link, getting extract\n", a)
@@ -60,7 +63,7 @@ func (t ext) Generate(a content.Article) error {
 		} else {
 			t.log.Debugf("Generating thumbnail from top image %s of %s\n", extract.TopImage, a)
 			if t.store {
-				thumbnail.Thumbnail = generateThumbnailFromImageLink(extract.TopImage)
+				thumbnail.Thumbnail = generateThumbnailFromImageLink(t.client, extract.TopImage)
 			}
 			thumbnail.Link = extract.TopImage
 		}
@@ -54,7 +54,7 @@ func generateThumbnail(r io.Reader) (b []byte, mimeType string, err error) {
 	return
 }
 
-func generateThumbnailFromDescription(description io.Reader) (string, string) {
+func generateThumbnailFromDescription(client *http.Client, description io.Reader) (string, string) {
 	var data, link string
 	if d, err := goquery.NewDocumentFromReader(description); err == nil {
 		d.Find("img").EachWithBreak(func(i int, s *goquery.Selection) bool {
@@ -64,7 +64,7 @@ func generateThumbnailFromDescription(description io.Reader) (string, string) {
 					return true
 				}
 
-				resp, err := http.Get(u.String())
+				resp, err := client.Get(u.String())
 				if err != nil {
 					return true
 				}
@@ -107,13 +107,13 @@ func generateThumbnailFromDescription(description io.Reader) (string, string) {
 	return data, link
 }
 
-func generateThumbnailFromImageLink(link string) (t string) {
+func generateThumbnailFromImageLink(client *http.Client, link string) (t string) {
 	u, err := url.Parse(link)
 	if err != nil || !u.IsAbs() {
 		return
 	}
 
-	resp, err := http.Get(u.String())
+	resp, err := client.Get(u.String())
 	if err != nil {
 		return
 	}
@@ -9,8 +9,13 @@ import (
 	"github.com/pkg/errors"
 )
 
-func Favicon(site string) ([]byte, string, error) {
-	doc, err := goquery.NewDocument(site)
+func Favicon(client *http.Client, site string) ([]byte, string, error) {
+	resp, err := client.Get(site)
+	if err != nil {
+		return nil, "", errors.Wrapf(err, "querying site: %q", site)
+	}
+
+	doc, err := goquery.NewDocumentFromResponse(resp)
 	if err != nil {
 		return nil, "", errors.Wrapf(err, "querying site: %q", site)
 	}
@@ -46,7 +51,7 @@ func Favicon(site string) ([]byte, string, error) {
 			}
 		}
 
-		resp, err := http.Get(iconURL.String())
+		resp, err := client.Get(iconURL.String())
 		if err != nil {
 			return nil, "", errors.Wrapf(err, "getting favicon %q", iconURL)
 		}
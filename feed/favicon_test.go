@@ -1,6 +1,7 @@
 package feed
 
 import (
+	"net/http"
 	"testing"
 )
 
@@ -18,7 +19,7 @@ func TestFavicon(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, ct, err := Favicon(tt.site)
+			got, ct, err := Favicon(http.DefaultClient, tt.site)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("Favicon() error = %v, wantErr %v", err, tt.wantErr)
 				return
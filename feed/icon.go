@@ -0,0 +1,152 @@
+package feed
+
+import (
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/urandom/readeef/content"
+	"github.com/urandom/readeef/content/repo"
+	"github.com/urandom/readeef/log"
+)
+
+// IconCache keeps feed images and icons in the feed image repository,
+// fetching them again once they become older than the refresh interval.
+type IconCache struct {
+	repo    repo.FeedImage
+	refresh time.Duration
+	client  *http.Client
+	log     log.Log
+}
+
+const maxIconSize = 1 << 20
+
+func NewIconCache(repo repo.FeedImage, refresh time.Duration, client *http.Client, log log.Log) IconCache {
+	return IconCache{
+		repo:    repo,
+		refresh: refresh,
+		client:  client,
+		log:     log,
+	}
+}
+
+// Get returns the cached image of the feed. The icon is fetched when it is
+// missing, stale, or when the feed advertises a different image.
+func (c IconCache) Get(feed content.Feed) (content.FeedImage, error) {
+	image, err := c.repo.Get(feed)
+	if err != nil && !content.IsNoContent(err) {
+		return content.FeedImage{}, errors.WithMessage(err, "getting cached feed image")
+	}
+
+	parsed := feed.ParsedImage()
+	changed := parsed.URL != "" && parsed.URL != image.URL
+
+	if !changed && !image.Stale(c.refresh) {
+		return image, nil
+	}
+
+	image.FeedID = feed.ID
+	if changed {
+		image.Title, image.URL = parsed.Title, parsed.URL
+		image.Width, image.Height = parsed.Width, parsed.Height
+	}
+
+	c.log.Infof("Fetching icon for feed %s", feed)
+
+	// Keep the previous icon if a new one couldn't be obtained
+	if icon, ct := c.fetchIcon(feed, image.URL); len(icon) > 0 {
+		image.Icon, image.IconType = icon, ct
+	}
+	image.FetchDate = time.Now()
+
+	if err := c.repo.Update(image); err != nil {
+		return content.FeedImage{}, errors.WithMessage(err, "updating feed image")
+	}
+
+	return image, nil
+}
+
+// fetchIcon tries the site favicon, then the feed image, and finally the
+// conventional /favicon.ico location.
+func (c IconCache) fetchIcon(feed content.Feed, imageURL string) ([]byte, string) {
+	site := feed.SiteLink
+	if site == "" {
+		site = origin(feed.Link)
+	}
+
+	if site != "" {
+		b, ct, err := Favicon(c.client, site)
+		if err != nil {
+			c.log.Debugf("Error getting favicon for %q: %v", site, err)
+		} else if ct, ok := imageType(ct, b); ok {
+			return b, ct
+		}
+	}
+
+	candidates := []string{imageURL}
+	if o := origin(site); o != "" {
+		candidates = append(candidates, o+"/favicon.ico")
+	}
+
+	for _, link := range candidates {
+		if link == "" {
+			continue
+		}
+
+		b, ct, err := c.fetchImage(link)
+		if err != nil {
+			c.log.Debugf("Error getting icon %q: %v", link, err)
+			continue
+		}
+
+		if ct, ok := imageType(ct, b); ok {
+			return b, ct
+		}
+	}
+
+	return nil, ""
+}
+
+func (c IconCache) fetchImage(link string) ([]byte, string, error) {
+	resp, err := c.client.Get(link)
+	if err != nil {
+		return nil, "", errors.Wrapf(err, "getting image %q", link)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", errors.Errorf("getting image %q: %s", link, resp.Status)
+	}
+
+	b, err := ioutil.ReadAll(io.LimitReader(resp.Body, maxIconSize))
+	if err != nil {
+		return nil, "", errors.Wrapf(err, "reading image %q", link)
+	}
+
+	return b, resp.Header.Get("Content-Type"), nil
+}
+
+func imageType(ct string, b []byte) (string, bool) {
+	if len(b) == 0 {
+		return "", false
+	}
+
+	if !strings.HasPrefix(ct, "image/") {
+		ct = http.DetectContentType(b)
+	}
+
+	return ct, strings.HasPrefix(ct, "image/")
+}
+
+func origin(link string) string {
+	u, err := url.Parse(link)
+	if err != nil || !u.IsAbs() {
+		return ""
+	}
+
+	return u.Scheme + "://" + u.Host
+}
@@ -0,0 +1,98 @@
+package feed
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/urandom/readeef/config"
+	"github.com/urandom/readeef/content"
+	"github.com/urandom/readeef/content/repo/mock_repo"
+	"github.com/urandom/readeef/log"
+	"github.com/urandom/readeef/parser"
+)
+
+var pngIcon = []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+
+func TestIconCache_Get(t *testing.T) {
+	tests := []struct {
+		name     string
+		site     string
+		image    parser.Image
+		cached   content.FeedImage
+		noCache  bool
+		want     []byte
+		wantURL  string
+		noUpdate bool
+	}{
+		{name: "fresh", cached: content.FeedImage{FeedID: 1, Icon: []byte("cached"), FetchDate: time.Now()}, want: []byte("cached"), noUpdate: true},
+		{name: "not cached", site: "/with-icon", noCache: true, want: pngIcon},
+		{name: "stale", site: "/with-icon", cached: content.FeedImage{FeedID: 1, Icon: []byte("cached"), FetchDate: time.Now().Add(-2 * time.Hour)}, want: pngIcon},
+		{name: "feed image", site: "/without-icon", image: parser.Image{Title: "logo", Url: "/logo.png"}, cached: content.FeedImage{FeedID: 1, FetchDate: time.Now()}, want: pngIcon, wantURL: "/logo.png"},
+		{name: "failed fetch keeps icon", site: "/without-icon", cached: content.FeedImage{FeedID: 1, Icon: []byte("cached"), FetchDate: time.Now().Add(-2 * time.Hour)}, want: []byte("cached")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch r.URL.Path {
+				case "/with-icon":
+					w.Write([]byte(`<html><head><link rel="icon" href="/icon.png"></head></html>`))
+				case "/without-icon":
+					w.Write([]byte(`<html><head></head></html>`))
+				case "/icon.png", "/logo.png":
+					w.Write(pngIcon)
+				default:
+					http.NotFound(w, r)
+				}
+			}))
+			defer ts.Close()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			feed := content.Feed{ID: 1, Link: ts.URL + "/feed", SiteLink: ts.URL + tt.site}
+			if tt.image.Url != "" {
+				tt.image.Url = ts.URL + tt.image.Url
+				tt.wantURL = ts.URL + tt.wantURL
+			}
+			feed.Refresh(parser.Feed{SiteLink: feed.SiteLink, Image: tt.image})
+
+			repo := mock_repo.NewMockFeedImage(ctrl)
+			if tt.noCache {
+				repo.EXPECT().Get(feed).Return(content.FeedImage{}, content.ErrNoContent)
+			} else {
+				repo.EXPECT().Get(feed).Return(tt.cached, nil)
+			}
+
+			if !tt.noUpdate {
+				repo.EXPECT().Update(gomock.Any()).DoAndReturn(func(image content.FeedImage) error {
+					if image.FeedID != feed.ID || image.Stale(time.Minute) {
+						t.Errorf("IconCache.Get() updated image = %v", image)
+					}
+					return nil
+				})
+			}
+
+			cfg := config.Log{}
+			cfg.Converted.Writer = os.Stderr
+			c := NewIconCache(repo, time.Hour, http.DefaultClient, log.WithStd(cfg))
+
+			got, err := c.Get(feed)
+			if err != nil {
+				t.Fatalf("IconCache.Get() error = %v", err)
+			}
+
+			if !bytes.Equal(got.Icon, tt.want) {
+				t.Errorf("IconCache.Get() icon = %q, want %q", got.Icon, tt.want)
+			}
+
+			if got.URL != tt.wantURL {
+				t.Errorf("IconCache.Get() url = %q, want %q", got.URL, tt.wantURL)
+			}
+		})
+	}
+}
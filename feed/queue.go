@@ -0,0 +1,127 @@
+package feed
+
+import (
+	"container/heap"
+	"context"
+	"time"
+
+	"github.com/urandom/readeef/content"
+)
+
+// QueueState describes the update queue of a Scheduler.
+type QueueState struct {
+	// Scheduled is the number of feeds waiting for their next update.
+	Scheduled int `json:"scheduled"`
+	// InFlight is the number of feeds being updated.
+	InFlight int `json:"inFlight"`
+	Workers  int `json:"workers"`
+
+	// NextFeed is the feed that is due first, at NextUpdate.
+	NextFeed   content.FeedID `json:"nextFeed"`
+	NextUpdate time.Time      `json:"nextUpdate"`
+}
+
+type queueItem struct {
+	ctx         context.Context
+	payload     schedulePayload
+	contentHash []byte
+
+	due   time.Time
+	index int
+
+	inFlight bool
+	removed  bool
+	// reattached marks the feeds that were scheduled again while being
+	// updated, whose update data goes to a new channel after the update.
+	reattached bool
+	// refresh holds the time of an update requested while the feed was
+	// being updated.
+	refresh time.Time
+	// forced marks the next update as requested by a user.
+	forced bool
+}
+
+// queue orders the scheduled feeds by the time of their next update. Feeds
+// that are being updated are only kept in the feed map.
+type queue struct {
+	items    []*queueItem
+	feeds    map[content.FeedID]*queueItem
+	inFlight int
+}
+
+func newQueue() *queue {
+	return &queue{feeds: map[content.FeedID]*queueItem{}}
+}
+
+func (q queue) Len() int { return len(q.items) }
+
+func (q queue) Less(i, j int) bool { return q.items[i].due.Before(q.items[j].due) }
+
+func (q queue) Swap(i, j int) {
+	q.items[i], q.items[j] = q.items[j], q.items[i]
+	q.items[i].index = i
+	q.items[j].index = j
+}
+
+func (q *queue) Push(x interface{}) {
+	item := x.(*queueItem)
+	item.index = len(q.items)
+	q.items = append(q.items, item)
+}
+
+func (q *queue) Pop() interface{} {
+	n := len(q.items)
+	item := q.items[n-1]
+	q.items[n-1] = nil
+	q.items = q.items[:n-1]
+	item.index = -1
+
+	return item
+}
+
+// peek returns the feed that is due first.
+func (q *queue) peek() *queueItem {
+	if len(q.items) == 0 {
+		return nil
+	}
+
+	return q.items[0]
+}
+
+// schedule queues the feed for an update at the given time.
+func (q *queue) schedule(item *queueItem, due time.Time) {
+	item.due = due
+
+	if item.index >= 0 {
+		heap.Fix(q, item.index)
+	} else {
+		heap.Push(q, item)
+	}
+}
+
+// remove drops the feed from the queue, and closes its update channel,
+// unless it is being updated.
+func (q *queue) remove(item *queueItem) {
+	if item.index >= 0 {
+		heap.Remove(q, item.index)
+	}
+
+	if item.inFlight {
+		item.removed = true
+		return
+	}
+
+	delete(q.feeds, item.payload.feed.ID)
+	close(item.payload.updateData)
+}
+
+func (q *queue) state(workers int) QueueState {
+	state := QueueState{Scheduled: len(q.items), InFlight: q.inFlight, Workers: workers}
+
+	if item := q.peek(); item != nil {
+		state.NextFeed = item.payload.feed.ID
+		state.NextUpdate = item.due
+	}
+
+	return state
+}
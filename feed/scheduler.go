@@ -2,167 +2,694 @@ package feed
 
 import (
 	"bytes"
+	"container/heap"
 	"context"
 	"crypto/md5"
+	"errors"
 	"io"
 	"io/ioutil"
+	"net"
 	"net/http"
+	"sort"
 	"strconv"
 	"time"
 
 	"github.com/urandom/readeef/content"
+	"github.com/urandom/readeef/fetch"
 	"github.com/urandom/readeef/log"
 	"github.com/urandom/readeef/parser"
 	"github.com/urandom/readeef/pool"
 )
 
+// Scheduler updates the scheduled feeds once they are due, using a bounded
+// pool of workers. The feeds are kept in a queue ordered by the time of their
+// next update, and may be added, removed and rescheduled while it runs.
 type Scheduler struct {
-	ops    chan feedOp
-	client *http.Client
-	log    log.Log
+	ops     chan feedOp
+	client  *http.Client
+	log     log.Log
+	workers int
+
+	// minInterval and maxInterval bound the adaptive update interval of a
+	// feed. When maxInterval is 0, feeds are updated at their fixed interval.
+	minInterval time.Duration
+	maxInterval time.Duration
+
+	// pushed reports whether the updates of a feed are pushed by its hub.
+	pushed func(content.Feed) bool
+	// settings returns the user overrides of how a feed is fetched.
+	settings func(content.Feed) content.FeedSettings
 }
 
 type UpdateData struct {
-	Feed    parser.Feed
-	message string
+	Feed parser.Feed
+
+	// ETag and LastModified hold the cache validators of the response, and
+	// should be stored on the content.Feed for subsequent conditional requests.
+	ETag         string
+	LastModified string
+
+	// NextUpdate is the time of the next scheduled update of the feed.
+	NextUpdate time.Time
+
+	// Redirect holds the new link of a feed that has been permanently
+	// redirected, and should replace the link of the content.Feed.
+	Redirect string
+
+	// Fetch describes the download of the feed. Its time is zero when the
+	// feed wasn't downloaded.
+	Fetch content.FeedFetch
+
+	message    string
+	retryAfter time.Duration
 }
 
-func NewScheduler(log log.Log) Scheduler {
+const (
+	maxBackoffShift = 10
+
+	// Feeds whose updates are pushed by their hub are still polled, though
+	// only as a safety net.
+	pushedUpdateInterval = 6 * time.Hour
+)
+
+func NewScheduler(client *http.Client, workers int, minInterval, maxInterval time.Duration, log log.Log) Scheduler {
 	return Scheduler{
-		ops:    make(chan feedOp),
-		client: &http.Client{Timeout: 30 * time.Second},
-		log:    log,
+		ops:         make(chan feedOp),
+		client:      client,
+		log:         log,
+		workers:     workers,
+		minInterval: minInterval,
+		maxInterval: maxInterval,
 	}
 }
 
-type feedOp func(feedMap)
-type feedMap map[content.FeedID]schedulePayload
+// SetPushCheck sets the function reporting whether a feed is kept up to date
+// by its hub. Such feeds are polled at most every pushedUpdateInterval, until
+// the hub stops pushing their updates.
+func (s *Scheduler) SetPushCheck(pushed func(content.Feed) bool) {
+	s.pushed = pushed
+}
+
+// SetSettings sets the function returning the settings of a feed. They are
+// looked up before every update, so that changes take effect with the next
+// one.
+func (s *Scheduler) SetSettings(settings func(content.Feed) content.FeedSettings) {
+	s.settings = settings
+}
+
+type feedOp func(*queue)
 
 type schedulePayload struct {
 	feed       content.Feed
 	update     time.Duration
 	updateData chan UpdateData
+
+	interval time.Duration
+	failures uint
+	settings content.FeedSettings
+}
+
+// fetchJob is an update of a feed, performed by one of the workers.
+type fetchJob struct {
+	ctx         context.Context
+	payload     schedulePayload
+	contentHash []byte
+	// forced updates ignore the pausing and skip hours of the feed, and
+	// always send their update data.
+	forced bool
 }
 
+type fetchResult struct {
+	payload     schedulePayload
+	contentHash []byte
+	next        time.Time
+}
+
+// ScheduleFeed adds the feed to the queue. It is first updated at its
+// persisted next update time, and the returned channel receives the data of
+// its updates until the feed is unscheduled or the context is done. Feeds
+// that are already scheduled get a closed channel.
 func (s Scheduler) ScheduleFeed(ctx context.Context, feed content.Feed, update time.Duration) <-chan UpdateData {
 	ret := make(chan UpdateData)
 
-	s.ops <- func(feedMap feedMap) {
-		if _, ok := feedMap[feed.ID]; ok {
+	s.ops <- func(q *queue) {
+		if item, ok := q.feeds[feed.ID]; ok {
+			if !item.removed {
+				close(ret)
+				return
+			}
+
+			// The feed was unscheduled during its update, which still sends
+			// its data to the previous channel. The channel is replaced once
+			// the update is over.
+			s.log.Infof("Scheduling feed %s again during its update", feed)
+
+			if item.reattached {
+				// Nothing sends to the channel of the previous reattachment
+				close(item.payload.updateData)
+			}
+
+			item.removed = false
+			item.reattached = true
+			item.ctx = ctx
+			item.payload.updateData = ret
+
 			return
 		}
 
-		payload := schedulePayload{
-			feed:       feed,
-			update:     update,
-			updateData: ret,
+		item := &queueItem{
+			ctx: ctx,
+			payload: schedulePayload{
+				feed:       feed,
+				update:     update,
+				updateData: ret,
+				interval:   update,
+			},
+			index: -1,
 		}
-		feedMap[feed.ID] = payload
+		q.feeds[feed.ID] = item
 
-		go s.updateFeed(ctx, payload, []byte{})
+		wait := time.Until(feed.NextUpdate)
+		if s.maxInterval > 0 && wait > s.maxInterval {
+			wait = s.maxInterval
+		}
+
+		if wait > 0 {
+			s.log.Infof("Delaying update of feed %s by %s", feed, wait)
+		} else {
+			wait = 0
+		}
+
+		q.schedule(item, time.Now().Add(wait))
 	}
 
 	return ret
 }
 
-func (s Scheduler) unscheduleFeed(ctx context.Context, feed content.Feed) {
-	s.ops <- func(feedMap feedMap) {
-		s.log.Infof("Unscheduling updates for feed %s", feed)
-		payload := feedMap[feed.ID]
-		close(payload.updateData)
+// UnscheduleFeed removes the feed from the queue, closing its update channel.
+// A feed that is being updated is removed after the update.
+func (s Scheduler) UnscheduleFeed(feed content.Feed) {
+	s.ops <- func(q *queue) {
+		if item, ok := q.feeds[feed.ID]; ok {
+			s.log.Infof("Unscheduling updates for feed %s", feed)
+			q.remove(item)
+		}
+	}
+}
+
+// RescheduleFeed moves the next update of the feed to the given time,
+// reporting whether the feed is scheduled. A feed that is being updated is
+// updated again at that time, if it comes before its next update.
+func (s Scheduler) RescheduleFeed(feed content.Feed, at time.Time) bool {
+	ret := make(chan bool)
+
+	s.ops <- func(q *queue) {
+		item, ok := q.feeds[feed.ID]
+		if !ok || item.removed {
+			ret <- false
+			return
+		}
 
-		delete(feedMap, feed.ID)
+		s.log.Infof("Rescheduling update of feed %s at %s", feed, at)
+
+		if item.inFlight {
+			item.refresh = at
+		} else {
+			q.schedule(item, at)
+		}
+
+		ret <- true
 	}
+
+	return <-ret
 }
 
-func (s Scheduler) updateFeed(ctx context.Context, payload schedulePayload, contentHash []byte) {
-	select {
-	case <-ctx.Done():
-		s.unscheduleFeed(ctx, payload.feed)
-		return
-	default:
-		var data UpdateData
-		feed := payload.feed
-		now := time.Now()
+// RefreshFeed updates the feed as soon as a worker is free, reporting
+// whether the feed is scheduled. Unlike the regular updates, the refresh
+// sends its update data even when the feed hasn't changed.
+func (s Scheduler) RefreshFeed(feed content.Feed) bool {
+	ret := make(chan bool)
 
-		if len(contentHash) == 0 || (!feed.SkipHours[now.Hour()] && !feed.SkipDays[now.Weekday().String()]) {
-			data, contentHash = s.downloadFeed(payload, contentHash)
+	s.ops <- func(q *queue) {
+		item, ok := q.feeds[feed.ID]
+		if !ok || item.removed {
+			ret <- false
+			return
+		}
+
+		s.log.Infof("Refreshing feed %s", feed)
+
+		item.forced = true
+		if item.inFlight {
+			item.refresh = time.Now()
+		} else {
+			q.schedule(item, time.Now())
+		}
+
+		ret <- true
+	}
+
+	return <-ret
+}
+
+// IsScheduled reports whether the feed is in the queue.
+func (s Scheduler) IsScheduled(feed content.Feed) bool {
+	ret := make(chan bool)
+
+	s.ops <- func(q *queue) {
+		item, ok := q.feeds[feed.ID]
+		ret <- ok && !item.removed
+	}
+
+	return <-ret
+}
+
+// QueueState returns the current state of the update queue.
+func (s Scheduler) QueueState() QueueState {
+	ret := make(chan QueueState)
+
+	s.ops <- func(q *queue) {
+		ret <- q.state(s.workers)
+	}
+
+	return <-ret
+}
+
+// Start runs the workers, and hands them the feeds as they become due. It
+// returns once the context is done and the updates in progress are over.
+func (s Scheduler) Start(ctx context.Context) {
+	workers := s.workers
+	if workers < 1 {
+		workers = 1
+	}
+
+	q := newQueue()
+	jobs := make(chan fetchJob)
+	results := make(chan fetchResult)
+
+	for i := 0; i < workers; i++ {
+		go s.worker(ctx, jobs, results)
+	}
+
+	timer := time.NewTimer(time.Hour)
+	defer timer.Stop()
+
+	for {
+		var due <-chan time.Time
+
+		// There is an idle worker for every feed that isn't in flight, so
+		// handing out the jobs doesn't block.
+		for q.inFlight < workers && q.Len() > 0 {
+			item := q.peek()
+			if wait := time.Until(item.due); wait > 0 {
+				if !timer.Stop() {
+					select {
+					case <-timer.C:
+					default:
+					}
+				}
+				timer.Reset(wait)
+				due = timer.C
+
+				break
+			}
+
+			heap.Pop(q)
+
+			if item.ctx.Err() != nil {
+				q.remove(item)
+				continue
+			}
+
+			item.inFlight = true
+			q.inFlight++
+
+			jobs <- fetchJob{ctx: item.ctx, payload: item.payload, contentHash: item.contentHash, forced: item.forced}
+			item.forced = false
 		}
 
 		select {
+		case op := <-s.ops:
+			op(q)
+		case <-due:
+		case r := <-results:
+			s.finishUpdate(q, r)
 		case <-ctx.Done():
-			s.unscheduleFeed(ctx, payload.feed)
+			for q.inFlight > 0 {
+				s.finishUpdate(q, <-results)
+			}
+
+			for _, item := range q.feeds {
+				q.remove(item)
+			}
+			close(jobs)
+
 			return
-		default:
-			s.log.Debugf("Sending update data for feed %s", payload.feed)
-			if data.isUpdated() || data.IsErr() {
-				payload.updateData <- data
+		}
+	}
+}
+
+// finishUpdate queues the feed for its next update, or removes it if it was
+// unscheduled during the update.
+func (s Scheduler) finishUpdate(q *queue, r fetchResult) {
+	item := q.feeds[r.payload.feed.ID]
+
+	item.inFlight = false
+	q.inFlight--
+
+	updateData := item.payload.updateData
+	item.payload = r.payload
+	item.contentHash = r.contentHash
+
+	if item.reattached {
+		close(r.payload.updateData)
+		item.payload.updateData = updateData
+		item.reattached = false
+	}
+
+	if item.removed || item.ctx.Err() != nil {
+		q.remove(item)
+		return
+	}
+
+	next := r.next
+	if !item.refresh.IsZero() && item.refresh.Before(next) {
+		next = item.refresh
+	}
+	item.refresh = time.Time{}
+
+	q.schedule(item, next)
+}
+
+func (s Scheduler) worker(ctx context.Context, jobs <-chan fetchJob, results chan<- fetchResult) {
+	for job := range jobs {
+		results <- s.updateFeed(ctx, job)
+	}
+}
+
+// updateFeed downloads the feed, and sends the update data to its channel
+// whenever the feed was downloaded.
+func (s Scheduler) updateFeed(ctx context.Context, job fetchJob) fetchResult {
+	var data UpdateData
+	payload, contentHash := job.payload, job.contentHash
+	feed := payload.feed
+	now := time.Now()
+
+	if s.settings != nil {
+		payload.settings = s.settings(feed)
+	}
+
+	if payload.settings.Paused && !job.forced {
+		s.log.Debugf("Updates of feed %s are paused", feed)
+	} else if len(contentHash) == 0 || job.forced || (!feed.SkipHours[now.Hour()] && !feed.SkipDays[now.Weekday().String()]) {
+		data, contentHash = s.downloadFeed(job.ctx, payload, contentHash)
+
+		data.Fetch.FeedID, data.Fetch.Time, data.Fetch.Duration = feed.ID, now, time.Since(now)
+		data.Fetch.Error = data.message
+
+		if !data.IsErr() {
+			payload.feed.ETag = data.ETag
+			payload.feed.LastModified = data.LastModified
+
+			if data.Redirect != "" {
+				payload.feed.Link = data.Redirect
 			}
+		}
+	}
+
+	payload = s.adaptInterval(payload, data)
+
+	wait := payload.interval
+	if s.pushed != nil && wait < pushedUpdateInterval && s.pushed(payload.feed) {
+		wait = pushedUpdateInterval
+	}
+	data.NextUpdate = time.Now().Add(wait)
+
+	if data.IsFetched() || job.forced {
+		s.log.Debugf("Sending update data for feed %s", payload.feed)
 
-			<-time.After(payload.update)
-			s.updateFeed(ctx, payload, contentHash)
+		select {
+		case payload.updateData <- data:
+		case <-job.ctx.Done():
+		case <-ctx.Done():
 		}
 	}
+
+	return fetchResult{payload: payload, contentHash: contentHash, next: data.NextUpdate}
 }
 
-func (s Scheduler) downloadFeed(payload schedulePayload, contentHash []byte) (UpdateData, []byte) {
+// downloadFeed fetches and parses the feed. Besides the status code, the
+// size and the error class of the fetch, the time and duration of the
+// returned data's fetch are left to the caller.
+func (s Scheduler) downloadFeed(ctx context.Context, payload schedulePayload, contentHash []byte) (UpdateData, []byte) {
 	feed := payload.feed
 
 	s.log.Infof("Downloading content for feed %s", feed)
-	resp, err := s.client.Get(feed.Link)
+
+	req, err := http.NewRequest("GET", feed.Link, nil)
+	if err != nil {
+		return UpdateData{message: err.Error(), Fetch: content.FeedFetch{ErrorClass: content.FetchErrorNetwork}}, contentHash
+	}
+	req = req.WithContext(ctx)
+
+	applySettings(req, payload.settings)
+
+	if feed.ETag != "" {
+		req.Header.Set("If-None-Match", feed.ETag)
+	}
+	if feed.LastModified != "" {
+		req.Header.Set("If-Modified-Since", feed.LastModified)
+	}
+
+	resp, err := s.client.Do(req)
 
 	if err != nil {
-		return UpdateData{message: err.Error()}, contentHash
+		// The host may have asked not to be contacted for a while
+		retryAfter, _ := fetch.RetryAfter(err)
+
+		return UpdateData{
+			message: err.Error(), retryAfter: retryAfter,
+			Fetch: content.FeedFetch{ErrorClass: fetchErrorClass(err)},
+		}, contentHash
+	}
+
+	f := content.FeedFetch{StatusCode: resp.StatusCode}
+	redirect := permanentRedirect(resp)
+
+	if resp.StatusCode == http.StatusNotModified {
+		f.Bytes, _ = io.Copy(ioutil.Discard, resp.Body)
+		resp.Body.Close()
+
+		s.log.Debugf("Feed %s not modified", feed)
+
+		return UpdateData{ETag: feed.ETag, LastModified: feed.LastModified, Redirect: redirect, Fetch: f}, contentHash
 	} else if resp.StatusCode != http.StatusOK {
-		io.Copy(ioutil.Discard, resp.Body)
+		f.Bytes, _ = io.Copy(ioutil.Discard, resp.Body)
 		resp.Body.Close()
 
-		return UpdateData{message: "HTTP Status: " + strconv.Itoa(resp.StatusCode)}, contentHash
+		f.ErrorClass = content.FetchErrorHTTP
+		if resp.StatusCode == http.StatusTooManyRequests {
+			f.ErrorClass = content.FetchErrorRateLimited
+		}
+
+		return UpdateData{
+			message:    "HTTP Status: " + strconv.Itoa(resp.StatusCode),
+			retryAfter: fetch.ParseRetryAfter(resp.Header.Get("Retry-After")),
+			Fetch:      f,
+		}, contentHash
 	} else {
 		defer resp.Body.Close()
 
 		buf := pool.Buffer.Get()
 		defer pool.Buffer.Put(buf)
 
-		if _, err := buf.ReadFrom(resp.Body); err == nil {
-			hash := md5.Sum(buf.Bytes())
-			if bytes.Equal(contentHash, hash[:]) {
-				return UpdateData{}, contentHash
-			}
+		etag, lastModified := resp.Header.Get("ETag"), resp.Header.Get("Last-Modified")
 
-			contentHash = hash[:]
-			if pf, err := parser.ParseFeed(buf.Bytes(), parser.ParseRss2, parser.ParseAtom, parser.ParseRss1); err == nil {
-				return UpdateData{Feed: pf}, contentHash
-			} else {
-				return UpdateData{message: err.Error()}, contentHash
-			}
+		f.Bytes, err = buf.ReadFrom(resp.Body)
+		if err != nil {
+			f.ErrorClass = fetchErrorClass(err)
+			return UpdateData{message: err.Error(), Fetch: f}, contentHash
+		}
+
+		hash := md5.Sum(buf.Bytes())
+		if bytes.Equal(contentHash, hash[:]) {
+			return UpdateData{ETag: etag, LastModified: lastModified, Redirect: redirect, Fetch: f}, contentHash
+		}
+
+		contentHash = hash[:]
+		if pf, err := parser.ParseFeed(buf.Bytes(), parser.ParseJSONFeed, parser.ParseRss2, parser.ParseAtom, parser.ParseRss1); err == nil {
+			return UpdateData{Feed: pf, ETag: etag, LastModified: lastModified, Redirect: redirect, Fetch: f}, contentHash
 		} else {
-			return UpdateData{message: err.Error()}, contentHash
+			f.ErrorClass = content.FetchErrorParse
+			return UpdateData{message: err.Error(), Fetch: f}, contentHash
 		}
 	}
 }
 
-func (u UpdateData) isUpdated() bool {
-	return len(u.Feed.Articles) > 0 && !u.IsErr()
+// permanentRedirect returns the link the request of the response was
+// redirected to, if all of its redirects were permanent.
+func permanentRedirect(resp *http.Response) string {
+	req := resp.Request
+	if req == nil || req.Response == nil {
+		return ""
+	}
+
+	for r := req; r.Response != nil; r = r.Response.Request {
+		switch r.Response.StatusCode {
+		case http.StatusMovedPermanently, http.StatusPermanentRedirect:
+		default:
+			return ""
+		}
+	}
+
+	return req.URL.String()
 }
 
-func (u UpdateData) IsErr() bool {
-	return u.message != ""
+// fetchErrorClass returns the class of an error that prevented a feed from
+// being downloaded.
+func fetchErrorClass(err error) content.FetchErrorClass {
+	if _, ok := fetch.RetryAfter(err); ok {
+		return content.FetchErrorRateLimited
+	}
+
+	if errors.Is(err, context.DeadlineExceeded) {
+		return content.FetchErrorTimeout
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return content.FetchErrorTimeout
+	}
+
+	return content.FetchErrorNetwork
 }
 
-func (u UpdateData) Error() string {
-	return u.message
+// applySettings adds the custom user agent, headers and credentials of the
+// feed settings to the request.
+func applySettings(req *http.Request, settings content.FeedSettings) {
+	for k, v := range settings.Headers {
+		req.Header.Set(k, v)
+	}
+
+	if settings.UserAgent != "" {
+		req.Header.Set("User-Agent", settings.UserAgent)
+	}
+
+	if settings.Username != "" {
+		req.SetBasicAuth(settings.Username, settings.Password)
+	}
+
+	if settings.Cookie != "" {
+		req.Header.Set("Cookie", settings.Cookie)
+	}
 }
 
-func (s Scheduler) Start(ctx context.Context) {
-	feedMap := feedMap{}
+// adaptInterval computes the interval until the next update of the feed.
+// Failed updates back off exponentially from the base update interval,
+// while successful ones follow the publishing rate of the feed, unless the
+// feed settings fix the update interval.
+func (s Scheduler) adaptInterval(payload schedulePayload, data UpdateData) schedulePayload {
+	update := payload.update
+	if payload.settings.UpdateInterval > 0 {
+		update = payload.settings.UpdateInterval
+	}
 
-	for {
-		select {
-		case op := <-s.ops:
-			op(feedMap)
-		case <-ctx.Done():
-			return
+	if data.IsErr() {
+		shift := payload.failures
+		if shift > maxBackoffShift {
+			shift = maxBackoffShift
+		}
+
+		interval := update << shift
+		if data.retryAfter > interval {
+			interval = data.retryAfter
+		}
+
+		if s.maxInterval > 0 && interval > s.maxInterval {
+			interval = s.maxInterval
+		}
+
+		payload.interval = interval
+		payload.failures++
+
+		return payload
+	}
+
+	if payload.settings.UpdateInterval > 0 {
+		payload.failures = 0
+		payload.interval = update
+
+		return payload
+	}
+
+	if payload.failures > 0 {
+		payload.failures = 0
+		payload.interval = payload.update
+	}
+
+	if data.IsUpdated() && s.maxInterval > 0 {
+		payload.interval = publishingInterval(data.Feed.Articles, payload.update)
+
+		if payload.interval < s.minInterval {
+			payload.interval = s.minInterval
+		} else if payload.interval > s.maxInterval {
+			payload.interval = s.maxInterval
+		}
+	}
+
+	return payload
+}
+
+// publishingInterval estimates an update interval as half of the average
+// time between the publishing of the newest articles and the current time.
+func publishingInterval(articles []parser.Article, fallback time.Duration) time.Duration {
+	dates := make([]time.Time, 0, len(articles))
+	for _, a := range articles {
+		if a.Date.Unix() > 0 {
+			dates = append(dates, a.Date)
 		}
 	}
+
+	if len(dates) < 2 {
+		return fallback
+	}
+
+	sort.Slice(dates, func(i, j int) bool {
+		return dates[i].After(dates[j])
+	})
+
+	if len(dates) > 10 {
+		dates = dates[:10]
+	}
+
+	oldest := dates[len(dates)-1]
+	elapsed := time.Since(oldest)
+	if elapsed <= 0 {
+		return fallback
+	}
+
+	return elapsed / time.Duration(len(dates)) / 2
+}
+
+// IsFetched reports whether the feed was downloaded for the update.
+func (u UpdateData) IsFetched() bool {
+	return !u.Fetch.Time.IsZero()
+}
+
+// IsGone reports whether the feed has been removed from its host for good.
+func (u UpdateData) IsGone() bool {
+	return u.Fetch.StatusCode == http.StatusGone
+}
+
+// IsUpdated reports whether the update brought new feed content.
+func (u UpdateData) IsUpdated() bool {
+	return len(u.Feed.Articles) > 0 && !u.IsErr()
+}
+
+func (u UpdateData) IsErr() bool {
+	return u.message != ""
+}
+
+func (u UpdateData) Error() string {
+	return u.message
 }
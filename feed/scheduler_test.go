@@ -2,15 +2,19 @@ package feed
 
 import (
 	"context"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/urandom/readeef/config"
 	"github.com/urandom/readeef/content"
+	"github.com/urandom/readeef/fetch"
 	"github.com/urandom/readeef/log"
+	"github.com/urandom/readeef/parser"
 )
 
 func TestScheduler_ScheduleFeed(t *testing.T) {
@@ -28,10 +32,13 @@ func TestScheduler_ScheduleFeed(t *testing.T) {
 		{"client timeout", time.Microsecond, args{time.Second, content.Feed{ID: 100, Link: "/feed"}, time.Second}, []int{-1}},
 		{"timeout", time.Second, args{time.Nanosecond, content.Feed{ID: 100, Link: "/feed"}, time.Second}, []int{-1}},
 		{"feed update", time.Second, args{2 * time.Second, content.Feed{ID: 100, Link: "/feed"}, time.Second}, []int{2, 1}},
+		{"json feed", time.Second, args{2 * time.Second, content.Feed{ID: 100, Link: "/json-feed"}, time.Second}, []int{1}},
 		{"not-feed-content", time.Second, args{2 * time.Second, content.Feed{ID: 100, Link: "/not-feed"}, time.Second}, []int{-1}},
 		{"404", time.Second, args{2 * time.Second, content.Feed{ID: 100, Link: "/404"}, time.Second}, []int{-1}},
 		{"http error then update", time.Second, args{2 * time.Second, content.Feed{ID: 100, Link: "/error-update"}, time.Second}, []int{-1, 2}},
-		{"same content", time.Second, args{2 * time.Second, content.Feed{ID: 100, Link: "/same-content"}, 100 * time.Millisecond}, []int{2, 1}},
+		{"same content", time.Second, args{2 * time.Second, content.Feed{ID: 100, Link: "/same-content"}, 100 * time.Millisecond}, []int{2, 0, 1}},
+		{"not modified", time.Second, args{2 * time.Second, content.Feed{ID: 100, Link: "/not-modified"}, 100 * time.Millisecond}, []int{2, 0, 1}},
+		{"stored validators", time.Second, args{2 * time.Second, content.Feed{ID: 100, Link: "/not-modified", ETag: `"v1"`}, 100 * time.Millisecond}, []int{0, 0, 1}},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -44,6 +51,8 @@ func TestScheduler_ScheduleFeed(t *testing.T) {
 					} else {
 						w.Write([]byte(rss2Xmlv2))
 					}
+				case "/json-feed":
+					w.Write([]byte(jsonFeed))
 				case "/not-feed":
 					w.Write([]byte("Hello world"))
 				case "/404":
@@ -54,6 +63,20 @@ func TestScheduler_ScheduleFeed(t *testing.T) {
 					} else {
 						w.Write([]byte(rss2Xml))
 					}
+				case "/not-modified":
+					if iter > 0 || r.Header.Get("If-None-Match") != "" {
+						if r.Header.Get("If-None-Match") != `"v1"` {
+							w.Write([]byte("Hello world"))
+						} else if iter <= 1 {
+							w.WriteHeader(http.StatusNotModified)
+						} else {
+							w.Header().Set("ETag", `"v2"`)
+							w.Write([]byte(rss2Xmlv2))
+						}
+					} else {
+						w.Header().Set("ETag", `"v1"`)
+						w.Write([]byte(rss2Xml))
+					}
 				case "/same-content":
 					if iter == 0 || iter == 1 {
 						w.Write([]byte(rss2Xml))
@@ -131,7 +154,589 @@ func TestScheduler_ScheduleFeed(t *testing.T) {
 	}
 }
 
+func TestScheduler_workers(t *testing.T) {
+	var mu sync.Mutex
+	active, maxActive := 0, 0
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		active++
+		if active > maxActive {
+			maxActive = active
+		}
+		mu.Unlock()
+
+		time.Sleep(50 * time.Millisecond)
+		w.Write([]byte(rss2Xml))
+
+		mu.Lock()
+		active--
+		mu.Unlock()
+	}))
+	defer ts.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	cfg := config.Log{}
+	cfg.Converted.Writer = os.Stderr
+	s := NewScheduler(http.DefaultClient, 2, 0, 0, log.WithStd(cfg))
+
+	go s.Start(ctx)
+
+	var wg sync.WaitGroup
+	for i := 1; i <= 6; i++ {
+		up := s.ScheduleFeed(ctx, content.Feed{ID: content.FeedID(i), Link: fmt.Sprintf("%s/%d", ts.URL, i)}, time.Hour)
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			select {
+			case <-up:
+			case <-time.After(2 * time.Second):
+				t.Errorf("Scheduler.ScheduleFeed() timeout waiting for data")
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	if maxActive != 2 {
+		t.Errorf("Scheduler.Start() concurrent downloads = %d, want 2", maxActive)
+	}
+}
+
+func TestScheduler_queue(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(rss2Xml))
+	}))
+	defer ts.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	cfg := config.Log{}
+	cfg.Converted.Writer = os.Stderr
+	s := NewScheduler(http.DefaultClient, 1, 0, 0, log.WithStd(cfg))
+
+	go s.Start(ctx)
+
+	now := time.Now()
+	feed1 := content.Feed{ID: 1, Link: ts.URL + "/1", NextUpdate: now.Add(time.Hour)}
+	feed2 := content.Feed{ID: 2, Link: ts.URL + "/2", NextUpdate: now.Add(2 * time.Hour)}
+
+	up1 := s.ScheduleFeed(ctx, feed1, time.Hour)
+	up2 := s.ScheduleFeed(ctx, feed2, time.Hour)
+
+	if _, ok := <-s.ScheduleFeed(ctx, feed1, time.Hour); ok {
+		t.Errorf("Scheduler.ScheduleFeed() duplicate feed channel is open")
+	}
+
+	state := s.QueueState()
+	if state.Scheduled != 2 || state.InFlight != 0 || state.Workers != 1 || state.NextFeed != feed1.ID || state.NextUpdate.Sub(feed1.NextUpdate) > time.Second {
+		t.Errorf("Scheduler.QueueState() = %+v", state)
+	}
+
+	if s.RescheduleFeed(content.Feed{ID: 3}, now) {
+		t.Errorf("Scheduler.RescheduleFeed() rescheduled an unknown feed")
+	}
+
+	if !s.RescheduleFeed(feed2, now) {
+		t.Errorf("Scheduler.RescheduleFeed() didn't reschedule feed 2")
+	}
+
+	select {
+	case data := <-up2:
+		if data.IsErr() {
+			t.Errorf("Scheduler.RescheduleFeed() update error = %v", data.Error())
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("Scheduler.RescheduleFeed() timeout waiting for data")
+	}
+
+	s.UnscheduleFeed(feed1)
+
+	select {
+	case _, ok := <-up1:
+		if ok {
+			t.Errorf("Scheduler.UnscheduleFeed() unexpected update data")
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("Scheduler.UnscheduleFeed() channel not closed")
+	}
+
+	if state = s.QueueState(); state.Scheduled != 1 || state.NextFeed != feed2.ID {
+		t.Errorf("Scheduler.QueueState() = %+v", state)
+	}
+}
+
+func TestScheduler_refresh(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(rss2Xml))
+	}))
+	defer ts.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	cfg := config.Log{}
+	cfg.Converted.Writer = os.Stderr
+	s := NewScheduler(http.DefaultClient, 1, 0, 0, log.WithStd(cfg))
+	s.SetSettings(func(f content.Feed) content.FeedSettings {
+		return content.FeedSettings{Paused: true}
+	})
+
+	go s.Start(ctx)
+
+	if s.RefreshFeed(content.Feed{ID: 1}) {
+		t.Errorf("Scheduler.RefreshFeed() refreshed an unknown feed")
+	}
+
+	feed := content.Feed{ID: 1, Link: ts.URL, NextUpdate: time.Now().Add(time.Hour)}
+	up := s.ScheduleFeed(ctx, feed, time.Hour)
+
+	// Paused feeds are still refreshed, and the refresh is reported even
+	// when the content hasn't changed.
+	for _, updated := range []bool{true, false} {
+		if !s.RefreshFeed(feed) {
+			t.Fatalf("Scheduler.RefreshFeed() feed not scheduled")
+		}
+
+		select {
+		case data := <-up:
+			if data.IsErr() || data.IsUpdated() != updated {
+				t.Errorf("Scheduler.RefreshFeed() data updated = %v, err = %q, want updated %v", data.IsUpdated(), data.Error(), updated)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("Scheduler.RefreshFeed() timeout waiting for data")
+		}
+	}
+}
+
+func TestScheduler_rescheduleInFlight(t *testing.T) {
+	fetching, release := make(chan struct{}, 1), make(chan struct{})
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case fetching <- struct{}{}:
+		default:
+		}
+		<-release
+		w.Write([]byte(rss2Xml))
+	}))
+	defer ts.Close()
+	defer close(release)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	cfg := config.Log{}
+	cfg.Converted.Writer = os.Stderr
+	s := NewScheduler(http.DefaultClient, 1, 0, 0, log.WithStd(cfg))
+
+	go s.Start(ctx)
+
+	feed := content.Feed{ID: 1, Link: ts.URL}
+	up1 := s.ScheduleFeed(ctx, feed, time.Hour)
+
+	select {
+	case <-fetching:
+	case <-time.After(time.Second):
+		t.Fatalf("Scheduler.ScheduleFeed() timeout waiting for the update")
+	}
+
+	s.UnscheduleFeed(feed)
+	if s.IsScheduled(feed) {
+		t.Fatalf("Scheduler.IsScheduled() unscheduled feed is scheduled")
+	}
+
+	up2 := s.ScheduleFeed(ctx, feed, time.Hour)
+	if !s.IsScheduled(feed) {
+		t.Fatalf("Scheduler.IsScheduled() rescheduled feed is not scheduled")
+	}
+
+	release <- struct{}{}
+
+	// The update in flight is still sent to the previous channel, which is
+	// then closed
+	if _, ok := <-up1; !ok {
+		t.Fatalf("Scheduler.ScheduleFeed() previous channel closed before the update")
+	}
+
+	select {
+	case _, ok := <-up1:
+		if ok {
+			t.Errorf("Scheduler.ScheduleFeed() unexpected data on the previous channel")
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("Scheduler.ScheduleFeed() previous channel not closed")
+	}
+
+	if !s.RefreshFeed(feed) {
+		t.Fatalf("Scheduler.RefreshFeed() rescheduled feed not refreshed")
+	}
+
+	go func() { release <- struct{}{} }()
+
+	select {
+	case data, ok := <-up2:
+		if !ok || data.IsErr() {
+			t.Errorf("Scheduler.ScheduleFeed() rescheduled data = %v, open %v", data.Error(), ok)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("Scheduler.ScheduleFeed() timeout waiting for the rescheduled update")
+	}
+}
+
+func TestScheduler_pushCheck(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(rss2Xml))
+	}))
+	defer ts.Close()
+
+	tests := []struct {
+		name   string
+		pushed bool
+		want   time.Duration
+	}{
+		{"polled", false, time.Second},
+		{"pushed", true, pushedUpdateInterval},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			cfg := config.Log{}
+			cfg.Converted.Writer = os.Stderr
+			s := Scheduler{
+				ops:    make(chan feedOp),
+				client: &http.Client{Timeout: time.Second},
+				log:    log.WithStd(cfg),
+			}
+			s.SetPushCheck(func(f content.Feed) bool {
+				return tt.pushed && f.ID == 100
+			})
+
+			go s.Start(ctx)
+
+			up := s.ScheduleFeed(ctx, content.Feed{ID: 100, Link: ts.URL}, time.Second)
+
+			select {
+			case data := <-up:
+				if got := time.Until(data.NextUpdate); got > tt.want || got < tt.want-time.Minute {
+					t.Errorf("Scheduler.ScheduleFeed() next update in %s, want %s", got, tt.want)
+				}
+			case <-time.After(time.Second):
+				t.Errorf("Scheduler.ScheduleFeed() timeout waiting for data")
+			}
+		})
+	}
+}
+
+func TestScheduler_settings(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if user, pass, ok := r.BasicAuth(); !ok || user != "user" || pass != "pass" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		if r.Header.Get("User-Agent") != "readeef-test" || r.Header.Get("X-Token") != "abcd" || r.Header.Get("Cookie") != "session=abcd" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+
+		w.Write([]byte(rss2Xml))
+	}))
+	defer ts.Close()
+
+	private := content.FeedSettings{
+		UserAgent: "readeef-test",
+		Headers:   content.FeedHeaders{"X-Token": "abcd"},
+		Username:  "user",
+		Password:  "pass",
+		Cookie:    "session=abcd",
+	}
+
+	tests := []struct {
+		name     string
+		settings content.FeedSettings
+		wantErr  bool
+		paused   bool
+	}{
+		{"no credentials", content.FeedSettings{}, true, false},
+		{"private", private, false, false},
+		{"paused", content.FeedSettings{Paused: true}, false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			cfg := config.Log{}
+			cfg.Converted.Writer = os.Stderr
+			s := Scheduler{
+				ops:    make(chan feedOp),
+				client: &http.Client{Timeout: time.Second},
+				log:    log.WithStd(cfg),
+			}
+			s.SetSettings(func(f content.Feed) content.FeedSettings {
+				return tt.settings
+			})
+
+			go s.Start(ctx)
+
+			up := s.ScheduleFeed(ctx, content.Feed{ID: 100, Link: ts.URL}, 100*time.Millisecond)
+
+			select {
+			case data := <-up:
+				if tt.paused {
+					t.Errorf("Scheduler.ScheduleFeed() unexpected update of a paused feed")
+				} else if data.IsErr() != tt.wantErr {
+					t.Errorf("Scheduler.ScheduleFeed() error = %v, wantErr %v", data.Error(), tt.wantErr)
+				}
+			case <-time.After(500 * time.Millisecond):
+				if !tt.paused {
+					t.Errorf("Scheduler.ScheduleFeed() timeout waiting for data")
+				}
+			}
+		})
+	}
+}
+
+func TestScheduler_adaptInterval(t *testing.T) {
+	now := time.Now()
+	articles := func(gaps ...time.Duration) []parser.Article {
+		a := make([]parser.Article, len(gaps))
+		for i := range gaps {
+			a[i] = parser.Article{Date: now.Add(-gaps[i])}
+		}
+		return a
+	}
+
+	tests := []struct {
+		name         string
+		maxInterval  time.Duration
+		payload      schedulePayload
+		data         UpdateData
+		wantInterval time.Duration
+		wantFailures uint
+	}{
+		{"fixed", 0, schedulePayload{update: time.Hour, interval: time.Hour}, UpdateData{Feed: parser.Feed{Articles: articles(time.Minute, 2*time.Minute)}}, time.Hour, 0},
+		{"first failure", 0, schedulePayload{update: time.Hour, interval: time.Hour}, UpdateData{message: "err"}, time.Hour, 1},
+		{"third failure", 0, schedulePayload{update: time.Hour, interval: 2 * time.Hour, failures: 2}, UpdateData{message: "err"}, 4 * time.Hour, 3},
+		{"capped failure", 6 * time.Hour, schedulePayload{update: time.Hour, interval: 4 * time.Hour, failures: 3}, UpdateData{message: "err"}, 6 * time.Hour, 4},
+		{"retry after", 0, schedulePayload{update: time.Hour, interval: time.Hour}, UpdateData{message: "err", retryAfter: 3 * time.Hour}, 3 * time.Hour, 1},
+		{"recovery", 0, schedulePayload{update: time.Hour, interval: 8 * time.Hour, failures: 4}, UpdateData{}, time.Hour, 0},
+		{"frequent", 12 * time.Hour, schedulePayload{update: time.Hour, interval: time.Hour}, UpdateData{Feed: parser.Feed{Articles: articles(5*time.Minute, 10*time.Minute)}}, 10 * time.Minute, 0},
+		{"regular", 12 * time.Hour, schedulePayload{update: time.Hour, interval: time.Hour}, UpdateData{Feed: parser.Feed{Articles: articles(4*time.Hour, 8*time.Hour)}}, 2 * time.Hour, 0},
+		{"quiet", 12 * time.Hour, schedulePayload{update: time.Hour, interval: time.Hour}, UpdateData{Feed: parser.Feed{Articles: articles(30*24*time.Hour, 60*24*time.Hour)}}, 12 * time.Hour, 0},
+		{"single article", 12 * time.Hour, schedulePayload{update: time.Hour, interval: 2 * time.Hour}, UpdateData{Feed: parser.Feed{Articles: articles(time.Minute)}}, time.Hour, 0},
+		{"custom interval", 12 * time.Hour, schedulePayload{update: time.Hour, interval: time.Hour, settings: content.FeedSettings{UpdateInterval: 3 * time.Hour}}, UpdateData{Feed: parser.Feed{Articles: articles(5*time.Minute, 10*time.Minute)}}, 3 * time.Hour, 0},
+		{"custom interval failure", 0, schedulePayload{update: time.Hour, interval: 3 * time.Hour, failures: 1, settings: content.FeedSettings{UpdateInterval: 3 * time.Hour}}, UpdateData{message: "err"}, 6 * time.Hour, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := Scheduler{minInterval: 10 * time.Minute, maxInterval: tt.maxInterval}
+
+			got := s.adaptInterval(tt.payload, tt.data)
+
+			if got.interval.Round(time.Minute) != tt.wantInterval {
+				t.Errorf("Scheduler.adaptInterval() interval = %v, want %v", got.interval, tt.wantInterval)
+			}
+
+			if got.failures != tt.wantFailures {
+				t.Errorf("Scheduler.adaptInterval() failures = %v, want %v", got.failures, tt.wantFailures)
+			}
+		})
+	}
+}
+
+func TestScheduler_retryAfter(t *testing.T) {
+	var mu sync.Mutex
+	requests := 0
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests++
+		mu.Unlock()
+
+		w.Header().Set("Retry-After", "3600")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer ts.Close()
+
+	cfg := config.Log{}
+	cfg.Converted.Writer = os.Stderr
+	s := Scheduler{
+		client: fetch.NewClient(fetch.Limits{MaxRetryAfter: 2 * time.Hour}, time.Second),
+		log:    log.WithStd(cfg),
+	}
+
+	for i := 1; i <= 2; i++ {
+		payload := schedulePayload{feed: content.Feed{ID: content.FeedID(i), Link: fmt.Sprintf("%s/%d", ts.URL, i)}}
+
+		data, _ := s.downloadFeed(context.Background(), payload, nil)
+		if !data.IsErr() {
+			t.Errorf("Scheduler.downloadFeed() feed %d expected an error", i)
+		}
+
+		if data.retryAfter.Round(time.Minute) != time.Hour {
+			t.Errorf("Scheduler.downloadFeed() feed %d retry after = %v, want %v", i, data.retryAfter, time.Hour)
+		}
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	if requests != 1 {
+		t.Errorf("Scheduler.downloadFeed() requests = %d, want 1", requests)
+	}
+}
+
+func TestScheduler_fetch(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/feed":
+			w.Write([]byte(rss2Xml))
+		case "/not-modified":
+			w.WriteHeader(http.StatusNotModified)
+		case "/not-feed":
+			w.Write([]byte("Hello world"))
+		case "/404":
+			w.WriteHeader(http.StatusNotFound)
+		case "/429":
+			w.WriteHeader(http.StatusTooManyRequests)
+		case "/slow":
+			time.Sleep(100 * time.Millisecond)
+		}
+	}))
+	defer ts.Close()
+
+	tests := []struct {
+		name       string
+		path       string
+		wantStatus int
+		wantBytes  int64
+		wantClass  content.FetchErrorClass
+	}{
+		{"feed", "/feed", http.StatusOK, int64(len(rss2Xml)), ""},
+		{"not modified", "/not-modified", http.StatusNotModified, 0, ""},
+		{"not feed content", "/not-feed", http.StatusOK, 11, content.FetchErrorParse},
+		{"404", "/404", http.StatusNotFound, 0, content.FetchErrorHTTP},
+		{"429", "/429", http.StatusTooManyRequests, 0, content.FetchErrorRateLimited},
+		{"timeout", "/slow", 0, 0, content.FetchErrorTimeout},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := config.Log{}
+			cfg.Converted.Writer = os.Stderr
+			s := Scheduler{
+				client: &http.Client{Timeout: 50 * time.Millisecond},
+				log:    log.WithStd(cfg),
+			}
+
+			payload := schedulePayload{feed: content.Feed{ID: 1, Link: ts.URL + tt.path}}
+
+			data, _ := s.downloadFeed(context.Background(), payload, nil)
+			if data.Fetch.StatusCode != tt.wantStatus {
+				t.Errorf("Scheduler.downloadFeed() status = %d, want %d", data.Fetch.StatusCode, tt.wantStatus)
+			}
+
+			if data.Fetch.Bytes != tt.wantBytes {
+				t.Errorf("Scheduler.downloadFeed() bytes = %d, want %d", data.Fetch.Bytes, tt.wantBytes)
+			}
+
+			if data.Fetch.ErrorClass != tt.wantClass {
+				t.Errorf("Scheduler.downloadFeed() error class = %q, want %q", data.Fetch.ErrorClass, tt.wantClass)
+			}
+		})
+	}
+}
+
+func TestScheduler_redirect(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/feed":
+			w.Write([]byte(rss2Xml))
+		case "/moved":
+			http.Redirect(w, r, "/feed", http.StatusMovedPermanently)
+		case "/permanent":
+			http.Redirect(w, r, "/moved", http.StatusPermanentRedirect)
+		case "/found":
+			http.Redirect(w, r, "/feed", http.StatusFound)
+		case "/found-moved":
+			http.Redirect(w, r, "/moved", http.StatusFound)
+		case "/moved-gone":
+			http.Redirect(w, r, "/gone", http.StatusMovedPermanently)
+		case "/gone":
+			w.WriteHeader(http.StatusGone)
+		}
+	}))
+	defer ts.Close()
+
+	tests := []struct {
+		name     string
+		path     string
+		want     string
+		wantGone bool
+	}{
+		{"none", "/feed", "", false},
+		{"moved", "/moved", "/feed", false},
+		{"permanent chain", "/permanent", "/feed", false},
+		{"found", "/found", "", false},
+		{"temporary chain", "/found-moved", "", false},
+		{"moved to gone", "/moved-gone", "", true},
+		{"gone", "/gone", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := config.Log{}
+			cfg.Converted.Writer = os.Stderr
+			s := Scheduler{
+				client: &http.Client{Timeout: time.Second},
+				log:    log.WithStd(cfg),
+			}
+
+			payload := schedulePayload{feed: content.Feed{ID: 1, Link: ts.URL + tt.path}}
+
+			data, _ := s.downloadFeed(context.Background(), payload, nil)
+
+			want := tt.want
+			if want != "" {
+				want = ts.URL + want
+			}
+
+			if data.Redirect != want {
+				t.Errorf("Scheduler.downloadFeed() redirect = %q, want %q", data.Redirect, want)
+			}
+
+			if data.IsGone() != tt.wantGone {
+				t.Errorf("UpdateData.IsGone() = %v, want %v", data.IsGone(), tt.wantGone)
+			}
+		})
+	}
+}
+
 const (
+	jsonFeed = `
+{
+	"version": "https://jsonfeed.org/version/1.1",
+	"title": "Liftoff News",
+	"items": [
+		{
+			"id": "http://liftoff.msfc.nasa.gov/2003/06/03.html#item573",
+			"title": "Star City",
+			"url": "http://liftoff.msfc.nasa.gov/news/2003/news-starcity.asp",
+			"content_text": "How do Americans get ready to work with Russians aboard the International Space Station?",
+			"date_published": "2003-06-03T09:39:21Z"
+		}
+	]
+}
+`
 	rss2Xml = `
 
 <?xml version="1.0"?>
@@ -12,6 +12,7 @@ import (
 
 	"github.com/PuerkitoBio/goquery"
 	"github.com/pkg/errors"
+	"github.com/urandom/readeef/content"
 	"github.com/urandom/readeef/log"
 	"github.com/urandom/readeef/parser"
 	"github.com/urandom/readeef/pool"
@@ -21,34 +22,43 @@ var (
 	domainPattern  = regexp.MustCompile(`^(?:[a-zA-Z0-9-]+\.)+[a-zA-Z]{2,}$`)
 	commentPattern = regexp.MustCompile("<!--.*?-->")
 	linkPattern    = regexp.MustCompile(`<link ([^>]+)>`)
+
+	feedLinkTypes = []string{"application/rss+xml", "application/feed+json"}
 )
 
-func Search(query string, log log.Log) (map[string]parser.Feed, error) {
+func Search(client *http.Client, query string, log log.Log) (map[string]parser.Feed, error) {
+	return SearchWithSettings(client, query, content.FeedSettings{}, log)
+}
+
+// SearchWithSettings searches for feeds like Search, fetching the url with
+// the user agent, headers and credentials of the settings. The settings are
+// not used when the query is not a url.
+func SearchWithSettings(client *http.Client, query string, settings content.FeedSettings, log log.Log) (map[string]parser.Feed, error) {
 	if u, err := url.Parse(query); err == nil && (u.IsAbs() || domainPattern.MatchString(u.String())) {
 		if u.Scheme == "" {
 			u.Scheme = "http"
 		}
 
-		return searchByURL(u, log)
+		return searchByURL(client, u, settings, log)
 	}
 
 	// Assume the query is not a url
-	return searchByQuery(query, log)
+	return searchByQuery(client, query, log)
 }
 
-func searchByURL(u *url.URL, log log.Log) (map[string]parser.Feed, error) {
+func searchByURL(client *http.Client, u *url.URL, settings content.FeedSettings, log log.Log) (map[string]parser.Feed, error) {
 	log.Infof("Searching for feeds from url %s", u)
 	if u.Scheme == "http" {
 		u.Scheme = "https"
 
-		if feeds, err := downloadLinkContent(context.TODO(), u, log); err == nil {
+		if feeds, err := downloadLinkContent(context.TODO(), client, u, settings, log); err == nil {
 			return feeds, nil
 		}
 
 		u.Scheme = "http"
 	}
 
-	feeds, err := downloadLinkContent(context.TODO(), u, log)
+	feeds, err := downloadLinkContent(context.TODO(), client, u, settings, log)
 	if err != nil {
 		return nil, errors.WithMessage(err, "searching by url "+u.String())
 	}
@@ -58,14 +68,14 @@ func searchByURL(u *url.URL, log log.Log) (map[string]parser.Feed, error) {
 	return feeds, nil
 }
 
-func searchByQuery(query string, log log.Log) (map[string]parser.Feed, error) {
+func searchByQuery(client *http.Client, query string, log log.Log) (map[string]parser.Feed, error) {
 	log.Infof("Searching for feeds via %s", query)
 	req, err := http.NewRequest("GET", "https://html.duckduckgo.com/html/?q="+url.QueryEscape(query), nil)
 	if err != nil {
 		return nil, errors.Wrapf(err, "creating feed search query with %s", query)
 	}
 	req.Header.Add("User-Agent", "Mozilla/5.0 (Macintosh; Intel Mac OS X 10.16; rv:85.0) Gecko/20100101 Firefox/85.0")
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := client.Do(req)
 	if err != nil {
 		return nil, errors.Wrap(err, "executing feed search request")
 	}
@@ -128,7 +138,7 @@ func searchByQuery(query string, log log.Log) (map[string]parser.Feed, error) {
 		go func() {
 			defer wg.Done()
 			for u := range input {
-				res, err := downloadLinkContent(ctx, u, log)
+				res, err := downloadLinkContent(ctx, client, u, content.FeedSettings{}, log)
 				output <- out{res, err}
 			}
 		}()
@@ -160,15 +170,17 @@ func searchByQuery(query string, log log.Log) (map[string]parser.Feed, error) {
 	return parsed, nil
 }
 
-func downloadLinkContent(ctx context.Context, u *url.URL, log log.Log) (map[string]parser.Feed, error) {
+func downloadLinkContent(ctx context.Context, client *http.Client, u *url.URL, settings content.FeedSettings, log log.Log) (map[string]parser.Feed, error) {
 	log.Debugf("Downloading content from %s", u)
 	defer log.Debugf("Ending download for %s", u)
 
 	req, err := http.NewRequest("GET", u.String(), nil)
 	var resp *http.Response
 	if err == nil {
+		applySettings(req, settings)
+
 		req = req.WithContext(ctx)
-		resp, err = http.DefaultClient.Do(req)
+		resp, err = client.Do(req)
 	}
 	if err != nil {
 		switch err {
@@ -179,17 +191,18 @@ func downloadLinkContent(ctx context.Context, u *url.URL, log log.Log) (map[stri
 		return nil, errors.Wrapf(err, "getting link %s", u)
 	}
 
+	defer resp.Body.Close()
+
 	if resp.StatusCode != http.StatusOK {
 		return nil, errors.WithStack(fmt.Errorf("getting link %s, invalid status code: %d (%s)", u, resp.StatusCode, resp.Status))
 	}
-	defer resp.Body.Close()
 
 	buf := pool.Buffer.Get()
 	defer pool.Buffer.Put(buf)
 
 	buf.ReadFrom(resp.Body)
 
-	if feed, err := parser.ParseFeed(buf.Bytes(), parser.ParseRss2, parser.ParseAtom, parser.ParseRss1); err == nil {
+	if feed, err := parser.ParseFeed(buf.Bytes(), parser.ParseJSONFeed, parser.ParseRss2, parser.ParseAtom, parser.ParseRss1); err == nil {
 		return map[string]parser.Feed{u.String(): feed}, nil
 	}
 
@@ -199,7 +212,7 @@ func downloadLinkContent(ctx context.Context, u *url.URL, log log.Log) (map[stri
 	feeds := map[string]parser.Feed{}
 	for _, l := range links {
 		attrs := l[1]
-		if strings.Contains(attrs, `"application/rss+xml"`) || strings.Contains(attrs, `'application/rss+xml'`) {
+		if isFeedLink(attrs) {
 			index := strings.Index(attrs, "href=")
 			attr := attrs[index+6:]
 			index = strings.IndexByte(attr, attrs[index+5])
@@ -217,7 +230,7 @@ func downloadLinkContent(ctx context.Context, u *url.URL, log log.Log) (map[stri
 
 				}
 
-				feedMap, err := downloadLinkContent(ctx, docURL, log)
+				feedMap, err := downloadLinkContent(ctx, client, docURL, settings, log)
 				if err != nil {
 					return nil, err
 				}
@@ -231,3 +244,13 @@ func downloadLinkContent(ctx context.Context, u *url.URL, log log.Log) (map[stri
 
 	return feeds, nil
 }
+
+func isFeedLink(attrs string) bool {
+	for _, t := range feedLinkTypes {
+		if strings.Contains(attrs, `"`+t+`"`) || strings.Contains(attrs, `'`+t+`'`) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -3,6 +3,7 @@ package readeef
 import (
 	"context"
 	"fmt"
+	"net/http"
 	"regexp"
 	"time"
 
@@ -23,34 +24,74 @@ import (
 type FeedManager struct {
 	config           config.Config
 	repo             repo.Feed
+	settings         repo.FeedSettings
+	health           repo.FeedHealth
+	client           *http.Client
 	ops              chan func(context.Context, *FeedManager)
 	log              log.Log
 	hubbub           *Hubbub
 	scheduler        feed.Scheduler
 	parserProcessors []processor.Feed
+
+	// refreshes holds the channels waiting for the outcome of the manual
+	// refreshes of each feed. It is only used by the loop.
+	refreshes map[content.FeedID][]chan<- RefreshResult
+}
+
+// RefreshResult is the outcome of a manual refresh of a feed.
+type RefreshResult struct {
+	Feed        content.Feed
+	NewArticles int
+	Err         error
 }
 
 var (
 	commentPattern = regexp.MustCompile("<!--.*?-->")
 	linkPattern    = regexp.MustCompile(`<link ([^>]+)>`)
 
-	ErrNoFeed = errors.New("Feed not found")
+	ErrNoFeed       = errors.New("Feed not found")
+	ErrNotScheduled = errors.New("Feed is not scheduled for updates")
 
 	httpStatusPrefix = "HTTP Status: "
 )
 
-func NewFeedManager(repo repo.Feed, c config.Config, l log.Log) *FeedManager {
-	return &FeedManager{
-		repo: repo, config: c, log: l,
+func NewFeedManager(
+	repo repo.Feed,
+	settings repo.FeedSettings,
+	health repo.FeedHealth,
+	client *http.Client,
+	c config.Config,
+	l log.Log,
+) *FeedManager {
+	intervals := c.FeedManager.Converted
+
+	fm := &FeedManager{
+		repo: repo, settings: settings, health: health, client: client, config: c, log: l,
 		ops:       make(chan func(context.Context, *FeedManager)),
-		scheduler: feed.NewScheduler(l),
+		refreshes: map[content.FeedID][]chan<- RefreshResult{},
+		scheduler: feed.NewScheduler(client, c.FeedManager.UpdateWorkers, intervals.MinUpdateInterval, intervals.MaxUpdateInterval, l),
 	}
+
+	// Feeds fall back to regular polling when their hub denies the
+	// subscription, or stops pushing updates.
+	fm.scheduler.SetPushCheck(func(f content.Feed) bool {
+		return f.HubLink != "" && fm.hubbub != nil && fm.hubbub.Active(f)
+	})
+
+	fm.scheduler.SetSettings(fm.feedSettings)
+
+	return fm
 }
 
 func (fm *FeedManager) SetHubbub(hubbub *Hubbub) {
 	fm.hubbub = hubbub
 }
 
+// QueueState returns the state of the feed update queue.
+func (fm *FeedManager) QueueState() feed.QueueState {
+	return fm.scheduler.QueueState()
+}
+
 func (fm *FeedManager) AddFeedProcessor(p processor.Feed) {
 	fm.parserProcessors = append(fm.parserProcessors, p)
 }
@@ -67,6 +108,10 @@ func (fm *FeedManager) Start(ctx context.Context) error {
 	}
 
 	for _, f := range feeds {
+		if f.Dead {
+			continue
+		}
+
 		fm.log.Infoln("Scheduling feed " + f.String())
 
 		fm.AddFeed(f)
@@ -87,7 +132,42 @@ func (fm *FeedManager) RemoveFeed(feed content.Feed) {
 	}
 }
 
+// RefreshFeed updates the feed as soon as possible, instead of waiting for
+// its next scheduled update. The returned channel receives the outcome of
+// the update, and need not be read.
+func (fm *FeedManager) RefreshFeed(feed content.Feed) (<-chan RefreshResult, error) {
+	ret := make(chan RefreshResult, 1)
+	errc := make(chan error)
+
+	fm.ops <- func(ctx context.Context, fm *FeedManager) {
+		if !fm.scheduler.RefreshFeed(feed) {
+			errc <- ErrNotScheduled
+			return
+		}
+
+		fm.refreshes[feed.ID] = append(fm.refreshes[feed.ID], ret)
+		errc <- nil
+	}
+
+	if err := <-errc; err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
+
 func (fm *FeedManager) AddFeedByLink(link string) (content.Feed, error) {
+	return fm.addFeedByLink(link, nil)
+}
+
+// AddFeedWithSettings adds the feed like AddFeedByLink, using the settings
+// for its discovery. The settings are stored only when the feed is new, as
+// the feeds are shared by all their users.
+func (fm *FeedManager) AddFeedWithSettings(link string, settings content.FeedSettings) (content.Feed, error) {
+	return fm.addFeedByLink(link, &settings)
+}
+
+func (fm *FeedManager) addFeedByLink(link string, settings *content.FeedSettings) (content.Feed, error) {
 	u, err := url.Parse(link)
 	if err == nil {
 		if !u.IsAbs() {
@@ -107,7 +187,12 @@ func (fm *FeedManager) AddFeedByLink(link string) (content.Feed, error) {
 	if err != nil {
 		fm.log.Infoln("Discovering feeds in " + link)
 
-		parsedFeeds, err := feed.Search(link, fm.log)
+		var parsedFeeds map[string]parser.Feed
+		if settings == nil {
+			parsedFeeds, err = feed.Search(fm.client, link, fm.log)
+		} else {
+			parsedFeeds, err = feed.SearchWithSettings(fm.client, link, *settings, fm.log)
+		}
 		if err != nil {
 			return content.Feed{}, errors.WithMessage(err, "searching for feeds")
 		}
@@ -122,6 +207,24 @@ func (fm *FeedManager) AddFeedByLink(link string) (content.Feed, error) {
 		if _, err = fm.repo.Update(&f); err != nil {
 			return content.Feed{}, errors.WithMessage(err, "updating feed with parsed data")
 		}
+
+		if settings != nil {
+			settings.FeedID = f.ID
+			if err = fm.settings.Update(*settings); err != nil {
+				if delErr := fm.repo.Delete(f); delErr != nil {
+					fm.log.Printf("Error deleting feed '%s' with invalid settings: %+v", f, delErr)
+				}
+
+				return content.Feed{}, errors.WithMessage(err, "updating feed settings")
+			}
+		}
+	} else if f.Dead {
+		fm.log.Infoln("Reviving dead feed " + f.String())
+
+		f.Dead = false
+		if _, err = fm.repo.Update(&f); err != nil {
+			return content.Feed{}, errors.WithMessage(err, "reviving dead feed")
+		}
 	}
 
 	fm.log.Infoln("Adding feed " + f.String() + " to manager")
@@ -149,7 +252,7 @@ func (fm *FeedManager) RemoveFeedByLink(link string) (content.Feed, error) {
 
 func (fm *FeedManager) DiscoverFeeds(link string) ([]content.Feed, error) {
 
-	parsedFeeds, err := feed.Search(link, fm.log)
+	parsedFeeds, err := feed.Search(fm.client, link, fm.log)
 	if err != nil {
 		return []content.Feed{}, errors.WithMessage(err, "discovering feeds")
 	}
@@ -171,6 +274,9 @@ func (fm *FeedManager) loop(ctx context.Context) {
 		case op := <-fm.ops:
 			op(ctx, fm)
 		case <-ctx.Done():
+			for id := range fm.refreshes {
+				fm.completeRefreshes(id, RefreshResult{Feed: content.Feed{ID: id}, Err: ctx.Err()})
+			}
 			return
 		}
 	}
@@ -201,16 +307,79 @@ func (fm *FeedManager) scheduleFeed(ctx context.Context, feed content.Feed, upda
 	fm.log.Infof("Scheduling update of feed %s", feed)
 	for update := range fm.scheduler.ScheduleFeed(ctx, feed, update) {
 		fm.log.Infof("Update for feed %s", feed)
+
+		if update.Redirect != "" && update.Redirect != feed.Link {
+			if into, merged := fm.moveFeed(&feed, update.Redirect); merged {
+				fm.refreshed(ctx, feed.ID, RefreshResult{Feed: into})
+				continue
+			}
+		}
+
+		var result RefreshResult
 		if update.IsErr() {
 			feed.AddUpdateError(fmt.Sprintf("%s: %s", time.Now().Format(time.UnixDate), update.Error()))
+			result.Err = update
 		} else {
-			feed.Refresh(fm.processParserFeed(update.Feed))
+			// Refreshes report their updates even when the feed hasn't changed
+			if update.IsUpdated() {
+				feed.Refresh(fm.processParserFeed(update.Feed))
+			}
+			feed.ETag, feed.LastModified = update.ETag, update.LastModified
+		}
+		feed.NextUpdate = update.NextUpdate
+
+		articles, err := fm.updateFeed(feed)
+		if result.Err == nil {
+			result.Err = err
+		}
+		result.Feed, result.NewArticles = feed, len(articles)
+
+		if update.IsFetched() {
+			health, ok := fm.recordFetch(update.Fetch, len(articles))
+
+			if !feed.Dead && (update.IsGone() || ok && fm.failedTooLong(health)) {
+				fm.markDead(&feed)
+				result.Feed = feed
+			}
 		}
 
-		fm.updateFeed(feed)
+		fm.refreshed(ctx, feed.ID, result)
+	}
+
+	// Unless the feed was already scheduled, it has been removed, merged or
+	// marked as dead, and won't be refreshed anymore.
+	op := func(ctx context.Context, fm *FeedManager) {
+		if !fm.scheduler.IsScheduled(feed) {
+			fm.completeRefreshes(feed.ID, RefreshResult{Feed: feed, Err: ErrNotScheduled})
+		}
+	}
+
+	select {
+	case fm.ops <- op:
+	case <-ctx.Done():
 	}
 }
 
+// refreshed hands the outcome of an update to the loop, which sends it to
+// the refreshes of the feed waiting for it.
+func (fm *FeedManager) refreshed(ctx context.Context, id content.FeedID, result RefreshResult) {
+	op := func(ctx context.Context, fm *FeedManager) {
+		fm.completeRefreshes(id, result)
+	}
+
+	select {
+	case fm.ops <- op:
+	case <-ctx.Done():
+	}
+}
+
+func (fm *FeedManager) completeRefreshes(id content.FeedID, result RefreshResult) {
+	for _, c := range fm.refreshes[id] {
+		c <- result
+	}
+	delete(fm.refreshes, id)
+}
+
 func (fm *FeedManager) stopUpdatingFeed(feed content.Feed) {
 	if feed.HubLink != "" && fm.hubbub != nil {
 		fm.hubbub.Unsubscribe(feed)
@@ -225,6 +394,9 @@ func (fm *FeedManager) stopUpdatingFeed(feed content.Feed) {
 		if len(users) == 0 {
 			fm.log.Infoln("Removing orphan feed " + feed.String() + " from the database")
 
+			fm.scheduler.UnscheduleFeed(feed)
+			fm.completeRefreshes(feed.ID, RefreshResult{Feed: feed, Err: ErrNotScheduled})
+
 			if err = fm.repo.Delete(feed); err != nil {
 				fm.log.Printf("Error deleting feed '%s' from the repository: %v\n", feed, err)
 			}
@@ -232,11 +404,107 @@ func (fm *FeedManager) stopUpdatingFeed(feed content.Feed) {
 	}
 }
 
-func (fm FeedManager) updateFeed(feed content.Feed) {
-	if _, err := fm.repo.Update(&feed); err != nil {
+// feedSettings returns the settings of the feed, or the defaults if it has
+// none.
+func (fm FeedManager) feedSettings(feed content.Feed) content.FeedSettings {
+	settings, err := fm.settings.Get(feed)
+	if err != nil && !content.IsNoContent(err) {
+		fm.log.Printf("Error getting settings of feed '%s': %+v", feed, err)
+	}
+
+	return settings
+}
+
+// recordFetch adds the fetch to the health history of the feed, returning
+// the resulting health of the feed.
+func (fm FeedManager) recordFetch(fetch content.FeedFetch, newArticles int) (content.FeedHealth, bool) {
+	fetch.NewArticles = newArticles
+
+	health, err := fm.health.Record(fetch)
+	if err != nil {
+		fm.log.Printf("Error recording %s: %+v", fetch, err)
+		return health, false
+	}
+
+	return health, true
+}
+
+// failedTooLong returns whether the feed has been failing for long enough to
+// be considered dead.
+func (fm FeedManager) failedTooLong(health content.FeedHealth) bool {
+	deadAfter := fm.config.FeedManager.Converted.DeadAfter
+
+	return deadAfter > 0 && health.Failing() && time.Since(health.FailingSince) >= deadAfter
+}
+
+// moveFeed changes the link of a permanently redirected feed. If another feed
+// already has the new link, the feed is merged into it and no longer updated,
+// in which case the other feed is returned.
+func (fm *FeedManager) moveFeed(feed *content.Feed, link string) (content.Feed, bool) {
+	into, err := fm.repo.FindByLink(link)
+	if err != nil {
+		if content.IsNoContent(err) {
+			fm.log.Infof("Feed %s moved to %s", feed, link)
+			feed.Link = link
+		} else {
+			fm.log.Printf("Error finding feed by link '%s': %+v", link, err)
+		}
+
+		return content.Feed{}, false
+	}
+
+	if into.ID == feed.ID {
+		feed.Link = link
+		return content.Feed{}, false
+	}
+
+	fm.log.Infof("Feed %s moved to existing feed %s, merging", feed, into)
+
+	if err := fm.repo.Merge(*feed, into); err != nil {
+		fm.log.Printf("Error merging feed '%s' into '%s': %+v", feed, into, err)
+		return content.Feed{}, false
+	}
+
+	fm.unscheduleFeed(*feed)
+
+	if into.Dead {
+		fm.log.Infoln("Reviving dead feed " + into.String())
+
+		into.Dead = false
+		if _, err := fm.updateFeed(into); err == nil {
+			fm.AddFeed(into)
+		}
+	}
+
+	return into, true
+}
+
+// markDead stops updating a feed that is gone, or has been failing for too
+// long.
+func (fm FeedManager) markDead(feed *content.Feed) {
+	fm.log.Infof("Marking feed %s as dead", feed)
+
+	feed.Dead = true
+	fm.updateFeed(*feed)
+
+	fm.unscheduleFeed(*feed)
+}
+
+func (fm FeedManager) unscheduleFeed(feed content.Feed) {
+	if feed.HubLink != "" && fm.hubbub != nil {
+		fm.hubbub.Unsubscribe(feed)
+	}
+
+	fm.scheduler.UnscheduleFeed(feed)
+}
+
+func (fm FeedManager) updateFeed(feed content.Feed) ([]content.Article, error) {
+	articles, err := fm.repo.Update(&feed)
+	if err != nil {
 		fm.log.Printf("Error updating feed '%s' database record: %+v", feed, err)
 	}
 
+	return articles, err
 }
 
 func (fm FeedManager) processParserFeed(pf parser.Feed) parser.Feed {
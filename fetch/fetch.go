@@ -0,0 +1,247 @@
+// Package fetch provides the HTTP layer shared by everything that contacts
+// remote sites. It limits the number of concurrent requests to each host,
+// spaces the requests out, and leaves hosts alone for as long as they ask to
+// via the Retry-After header.
+package fetch
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Limits describes how polite the transport is towards each host.
+type Limits struct {
+	// Concurrency caps the number of requests to a host that are waiting
+	// for their response headers at once. Values below 1 are treated as 1.
+	Concurrency int
+	// Interval is the minimum time between the starts of two requests to
+	// the same host.
+	Interval time.Duration
+	// MaxRetryAfter caps the time for which a Retry-After header keeps a host
+	// from being contacted. When 0, the header is only honored by the callers
+	// that check the response.
+	MaxRetryAfter time.Duration
+}
+
+// RetryAfterError is returned instead of contacting a host that has asked
+// for requests to be retried later.
+type RetryAfterError struct {
+	Host  string
+	Until time.Time
+}
+
+func (e RetryAfterError) Error() string {
+	return fmt.Sprintf("host %s asked to be retried after %s", e.Host, e.Until.Format(time.RFC1123))
+}
+
+// Transport is an http.RoundTripper that applies the limits to the requests
+// of each host, before passing them to the underlying round tripper.
+type Transport struct {
+	base   http.RoundTripper
+	limits Limits
+
+	mu    sync.Mutex
+	hosts map[string]*host
+}
+
+type host struct {
+	slots chan struct{}
+	// users counts the requests holding a reference to the host, which is
+	// forgotten once it is no longer used or limited.
+	users int
+	// next is the earliest time at which a request may start.
+	next time.Time
+	// blocked is the time until which the host is not contacted.
+	blocked time.Time
+}
+
+// NewTransport creates a transport with the given limits. The
+// http.DefaultTransport is used when base is nil.
+func NewTransport(base http.RoundTripper, limits Limits) *Transport {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	if limits.Concurrency < 1 {
+		limits.Concurrency = 1
+	}
+
+	return &Transport{base: base, limits: limits, hosts: map[string]*host{}}
+}
+
+// NewClient creates a client with the given timeout, whose requests go
+// through a new transport.
+func NewClient(limits Limits, timeout time.Duration) *http.Client {
+	return &http.Client{
+		Transport: NewTransport(nil, limits),
+		Timeout:   timeout,
+	}
+}
+
+// RoundTrip waits for a free slot and the end of the interval since the
+// previous request to the host, unless the request is canceled first. The
+// slot is released as soon as the response headers arrive, so that a body
+// that is never closed cannot keep the host from being contacted.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	key := strings.ToLower(req.URL.Host)
+	h := t.acquire(key)
+
+	if err := t.blocked(key, h); err != nil {
+		t.release(h, false)
+		return nil, err
+	}
+
+	ctx := req.Context()
+
+	select {
+	case h.slots <- struct{}{}:
+	case <-ctx.Done():
+		t.release(h, false)
+		return nil, ctx.Err()
+	}
+
+	if wait := t.reserve(h); wait > 0 {
+		timer := time.NewTimer(wait)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			t.release(h, true)
+			return nil, ctx.Err()
+		}
+	}
+
+	if err := t.blocked(key, h); err != nil {
+		t.release(h, true)
+		return nil, err
+	}
+
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		t.release(h, true)
+		return nil, err
+	}
+
+	if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusServiceUnavailable {
+		t.block(h, ParseRetryAfter(resp.Header.Get("Retry-After")))
+	}
+
+	t.release(h, true)
+
+	return resp, nil
+}
+
+func (t *Transport) acquire(key string) *host {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	h, ok := t.hosts[key]
+	if !ok {
+		t.forgetIdle()
+
+		h = &host{slots: make(chan struct{}, t.limits.Concurrency)}
+		t.hosts[key] = h
+	}
+	h.users++
+
+	return h
+}
+
+// forgetIdle removes the hosts that are neither used, nor have any pending
+// limits.
+func (t *Transport) forgetIdle() {
+	now := time.Now()
+	for key, h := range t.hosts {
+		if h.users == 0 && now.After(h.next) && now.After(h.blocked) {
+			delete(t.hosts, key)
+		}
+	}
+}
+
+func (t *Transport) release(h *host, slot bool) {
+	if slot {
+		<-h.slots
+	}
+
+	t.mu.Lock()
+	h.users--
+	t.mu.Unlock()
+}
+
+// reserve returns how long the request has to wait before it can start,
+// and pushes back the start of the next request to the host.
+func (t *Transport) reserve(h *host) time.Duration {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := time.Now()
+	start := h.next
+	if start.Before(now) {
+		start = now
+	}
+	h.next = start.Add(t.limits.Interval)
+
+	return start.Sub(now)
+}
+
+func (t *Transport) blocked(key string, h *host) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if time.Now().Before(h.blocked) {
+		return RetryAfterError{Host: key, Until: h.blocked}
+	}
+
+	return nil
+}
+
+func (t *Transport) block(h *host, d time.Duration) {
+	if d <= 0 || t.limits.MaxRetryAfter <= 0 {
+		return
+	}
+
+	if d > t.limits.MaxRetryAfter {
+		d = t.limits.MaxRetryAfter
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if until := time.Now().Add(d); until.After(h.blocked) {
+		h.blocked = until
+	}
+}
+
+// RetryAfter returns the time left until the host of a request that failed
+// with a RetryAfterError may be contacted again.
+func RetryAfter(err error) (time.Duration, bool) {
+	var e RetryAfterError
+	if errors.As(err, &e) {
+		return time.Until(e.Until), true
+	}
+
+	return 0, false
+}
+
+// ParseRetryAfter returns the duration specified in a Retry-After header,
+// either as delay seconds or as an HTTP date.
+func ParseRetryAfter(value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return time.Duration(seconds) * time.Second
+	}
+
+	if t, err := http.ParseTime(value); err == nil {
+		return time.Until(t)
+	}
+
+	return 0
+}
@@ -0,0 +1,233 @@
+package fetch
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestTransport_concurrency(t *testing.T) {
+	var mu sync.Mutex
+	active, maxActive := 0, 0
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		active++
+		if active > maxActive {
+			maxActive = active
+		}
+		mu.Unlock()
+
+		time.Sleep(50 * time.Millisecond)
+
+		mu.Lock()
+		active--
+		mu.Unlock()
+	}))
+	defer ts.Close()
+
+	client := NewClient(Limits{Concurrency: 2}, time.Second)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 6; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			resp, err := client.Get(ts.URL)
+			if err != nil {
+				t.Errorf("Client.Get() error = %v", err)
+				return
+			}
+			io.Copy(ioutil.Discard, resp.Body)
+			resp.Body.Close()
+		}()
+	}
+
+	wg.Wait()
+
+	if maxActive != 2 {
+		t.Errorf("Transport.RoundTrip() concurrent requests = %d, want 2", maxActive)
+	}
+}
+
+func TestTransport_interval(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer ts.Close()
+
+	client := NewClient(Limits{Concurrency: 3, Interval: 100 * time.Millisecond}, time.Second)
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		resp, err := client.Get(ts.URL)
+		if err != nil {
+			t.Fatalf("Client.Get() error = %v", err)
+		}
+		resp.Body.Close()
+	}
+
+	if elapsed := time.Since(start); elapsed < 200*time.Millisecond {
+		t.Errorf("Transport.RoundTrip() 3 requests took %s, want at least %s", elapsed, 200*time.Millisecond)
+	}
+
+	// The interval is kept per host
+	other := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer other.Close()
+
+	start = time.Now()
+	resp, err := client.Get(other.URL)
+	if err != nil {
+		t.Fatalf("Client.Get() error = %v", err)
+	}
+	resp.Body.Close()
+
+	if elapsed := time.Since(start); elapsed >= 100*time.Millisecond {
+		t.Errorf("Transport.RoundTrip() request to another host took %s", elapsed)
+	}
+}
+
+func TestTransport_canceled(t *testing.T) {
+	done := make(chan struct{})
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-done
+	}))
+	defer ts.Close()
+	defer close(done)
+
+	client := NewClient(Limits{Concurrency: 1}, time.Second)
+
+	// Keep the only slot of the host taken
+	go func() {
+		if resp, err := client.Get(ts.URL); err == nil {
+			resp.Body.Close()
+		}
+	}()
+	time.Sleep(20 * time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	req, _ := http.NewRequest("GET", ts.URL, nil)
+	if _, err := client.Do(req.WithContext(ctx)); err == nil {
+		t.Errorf("Client.Do() expected an error while the host is busy")
+	}
+}
+
+func TestTransport_unreadBody(t *testing.T) {
+	done := make(chan struct{})
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/stream" {
+			w.WriteHeader(http.StatusOK)
+			w.(http.Flusher).Flush()
+			<-done
+		}
+	}))
+	defer ts.Close()
+	defer close(done)
+
+	client := NewClient(Limits{Concurrency: 1}, time.Second)
+
+	// A body that is never read nor closed doesn't hold the slot
+	resp, err := client.Get(ts.URL + "/stream")
+	if err != nil {
+		t.Fatalf("Client.Get() error = %v", err)
+	}
+	defer resp.Body.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+	defer cancel()
+
+	req, _ := http.NewRequest("GET", ts.URL, nil)
+	resp, err = client.Do(req.WithContext(ctx))
+	if err != nil {
+		t.Fatalf("Client.Do() error = %v while a previous body is unread", err)
+	}
+	resp.Body.Close()
+}
+
+func TestTransport_retryAfter(t *testing.T) {
+	tests := []struct {
+		name       string
+		status     int
+		retryAfter string
+		max        time.Duration
+		want       time.Duration
+	}{
+		{"too many requests", http.StatusTooManyRequests, "120", time.Hour, 2 * time.Minute},
+		{"unavailable", http.StatusServiceUnavailable, time.Now().Add(30 * time.Minute).UTC().Format(http.TimeFormat), time.Hour, 30 * time.Minute},
+		{"capped", http.StatusTooManyRequests, "7200", time.Hour, time.Hour},
+		{"disabled", http.StatusTooManyRequests, "120", 0, 0},
+		{"no header", http.StatusTooManyRequests, "", time.Hour, 0},
+		{"other status", http.StatusInternalServerError, "120", time.Hour, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requests := 0
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requests++
+
+				if tt.retryAfter != "" {
+					w.Header().Set("Retry-After", tt.retryAfter)
+				}
+				w.WriteHeader(tt.status)
+			}))
+			defer ts.Close()
+
+			client := NewClient(Limits{MaxRetryAfter: tt.max}, time.Second)
+
+			resp, err := client.Get(ts.URL)
+			if err != nil {
+				t.Fatalf("Client.Get() error = %v", err)
+			}
+			resp.Body.Close()
+
+			resp, err = client.Get(ts.URL)
+			if err == nil {
+				resp.Body.Close()
+			}
+
+			got, blocked := RetryAfter(err)
+			if blocked != (tt.want > 0) {
+				t.Fatalf("RetryAfter() blocked = %v, error = %v", blocked, err)
+			}
+
+			if got.Round(time.Minute) != tt.want {
+				t.Errorf("RetryAfter() = %v, want %v", got, tt.want)
+			}
+
+			wantRequests := 2
+			if blocked {
+				wantRequests = 1
+			}
+
+			if requests != wantRequests {
+				t.Errorf("Transport.RoundTrip() requests = %d, want %d", requests, wantRequests)
+			}
+		})
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		want  time.Duration
+	}{
+		{"empty", "", 0},
+		{"seconds", "120", 2 * time.Minute},
+		{"date", time.Now().Add(time.Hour).UTC().Format(http.TimeFormat), time.Hour},
+		{"invalid", "soon", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ParseRetryAfter(tt.value); got.Round(time.Minute) != tt.want {
+				t.Errorf("ParseRetryAfter() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
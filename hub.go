@@ -0,0 +1,418 @@
+package readeef
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/urandom/readeef/config"
+	"github.com/urandom/readeef/content"
+	"github.com/urandom/readeef/content/repo"
+	"github.com/urandom/readeef/log"
+)
+
+// Hub is a WebSub hub for the outbound feeds of readeef. It verifies the
+// intent of the subscribers, keeps track of their leases, and pushes the
+// content of a topic to them whenever it changes.
+//
+// Changes are detected by fetching the topics and comparing their digests,
+// which are only kept in memory. The first check after a restart therefore
+// doesn't distribute anything. Only the topics under the configured base URL
+// of the API are ever fetched.
+type Hub struct {
+	service repo.Service
+	config  config.Config
+	client  *http.Client
+	log     log.Log
+
+	check chan struct{}
+	// delay is how long a requested check waits for further requests, which
+	// are merged into it.
+	delay time.Duration
+
+	mu      sync.Mutex
+	digests map[string]string
+}
+
+const (
+	// The maximum size of a topic's content.
+	maxHubTopicSize = 10 << 20
+	// The maximum size of a subscriber's response to a verification request.
+	maxHubChallengeSize = 1024
+	// How long a requested check waits for further changes.
+	hubCheckDelay = 30 * time.Second
+)
+
+func NewHub(service repo.Service, client *http.Client, c config.Config, l log.Log) *Hub {
+	return &Hub{
+		service: service,
+		config:  c,
+		client:  client,
+		log:     l,
+		check:   make(chan struct{}, 1),
+		delay:   hubCheckDelay,
+		digests: map[string]string{},
+	}
+}
+
+// HubURL returns the URL of the hub that serves the given topic, which must
+// be an API URL.
+func HubURL(topic string) string {
+	u, err := url.Parse(topic)
+	if err != nil {
+		return ""
+	}
+
+	i := strings.Index(u.Path, "/v2/")
+	if i == -1 {
+		return ""
+	}
+
+	u.Path = u.Path[:i] + "/v2/hub"
+	u.RawQuery = ""
+	u.Fragment = ""
+
+	return u.String()
+}
+
+// Subscribe verifies the intent of the subscriber, and stores the
+// subscription with the given lease. If the topic cannot be fetched, the
+// subscription is denied instead. The verification is performed
+// asynchronously.
+func (h *Hub) Subscribe(s content.HubSubscription, lease time.Duration) error {
+	if err := s.Validate(); err != nil {
+		return errors.WithMessage(err, "validating hub subscription")
+	}
+
+	go func() {
+		if err := h.subscribe(s, lease); err != nil {
+			h.log.Printf("Error subscribing %s: %+v", s, err)
+		}
+	}()
+
+	return nil
+}
+
+// Unsubscribe verifies the intent of the subscriber, and removes the
+// subscription. The verification is performed asynchronously.
+func (h *Hub) Unsubscribe(s content.HubSubscription) error {
+	if err := s.Validate(); err != nil {
+		return errors.WithMessage(err, "validating hub subscription")
+	}
+
+	go func() {
+		if err := h.unsubscribe(s); err != nil {
+			h.log.Printf("Error unsubscribing %s: %+v", s, err)
+		}
+	}()
+
+	return nil
+}
+
+// Check schedules a check of all subscribed topics, distributing the ones
+// that have changed. The check is delayed, and the checks that are requested
+// in the meantime are merged into it.
+func (h *Hub) Check() {
+	select {
+	case h.check <- struct{}{}:
+	default:
+	}
+}
+
+// Start checks the subscribed topics periodically, as well as when
+// requested, until the context is canceled.
+func (h *Hub) Start(ctx context.Context) {
+	h.log.Infoln("Starting the WebSub hub")
+
+	go func() {
+		ticker := time.NewTicker(h.config.Hub.Converted.CheckInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			case <-h.check:
+				select {
+				case <-ctx.Done():
+					return
+				case <-time.After(h.delay):
+				}
+
+				// Drop the checks requested during the delay
+				select {
+				case <-h.check:
+				default:
+				}
+			}
+
+			if err := h.distribute(); err != nil {
+				h.log.Printf("Error distributing hub topics: %+v", err)
+			}
+		}
+	}()
+}
+
+func (h *Hub) subscribe(s content.HubSubscription, lease time.Duration) error {
+	h.log.Infof("Verifying hub subscription %s", s)
+
+	body, _, err := h.fetch(s.Topic)
+	if err != nil {
+		h.log.Infof("Denying hub subscription %s: %v", s, err)
+
+		return errors.WithMessage(h.deny(s, "the topic is not available"), "denying subscription")
+	}
+
+	if err := h.verify(s, "subscribe", lease); err != nil {
+		return errors.WithMessage(err, "verifying subscription intent")
+	}
+
+	s.LeaseExpiry = time.Now().Add(lease)
+	if err := h.service.HubSubscriptionRepo().Update(s); err != nil {
+		return errors.WithMessage(err, "updating hub subscription")
+	}
+
+	h.mu.Lock()
+	if _, ok := h.digests[s.Topic]; !ok {
+		h.digests[s.Topic] = topicDigest(body)
+	}
+	h.mu.Unlock()
+
+	return nil
+}
+
+func (h *Hub) unsubscribe(s content.HubSubscription) error {
+	h.log.Infof("Verifying hub unsubscription %s", s)
+
+	if err := h.verify(s, "unsubscribe", 0); err != nil {
+		return errors.WithMessage(err, "verifying unsubscription intent")
+	}
+
+	if err := h.service.HubSubscriptionRepo().Delete(s); err != nil {
+		return errors.WithMessage(err, "deleting hub subscription")
+	}
+
+	return nil
+}
+
+// distribute removes the expired subscriptions, and pushes each changed
+// topic to its remaining subscribers.
+func (h *Hub) distribute() error {
+	repo := h.service.HubSubscriptionRepo()
+
+	if err := repo.DeleteExpired(time.Now()); err != nil {
+		return errors.WithMessage(err, "deleting expired hub subscriptions")
+	}
+
+	subscriptions, err := repo.All()
+	if err != nil {
+		return errors.WithMessage(err, "getting hub subscriptions")
+	}
+
+	topics := map[string][]content.HubSubscription{}
+	for _, s := range subscriptions {
+		if !s.Expired() {
+			topics[s.Topic] = append(topics[s.Topic], s)
+		}
+	}
+
+	h.mu.Lock()
+	for topic := range h.digests {
+		if _, ok := topics[topic]; !ok {
+			delete(h.digests, topic)
+		}
+	}
+	h.mu.Unlock()
+
+	for topic, subscriptions := range topics {
+		body, contentType, err := h.fetch(topic)
+		if err != nil {
+			h.log.Printf("Error fetching hub topic %s: %+v", topic, err)
+			continue
+		}
+
+		d := topicDigest(body)
+
+		h.mu.Lock()
+		previous, ok := h.digests[topic]
+		h.digests[topic] = d
+		h.mu.Unlock()
+
+		if !ok || previous == d {
+			continue
+		}
+
+		h.log.Infof("Distributing hub topic %s to %d subscribers", topic, len(subscriptions))
+
+		for _, s := range subscriptions {
+			if err := h.push(s, body, contentType); err != nil {
+				h.log.Printf("Error distributing hub topic to %s: %+v", s, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// fetch returns the content of the topic, along with its content type.
+func (h *Hub) fetch(topic string) ([]byte, string, error) {
+	if base := h.config.Hub.BaseURL; base == "" || !strings.HasPrefix(topic, base+"/") {
+		return nil, "", errors.Errorf("topic %s is not under the API base URL", topic)
+	}
+
+	resp, err := h.client.Get(topic)
+	if err != nil {
+		return nil, "", errors.Wrapf(err, "getting topic %s", topic)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", errors.Errorf("getting topic %s: unexpected status %s", topic, resp.Status)
+	}
+
+	body, err := ioutil.ReadAll(io.LimitReader(resp.Body, maxHubTopicSize))
+	if err != nil {
+		return nil, "", errors.Wrapf(err, "reading topic %s", topic)
+	}
+
+	return body, resp.Header.Get("Content-Type"), nil
+}
+
+// verify confirms that the subscriber wants the subscription, by checking
+// that it echoes back a random challenge.
+func (h *Hub) verify(s content.HubSubscription, mode string, lease time.Duration) error {
+	challenge, err := randomToken()
+	if err != nil {
+		return errors.WithMessage(err, "generating hub challenge")
+	}
+
+	params := url.Values{}
+	params.Set("hub.mode", mode)
+	params.Set("hub.topic", s.Topic)
+	params.Set("hub.challenge", challenge)
+	if mode == "subscribe" {
+		params.Set("hub.lease_seconds", strconv.FormatInt(int64(lease/time.Second), 10))
+	}
+
+	resp, err := h.client.Get(callbackWithParams(s.Callback, params))
+	if err != nil {
+		return errors.Wrapf(err, "getting callback %s", s.Callback)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return errors.Errorf("verifying callback %s: unexpected status %s", s.Callback, resp.Status)
+	}
+
+	body, err := ioutil.ReadAll(io.LimitReader(resp.Body, maxHubChallengeSize))
+	if err != nil {
+		return errors.Wrapf(err, "reading callback %s response", s.Callback)
+	}
+
+	if strings.TrimSpace(string(body)) != challenge {
+		return errors.Errorf("verifying callback %s: challenge mismatch", s.Callback)
+	}
+
+	return nil
+}
+
+// deny notifies the subscriber that its subscription was not accepted.
+func (h *Hub) deny(s content.HubSubscription, reason string) error {
+	params := url.Values{}
+	params.Set("hub.mode", "denied")
+	params.Set("hub.topic", s.Topic)
+	params.Set("hub.reason", reason)
+
+	resp, err := h.client.Get(callbackWithParams(s.Callback, params))
+	if err != nil {
+		return errors.Wrapf(err, "getting callback %s", s.Callback)
+	}
+	resp.Body.Close()
+
+	return nil
+}
+
+// push sends the content of the topic to the subscriber, signed with its
+// secret, if it has one. Subscribers that respond with 410 Gone are removed.
+func (h *Hub) push(s content.HubSubscription, body []byte, contentType string) error {
+	req, err := http.NewRequest("POST", s.Callback, bytes.NewReader(body))
+	if err != nil {
+		return errors.Wrapf(err, "creating request to %s", s.Callback)
+	}
+
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	req.Header.Add("Link", fmt.Sprintf(`<%s>; rel="hub"`, HubURL(s.Topic)))
+	req.Header.Add("Link", fmt.Sprintf(`<%s>; rel="self"`, s.Topic))
+
+	if s.Secret != "" {
+		req.Header.Set("X-Hub-Signature", "sha256="+HubSignature(s.Secret, body))
+	}
+
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return errors.Wrapf(err, "posting to %s", s.Callback)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode == http.StatusGone {
+		h.log.Infof("Removing gone hub subscription %s", s)
+
+		return errors.WithMessage(h.service.HubSubscriptionRepo().Delete(s), "deleting hub subscription")
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return errors.Errorf("posting to %s: unexpected status %s", s.Callback, resp.Status)
+	}
+
+	return nil
+}
+
+// HubSignature returns the hex encoded HMAC-SHA256 signature of the body,
+// as sent in the X-Hub-Signature header.
+func HubSignature(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func callbackWithParams(callback string, params url.Values) string {
+	if strings.Contains(callback, "?") {
+		return callback + "&" + params.Encode()
+	}
+
+	return callback + "?" + params.Encode()
+}
+
+// randomToken returns a random hex string, used for the hub challenges and
+// secrets.
+func randomToken() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", errors.Wrap(err, "reading random bytes")
+	}
+
+	return hex.EncodeToString(b), nil
+}
+
+func topicDigest(b []byte) string {
+	sum := sha256.Sum256(b)
+
+	return hex.EncodeToString(sum[:])
+}
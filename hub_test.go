@@ -0,0 +1,316 @@
+package readeef
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/urandom/readeef/config"
+	"github.com/urandom/readeef/content"
+	"github.com/urandom/readeef/content/repo/mock_repo"
+	"github.com/urandom/readeef/log"
+)
+
+type hubSubscriber struct {
+	sync.Mutex
+
+	echo   bool
+	status int
+
+	modes  []string
+	bodies []string
+	header []http.Header
+}
+
+func (s *hubSubscriber) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.Lock()
+	defer s.Unlock()
+
+	if r.Method == "GET" {
+		s.modes = append(s.modes, r.URL.Query().Get("hub.mode"))
+
+		if s.echo {
+			w.Write([]byte(r.URL.Query().Get("hub.challenge")))
+		} else {
+			w.Write([]byte("nope"))
+		}
+
+		return
+	}
+
+	b, _ := ioutil.ReadAll(r.Body)
+	s.bodies = append(s.bodies, string(b))
+	s.header = append(s.header, r.Header)
+
+	if s.status != 0 {
+		w.WriteHeader(s.status)
+	}
+}
+
+func newTestHub(service *mock_repo.MockService, baseURL string) *Hub {
+	cfg := config.Config{}
+	cfg.Hub.BaseURL = baseURL
+
+	logCfg := config.Log{}
+	logCfg.Converted.Writer = os.Stderr
+	logCfg.Converted.Prefix = "[testing] "
+
+	return NewHub(service, &http.Client{Timeout: time.Second}, cfg, log.WithStd(logCfg))
+}
+
+func TestHub_subscribe(t *testing.T) {
+	topics := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v2/published/user/token/atom" {
+			http.NotFound(w, r)
+			return
+		}
+
+		w.Write([]byte("<feed></feed>"))
+	}))
+	defer topics.Close()
+
+	tests := []struct {
+		name    string
+		topic   string
+		echo    bool
+		modes   []string
+		updated bool
+		wantErr bool
+	}{
+		{name: "subscribe", topic: "/api/v2/published/user/token/atom", echo: true, modes: []string{"subscribe"}, updated: true},
+		{name: "missing topic", topic: "/api/v2/published/user/token/rss", echo: true, modes: []string{"denied"}},
+		{name: "foreign topic", topic: "http://example.com/api/v2/published/user/token/atom", echo: true, modes: []string{"denied"}},
+		{name: "no echo", topic: "/api/v2/published/user/token/atom", modes: []string{"subscribe"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			service := mock_repo.NewMockService(ctrl)
+			repo := mock_repo.NewMockHubSubscription(ctrl)
+			service.EXPECT().HubSubscriptionRepo().Return(repo).AnyTimes()
+
+			subscriber := &hubSubscriber{echo: tt.echo}
+			callback := httptest.NewServer(subscriber)
+			defer callback.Close()
+
+			topic := tt.topic
+			if strings.HasPrefix(topic, "/") {
+				topic = topics.URL + topic
+			}
+
+			s := content.HubSubscription{Topic: topic, Callback: callback.URL + "/callback", Secret: "secret"}
+
+			if tt.updated {
+				repo.EXPECT().Update(gomock.Any()).DoAndReturn(func(got content.HubSubscription) error {
+					if got.Topic != s.Topic || got.Callback != s.Callback || got.Secret != s.Secret {
+						t.Errorf("Hub.subscribe() subscription = %v, want %v", got, s)
+					}
+
+					if d := time.Until(got.LeaseExpiry); d < 59*time.Minute || d > time.Hour {
+						t.Errorf("Hub.subscribe() lease expiry = %v", got.LeaseExpiry)
+					}
+
+					return nil
+				})
+			}
+
+			h := newTestHub(service, topics.URL+"/api")
+			if err := h.subscribe(s, time.Hour); (err != nil) != tt.wantErr {
+				t.Errorf("Hub.subscribe() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if strings.Join(subscriber.modes, ",") != strings.Join(tt.modes, ",") {
+				t.Errorf("Hub.subscribe() modes = %v, want %v", subscriber.modes, tt.modes)
+			}
+
+			if _, ok := h.digests[s.Topic]; ok != tt.updated {
+				t.Errorf("Hub.subscribe() topic digest recorded = %v, want %v", ok, tt.updated)
+			}
+		})
+	}
+}
+
+func TestHub_unsubscribe(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	service := mock_repo.NewMockService(ctrl)
+	repo := mock_repo.NewMockHubSubscription(ctrl)
+	service.EXPECT().HubSubscriptionRepo().Return(repo).AnyTimes()
+
+	subscriber := &hubSubscriber{echo: true}
+	callback := httptest.NewServer(subscriber)
+	defer callback.Close()
+
+	s := content.HubSubscription{Topic: "http://example.com/api/v2/published/user/token/atom", Callback: callback.URL}
+	repo.EXPECT().Delete(s).Return(nil)
+
+	if err := newTestHub(service, "http://example.com/api").unsubscribe(s); err != nil {
+		t.Errorf("Hub.unsubscribe() error = %v", err)
+	}
+
+	if len(subscriber.modes) != 1 || subscriber.modes[0] != "unsubscribe" {
+		t.Errorf("Hub.unsubscribe() modes = %v", subscriber.modes)
+	}
+}
+
+func TestHub_distribute(t *testing.T) {
+	var mu sync.Mutex
+	feed := "<feed>1</feed>"
+
+	topics := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		w.Header().Set("Content-Type", "application/atom+xml")
+		w.Write([]byte(feed))
+	}))
+	defer topics.Close()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	service := mock_repo.NewMockService(ctrl)
+	repo := mock_repo.NewMockHubSubscription(ctrl)
+	service.EXPECT().HubSubscriptionRepo().Return(repo).AnyTimes()
+
+	signed := &hubSubscriber{}
+	signedServer := httptest.NewServer(signed)
+	defer signedServer.Close()
+
+	gone := &hubSubscriber{status: http.StatusGone}
+	goneServer := httptest.NewServer(gone)
+	defer goneServer.Close()
+
+	expired := &hubSubscriber{}
+	expiredServer := httptest.NewServer(expired)
+	defer expiredServer.Close()
+
+	topic := topics.URL + "/api/v2/syndication/user/token/atom/favorite"
+	lease := time.Now().Add(time.Hour)
+	subscriptions := []content.HubSubscription{
+		{Topic: topic, Callback: signedServer.URL, Secret: "secret", LeaseExpiry: lease},
+		{Topic: topic, Callback: goneServer.URL, LeaseExpiry: lease},
+		{Topic: topic, Callback: expiredServer.URL, LeaseExpiry: time.Now().Add(-time.Minute)},
+	}
+
+	repo.EXPECT().DeleteExpired(gomock.Any()).Return(nil).Times(3)
+	repo.EXPECT().All().Return(subscriptions, nil).Times(3)
+	repo.EXPECT().Delete(subscriptions[1]).Return(nil)
+
+	h := newTestHub(service, topics.URL+"/api")
+
+	// The first check only records the digest, the second finds no change
+	for i := 0; i < 2; i++ {
+		if err := h.distribute(); err != nil {
+			t.Fatalf("Hub.distribute() error = %v", err)
+		}
+	}
+
+	if len(signed.bodies) != 0 {
+		t.Fatalf("Hub.distribute() pushed unchanged topic: %v", signed.bodies)
+	}
+
+	mu.Lock()
+	feed = "<feed>2</feed>"
+	mu.Unlock()
+
+	if err := h.distribute(); err != nil {
+		t.Fatalf("Hub.distribute() error = %v", err)
+	}
+
+	if len(signed.bodies) != 1 || signed.bodies[0] != feed {
+		t.Fatalf("Hub.distribute() bodies = %v, want %s", signed.bodies, feed)
+	}
+
+	header := signed.header[0]
+	if header.Get("Content-Type") != "application/atom+xml" {
+		t.Errorf("Hub.distribute() content type = %s", header.Get("Content-Type"))
+	}
+
+	if sig := header.Get("X-Hub-Signature"); sig != "sha256="+HubSignature("secret", []byte(feed)) {
+		t.Errorf("Hub.distribute() signature = %s", sig)
+	}
+
+	links := strings.Join(header["Link"], ", ")
+	if !strings.Contains(links, `<`+topics.URL+`/api/v2/hub>; rel="hub"`) || !strings.Contains(links, `<`+topic+`>; rel="self"`) {
+		t.Errorf("Hub.distribute() links = %s", links)
+	}
+
+	if len(gone.bodies) != 1 || gone.header[0].Get("X-Hub-Signature") != "" {
+		t.Errorf("Hub.distribute() unsigned push = %v, %v", gone.bodies, gone.header)
+	}
+
+	if len(expired.bodies) != 0 {
+		t.Errorf("Hub.distribute() pushed to expired subscriber: %v", expired.bodies)
+	}
+}
+
+func TestHub_Check(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	service := mock_repo.NewMockService(ctrl)
+	repo := mock_repo.NewMockHubSubscription(ctrl)
+	service.EXPECT().HubSubscriptionRepo().Return(repo).AnyTimes()
+
+	done := make(chan struct{}, 2)
+	repo.EXPECT().DeleteExpired(gomock.Any()).Return(nil)
+	repo.EXPECT().All().DoAndReturn(func() ([]content.HubSubscription, error) {
+		done <- struct{}{}
+		return nil, nil
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	h := newTestHub(service, "http://example.com/api")
+	h.config.Hub.Converted.CheckInterval = time.Hour
+	h.delay = 20 * time.Millisecond
+	h.Start(ctx)
+
+	// Checks requested in quick succession are merged into a single one
+	for i := 0; i < 5; i++ {
+		h.Check()
+		time.Sleep(time.Millisecond)
+	}
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatalf("Hub.Check() did not distribute")
+	}
+
+	select {
+	case <-done:
+		t.Fatalf("Hub.Check() distributed more than once")
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestHubURL(t *testing.T) {
+	tests := []struct {
+		topic string
+		want  string
+	}{
+		{"http://example.com/api/v2/published/user/token/atom", "http://example.com/api/v2/hub"},
+		{"https://example.com/reader/api/v2/syndication/user/token/json/tag/1?unreadOnly", "https://example.com/reader/api/v2/hub"},
+		{"http://example.com/feed.xml", ""},
+	}
+
+	for _, tt := range tests {
+		if got := HubURL(tt.topic); got != tt.want {
+			t.Errorf("HubURL(%s) = %v, want %v", tt.topic, got, tt.want)
+		}
+	}
+}
@@ -15,15 +15,12 @@ import (
 )
 
 type Hubbub struct {
-	service       repo.Service
-	config        config.Config
-	endpoint      string
-	subscribe     chan content.Subscription
-	unsubscribe   chan content.Subscription
-	client        *http.Client
-	log           log.Log
-	subscriptions []content.Subscription
-	feedManager   *FeedManager
+	service     repo.Service
+	config      config.Config
+	endpoint    string
+	client      *http.Client
+	log         log.Log
+	feedManager *FeedManager
 }
 
 type SubscriptionError struct {
@@ -31,6 +28,25 @@ type SubscriptionError struct {
 	Subscription content.Subscription
 }
 
+// subscriptionRetry tracks the attempts to subscribe again after a
+// subscription has failed, or has been denied by the hub.
+type subscriptionRetry struct {
+	attempts int
+	next     time.Time
+}
+
+const (
+	// How often the subscriptions are checked for renewal.
+	renewalCheckInterval = 15 * time.Minute
+	// The minimum time before the lease expiry at which a subscription is
+	// renewed.
+	minRenewalMargin = 2 * renewalCheckInterval
+	// The delay before a failed subscription is first retried, which is
+	// doubled with each attempt, up to maxRetryBackoff.
+	minRetryBackoff = renewalCheckInterval
+	maxRetryBackoff = 24 * time.Hour
+)
+
 var (
 	ErrNotConfigured = errors.New("Hubbub callback URL is not set")
 	ErrNoFeedHubLink = errors.New("Feed does not contain a hub link")
@@ -49,7 +65,6 @@ func NewHubbub(
 	return &Hubbub{
 		service: service,
 		config:  c, log: l, endpoint: endpoint,
-		subscribe: make(chan content.Subscription), unsubscribe: make(chan content.Subscription),
 		client:      NewTimeoutClient(c.Timeout.Converted.Connect, c.Timeout.Converted.ReadWrite),
 		feedManager: feedManager,
 	}
@@ -87,6 +102,10 @@ func (h *Hubbub) Subscribe(f content.Feed) error {
 	s.FeedID = f.ID
 	s.SubscriptionFailure = true
 
+	if s.Secret, err = randomToken(); err != nil {
+		return errors.WithMessage(err, "generating subscription secret")
+	}
+
 	if err = repo.Update(s); err != nil {
 		return errors.WithMessage(err, "updating subscription during subscribe")
 	}
@@ -131,6 +150,9 @@ func (h *Hubbub) Unsubscribe(f content.Feed) error {
 	return nil
 }
 
+// InitSubscriptions renews all subscriptions, and keeps renewing them before
+// their leases run out. Failed and denied subscriptions are retried with an
+// increasing backoff.
 func (h *Hubbub) InitSubscriptions() error {
 	subscriptions, err := h.service.SubscriptionRepo().All()
 	if err != nil {
@@ -139,48 +161,42 @@ func (h *Hubbub) InitSubscriptions() error {
 
 	h.log.Infof("Initializing %d hubbub subscriptions", len(subscriptions))
 
-	feedRepo := h.service.FeedRepo()
 	go func() {
 		for _, s := range subscriptions {
-			f, err := feedRepo.Get(s.FeedID, content.User{})
+			h.renew(s)
+		}
+
+		ticker := time.NewTicker(renewalCheckInterval)
+		defer ticker.Stop()
+
+		retries := map[content.FeedID]subscriptionRetry{}
+		for range ticker.C {
+			subscriptions, err := h.service.SubscriptionRepo().All()
 			if err != nil {
-				h.log.Printf("Error getting subscription feed: %+v\n", err)
+				h.log.Printf("Error getting subscriptions: %+v", err)
 				continue
 			}
 
-			h.subscription(s, f, true)
-		}
-	}()
+			failed := map[content.FeedID]bool{}
+			for _, s := range subscriptions {
+				switch {
+				case s.SubscriptionFailure:
+					failed[s.FeedID] = true
 
-	go func() {
-		after := time.After(h.config.FeedManager.Converted.UpdateInterval)
-		subscriptions := []content.Subscription{}
-
-		for {
-			select {
-			case s := <-h.subscribe:
-				subscriptions = append(subscriptions, s)
-			case s := <-h.unsubscribe:
-				filtered := []content.Subscription{}
-				for i := range subscriptions {
-					if subscriptions[i] != s {
-						filtered = append(filtered, subscriptions[i])
+					if retryDue(retries, s, time.Now()) {
+						h.log.Infof("Retrying failed subscription to %s", s)
+						h.renew(s)
 					}
+				case renewalDue(s):
+					h.renew(s)
 				}
+			}
 
-				subscriptions = filtered
-			case <-after:
-				for _, s := range subscriptions {
-					if s.VerificationTime.Add(time.Duration(s.LeaseDuration)).Before(time.Now().Add(-30 * time.Minute)) {
-						f, err := feedRepo.Get(s.FeedID, content.User{})
-						if err != nil {
-							h.log.Printf("Error getting subscription feed: %+v\n", err)
-							continue
-						}
-
-						h.log.Infof("Renewing subscription to %s\n", s)
-						h.subscription(s, f, true)
-					}
+			// Verified and removed subscriptions start over when they fail
+			// again.
+			for id := range retries {
+				if !failed[id] {
+					delete(retries, id)
 				}
 			}
 		}
@@ -189,6 +205,101 @@ func (h *Hubbub) InitSubscriptions() error {
 	return nil
 }
 
+// Active reports whether the hub of the feed can be relied upon for its
+// updates, which is the case when the subscription is verified, its lease
+// hasn't run out, and the hub hasn't been silent for too long.
+func (h *Hubbub) Active(f content.Feed) bool {
+	s, err := h.service.SubscriptionRepo().Get(f)
+	if err != nil {
+		if !content.IsNoContent(err) {
+			h.log.Printf("Error getting feed %s subscription: %+v", f, err)
+		}
+		return false
+	}
+
+	return s.State(h.config.Hubbub.Converted.SilenceTimeout) == content.SubscriptionStateActive
+}
+
+// renew subscribes to the hub again, generating a secret for subscriptions
+// created without one. The subscriptions of dead feeds are left alone.
+func (h *Hubbub) renew(s content.Subscription) {
+	f, err := h.service.FeedRepo().Get(s.FeedID, content.User{})
+	if err != nil {
+		h.log.Printf("Error getting subscription feed: %+v", err)
+		return
+	}
+
+	if f.Dead {
+		return
+	}
+
+	if s.Secret == "" {
+		if s.Secret, err = randomToken(); err != nil {
+			h.log.Printf("Error generating subscription secret: %+v", err)
+			return
+		}
+
+		if err = h.service.SubscriptionRepo().Update(s); err != nil {
+			h.log.Printf("Error updating subscription %s: %+v", s, err)
+			return
+		}
+	}
+
+	h.log.Infof("Renewing subscription to %s", s)
+	h.subscription(s, f, true)
+}
+
+// renewalDue reports whether the lease of a verified subscription is about to
+// run out. Subscriptions are renewed during the last tenth of their lease.
+func renewalDue(s content.Subscription) bool {
+	if s.SubscriptionFailure || s.LeaseDuration <= 0 {
+		return false
+	}
+
+	margin := time.Duration(s.LeaseDuration) / 10
+	if margin < minRenewalMargin {
+		margin = minRenewalMargin
+	}
+
+	return time.Until(s.LeaseExpiry()) < margin
+}
+
+// retryDue reports whether a failed subscription is to be retried at the
+// given time, recording the attempt. A newly failed subscription is first
+// retried after minRetryBackoff, leaving the hub time to verify it, if it
+// is still pending.
+func retryDue(retries map[content.FeedID]subscriptionRetry, s content.Subscription, now time.Time) bool {
+	r, ok := retries[s.FeedID]
+	if !ok {
+		retries[s.FeedID] = subscriptionRetry{next: now.Add(minRetryBackoff)}
+		return false
+	}
+
+	if now.Before(r.next) {
+		return false
+	}
+
+	r.attempts++
+	r.next = now.Add(retryBackoff(r.attempts))
+	retries[s.FeedID] = r
+
+	return true
+}
+
+// retryBackoff returns the delay after the given number of retries.
+func retryBackoff(attempts int) time.Duration {
+	backoff := minRetryBackoff
+	for i := 0; i < attempts && backoff < maxRetryBackoff; i++ {
+		backoff *= 2
+	}
+
+	if backoff > maxRetryBackoff {
+		backoff = maxRetryBackoff
+	}
+
+	return backoff
+}
+
 func (h *Hubbub) subscription(s content.Subscription, f content.Feed, subscribe bool) {
 	var err error
 
@@ -204,6 +315,9 @@ func (h *Hubbub) subscription(s content.Subscription, f content.Feed, subscribe
 		body.Set("hub.mode", "unsubscribe")
 	}
 	body.Set("hub.topic", f.Link)
+	if subscribe && s.Secret != "" {
+		body.Set("hub.secret", s.Secret)
+	}
 
 	buf := pool.Buffer.Get()
 	defer pool.Buffer.Put(buf)
@@ -217,17 +331,15 @@ func (h *Hubbub) subscription(s content.Subscription, f content.Feed, subscribe
 
 	if err != nil {
 		err = SubscriptionError{error: err, Subscription: s}
-	} else if resp.StatusCode != 202 {
-		err = SubscriptionError{error: errors.New("Expected response status 202, got " + resp.Status), Subscription: s}
-	}
+	} else {
+		resp.Body.Close()
 
-	if err == nil {
-		if subscribe {
-			h.subscribe <- s
-		} else {
-			h.unsubscribe <- s
+		if resp.StatusCode != 202 {
+			err = SubscriptionError{error: errors.New("Expected response status 202, got " + resp.Status), Subscription: s}
 		}
-	} else {
+	}
+
+	if err != nil {
 		f.SubscribeError = fmt.Sprintf("%s: %s", time.Now().Format(time.UnixDate), err.Error())
 		h.log.Printf("Error subscribing to hub feed '%s': %s\n", f, err)
 
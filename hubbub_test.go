@@ -1,5 +1,61 @@
 package readeef
 
+import (
+	"testing"
+	"time"
+
+	"github.com/urandom/readeef/content"
+)
+
+func Test_retryDue(t *testing.T) {
+	retries := map[content.FeedID]subscriptionRetry{}
+	failed := content.Subscription{FeedID: 1, SubscriptionFailure: true}
+	now := time.Now()
+
+	tests := []struct {
+		name  string
+		after time.Duration
+		want  bool
+	}{
+		{"newly failed", 0, false},
+		{"before the first retry", minRetryBackoff / 2, false},
+		{"first retry", minRetryBackoff, true},
+		{"after the first retry", minRetryBackoff + time.Minute, false},
+		{"before the second retry", minRetryBackoff + 2*minRetryBackoff - time.Minute, false},
+		{"second retry", minRetryBackoff + 2*minRetryBackoff, true},
+		{"third retry", minRetryBackoff + 2*minRetryBackoff + 4*minRetryBackoff, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := retryDue(retries, failed, now.Add(tt.after)); got != tt.want {
+				t.Errorf("retryDue() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if got := retries[failed.FeedID].attempts; got != 3 {
+		t.Errorf("retryDue() attempts = %d, want 3", got)
+	}
+}
+
+func Test_retryBackoff(t *testing.T) {
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{0, minRetryBackoff},
+		{1, 2 * minRetryBackoff},
+		{3, 8 * minRetryBackoff},
+		{10, maxRetryBackoff},
+		{100, maxRetryBackoff},
+	}
+	for _, tt := range tests {
+		if got := retryBackoff(tt.attempts); got != tt.want {
+			t.Errorf("retryBackoff(%d) = %v, want %v", tt.attempts, got, tt.want)
+		}
+	}
+}
+
 /*
 
 import (
@@ -4,32 +4,111 @@ import (
 	"bytes"
 	"encoding/xml"
 	"io"
+	"strings"
 	"time"
 )
 
 type atomFeed struct {
-	XMLName     xml.Name   `xml:"feed"`
-	Title       string     `xml:"title"`
-	Description string     `xml:"description"`
-	Link        atomLink   `xml:"link"`
-	Image       rssImage   `xml:"image"`
-	Items       []atomItem `xml:"entry"`
+	XMLName     xml.Name     `xml:"feed"`
+	Title       string       `xml:"title"`
+	Description string       `xml:"description"`
+	Link        atomLink     `xml:"link"`
+	Image       rssImage     `xml:"image"`
+	Authors     []atomPerson `xml:"author"`
+	Items       []atomItem   `xml:"entry"`
 }
 
 type atomItem struct {
-	XMLName     xml.Name   `xml:"entry"`
-	Id          string     `xml:"id"`
-	Title       string     `xml:"title"`
-	Description rssContent `xml:"summary"`
-	Content     rssContent `xml:"content"`
-	Link        atomLink   `xml:"link"`
-	Date        string     `xml:"updated"`
-	PubDate     string     `xml:"published"`
+	XMLName     xml.Name       `xml:"entry"`
+	Id          string         `xml:"id"`
+	Title       string         `xml:"title"`
+	Description rssContent     `xml:"summary"`
+	Content     rssContent     `xml:"content"`
+	Links       []atomLink     `xml:"link"`
+	Date        string         `xml:"updated"`
+	PubDate     string         `xml:"published"`
+	Authors     []atomPerson   `xml:"author"`
+	Categories  []atomCategory `xml:"category"`
+	mediaElements
+}
+
+type atomPerson struct {
+	Name  string `xml:"name"`
+	URI   string `xml:"uri"`
+	Email string `xml:"email"`
+}
+
+type atomCategory struct {
+	Term  string `xml:"term,attr"`
+	Label string `xml:"label,attr"`
 }
 
 type atomLink struct {
-	Rel  string `xml:"rel,attr,omitempty"`
-	Href string `xml:"href,attr"`
+	Rel    string `xml:"rel,attr,omitempty"`
+	Href   string `xml:"href,attr"`
+	Type   string `xml:"type,attr,omitempty"`
+	Title  string `xml:"title,attr,omitempty"`
+	Length string `xml:"length,attr,omitempty"`
+}
+
+// link returns the alternate link of the entry, or the first one if there
+// are no links with such a relation.
+func (i atomItem) link() string {
+	for _, l := range i.Links {
+		if l.Rel == "" || l.Rel == "alternate" {
+			return l.Href
+		}
+	}
+
+	if len(i.Links) > 0 {
+		return i.Links[0].Href
+	}
+
+	return ""
+}
+
+func (i atomItem) enclosures() []Enclosure {
+	enclosures := []Enclosure{}
+	for _, l := range i.Links {
+		if l.Rel == "enclosure" {
+			enclosures = append(enclosures, Enclosure{
+				Link: l.Href, Type: l.Type, Title: l.Title, Length: parseInt64(l.Length),
+			})
+		}
+	}
+
+	return uniqueEnclosures(append(enclosures, i.mediaElements.enclosures()...))
+}
+
+func (i atomItem) categories() []string {
+	categories := make([]string, 0, len(i.Categories))
+	for _, c := range i.Categories {
+		if c.Label != "" {
+			categories = append(categories, c.Label)
+		} else {
+			categories = append(categories, c.Term)
+		}
+	}
+
+	return uniqueCategories(categories)
+}
+
+// atomAuthor returns the names of the persons, falling back to their email
+// or uri.
+func atomAuthor(persons []atomPerson) string {
+	names := make([]string, 0, len(persons))
+	for _, p := range persons {
+		switch {
+		case strings.TrimSpace(p.Name) != "":
+			names = append(names, p.Name)
+		case strings.TrimSpace(p.Email) != "":
+			names = append(names, p.Email)
+		default:
+			names = append(names, p.URI)
+		}
+	}
+
+	return joinAuthors(names)
 }
 
 func ParseAtom(b []byte) (Feed, error) {
@@ -52,10 +131,19 @@ func ParseAtom(b []byte) (Feed, error) {
 			rss.Image.Width, rss.Image.Height},
 	}
 
+	feedAuthor := atomAuthor(rss.Authors)
+
 	var lastValidDate time.Time
 	for _, i := range rss.Items {
-		article := Article{Title: i.Title, Link: i.Link.Href, Guid: i.Id}
+		article := Article{Title: i.Title, Link: i.link(), Guid: i.Id}
 		article.Description = getLargerContent(i.Content, i.Description)
+		article.Categories = i.categories()
+		article.Enclosures = i.enclosures()
+
+		// Entries without an author inherit the one of the feed
+		if article.Author = atomAuthor(i.Authors); article.Author == "" {
+			article.Author = feedAuthor
+		}
 
 		var err error
 		if i.PubDate != "" {
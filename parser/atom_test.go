@@ -17,6 +17,8 @@ func TestParseAtom(t *testing.T) {
 		{"single publish date", []byte(singlePubAtomXML), singleAtomFeed, false},
 		{"single no date", []byte(singleNoDateAtomXML), singleNoDateAtomFeed, false},
 		{"multi last no date", []byte(multiLastNoDateAtomXML), multiLastNoDateAtomFeed, false},
+		{"enclosures", []byte(enclosuresAtomXML), enclosuresAtomFeed, false},
+		{"authors and categories", []byte(authorsAtomXML), authorsAtomFeed, false},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -43,6 +45,7 @@ var (
 				Guid:        "urn:uuid:1225c695-cfb8-4ebb-aaaa-80da344efa6a",
 				Description: "Some text.",
 				Date:        time.Date(2003, time.December, 13, 18, 30, 02, 0, time.UTC),
+				Author:      "John Doe",
 			},
 		},
 	}
@@ -57,6 +60,7 @@ var (
 				Guid:        "urn:uuid:1225c695-cfb8-4ebb-aaaa-80da344efa6a",
 				Description: "Some text.",
 				Date:        time.Unix(0, 0),
+				Author:      "John Doe",
 			},
 		},
 	}
@@ -71,6 +75,7 @@ var (
 				Guid:        "urn:uuid:1225c695-cfb8-4ebb-aaaa-80da344efa6a",
 				Description: "Some text.",
 				Date:        time.Date(2003, time.December, 13, 18, 30, 02, 0, time.UTC),
+				Author:      "John Doe",
 			},
 			{
 				Title:       "Atom-Powered Robots Run Amok 2",
@@ -78,6 +83,67 @@ var (
 				Guid:        "urn:uuid:1225c695-cfb8-4ebb-aaaa-80da344efa6a 2",
 				Description: "Some text. 2",
 				Date:        time.Date(2003, time.December, 13, 18, 30, 03, 0, time.UTC),
+				Author:      "John Doe",
+			},
+		},
+	}
+
+	enclosuresAtomFeed = Feed{
+		Title:    "Channel",
+		SiteLink: "http://example.org/",
+		Articles: []Article{
+			{
+				Title:       "Video",
+				Link:        "http://example.org/watch?v=1",
+				Guid:        "urn:video:1",
+				Description: "Video description",
+				Date:        time.Date(2003, time.December, 13, 18, 30, 02, 0, time.UTC),
+				Enclosures: []Enclosure{
+					{
+						Link:   "http://example.org/audio.ogg",
+						Type:   "audio/ogg",
+						Medium: "audio",
+						Title:  "Audio",
+						Length: 1024,
+					},
+					{
+						Link:   "http://example.org/v/1",
+						Type:   "application/x-shockwave-flash",
+						Title:  "Video",
+						Width:  640,
+						Height: 390,
+					},
+					{
+						Link:   "http://example.org/vi/1/hqdefault.jpg",
+						Medium: "image",
+						Width:  480,
+						Height: 360,
+					},
+				},
+			},
+		},
+	}
+
+	authorsAtomFeed = Feed{
+		Title:    "Blog",
+		SiteLink: "http://example.org/",
+		Articles: []Article{
+			{
+				Title:       "Post 1",
+				Link:        "http://example.org/post-1",
+				Guid:        "urn:post:1",
+				Description: "First",
+				Date:        time.Date(2003, time.December, 13, 18, 30, 02, 0, time.UTC),
+				Author:      "Jane Doe, richard@example.org",
+				Categories:  []string{"Go", "Web feeds"},
+			},
+			{
+				Title:       "Post 2",
+				Link:        "http://example.org/post-2",
+				Guid:        "urn:post:2",
+				Description: "Second",
+				Date:        time.Date(2003, time.December, 14, 18, 30, 02, 0, time.UTC),
+				Author:      "Blog Team",
 			},
 		},
 	}
@@ -171,5 +237,52 @@ const (
 		<summary>Some text. 2</summary>
 	</entry>
 </feed>
+`
+	enclosuresAtomXML = `
+<?xml version="1.0" encoding="utf-8"?>
+<feed xmlns="http://www.w3.org/2005/Atom" xmlns:media="http://search.yahoo.com/mrss/">
+	<title>Channel</title>
+	<link href="http://example.org/"/>
+	<entry>
+		<title>Video</title>
+		<link rel="self" href="http://example.org/entry/1"/>
+		<link rel="alternate" href="http://example.org/watch?v=1"/>
+		<link rel="enclosure" type="audio/ogg" title="Audio" length="1024" href="http://example.org/audio.ogg"/>
+		<id>urn:video:1</id>
+		<updated>2003-12-13T18:30:02Z</updated>
+		<summary>Video description</summary>
+		<media:group>
+			<media:title>Video</media:title>
+			<media:content url="http://example.org/v/1" type="application/x-shockwave-flash" width="640" height="390"/>
+			<media:thumbnail url="http://example.org/vi/1/hqdefault.jpg" width="480" height="360"/>
+		</media:group>
+	</entry>
+</feed>
+`
+	authorsAtomXML = `
+<?xml version="1.0" encoding="utf-8"?>
+<feed xmlns="http://www.w3.org/2005/Atom">
+	<title>Blog</title>
+	<link href="http://example.org/"/>
+	<author><name>Blog Team</name></author>
+	<entry>
+		<title>Post 1</title>
+		<link href="http://example.org/post-1"/>
+		<id>urn:post:1</id>
+		<updated>2003-12-13T18:30:02Z</updated>
+		<summary>First</summary>
+		<author><name>Jane Doe</name></author>
+		<author><email>richard@example.org</email></author>
+		<category term="go" label="Go"/>
+		<category term="Web feeds"/>
+	</entry>
+	<entry>
+		<title>Post 2</title>
+		<link href="http://example.org/post-2"/>
+		<id>urn:post:2</id>
+		<updated>2003-12-14T18:30:02Z</updated>
+		<summary>Second</summary>
+	</entry>
+</feed>
 `
 )
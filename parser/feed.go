@@ -20,6 +20,22 @@ type Article struct {
 	Link        string
 	Guid        string
 	Date        time.Time
+	Author      string
+	Categories  []string
+	Enclosures  []Enclosure
+}
+
+// Enclosure is a media object attached to an article, such as an RSS
+// enclosure, an Atom enclosure link, or a Media RSS content or thumbnail.
+type Enclosure struct {
+	Link     string
+	Type     string
+	Medium   string
+	Title    string
+	Length   int64
+	Duration time.Duration
+	Width    int
+	Height   int
 }
 
 type Image struct {
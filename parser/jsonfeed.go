@@ -0,0 +1,196 @@
+package parser
+
+import (
+	"bytes"
+	"encoding/json"
+	"html"
+	"io"
+	"strings"
+	"time"
+)
+
+type jsonFeed struct {
+	Version     string         `json:"version"`
+	Title       string         `json:"title"`
+	HomePageURL string         `json:"home_page_url"`
+	Description string         `json:"description"`
+	Icon        string         `json:"icon"`
+	Favicon     string         `json:"favicon"`
+	Author      *jsonAuthor    `json:"author"`
+	Authors     []jsonAuthor   `json:"authors"`
+	Hubs        []jsonHub      `json:"hubs"`
+	Items       []jsonFeedItem `json:"items"`
+}
+
+type jsonFeedItem struct {
+	ID            json.RawMessage      `json:"id"`
+	URL           string               `json:"url"`
+	ExternalURL   string               `json:"external_url"`
+	Title         string               `json:"title"`
+	ContentHTML   string               `json:"content_html"`
+	ContentText   string               `json:"content_text"`
+	Summary       string               `json:"summary"`
+	DatePublished string               `json:"date_published"`
+	DateModified  string               `json:"date_modified"`
+	Author        *jsonAuthor          `json:"author"`
+	Authors       []jsonAuthor         `json:"authors"`
+	Tags          []string             `json:"tags"`
+	Attachments   []jsonFeedAttachment `json:"attachments"`
+}
+
+type jsonAuthor struct {
+	Name string `json:"name"`
+	URL  string `json:"url"`
+}
+
+type jsonHub struct {
+	Type string `json:"type"`
+	URL  string `json:"url"`
+}
+
+type jsonFeedAttachment struct {
+	URL      string  `json:"url"`
+	MimeType string  `json:"mime_type"`
+	Title    string  `json:"title"`
+	Size     int64   `json:"size_in_bytes"`
+	Duration float64 `json:"duration_in_seconds"`
+}
+
+const jsonFeedVersionPrefix = "https://jsonfeed.org/version/"
+
+// ParseJSONFeed parses a JSON Feed document, version 1 or 1.1.
+func ParseJSONFeed(b []byte) (Feed, error) {
+	var f Feed
+	var jf jsonFeed
+
+	if trimmed := bytes.TrimSpace(b); len(trimmed) == 0 || trimmed[0] != '{' {
+		return f, formatError("not a json feed")
+	}
+
+	if err := json.Unmarshal(b, &jf); err != nil {
+		return f, formatError("decoding json feed: " + err.Error())
+	}
+
+	if !strings.HasPrefix(jf.Version, jsonFeedVersionPrefix) {
+		return f, formatError("unknown json feed version " + jf.Version)
+	}
+
+	f = Feed{
+		Title:       jf.Title,
+		Description: jf.Description,
+		SiteLink:    jf.HomePageURL,
+		HubLink:     jsonHubLink(jf.Hubs),
+	}
+
+	if jf.Icon != "" {
+		f.Image.Url = jf.Icon
+	} else {
+		f.Image.Url = jf.Favicon
+	}
+
+	feedAuthor := jsonAuthorName(jf.Author, jf.Authors)
+
+	var lastValidDate time.Time
+	for _, i := range jf.Items {
+		article := Article{Title: i.Title, Link: i.URL, Guid: jsonFeedID(i.ID)}
+
+		if article.Link == "" {
+			article.Link = i.ExternalURL
+		}
+
+		switch {
+		case i.ContentHTML != "":
+			article.Description = i.ContentHTML
+		case i.ContentText != "":
+			article.Description = html.EscapeString(i.ContentText)
+		default:
+			article.Description = i.Summary
+		}
+
+		if article.Author = jsonAuthorName(i.Author, i.Authors); article.Author == "" {
+			article.Author = feedAuthor
+		}
+
+		article.Categories = uniqueCategories(i.Tags)
+
+		enclosures := make([]Enclosure, 0, len(i.Attachments))
+		for _, a := range i.Attachments {
+			enclosures = append(enclosures, Enclosure{
+				Link:     a.URL,
+				Type:     a.MimeType,
+				Title:    a.Title,
+				Length:   a.Size,
+				Duration: time.Duration(a.Duration * float64(time.Second)),
+			})
+		}
+		article.Enclosures = uniqueEnclosures(enclosures)
+
+		var err error
+		if i.DatePublished != "" {
+			article.Date, err = parseDate(i.DatePublished)
+		} else if i.DateModified != "" {
+			article.Date, err = parseDate(i.DateModified)
+		} else {
+			err = io.EOF
+		}
+
+		if err == nil {
+			lastValidDate = article.Date.Add(time.Second)
+		} else if lastValidDate.IsZero() {
+			article.Date = unknownTime
+		} else {
+			article.Date = lastValidDate
+		}
+
+		f.Articles = append(f.Articles, article)
+	}
+
+	return f, nil
+}
+
+// jsonFeedID returns the item id as a string, since some version 1 feeds
+// use numeric ids.
+func jsonFeedID(raw json.RawMessage) string {
+	var id string
+	if err := json.Unmarshal(raw, &id); err == nil {
+		return id
+	}
+
+	var num json.Number
+	if err := json.Unmarshal(raw, &num); err == nil {
+		return num.String()
+	}
+
+	return ""
+}
+
+func jsonAuthorName(author *jsonAuthor, authors []jsonAuthor) string {
+	if len(authors) == 0 && author != nil {
+		authors = []jsonAuthor{*author}
+	}
+
+	names := make([]string, 0, len(authors))
+	for _, a := range authors {
+		if a.Name != "" {
+			names = append(names, a.Name)
+		} else if a.URL != "" {
+			names = append(names, a.URL)
+		}
+	}
+
+	return strings.Join(names, ", ")
+}
+
+func jsonHubLink(hubs []jsonHub) string {
+	for _, h := range hubs {
+		if strings.EqualFold(h.Type, "websub") || strings.EqualFold(h.Type, "pubsubhubbub") {
+			return h.URL
+		}
+	}
+
+	if len(hubs) > 0 {
+		return hubs[0].URL
+	}
+
+	return ""
+}
@@ -0,0 +1,144 @@
+package parser
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseJSONFeed(t *testing.T) {
+	tests := []struct {
+		name    string
+		b       []byte
+		want    Feed
+		wantErr bool
+	}{
+		{"version 1", []byte(singleJSONFeedV1), singleJSONFeedV1Feed, false},
+		{"version 1.1", []byte(multiJSONFeedV11), multiJSONFeedV11Feed, false},
+		{"xml", []byte(singleAtomXML), Feed{}, true},
+		{"unknown version", []byte(`{"version": "1", "items": []}`), Feed{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseJSONFeed(tt.b)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseJSONFeed() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseJSONFeed() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+var (
+	singleJSONFeedV1Feed = Feed{
+		Title:       "My Example Feed",
+		Description: "An example",
+		SiteLink:    "https://example.org/",
+		Image:       Image{Url: "https://example.org/icon.png"},
+		Articles: []Article{
+			{
+				Title:       "First post",
+				Link:        "https://example.org/initial-post",
+				Guid:        "2",
+				Description: "&lt;p&gt;Hello, world!&lt;/p&gt;",
+				Date:        time.Date(2017, time.May, 17, 8, 2, 12, 0, time.FixedZone("", -7*3600)),
+				Author:      "John Doe",
+			},
+		},
+	}
+
+	multiJSONFeedV11Feed = Feed{
+		Title:    "Podcast",
+		SiteLink: "https://example.org/",
+		HubLink:  "https://hub.example.org/",
+		Articles: []Article{
+			{
+				Title:       "Episode 1",
+				Link:        "https://example.org/episode-1",
+				Guid:        "https://example.org/episode-1",
+				Description: "<p>The first episode</p>",
+				Date:        time.Date(2020, time.August, 7, 10, 0, 0, 0, time.UTC),
+				Author:      "Jane Doe, Richard Roe",
+				Categories:  []string{"podcast", "news"},
+				Enclosures: []Enclosure{
+					{
+						Link:     "https://example.org/episode-1.mp3",
+						Type:     "audio/mpeg",
+						Medium:   "audio",
+						Length:   89970236,
+						Duration: 6629 * time.Second,
+					},
+				},
+			},
+			{
+				Title:       "Episode 2",
+				Link:        "https://other.example.org/episode-2",
+				Guid:        "https://example.org/episode-2",
+				Description: "Summary",
+				Date:        time.Date(2020, time.August, 7, 10, 0, 1, 0, time.UTC),
+				Author:      "https://example.org/authors/editor",
+			},
+		},
+	}
+)
+
+const (
+	singleJSONFeedV1 = `
+{
+	"version": "https://jsonfeed.org/version/1",
+	"title": "My Example Feed",
+	"home_page_url": "https://example.org/",
+	"feed_url": "https://example.org/feed.json",
+	"description": "An example",
+	"icon": "https://example.org/icon.png",
+	"author": {"name": "John Doe"},
+	"items": [
+		{
+			"id": 2,
+			"title": "First post",
+			"content_text": "<p>Hello, world!</p>",
+			"url": "https://example.org/initial-post",
+			"date_published": "2017-05-17T08:02:12-07:00"
+		}
+	]
+}
+`
+	multiJSONFeedV11 = `
+{
+	"version": "https://jsonfeed.org/version/1.1",
+	"title": "Podcast",
+	"home_page_url": "https://example.org/",
+	"hubs": [{"type": "rssCloud", "url": "https://cloud.example.org/"}, {"type": "WebSub", "url": "https://hub.example.org/"}],
+	"items": [
+		{
+			"id": "https://example.org/episode-1",
+			"title": "Episode 1",
+			"url": "https://example.org/episode-1",
+			"content_html": "<p>The first episode</p>",
+			"summary": "Summary",
+			"date_published": "2020-08-07T10:00:00Z",
+			"authors": [{"name": "Jane Doe"}, {"name": "Richard Roe"}],
+			"tags": ["podcast", "news", " podcast "],
+			"attachments": [
+				{
+					"url": "https://example.org/episode-1.mp3",
+					"mime_type": "audio/mpeg",
+					"size_in_bytes": 89970236,
+					"duration_in_seconds": 6629
+				}
+			]
+		},
+		{
+			"id": "https://example.org/episode-2",
+			"title": "Episode 2",
+			"external_url": "https://other.example.org/episode-2",
+			"summary": "Summary",
+			"authors": [{"url": "https://example.org/authors/editor"}]
+		}
+	]
+}
+`
+)
@@ -0,0 +1,149 @@
+package parser
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// mediaElements holds the Media RSS elements of an item or a media group.
+type mediaElements struct {
+	Contents   []mediaContent   `xml:"http://search.yahoo.com/mrss/ content"`
+	Thumbnails []mediaThumbnail `xml:"http://search.yahoo.com/mrss/ thumbnail"`
+	Groups     []mediaGroup     `xml:"http://search.yahoo.com/mrss/ group"`
+}
+
+type mediaGroup struct {
+	Contents   []mediaContent   `xml:"http://search.yahoo.com/mrss/ content"`
+	Thumbnails []mediaThumbnail `xml:"http://search.yahoo.com/mrss/ thumbnail"`
+	Title      string           `xml:"http://search.yahoo.com/mrss/ title"`
+}
+
+type mediaContent struct {
+	Url      string `xml:"url,attr"`
+	Type     string `xml:"type,attr"`
+	Medium   string `xml:"medium,attr"`
+	FileSize string `xml:"fileSize,attr"`
+	Duration string `xml:"duration,attr"`
+	Width    string `xml:"width,attr"`
+	Height   string `xml:"height,attr"`
+	Title    string `xml:"http://search.yahoo.com/mrss/ title"`
+}
+
+type mediaThumbnail struct {
+	Url    string `xml:"url,attr"`
+	Width  string `xml:"width,attr"`
+	Height string `xml:"height,attr"`
+}
+
+func (m mediaElements) enclosures() []Enclosure {
+	enclosures := mediaEnclosures(m.Contents, m.Thumbnails, "")
+
+	for _, g := range m.Groups {
+		enclosures = append(enclosures, mediaEnclosures(g.Contents, g.Thumbnails, g.Title)...)
+	}
+
+	return enclosures
+}
+
+func mediaEnclosures(contents []mediaContent, thumbnails []mediaThumbnail, title string) []Enclosure {
+	enclosures := make([]Enclosure, 0, len(contents)+len(thumbnails))
+
+	for _, c := range contents {
+		e := Enclosure{
+			Link:     c.Url,
+			Type:     c.Type,
+			Medium:   c.Medium,
+			Title:    c.Title,
+			Length:   parseInt64(c.FileSize),
+			Duration: time.Duration(parseInt64(c.Duration)) * time.Second,
+			Width:    int(parseInt64(c.Width)),
+			Height:   int(parseInt64(c.Height)),
+		}
+
+		if e.Title == "" {
+			e.Title = title
+		}
+
+		if e.Medium == "" {
+			e.Medium = mediumFromType(e.Type)
+		}
+
+		enclosures = append(enclosures, e)
+	}
+
+	for _, t := range thumbnails {
+		enclosures = append(enclosures, Enclosure{
+			Link:   t.Url,
+			Medium: "image",
+			Width:  int(parseInt64(t.Width)),
+			Height: int(parseInt64(t.Height)),
+		})
+	}
+
+	return enclosures
+}
+
+// uniqueEnclosures removes enclosures without links, and all but the first
+// enclosure with the same link.
+func uniqueEnclosures(enclosures []Enclosure) []Enclosure {
+	if len(enclosures) == 0 {
+		return nil
+	}
+
+	seen := map[string]bool{}
+	unique := make([]Enclosure, 0, len(enclosures))
+
+	for _, e := range enclosures {
+		if e.Link == "" || seen[e.Link] {
+			continue
+		}
+
+		if e.Medium == "" {
+			e.Medium = mediumFromType(e.Type)
+		}
+
+		seen[e.Link] = true
+		unique = append(unique, e)
+	}
+
+	if len(unique) == 0 {
+		return nil
+	}
+
+	return unique
+}
+
+func mediumFromType(mimeType string) string {
+	for _, medium := range []string{"image", "audio", "video"} {
+		if strings.HasPrefix(mimeType, medium+"/") {
+			return medium
+		}
+	}
+
+	return ""
+}
+
+func parseInt64(value string) int64 {
+	value = strings.TrimSpace(value)
+	if i, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return i
+	}
+
+	if f, err := strconv.ParseFloat(value, 64); err == nil {
+		return int64(f)
+	}
+
+	return 0
+}
+
+// parseITunesDuration parses durations in the HH:MM:SS, MM:SS and SS formats.
+func parseITunesDuration(value string) time.Duration {
+	var duration time.Duration
+
+	for _, part := range strings.Split(strings.TrimSpace(value), ":") {
+		duration = duration*60 + time.Duration(parseInt64(part))
+	}
+
+	return duration * time.Second
+}
@@ -5,21 +5,37 @@ import (
 	"strings"
 )
 
+// OpmlNamespace is the namespace of the readeef specific OPML attributes.
+const OpmlNamespace = "https://github.com/urandom/readeef"
+
 type Opml struct {
 	Feeds []OpmlFeed
+
+	// Settings holds the raw value of the readeef:settings attribute of the
+	// opml element.
+	Settings string
 }
 
 type OpmlFeed struct {
-	Title string
-	URL   string
-	Tags  []string
+	Title   string
+	URL     string
+	SiteURL string
+
+	// Tags holds the categories of the feed. Nested categories are joined
+	// with a '/'.
+	Tags []string
+
+	// Settings holds the raw value of the readeef:settings attribute of the
+	// feed outline.
+	Settings string
 }
 
 type OpmlXml struct {
-	XMLName xml.Name `xml:"opml"`
-	Version string   `xml:"version,attr"`
-	Head    OpmlHead `xml:"head"`
-	Body    OpmlBody `xml:"body"`
+	XMLName  xml.Name `xml:"opml"`
+	Version  string   `xml:"version,attr"`
+	Settings string   `xml:"https://github.com/urandom/readeef settings,attr,omitempty"`
+	Head     OpmlHead `xml:"head"`
+	Body     OpmlBody `xml:"body"`
 }
 
 type OpmlHead struct {
@@ -38,6 +54,7 @@ type OpmlOutline struct {
 	HtmlUrl  string        `xml:"htmlUrl,attr,omitempty"`
 	URL      string        `xml:"url,attr,omitempty"`
 	Category string        `xml:"category,attr,omitempty"`
+	Settings string        `xml:"https://github.com/urandom/readeef settings,attr,omitempty"`
 	Outline  []OpmlOutline `xml:"outline"`
 }
 
@@ -49,41 +66,97 @@ func ParseOpml(content []byte) (Opml, error) {
 		return opml, err
 	}
 
-	processOutline(&opml, o.Body.Outline, "")
+	opml.Settings = o.Settings
+
+	index := map[string]int{}
+	processOutline(&opml, index, o.Body.Outline, nil)
 
 	return opml, nil
 }
 
-func processOutline(opml *Opml, outlines []OpmlOutline, text string) {
+// processOutline collects the feeds of the outlines. Each of the parent
+// outlines may list several comma-separated categories, and the categories
+// of a feed are the paths through them. Feeds listed more than once are
+// merged.
+func processOutline(opml *Opml, index map[string]int, outlines []OpmlOutline, parents []string) {
 	for _, outline := range outlines {
-		if len(outline.Outline) == 0 {
-			feed := OpmlFeed{Title: outline.Text}
-			if outline.URL == "" {
-				feed.URL = outline.XmlUrl
-			} else {
-				feed.URL = outline.URL
+		link := outline.URL
+		if link == "" {
+			link = outline.XmlUrl
+		}
+
+		if link == "" {
+			if len(outline.Outline) > 0 {
+				processOutline(opml, index, outline.Outline, categoryPaths(parents, outline.Text))
 			}
 
-			tagSet := map[string]struct{}{}
-			if text == "" {
-				if outline.Category != "" {
-					for _, tag := range strings.Split(outline.Category, ",") {
-						tagSet[strings.TrimSpace(tag)] = struct{}{}
-					}
-				}
-			} else {
-				for _, tag := range strings.Split(text, ",") {
-					tagSet[strings.TrimSpace(tag)] = struct{}{}
+			continue
+		}
+
+		tags := parents
+		if len(tags) == 0 && outline.Category != "" {
+			for _, category := range strings.Split(outline.Category, ",") {
+				if category = strings.Trim(strings.TrimSpace(category), "/"); category != "" {
+					tags = append(tags, category)
 				}
 			}
+		}
+
+		i, ok := index[link]
+		if !ok {
+			title := outline.Text
+			if title == "" {
+				title = outline.Title
+			}
+
+			i = len(opml.Feeds)
+			index[link] = i
+			opml.Feeds = append(opml.Feeds, OpmlFeed{
+				Title: title, URL: link, SiteURL: outline.HtmlUrl, Settings: outline.Settings, Tags: []string{},
+			})
+		}
 
-			feed.Tags = make([]string, 0, len(tagSet))
-			for tag := range tagSet {
+		feed := &opml.Feeds[i]
+		for _, tag := range tags {
+			if !hasTag(feed.Tags, tag) {
 				feed.Tags = append(feed.Tags, tag)
 			}
-			opml.Feeds = append(opml.Feeds, feed)
-		} else {
-			processOutline(opml, outline.Outline, outline.Text)
 		}
 	}
 }
+
+func categoryPaths(parents []string, text string) []string {
+	var categories []string
+	for _, category := range strings.Split(text, ",") {
+		if category = strings.TrimSpace(category); category != "" {
+			categories = append(categories, category)
+		}
+	}
+
+	if len(parents) == 0 || len(categories) == 0 {
+		if len(categories) == 0 {
+			return parents
+		}
+
+		return categories
+	}
+
+	paths := make([]string, 0, len(parents)*len(categories))
+	for _, p := range parents {
+		for _, c := range categories {
+			paths = append(paths, p+"/"+c)
+		}
+	}
+
+	return paths
+}
+
+func hasTag(tags []string, tag string) bool {
+	for _, t := range tags {
+		if t == tag {
+			return true
+		}
+	}
+
+	return false
+}
@@ -16,6 +16,7 @@ func TestParseOpml(t *testing.T) {
 		{"single", []byte(singleOmplXML), singleOpml, false},
 		{"single url", []byte(singleUrlOmplXML), singleTagsOpml, false},
 		{"deep", []byte(deepOpmlXML), deepOpml, false},
+		{"nested", []byte(nestedOpmlXML), nestedOpml, false},
 		{"error", []byte("<foobar/>"), Opml{}, true},
 	}
 	for _, tt := range tests {
@@ -41,32 +42,54 @@ var (
 	singleOpml = Opml{
 		Feeds: []OpmlFeed{
 			{
-				Title: "Item 1 text",
-				URL:   "http://www.item1.com/rss",
-				Tags:  []string{},
+				Title:   "Item 1 text",
+				URL:     "http://www.item1.com/rss",
+				SiteURL: "http://www.item1.com",
+				Tags:    []string{},
 			},
 		},
 	}
 	singleTagsOpml = Opml{
 		Feeds: []OpmlFeed{
 			{
-				Title: "Item 1 text",
-				URL:   "http://www.item1.com/rss",
-				Tags:  []string{"cat1", "cat2"},
+				Title:   "Item 1 text",
+				URL:     "http://www.item1.com/rss",
+				SiteURL: "http://www.item1.com",
+				Tags:    []string{"cat1", "cat2"},
 			},
 		},
 	}
 	deepOpml = Opml{
 		Feeds: []OpmlFeed{
 			{
-				Title: "Item 1 text",
-				URL:   "http://www.item1.com/rss",
-				Tags:  []string{"cat1", "cat2"},
+				Title:   "Item 1 text",
+				URL:     "http://www.item1.com/rss",
+				SiteURL: "http://www.item1.com",
+				Tags:    []string{"cat1", "cat2"},
 			},
 			{
-				Title: "Item 2 text",
-				URL:   "http://www.item2.com/rss",
-				Tags:  []string{"cat1", "cat2"},
+				Title:   "Item 2 text",
+				URL:     "http://www.item2.com/rss",
+				SiteURL: "http://www.item2.com",
+				Tags:    []string{"cat1", "cat2"},
+			},
+		},
+	}
+	nestedOpml = Opml{
+		Settings: `{"filters":[]}`,
+		Feeds: []OpmlFeed{
+			{
+				Title:    "Item 1 text",
+				URL:      "http://www.item1.com/rss",
+				SiteURL:  "http://www.item1.com",
+				Tags:     []string{"cat1", "cat1/sub1"},
+				Settings: `{"ttl":"1h"}`,
+			},
+			{
+				Title:   "Item 2 title",
+				URL:     "http://www.item2.com/rss",
+				SiteURL: "http://www.item2.com",
+				Tags:    []string{"cat1/sub1", "cat1/sub2"},
 			},
 		},
 	}
@@ -115,5 +138,26 @@ const (
 		</outline>
     </body>
 </opml>
+`
+
+	nestedOpmlXML = `
+<?xml version="1.0" encoding="UTF-8"?>
+<opml version="2.0" xmlns:readeef="https://github.com/urandom/readeef" readeef:settings="{&#34;filters&#34;:[]}">
+    <head>
+        <title>OPML title</title>
+    </head>
+    <body>
+		<outline text="cat1">
+			<outline type="rss" text="Item 1 text" xmlUrl="http://www.item1.com/rss" htmlUrl="http://www.item1.com" readeef:settings="{&#34;ttl&#34;:&#34;1h&#34;}"></outline>
+			<outline text="sub1, sub2">
+				<outline type="rss" title="Item 2 title" xmlUrl="http://www.item2.com/rss" htmlUrl="http://www.item2.com"></outline>
+			</outline>
+			<outline text="sub1">
+				<outline type="rss" text="Item 1 text" xmlUrl="http://www.item1.com/rss" htmlUrl="http://www.item1.com"></outline>
+			</outline>
+			<outline text="empty"></outline>
+		</outline>
+    </body>
+</opml>
 `
 )
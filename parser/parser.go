@@ -23,7 +23,9 @@ func ParseFeed(source []byte, funcs ...func([]byte) (Feed, error)) (Feed, error)
 	for _, f := range funcs {
 		feed, err = f(source)
 		if err != nil {
-			if _, ok := err.(xml.UnmarshalError); !ok {
+			switch err.(type) {
+			case xml.UnmarshalError, formatError:
+			default:
 				return feed, err
 			}
 		} else {
@@ -34,6 +36,50 @@ func ParseFeed(source []byte, funcs ...func([]byte) (Feed, error)) (Feed, error)
 	return feed, err
 }
 
+// formatError is returned by the non-xml parsers when the source is not in
+// their format, so that the next parser may be tried.
+type formatError string
+
+func (e formatError) Error() string {
+	return string(e)
+}
+
+// uniqueCategories returns the trimmed, non-empty categories, without
+// duplicates.
+func uniqueCategories(categories []string) []string {
+	seen := map[string]bool{}
+	unique := make([]string, 0, len(categories))
+
+	for _, c := range categories {
+		c = strings.TrimSpace(c)
+		if c == "" || seen[c] {
+			continue
+		}
+
+		seen[c] = true
+		unique = append(unique, c)
+	}
+
+	if len(unique) == 0 {
+		return nil
+	}
+
+	return unique
+}
+
+// joinAuthors returns the trimmed, non-empty author names as a comma
+// separated list.
+func joinAuthors(authors []string) string {
+	names := make([]string, 0, len(authors))
+	for _, a := range authors {
+		if a = strings.TrimSpace(a); a != "" {
+			names = append(names, a)
+		}
+	}
+
+	return strings.Join(names, ", ")
+}
+
 func parseDate(date string) (time.Time, error) {
 	formats := []string{
 		time.ANSIC,
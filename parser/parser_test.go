@@ -21,6 +21,18 @@ func TestParseFeed(t *testing.T) {
 		t.Fatal(err)
 	}
 
+	_, err = ParseFeed([]byte(singleJSONFeedV1), ParseJSONFeed, ParseRss2, ParseAtom, ParseRss1)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = ParseFeed([]byte(singleAtomXML), ParseJSONFeed, ParseRss2, ParseAtom, ParseRss1)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
 	_, err = ParseFeed([]byte(singleRss1XML), ParseRss2, ParseAtom)
 
 	if err == nil {
@@ -5,6 +5,27 @@ import (
 	"strings"
 )
 
+func (i RssItem) enclosures() []Enclosure {
+	enclosures := make([]Enclosure, 0, len(i.Enclosures))
+	for _, e := range i.Enclosures {
+		enclosures = append(enclosures, Enclosure{
+			Link: e.Url, Type: e.Type, Length: parseInt64(e.Length),
+		})
+	}
+
+	enclosures = append(enclosures, i.mediaElements.enclosures()...)
+
+	if duration := parseITunesDuration(i.ITunesDuration); duration > 0 {
+		for j := range enclosures {
+			if enclosures[j].Duration == 0 && enclosures[j].Medium != "image" {
+				enclosures[j].Duration = duration
+			}
+		}
+	}
+
+	return uniqueEnclosures(enclosures)
+}
+
 type rssImage struct {
 	XMLName xml.Name `xml:"image"`
 	Title   string   `xml:"title"`
@@ -27,6 +48,54 @@ type RssItem struct {
 	TTL         int        `xml:"ttl"`
 	SkipHours   []int      `xml:"skipHours>hour"`
 	SkipDays    []string   `xml:"skipDays>day"`
+
+	Author       string   `xml:"parserfeed author"`
+	Creators     []string `xml:"http://purl.org/dc/elements/1.1/ creator"`
+	ITunesAuthor string   `xml:"http://www.itunes.com/dtds/podcast-1.0.dtd author"`
+	Categories   []string `xml:"parserfeed category"`
+	Subjects     []string `xml:"http://purl.org/dc/elements/1.1/ subject"`
+
+	Enclosures     []rssEnclosure `xml:"enclosure"`
+	ITunesDuration string         `xml:"http://www.itunes.com/dtds/podcast-1.0.dtd duration"`
+	mediaElements
+}
+
+// author returns the dc:creator names, falling back to the author's name
+// and then the itunes:author.
+func (i RssItem) author() string {
+	if author := joinAuthors(i.Creators); author != "" {
+		return author
+	}
+
+	if author := rssAuthorName(i.Author); author != "" {
+		return author
+	}
+
+	return strings.TrimSpace(i.ITunesAuthor)
+}
+
+func (i RssItem) categories() []string {
+	return uniqueCategories(append(append([]string{}, i.Categories...), i.Subjects...))
+}
+
+// rssAuthorName extracts the name from an RSS author, which is usually in
+// the form of "email (Name)".
+func rssAuthorName(author string) string {
+	author = strings.TrimSpace(author)
+
+	if start, end := strings.Index(author, "("), strings.LastIndex(author, ")"); start != -1 && end > start {
+		if name := strings.TrimSpace(author[start+1 : end]); name != "" {
+			return name
+		}
+	}
+
+	return author
+}
+
+type rssEnclosure struct {
+	Url    string `xml:"url,attr"`
+	Type   string `xml:"type,attr"`
+	Length string `xml:"length,attr"`
 }
 
 type rssContent struct {
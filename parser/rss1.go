@@ -75,6 +75,9 @@ func ParseRss1(b []byte) (Feed, error) {
 	for _, i := range rss.Items {
 		article := Article{Title: i.Title, Link: i.Link, Guid: i.Id}
 		article.Description = getLargerContent(i.Content, i.Description)
+		article.Author = i.author()
+		article.Categories = i.categories()
+		article.Enclosures = i.enclosures()
 
 		var err error
 		if i.PubDate != "" {
@@ -19,6 +19,8 @@ func TestParseRss2(t *testing.T) {
 		{"single no date", []byte(singleNoDateRss2XML), singleNoDateRss2Feed, false},
 		{"multi last no date", []byte(multiLastNoDateRss2XML), multiLastNoDateRss2Feed, false},
 		{"html escapes in xml", []byte(htmlEscapesInXML), htmlEscapesInXMLFeed, false},
+		{"enclosures", []byte(enclosuresRss2XML), enclosuresRss2Feed, false},
+		{"authors and categories", []byte(authorsRss2XML), authorsRss2Feed, false},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -121,6 +123,73 @@ var (
 			},
 		},
 	}
+
+	enclosuresRss2Feed = Feed{
+		Title:     "Podcast",
+		SiteLink:  "http://example.com/",
+		SkipHours: map[int]bool{},
+		SkipDays:  map[string]bool{},
+		Articles: []Article{
+			{
+				Title:       "Episode 1",
+				Link:        "http://example.com/episode-1",
+				Guid:        "http://example.com/episode-1",
+				Description: "The first episode",
+				Date:        time.Date(2020, time.August, 7, 10, 0, 0, 0, gmt),
+				Enclosures: []Enclosure{
+					{
+						Link:     "http://example.com/episode-1.mp3",
+						Type:     "audio/mpeg",
+						Medium:   "audio",
+						Length:   89970236,
+						Duration: time.Hour + 50*time.Minute + 29*time.Second,
+					},
+					{
+						Link:     "http://example.com/episode-1.mp4",
+						Type:     "video/mp4",
+						Medium:   "video",
+						Title:    "Video version",
+						Length:   1234,
+						Duration: 6629 * time.Second,
+						Width:    1280,
+						Height:   720,
+					},
+					{
+						Link:   "http://example.com/episode-1.jpg",
+						Medium: "image",
+						Width:  640,
+						Height: 360,
+					},
+				},
+			},
+		},
+	}
+
+	authorsRss2Feed = Feed{
+		Title:     "Blog",
+		SiteLink:  "http://example.com/",
+		SkipHours: map[int]bool{},
+		SkipDays:  map[string]bool{},
+		Articles: []Article{
+			{
+				Title:       "Post 1",
+				Link:        "http://example.com/post-1",
+				Guid:        "http://example.com/post-1",
+				Description: "The first post",
+				Date:        time.Date(2020, time.August, 7, 10, 0, 0, 0, gmt),
+				Author:      "John Doe",
+				Categories:  []string{"Go", "Feeds", "Parsing"},
+			},
+			{
+				Title:       "Post 2",
+				Link:        "http://example.com/post-2",
+				Guid:        "http://example.com/post-2",
+				Description: "The second post",
+				Date:        time.Date(2020, time.August, 8, 10, 0, 0, 0, gmt),
+				Author:      "Jane Doe, Richard Roe",
+			},
+		},
+	}
 )
 
 const (
@@ -294,5 +363,59 @@ const (
 </rss>
 
 
+`
+	enclosuresRss2XML = `
+<?xml version="1.0"?>
+<rss version="2.0" xmlns:itunes="http://www.itunes.com/dtds/podcast-1.0.dtd" xmlns:media="http://search.yahoo.com/mrss/">
+   <channel>
+      <title>Podcast</title>
+      <link>http://example.com/</link>
+      <item>
+         <title>Episode 1</title>
+         <link>http://example.com/episode-1</link>
+         <description>The first episode</description>
+         <pubDate>Fri, 07 Aug 2020 10:00:00 GMT</pubDate>
+         <guid>http://example.com/episode-1</guid>
+         <enclosure url="http://example.com/episode-1.mp3" length="89970236" type="audio/mpeg" />
+         <itunes:duration>01:50:29</itunes:duration>
+         <media:content url="http://example.com/episode-1.mp4" type="video/mp4" fileSize="1234" duration="6629" width="1280" height="720">
+            <media:title>Video version</media:title>
+         </media:content>
+         <media:content url="http://example.com/episode-1.mp3" type="audio/mpeg" />
+         <media:thumbnail url="http://example.com/episode-1.jpg" width="640" height="360" />
+      </item>
+   </channel>
+</rss>
+`
+	authorsRss2XML = `
+<?xml version="1.0"?>
+<rss version="2.0" xmlns:dc="http://purl.org/dc/elements/1.1/">
+   <channel>
+      <title>Blog</title>
+      <link>http://example.com/</link>
+      <item>
+         <title>Post 1</title>
+         <link>http://example.com/post-1</link>
+         <description>The first post</description>
+         <pubDate>Fri, 07 Aug 2020 10:00:00 GMT</pubDate>
+         <guid>http://example.com/post-1</guid>
+         <author>john@example.com (John Doe)</author>
+         <category>Go</category>
+         <category domain="http://example.com/tags"> Feeds </category>
+         <category>Go</category>
+         <dc:subject>Parsing</dc:subject>
+      </item>
+      <item>
+         <title>Post 2</title>
+         <link>http://example.com/post-2</link>
+         <description>The second post</description>
+         <pubDate>Sat, 08 Aug 2020 10:00:00 GMT</pubDate>
+         <guid>http://example.com/post-2</guid>
+         <author>editor@example.com</author>
+         <dc:creator>Jane Doe</dc:creator>
+         <dc:creator>Richard Roe</dc:creator>
+      </item>
+   </channel>
+</rss>
 `
 )